@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"slices"
 	"testing"
 	"time"
 
@@ -344,7 +345,7 @@ func TestFilterValidGrants(t *testing.T) {
 	}
 }
 
-func TestHasAnyPermission(t *testing.T) {
+func TestEvaluatePermissionsAny(t *testing.T) {
 	now := time.Now()
 	userID := uuid.New()
 	roleID := uuid.New()
@@ -405,15 +406,17 @@ func TestHasAnyPermission(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := HasAnyPermission(tt.grants, roles, tt.permissions, tt.scope, now)
+			result := slices.ContainsFunc(tt.permissions, func(permission string) bool {
+				return EvaluatePermissions(tt.grants, roles, permission, tt.scope, now)
+			})
 			if result != tt.expected {
-				t.Errorf("HasAnyPermission() = %v, want %v", result, tt.expected)
+				t.Errorf("EvaluatePermissions() for any of %v = %v, want %v", tt.permissions, result, tt.expected)
 			}
 		})
 	}
 }
 
-func TestHasAllPermissions(t *testing.T) {
+func TestEvaluatePermissionsAll(t *testing.T) {
 	now := time.Now()
 	userID := uuid.New()
 	roleID := uuid.New()
@@ -474,9 +477,11 @@ func TestHasAllPermissions(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := HasAllPermissions(tt.grants, roles, tt.permissions, tt.scope, now)
+			result := !slices.ContainsFunc(tt.permissions, func(permission string) bool {
+				return !EvaluatePermissions(tt.grants, roles, permission, tt.scope, now)
+			})
 			if result != tt.expected {
-				t.Errorf("HasAllPermissions() = %v, want %v", result, tt.expected)
+				t.Errorf("EvaluatePermissions() for all of %v = %v, want %v", tt.permissions, result, tt.expected)
 			}
 		})
 	}
@@ -22,6 +22,11 @@ const (
 	PermGrantsWrite  Permission = "grants:write"
 	PermGrantsDelete Permission = "grants:delete"
 	PermGrantsManage Permission = "grants:manage"
+
+	// Estate Management, evaluated in the estate scope of each property
+	PermEstatesRead   Permission = "estates:read"
+	PermEstatesWrite  Permission = "estates:write"
+	PermEstatesDelete Permission = "estates:delete"
 )
 
 type PermissionCategory struct {
@@ -69,6 +74,14 @@ var PermissionRegistry = []PermissionCategory{
 			{PermGrantsManage, "Manage Grants", "Full grant management"},
 		},
 	},
+	{
+		Name: "Estates",
+		Permissions: []PermissionInfo{
+			{PermEstatesRead, "Read Estates", "View properties"},
+			{PermEstatesWrite, "Write Estates", "Create and update properties"},
+			{PermEstatesDelete, "Delete Estates", "Delete and restore properties"},
+		},
+	},
 }
 
 func AllPermissions() []Permission {
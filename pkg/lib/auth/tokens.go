@@ -368,6 +368,33 @@ func VerifyPASETOToken(token string, publicKey ed25519.PublicKey) (*TokenClaims,
 	return claims, nil
 }
 
+// TokenVerifier validates the PASETO tokens issued for an audience, such as
+// the "session" tokens of the authn service.
+type TokenVerifier struct {
+	publicKey ed25519.PublicKey
+	audience  string
+	now       func() time.Time
+}
+
+// NewTokenVerifier creates a verifier of the tokens signed with the private
+// key of publicKey for audience.
+func NewTokenVerifier(publicKey ed25519.PublicKey, audience string) *TokenVerifier {
+	return &TokenVerifier{publicKey: publicKey, audience: audience, now: time.Now}
+}
+
+// ValidateToken verifies the token signature and claims and returns its
+// subject, the user ID.
+func (v *TokenVerifier) ValidateToken(token string) (string, error) {
+	claims, err := VerifyPASETOToken(token, v.publicKey)
+	if err != nil {
+		return "", err
+	}
+	if errs := ValidateTokenForService(*claims, v.audience, v.now()); errs.HasErrors() {
+		return "", fmt.Errorf("invalid token: %w", errs)
+	}
+	return claims.Subject, nil
+}
+
 // GenerateKeyPair generates an Ed25519 key pair for PASETO tokens
 func GenerateKeyPair() (ed25519.PublicKey, ed25519.PrivateKey, error) {
 	return ed25519.GenerateKey(nil)
@@ -644,3 +644,32 @@ func TestCreateTokenClaims(t *testing.T) {
 		t.Errorf("CreateTokenClaims() expiration time is not within expected range")
 	}
 }
+
+func TestTokenVerifier(t *testing.T) {
+	publicKey, privateKey, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair() error = %v", err)
+	}
+	_, otherKey, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair() error = %v", err)
+	}
+
+	session, _ := GenerateSessionToken("user123", "session456", privateKey, time.Hour)
+	expired, _ := GenerateSessionToken("user123", "session456", privateKey, -time.Minute)
+	internal, _ := GenerateInternalToken("user123", "session456", "orders", nil, privateKey, time.Hour)
+	forged, _ := GenerateSessionToken("user123", "session456", otherKey, time.Hour)
+
+	verifier := NewTokenVerifier(publicKey, "session")
+
+	subject, err := verifier.ValidateToken(session)
+	if err != nil || subject != "user123" {
+		t.Errorf("ValidateToken() = %q, %v, want user123", subject, err)
+	}
+
+	for name, token := range map[string]string{"expired": expired, "wrong audience": internal, "forged": forged, "malformed": "dev-admin"} {
+		if _, err := verifier.ValidateToken(token); err == nil {
+			t.Errorf("ValidateToken() accepted %s token", name)
+		}
+	}
+}
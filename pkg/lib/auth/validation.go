@@ -176,6 +176,8 @@ func ValidateScope(scope Scope) ValidationErrors {
 	}
 
 	switch scope.Type {
+	case "":
+		// Already reported as required.
 	case "global":
 		if scope.ID != "" {
 			errors = append(errors, ValidationError{
@@ -184,7 +186,7 @@ func ValidateScope(scope Scope) ValidationErrors {
 				Message: "Global scope cannot have an ID",
 			})
 		}
-	case "team", "organization":
+	case "team", "organization", "estate":
 		if scope.ID == "" {
 			errors = append(errors, ValidationError{
 				Field:   "scope.id",
@@ -196,7 +198,7 @@ func ValidateScope(scope Scope) ValidationErrors {
 		errors = append(errors, ValidationError{
 			Field:   "scope.type",
 			Code:    "invalid_value",
-			Message: "Scope type must be global, team, or organization",
+			Message: "Scope type must be global, team, organization or estate",
 		})
 	}
 
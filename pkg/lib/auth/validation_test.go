@@ -370,6 +370,18 @@ func TestValidateScope(t *testing.T) {
 			expectedCount: 0,
 			expectedCodes: []string{},
 		},
+		{
+			name:          "valid estate scope",
+			scope:         Scope{Type: "estate", ID: "team-7"},
+			expectedCount: 0,
+			expectedCodes: []string{},
+		},
+		{
+			name:          "estate scope without ID",
+			scope:         Scope{Type: "estate", ID: ""},
+			expectedCount: 1,
+			expectedCodes: []string{"required"},
+		},
 		{
 			name:          "empty scope type",
 			scope:         Scope{Type: "", ID: "123"},
@@ -3,6 +3,7 @@ package core
 import (
 	"context"
 	"fmt"
+	"net/url"
 )
 
 type AuthzHTTPClient struct {
@@ -55,6 +56,27 @@ func (c *AuthzHTTPClient) CheckPermission(ctx context.Context, userID, permissio
 	return allowed, nil
 }
 
+// ListScopes returns the scopes of scopeType in which the user holds the
+// permission. A single scope of type global means the user holds it
+// everywhere.
+func (c *AuthzHTTPClient) ListScopes(ctx context.Context, userID, permission, scopeType string) ([]AuthzScope, error) {
+	query := url.Values{}
+	query.Set("permission", permission)
+	query.Set("scope_type", scopeType)
+	path := fmt.Sprintf("/authz/policy/users/%s/scopes?%s", url.PathEscape(userID), query.Encode())
+
+	var resp struct {
+		Data struct {
+			Scopes []AuthzScope `json:"scopes"`
+		} `json:"data"`
+	}
+	if err := c.http.Get(ctx, path, &resp); err != nil {
+		return nil, fmt.Errorf("authz scopes failed: %w", err)
+	}
+
+	return resp.Data.Scopes, nil
+}
+
 type PermissionRequest struct {
 	UserID     string     `json:"user_id"`
 	Permission string     `json:"permission"`
@@ -0,0 +1,29 @@
+package core
+
+import "context"
+
+type bearerTokenKeyType struct{}
+
+var bearerTokenKey bearerTokenKeyType
+
+// WithBearerToken attaches the token of the user a request acts for to the
+// context. HTTPClient sends it as the Authorization of outgoing requests, so
+// downstream services authorize the user and not the caller service.
+func WithBearerToken(ctx context.Context, token string) context.Context {
+	if token == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, bearerTokenKey, token)
+}
+
+// BearerTokenFrom extracts the bearer token from the context, returning an
+// empty string when the token is not present.
+func BearerTokenFrom(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if v, ok := ctx.Value(bearerTokenKey).(string); ok {
+		return v
+	}
+	return ""
+}
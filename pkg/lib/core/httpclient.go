@@ -46,22 +46,63 @@ func NewHTTPClient(config HTTPClientConfig) *HTTPClient {
 }
 
 func (c *HTTPClient) Get(ctx context.Context, path string, result interface{}) error {
-	return c.doWithRetry(ctx, "GET", path, nil, result)
+	return c.doWithRetry(ctx, "GET", path, nil, nil, result)
 }
 
 func (c *HTTPClient) Post(ctx context.Context, path string, body interface{}, result interface{}) error {
-	return c.doWithRetry(ctx, "POST", path, body, result)
+	return c.doWithRetry(ctx, "POST", path, nil, body, result)
+}
+
+func (c *HTTPClient) Put(ctx context.Context, path string, body interface{}, result interface{}) error {
+	return c.doWithRetry(ctx, "PUT", path, nil, body, result)
 }
 
 func (c *HTTPClient) Patch(ctx context.Context, path string, body interface{}, result interface{}) error {
-	return c.doWithRetry(ctx, "PATCH", path, body, result)
+	return c.doWithRetry(ctx, "PATCH", path, nil, body, result)
 }
 
 func (c *HTTPClient) Delete(ctx context.Context, path string) error {
-	return c.doWithRetry(ctx, "DELETE", path, nil, nil)
+	return c.doWithRetry(ctx, "DELETE", path, nil, nil, nil)
+}
+
+// Do sends a request with extra headers, e.g. If-Match for conditional writes.
+func (c *HTTPClient) Do(ctx context.Context, method, path string, header http.Header, body interface{}, result interface{}) error {
+	return c.doWithRetry(ctx, method, path, header, body, result)
+}
+
+// Stream sends a GET request and returns the response unread, for payloads
+// that are not JSON such as file downloads. The caller must close the body.
+// Error statuses are returned as *HTTPError. The client timeout does not
+// apply, as reading the body may take long; ctx bounds the request instead.
+func (c *HTTPClient) Stream(ctx context.Context, path string, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.BaseURL+path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	c.setHeader(req, header)
+	if reqID := RequestIDFrom(ctx); reqID != "" {
+		req.Header.Set(RequestIDHeader, reqID)
+	}
+
+	client := *c.HTTPClient
+	client.Timeout = 0
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+
+	if resp.StatusCode >= 400 {
+		defer resp.Body.Close()
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, &HTTPError{
+			StatusCode: resp.StatusCode,
+			Message:    string(bodyBytes),
+		}
+	}
+	return resp, nil
 }
 
-func (c *HTTPClient) doWithRetry(ctx context.Context, method, path string, body interface{}, result interface{}) error {
+func (c *HTTPClient) doWithRetry(ctx context.Context, method, path string, header http.Header, body interface{}, result interface{}) error {
 	var lastErr error
 
 	for attempt := 0; attempt <= c.MaxRetries; attempt++ {
@@ -73,7 +114,7 @@ func (c *HTTPClient) doWithRetry(ctx context.Context, method, path string, body
 			}
 		}
 
-		err := c.do(ctx, method, path, body, result)
+		err := c.do(ctx, method, path, header, body, result)
 		if err == nil {
 			return nil
 		}
@@ -88,7 +129,7 @@ func (c *HTTPClient) doWithRetry(ctx context.Context, method, path string, body
 	return fmt.Errorf("max retries (%d) exceeded: %w", c.MaxRetries, lastErr)
 }
 
-func (c *HTTPClient) do(ctx context.Context, method, path string, body interface{}, result interface{}) error {
+func (c *HTTPClient) do(ctx context.Context, method, path string, header http.Header, body interface{}, result interface{}) error {
 	url := c.BaseURL + path
 
 	var bodyReader io.Reader
@@ -109,6 +150,7 @@ func (c *HTTPClient) do(ctx context.Context, method, path string, body interface
 		req.Header.Set("Content-Type", "application/json")
 	}
 	req.Header.Set("Accept", "application/json")
+	c.setHeader(req, header)
 
 	if reqID := RequestIDFrom(ctx); reqID != "" {
 		req.Header.Set(RequestIDHeader, reqID)
@@ -137,6 +179,20 @@ func (c *HTTPClient) do(ctx context.Context, method, path string, body interface
 	return nil
 }
 
+// setHeader adds the Authorization of the context bearer token and then the
+// request headers; request headers replace the token of the same name.
+func (c *HTTPClient) setHeader(req *http.Request, header http.Header) {
+	if token := BearerTokenFrom(req.Context()); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	for key, values := range header {
+		req.Header.Del(key)
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+}
+
 func (c *HTTPClient) shouldRetry(err error) bool {
 	if err == nil {
 		return false
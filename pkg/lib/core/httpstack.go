@@ -17,6 +17,13 @@ type StackOptions struct {
 	Metrics     Metrics
 	Errors      ErrorReporter
 	DebugRoutes bool
+	// ContentTypes are accepted in addition to the default request body
+	// types, e.g. application/merge-patch+json.
+	ContentTypes []string
+	// RealIP resolves the client address of requests. Nil keeps
+	// chimiddleware.RealIP, which trusts the forwarding headers of anyone;
+	// services exposed to clients directly use RealIPMiddleware instead.
+	RealIP func(http.Handler) http.Handler
 }
 
 // ApplyStack wires the shared middleware set onto the provided router. It keeps
@@ -35,16 +42,20 @@ func ApplyStack(r *chi.Mux, logger Logger, opts StackOptions) {
 	if opts.Errors == nil {
 		opts.Errors = NewNoopErrorReporter()
 	}
+	if opts.RealIP == nil {
+		opts.RealIP = chimiddleware.RealIP
+	}
 
 	r.Use(RequestIDMiddleware)
-	r.Use(chimiddleware.RealIP)
+	r.Use(opts.RealIP)
 	r.Use(chimiddleware.Compress(5))
 	r.Use(chimiddleware.Recoverer)
 	r.Use(NewErrorReportingMiddleware(opts.Errors))
 	r.Use(chimiddleware.Timeout(opts.Timeout))
 	r.Use(NewRequestLogger(logger))
 	r.Use(NewMetricsMiddleware(opts.Metrics))
-	r.Use(chimiddleware.AllowContentType("application/json", "application/x-www-form-urlencoded", "multipart/form-data"))
+	contentTypes := append([]string{"application/json", "application/x-www-form-urlencoded", "multipart/form-data"}, opts.ContentTypes...)
+	r.Use(chimiddleware.AllowContentType(contentTypes...))
 
 	if opts.CORS != nil {
 		r.Use(CORSMiddleware(*opts.CORS))
@@ -0,0 +1,75 @@
+package core
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRealIPMiddleware(t *testing.T) {
+	trusted, err := ParseTrustedProxies("10.0.0.0/8, 192.168.1.10")
+	if err != nil {
+		t.Fatalf("ParseTrustedProxies: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		remote  string
+		headers map[string]string
+		want    string
+	}{
+		{"direct", "203.0.113.7:5000", nil, "203.0.113.7:5000"},
+		{"untrusted forwarded", "203.0.113.7:5000", map[string]string{"X-Forwarded-For": "198.51.100.1"}, "203.0.113.7:5000"},
+		{"untrusted real IP", "203.0.113.7:5000", map[string]string{"X-Real-IP": "198.51.100.1"}, "203.0.113.7:5000"},
+		{"trusted forwarded", "10.1.2.3:5000", map[string]string{"X-Forwarded-For": "198.51.100.1"}, "198.51.100.1"},
+		{"trusted single address", "192.168.1.10:5000", map[string]string{"X-Forwarded-For": "198.51.100.1"}, "198.51.100.1"},
+		{"forged hop", "10.1.2.3:5000", map[string]string{"X-Forwarded-For": "1.1.1.1, 198.51.100.1"}, "198.51.100.1"},
+		{"proxy chain", "10.1.2.3:5000", map[string]string{"X-Forwarded-For": "198.51.100.1, 10.9.9.9"}, "198.51.100.1"},
+		{"trusted real IP", "10.1.2.3:5000", map[string]string{"X-Real-IP": "198.51.100.1"}, "198.51.100.1"},
+		{"invalid header", "10.1.2.3:5000", map[string]string{"X-Forwarded-For": "unknown"}, "10.1.2.3:5000"},
+		{"no header", "10.1.2.3:5000", nil, "10.1.2.3:5000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			handler := RealIPMiddleware(trusted)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.RemoteAddr
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remote
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRealIPMiddlewareTrustsNoProxyByDefault(t *testing.T) {
+	var got string
+	handler := RealIPMiddleware(nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.RemoteAddr
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "127.0.0.1:5000"
+	req.Header.Set("X-Forwarded-For", "198.51.100.1")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if got != "127.0.0.1:5000" {
+		t.Errorf("expected the peer address, got %q", got)
+	}
+}
+
+func TestParseTrustedProxiesInvalid(t *testing.T) {
+	for _, list := range []string{"proxy", "10.0.0.0/33"} {
+		if _, err := ParseTrustedProxies(list); err == nil {
+			t.Errorf("expected an error for %q", list)
+		}
+	}
+}
@@ -70,6 +70,13 @@ func RespondSuccess(w http.ResponseWriter, data interface{}, links ...Link) {
 	json.NewEncoder(w).Encode(SuccessResponse{Data: data, Links: links})
 }
 
+// RespondSuccessWithMeta sends a successful JSON response with metadata (e.g. pagination) and HATEOAS links
+func RespondSuccessWithMeta(w http.ResponseWriter, data interface{}, meta interface{}, links ...Link) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(SuccessResponse{Data: data, Meta: meta, Links: links})
+}
+
 // RespondError sends a simple error response
 func RespondError(w http.ResponseWriter, code int, message string) {
 	w.Header().Set("Content-Type", "application/json")
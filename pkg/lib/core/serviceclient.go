@@ -3,6 +3,7 @@ package core
 import (
 	"context"
 	"fmt"
+	"net/http"
 )
 
 type ServiceClient struct {
@@ -71,23 +72,20 @@ func (c *ServiceClient) Delete(ctx context.Context, resource, id string) error {
 }
 
 func (c *ServiceClient) Request(ctx context.Context, method, path string, body interface{}) (*SuccessResponse, error) {
+	return c.RequestWithHeader(ctx, method, path, nil, body)
+}
+
+// RequestWithHeader is like Request but sends extra headers, e.g. If-Match.
+func (c *ServiceClient) RequestWithHeader(ctx context.Context, method, path string, header http.Header, body interface{}) (*SuccessResponse, error) {
 	var resp SuccessResponse
 
 	switch method {
-	case "GET":
-		if err := c.http.Get(ctx, path, &resp); err != nil {
-			return nil, err
-		}
-	case "POST":
-		if err := c.http.Post(ctx, path, body, &resp); err != nil {
-			return nil, err
-		}
-	case "PATCH":
-		if err := c.http.Patch(ctx, path, body, &resp); err != nil {
+	case "GET", "POST", "PUT", "PATCH":
+		if err := c.http.Do(ctx, method, path, header, body, &resp); err != nil {
 			return nil, err
 		}
 	case "DELETE":
-		if err := c.http.Delete(ctx, path); err != nil {
+		if err := c.http.Do(ctx, method, path, header, nil, nil); err != nil {
 			return nil, err
 		}
 	default:
@@ -97,6 +95,12 @@ func (c *ServiceClient) Request(ctx context.Context, method, path string, body i
 	return &resp, nil
 }
 
+// Stream sends a GET request and returns the response unread, e.g. a file
+// download. The caller must close the body.
+func (c *ServiceClient) Stream(ctx context.Context, path string, header http.Header) (*http.Response, error) {
+	return c.http.Stream(ctx, path, header)
+}
+
 func (c *ServiceClient) Ping(ctx context.Context) error {
 	return c.http.Ping(ctx)
 }
@@ -2,7 +2,14 @@ package admin
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/pulap/pulap/pkg/lib/core"
@@ -21,38 +28,64 @@ func NewAPIPropertyRepo(client *core.ServiceClient) *APIPropertyRepo {
 }
 
 // List retrieves all properties from estate service.
+// The estate service paginates results, so pages are followed until the last one.
 func (r *APIPropertyRepo) List(ctx context.Context) ([]*Property, error) {
-	resp, err := r.client.List(ctx, "estates")
-	if err != nil {
-		return nil, fmt.Errorf("failed to list properties: %w", err)
-	}
+	properties := []*Property{}
+	cursor := ""
 
-	// Handle null/empty data
-	if resp.Data == nil {
-		return []*Property{}, nil
-	}
+	for {
+		resource := fmt.Sprintf("estates?limit=%d", listPageSize)
+		if cursor != "" {
+			resource += "&cursor=" + url.QueryEscape(cursor)
+		}
 
-	propertiesData, ok := resp.Data.([]interface{})
-	if !ok {
-		return nil, fmt.Errorf("invalid response format")
-	}
+		resp, err := r.client.List(ctx, resource)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list properties: %w", err)
+		}
 
-	properties := make([]*Property, 0, len(propertiesData))
-	for _, item := range propertiesData {
-		propertyData, ok := item.(map[string]interface{})
+		// Handle null/empty data
+		if resp.Data == nil {
+			return properties, nil
+		}
+
+		propertiesData, ok := resp.Data.([]interface{})
 		if !ok {
-			continue
+			return nil, fmt.Errorf("invalid response format")
 		}
 
-		property, err := parsePropertyFromMap(propertyData)
-		if err != nil {
-			continue
+		for _, item := range propertiesData {
+			propertyData, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			property, err := parsePropertyFromMap(propertyData)
+			if err != nil {
+				continue
+			}
+
+			properties = append(properties, property)
 		}
 
-		properties = append(properties, property)
+		cursor = nextCursor(resp.Meta)
+		if cursor == "" {
+			return properties, nil
+		}
 	}
+}
+
+// listPageSize is the page size requested when listing properties.
+const listPageSize = 200
 
-	return properties, nil
+// nextCursor extracts the pagination cursor from a collection response meta.
+func nextCursor(meta interface{}) string {
+	m, ok := meta.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	cursor, _ := m["next_cursor"].(string)
+	return cursor
 }
 
 // Get retrieves a property by ID from estate service.
@@ -71,9 +104,21 @@ func (r *APIPropertyRepo) Get(ctx context.Context, id uuid.UUID) (*Property, err
 }
 
 // Create creates a new property via estate service.
+// Estate answers 409 with the likely duplicates unless they are allowed.
 func (r *APIPropertyRepo) Create(ctx context.Context, req *CreatePropertyRequest) (*Property, error) {
-	resp, err := r.client.Create(ctx, "estates", req)
+	path := "/estates"
+	if req.AllowDuplicate {
+		path += "?allow_duplicate=true"
+	}
+
+	resp, err := r.client.Request(ctx, "POST", path, req)
 	if err != nil {
+		var httpErr *core.HTTPError
+		if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusConflict {
+			if dupErr := parseDuplicateConflict(httpErr.Message); dupErr != nil {
+				return nil, fmt.Errorf("failed to create property: %w", dupErr)
+			}
+		}
 		return nil, fmt.Errorf("failed to create property: %w", err)
 	}
 
@@ -86,9 +131,19 @@ func (r *APIPropertyRepo) Create(ctx context.Context, req *CreatePropertyRequest
 }
 
 // Update updates an existing property via estate service.
+// The revision is sent as If-Match so estate rejects stale updates.
 func (r *APIPropertyRepo) Update(ctx context.Context, id uuid.UUID, req *UpdatePropertyRequest) (*Property, error) {
-	resp, err := r.client.Update(ctx, "estates", id.String(), req)
+	var header http.Header
+	if req.Revision != 0 {
+		header = http.Header{"If-Match": {strconv.Quote(strconv.FormatInt(req.Revision, 10))}}
+	}
+
+	resp, err := r.client.RequestWithHeader(ctx, "PUT", "/estates/"+id.String(), header, req)
 	if err != nil {
+		var httpErr *core.HTTPError
+		if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusPreconditionFailed {
+			return nil, fmt.Errorf("failed to update property: %w", ErrPropertyConflict)
+		}
 		return nil, fmt.Errorf("failed to update property: %w", err)
 	}
 
@@ -100,15 +155,113 @@ func (r *APIPropertyRepo) Update(ctx context.Context, id uuid.UUID, req *UpdateP
 	return parsePropertyFromMap(propertyData)
 }
 
-// Delete removes a property via estate service.
+// Transition changes the status of a property via estate service.
+func (r *APIPropertyRepo) Transition(ctx context.Context, id uuid.UUID, req *TransitionPropertyRequest) error {
+	path := fmt.Sprintf("/estates/%s/transitions", id.String())
+	if _, err := r.client.Request(ctx, "POST", path, req); err != nil {
+		return fmt.Errorf("failed to transition property: %w", err)
+	}
+
+	return nil
+}
+
+// Revisions retrieves the revision history of a property from estate service.
+func (r *APIPropertyRepo) Revisions(ctx context.Context, id uuid.UUID) ([]PropertyRevision, error) {
+	path := fmt.Sprintf("/estates/%s/revisions", id.String())
+	resp, err := r.client.Request(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list property revisions: %w", err)
+	}
+
+	items, ok := resp.Data.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid response format")
+	}
+
+	revisions := make([]PropertyRevision, 0, len(items))
+	for _, item := range items {
+		data, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		revisions = append(revisions, parseRevisionFromMap(data))
+	}
+
+	return revisions, nil
+}
+
+// Restore restores a property revision via estate service.
+func (r *APIPropertyRepo) Restore(ctx context.Context, id uuid.UUID, number int64) error {
+	path := fmt.Sprintf("/estates/%s/revisions/%d/restore", id.String(), number)
+	if _, err := r.client.Request(ctx, "POST", path, nil); err != nil {
+		return fmt.Errorf("failed to restore property revision: %w", err)
+	}
+
+	return nil
+}
+
+// Delete moves a property to the trash via estate service.
 func (r *APIPropertyRepo) Delete(ctx context.Context, id uuid.UUID) error {
-	if err := r.client.Delete(ctx, "estates", id.String()); err != nil {
+	path := fmt.Sprintf("/estates/%s", id.String())
+	if _, err := r.client.Request(ctx, "DELETE", path, nil); err != nil {
 		return fmt.Errorf("failed to delete property: %w", err)
 	}
 
 	return nil
 }
 
+// ListTrash retrieves the deleted properties from estate service.
+func (r *APIPropertyRepo) ListTrash(ctx context.Context) (*PropertyTrash, error) {
+	trash := &PropertyTrash{Properties: []*Property{}}
+	cursor := ""
+
+	for {
+		resource := fmt.Sprintf("estates/trash?limit=%d", listPageSize)
+		if cursor != "" {
+			resource += "&cursor=" + url.QueryEscape(cursor)
+		}
+
+		resp, err := r.client.List(ctx, resource)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list deleted properties: %w", err)
+		}
+
+		if meta, ok := resp.Meta.(map[string]interface{}); ok {
+			trash.RetentionDays = intField(meta, "retention_days")
+		}
+
+		items, _ := resp.Data.([]interface{})
+		for _, item := range items {
+			data, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			property, err := parsePropertyFromMap(data)
+			if err != nil {
+				continue
+			}
+
+			trash.Properties = append(trash.Properties, property)
+		}
+
+		cursor = nextCursor(resp.Meta)
+		if cursor == "" {
+			return trash, nil
+		}
+	}
+}
+
+// Undelete restores a property from the trash via estate service.
+func (r *APIPropertyRepo) Undelete(ctx context.Context, id uuid.UUID) error {
+	path := fmt.Sprintf("/estates/%s/restore", id.String())
+	if _, err := r.client.Request(ctx, "POST", path, nil); err != nil {
+		return fmt.Errorf("failed to restore deleted property: %w", err)
+	}
+
+	return nil
+}
+
 // ListByOwner retrieves properties filtered by owner from estate service.
 func (r *APIPropertyRepo) ListByOwner(ctx context.Context, ownerID string) ([]*Property, error) {
 	// Use the List method and filter client-side for now
@@ -147,6 +300,87 @@ func (r *APIPropertyRepo) ListByStatus(ctx context.Context, status string) ([]*P
 	return filtered, nil
 }
 
+// Export streams a property export from estate service.
+func (r *APIPropertyRepo) Export(ctx context.Context, format string, filters url.Values) (*PropertyExport, error) {
+	query := url.Values{}
+	for key, values := range filters {
+		query[key] = values
+	}
+	query.Set("format", format)
+
+	resp, err := r.client.Stream(ctx, "/estates/export?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export properties: %w", err)
+	}
+
+	filename := "properties." + format
+	if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil && params["filename"] != "" {
+		filename = params["filename"]
+	}
+	return &PropertyExport{
+		Body:        resp.Body,
+		ContentType: resp.Header.Get("Content-Type"),
+		Filename:    filename,
+	}, nil
+}
+
+// ListAppointments retrieves the viewings overlapping a time range from
+// estate service, at most listPageSize of them. Appointments at properties
+// the user cannot read are left out by the service.
+func (r *APIPropertyRepo) ListAppointments(ctx context.Context, from, to time.Time, agentID string) ([]*Appointment, error) {
+	query := url.Values{}
+	query.Set("from", from.UTC().Format(time.RFC3339))
+	query.Set("to", to.UTC().Format(time.RFC3339))
+	query.Set("limit", strconv.Itoa(listPageSize))
+	if agentID != "" {
+		query.Set("agent", agentID)
+	}
+
+	resp, err := r.client.List(ctx, "appointments?"+query.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list appointments: %w", err)
+	}
+
+	names := map[string]interface{}{}
+	if meta, ok := resp.Meta.(map[string]interface{}); ok {
+		names, _ = meta["properties"].(map[string]interface{})
+	}
+
+	appointments := []*Appointment{}
+	items, _ := resp.Data.([]interface{})
+	for _, item := range items {
+		data, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		appointment, err := parseAppointmentFromMap(data)
+		if err != nil {
+			continue
+		}
+		appointment.PropertyName, _ = names[appointment.PropertyID.String()].(string)
+
+		appointments = append(appointments, appointment)
+	}
+
+	return appointments, nil
+}
+
+// CalendarFeed retrieves the iCalendar link of an agent from estate
+// service.
+func (r *APIPropertyRepo) CalendarFeed(ctx context.Context, agentID string) (string, error) {
+	resp, err := r.client.Request(ctx, "GET", "/agents/"+url.PathEscape(agentID)+"/calendar", nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to get calendar feed: %w", err)
+	}
+
+	data, ok := resp.Data.(map[string]interface{})
+	if !ok {
+		return "", fmt.Errorf("invalid response format")
+	}
+	return stringField(data, "url"), nil
+}
+
 // Helper functions
 
 func parsePropertyFromMap(data map[string]interface{}) (*Property, error) {
@@ -166,7 +400,13 @@ func parsePropertyFromMap(data map[string]interface{}) (*Property, error) {
 		Description:   stringField(data, "description"),
 		Status:        stringField(data, "status"),
 		OwnerID:       stringField(data, "owner_id"),
+		TeamID:        stringField(data, "team_id"),
 		SchemaVersion: intField(data, "schema_version"),
+		Revision:      int64(floatField(data, "revision")),
+		DeletedBy:     stringField(data, "deleted_by"),
+	}
+	if deletedAt, err := time.Parse(time.RFC3339Nano, stringField(data, "deleted_at")); err == nil {
+		property.DeletedAt = &deletedAt
 	}
 
 	// Parse classification
@@ -269,6 +509,95 @@ func parsePropertyFromMap(data map[string]interface{}) (*Property, error) {
 	return property, nil
 }
 
+// parseDuplicateConflict reads the likely duplicates from the body of a
+// create rejected by estate, or returns nil when it lists none.
+func parseDuplicateConflict(body string) *PropertyDuplicateError {
+	var conflict struct {
+		Duplicates []struct {
+			ID       uuid.UUID              `json:"id"`
+			Score    float64                `json:"score"`
+			Reasons  []string               `json:"reasons"`
+			Property map[string]interface{} `json:"property"`
+		} `json:"duplicates"`
+	}
+	if err := json.Unmarshal([]byte(body), &conflict); err != nil || len(conflict.Duplicates) == 0 {
+		return nil
+	}
+
+	dupErr := &PropertyDuplicateError{}
+	for _, d := range conflict.Duplicates {
+		dup := PropertyDuplicate{ID: d.ID, Score: d.Score, Reasons: d.Reasons}
+		if d.Property != nil {
+			dup.Property, _ = parsePropertyFromMap(d.Property)
+		}
+		dupErr.Duplicates = append(dupErr.Duplicates, dup)
+	}
+	return dupErr
+}
+
+func parseAppointmentFromMap(data map[string]interface{}) (*Appointment, error) {
+	id, err := uuid.Parse(stringField(data, "id"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid appointment ID: %w", err)
+	}
+	propertyID, err := uuid.Parse(stringField(data, "property_id"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid appointment property ID: %w", err)
+	}
+
+	appointment := &Appointment{
+		ID:         id,
+		PropertyID: propertyID,
+		AgentID:    stringField(data, "agent_id"),
+		StartsAt:   timeField(data, "starts_at"),
+		EndsAt:     timeField(data, "ends_at"),
+		Status:     stringField(data, "status"),
+		Notes:      stringField(data, "notes"),
+	}
+	if client, ok := data["client"].(map[string]interface{}); ok {
+		appointment.ClientName = stringField(client, "name")
+		appointment.ClientEmail = stringField(client, "email")
+		appointment.ClientPhone = stringField(client, "phone")
+	}
+	return appointment, nil
+}
+
+func parseRevisionFromMap(data map[string]interface{}) PropertyRevision {
+	rev := PropertyRevision{
+		Number:       int64(floatField(data, "number")),
+		Action:       stringField(data, "action"),
+		Actor:        stringField(data, "actor"),
+		RestoredFrom: int64(floatField(data, "restored_from")),
+	}
+	rev.At, _ = time.Parse(time.RFC3339Nano, stringField(data, "at"))
+
+	changes, _ := data["changes"].([]interface{})
+	for _, item := range changes {
+		change, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		rev.Changes = append(rev.Changes, PropertyFieldChange{
+			Path: stringField(change, "path"),
+			From: formatChangeValue(change["from"]),
+			To:   formatChangeValue(change["to"]),
+		})
+	}
+
+	return rev
+}
+
+func formatChangeValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
 func intField(data map[string]interface{}, key string) int {
 	if v, ok := data[key].(float64); ok {
 		return int(v)
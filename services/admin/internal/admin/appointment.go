@@ -0,0 +1,60 @@
+package admin
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Appointment is a viewing of a property scheduled in the estate service.
+type Appointment struct {
+	ID           uuid.UUID
+	PropertyID   uuid.UUID
+	PropertyName string // Empty when the property is not readable
+	AgentID      string
+	ClientName   string
+	ClientEmail  string
+	ClientPhone  string
+	StartsAt     time.Time
+	EndsAt       time.Time
+	Status       string // scheduled, confirmed, completed, cancelled or no_show
+	Notes        string
+}
+
+// CalendarDay is a day of the appointments calendar.
+type CalendarDay struct {
+	Date         time.Time
+	Today        bool
+	Appointments []*Appointment
+}
+
+// NewCalendarWeek returns the seven days of the week starting on Monday
+// that contains day, with the appointments starting on each.
+func NewCalendarWeek(day time.Time, appointments []*Appointment, now time.Time) []CalendarDay {
+	start := WeekStart(day)
+	days := make([]CalendarDay, 7)
+	for i := range days {
+		date := start.AddDate(0, 0, i)
+		days[i] = CalendarDay{Date: date, Today: sameDay(date, now), Appointments: []*Appointment{}}
+	}
+	for _, a := range appointments {
+		starts := a.StartsAt.In(start.Location())
+		if i := int(starts.Sub(start).Hours() / 24); i >= 0 && i < len(days) && sameDay(days[i].Date, starts) {
+			days[i].Appointments = append(days[i].Appointments, a)
+		}
+	}
+	return days
+}
+
+// WeekStart returns midnight on the Monday of the week of day.
+func WeekStart(day time.Time) time.Time {
+	offset := (int(day.Weekday()) + 6) % 7
+	y, m, d := day.AddDate(0, 0, -offset).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, day.Location())
+}
+
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.In(a.Location()).Date()
+	return ay == by && am == bm && ad == bd
+}
@@ -0,0 +1,81 @@
+package admin
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Calendar displays the viewings of a week, from Monday, of every agent or
+// of the one in ?agent=, with the link to subscribe to the agent calendar
+func (h *Handler) Calendar(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.http.Start(w, r, "Handler.Calendar")
+	defer finish()
+	log := h.log(r)
+
+	ctx := r.Context()
+	now := time.Now()
+	week := WeekStart(now)
+	if param := r.URL.Query().Get("week"); param != "" {
+		day, err := time.ParseInLocation(time.DateOnly, param, time.Local)
+		if err != nil {
+			http.Error(w, "Invalid week", http.StatusBadRequest)
+			return
+		}
+		week = WeekStart(day)
+	}
+	agentID := r.URL.Query().Get("agent")
+
+	appointments, err := h.service.ListAppointments(ctx, week, week.AddDate(0, 0, 7), agentID)
+	if err != nil {
+		log.Error("error listing appointments", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	// The feed of the agent shown, or of the user when showing everyone
+	feedAgent := agentID
+	if feedAgent == "" {
+		feedAgent, _ = GetUserID(ctx)
+	}
+	var feedURL string
+	if feedAgent != "" {
+		if feedURL, err = h.service.AgentCalendarFeed(ctx, feedAgent); err != nil {
+			log.Debug("calendar feed unavailable", "error", err, "agent_id", feedAgent)
+		}
+	}
+
+	tmpl, err := h.tmplMgr.Get("calendar.html")
+	if err != nil {
+		log.Error("error getting template", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	data := map[string]interface{}{
+		"Title":     "Calendar",
+		"Days":      NewCalendarWeek(week, appointments, now),
+		"Week":      week,
+		"Agent":     agentID,
+		"PrevURL":   calendarURL(week.AddDate(0, 0, -7), agentID),
+		"NextURL":   calendarURL(week.AddDate(0, 0, 7), agentID),
+		"TodayURL":  calendarURL(now, agentID),
+		"FeedAgent": feedAgent,
+		"FeedURL":   feedURL,
+		"ActiveNav": "calendar",
+		"Template":  "calendar-content",
+	}
+
+	if err := tmpl.ExecuteTemplate(w, "calendar.html", data); err != nil {
+		log.Error("error executing template", "error", err)
+	}
+}
+
+func calendarURL(week time.Time, agentID string) string {
+	query := url.Values{}
+	query.Set("week", WeekStart(week).Format(time.DateOnly))
+	if agentID != "" {
+		query.Set("agent", agentID)
+	}
+	return "/calendar?" + query.Encode()
+}
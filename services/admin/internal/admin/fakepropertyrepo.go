@@ -3,6 +3,8 @@ package admin
 import (
 	"context"
 	"fmt"
+	"net/url"
+	"slices"
 	"sync"
 	"time"
 
@@ -11,14 +13,25 @@ import (
 
 // FakePropertyRepo provides an in-memory implementation of PropertyRepo for development.
 type FakePropertyRepo struct {
-	properties map[uuid.UUID]*Property
-	mutex      sync.RWMutex
+	properties   map[uuid.UUID]*Property
+	deleted      map[uuid.UUID]*Property
+	revisions    map[uuid.UUID][]fakeRevision
+	appointments []*Appointment
+	mutex        sync.RWMutex
+}
+
+// fakeRevision is a revision with the snapshot needed to restore it.
+type fakeRevision struct {
+	PropertyRevision
+	snapshot Property
 }
 
 // NewFakePropertyRepo creates a new fake property repository with seed data.
 func NewFakePropertyRepo() *FakePropertyRepo {
 	repo := &FakePropertyRepo{
 		properties: make(map[uuid.UUID]*Property),
+		deleted:    make(map[uuid.UUID]*Property),
+		revisions:  make(map[uuid.UUID][]fakeRevision),
 	}
 	repo.seedProperties()
 	return repo
@@ -254,7 +267,42 @@ func (r *FakePropertyRepo) seedProperties() {
 	}
 
 	for _, prop := range properties {
+		prop.Revision = 1
 		r.properties[prop.ID] = prop
+		r.record("create", nil, prop, prop.CreatedBy)
+	}
+	r.seedAppointments(properties)
+}
+
+// seedAppointments schedules a few viewings of the seeded properties this
+// week.
+func (r *FakePropertyRepo) seedAppointments(properties []*Property) {
+	monday := WeekStart(time.Now())
+	slots := []struct {
+		day, hour int
+		agent     string
+		client    string
+		status    string
+	}{
+		{0, 10, "agent-001", "Marta Ruiz", "confirmed"},
+		{0, 12, "agent-001", "Jan Kowalski", "scheduled"},
+		{2, 17, "agent-002", "Lucía Ferrer", "scheduled"},
+		{4, 11, "agent-001", "Tom Baker", "cancelled"},
+	}
+	for i, slot := range slots {
+		property := properties[i%len(properties)]
+		starts := monday.AddDate(0, 0, slot.day).Add(time.Duration(slot.hour) * time.Hour)
+		r.appointments = append(r.appointments, &Appointment{
+			ID:           uuid.New(),
+			PropertyID:   property.ID,
+			PropertyName: property.Name,
+			AgentID:      slot.agent,
+			ClientName:   slot.client,
+			ClientEmail:  "client@example.com",
+			StartsAt:     starts,
+			EndsAt:       starts.Add(45 * time.Minute),
+			Status:       slot.status,
+		})
 	}
 }
 
@@ -272,6 +320,8 @@ func (r *FakePropertyRepo) Create(ctx context.Context, req *CreatePropertyReques
 		Prices:         req.Prices,
 		Status:         req.Status,
 		OwnerID:        req.OwnerID,
+		TeamID:         req.TeamID,
+		Revision:       1,
 		CreatedAt:      time.Now(),
 		CreatedBy:      "admin", // TODO: Get from context
 		UpdatedAt:      time.Now(),
@@ -283,6 +333,7 @@ func (r *FakePropertyRepo) Create(ctx context.Context, req *CreatePropertyReques
 	}
 
 	r.properties[property.ID] = property
+	r.record("create", nil, property, property.CreatedBy)
 	return property, nil
 }
 
@@ -318,6 +369,10 @@ func (r *FakePropertyRepo) Update(ctx context.Context, id uuid.UUID, req *Update
 	if !exists {
 		return nil, fmt.Errorf("property with id %s not found", id.String())
 	}
+	if req.Revision != 0 && req.Revision != property.Revision {
+		return nil, ErrPropertyConflict
+	}
+	before := *property
 
 	property.Name = req.Name
 	property.Description = req.Description
@@ -327,24 +382,173 @@ func (r *FakePropertyRepo) Update(ctx context.Context, id uuid.UUID, req *Update
 	property.Prices = req.Prices
 	property.Status = req.Status
 	property.OwnerID = req.OwnerID
+	property.TeamID = req.TeamID
+	property.Revision++
 	property.UpdatedAt = time.Now()
 	property.UpdatedBy = "admin" // TODO: Get from context
 
+	r.record("update", &before, property, property.UpdatedBy)
 	return property, nil
 }
 
+func (r *FakePropertyRepo) Transition(ctx context.Context, id uuid.UUID, req *TransitionPropertyRequest) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	property, exists := r.properties[id]
+	if !exists {
+		return fmt.Errorf("property with id %s not found", id.String())
+	}
+	before := *property
+
+	property.Status = req.To
+	property.Revision++
+	property.UpdatedAt = time.Now()
+	property.UpdatedBy = fakeActor(ctx)
+
+	r.record("transition", &before, property, property.UpdatedBy)
+	return nil
+}
+
+func (r *FakePropertyRepo) Revisions(ctx context.Context, id uuid.UUID) ([]PropertyRevision, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	stored := r.revisions[id]
+	revisions := make([]PropertyRevision, 0, len(stored))
+	for i := len(stored) - 1; i >= 0; i-- {
+		revisions = append(revisions, stored[i].PropertyRevision)
+	}
+
+	return revisions, nil
+}
+
+func (r *FakePropertyRepo) Restore(ctx context.Context, id uuid.UUID, number int64) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	property, exists := r.properties[id]
+	if !exists {
+		return fmt.Errorf("property with id %s not found", id.String())
+	}
+
+	for _, rev := range r.revisions[id] {
+		if rev.Number != number {
+			continue
+		}
+		before := *property
+
+		restored := rev.snapshot
+		restored.Status = property.Status
+		restored.Revision = property.Revision + 1
+		restored.CreatedAt = property.CreatedAt
+		restored.CreatedBy = property.CreatedBy
+		restored.UpdatedAt = time.Now()
+		restored.UpdatedBy = fakeActor(ctx)
+		*property = restored
+
+		r.record("restore", &before, property, restored.UpdatedBy)
+		r.revisions[id][len(r.revisions[id])-1].RestoredFrom = number
+		return nil
+	}
+
+	return fmt.Errorf("revision %d of property %s not found", number, id.String())
+}
+
+// fakeActor returns the signed in user, as the estate service takes it from
+// the forwarded token.
+func fakeActor(ctx context.Context) string {
+	if actor, ok := GetUserID(ctx); ok && actor != "" {
+		return actor
+	}
+	return "admin"
+}
+
+// record appends the revision of a write; callers hold the lock.
+func (r *FakePropertyRepo) record(action string, before, after *Property, actor string) {
+	rev := fakeRevision{
+		PropertyRevision: PropertyRevision{
+			Number: after.Revision,
+			Action: action,
+			Actor:  actor,
+			At:     after.UpdatedAt,
+		},
+		snapshot: *after,
+	}
+
+	if before != nil {
+		for _, d := range diffProperty(updateRequestFor(before), after) {
+			rev.Changes = append(rev.Changes, PropertyFieldChange{Path: d.Field, From: d.Yours, To: d.Current})
+		}
+	}
+
+	r.revisions[after.ID] = append(r.revisions[after.ID], rev)
+}
+
+func updateRequestFor(p *Property) *UpdatePropertyRequest {
+	return &UpdatePropertyRequest{
+		Name:           p.Name,
+		Description:    p.Description,
+		Classification: p.Classification,
+		Location:       p.Location,
+		Features:       p.Features,
+		Prices:         p.Prices,
+		Status:         p.Status,
+		OwnerID:        p.OwnerID,
+		TeamID:         p.TeamID,
+	}
+}
+
 func (r *FakePropertyRepo) Delete(ctx context.Context, id uuid.UUID) error {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
-	if _, exists := r.properties[id]; !exists {
+	property, exists := r.properties[id]
+	if !exists {
 		return fmt.Errorf("property with id %s not found", id.String())
 	}
 
+	now := time.Now()
+	property.DeletedAt = &now
+	property.DeletedBy = fakeActor(ctx)
+	property.Revision++
+	r.deleted[id] = property
 	delete(r.properties, id)
 	return nil
 }
 
+func (r *FakePropertyRepo) ListTrash(ctx context.Context) (*PropertyTrash, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	properties := make([]*Property, 0, len(r.deleted))
+	for _, prop := range r.deleted {
+		properties = append(properties, prop)
+	}
+	slices.SortFunc(properties, func(a, b *Property) int { return b.DeletedAt.Compare(*a.DeletedAt) })
+
+	return &PropertyTrash{Properties: properties, RetentionDays: 30}, nil
+}
+
+func (r *FakePropertyRepo) Undelete(ctx context.Context, id uuid.UUID) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	property, exists := r.deleted[id]
+	if !exists {
+		return fmt.Errorf("deleted property with id %s not found", id.String())
+	}
+
+	property.DeletedAt = nil
+	property.DeletedBy = ""
+	property.Revision++
+	property.UpdatedAt = time.Now()
+	property.UpdatedBy = fakeActor(ctx)
+	r.properties[id] = property
+	delete(r.deleted, id)
+	return nil
+}
+
 func (r *FakePropertyRepo) ListByOwner(ctx context.Context, ownerID string) ([]*Property, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
@@ -359,6 +563,30 @@ func (r *FakePropertyRepo) ListByOwner(ctx context.Context, ownerID string) ([]*
 	return properties, nil
 }
 
+func (r *FakePropertyRepo) ListAppointments(ctx context.Context, from, to time.Time, agentID string) ([]*Appointment, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	appointments := make([]*Appointment, 0)
+	for _, a := range r.appointments {
+		if a.StartsAt.Before(to) && a.EndsAt.After(from) && (agentID == "" || a.AgentID == agentID) {
+			appointments = append(appointments, a)
+		}
+	}
+	slices.SortFunc(appointments, func(a, b *Appointment) int { return a.StartsAt.Compare(b.StartsAt) })
+
+	return appointments, nil
+}
+
+func (r *FakePropertyRepo) CalendarFeed(ctx context.Context, agentID string) (string, error) {
+	return "/agents/" + url.PathEscape(agentID) + "/calendar.ics?token=fake", nil
+}
+
+// Export is not available in the fake repository.
+func (r *FakePropertyRepo) Export(ctx context.Context, format string, filters url.Values) (*PropertyExport, error) {
+	return nil, ErrPropertyExportUnavailable
+}
+
 func (r *FakePropertyRepo) ListByStatus(ctx context.Context, status string) ([]*Property, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
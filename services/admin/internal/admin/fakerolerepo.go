@@ -47,6 +47,7 @@ func (r *FakeRoleRepo) seedRoles() {
 				"users:write",
 				"estates:read",
 				"estates:write",
+				"estates:delete",
 				"estates:manage",
 				"grants:write",
 			},
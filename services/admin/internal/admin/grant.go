@@ -23,7 +23,7 @@ type Grant struct {
 
 // Scope represents the context scope for a grant
 type Scope struct {
-	Type string `json:"type"` // "global", "team", "organization"
+	Type string `json:"type"` // "global", "team", "organization", "estate"
 	ID   string `json:"id"`   // Specific ID or empty for global
 }
 
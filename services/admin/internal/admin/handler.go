@@ -106,14 +106,19 @@ func (h *Handler) RegisterRoutes(r chi.Router) {
 
 		h.log().Info("Registering property management routes...")
 		r.Get("/list-properties", h.ListProperties)
+		r.Get("/export-properties", h.ExportProperties)
 		r.Get("/new-property", h.NewProperty)
 		r.Post("/create-property", h.CreateProperty)
 		r.Get("/show-property/{id}", h.ShowProperty)
 		r.Get("/edit-property/{id}", h.EditProperty)
 		r.Post("/update-property/{id}", h.UpdateProperty)
 		r.Post("/delete-property/{id}", h.DeleteProperty)
+		r.Get("/list-trash", h.ListTrash)
+		r.Post("/undelete-property/{id}", h.UndeleteProperty)
+		r.Post("/restore-property/{id}/{n}", h.RestorePropertyRevision)
 		r.Get("/properties/locations/suggest", h.SuggestLocations)
 		r.Post("/properties/locations/normalize", h.HTMXNormalizeLocation)
+		r.Get("/calendar", h.Calendar)
 
 		// HTMX endpoints for cascading selects
 		r.Get("/htmx/types-by-category", h.HTMXTypesByCategory)
@@ -185,9 +190,16 @@ func (h *Handler) HandleSignIn(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	token, err := extractToken(resp.Data)
+	if err != nil {
+		h.log(r).Error("cannot extract token from signin response", "error", err)
+		http.Error(w, "Authentication service error", http.StatusInternalServerError)
+		return
+	}
+
 	http.SetCookie(w, &http.Cookie{
 		Name:     sessionCookieName,
-		Value:    userID,
+		Value:    token,
 		Path:     "/",
 		HttpOnly: true,
 		SameSite: http.SameSiteLaxMode,
@@ -303,6 +315,20 @@ func extractUserID(data interface{}) (map[string]interface{}, string, error) {
 	return userData, idStr, nil
 }
 
+func extractToken(data interface{}) (string, error) {
+	payload, ok := data.(map[string]interface{})
+	if !ok {
+		return "", fmt.Errorf("unexpected signin payload type %T", data)
+	}
+
+	token, ok := payload["token"].(string)
+	if !ok || token == "" {
+		return "", fmt.Errorf("signin payload missing token")
+	}
+
+	return token, nil
+}
+
 func sanitizeRedirect(target string) string {
 	if target == "" {
 		return "/"
@@ -1032,12 +1058,10 @@ func (h *Handler) SetSessionValidator(validator func(string) (string, error)) {
 	h.sessionValidator = validator
 }
 
+// defaultSessionValidator rejects every session until a token validator is
+// set with SetSessionValidator.
 func defaultSessionValidator() func(string) (string, error) {
 	return func(sessionID string) (string, error) {
-		if strings.TrimSpace(sessionID) == "" {
-			return "", errors.New("invalid session")
-		}
-		// TODO: Integrate with AuthN service to resolve user ID from session token.
-		return sessionID, nil
+		return "", errors.New("session validation not configured")
 	}
 }
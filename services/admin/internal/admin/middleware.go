@@ -4,6 +4,8 @@ import (
 	"context"
 	"net/http"
 	"net/url"
+
+	"github.com/pulap/pulap/pkg/lib/core"
 )
 
 const (
@@ -19,6 +21,8 @@ const (
 
 // SessionMiddleware handles session validation and user context injection.
 // It doesn't deal with permissions (AuthZ), only authentication (AuthN).
+// The session cookie holds the authn session token of the user, which is
+// also attached to the context so service clients act as that user.
 func SessionMiddleware(sessionValidator func(sessionID string) (string, error)) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -36,6 +40,7 @@ func SessionMiddleware(sessionValidator func(sessionID string) (string, error))
 			}
 
 			ctx := context.WithValue(r.Context(), UserIDContextKey, userID)
+			ctx = core.WithBearerToken(ctx, cookie.Value)
 			actor := Actor{
 				ID:     userID,
 				Grants: []string{"*:*"},
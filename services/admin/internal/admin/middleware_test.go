@@ -0,0 +1,42 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pulap/pulap/pkg/lib/core"
+)
+
+func TestSessionMiddlewareActsAsSessionUser(t *testing.T) {
+	validator := func(token string) (string, error) {
+		if token != "token-of-ana" {
+			return "", errors.New("invalid token")
+		}
+		return "user-ana", nil
+	}
+
+	var userID, token string
+	handler := SessionMiddleware(validator)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, _ = GetUserID(r.Context())
+		token = core.BearerTokenFrom(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/properties", nil)
+	req.AddCookie(&http.Cookie{Name: sessionCookieName, Value: "token-of-ana"})
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if userID != "user-ana" || token != "token-of-ana" {
+		t.Errorf("expected user-ana acting with their token, got %q and %q", userID, token)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/properties", nil)
+	req.AddCookie(&http.Cookie{Name: sessionCookieName, Value: "expired"})
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusFound || rec.Header().Get("Location") != "/signin?next=%2Fproperties" {
+		t.Errorf("expected redirect to signin, got %d %q", rec.Code, rec.Header().Get("Location"))
+	}
+}
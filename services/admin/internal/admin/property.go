@@ -1,6 +1,7 @@
 package admin
 
 import (
+	"io"
 	"time"
 
 	"github.com/google/uuid"
@@ -18,11 +19,15 @@ type Property struct {
 	Prices         []Price        `json:"prices"`
 	Status         string         `json:"status"`
 	OwnerID        string         `json:"owner_id,omitempty"`
+	TeamID         string         `json:"team_id,omitempty"`
 	SchemaVersion  int            `json:"schema_version"`
+	Revision       int64          `json:"revision"`
 	CreatedAt      time.Time      `json:"created_at"`
 	CreatedBy      string         `json:"created_by"`
 	UpdatedAt      time.Time      `json:"updated_at"`
 	UpdatedBy      string         `json:"updated_by"`
+	DeletedAt      *time.Time     `json:"deleted_at,omitempty"` // Set while the property is in the trash
+	DeletedBy      string         `json:"deleted_by,omitempty"`
 }
 
 const CurrentPropertySchemaVersion = 3
@@ -111,7 +116,9 @@ type CreatePropertyRequest struct {
 	Prices         []Price        `json:"prices"`
 	Status         string         `json:"status,omitempty"`
 	OwnerID        string         `json:"owner_id,omitempty"`
+	TeamID         string         `json:"team_id,omitempty"`
 	SchemaVersion  int            `json:"schema_version,omitempty"`
+	AllowDuplicate bool           `json:"-"` // Create even if it likely duplicates existing properties
 }
 
 // UpdatePropertyRequest represents a request to update an existing property.
@@ -124,5 +131,49 @@ type UpdatePropertyRequest struct {
 	Prices         []Price        `json:"prices"`
 	Status         string         `json:"status"`
 	OwnerID        string         `json:"owner_id,omitempty"`
+	TeamID         string         `json:"team_id,omitempty"`
 	SchemaVersion  int            `json:"schema_version,omitempty"`
+	Revision       int64          `json:"-"` // Revision the edit is based on, sent as If-Match
+}
+
+// TransitionPropertyRequest represents a request to change the status of a property.
+type TransitionPropertyRequest struct {
+	To     string `json:"to"`
+	Reason string `json:"reason"`
+}
+
+// PropertyRevision is an entry of the property revision history kept by the
+// estate service, without its snapshot.
+type PropertyRevision struct {
+	Number       int64                 `json:"number"`
+	Action       string                `json:"action"` // create, update, restore, transition or delete
+	Actor        string                `json:"actor"`
+	At           time.Time             `json:"at"`
+	RestoredFrom int64                 `json:"restored_from,omitempty"`
+	Changes      []PropertyFieldChange `json:"changes"`
+}
+
+// PropertyFieldChange is a field changed by a revision. Path is a JSON
+// Pointer into the property; values are formatted for display.
+type PropertyFieldChange struct {
+	Path string `json:"path"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// PropertyExport is a property export file being downloaded from the estate
+// service. Body must be closed.
+type PropertyExport struct {
+	Body        io.ReadCloser
+	ContentType string
+	Filename    string
+}
+
+// PropertyExportFormats lists the formats the estate service exports.
+var PropertyExportFormats = []string{"csv", "geojson", "xlsx"}
+
+// PropertyTrash lists the deleted properties, most recently deleted first.
+type PropertyTrash struct {
+	Properties    []*Property
+	RetentionDays int // Days before deleted properties are purged, 0 for never
 }
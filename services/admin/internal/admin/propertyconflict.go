@@ -0,0 +1,103 @@
+package admin
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// PropertyFieldDiff is a field whose submitted value differs from the
+// currently stored one, shown on the edit conflict page.
+type PropertyFieldDiff struct {
+	Field   string
+	Yours   string
+	Current string
+}
+
+// FormField is a submitted form value carried over by the conflict page.
+type FormField struct {
+	Name  string
+	Value string
+}
+
+// diffProperty lists the fields where the submitted edit differs from the
+// current version of the property.
+func diffProperty(yours *UpdatePropertyRequest, current *Property) []PropertyFieldDiff {
+	fields := []struct {
+		name           string
+		yours, current string
+	}{
+		{"Name", yours.Name, current.Name},
+		{"Description", yours.Description, current.Description},
+		{"Status", yours.Status, current.Status},
+		{"Category", formatOptionalID(yours.Classification.CategoryID), formatOptionalID(current.Classification.CategoryID)},
+		{"Type", formatOptionalID(yours.Classification.TypeID), formatOptionalID(current.Classification.TypeID)},
+		{"Subtype", formatOptionalID(yours.Classification.SubtypeID), formatOptionalID(current.Classification.SubtypeID)},
+		{"Street", yours.Location.Address.Street, current.Location.Address.Street},
+		{"Number", yours.Location.Address.Number, current.Location.Address.Number},
+		{"Unit", yours.Location.Address.Unit, current.Location.Address.Unit},
+		{"City", yours.Location.Address.City, current.Location.Address.City},
+		{"State", yours.Location.Address.State, current.Location.Address.State},
+		{"Postal code", yours.Location.Address.PostalCode, current.Location.Address.PostalCode},
+		{"Country", yours.Location.Address.Country, current.Location.Address.Country},
+		{"Coordinates", formatCoordinates(yours.Location.Coordinates), formatCoordinates(current.Location.Coordinates)},
+		{"Region", yours.Location.Region, current.Location.Region},
+		{"Total area", fmt.Sprintf("%g", yours.Features.TotalArea), fmt.Sprintf("%g", current.Features.TotalArea)},
+		{"Bedrooms", fmt.Sprint(yours.Features.Bedrooms), fmt.Sprint(current.Features.Bedrooms)},
+		{"Bathrooms", fmt.Sprint(yours.Features.Bathrooms), fmt.Sprint(current.Features.Bathrooms)},
+		{"Parking", fmt.Sprint(yours.Features.Parking), fmt.Sprint(current.Features.Parking)},
+		{"Prices", formatPrices(yours.Prices), formatPrices(current.Prices)},
+		{"Owner ID", yours.OwnerID, current.OwnerID},
+		{"Team ID", yours.TeamID, current.TeamID},
+	}
+
+	var diffs []PropertyFieldDiff
+	for _, f := range fields {
+		if f.yours != f.current {
+			diffs = append(diffs, PropertyFieldDiff{Field: f.name, Yours: f.yours, Current: f.current})
+		}
+	}
+	return diffs
+}
+
+// conflictFormFields returns the submitted form values, except the revision,
+// in a stable order so the conflict page can resubmit them.
+func conflictFormFields(form url.Values) []FormField {
+	var fields []FormField
+	for name, values := range form {
+		if name == "revision" {
+			continue
+		}
+		for _, value := range values {
+			fields = append(fields, FormField{Name: name, Value: value})
+		}
+	}
+	sort.SliceStable(fields, func(i, j int) bool { return fields[i].Name < fields[j].Name })
+	return fields
+}
+
+func formatOptionalID(id uuid.UUID) string {
+	if id == uuid.Nil {
+		return ""
+	}
+	return id.String()
+}
+
+func formatCoordinates(c Coordinates) string {
+	if c.Latitude == 0 && c.Longitude == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%.6f, %.6f", c.Latitude, c.Longitude)
+}
+
+func formatPrices(prices []Price) string {
+	parts := make([]string, 0, len(prices))
+	for _, p := range prices {
+		parts = append(parts, fmt.Sprintf("%s %.2f %s", p.Type, p.Amount, p.Currency))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, "; ")
+}
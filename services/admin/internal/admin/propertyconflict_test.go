@@ -0,0 +1,55 @@
+package admin
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestDiffProperty(t *testing.T) {
+	current := &Property{
+		Name:     "Loft Palermo",
+		Status:   "available",
+		Location: Location{Address: Address{City: "Buenos Aires"}},
+		Features: Features{Bedrooms: 2},
+		Prices:   []Price{{Type: "sale", Amount: 100000, Currency: "USD"}},
+	}
+	yours := &UpdatePropertyRequest{
+		Name:     "Loft Palermo Soho",
+		Status:   "available",
+		Location: Location{Address: Address{City: "Buenos Aires"}},
+		Features: Features{Bedrooms: 3},
+		Prices:   []Price{{Type: "sale", Amount: 100000, Currency: "USD"}},
+	}
+
+	diffs := diffProperty(yours, current)
+
+	if len(diffs) != 2 {
+		t.Fatalf("expected 2 diffs, got %+v", diffs)
+	}
+	if diffs[0] != (PropertyFieldDiff{Field: "Name", Yours: "Loft Palermo Soho", Current: "Loft Palermo"}) {
+		t.Errorf("unexpected name diff: %+v", diffs[0])
+	}
+	if diffs[1] != (PropertyFieldDiff{Field: "Bedrooms", Yours: "3", Current: "2"}) {
+		t.Errorf("unexpected bedrooms diff: %+v", diffs[1])
+	}
+}
+
+func TestConflictFormFieldsSkipsRevision(t *testing.T) {
+	form := url.Values{
+		"revision": {"3"},
+		"name":     {"Loft"},
+		"bedrooms": {"2"},
+	}
+
+	fields := conflictFormFields(form)
+
+	want := []FormField{{Name: "bedrooms", Value: "2"}, {Name: "name", Value: "Loft"}}
+	if len(fields) != len(want) {
+		t.Fatalf("expected %d fields, got %+v", len(want), fields)
+	}
+	for i := range want {
+		if fields[i] != want[i] {
+			t.Errorf("field %d: expected %+v, got %+v", i, want[i], fields[i])
+		}
+	}
+}
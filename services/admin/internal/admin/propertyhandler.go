@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -211,10 +213,17 @@ func (h *Handler) CreateProperty(w http.ResponseWriter, r *http.Request) {
 		Prices:        prices,
 		Status:        strings.TrimSpace(r.FormValue("status")),
 		OwnerID:       strings.TrimSpace(r.FormValue("owner_id")),
+		TeamID:        strings.TrimSpace(r.FormValue("team_id")),
 		SchemaVersion: CurrentPropertySchemaVersion,
 	}
+	req.AllowDuplicate, _ = strconv.ParseBool(r.FormValue("allow_duplicate"))
 
 	property, err := h.service.CreateProperty(ctx, req)
+	var dupErr *PropertyDuplicateError
+	if errors.As(err, &dupErr) {
+		h.renderPropertyDuplicates(w, r, req, dupErr.Duplicates)
+		return
+	}
 	if err != nil {
 		log.Error("error creating property", "error", err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
@@ -403,6 +412,11 @@ func (h *Handler) ShowProperty(w http.ResponseWriter, r *http.Request) {
 		log.Error("error fetching price types", "error", err)
 	}
 
+	revisions, err := h.service.ListPropertyRevisions(ctx, id)
+	if err != nil {
+		log.Error("error fetching property revisions", "error", err, "id", id)
+	}
+
 	tmpl, err := h.tmplMgr.Get("show-property.html")
 	if err != nil {
 		log.Error("error getting template", "error", err)
@@ -416,6 +430,7 @@ func (h *Handler) ShowProperty(w http.ResponseWriter, r *http.Request) {
 		"ActiveNav":       "properties",
 		"Template":        "show-property",
 		"PriceTypeLabels": map[string]string{},
+		"Revisions":       revisions,
 	}
 
 	if priceTypes != nil {
@@ -602,20 +617,166 @@ func (h *Handler) UpdateProperty(w http.ResponseWriter, r *http.Request) {
 		Prices:        prices,
 		Status:        strings.TrimSpace(r.FormValue("status")),
 		OwnerID:       strings.TrimSpace(r.FormValue("owner_id")),
+		TeamID:        strings.TrimSpace(r.FormValue("team_id")),
 		SchemaVersion: CurrentPropertySchemaVersion,
 	}
 
+	req.Revision, _ = strconv.ParseInt(r.FormValue("revision"), 10, 64)
+
+	current, err := h.service.GetProperty(ctx, id)
+	if err != nil {
+		log.Error("error loading property", "error", err, "id", id)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	// Status changes go through the estate lifecycle once the remaining fields are saved.
+	status := req.Status
+	req.Status = current.Status
+
+	if req.Revision != 0 && req.Revision != current.Revision {
+		req.Status = status
+		h.renderPropertyConflict(w, r, req, current)
+		return
+	}
+
 	_, err = h.service.UpdateProperty(ctx, id, req)
+	if errors.Is(err, ErrPropertyConflict) {
+		current, err = h.service.GetProperty(ctx, id)
+		if err != nil {
+			log.Error("error loading property", "error", err, "id", id)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		req.Status = status
+		h.renderPropertyConflict(w, r, req, current)
+		return
+	}
 	if err != nil {
 		log.Error("error updating property", "error", err, "id", id)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		return
 	}
 
+	if status != "" && status != current.Status {
+		reason := strings.TrimSpace(r.FormValue("status_reason"))
+		if reason == "" {
+			reason = "Changed from admin"
+		}
+		transition := &TransitionPropertyRequest{
+			To:     status,
+			Reason: reason,
+		}
+		if err := h.service.TransitionProperty(ctx, id, transition); err != nil {
+			log.Info("property status change rejected", "error", err, "id", id, "from", current.Status, "to", status)
+			var httpErr *core.HTTPError
+			if errors.As(err, &httpErr) && httpErr.StatusCode < http.StatusInternalServerError {
+				http.Error(w, "Status change rejected: "+httpErr.Message, httpErr.StatusCode)
+				return
+			}
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+	}
+
 	log.Info("property updated successfully", "id", id)
 	http.Redirect(w, r, fmt.Sprintf("/show-property/%s", id), http.StatusSeeOther)
 }
 
+// renderPropertyConflict shows the fields where a rejected edit differs from
+// the current version, with the option to reapply the edit on top of it.
+func (h *Handler) renderPropertyConflict(w http.ResponseWriter, r *http.Request, yours *UpdatePropertyRequest, current *Property) {
+	log := h.log(r)
+	log.Info("property edit conflict", "id", current.ID, "revision", yours.Revision, "current_revision", current.Revision)
+
+	tmpl, err := h.tmplMgr.Get("conflict-property.html")
+	if err != nil {
+		log.Error("error getting template", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	data := map[string]interface{}{
+		"Title":      fmt.Sprintf("Conflict: %s", current.Name),
+		"Property":   current,
+		"Revision":   yours.Revision,
+		"Diffs":      diffProperty(yours, current),
+		"FormFields": conflictFormFields(r.PostForm),
+		"ActiveNav":  "properties",
+		"Template":   "conflict-property",
+	}
+
+	w.WriteHeader(http.StatusConflict)
+	if err := tmpl.ExecuteTemplate(w, "conflict-property.html", data); err != nil {
+		log.Error("error executing template", "error", err)
+	}
+}
+
+// renderPropertyDuplicates lists the existing properties a new one likely
+// duplicates, with the option to create it anyway.
+func (h *Handler) renderPropertyDuplicates(w http.ResponseWriter, r *http.Request, req *CreatePropertyRequest, duplicates []PropertyDuplicate) {
+	log := h.log(r)
+	log.Info("likely duplicate property", "name", req.Name, "duplicates", len(duplicates))
+
+	tmpl, err := h.tmplMgr.Get("duplicate-property.html")
+	if err != nil {
+		log.Error("error getting template", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	data := map[string]interface{}{
+		"Title":      fmt.Sprintf("Possible duplicate: %s", req.Name),
+		"Request":    req,
+		"Duplicates": duplicates,
+		"FormFields": conflictFormFields(r.PostForm),
+		"ActiveNav":  "properties",
+		"Template":   "duplicate-property",
+	}
+
+	w.WriteHeader(http.StatusConflict)
+	if err := tmpl.ExecuteTemplate(w, "duplicate-property.html", data); err != nil {
+		log.Error("error executing template", "error", err)
+	}
+}
+
+// RestorePropertyRevision handles restoring a property to a previous revision
+func (h *Handler) RestorePropertyRevision(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.http.Start(w, r, "Handler.RestorePropertyRevision")
+	defer finish()
+	log := h.log(r)
+
+	ctx := r.Context()
+	idStr := chi.URLParam(r, "id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		log.Error("invalid property id", "id", idStr)
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	number, err := strconv.ParseInt(chi.URLParam(r, "n"), 10, 64)
+	if err != nil || number < 1 {
+		log.Error("invalid revision number", "n", chi.URLParam(r, "n"))
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.RestorePropertyRevision(ctx, id, number); err != nil {
+		log.Info("property restore rejected", "error", err, "id", id, "revision", number)
+		var httpErr *core.HTTPError
+		if errors.As(err, &httpErr) && httpErr.StatusCode < http.StatusInternalServerError {
+			http.Error(w, "Restore rejected: "+httpErr.Message, httpErr.StatusCode)
+			return
+		}
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	log.Info("property revision restored", "id", id, "revision", number)
+	http.Redirect(w, r, fmt.Sprintf("/show-property/%s", id), http.StatusSeeOther)
+}
+
 // DeleteProperty handles deleting a property
 func (h *Handler) DeleteProperty(w http.ResponseWriter, r *http.Request) {
 	w, r, finish := h.http.Start(w, r, "Handler.DeleteProperty")
@@ -641,6 +802,110 @@ func (h *Handler) DeleteProperty(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/list-properties", http.StatusSeeOther)
 }
 
+// ListTrash displays the deleted properties, which can be restored until
+// they are purged
+func (h *Handler) ListTrash(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.http.Start(w, r, "Handler.ListTrash")
+	defer finish()
+	log := h.log(r)
+
+	ctx := r.Context()
+	trash, err := h.service.ListDeletedProperties(ctx)
+	if err != nil {
+		log.Error("error listing deleted properties", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	tmpl, err := h.tmplMgr.Get("list-trash.html")
+	if err != nil {
+		log.Error("error getting template", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	data := map[string]interface{}{
+		"Title":         "Trash",
+		"Properties":    trash.Properties,
+		"RetentionDays": trash.RetentionDays,
+		"ActiveNav":     "properties",
+		"Template":      "list-trash-content",
+	}
+
+	if err := tmpl.ExecuteTemplate(w, "list-trash.html", data); err != nil {
+		log.Error("error executing template", "error", err)
+	}
+}
+
+// UndeleteProperty handles restoring a property from the trash
+func (h *Handler) UndeleteProperty(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.http.Start(w, r, "Handler.UndeleteProperty")
+	defer finish()
+	log := h.log(r)
+
+	ctx := r.Context()
+	idStr := chi.URLParam(r, "id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		log.Error("invalid property id", "id", idStr)
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.RestoreDeletedProperty(ctx, id); err != nil {
+		log.Info("property undelete rejected", "error", err, "id", id)
+		var httpErr *core.HTTPError
+		if errors.As(err, &httpErr) && httpErr.StatusCode < http.StatusInternalServerError {
+			http.Error(w, "Restore rejected: "+httpErr.Message, httpErr.StatusCode)
+			return
+		}
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	log.Info("property restored from trash", "id", id)
+	http.Redirect(w, r, fmt.Sprintf("/show-property/%s", id), http.StatusSeeOther)
+}
+
+// ExportProperties streams a property export from the estate service.
+// ?format= is one of PropertyExportFormats; other parameters are passed on
+// as list filters.
+func (h *Handler) ExportProperties(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.http.Start(w, r, "Handler.ExportProperties")
+	defer finish()
+	log := h.log(r)
+
+	filters := r.URL.Query()
+	format := strings.ToLower(filters.Get("format"))
+	filters.Del("format")
+	if !slices.Contains(PropertyExportFormats, format) {
+		http.Error(w, "Bad Request: unknown export format", http.StatusBadRequest)
+		return
+	}
+
+	export, err := h.service.ExportProperties(r.Context(), format, filters)
+	if err != nil {
+		log.Error("error exporting properties", "error", err, "format", format)
+		var httpErr *core.HTTPError
+		switch {
+		case errors.Is(err, ErrPropertyExportUnavailable):
+			http.Error(w, "Property export is not available", http.StatusServiceUnavailable)
+		case errors.As(err, &httpErr) && httpErr.StatusCode < http.StatusInternalServerError:
+			http.Error(w, "Export rejected: "+httpErr.Message, httpErr.StatusCode)
+		default:
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		}
+		return
+	}
+	defer export.Body.Close()
+
+	w.Header().Set("Content-Type", export.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", export.Filename))
+	if _, err := io.Copy(w, export.Body); err != nil {
+		log.Error("error streaming property export", "error", err, "format", format)
+	}
+}
+
 // HTMXTypesByCategory returns HTML options for types filtered by category
 func (h *Handler) HTMXTypesByCategory(w http.ResponseWriter, r *http.Request) {
 	w, r, finish := h.http.Start(w, r, "Handler.HTMXTypesByCategory")
@@ -2,13 +2,60 @@ package admin
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"time"
 
 	"github.com/google/uuid"
 )
 
+// ErrPropertyExportUnavailable is returned by Export when the repository
+// cannot export properties.
+var ErrPropertyExportUnavailable = errors.New("property export is not available")
+
+// ErrPropertyConflict is returned by Update when the property was modified
+// after the revision the update is based on.
+var ErrPropertyConflict = errors.New("property was modified by someone else")
+
+// ErrPropertyDuplicate is returned by Create, wrapped in a
+// *PropertyDuplicateError, when the property likely duplicates existing ones.
+var ErrPropertyDuplicate = errors.New("property looks like a duplicate")
+
+// PropertyDuplicate is an existing property a new one likely duplicates.
+// Property is nil when the user may not read it.
+type PropertyDuplicate struct {
+	ID       uuid.UUID
+	Score    float64
+	Reasons  []string
+	Property *Property
+}
+
+// Percent returns the match score as a percentage.
+func (d PropertyDuplicate) Percent() int {
+	return int(math.Round(d.Score * 100))
+}
+
+// PropertyDuplicateError lists the likely duplicates of a rejected create;
+// setting CreatePropertyRequest.AllowDuplicate creates the property anyway.
+type PropertyDuplicateError struct {
+	Duplicates []PropertyDuplicate
+}
+
+func (e *PropertyDuplicateError) Error() string {
+	return fmt.Sprintf("%s of %d properties", ErrPropertyDuplicate, len(e.Duplicates))
+}
+
+func (e *PropertyDuplicateError) Unwrap() error {
+	return ErrPropertyDuplicate
+}
+
 // PropertyRepo defines the interface for property management operations in admin.
 type PropertyRepo interface {
-	// Create creates a new property
+	// Create creates a new property; unless req.AllowDuplicate is set, a
+	// property likely duplicating existing ones is rejected with a
+	// *PropertyDuplicateError
 	Create(ctx context.Context, req *CreatePropertyRequest) (*Property, error)
 
 	// Get retrieves a property by ID
@@ -17,15 +64,42 @@ type PropertyRepo interface {
 	// List retrieves all properties
 	List(ctx context.Context) ([]*Property, error)
 
-	// Update updates an existing property
+	// Update updates an existing property; a non-zero req.Revision must
+	// match the stored revision or ErrPropertyConflict is returned
 	Update(ctx context.Context, id uuid.UUID, req *UpdatePropertyRequest) (*Property, error)
 
-	// Delete removes a property
+	// Transition changes the status of a property
+	Transition(ctx context.Context, id uuid.UUID, req *TransitionPropertyRequest) error
+
+	// Revisions retrieves the revision history of a property, newest first
+	Revisions(ctx context.Context, id uuid.UUID) ([]PropertyRevision, error)
+
+	// Restore saves the property as it was at a previous revision
+	Restore(ctx context.Context, id uuid.UUID, number int64) error
+
+	// Delete moves a property to the trash
 	Delete(ctx context.Context, id uuid.UUID) error
 
+	// ListTrash retrieves the deleted properties
+	ListTrash(ctx context.Context) (*PropertyTrash, error)
+
+	// Undelete restores a property from the trash
+	Undelete(ctx context.Context, id uuid.UUID) error
+
 	// ListByOwner retrieves properties filtered by owner
 	ListByOwner(ctx context.Context, ownerID string) ([]*Property, error)
 
 	// ListByStatus retrieves properties filtered by status
 	ListByStatus(ctx context.Context, status string) ([]*Property, error)
+
+	// Export streams the properties matching the list filters in a format
+	// of PropertyExportFormats
+	Export(ctx context.Context, format string, filters url.Values) (*PropertyExport, error)
+
+	// ListAppointments retrieves the viewings overlapping a time range,
+	// soonest first, only those of an agent when agentID is not empty
+	ListAppointments(ctx context.Context, from, to time.Time, agentID string) ([]*Appointment, error)
+
+	// CalendarFeed retrieves the iCalendar link an agent subscribes to
+	CalendarFeed(ctx context.Context, agentID string) (string, error)
 }
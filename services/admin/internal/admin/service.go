@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/url"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/pulap/pulap/pkg/lib/core"
@@ -34,9 +36,17 @@ type Service interface {
 	GetProperty(ctx context.Context, id uuid.UUID) (*Property, error)
 	ListProperties(ctx context.Context) ([]*Property, error)
 	UpdateProperty(ctx context.Context, id uuid.UUID, req *UpdatePropertyRequest) (*Property, error)
+	TransitionProperty(ctx context.Context, id uuid.UUID, req *TransitionPropertyRequest) error
+	ListPropertyRevisions(ctx context.Context, id uuid.UUID) ([]PropertyRevision, error)
+	RestorePropertyRevision(ctx context.Context, id uuid.UUID, number int64) error
 	DeleteProperty(ctx context.Context, id uuid.UUID) error
+	ListDeletedProperties(ctx context.Context) (*PropertyTrash, error)
+	RestoreDeletedProperty(ctx context.Context, id uuid.UUID) error
 	ListPropertiesByOwner(ctx context.Context, ownerID string) ([]*Property, error)
 	ListPropertiesByStatus(ctx context.Context, status string) ([]*Property, error)
+	ExportProperties(ctx context.Context, format string, filters url.Values) (*PropertyExport, error)
+	ListAppointments(ctx context.Context, from, to time.Time, agentID string) ([]*Appointment, error)
+	AgentCalendarFeed(ctx context.Context, agentID string) (string, error)
 	SuggestLocations(ctx context.Context, query string) ([]LocationSuggestion, error)
 	ResolveLocation(ctx context.Context, reference string) (*ResolvedAddress, error)
 	NormalizeLocation(ctx context.Context, req NormalizeLocationRequest) (*NormalizedLocation, error)
@@ -163,10 +173,30 @@ func (s *defaultService) UpdateProperty(ctx context.Context, id uuid.UUID, req *
 	return s.repos.PropertyRepo.Update(ctx, id, req)
 }
 
+func (s *defaultService) TransitionProperty(ctx context.Context, id uuid.UUID, req *TransitionPropertyRequest) error {
+	return s.repos.PropertyRepo.Transition(ctx, id, req)
+}
+
+func (s *defaultService) ListPropertyRevisions(ctx context.Context, id uuid.UUID) ([]PropertyRevision, error) {
+	return s.repos.PropertyRepo.Revisions(ctx, id)
+}
+
+func (s *defaultService) RestorePropertyRevision(ctx context.Context, id uuid.UUID, number int64) error {
+	return s.repos.PropertyRepo.Restore(ctx, id, number)
+}
+
 func (s *defaultService) DeleteProperty(ctx context.Context, id uuid.UUID) error {
 	return s.repos.PropertyRepo.Delete(ctx, id)
 }
 
+func (s *defaultService) ListDeletedProperties(ctx context.Context) (*PropertyTrash, error) {
+	return s.repos.PropertyRepo.ListTrash(ctx)
+}
+
+func (s *defaultService) RestoreDeletedProperty(ctx context.Context, id uuid.UUID) error {
+	return s.repos.PropertyRepo.Undelete(ctx, id)
+}
+
 func (s *defaultService) ListPropertiesByOwner(ctx context.Context, ownerID string) ([]*Property, error) {
 	return s.repos.PropertyRepo.ListByOwner(ctx, ownerID)
 }
@@ -175,6 +205,18 @@ func (s *defaultService) ListPropertiesByStatus(ctx context.Context, status stri
 	return s.repos.PropertyRepo.ListByStatus(ctx, status)
 }
 
+func (s *defaultService) ExportProperties(ctx context.Context, format string, filters url.Values) (*PropertyExport, error) {
+	return s.repos.PropertyRepo.Export(ctx, format, filters)
+}
+
+func (s *defaultService) ListAppointments(ctx context.Context, from, to time.Time, agentID string) ([]*Appointment, error) {
+	return s.repos.PropertyRepo.ListAppointments(ctx, from, to, agentID)
+}
+
+func (s *defaultService) AgentCalendarFeed(ctx context.Context, agentID string) (string, error) {
+	return s.repos.PropertyRepo.CalendarFeed(ctx, agentID)
+}
+
 func (s *defaultService) SuggestLocations(ctx context.Context, query string) ([]LocationSuggestion, error) {
 	if s.locationProvider == nil {
 		return nil, ErrLocationProviderUnavailable
@@ -36,8 +36,9 @@ type ServicesConfig struct {
 }
 
 type AuthConfig struct {
-	CacheTTL      string `koanf:"cache_ttl"`
-	SessionSecret string `koanf:"session_secret"`
+	CacheTTL       string `koanf:"cache_ttl"`
+	SessionSecret  string `koanf:"session_secret"`
+	TokenPublicKey string `koanf:"token_public_key"` // Base64 Ed25519 key of the authn service
 }
 
 type LogConfig struct {
@@ -123,6 +124,7 @@ func LoadConfig(path, envPrefix string, args []string) (*Config, error) {
 	fs.String("services.dictionary_url", "http://localhost:8085", "Dictionary service URL")
 	fs.String("auth.cache_ttl", "5m", "Auth cache TTL")
 	fs.String("auth.session_secret", "change-this-in-production", "Session secret")
+	fs.String("auth.token_public_key", "", "Authn Ed25519 public key for session tokens (base64)")
 	fs.String("log.level", "info", "log level (debug, info, error)")
 	fs.Bool("debug.routes", true, "Expose /debug/routes endpoint")
 	fs.String("geocode.provider", "locationiq", "Location provider to use (locationiq|google|osm)")
@@ -182,6 +184,9 @@ func LoadConfig(path, envPrefix string, args []string) (*Config, error) {
 	if val := os.Getenv("ADMIN_AUTH_CACHE_TTL"); val != "" {
 		cfg.Auth.CacheTTL = val
 	}
+	if val := os.Getenv("ADMIN_AUTH_TOKEN_PUBLIC_KEY"); val != "" {
+		cfg.Auth.TokenPublicKey = val
+	}
 	if val := os.Getenv("ADMIN_LOG_LEVEL"); val != "" {
 		cfg.Log.Level = val
 	}
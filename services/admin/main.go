@@ -2,7 +2,10 @@ package main
 
 import (
 	"context"
+	"crypto/ed25519"
 	"embed"
+	"encoding/base64"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
@@ -10,6 +13,7 @@ import (
 	"syscall"
 	"time"
 
+	authpkg "github.com/pulap/pulap/pkg/lib/auth"
 	"github.com/pulap/pulap/pkg/lib/core"
 	"github.com/pulap/pulap/services/admin/internal/admin"
 	"github.com/pulap/pulap/services/admin/internal/config"
@@ -31,6 +35,11 @@ func main() {
 
 	logger := core.NewLogger(cfg.Log.Level)
 
+	sessionVerifier, err := configureSessionVerifier(cfg)
+	if err != nil {
+		log.Fatalf("Cannot setup %s(%s): %v", name, version, err)
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -81,6 +90,7 @@ func main() {
 	dictRepo := admin.NewAPIDictionaryRepo(dictServiceClient)
 
 	adminHandler := admin.NewHandler(tmplMgr, adminService, authZClient, authnClient, dictRepo, xparams)
+	adminHandler.SetSessionValidator(sessionVerifier.ValidateToken)
 	deps = append(deps, adminHandler)
 
 	starts, stops, _ := core.Setup(ctx, router, deps...)
@@ -104,6 +114,16 @@ func main() {
 	cancel()
 }
 
+// configureSessionVerifier returns the verifier of the authn session tokens
+// kept in the admin session cookie.
+func configureSessionVerifier(cfg *config.Config) (*authpkg.TokenVerifier, error) {
+	key, err := base64.StdEncoding.DecodeString(cfg.Auth.TokenPublicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("auth.token_public_key must be the base64 Ed25519 public key of the authn service")
+	}
+	return authpkg.NewTokenVerifier(ed25519.PublicKey(key), "session"), nil
+}
+
 func configureLocationProvider(cfg *config.Config) admin.LocationProvider {
 	if cfg == nil {
 		return nil
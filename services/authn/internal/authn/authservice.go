@@ -185,6 +185,19 @@ func generateSessionToken(cfg *config.Config, userID uuid.UUID) (string, error)
 	return authpkg.GenerateSessionToken(userID.String(), sessionID, privateKey, ttl)
 }
 
+// GenerateTokenKey sets the token keys of cfg to a newly generated Ed25519
+// pair and returns the base64 public key, so session tokens issued for the
+// rest of the run share one key services can verify them with.
+func GenerateTokenKey(cfg *config.Config) (string, error) {
+	publicKey, privateKey, err := authpkg.GenerateKeyPair()
+	if err != nil {
+		return "", fmt.Errorf("generate key pair: %w", err)
+	}
+	cfg.Auth.TokenPrivateKey = base64.StdEncoding.EncodeToString(privateKey)
+	cfg.Auth.TokenPublicKey = base64.StdEncoding.EncodeToString(publicKey)
+	return cfg.Auth.TokenPublicKey, nil
+}
+
 func tokenPrivateKey(encoded string) (ed25519.PrivateKey, error) {
 	if encoded != "" {
 		keyBytes, err := base64.StdEncoding.DecodeString(encoded)
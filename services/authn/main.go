@@ -27,6 +27,14 @@ func main() {
 
 	logger := core.NewLogger(cfg.Log.Level)
 
+	if cfg.Auth.TokenPrivateKey == "" {
+		publicKey, err := authn.GenerateTokenKey(cfg)
+		if err != nil {
+			log.Fatalf("Cannot setup %s(%s): %v", name, version, err)
+		}
+		logger.Infof("No auth.token_private_key set, session tokens are signed with a key generated for this run; verify them with public key %s", publicKey)
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -190,6 +190,18 @@ func (s *BootstrapService) seedRoles(ctx context.Context) error {
 				"grants:read",
 				"grants:delete",
 				"grants:list",
+				"estates:read",
+				"estates:write",
+				"estates:delete",
+			},
+		},
+		{
+			Name:        "agent",
+			Description: "Real estate agent, granted in the estate scope of the agent or their team",
+			Permissions: []string{
+				"estates:read",
+				"estates:write",
+				"estates:delete",
 			},
 		},
 		{
@@ -8,6 +8,7 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 
+	authpkg "github.com/pulap/pulap/pkg/lib/auth"
 	"github.com/pulap/pulap/pkg/lib/core"
 	"github.com/pulap/pulap/pkg/lib/telemetry"
 	"github.com/pulap/pulap/services/authz/internal/config"
@@ -55,6 +56,7 @@ type GrantRequest struct {
 	UserID    string  `json:"user_id"`
 	RoleName  string  `json:"role_name"`
 	Resource  string  `json:"resource"`
+	Scope     *Scope  `json:"scope,omitempty"`      // Overrides resource, e.g. {"type": "estate", "id": "team-7"}
 	ExpiresAt *string `json:"expires_at,omitempty"` // ISO8601 timestamp
 }
 
@@ -130,6 +132,12 @@ func (h *GrantHandler) CreateGrant(w http.ResponseWriter, r *http.Request) {
 		core.RespondError(w, http.StatusBadRequest, "Invalid user ID format")
 		return
 	}
+	if req.Scope != nil {
+		if errs := authpkg.ValidateScope(authpkg.Scope{Type: req.Scope.Type, ID: req.Scope.ID}); errs.HasErrors() {
+			core.RespondError(w, http.StatusBadRequest, errs.Error())
+			return
+		}
+	}
 
 	// Since we have a role_name, this is always a role grant
 	grantType := GrantTypeRole
@@ -163,6 +171,9 @@ func (h *GrantHandler) CreateGrant(w http.ResponseWriter, r *http.Request) {
 	grant.GrantType = grantType
 	grant.Value = role.ID.String() // Store role UUID, not name
 	grant.Scope = Scope{Type: "resource", ID: req.Resource}
+	if req.Scope != nil {
+		grant.Scope = *req.Scope
+	}
 	grant.ExpiresAt = expiresAt
 
 	if err := h.grantRepo.Create(ctx, grant); err != nil {
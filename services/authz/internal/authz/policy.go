@@ -98,6 +98,45 @@ func (p *PolicyEngine) GetUserPermissions(ctx context.Context, userID uuid.UUID,
 	return result, nil
 }
 
+// Scopes returns the scopes of scopeType in which a user has a permission.
+// A global grant covers every scope, so it is returned alone.
+func (p *PolicyEngine) Scopes(ctx context.Context, userID uuid.UUID, permission, scopeType string) ([]Scope, error) {
+	grants, err := p.grantRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get user grants: %w", err)
+	}
+
+	scopes := []Scope{}
+	seen := make(map[Scope]bool)
+	for _, grant := range p.filterActiveGrants(grants) {
+		if grant.Scope.Type != "global" && grant.Scope.Type != scopeType {
+			continue
+		}
+
+		hasPermission := false
+		switch grant.GrantType {
+		case GrantTypePermission:
+			hasPermission = grant.Value == permission
+		case GrantTypeRole:
+			hasPermission, err = p.roleHasPermission(ctx, grant.Value, permission)
+			if err != nil {
+				return nil, fmt.Errorf("error check role permission: %w", err)
+			}
+		}
+		if !hasPermission || seen[grant.Scope] {
+			continue
+		}
+
+		if grant.Scope.Type == "global" {
+			return []Scope{{Type: "global", ID: ""}}, nil
+		}
+		seen[grant.Scope] = true
+		scopes = append(scopes, grant.Scope)
+	}
+
+	return scopes, nil
+}
+
 // filterActiveGrants filters grants that are active and not expired
 func (p *PolicyEngine) filterActiveGrants(grants []*Grant) []*Grant {
 	var active []*Grant
@@ -310,6 +310,75 @@ func TestPolicyEngineHasGlobalScope(t *testing.T) {
 	}
 }
 
+func TestPolicyEngineScopes(t *testing.T) {
+	grantRepo := &testGrantRepo{}
+	roleRepo := &testRoleRepo{}
+	engine := NewPolicyEngine(roleRepo, grantRepo)
+
+	userID := uuid.New()
+	roleID := uuid.New()
+	roleRepo.Create(context.Background(), &Role{
+		ID:          roleID,
+		Name:        "agent",
+		Permissions: []string{"estates:*"},
+		Status:      authpkg.UserStatusActive,
+	})
+
+	grants := []*Grant{
+		{UserID: userID, GrantType: GrantTypePermission, Value: "estates:write", Scope: Scope{Type: "estate", ID: "owner-1"}},
+		{UserID: userID, GrantType: GrantTypeRole, Value: roleID.String(), Scope: Scope{Type: "estate", ID: "team-1"}},
+		{UserID: userID, GrantType: GrantTypePermission, Value: "estates:write", Scope: Scope{Type: "estate", ID: "owner-1"}},
+		{UserID: userID, GrantType: GrantTypePermission, Value: "estates:delete", Scope: Scope{Type: "estate", ID: "owner-2"}},
+		{UserID: userID, GrantType: GrantTypePermission, Value: "estates:write", Scope: Scope{Type: "team", ID: "team-2"}},
+	}
+	for _, grant := range grants {
+		grant.Status = authpkg.UserStatusActive
+		grantRepo.Create(context.Background(), grant)
+	}
+
+	scopes, err := engine.Scopes(context.Background(), userID, "estates:write", "estate")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := []Scope{{Type: "estate", ID: "owner-1"}, {Type: "estate", ID: "team-1"}}
+	if len(scopes) != len(want) || scopes[0] != want[0] || scopes[1] != want[1] {
+		t.Errorf("Expected scopes %v, got %v", want, scopes)
+	}
+
+	scopes, err = engine.Scopes(context.Background(), uuid.New(), "estates:write", "estate")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if scopes == nil || len(scopes) != 0 {
+		t.Errorf("Expected no scopes for a user without grants, got %v", scopes)
+	}
+}
+
+func TestPolicyEngineScopesGlobal(t *testing.T) {
+	grantRepo := &testGrantRepo{}
+	roleRepo := &testRoleRepo{}
+	engine := NewPolicyEngine(roleRepo, grantRepo)
+
+	userID := uuid.New()
+	for _, scope := range []Scope{{Type: "estate", ID: "owner-1"}, {Type: "global"}} {
+		grantRepo.Create(context.Background(), &Grant{
+			UserID:    userID,
+			GrantType: GrantTypePermission,
+			Value:     "estates:read",
+			Scope:     scope,
+			Status:    authpkg.UserStatusActive,
+		})
+	}
+
+	scopes, err := engine.Scopes(context.Background(), userID, "estates:read", "estate")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(scopes) != 1 || scopes[0].Type != "global" {
+		t.Errorf("Expected a single global scope, got %v", scopes)
+	}
+}
+
 func TestPolicyEngineGetUserPermissions(t *testing.T) {
 	grantRepo := &testGrantRepo{}
 	roleRepo := &testRoleRepo{}
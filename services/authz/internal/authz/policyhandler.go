@@ -36,6 +36,7 @@ func (h *PolicyHandler) RegisterRoutes(r chi.Router) {
 	r.Route("/authz/policy", func(r chi.Router) {
 		r.Post("/evaluate", h.EvaluatePermission)
 		r.Get("/users/{user_id}/permissions", h.GetUserPermissions)
+		r.Get("/users/{user_id}/scopes", h.GetUserScopes)
 	})
 }
 
@@ -61,6 +62,14 @@ type UserPermissionsResponse struct {
 	Permissions []string `json:"permissions"`
 }
 
+// UserScopesResponse represents the response for the scopes of a permission
+type UserScopesResponse struct {
+	UserID     string  `json:"user_id"`
+	Permission string  `json:"permission"`
+	ScopeType  string  `json:"scope_type"`
+	Scopes     []Scope `json:"scopes"` // A single global scope when granted globally
+}
+
 // EvaluatePermission handles POST /authz/policy/evaluate
 // This is the core endpoint that other services will call to check permissions
 func (h *PolicyHandler) EvaluatePermission(w http.ResponseWriter, r *http.Request) {
@@ -187,6 +196,62 @@ func (h *PolicyHandler) GetUserPermissions(w http.ResponseWriter, r *http.Reques
 	json.NewEncoder(w).Encode(core.SuccessResponse{Data: response, Links: links})
 }
 
+// GetUserScopes handles GET /authz/policy/users/{user_id}/scopes
+// Returns the scopes of ?scope_type= in which a user has ?permission=, so
+// services can filter lists to what the user may see
+func (h *PolicyHandler) GetUserScopes(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "PolicyHandler.GetUserScopes")
+	defer finish()
+
+	log := h.log(r)
+	ctx := r.Context()
+
+	userIDStr := chi.URLParam(r, "user_id")
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		core.RespondError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	permission := r.URL.Query().Get("permission")
+	scopeType := r.URL.Query().Get("scope_type")
+	if permission == "" {
+		core.RespondError(w, http.StatusBadRequest, "Permission is required")
+		return
+	}
+	if scopeType == "" || scopeType == "global" {
+		core.RespondError(w, http.StatusBadRequest, "A non-global scope type is required")
+		return
+	}
+
+	scopes, err := h.policyEngine.Scopes(ctx, userID, permission, scopeType)
+	if err != nil {
+		log.Error("failed to get user scopes", "error", err,
+			"user_id", userIDStr,
+			"permission", permission,
+			"scope_type", scopeType)
+		core.RespondError(w, http.StatusInternalServerError, "Failed to retrieve user scopes")
+		return
+	}
+
+	response := UserScopesResponse{
+		UserID:     userIDStr,
+		Permission: permission,
+		ScopeType:  scopeType,
+		Scopes:     scopes,
+	}
+
+	links := []core.Link{
+		{Rel: "self", Href: "/authz/policy/users/" + userIDStr + "/scopes"},
+		{Rel: "permissions", Href: "/authz/policy/users/" + userIDStr + "/permissions"},
+		{Rel: "grants", Href: "/authz/grants/users/" + userIDStr},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(core.SuccessResponse{Data: response, Links: links})
+}
+
 // Helper methods
 
 func (h *PolicyHandler) log(req ...*http.Request) core.Logger {
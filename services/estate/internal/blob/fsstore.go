@@ -0,0 +1,124 @@
+// Package blob implements estate.BlobStore backends.
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pulap/pulap/services/estate/internal/config"
+	"github.com/pulap/pulap/services/estate/internal/estate"
+)
+
+// FSStore implements estate.BlobStore on the local filesystem. Keys map to
+// paths below the root directory. Content types are not kept; callers store
+// them with their metadata.
+type FSStore struct {
+	root    string
+	xparams config.XParams
+}
+
+// NewFSStore creates a store rooted at the configured media path.
+func NewFSStore(xparams config.XParams) *FSStore {
+	return &FSStore{
+		root:    xparams.Cfg().Media.Path,
+		xparams: xparams,
+	}
+}
+
+// Start creates the root directory.
+func (s *FSStore) Start(ctx context.Context) error {
+	if err := os.MkdirAll(s.root, 0o755); err != nil {
+		return fmt.Errorf("cannot create media directory: %w", err)
+	}
+	s.xparams.Log().Infof("Storing media in %s", s.root)
+	return nil
+}
+
+// Put writes the object to a temporary file and renames it into place, so
+// readers never see a partial object.
+func (s *FSStore) Put(ctx context.Context, key, contentType string, r io.Reader) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("cannot create blob directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("cannot create blob: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return fmt.Errorf("cannot write blob: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("cannot write blob: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("cannot store blob: %w", err)
+	}
+	return nil
+}
+
+// Open opens the object for reading.
+func (s *FSStore) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%s: %w", key, estate.ErrBlobNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot open blob: %w", err)
+	}
+	return f, nil
+}
+
+// Delete removes the object.
+func (s *FSStore) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("cannot delete blob: %w", err)
+	}
+	return nil
+}
+
+// DeletePrefix removes every object below prefix. Prefixes are expected to
+// end at a key separator, as the ones used by estate.MediaLibrary do.
+func (s *FSStore) DeletePrefix(ctx context.Context, prefix string) error {
+	if !strings.HasSuffix(prefix, "/") {
+		return fmt.Errorf("blob prefix %q must end with /", prefix)
+	}
+	path, err := s.path(strings.TrimSuffix(prefix, "/"))
+	if err != nil {
+		return err
+	}
+	if err := os.RemoveAll(path); err != nil {
+		return fmt.Errorf("cannot delete blobs: %w", err)
+	}
+	return nil
+}
+
+// path maps a key to a file below the root, rejecting keys that would
+// escape it.
+func (s *FSStore) path(key string) (string, error) {
+	if key == "" || strings.HasPrefix(key, "/") || !filepath.IsLocal(filepath.FromSlash(key)) {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
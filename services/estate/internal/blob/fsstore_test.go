@@ -0,0 +1,94 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/pulap/pulap/pkg/lib/core"
+	"github.com/pulap/pulap/services/estate/internal/config"
+	"github.com/pulap/pulap/services/estate/internal/estate"
+)
+
+func TestFSStore(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	for _, key := range []string{"properties/a/1/original", "properties/a/1/small", "properties/a/2/original", "properties/b/1/original"} {
+		if err := store.Put(ctx, key, "image/jpeg", strings.NewReader(key)); err != nil {
+			t.Fatalf("Put %s: %v", key, err)
+		}
+	}
+
+	if got := read(t, store, "properties/a/1/small"); got != "properties/a/1/small" {
+		t.Errorf("Open returned %q", got)
+	}
+
+	if err := store.Put(ctx, "properties/a/1/small", "image/jpeg", strings.NewReader("replaced")); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if got := read(t, store, "properties/a/1/small"); got != "replaced" {
+		t.Errorf("expected replaced object, got %q", got)
+	}
+
+	if err := store.Delete(ctx, "properties/a/2/original"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := store.Delete(ctx, "properties/a/2/original"); err != nil {
+		t.Errorf("Delete of missing object: %v", err)
+	}
+	if _, err := store.Open(ctx, "properties/a/2/original"); !errors.Is(err, estate.ErrBlobNotFound) {
+		t.Errorf("expected ErrBlobNotFound, got %v", err)
+	}
+
+	if err := store.DeletePrefix(ctx, "properties/a/"); err != nil {
+		t.Fatalf("DeletePrefix: %v", err)
+	}
+	if _, err := store.Open(ctx, "properties/a/1/original"); !errors.Is(err, estate.ErrBlobNotFound) {
+		t.Errorf("expected prefix to be deleted, got %v", err)
+	}
+	if got := read(t, store, "properties/b/1/original"); got != "properties/b/1/original" {
+		t.Errorf("other prefix affected, got %q", got)
+	}
+}
+
+func TestFSStoreRejectsEscapingKeys(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	for _, key := range []string{"", "/etc/passwd", "../outside", "properties/../../outside"} {
+		if err := store.Put(ctx, key, "text/plain", strings.NewReader("x")); err == nil {
+			t.Errorf("expected key %q to be rejected", key)
+		}
+	}
+	if err := store.DeletePrefix(ctx, "properties"); err == nil {
+		t.Error("expected prefix without separator to be rejected")
+	}
+}
+
+func newTestStore(t *testing.T) *FSStore {
+	t.Helper()
+	cfg := config.New()
+	cfg.Media.Path = t.TempDir()
+	store := NewFSStore(config.NewXParams(core.NewNoopLogger(), cfg))
+	if err := store.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	return store
+}
+
+func read(t *testing.T, store *FSStore, key string) string {
+	t.Helper()
+	r, err := store.Open(context.Background(), key)
+	if err != nil {
+		t.Fatalf("Open %s: %v", key, err)
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("read %s: %v", key, err)
+	}
+	return string(data)
+}
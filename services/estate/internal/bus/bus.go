@@ -0,0 +1,79 @@
+// Package bus provides the event buses the estate relay publishes property
+// events to. Other brokers plug in by implementing estate.EventBus.
+package bus
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/pulap/pulap/pkg/lib/core"
+	"github.com/pulap/pulap/services/estate/internal/estate"
+)
+
+// LogBus logs each event instead of publishing it, for development and for
+// deployments without subscribers yet.
+type LogBus struct {
+	log core.Logger
+}
+
+// NewLogBus returns a bus logging events at info level.
+func NewLogBus(log core.Logger) *LogBus {
+	return &LogBus{log: log}
+}
+
+// Publish logs the event.
+func (b *LogBus) Publish(ctx context.Context, event estate.Event) error {
+	b.log.Info("property event", "id", event.ID.String(), "type", event.Type,
+		"property", event.AggregateID.String(), "sequence", event.Sequence)
+	return nil
+}
+
+// WebhookBus posts each event as JSON to a URL. The event type and ID are
+// also sent in the X-Event-Type and X-Event-ID headers, so receivers can
+// route and deduplicate without decoding the body. Any response other than
+// 2xx is an error and the event is retried.
+type WebhookBus struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookBus returns a bus posting events to url.
+func NewWebhookBus(url string, timeout time.Duration) (*WebhookBus, error) {
+	if url == "" {
+		return nil, fmt.Errorf("webhook bus needs a URL")
+	}
+	return &WebhookBus{url: url, client: &http.Client{Timeout: timeout}}, nil
+}
+
+// Publish posts the event.
+func (b *WebhookBus) Publish(ctx context.Context, event estate.Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("cannot encode event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("cannot create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-Type", event.Type)
+	req.Header.Set("X-Event-ID", event.ID.String())
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("cannot post event: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package bus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/services/estate/internal/estate"
+)
+
+func TestWebhookBusPublish(t *testing.T) {
+	event := estate.Event{ID: uuid.New(), Type: estate.EventPropertyCreated, AggregateID: uuid.New(), Sequence: 1}
+
+	var got estate.Event
+	var header http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode: %v", err)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	bus, err := NewWebhookBus(srv.URL, time.Second)
+	if err != nil {
+		t.Fatalf("NewWebhookBus: %v", err)
+	}
+	if err := bus.Publish(context.Background(), event); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	if got.ID != event.ID || got.AggregateID != event.AggregateID || got.Sequence != 1 {
+		t.Errorf("unexpected event posted: %+v", got)
+	}
+	if header.Get("X-Event-Type") != estate.EventPropertyCreated || header.Get("X-Event-ID") != event.ID.String() {
+		t.Errorf("unexpected headers: %v", header)
+	}
+}
+
+func TestWebhookBusPublishRejected(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	bus, _ := NewWebhookBus(srv.URL, time.Second)
+	if err := bus.Publish(context.Background(), estate.Event{ID: uuid.New()}); err == nil {
+		t.Error("expected an error for a 503 response")
+	}
+}
@@ -16,22 +16,145 @@ import (
 )
 
 type Config struct {
-	Log      LogConfig      `koanf:"log"`
-	Server   ServerConfig   `koanf:"server"`
-	Database DatabaseConfig `koanf:"database"`
-	Debug    DebugConfig    `koanf:"debug"`
+	Log          LogConfig          `koanf:"log"`
+	Server       ServerConfig       `koanf:"server"`
+	Database     DatabaseConfig     `koanf:"database"`
+	Services     ServicesConfig     `koanf:"services"`
+	Dictionary   DictionaryConfig   `koanf:"dictionary"`
+	Search       SearchConfig       `koanf:"search"`
+	Authn        AuthnConfig        `koanf:"authn"`
+	Authz        AuthzConfig        `koanf:"authz"`
+	Media        MediaConfig        `koanf:"media"`
+	Pricing      PricingConfig      `koanf:"pricing"`
+	Imports      ImportsConfig      `koanf:"imports"`
+	Feeds        FeedsConfig        `koanf:"feeds"`
+	Trash        TrashConfig        `koanf:"trash"`
+	Events       EventsConfig       `koanf:"events"`
+	Duplicates   DuplicatesConfig   `koanf:"duplicates"`
+	Appointments AppointmentsConfig `koanf:"appointments"`
+	Inquiries    InquiriesConfig    `koanf:"inquiries"`
+	Debug        DebugConfig        `koanf:"debug"`
 }
 
 type ServerConfig struct {
-	Port string `koanf:"port"`
+	Port           string `koanf:"port"`
+	TrustedProxies string `koanf:"trusted_proxies"` // Comma separated IPs and CIDRs whose forwarding headers are trusted
 }
 
 type DatabaseConfig struct {
+	Driver        string `koanf:"driver"` // "mongo" (default) or "sqlite"
 	Path          string `koanf:"path"`
 	MongoURL      string `koanf:"mongo_url"`
 	MongoDatabase string `koanf:"mongo_database"`
 }
 
+type ServicesConfig struct {
+	DictionaryURL string `koanf:"dictionary_url"`
+	AuthzURL      string `koanf:"authz_url"`
+}
+
+// DictionaryConfig controls how classifications are validated against the dictionary service.
+type DictionaryConfig struct {
+	Client   string `koanf:"client"`    // "http" (default) or "fake"
+	Timeout  string `koanf:"timeout"`   // Per-request timeout, e.g. "5s"
+	CacheTTL string `koanf:"cache_ttl"` // How long an option is considered fresh
+	StaleTTL string `koanf:"stale_ttl"` // How long a stale option may be served while it is refreshed
+	FailOpen bool   `koanf:"fail_open"` // Accept classifications when the dictionary is unreachable
+}
+
+// SearchConfig controls the embedded full-text index.
+type SearchConfig struct {
+	Path          string `koanf:"path"`           // Index directory; empty keeps the index in memory
+	DefaultLocale string `koanf:"default_locale"` // Analyzer used when a query does not set a locale
+}
+
+// AuthnConfig controls how bearer tokens are verified.
+type AuthnConfig struct {
+	Client    string `koanf:"client"`     // "token" (default) or "fake"
+	PublicKey string `koanf:"public_key"` // Base64 Ed25519 key of the authn service, for "token"
+}
+
+// AuthzConfig controls how permissions are checked.
+type AuthzConfig struct {
+	Client string `koanf:"client"` // "http" (default) or "fake"
+}
+
+// MediaConfig controls where property media is stored and upload limits.
+type MediaConfig struct {
+	Store          string `koanf:"store"`            // "fs" (default)
+	Path           string `koanf:"path"`             // Root directory of the fs store
+	MaxUploadBytes int64  `koanf:"max_upload_bytes"` // Per file
+}
+
+// PricingConfig controls how prices in different currencies are compared.
+type PricingConfig struct {
+	BaseCurrency string `koanf:"base_currency"` // ISO 4217 code valuations are expressed in
+}
+
+// ImportsConfig controls bulk property imports.
+type ImportsConfig struct {
+	MaxUploadBytes int64 `koanf:"max_upload_bytes"` // Per import file
+}
+
+// FeedsConfig controls the syndication feeds published to property portals.
+type FeedsConfig struct {
+	Refresh    string                `koanf:"refresh"`     // How often feeds are regenerated, e.g. "15m"
+	ListingURL string                `koanf:"listing_url"` // Public listing page, "{id}" is replaced by the property ID
+	MediaURL   string                `koanf:"media_url"`   // Public base URL of this service, used for photo links
+	Portals    map[string]FeedConfig `koanf:"portals"`     // Keyed by feed name
+}
+
+// FeedConfig is a feed published to a portal.
+type FeedConfig struct {
+	Format     string            `koanf:"format"` // "kyero"
+	Statuses   []string          `koanf:"statuses"`
+	OwnerID    string            `koanf:"owner_id"`
+	PriceTypes []string          `koanf:"price_types"`
+	Language   string            `koanf:"language"`
+	Types      map[string]string `koanf:"types"`    // Dictionary option key to portal property type
+	Features   map[string]string `koanf:"features"` // Amenity to portal feature
+}
+
+// TrashConfig controls how long deleted properties can be restored.
+type TrashConfig struct {
+	RetentionDays int    `koanf:"retention_days"` // Days before deleted properties are purged, 0 keeps them
+	PurgeInterval string `koanf:"purge_interval"` // How often the trash is purged, e.g. "1h"
+}
+
+// EventsConfig controls how property events are relayed from the outbox.
+type EventsConfig struct {
+	Bus            string `koanf:"bus"`             // "log" (default), "webhook" or "none" to leave events in the outbox
+	WebhookURL     string `koanf:"webhook_url"`     // Events are posted here by the "webhook" bus
+	WebhookTimeout string `koanf:"webhook_timeout"` // Per event, e.g. "5s"
+	Interval       string `koanf:"interval"`        // How often the outbox is checked, e.g. "1s"
+	BatchSize      int    `koanf:"batch_size"`      // Events read from the outbox at a time
+}
+
+// DuplicatesConfig controls duplicate property detection.
+type DuplicatesConfig struct {
+	Threshold float64 `koanf:"threshold"` // Score from 0 to 1 from which a property is a likely duplicate
+}
+
+// AppointmentsConfig controls viewing appointments and the calendar feeds
+// of agents.
+type AppointmentsConfig struct {
+	AgentBuffer    string `koanf:"agent_buffer"`    // Minimum gap between the appointments of an agent, e.g. "30m"
+	PropertyBuffer string `koanf:"property_buffer"` // Minimum gap between the appointments of a property, e.g. "15m"
+	FeedSecret     string `koanf:"feed_secret"`     // Signs calendar feed tokens, empty disables the feeds
+	FeedURL        string `koanf:"feed_url"`        // Public base URL of this service, used for feed links
+}
+
+// InquiriesConfig controls inquiries about properties: the keys their
+// contacts are encrypted with and the screening of the public form.
+type InquiriesConfig struct {
+	EncryptionKey   string `koanf:"encryption_key"`   // AES key of 16, 24 or 32 bytes, empty disables inquiries
+	LookupKey       string `koanf:"lookup_key"`       // HMAC key finding repeated contacts
+	RateLimit       int    `koanf:"rate_limit"`       // Public submissions per client and rate window, 0 disables the limit
+	RateWindow      string `koanf:"rate_window"`      // e.g. "1h"
+	DuplicateWindow string `koanf:"duplicate_window"` // Repeated inquiries of a contact within it are dropped, e.g. "24h"
+	MaxLinks        int    `koanf:"max_links"`        // Public messages with more links are spam
+}
+
 type LogConfig struct {
 	Level string `koanf:"level"`
 }
@@ -46,10 +169,69 @@ func New() *Config {
 			Port: ":8084",
 		},
 		Database: DatabaseConfig{
+			Driver:        "mongo",
 			Path:          "./app.db",
 			MongoURL:      "mongodb://localhost:27017",
 			MongoDatabase: "estate",
 		},
+		Services: ServicesConfig{
+			DictionaryURL: "http://localhost:8085",
+			AuthzURL:      "http://localhost:8083",
+		},
+		Dictionary: DictionaryConfig{
+			Client:   "http",
+			Timeout:  "5s",
+			CacheTTL: "5m",
+			StaleTTL: "1h",
+			FailOpen: false,
+		},
+		Search: SearchConfig{
+			Path:          "./search.bleve",
+			DefaultLocale: "es",
+		},
+		Authn: AuthnConfig{
+			Client: "token",
+		},
+		Authz: AuthzConfig{
+			Client: "http",
+		},
+		Media: MediaConfig{
+			Store:          "fs",
+			Path:           "./media",
+			MaxUploadBytes: 20 << 20,
+		},
+		Pricing: PricingConfig{
+			BaseCurrency: "USD",
+		},
+		Imports: ImportsConfig{
+			MaxUploadBytes: 32 << 20,
+		},
+		Feeds: FeedsConfig{
+			Refresh: "15m",
+		},
+		Trash: TrashConfig{
+			RetentionDays: 30,
+			PurgeInterval: "1h",
+		},
+		Events: EventsConfig{
+			Bus:            "log",
+			WebhookTimeout: "5s",
+			Interval:       "1s",
+			BatchSize:      100,
+		},
+		Duplicates: DuplicatesConfig{
+			Threshold: 0.6,
+		},
+		Appointments: AppointmentsConfig{
+			AgentBuffer:    "30m",
+			PropertyBuffer: "15m",
+		},
+		Inquiries: InquiriesConfig{
+			RateLimit:       5,
+			RateWindow:      "1h",
+			DuplicateWindow: "24h",
+			MaxLinks:        2,
+		},
 		Log: LogConfig{
 			Level: "info",
 		},
@@ -70,7 +252,38 @@ func LoadConfig(path, envPrefix string, args []string) (*Config, error) {
 	// Setup pflag
 	fs := pflag.NewFlagSet(args[0], pflag.ExitOnError)
 	fs.String("server.port", ":8084", "Server estateen address")
+	fs.String("server.trusted_proxies", "", "Comma separated reverse proxy IPs and CIDRs whose X-Forwarded-For is trusted")
+	fs.String("database.driver", "mongo", "Property repository backend (mongo|sqlite)")
 	fs.String("database.path", "./app.db", "Path to the SQLite database file")
+	fs.String("services.dictionary_url", "http://localhost:8085", "Dictionary service URL")
+	fs.String("services.authz_url", "http://localhost:8083", "Authz service URL")
+	fs.String("dictionary.client", "http", "Dictionary client to use (http|fake)")
+	fs.String("dictionary.cache_ttl", "5m", "Dictionary option cache TTL")
+	fs.String("dictionary.stale_ttl", "1h", "Dictionary stale-while-revalidate window")
+	fs.Bool("dictionary.fail_open", false, "Accept classifications when the dictionary is unreachable")
+	fs.String("search.path", "./search.bleve", "Full-text index directory (empty for in-memory)")
+	fs.String("search.default_locale", "es", "Default full-text search locale (en|es|pl)")
+	fs.String("authn.client", "token", "Token verifier to use (token|fake)")
+	fs.String("authz.client", "http", "Authorizer to use (http|fake)")
+	fs.String("media.store", "fs", "Media blob store (fs)")
+	fs.String("media.path", "./media", "Media directory of the fs store")
+	fs.Int64("media.max_upload_bytes", 20<<20, "Maximum size of an uploaded media file")
+	fs.String("pricing.base_currency", "USD", "ISO 4217 currency prices are compared in")
+	fs.Int64("imports.max_upload_bytes", 32<<20, "Maximum size of a bulk import file")
+	fs.String("feeds.refresh", "15m", "How often syndication feeds are regenerated")
+	fs.Int("trash.retention_days", 30, "Days before deleted properties are purged (0 keeps them)")
+	fs.String("trash.purge_interval", "1h", "How often the trash is purged")
+	fs.String("events.bus", "log", "Bus property events are published to (log|webhook|none)")
+	fs.String("events.webhook_url", "", "URL the webhook bus posts events to")
+	fs.String("events.interval", "1s", "How often the event outbox is relayed")
+	fs.Float64("duplicates.threshold", 0.6, "Score from which a property is reported as a likely duplicate")
+	fs.String("appointments.agent_buffer", "30m", "Minimum gap between the appointments of an agent")
+	fs.String("appointments.property_buffer", "15m", "Minimum gap between the appointments of a property")
+	fs.String("appointments.feed_url", "", "Public base URL of this service for calendar feed links")
+	fs.Int("inquiries.rate_limit", 5, "Public inquiries a client can submit per rate window (0 disables the limit)")
+	fs.String("inquiries.rate_window", "1h", "Window of the public inquiry rate limit")
+	fs.String("inquiries.duplicate_window", "24h", "Window repeated inquiries of a contact are dropped in")
+	fs.Int("inquiries.max_links", 2, "Links from which a public inquiry is spam")
 	fs.String("log.level", "info", "Log level (debug, info, error)")
 	fs.Bool("debug.routes", true, "Expose /debug/routes endpoint")
 	fs.Parse(args[1:])
@@ -101,5 +314,52 @@ func LoadConfig(path, envPrefix string, args []string) (*Config, error) {
 		return nil, fmt.Errorf("cannot unmarshal config: %w", err)
 	}
 
+	// Manual environment variable overrides (flattened keys don't play well with nested paths)
+	if val := os.Getenv("ESTATE_DATABASE_DRIVER"); val != "" {
+		cfg.Database.Driver = val
+	}
+	if val := os.Getenv("ESTATE_SEARCH_PATH"); val != "" {
+		cfg.Search.Path = val
+	}
+	if val := os.Getenv("ESTATE_SERVICES_DICTIONARY_URL"); val != "" {
+		cfg.Services.DictionaryURL = val
+	}
+	if val := os.Getenv("ESTATE_DICTIONARY_CLIENT"); val != "" {
+		cfg.Dictionary.Client = val
+	}
+	if val := os.Getenv("ESTATE_SERVICES_AUTHZ_URL"); val != "" {
+		cfg.Services.AuthzURL = val
+	}
+	if val := os.Getenv("ESTATE_AUTHN_CLIENT"); val != "" {
+		cfg.Authn.Client = val
+	}
+	if val := os.Getenv("ESTATE_AUTHN_PUBLIC_KEY"); val != "" {
+		cfg.Authn.PublicKey = val
+	}
+	if val := os.Getenv("ESTATE_AUTHZ_CLIENT"); val != "" {
+		cfg.Authz.Client = val
+	}
+	if val := os.Getenv("ESTATE_MEDIA_PATH"); val != "" {
+		cfg.Media.Path = val
+	}
+	if val := os.Getenv("ESTATE_PRICING_BASE_CURRENCY"); val != "" {
+		cfg.Pricing.BaseCurrency = val
+	}
+	if val := os.Getenv("ESTATE_EVENTS_BUS"); val != "" {
+		cfg.Events.Bus = val
+	}
+	if val := os.Getenv("ESTATE_EVENTS_WEBHOOK_URL"); val != "" {
+		cfg.Events.WebhookURL = val
+	}
+	if val := os.Getenv("ESTATE_APPOINTMENTS_FEED_SECRET"); val != "" {
+		cfg.Appointments.FeedSecret = val
+	}
+	if val := os.Getenv("ESTATE_INQUIRIES_ENCRYPTION_KEY"); val != "" {
+		cfg.Inquiries.EncryptionKey = val
+	}
+	if val := os.Getenv("ESTATE_INQUIRIES_LOOKUP_KEY"); val != "" {
+		cfg.Inquiries.LookupKey = val
+	}
+
 	return cfg, nil
 }
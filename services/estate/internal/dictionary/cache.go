@@ -0,0 +1,126 @@
+package dictionary
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// refreshTimeout bounds background refreshes of stale entries.
+const refreshTimeout = 10 * time.Second
+
+// loadFunc fetches a fresh value for a cache key.
+type loadFunc[V any] func(ctx context.Context) (V, error)
+
+type cacheEntry[V any] struct {
+	value     V
+	fetchedAt time.Time
+}
+
+// readThroughCache caches values by key with a TTL and a stale-while-revalidate window.
+//
+//   - age < ttl: the cached value is returned.
+//   - ttl <= age < ttl+staleTTL: the cached value is returned and refreshed in the background.
+//   - otherwise the value is loaded synchronously. If loading fails because the
+//     dictionary is unavailable and an expired value is still held, it is
+//     returned instead of the error.
+//
+// A load returning ErrOptionNotFound evicts the key.
+type readThroughCache[V any] struct {
+	mu         sync.Mutex
+	entries    map[string]*cacheEntry[V]
+	refreshing map[string]bool
+	ttl        time.Duration
+	staleTTL   time.Duration
+	now        func() time.Time
+	onError    func(key string, err error)
+}
+
+func newReadThroughCache[V any](ttl, staleTTL time.Duration) *readThroughCache[V] {
+	return &readThroughCache[V]{
+		entries:    make(map[string]*cacheEntry[V]),
+		refreshing: make(map[string]bool),
+		ttl:        ttl,
+		staleTTL:   staleTTL,
+		now:        time.Now,
+		onError:    func(string, error) {},
+	}
+}
+
+// get returns the value for key, loading it with load when needed.
+func (c *readThroughCache[V]) get(ctx context.Context, key string, load loadFunc[V]) (V, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	if ok {
+		age := c.now().Sub(entry.fetchedAt)
+		if age < c.ttl {
+			c.mu.Unlock()
+			return entry.value, nil
+		}
+		if age < c.ttl+c.staleTTL {
+			if !c.refreshing[key] {
+				c.refreshing[key] = true
+				go c.refresh(key, load)
+			}
+			c.mu.Unlock()
+			return entry.value, nil
+		}
+	}
+	c.mu.Unlock()
+
+	value, err := load(ctx)
+	if err != nil {
+		if errors.Is(err, ErrOptionNotFound) {
+			c.evict(key)
+		} else if ok {
+			c.onError(key, err)
+			return entry.value, nil
+		}
+		var zero V
+		return zero, err
+	}
+
+	c.put(key, value)
+	return value, nil
+}
+
+func (c *readThroughCache[V]) refresh(key string, load loadFunc[V]) {
+	ctx, cancel := context.WithTimeout(context.Background(), refreshTimeout)
+	defer cancel()
+
+	value, err := load(ctx)
+
+	c.mu.Lock()
+	delete(c.refreshing, key)
+	c.mu.Unlock()
+
+	if err != nil {
+		if errors.Is(err, ErrOptionNotFound) {
+			c.evict(key)
+			return
+		}
+		c.onError(key, err)
+		return
+	}
+	c.put(key, value)
+}
+
+func (c *readThroughCache[V]) put(key string, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = &cacheEntry[V]{value: value, fetchedAt: c.now()}
+}
+
+func (c *readThroughCache[V]) evict(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+}
+
+// invalidate drops every cached entry.
+func (c *readThroughCache[V]) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]*cacheEntry[V])
+}
@@ -0,0 +1,208 @@
+package dictionary
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/pkg/lib/core"
+	"github.com/pulap/pulap/services/estate/internal/config"
+	"github.com/pulap/pulap/services/estate/internal/estate"
+)
+
+var (
+	// ErrOptionNotFound is returned when the dictionary has no option with the requested ID.
+	ErrOptionNotFound = errors.New("option not found")
+
+	// ErrUnavailable is returned when the dictionary cannot be reached and nothing is cached.
+	ErrUnavailable = errors.New("dictionary service unavailable")
+)
+
+const (
+	defaultTimeout  = 5 * time.Second
+	defaultCacheTTL = 5 * time.Minute
+	defaultStaleTTL = time.Hour
+)
+
+// HTTPClient implements estate.Client against the dictionary service HTTP API.
+// Options are cached with a TTL and served stale while they are refreshed in
+// the background, so classification checks keep working through short
+// dictionary outages.
+type HTTPClient struct {
+	http     *core.HTTPClient
+	options  *readThroughCache[*estate.Option]
+	children *readThroughCache[[]estate.Option]
+	failOpen bool
+	xparams  config.XParams
+}
+
+// NewHTTPClient creates a dictionary client from the estate configuration.
+func NewHTTPClient(xparams config.XParams) *HTTPClient {
+	cfg := xparams.Cfg()
+
+	timeout := parseDuration(cfg.Dictionary.Timeout, defaultTimeout)
+	cacheTTL := parseDuration(cfg.Dictionary.CacheTTL, defaultCacheTTL)
+	staleTTL := parseDuration(cfg.Dictionary.StaleTTL, defaultStaleTTL)
+
+	c := &HTTPClient{
+		http: core.NewHTTPClient(core.HTTPClientConfig{
+			BaseURL:    strings.TrimRight(cfg.Services.DictionaryURL, "/"),
+			Timeout:    timeout,
+			MaxRetries: 1,
+			RetryDelay: 250 * time.Millisecond,
+		}),
+		options:  newReadThroughCache[*estate.Option](cacheTTL, staleTTL),
+		children: newReadThroughCache[[]estate.Option](cacheTTL, staleTTL),
+		failOpen: cfg.Dictionary.FailOpen,
+		xparams:  xparams,
+	}
+
+	onError := func(key string, err error) {
+		xparams.Log().Info("serving stale dictionary entry", "key", key, "error", err)
+	}
+	c.options.onError = onError
+	c.children.onError = onError
+
+	return c
+}
+
+// GetOption retrieves a single option by ID.
+func (c *HTTPClient) GetOption(ctx context.Context, id uuid.UUID) (*estate.Option, error) {
+	opt, err := c.options.get(ctx, id.String(), func(ctx context.Context) (*estate.Option, error) {
+		var resp struct {
+			Data estate.Option `json:"data"`
+		}
+		if err := c.http.Get(ctx, "/dictionary/options/"+id.String(), &resp); err != nil {
+			return nil, c.mapError(err)
+		}
+		return &resp.Data, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return opt, nil
+}
+
+// ListOptionsByParent lists all options in a set filtered by parent ID.
+// If parentID is nil, returns root-level options (no parent).
+func (c *HTTPClient) ListOptionsByParent(ctx context.Context, setName string, parentID *uuid.UUID) ([]estate.Option, error) {
+	parent := "null"
+	if parentID != nil {
+		parent = parentID.String()
+	}
+
+	key := setName + "/" + parent
+	opts, err := c.children.get(ctx, key, func(ctx context.Context) ([]estate.Option, error) {
+		var resp struct {
+			Data []estate.Option `json:"data"`
+		}
+		path := fmt.Sprintf("/dictionary/options/set/%s/parent/%s", url.PathEscape(setName), parent)
+		if err := c.http.Get(ctx, path, &resp); err != nil {
+			return nil, c.mapError(err)
+		}
+		return resp.Data, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return opts, nil
+}
+
+// ValidateClassification validates that a classification is valid:
+// - All IDs exist and are active
+// - Category is a root option
+// - Type.parent_id equals CategoryID
+// - Subtype.parent_id equals TypeID (if subtype is provided)
+//
+// When the dictionary is unreachable and the options are not cached, the
+// classification is accepted if the client is configured to fail open;
+// otherwise ErrUnavailable is returned.
+func (c *HTTPClient) ValidateClassification(ctx context.Context, cl estate.Classification) (bool, []string, error) {
+	var errs []string
+
+	category, err := c.GetOption(ctx, cl.CategoryID)
+	if err != nil {
+		if errors.Is(err, ErrOptionNotFound) {
+			return false, append(errs, "category_id not found"), nil
+		}
+		return c.degrade(err)
+	}
+	if !category.Active {
+		errs = append(errs, "category is not active")
+	}
+	if category.ParentID != nil {
+		errs = append(errs, "category_id does not reference a top-level category")
+	}
+
+	typeOpt, err := c.GetOption(ctx, cl.TypeID)
+	if err != nil {
+		if errors.Is(err, ErrOptionNotFound) {
+			return false, append(errs, "type_id not found"), nil
+		}
+		return c.degrade(err)
+	}
+	if !typeOpt.Active {
+		errs = append(errs, "type is not active")
+	}
+	if typeOpt.ParentID == nil || *typeOpt.ParentID != cl.CategoryID {
+		errs = append(errs, "type does not belong to the selected category")
+	}
+
+	if cl.SubtypeID != uuid.Nil {
+		subtype, err := c.GetOption(ctx, cl.SubtypeID)
+		if err != nil {
+			if errors.Is(err, ErrOptionNotFound) {
+				return false, append(errs, "subtype_id not found"), nil
+			}
+			return c.degrade(err)
+		}
+		if !subtype.Active {
+			errs = append(errs, "subtype is not active")
+		}
+		if subtype.ParentID == nil || *subtype.ParentID != cl.TypeID {
+			errs = append(errs, "subtype does not belong to the selected type")
+		}
+	}
+
+	return len(errs) == 0, errs, nil
+}
+
+// Invalidate drops all cached options.
+func (c *HTTPClient) Invalidate() {
+	c.options.invalidate()
+	c.children.invalidate()
+}
+
+// degrade applies the fail-open/fail-closed policy for an unreachable dictionary.
+func (c *HTTPClient) degrade(err error) (bool, []string, error) {
+	if c.failOpen {
+		c.xparams.Log().Info("dictionary unavailable, accepting classification (fail-open)", "error", err)
+		return true, nil, nil
+	}
+	return false, nil, err
+}
+
+// mapError translates transport errors into the package sentinel errors.
+func (c *HTTPClient) mapError(err error) error {
+	var httpErr *core.HTTPError
+	if errors.As(err, &httpErr) && httpErr.IsNotFound() {
+		return ErrOptionNotFound
+	}
+	return fmt.Errorf("%w: %v", ErrUnavailable, err)
+}
+
+func parseDuration(value string, fallback time.Duration) time.Duration {
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return fallback
+	}
+	return d
+}
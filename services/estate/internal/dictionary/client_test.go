@@ -0,0 +1,226 @@
+package dictionary
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/pkg/lib/core"
+	"github.com/pulap/pulap/services/estate/internal/config"
+	"github.com/pulap/pulap/services/estate/internal/estate"
+	"github.com/pulap/pulap/services/estate/internal/fake"
+)
+
+var (
+	residentialID = uuid.MustParse("00000000-0000-0000-0001-000000000001")
+	commercialID  = uuid.MustParse("00000000-0000-0000-0001-000000000002")
+	houseID       = uuid.MustParse("00000000-0000-0000-0002-000000000001")
+	bungalowID    = uuid.MustParse("00000000-0000-0000-0003-000000000001")
+)
+
+// dictionaryServer serves the fake dictionary seed over the dictionary service HTTP API.
+type dictionaryServer struct {
+	*httptest.Server
+	hits atomic.Int32
+	down atomic.Bool
+}
+
+func newDictionaryServer(t *testing.T) *dictionaryServer {
+	t.Helper()
+
+	seed := fake.NewDictionary()
+	ds := &dictionaryServer{}
+	ds.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ds.hits.Add(1)
+		if ds.down.Load() {
+			core.RespondError(w, http.StatusServiceUnavailable, "down")
+			return
+		}
+
+		path := strings.TrimPrefix(r.URL.Path, "/dictionary/options/")
+		if strings.HasPrefix(path, "set/") {
+			parts := strings.Split(path, "/") // set/{name}/parent/{id}
+			var parentID *uuid.UUID
+			if parts[3] != "null" {
+				id := uuid.MustParse(parts[3])
+				parentID = &id
+			}
+			opts, err := seed.ListOptionsByParent(r.Context(), parts[1], parentID)
+			if err != nil {
+				core.RespondError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+			core.RespondSuccess(w, opts)
+			return
+		}
+
+		opt, err := seed.GetOption(r.Context(), uuid.MustParse(path))
+		if err != nil {
+			core.RespondError(w, http.StatusNotFound, "Option not found")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(core.SuccessResponse{Data: opt})
+	}))
+	t.Cleanup(ds.Close)
+	return ds
+}
+
+func newTestClient(url string, failOpen bool) *HTTPClient {
+	cfg := config.New()
+	cfg.Services.DictionaryURL = url
+	cfg.Dictionary.Timeout = "1s"
+	cfg.Dictionary.CacheTTL = "1m"
+	cfg.Dictionary.StaleTTL = "1m"
+	cfg.Dictionary.FailOpen = failOpen
+	return NewHTTPClient(config.NewXParams(nil, cfg))
+}
+
+func TestHTTPClientValidateClassification(t *testing.T) {
+	srv := newDictionaryServer(t)
+	client := newTestClient(srv.URL, false)
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		c         estate.Classification
+		wantValid bool
+		wantMsg   string
+	}{
+		{
+			name:      "valid chain",
+			c:         estate.Classification{CategoryID: residentialID, TypeID: houseID, SubtypeID: bungalowID},
+			wantValid: true,
+		},
+		{
+			name:      "type outside category",
+			c:         estate.Classification{CategoryID: commercialID, TypeID: houseID},
+			wantValid: false,
+			wantMsg:   "type does not belong to the selected category",
+		},
+		{
+			name:      "type used as category",
+			c:         estate.Classification{CategoryID: houseID, TypeID: bungalowID},
+			wantValid: false,
+			wantMsg:   "category_id does not reference a top-level category",
+		},
+		{
+			name:      "unknown category",
+			c:         estate.Classification{CategoryID: uuid.New(), TypeID: houseID},
+			wantValid: false,
+			wantMsg:   "category_id not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			valid, errs, err := client.ValidateClassification(ctx, tt.c)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if valid != tt.wantValid {
+				t.Fatalf("expected valid=%v, got %v (errors: %v)", tt.wantValid, valid, errs)
+			}
+			if tt.wantMsg != "" && !contains(errs, tt.wantMsg) {
+				t.Errorf("expected error %q, got %v", tt.wantMsg, errs)
+			}
+		})
+	}
+}
+
+func TestHTTPClientCachesOptions(t *testing.T) {
+	srv := newDictionaryServer(t)
+	client := newTestClient(srv.URL, false)
+	ctx := context.Background()
+
+	c := estate.Classification{CategoryID: residentialID, TypeID: houseID, SubtypeID: bungalowID}
+	for i := 0; i < 3; i++ {
+		if valid, errs, err := client.ValidateClassification(ctx, c); err != nil || !valid {
+			t.Fatalf("expected valid classification, got valid=%v errs=%v err=%v", valid, errs, err)
+		}
+	}
+
+	if hits := srv.hits.Load(); hits != 3 {
+		t.Errorf("expected 3 dictionary calls (one per option), got %d", hits)
+	}
+
+	children, err := client.ListOptionsByParent(ctx, "estate_type", &residentialID)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(children) != 2 {
+		t.Errorf("expected 2 residential types, got %d", len(children))
+	}
+}
+
+func TestHTTPClientServesStaleWhileRevalidating(t *testing.T) {
+	srv := newDictionaryServer(t)
+	client := newTestClient(srv.URL, false)
+	ctx := context.Background()
+
+	now := time.Now()
+	client.options.now = func() time.Time { return now }
+
+	if _, err := client.GetOption(ctx, residentialID); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// Inside the stale window the cached value is returned even if the service is down.
+	srv.down.Store(true)
+	now = now.Add(90 * time.Second)
+	opt, err := client.GetOption(ctx, residentialID)
+	if err != nil {
+		t.Fatalf("expected stale option, got error %v", err)
+	}
+	if opt.Key != "residential" {
+		t.Errorf("expected key 'residential', got '%s'", opt.Key)
+	}
+
+	// Past the stale window a failed reload still falls back to the expired value.
+	now = now.Add(time.Hour)
+	if _, err := client.GetOption(ctx, residentialID); err != nil {
+		t.Fatalf("expected expired option to be served on error, got %v", err)
+	}
+}
+
+func TestHTTPClientDegradation(t *testing.T) {
+	srv := newDictionaryServer(t)
+	srv.down.Store(true)
+	ctx := context.Background()
+	c := estate.Classification{CategoryID: residentialID, TypeID: houseID}
+
+	closed := newTestClient(srv.URL, false)
+	valid, _, err := closed.ValidateClassification(ctx, c)
+	if !errors.Is(err, ErrUnavailable) {
+		t.Errorf("fail-closed: expected ErrUnavailable, got %v", err)
+	}
+	if valid {
+		t.Error("fail-closed: expected classification to be rejected")
+	}
+
+	open := newTestClient(srv.URL, true)
+	valid, errs, err := open.ValidateClassification(ctx, c)
+	if err != nil {
+		t.Errorf("fail-open: expected no error, got %v", err)
+	}
+	if !valid || len(errs) != 0 {
+		t.Errorf("fail-open: expected classification to be accepted, got valid=%v errs=%v", valid, errs)
+	}
+}
+
+func contains(values []string, want string) bool {
+	for _, v := range values {
+		if v == want {
+			return true
+		}
+	}
+	return false
+}
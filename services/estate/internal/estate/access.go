@@ -0,0 +1,301 @@
+package estate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/pkg/lib/core"
+	"github.com/pulap/pulap/services/estate/internal/config"
+)
+
+// Property permissions, evaluated in the estate scope of each property.
+const (
+	PermissionRead   = "estates:read"
+	PermissionWrite  = "estates:write"
+	PermissionDelete = "estates:delete"
+)
+
+// ScopeEstate is the authz scope type of property grants. The scope ID is a
+// user or team: a grant in {estate, team-1} covers the properties owned by
+// team-1 or assigned to it through TeamID. Global grants cover every property.
+const ScopeEstate = "estate"
+
+// scopeGlobal is the authz scope type of grants that apply everywhere.
+const scopeGlobal = "global"
+
+// Access is what a user may reach under one permission: every property, or
+// the properties owned by or assigned to the team of one of Scopes.
+type Access struct {
+	All    bool
+	Scopes []string
+}
+
+// NewAccess builds the Access granted by the authz scopes of a permission.
+func NewAccess(scopes []core.AuthzScope) Access {
+	var a Access
+	for _, s := range scopes {
+		switch {
+		case s.Type == scopeGlobal:
+			return Access{All: true}
+		case s.Type == ScopeEstate && s.ID != "":
+			a.Scopes = append(a.Scopes, s.ID)
+		}
+	}
+	return a
+}
+
+// Allows returns true if the access reaches the property.
+func (a Access) Allows(p *Property) bool {
+	return a.AllowsOwner(p.OwnerID, p.TeamID)
+}
+
+// AllowsOwner returns true if the access reaches what the user or team owns.
+func (a Access) AllowsOwner(ownerID, teamID string) bool {
+	if a.All {
+		return true
+	}
+	for _, s := range a.Scopes {
+		if s == ownerID || s == teamID {
+			return true
+		}
+	}
+	return false
+}
+
+// Restrict limits the query to the properties the access reaches.
+func (a Access) Restrict(q *PropertyQuery) {
+	if a.All {
+		return
+	}
+	q.Scoped = true
+	q.Scopes = a.Scopes
+}
+
+// RestrictDevelopments limits the query to the developments the access reaches.
+func (a Access) RestrictDevelopments(q *DevelopmentQuery) {
+	if a.All {
+		return
+	}
+	q.Scoped = true
+	q.Scopes = a.Scopes
+}
+
+// PropertyAccess authenticates the requests of the estate handlers and
+// checks the permissions users hold on properties, and so on the records
+// that belong to them. The handlers share one.
+type PropertyAccess struct {
+	repo          Repo
+	authenticator core.Authenticator
+	authorizer    Authorizer
+	xparams       config.XParams
+}
+
+// NewPropertyAccess creates the PropertyAccess of the estate handlers,
+// reading properties from repo. A nil authorizer denies every permission
+// check.
+func NewPropertyAccess(repo Repo, authenticator core.Authenticator, authorizer Authorizer, xparams config.XParams) *PropertyAccess {
+	return &PropertyAccess{
+		repo:          repo,
+		authenticator: authenticator,
+		authorizer:    authorizer,
+		xparams:       xparams,
+	}
+}
+
+// authn returns the middleware verifying the bearer token of requests.
+func (a *PropertyAccess) authn() func(http.Handler) http.Handler {
+	return core.AuthMiddleware(a.authenticator, a.xparams.Log())
+}
+
+// access returns what the authenticated user may reach under the
+// permission, responding with the error when it returns false.
+func (a *PropertyAccess) access(w http.ResponseWriter, r *http.Request, permission string) (Access, bool) {
+	userID, ok := core.GetUserIDFromContext(r.Context())
+	if !ok || userID == "" {
+		core.RespondError(w, http.StatusUnauthorized, "Authentication required")
+		return Access{}, false
+	}
+	if a.authorizer == nil {
+		core.RespondError(w, http.StatusForbidden, "Permission checks are not configured")
+		return Access{}, false
+	}
+
+	scopes, err := a.authorizer.ListScopes(r.Context(), userID, permission, ScopeEstate)
+	if err != nil {
+		a.log(r).Error("authz scopes failed", "error", err, "perm", permission, "user_id", userID)
+		core.RespondError(w, http.StatusBadGateway, "Could not check permissions")
+		return Access{}, false
+	}
+	return NewAccess(scopes), true
+}
+
+// authorizeProperty checks the authenticated user holds the permission on
+// the property, responding with the error when it returns false. Properties
+// the user cannot read are reported as not found.
+func (a *PropertyAccess) authorizeProperty(w http.ResponseWriter, r *http.Request, property *Property, permission string) bool {
+	return a.authorizePropertyAs(w, r, property, permission, "Property not found")
+}
+
+// authorizePropertyAs is authorizeProperty reporting properties the user
+// cannot read with the given not found message, e.g. for the records that
+// belong to them.
+func (a *PropertyAccess) authorizePropertyAs(w http.ResponseWriter, r *http.Request, property *Property, permission, notFound string) bool {
+	access, ok := a.access(w, r, permission)
+	if !ok {
+		return false
+	}
+	if access.Allows(property) {
+		return true
+	}
+
+	if permission != PermissionRead {
+		read, ok := a.access(w, r, PermissionRead)
+		if !ok {
+			return false
+		}
+		if read.Allows(property) {
+			a.log(r).Info("property access denied", "id", property.ID.String(), "perm", permission)
+			core.RespondError(w, http.StatusForbidden, fmt.Sprintf("Permission %s is required", permission))
+			return false
+		}
+	}
+	core.RespondError(w, http.StatusNotFound, notFound)
+	return false
+}
+
+// loadProperty loads the property of the request and checks the user holds
+// the permission on it, responding with the error when it returns false.
+func (a *PropertyAccess) loadProperty(w http.ResponseWriter, r *http.Request, permission string) (*Property, bool) {
+	log := a.log(r)
+	id, ok := a.parseIDParam(w, r, log)
+	if !ok {
+		return nil, false
+	}
+
+	property, err := a.repo.Get(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			core.RespondError(w, http.StatusNotFound, "Property not found")
+			return nil, false
+		}
+		log.Error("error loading property", "error", err, "id", id.String())
+		core.RespondError(w, http.StatusInternalServerError, "Could not retrieve property")
+		return nil, false
+	}
+	if !a.authorizeProperty(w, r, property, permission) {
+		return nil, false
+	}
+	return property, true
+}
+
+// authorizePropertyOf checks the user holds the permission on the property
+// a record belongs to, responding with the error when it returns false.
+// Records of properties the user cannot read, or that no longer exist, are
+// reported with the not found message.
+func (a *PropertyAccess) authorizePropertyOf(w http.ResponseWriter, r *http.Request, propertyID uuid.UUID, permission, notFound string) bool {
+	property, err := a.repo.Get(r.Context(), propertyID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			core.RespondError(w, http.StatusNotFound, notFound)
+			return false
+		}
+		a.log(r).Error("error loading property", "error", err, "id", propertyID.String())
+		core.RespondError(w, http.StatusInternalServerError, "Could not retrieve property")
+		return false
+	}
+	return a.authorizePropertyAs(w, r, property, permission, notFound)
+}
+
+// readableProperties returns the properties with the IDs the user can
+// read, by ID, responding with the error when it returns false.
+func (a *PropertyAccess) readableProperties(w http.ResponseWriter, r *http.Request, ids []uuid.UUID) (map[uuid.UUID]*Property, bool) {
+	access, ok := a.access(w, r, PermissionRead)
+	if !ok {
+		return nil, false
+	}
+
+	properties, err := a.findProperties(r.Context(), ids, &access)
+	if err != nil {
+		a.log(r).Error("error searching properties", "error", err)
+		core.RespondError(w, http.StatusInternalServerError, "Could not retrieve properties")
+		return nil, false
+	}
+	return properties, true
+}
+
+// findProperties returns the properties with the IDs, by ID, those the
+// access reaches when it is not nil.
+func (a *PropertyAccess) findProperties(ctx context.Context, ids []uuid.UUID, access *Access) (map[uuid.UUID]*Property, error) {
+	seen := map[uuid.UUID]bool{}
+	var unique []uuid.UUID
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	properties := map[uuid.UUID]*Property{}
+	for chunk := range slices.Chunk(unique, MaxSearchLimit) {
+		query := PropertyQuery{IDs: chunk, Limit: len(chunk)}
+		if access != nil {
+			access.Restrict(&query)
+		}
+		query.Normalize()
+
+		page, err := a.repo.Search(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range page.Items {
+			properties[p.ID] = p
+		}
+	}
+	return properties, nil
+}
+
+// checkPermission returns (0, "") if the user holds the permission on the
+// resource; otherwise the status and message to respond with.
+func (a *PropertyAccess) checkPermission(ctx context.Context, userID, permission, resource string) (int, string) {
+	if a.authorizer == nil {
+		return http.StatusForbidden, "Permission checks are not configured"
+	}
+
+	allowed, err := a.authorizer.CheckPermission(ctx, userID, permission, resource)
+	if err != nil {
+		a.xparams.Log().Error("authz check failed", "error", err, "perm", permission, "resource", resource)
+		return http.StatusBadGateway, "Could not check permissions"
+	}
+	if !allowed {
+		return http.StatusForbidden, fmt.Sprintf("Permission %s is required", permission)
+	}
+	return 0, ""
+}
+
+func (a *PropertyAccess) log(r *http.Request) core.Logger {
+	return a.xparams.Log().With("request_id", r.Context().Value("request_id"))
+}
+
+func (a *PropertyAccess) parseIDParam(w http.ResponseWriter, r *http.Request, log core.Logger) (uuid.UUID, bool) {
+	idStr := chi.URLParam(r, "id")
+	if idStr == "" {
+		log.Debug("missing id parameter")
+		core.RespondError(w, http.StatusBadRequest, "Missing id parameter")
+		return uuid.Nil, false
+	}
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		log.Debug("invalid id parameter", "id", idStr, "error", err)
+		core.RespondError(w, http.StatusBadRequest, "Invalid id parameter")
+		return uuid.Nil, false
+	}
+
+	return id, true
+}
@@ -0,0 +1,74 @@
+package estate
+
+import (
+	"testing"
+
+	"github.com/pulap/pulap/pkg/lib/core"
+)
+
+func TestNewAccess(t *testing.T) {
+	access := NewAccess([]core.AuthzScope{
+		{Type: ScopeEstate, ID: "agent-1"},
+		{Type: "team", ID: "team-9"},
+		{Type: ScopeEstate, ID: ""},
+		{Type: ScopeEstate, ID: "team-1"},
+	})
+	if access.All || len(access.Scopes) != 2 || access.Scopes[0] != "agent-1" || access.Scopes[1] != "team-1" {
+		t.Errorf("expected the estate scopes agent-1 and team-1, got %+v", access)
+	}
+
+	access = NewAccess([]core.AuthzScope{{Type: ScopeEstate, ID: "agent-1"}, {Type: "global"}})
+	if !access.All {
+		t.Errorf("expected a global scope to grant every property, got %+v", access)
+	}
+
+	if access := NewAccess(nil); access.All || len(access.Scopes) != 0 {
+		t.Errorf("expected no access without scopes, got %+v", access)
+	}
+}
+
+func TestAccessAllows(t *testing.T) {
+	access := Access{Scopes: []string{"agent-1", "team-1"}}
+
+	tests := []struct {
+		name    string
+		ownerID string
+		teamID  string
+		want    bool
+	}{
+		{"owned", "agent-1", "", true},
+		{"of the team", "agent-2", "team-1", true},
+		{"of another agent", "agent-2", "team-2", false},
+		{"unowned", "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Property{OwnerID: tt.ownerID, TeamID: tt.teamID}
+			if got := access.Allows(p); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+			if !(Access{All: true}).Allows(p) {
+				t.Error("expected global access to allow every property")
+			}
+		})
+	}
+}
+
+func TestAccessRestrict(t *testing.T) {
+	q := PropertyQuery{OwnerID: "agent-2"}
+	Access{All: true}.Restrict(&q)
+	if q.Scoped {
+		t.Errorf("expected global access to leave the query unscoped, got %+v", q)
+	}
+
+	Access{Scopes: []string{"agent-1"}}.Restrict(&q)
+	if !q.Scoped || len(q.Scopes) != 1 || q.Scopes[0] != "agent-1" || q.OwnerID != "agent-2" {
+		t.Errorf("expected the query scoped to agent-1 keeping its filters, got %+v", q)
+	}
+
+	q = PropertyQuery{}
+	Access{}.Restrict(&q)
+	if !q.Scoped || len(q.Scopes) != 0 {
+		t.Errorf("expected the query scoped to nothing, got %+v", q)
+	}
+}
@@ -0,0 +1,399 @@
+package estate
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/pkg/lib/core"
+)
+
+// Appointment statuses
+const (
+	AppointmentScheduled = "scheduled"
+	AppointmentConfirmed = "confirmed" // The client confirmed they will attend
+	AppointmentCompleted = "completed"
+	AppointmentCancelled = "cancelled" // Frees the slot
+	AppointmentNoShow    = "no_show"   // The client did not attend
+)
+
+// AppointmentStatuses lists every appointment status in lifecycle order.
+var AppointmentStatuses = []string{AppointmentScheduled, AppointmentConfirmed, AppointmentCompleted, AppointmentCancelled, AppointmentNoShow}
+
+// appointmentTransitions is the appointment lifecycle: the statuses
+// reachable from each status. Completed, cancelled and no-show are final.
+var appointmentTransitions = map[string][]string{
+	AppointmentScheduled: {AppointmentConfirmed, AppointmentCompleted, AppointmentCancelled, AppointmentNoShow},
+	AppointmentConfirmed: {AppointmentCompleted, AppointmentCancelled, AppointmentNoShow},
+	AppointmentCompleted: {},
+	AppointmentCancelled: {},
+	AppointmentNoShow:    {},
+}
+
+// MaxAppointmentDuration is the longest time slot of an appointment.
+const MaxAppointmentDuration = 4 * time.Hour
+
+// DefaultAppointmentBuffers keeps half an hour between the appointments of
+// an agent, to get from one property to the next, and a quarter of an hour
+// between those of a property.
+var DefaultAppointmentBuffers = AppointmentBuffers{Agent: 30 * time.Minute, Property: 15 * time.Minute}
+
+// The calendar feed of an agent covers the appointments from
+// CalendarFeedPast ago to CalendarFeedAhead from now, at most
+// MaxCalendarFeedAppointments of them.
+const (
+	CalendarFeedPast            = 30 * 24 * time.Hour
+	CalendarFeedAhead           = 365 * 24 * time.Hour
+	MaxCalendarFeedAppointments = 1000
+)
+
+var (
+	// ErrAppointmentNotFound is returned when an appointment does not exist.
+	ErrAppointmentNotFound = errors.New("appointment not found")
+
+	// ErrAppointmentConflict is returned when an appointment is too close
+	// to another of the same agent or property.
+	ErrAppointmentConflict = errors.New("appointment conflicts with another appointment")
+
+	// ErrAppointmentClosed is returned when rescheduling a completed,
+	// cancelled or no-show appointment.
+	ErrAppointmentClosed = errors.New("appointment is closed")
+)
+
+// Appointment is a viewing of a property: the agent showing it, the client
+// visiting it and when.
+type Appointment struct {
+	ID         uuid.UUID `json:"id"`
+	PropertyID uuid.UUID `json:"property_id"`
+	AgentID    string    `json:"agent_id"` // User ID of the agent
+	Client     Contact   `json:"client"`
+	StartsAt   time.Time `json:"starts_at"`
+	EndsAt     time.Time `json:"ends_at"`
+	Status     string    `json:"status"` // One of AppointmentStatuses
+	Notes      string    `json:"notes,omitempty"`
+	Revision   int64     `json:"revision"` // Incremented on every write, exposed as the ETag
+	CreatedAt  time.Time `json:"created_at"`
+	CreatedBy  string    `json:"created_by"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	UpdatedBy  string    `json:"updated_by"`
+}
+
+// AppointmentBuffers are the minimum gaps between appointments. Time slots
+// closer than that conflict, so a zero buffer only rules out overlaps.
+type AppointmentBuffers struct {
+	Agent    time.Duration // Between the appointments of an agent
+	Property time.Duration // Between the appointments of a property
+}
+
+// GetID returns the ID of the Appointment (implements Identifiable interface).
+func (a *Appointment) GetID() uuid.UUID {
+	return a.ID
+}
+
+// ResourceType returns the resource type for URL generation.
+func (a *Appointment) ResourceType() string {
+	return "appointment"
+}
+
+// BeforeCreate sets the ID, timestamps and first revision.
+func (a *Appointment) BeforeCreate() {
+	if a.ID == uuid.Nil {
+		a.ID = core.GenerateNewID()
+	}
+	if a.Status == "" {
+		a.Status = AppointmentScheduled
+	}
+	a.CreatedAt = time.Now()
+	a.UpdatedAt = a.CreatedAt
+	a.Revision = 1
+}
+
+// BeforeUpdate sets the update timestamp.
+func (a *Appointment) BeforeUpdate() {
+	a.UpdatedAt = time.Now()
+}
+
+// Normalize trims the appointment and keeps its time slot in UTC minutes.
+func (a *Appointment) Normalize() {
+	a.AgentID = strings.TrimSpace(a.AgentID)
+	a.Client.Normalize()
+	a.StartsAt = a.StartsAt.UTC().Truncate(time.Minute)
+	a.EndsAt = a.EndsAt.UTC().Truncate(time.Minute)
+	a.Notes = strings.TrimSpace(a.Notes)
+}
+
+// Validate checks the appointment before it is stored.
+func (a *Appointment) Validate() []ValidationError {
+	var errors []ValidationError
+
+	if a.PropertyID == uuid.Nil {
+		errors = append(errors, ValidationError{Field: "property_id", Message: "property_id is required"})
+	}
+	if a.AgentID == "" {
+		errors = append(errors, ValidationError{Field: "agent_id", Message: "agent_id is required"})
+	}
+	errors = append(errors, a.Client.Validate("client")...)
+
+	switch {
+	case a.StartsAt.IsZero():
+		errors = append(errors, ValidationError{Field: "starts_at", Message: "starts_at is required"})
+	case !a.EndsAt.After(a.StartsAt):
+		errors = append(errors, ValidationError{Field: "ends_at", Message: "ends_at must be after starts_at"})
+	case a.EndsAt.Sub(a.StartsAt) > MaxAppointmentDuration:
+		errors = append(errors, ValidationError{Field: "ends_at", Message: fmt.Sprintf("appointments cannot last more than %s", MaxAppointmentDuration)})
+	}
+
+	if !slices.Contains(AppointmentStatuses, a.Status) {
+		errors = append(errors, ValidationError{Field: "status", Message: "status must be one of: " + strings.Join(AppointmentStatuses, ", ")})
+	}
+
+	return errors
+}
+
+// Closed returns true if the appointment was completed, cancelled or
+// missed.
+func (a *Appointment) Closed() bool {
+	return len(appointmentTransitions[a.Status]) == 0
+}
+
+// Blocks returns true if the appointment holds its time slot: every
+// appointment but a cancelled one.
+func (a *Appointment) Blocks() bool {
+	return a.Status != AppointmentCancelled
+}
+
+// CanTransitionAppointment returns true if the lifecycle allows going from
+// one appointment status to another.
+func CanTransitionAppointment(from, to string) bool {
+	return slices.Contains(appointmentTransitions[from], to)
+}
+
+// AllowedAppointmentTransitions returns the statuses reachable from an
+// appointment status.
+func AllowedAppointmentTransitions(status string) []string {
+	return appointmentTransitions[status]
+}
+
+// Transition moves the appointment to another status.
+func (a *Appointment) Transition(to string) error {
+	if !slices.Contains(AppointmentStatuses, to) {
+		return fmt.Errorf("%w: %q", ErrInvalidStatus, to)
+	}
+	if !CanTransitionAppointment(a.Status, to) {
+		return fmt.Errorf("%w: from %s to %s", ErrInvalidTransition, a.Status, to)
+	}
+	a.Status = to
+	return nil
+}
+
+// Reschedule moves an open appointment to another time slot. A confirmed
+// appointment needs to be confirmed again.
+func (a *Appointment) Reschedule(startsAt, endsAt time.Time) error {
+	if a.Closed() {
+		return fmt.Errorf("%w: it is %s", ErrAppointmentClosed, a.Status)
+	}
+	if a.StartsAt.Equal(startsAt) && a.EndsAt.Equal(endsAt) {
+		return nil
+	}
+	a.StartsAt, a.EndsAt = startsAt, endsAt
+	a.Status = AppointmentScheduled
+	return nil
+}
+
+// AgentWindow returns the time slot, widened by the agent buffer, that
+// other appointments of the agent cannot overlap.
+func (b AppointmentBuffers) AgentWindow(a *Appointment) (from, to time.Time) {
+	return a.StartsAt.Add(-b.Agent), a.EndsAt.Add(b.Agent)
+}
+
+// PropertyWindow returns the time slot, widened by the property buffer,
+// that other appointments of the property cannot overlap.
+func (b AppointmentBuffers) PropertyWindow(a *Appointment) (from, to time.Time) {
+	return a.StartsAt.Add(-b.Property), a.EndsAt.Add(b.Property)
+}
+
+// Conflicts returns true if both appointments block their slots and are
+// too close for the agent or the property they share.
+func (b AppointmentBuffers) Conflicts(a, other *Appointment) bool {
+	return b.conflict(a, other) != ""
+}
+
+// ConflictError returns ErrAppointmentConflict explaining why the
+// appointment conflicts with another.
+func (b AppointmentBuffers) ConflictError(a, other *Appointment) error {
+	holder := b.conflict(a, other)
+	if holder == "" {
+		holder = "agent or property"
+	}
+	return fmt.Errorf("%w: the %s has appointment %s from %s to %s", ErrAppointmentConflict, holder,
+		other.ID, other.StartsAt.UTC().Format(time.RFC3339), other.EndsAt.UTC().Format(time.RFC3339))
+}
+
+// conflict returns who the appointments conflict for, "agent" or
+// "property", or "" if they do not.
+func (b AppointmentBuffers) conflict(a, other *Appointment) string {
+	if a.ID == other.ID || !a.Blocks() || !other.Blocks() {
+		return ""
+	}
+	overlaps := func(from, to time.Time) bool {
+		return other.StartsAt.Before(to) && other.EndsAt.After(from)
+	}
+	switch {
+	case a.AgentID == other.AgentID && overlaps(b.AgentWindow(a)):
+		return "agent"
+	case a.PropertyID == other.PropertyID && overlaps(b.PropertyWindow(a)):
+		return "property"
+	}
+	return ""
+}
+
+// AppointmentQuery filters the appointments listed. Zero values mean "no
+// filter".
+type AppointmentQuery struct {
+	PropertyIDs []uuid.UUID
+	AgentIDs    []string
+	Statuses    []string
+	From        *time.Time // Appointments ending after this time
+	To          *time.Time // Appointments starting before this time
+	Limit       int
+}
+
+// Normalize fills defaults and validates the query.
+func (q *AppointmentQuery) Normalize() []ValidationError {
+	var errors []ValidationError
+	if q.Limit <= 0 {
+		q.Limit = DefaultSearchLimit
+	}
+	if q.Limit > MaxSearchLimit {
+		q.Limit = MaxSearchLimit
+	}
+	for _, s := range q.Statuses {
+		if !slices.Contains(AppointmentStatuses, s) {
+			errors = append(errors, ValidationError{Field: "status", Message: "status must be one of: " + strings.Join(AppointmentStatuses, ", ")})
+			break
+		}
+	}
+	if q.From != nil && q.To != nil && !q.To.After(*q.From) {
+		errors = append(errors, ValidationError{Field: "to", Message: "to must be after from"})
+	}
+	return errors
+}
+
+// ParseAppointmentQuery reads an AppointmentQuery from URL parameters:
+// agent and status take comma-separated lists, from and to RFC 3339 times.
+func ParseAppointmentQuery(values url.Values) (AppointmentQuery, []ValidationError) {
+	p := queryParser{values: values}
+	q := AppointmentQuery{
+		AgentIDs: splitList(values.Get("agent")),
+		Statuses: splitList(values.Get("status")),
+		From:     p.time("from"),
+		To:       p.time("to"),
+		Limit:    p.int("limit"),
+	}
+	errors := p.errors
+	errors = append(errors, q.Normalize()...)
+	return q, errors
+}
+
+// AppointmentRepo defines the repository interface for Appointment aggregates.
+type AppointmentRepo interface {
+	// CreateAppointment stores a new appointment unless it blocks a slot
+	// another appointment conflicts with under the buffers, returning
+	// ErrAppointmentConflict. Concurrent writes of conflicting appointments
+	// may all fail, but never all succeed.
+	CreateAppointment(ctx context.Context, a *Appointment, buffers AppointmentBuffers) error
+
+	// GetAppointment retrieves an appointment, or ErrAppointmentNotFound.
+	GetAppointment(ctx context.Context, id uuid.UUID) (*Appointment, error)
+
+	// SaveAppointment updates an appointment while its stored revision is
+	// still a.Revision, which is then incremented. Conflicts are checked
+	// like in CreateAppointment. It returns ErrAppointmentNotFound,
+	// ErrAppointmentConflict or ErrRevisionConflict.
+	SaveAppointment(ctx context.Context, a *Appointment, buffers AppointmentBuffers) error
+
+	// ListAppointments lists the appointments matching the query, soonest
+	// first. The query is expected to be normalized.
+	ListAppointments(ctx context.Context, query AppointmentQuery) ([]*Appointment, error)
+}
+
+// Appointments schedules appointments under the configured buffers and
+// signs the calendar feeds of agents.
+type Appointments struct {
+	repo    AppointmentRepo
+	buffers AppointmentBuffers
+	feedKey []byte // Empty disables the calendar feeds
+	feedURL string // Public base URL of the feeds, empty for relative links
+}
+
+// NewAppointments returns the appointments stored in repo. Calendar feed
+// tokens are signed with feedKey, an empty key disabling the feeds, and
+// linked under feedURL.
+func NewAppointments(repo AppointmentRepo, buffers AppointmentBuffers, feedKey []byte, feedURL string) (*Appointments, error) {
+	if buffers.Agent < 0 || buffers.Property < 0 {
+		return nil, fmt.Errorf("appointment buffers cannot be negative")
+	}
+	return &Appointments{repo: repo, buffers: buffers, feedKey: feedKey, feedURL: strings.TrimSuffix(feedURL, "/")}, nil
+}
+
+// Buffers returns the buffers appointments are scheduled under.
+func (s *Appointments) Buffers() AppointmentBuffers {
+	return s.buffers
+}
+
+// Create stores a new appointment unless it conflicts with another.
+func (s *Appointments) Create(ctx context.Context, a *Appointment) error {
+	return s.repo.CreateAppointment(ctx, a, s.buffers)
+}
+
+// Get retrieves an appointment.
+func (s *Appointments) Get(ctx context.Context, id uuid.UUID) (*Appointment, error) {
+	return s.repo.GetAppointment(ctx, id)
+}
+
+// Save updates an appointment unless it conflicts with another.
+func (s *Appointments) Save(ctx context.Context, a *Appointment) error {
+	return s.repo.SaveAppointment(ctx, a, s.buffers)
+}
+
+// List lists the appointments matching the query, soonest first.
+func (s *Appointments) List(ctx context.Context, query AppointmentQuery) ([]*Appointment, error) {
+	return s.repo.ListAppointments(ctx, query)
+}
+
+// FeedToken returns the token that opens the calendar feed of an agent, or
+// false when the feeds are disabled.
+func (s *Appointments) FeedToken(agentID string) (string, bool) {
+	if len(s.feedKey) == 0 {
+		return "", false
+	}
+	mac := hmac.New(sha256.New, s.feedKey)
+	mac.Write([]byte("calendar:" + agentID))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), true
+}
+
+// FeedURL returns the link calendar apps subscribe to for the feed of an
+// agent, or false when the feeds are disabled.
+func (s *Appointments) FeedURL(agentID string) (string, bool) {
+	token, ok := s.FeedToken(agentID)
+	if !ok {
+		return "", false
+	}
+	return s.feedURL + "/agents/" + url.PathEscape(agentID) + "/calendar.ics?token=" + token, true
+}
+
+// CheckFeedToken returns true if the token opens the calendar feed of the
+// agent.
+func (s *Appointments) CheckFeedToken(agentID, token string) bool {
+	want, ok := s.FeedToken(agentID)
+	return ok && hmac.Equal([]byte(token), []byte(want))
+}
@@ -0,0 +1,216 @@
+package estate
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// viewingDay is the morning test appointments are scheduled on.
+var viewingDay = time.Date(2026, 5, 4, 9, 0, 0, 0, time.UTC)
+
+func newTestAppointment(propertyID uuid.UUID, agentID string, hour, minute int) *Appointment {
+	start := viewingDay.Add(time.Duration(hour-9)*time.Hour + time.Duration(minute)*time.Minute)
+	return &Appointment{
+		ID:         uuid.New(),
+		PropertyID: propertyID,
+		AgentID:    agentID,
+		Client:     Contact{Name: "Marta Ruiz", Email: "marta@example.com"},
+		StartsAt:   start,
+		EndsAt:     start.Add(45 * time.Minute),
+		Status:     AppointmentScheduled,
+	}
+}
+
+func TestAppointmentNormalizeAndValidate(t *testing.T) {
+	a := newTestAppointment(uuid.New(), " agent-1 ", 10, 0)
+	madrid := time.FixedZone("CEST", 2*60*60)
+	a.StartsAt = time.Date(2026, 5, 4, 12, 0, 42, 0, madrid)
+	a.EndsAt = a.StartsAt.Add(45 * time.Minute)
+	a.Client = Contact{Name: " Marta Ruiz ", Email: " Marta@Example.com "}
+	a.Normalize()
+
+	if a.AgentID != "agent-1" || a.Client.Email != "marta@example.com" {
+		t.Errorf("unexpected normalized appointment: %q %+v", a.AgentID, a.Client)
+	}
+	if want := time.Date(2026, 5, 4, 10, 0, 0, 0, time.UTC); !a.StartsAt.Equal(want) || a.StartsAt.Location() != time.UTC {
+		t.Errorf("expected the slot to start at %v, got %v", want, a.StartsAt)
+	}
+	if errs := a.Validate(); len(errs) > 0 {
+		t.Fatalf("expected a valid appointment, got %v", errs)
+	}
+
+	tests := []struct {
+		name   string
+		change func(a *Appointment)
+		field  string
+	}{
+		{"property", func(a *Appointment) { a.PropertyID = uuid.Nil }, "property_id"},
+		{"agent", func(a *Appointment) { a.AgentID = "" }, "agent_id"},
+		{"client contact", func(a *Appointment) { a.Client.Email = "" }, "client"},
+		{"start", func(a *Appointment) { a.StartsAt = time.Time{} }, "starts_at"},
+		{"end before start", func(a *Appointment) { a.EndsAt = a.StartsAt }, "ends_at"},
+		{"too long", func(a *Appointment) { a.EndsAt = a.StartsAt.Add(MaxAppointmentDuration + time.Minute) }, "ends_at"},
+		{"status", func(a *Appointment) { a.Status = "booked" }, "status"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			invalid := *a
+			tt.change(&invalid)
+			errs := invalid.Validate()
+			if len(errs) != 1 || errs[0].Field != tt.field {
+				t.Errorf("expected one %s error, got %v", tt.field, errs)
+			}
+		})
+	}
+}
+
+func TestAppointmentTransition(t *testing.T) {
+	a := newTestAppointment(uuid.New(), "agent-1", 10, 0)
+
+	if err := a.Transition("booked"); !errors.Is(err, ErrInvalidStatus) {
+		t.Errorf("expected ErrInvalidStatus, got %v", err)
+	}
+	if err := a.Transition(AppointmentConfirmed); err != nil {
+		t.Fatalf("Transition: %v", err)
+	}
+	if err := a.Transition(AppointmentScheduled); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("expected ErrInvalidTransition back to scheduled, got %v", err)
+	}
+	if err := a.Transition(AppointmentNoShow); err != nil {
+		t.Fatalf("Transition: %v", err)
+	}
+	if !a.Closed() || !a.Blocks() {
+		t.Errorf("expected a missed appointment to be closed and keep its slot")
+	}
+	if err := a.Transition(AppointmentCancelled); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("expected ErrInvalidTransition from a final status, got %v", err)
+	}
+}
+
+func TestAppointmentReschedule(t *testing.T) {
+	a := newTestAppointment(uuid.New(), "agent-1", 10, 0)
+	a.Status = AppointmentConfirmed
+
+	if err := a.Reschedule(a.StartsAt, a.EndsAt); err != nil || a.Status != AppointmentConfirmed {
+		t.Errorf("expected the same slot to keep the confirmation, got %q and %v", a.Status, err)
+	}
+	later := a.StartsAt.Add(2 * time.Hour)
+	if err := a.Reschedule(later, later.Add(time.Hour)); err != nil {
+		t.Fatalf("Reschedule: %v", err)
+	}
+	if a.Status != AppointmentScheduled || !a.StartsAt.Equal(later) {
+		t.Errorf("expected a scheduled appointment at %v, got %q at %v", later, a.Status, a.StartsAt)
+	}
+
+	a.Status = AppointmentCompleted
+	if err := a.Reschedule(later, later.Add(time.Hour)); !errors.Is(err, ErrAppointmentClosed) {
+		t.Errorf("expected ErrAppointmentClosed, got %v", err)
+	}
+}
+
+func TestAppointmentBuffersConflicts(t *testing.T) {
+	buffers := AppointmentBuffers{Agent: 30 * time.Minute, Property: 15 * time.Minute}
+	flat, house := uuid.New(), uuid.New()
+	booked := newTestAppointment(flat, "agent-1", 10, 0) // 10:00 to 10:45
+
+	tests := []struct {
+		name         string
+		property     uuid.UUID
+		agent        string
+		hour, minute int
+		status       string
+		want         string
+	}{
+		{"same agent within the buffer", house, "agent-1", 11, 0, AppointmentScheduled, "agent"},
+		{"same agent after the buffer", house, "agent-1", 11, 15, AppointmentScheduled, ""},
+		{"same property within the buffer", flat, "agent-2", 9, 5, AppointmentScheduled, "property"},
+		{"same property after the buffer", flat, "agent-2", 11, 0, AppointmentScheduled, ""},
+		{"unrelated", house, "agent-2", 10, 0, AppointmentScheduled, ""},
+		{"cancelled", flat, "agent-1", 10, 0, AppointmentCancelled, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestAppointment(tt.property, tt.agent, tt.hour, tt.minute)
+			a.Status = tt.status
+			if got := buffers.conflict(a, booked); got != tt.want {
+				t.Errorf("expected conflict %q, got %q", tt.want, got)
+			}
+			if got := buffers.Conflicts(booked, a); got != (tt.want != "") {
+				t.Errorf("expected a symmetric conflict %v, got %v", tt.want != "", got)
+			}
+		})
+	}
+
+	if buffers.Conflicts(booked, booked) {
+		t.Errorf("expected an appointment not to conflict with itself")
+	}
+	err := buffers.ConflictError(newTestAppointment(house, "agent-1", 11, 0), booked)
+	if !errors.Is(err, ErrAppointmentConflict) || !strings.Contains(err.Error(), "the agent has appointment "+booked.ID.String()) {
+		t.Errorf("unexpected conflict error: %v", err)
+	}
+}
+
+func TestParseAppointmentQuery(t *testing.T) {
+	q, errs := ParseAppointmentQuery(url.Values{
+		"agent":  {"agent-1,agent-2"},
+		"status": {"scheduled,confirmed"},
+		"from":   {"2026-05-04T00:00:00Z"},
+		"to":     {"2026-05-11T00:00:00Z"},
+	})
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if len(q.AgentIDs) != 2 || len(q.Statuses) != 2 || q.From == nil || q.To == nil || q.Limit != DefaultSearchLimit {
+		t.Errorf("unexpected query: %+v", q)
+	}
+
+	if _, errs := ParseAppointmentQuery(url.Values{"status": {"booked"}}); len(errs) != 1 || errs[0].Field != "status" {
+		t.Errorf("expected a status error, got %v", errs)
+	}
+	if _, errs := ParseAppointmentQuery(url.Values{"from": {"2026-05-11T00:00:00Z"}, "to": {"2026-05-04T00:00:00Z"}}); len(errs) != 1 || errs[0].Field != "to" {
+		t.Errorf("expected a to error, got %v", errs)
+	}
+	if _, errs := ParseAppointmentQuery(url.Values{"from": {"monday"}}); len(errs) != 1 || errs[0].Field != "from" {
+		t.Errorf("expected a from error, got %v", errs)
+	}
+}
+
+func TestAppointmentsFeedToken(t *testing.T) {
+	disabled, err := NewAppointments(nil, DefaultAppointmentBuffers, nil, "")
+	if err != nil {
+		t.Fatalf("NewAppointments: %v", err)
+	}
+	if _, ok := disabled.FeedToken("agent-1"); ok {
+		t.Errorf("expected feeds to be disabled without a key")
+	}
+	if disabled.CheckFeedToken("agent-1", "") {
+		t.Errorf("expected no token to open a disabled feed")
+	}
+
+	s, err := NewAppointments(nil, DefaultAppointmentBuffers, []byte("secret"), "https://estate.example.com/")
+	if err != nil {
+		t.Fatalf("NewAppointments: %v", err)
+	}
+	token, ok := s.FeedToken("agent-1")
+	if !ok || token == "" {
+		t.Fatalf("expected a token")
+	}
+	if !s.CheckFeedToken("agent-1", token) {
+		t.Errorf("expected the token to open the feed of its agent")
+	}
+	if s.CheckFeedToken("agent-2", token) {
+		t.Errorf("expected the token not to open the feed of another agent")
+	}
+	if u, _ := s.FeedURL("agent-1"); u != "https://estate.example.com/agents/agent-1/calendar.ics?token="+token {
+		t.Errorf("unexpected feed URL %q", u)
+	}
+
+	if _, err := NewAppointments(nil, AppointmentBuffers{Agent: -time.Minute}, nil, ""); err == nil {
+		t.Errorf("expected negative buffers to be rejected")
+	}
+}
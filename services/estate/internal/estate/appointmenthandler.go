@@ -0,0 +1,497 @@
+package estate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/pkg/lib/core"
+	"github.com/pulap/pulap/pkg/lib/telemetry"
+	"github.com/pulap/pulap/services/estate/internal/config"
+)
+
+// AppointmentHandler handles HTTP requests for the Appointment aggregate
+// and the calendars of agents.
+type AppointmentHandler struct {
+	*PropertyAccess
+	appointments *Appointments
+	tlm          *telemetry.HTTP
+}
+
+// NewAppointmentHandler creates a new AppointmentHandler scheduling with
+// appointments.
+func NewAppointmentHandler(appointments *Appointments, access *PropertyAccess, xparams config.XParams) *AppointmentHandler {
+	return &AppointmentHandler{
+		PropertyAccess: access,
+		appointments:   appointments,
+		tlm: telemetry.NewHTTP(
+			telemetry.WithTracer(xparams.Tracer()),
+			telemetry.WithMetrics(xparams.Metrics()),
+		),
+	}
+}
+
+// RegisterRoutes registers the appointment and agent calendar routes. The
+// calendar feeds of agents take a token of their own instead of a bearer
+// token, as calendar apps pull them.
+func (h *AppointmentHandler) RegisterRoutes(r chi.Router) {
+	authn := h.authn()
+
+	r.With(authn).Get("/estates/{id}/appointments", h.ListPropertyAppointments)
+	r.With(authn).Post("/estates/{id}/appointments", h.CreateAppointment)
+	r.Route("/appointments", func(r chi.Router) {
+		r.Use(authn)
+		r.Get("/", h.ListAppointments)
+		r.Get("/{id}", h.GetAppointment)
+		r.Put("/{id}", h.UpdateAppointment)
+		r.Post("/{id}/transitions", h.TransitionAppointment)
+	})
+	r.Route("/agents", func(r chi.Router) {
+		r.Get("/{agent}/calendar.ics", h.GetAgentCalendarFeed)
+		r.With(authn).Get("/{agent}/calendar", h.GetAgentCalendar)
+	})
+}
+
+// AppointmentTransitionRequest is the payload of POST
+// /appointments/{id}/transitions.
+type AppointmentTransitionRequest struct {
+	To string `json:"to"`
+}
+
+// AppointmentMeta describes an appointment: the statuses it can move to.
+type AppointmentMeta struct {
+	Allowed []string `json:"allowed"`
+}
+
+// AppointmentListMeta describes a list of appointments and names the
+// properties they take place at, by ID.
+type AppointmentListMeta struct {
+	Count      int               `json:"count"`
+	Limit      int               `json:"limit"`
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+// CalendarSubscription is the calendar feed of an agent.
+type CalendarSubscription struct {
+	AgentID string `json:"agent_id"`
+	URL     string `json:"url"` // Relative to this service unless a public URL is configured
+}
+
+// CreateAppointment handles POST /estates/{id}/appointments
+// Appointments start scheduled, with the authenticated user as the agent
+// unless agent_id is given, and cannot be closer to another appointment of
+// the agent or the property than the configured buffers. Requires
+// PermissionWrite on the property.
+func (h *AppointmentHandler) CreateAppointment(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "AppointmentHandler.CreateAppointment")
+	defer finish()
+	log := h.log(r)
+	ctx := r.Context()
+
+	property, ok := h.loadProperty(w, r, PermissionWrite)
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, MaxBodyBytes)
+	defer r.Body.Close()
+
+	var a Appointment
+	if err := json.NewDecoder(r.Body).Decode(&a); err != nil {
+		log.Debug("error decoding JSON", "error", err)
+		core.RespondError(w, http.StatusBadRequest, "Invalid JSON payload")
+		return
+	}
+
+	a.ID = uuid.Nil
+	a.PropertyID = property.ID
+	if a.AgentID = strings.TrimSpace(a.AgentID); a.AgentID == "" {
+		a.AgentID = requestActor(r)
+	}
+	a.Status = AppointmentScheduled
+	a.Normalize()
+	if !respondAppointmentInvalid(w, &a, log) {
+		return
+	}
+
+	a.CreatedBy = requestActor(r)
+	a.UpdatedBy = a.CreatedBy
+	if err := h.appointments.Create(ctx, &a); err != nil {
+		h.respondAppointmentSaveError(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", ETag(a.Revision))
+	w.WriteHeader(http.StatusCreated)
+	core.RespondSuccessWithMeta(w, &a, appointmentMeta(&a), appointmentLinks(&a)...)
+}
+
+// ListPropertyAppointments handles GET /estates/{id}/appointments
+// The appointments of the property, soonest first, cancelled ones included.
+// agent and status filter by comma-separated lists, from and to by a time
+// range the appointments overlap.
+func (h *AppointmentHandler) ListPropertyAppointments(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "AppointmentHandler.ListPropertyAppointments")
+	defer finish()
+	log := h.log(r)
+	ctx := r.Context()
+
+	property, ok := h.loadProperty(w, r, PermissionRead)
+	if !ok {
+		return
+	}
+
+	query, validationErrors := ParseAppointmentQuery(r.URL.Query())
+	if len(validationErrors) > 0 {
+		log.Debug("invalid appointment query", "errors", validationErrors)
+		core.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid query: %s", validationErrors[0].Message))
+		return
+	}
+	query.PropertyIDs = []uuid.UUID{property.ID}
+
+	appointments, err := h.appointments.List(ctx, query)
+	if err != nil {
+		log.Error("error listing appointments", "error", err, "property_id", property.ID.String())
+		core.RespondError(w, http.StatusInternalServerError, "Could not retrieve appointments")
+		return
+	}
+	if appointments == nil {
+		appointments = []*Appointment{}
+	}
+
+	core.RespondSuccessWithMeta(w, appointments, AppointmentListMeta{Count: len(appointments), Limit: query.Limit})
+}
+
+// ListAppointments handles GET /appointments
+// The appointments at readable properties, soonest first, filtered like
+// GET /estates/{id}/appointments, for calendars across properties. limit
+// caps the appointments considered before access is checked.
+func (h *AppointmentHandler) ListAppointments(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "AppointmentHandler.ListAppointments")
+	defer finish()
+	log := h.log(r)
+	ctx := r.Context()
+
+	query, validationErrors := ParseAppointmentQuery(r.URL.Query())
+	if len(validationErrors) > 0 {
+		log.Debug("invalid appointment query", "errors", validationErrors)
+		core.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid query: %s", validationErrors[0].Message))
+		return
+	}
+
+	appointments, err := h.appointments.List(ctx, query)
+	if err != nil {
+		log.Error("error listing appointments", "error", err)
+		core.RespondError(w, http.StatusInternalServerError, "Could not retrieve appointments")
+		return
+	}
+
+	ids := make([]uuid.UUID, 0, len(appointments))
+	for _, a := range appointments {
+		ids = append(ids, a.PropertyID)
+	}
+	readable, ok := h.readableProperties(w, r, ids)
+	if !ok {
+		return
+	}
+
+	list := []*Appointment{}
+	names := map[string]string{}
+	for _, a := range appointments {
+		if p := readable[a.PropertyID]; p != nil {
+			list = append(list, a)
+			names[p.ID.String()] = p.Name
+		}
+	}
+
+	core.RespondSuccessWithMeta(w, list, AppointmentListMeta{Count: len(list), Limit: query.Limit, Properties: names})
+}
+
+// GetAppointment handles GET /appointments/{id}
+// Requires PermissionRead on the property of the appointment.
+func (h *AppointmentHandler) GetAppointment(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "AppointmentHandler.GetAppointment")
+	defer finish()
+
+	a, ok := h.loadAppointment(w, r, PermissionRead)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("ETag", ETag(a.Revision))
+	core.RespondSuccessWithMeta(w, a, appointmentMeta(a), appointmentLinks(a)...)
+}
+
+// UpdateAppointment handles PUT /appointments/{id}
+// Replaces the agent, client, time slot and notes of an open appointment.
+// Moving it to another slot asks for a new confirmation. Status changes go
+// through POST /appointments/{id}/transitions. An If-Match header makes it
+// conditional on the appointment revision.
+func (h *AppointmentHandler) UpdateAppointment(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "AppointmentHandler.UpdateAppointment")
+	defer finish()
+	log := h.log(r)
+	ctx := r.Context()
+
+	r.Body = http.MaxBytesReader(w, r.Body, MaxBodyBytes)
+	defer r.Body.Close()
+
+	var req Appointment
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Debug("error decoding JSON", "error", err)
+		core.RespondError(w, http.StatusBadRequest, "Invalid JSON payload")
+		return
+	}
+
+	a, ok := h.loadAppointment(w, r, PermissionWrite)
+	if !ok {
+		return
+	}
+	if header := r.Header.Get("If-Match"); header != "" && !IfMatch(header, a.Revision) {
+		w.Header().Set("ETag", ETag(a.Revision))
+		core.RespondError(w, http.StatusPreconditionFailed, "Appointment was modified, reload and try again")
+		return
+	}
+	if req.Status != "" && req.Status != a.Status {
+		core.RespondError(w, http.StatusConflict, "Status cannot be updated; use POST /appointments/{id}/transitions")
+		return
+	}
+
+	req.Normalize()
+	if err := a.Reschedule(req.StartsAt, req.EndsAt); err != nil {
+		core.RespondError(w, http.StatusConflict, capitalize(err.Error()))
+		return
+	}
+	a.AgentID, a.Client, a.Notes = req.AgentID, req.Client, req.Notes
+	if !respondAppointmentInvalid(w, a, log) {
+		return
+	}
+
+	a.UpdatedBy = requestActor(r)
+	if err := h.appointments.Save(ctx, a); err != nil {
+		h.respondAppointmentSaveError(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", ETag(a.Revision))
+	core.RespondSuccessWithMeta(w, a, appointmentMeta(a), appointmentLinks(a)...)
+}
+
+// TransitionAppointment handles POST /appointments/{id}/transitions
+// Confirms, completes, cancels or marks an appointment as missed.
+// Cancelling frees its time slot. Requires PermissionWrite on the property
+// of the appointment.
+func (h *AppointmentHandler) TransitionAppointment(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "AppointmentHandler.TransitionAppointment")
+	defer finish()
+	log := h.log(r)
+	ctx := r.Context()
+
+	r.Body = http.MaxBytesReader(w, r.Body, MaxBodyBytes)
+	defer r.Body.Close()
+
+	var req AppointmentTransitionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Debug("error decoding transition", "error", err)
+		core.RespondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	req.To = strings.ToLower(strings.TrimSpace(req.To))
+
+	a, ok := h.loadAppointment(w, r, PermissionWrite)
+	if !ok {
+		return
+	}
+	if header := r.Header.Get("If-Match"); header != "" && !IfMatch(header, a.Revision) {
+		w.Header().Set("ETag", ETag(a.Revision))
+		core.RespondError(w, http.StatusPreconditionFailed, "Appointment was modified, reload and try again")
+		return
+	}
+
+	if err := a.Transition(req.To); err != nil {
+		if errors.Is(err, ErrInvalidStatus) {
+			core.RespondError(w, http.StatusBadRequest, "Status must be one of: "+strings.Join(AppointmentStatuses, ", "))
+			return
+		}
+		core.RespondError(w, http.StatusConflict, capitalize(err.Error()))
+		return
+	}
+
+	a.UpdatedBy = requestActor(r)
+	if err := h.appointments.Save(ctx, a); err != nil {
+		h.respondAppointmentSaveError(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", ETag(a.Revision))
+	core.RespondSuccessWithMeta(w, a, appointmentMeta(a), appointmentLinks(a)...)
+}
+
+// GetAgentCalendar handles GET /agents/{agent}/calendar
+// Returns the link to subscribe to the calendar feed of an agent from a
+// calendar app. Agents get their own; others need PermissionRead over
+// the properties the agent owns.
+func (h *AppointmentHandler) GetAgentCalendar(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "AppointmentHandler.GetAgentCalendar")
+	defer finish()
+
+	agentID := chi.URLParam(r, "agent")
+	if userID, _ := core.GetUserIDFromContext(r.Context()); userID != agentID {
+		access, ok := h.access(w, r, PermissionRead)
+		if !ok {
+			return
+		}
+		if !access.AllowsOwner(agentID, "") {
+			core.RespondError(w, http.StatusForbidden, fmt.Sprintf("Permission %s is required", PermissionRead))
+			return
+		}
+	}
+
+	feedURL, ok := h.appointments.FeedURL(agentID)
+	if !ok {
+		core.RespondError(w, http.StatusNotFound, "Calendar feeds are not enabled")
+		return
+	}
+
+	core.RespondSuccess(w, CalendarSubscription{AgentID: agentID, URL: feedURL},
+		core.Link{Rel: core.RelSelf, Href: "/agents/" + agentID + "/calendar"})
+}
+
+// GetAgentCalendarFeed handles GET /agents/{agent}/calendar.ics
+// The iCalendar feed of an agent, from CalendarFeedPast ago on, that
+// calendar apps poll without credentials. The token from GET
+// /agents/{agent}/calendar stands in for them; a wrong token is reported as
+// not found.
+func (h *AppointmentHandler) GetAgentCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "AppointmentHandler.GetAgentCalendarFeed")
+	defer finish()
+	log := h.log(r)
+	ctx := r.Context()
+
+	agentID := chi.URLParam(r, "agent")
+	if !h.appointments.CheckFeedToken(agentID, r.URL.Query().Get("token")) {
+		core.RespondError(w, http.StatusNotFound, "Calendar not found")
+		return
+	}
+
+	now := time.Now()
+	from, to := now.Add(-CalendarFeedPast), now.Add(CalendarFeedAhead)
+	appointments, err := h.appointments.List(ctx, AppointmentQuery{
+		AgentIDs: []string{agentID},
+		From:     &from,
+		To:       &to,
+		Limit:    MaxCalendarFeedAppointments,
+	})
+	if err != nil {
+		log.Error("error listing appointments", "error", err, "agent_id", agentID)
+		core.RespondError(w, http.StatusInternalServerError, "Could not retrieve calendar")
+		return
+	}
+
+	ids := make([]uuid.UUID, 0, len(appointments))
+	for _, a := range appointments {
+		ids = append(ids, a.PropertyID)
+	}
+	// The token grants the agent's calendar whatever their access, as it
+	// only shows where their own appointments take place
+	properties, err := h.findProperties(ctx, ids, nil)
+	if err != nil {
+		log.Error("error searching properties", "error", err, "agent_id", agentID)
+		core.RespondError(w, http.StatusInternalServerError, "Could not retrieve calendar")
+		return
+	}
+
+	events := make([]CalendarEvent, 0, len(appointments))
+	for _, a := range appointments {
+		events = append(events, AppointmentEvent(a, properties[a.PropertyID]))
+	}
+
+	w.Header().Set("Content-Type", ICalendarContentType)
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	if err := WriteICalendar(w, "Pulap viewings", events, now); err != nil {
+		log.Error("error writing calendar", "error", err, "agent_id", agentID)
+	}
+}
+
+// loadAppointment loads the appointment of the request and checks the user
+// holds the permission on its property, responding with the error when it
+// returns false. Appointments at properties the user cannot read, or that
+// no longer exist, are reported as not found.
+func (h *AppointmentHandler) loadAppointment(w http.ResponseWriter, r *http.Request, permission string) (*Appointment, bool) {
+	log := h.log(r)
+
+	id, ok := h.parseIDParam(w, r, log)
+	if !ok {
+		return nil, false
+	}
+
+	a, err := h.appointments.Get(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, ErrAppointmentNotFound) {
+			core.RespondError(w, http.StatusNotFound, "Appointment not found")
+			return nil, false
+		}
+		log.Error("error loading appointment", "error", err, "id", id.String())
+		core.RespondError(w, http.StatusInternalServerError, "Could not retrieve appointment")
+		return nil, false
+	}
+
+	if !h.authorizePropertyOf(w, r, a.PropertyID, permission, "Appointment not found") {
+		return nil, false
+	}
+	return a, true
+}
+
+// respondAppointmentSaveError responds to a failed appointment write.
+func (h *AppointmentHandler) respondAppointmentSaveError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, ErrAppointmentNotFound):
+		core.RespondError(w, http.StatusNotFound, "Appointment not found")
+	case errors.Is(err, ErrAppointmentConflict):
+		core.RespondError(w, http.StatusConflict, capitalize(err.Error()))
+	case errors.Is(err, ErrRevisionConflict) && r.Header.Get("If-Match") != "":
+		core.RespondError(w, http.StatusPreconditionFailed, "Appointment was modified, reload and try again")
+	case errors.Is(err, ErrRevisionConflict):
+		core.RespondError(w, http.StatusConflict, "Appointments were modified concurrently, try again")
+	default:
+		h.log(r).Error("cannot save appointment", "error", err)
+		core.RespondError(w, http.StatusInternalServerError, "Could not save appointment")
+	}
+}
+
+// respondAppointmentInvalid responds 400 and returns false if the
+// appointment does not validate.
+func respondAppointmentInvalid(w http.ResponseWriter, a *Appointment, log core.Logger) bool {
+	validationErrors := a.Validate()
+	if len(validationErrors) == 0 {
+		return true
+	}
+	log.Debug("validation failed", "errors", validationErrors)
+	core.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Validation failed: %s", validationErrors[0].Message))
+	return false
+}
+
+func appointmentMeta(a *Appointment) AppointmentMeta {
+	allowed := AllowedAppointmentTransitions(a.Status)
+	if allowed == nil {
+		allowed = []string{}
+	}
+	return AppointmentMeta{Allowed: allowed}
+}
+
+// appointmentLinks links an appointment and the appointments of its
+// property.
+func appointmentLinks(a *Appointment) []core.Link {
+	self := "/appointments/" + a.ID.String()
+	return []core.Link{
+		{Rel: core.RelSelf, Href: self},
+		{Rel: core.RelUpdate, Href: self},
+		{Rel: core.RelCollection, Href: "/estates/" + a.PropertyID.String() + "/appointments"},
+	}
+}
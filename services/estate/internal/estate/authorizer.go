@@ -0,0 +1,17 @@
+package estate
+
+import (
+	"context"
+
+	"github.com/pulap/pulap/pkg/lib/core"
+)
+
+// Authorizer checks whether a user holds a permission, globally or on a
+// resource. core.AuthzHTTPClient implements it against the authz service.
+type Authorizer interface {
+	CheckPermission(ctx context.Context, userID, permission, resource string) (bool, error)
+
+	// ListScopes returns the scopes of scopeType in which the user holds the
+	// permission; a global scope means everywhere.
+	ListScopes(ctx context.Context, userID, permission, scopeType string) ([]core.AuthzScope, error)
+}
@@ -1,6 +1,18 @@
 package estate
 
-import "github.com/google/uuid"
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// Dictionary sets holding the classification options.
+const (
+	CategorySetName = "estate_category"
+	TypeSetName     = "estate_type"
+	SubtypeSetName  = "estate_subtype"
+)
 
 // Classification represents the hierarchical taxonomy of a property.
 // It stores references (IDs) to fake options, not labels.
@@ -35,3 +47,41 @@ func (c Classification) Validate() []string {
 
 	return errors
 }
+
+// classificationKeys resolves classification IDs to dictionary keys, caching
+// them for its lifetime, e.g. a feed generation or a request.
+type classificationKeys struct {
+	dict  Client
+	cache map[uuid.UUID]string
+}
+
+func newClassificationKeys(dict Client) *classificationKeys {
+	return &classificationKeys{dict: dict, cache: map[uuid.UUID]string{}}
+}
+
+func (k *classificationKeys) classification(ctx context.Context, c Classification) (FeedClassification, error) {
+	var fc FeedClassification
+	for _, s := range []struct {
+		id  uuid.UUID
+		key *string
+	}{
+		{c.CategoryID, &fc.Category},
+		{c.TypeID, &fc.Type},
+		{c.SubtypeID, &fc.Subtype},
+	} {
+		if s.id == uuid.Nil {
+			continue
+		}
+		key, ok := k.cache[s.id]
+		if !ok {
+			opt, err := k.dict.GetOption(ctx, s.id)
+			if err != nil {
+				return fc, fmt.Errorf("cannot resolve classification option %s: %w", s.id, err)
+			}
+			key = opt.Key
+			k.cache[s.id] = key
+		}
+		*s.key = key
+	}
+	return fc, nil
+}
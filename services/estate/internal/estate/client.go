@@ -31,6 +31,7 @@ type Option struct {
 	ID          uuid.UUID  `json:"id"`
 	SetID       uuid.UUID  `json:"set_id"`
 	ParentID    *uuid.UUID `json:"parent_id,omitempty"`
+	Locale      string     `json:"locale,omitempty"`
 	ShortCode   string     `json:"short_code"`
 	Key         string     `json:"key"`
 	Label       string     `json:"label"`
@@ -0,0 +1,41 @@
+package estate
+
+import (
+	"net/mail"
+	"strings"
+)
+
+// Contact is a person the agency deals with about a property, e.g. a
+// tenant, stored with the record it belongs to.
+type Contact struct {
+	Name  string `json:"name"`
+	Email string `json:"email,omitempty"`
+	Phone string `json:"phone,omitempty"`
+}
+
+// Normalize trims the contact and lowercases its email.
+func (c *Contact) Normalize() {
+	c.Name = strings.TrimSpace(c.Name)
+	c.Email = strings.ToLower(strings.TrimSpace(c.Email))
+	c.Phone = strings.TrimSpace(c.Phone)
+}
+
+// Validate checks the contact has a name and a way to reach it. Messages
+// are prefixed with the field the contact is stored in, e.g. "tenant".
+func (c Contact) Validate(field string) []ValidationError {
+	var errors []ValidationError
+
+	if c.Name == "" {
+		errors = append(errors, ValidationError{Field: field, Message: field + ".name is required"})
+	}
+	if c.Email == "" && c.Phone == "" {
+		errors = append(errors, ValidationError{Field: field, Message: field + ".email or " + field + ".phone is required"})
+	}
+	if c.Email != "" {
+		if addr, err := mail.ParseAddress(c.Email); err != nil || addr.Address != c.Email {
+			errors = append(errors, ValidationError{Field: field, Message: field + ".email must be an email address"})
+		}
+	}
+
+	return errors
+}
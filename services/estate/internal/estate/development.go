@@ -0,0 +1,491 @@
+package estate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/pkg/lib/core"
+	"github.com/pulap/pulap/services/estate/internal/money"
+)
+
+// Development kinds
+const (
+	DevelopmentProject  = "development" // A new-construction project, possibly of several buildings
+	DevelopmentBuilding = "building"    // A single multi-unit building
+)
+
+// DevelopmentKinds lists the accepted Development.Kind values.
+var DevelopmentKinds = []string{DevelopmentProject, DevelopmentBuilding}
+
+// Development stages
+const (
+	StagePlanned           = "planned"
+	StageUnderConstruction = "under_construction"
+	StageCompleted         = "completed"
+)
+
+// DevelopmentStages lists the accepted Development.Stage values.
+var DevelopmentStages = []string{StagePlanned, StageUnderConstruction, StageCompleted}
+
+// Unit availability, rolled up from the statuses of the units
+const (
+	AvailabilityNoUnits     = "no_units"
+	AvailabilityAvailable   = "available"   // At least one unit is available
+	AvailabilityReserved    = "reserved"    // None available, at least one reserved
+	AvailabilitySoldOut     = "sold_out"    // Every unit is sold or rented
+	AvailabilityUnavailable = "unavailable" // The remaining units are drafts or inactive
+)
+
+var (
+	// ErrDevelopmentNotFound is returned when a development does not exist.
+	ErrDevelopmentNotFound = errors.New("development not found")
+
+	// ErrDevelopmentHasUnits is returned when deleting a development that
+	// still has units.
+	ErrDevelopmentHasUnits = errors.New("development has units")
+)
+
+// Development is a building or new-construction project whose units are
+// properties referencing it through Property.DevelopmentID. It holds the
+// data its units share: units inherit the location, amenities and year
+// built they leave empty, and keep the values they set (see Inherit).
+type Development struct {
+	ID           uuid.UUID `json:"id"`
+	Kind         string    `json:"kind"` // One of DevelopmentKinds
+	Name         string    `json:"name"`
+	Description  string    `json:"description"`
+	Developer    string    `json:"developer,omitempty"`     // Company building or promoting it
+	Stage        string    `json:"stage,omitempty"`         // One of DevelopmentStages
+	DeliveryDate string    `json:"delivery_date,omitempty"` // Expected or actual delivery, YYYY-MM-DD
+	Location     Location  `json:"location"`                // Units inherit it but their Address.Unit
+	Amenities    []string  `json:"amenities,omitempty"`     // AmenityFlags and Features.Amenities entries shared by the units
+	YearBuilt    int       `json:"year_built,omitempty"`
+	OwnerID      string    `json:"owner_id,omitempty"`
+	TeamID       string    `json:"team_id,omitempty"`
+	Revision     int64     `json:"revision"` // Incremented on every write, exposed as the ETag
+	CreatedAt    time.Time `json:"created_at"`
+	CreatedBy    string    `json:"created_by"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	UpdatedBy    string    `json:"updated_by"`
+}
+
+// GetID returns the ID of the Development (implements Identifiable interface).
+func (d *Development) GetID() uuid.UUID {
+	return d.ID
+}
+
+// ResourceType returns the resource type for URL generation.
+func (d *Development) ResourceType() string {
+	return "development"
+}
+
+// BeforeCreate sets the ID, timestamps and first revision.
+func (d *Development) BeforeCreate() {
+	if d.ID == uuid.Nil {
+		d.ID = core.GenerateNewID()
+	}
+	d.CreatedAt = time.Now()
+	d.UpdatedAt = d.CreatedAt
+	d.Revision = 1
+}
+
+// BeforeUpdate sets the update timestamp.
+func (d *Development) BeforeUpdate() {
+	d.UpdatedAt = time.Now()
+}
+
+// Validate checks the development before it is stored.
+func (d *Development) Validate() []ValidationError {
+	var errors []ValidationError
+
+	if strings.TrimSpace(d.Name) == "" {
+		errors = append(errors, ValidationError{Field: "name", Message: "Name is required"})
+	}
+	if !slices.Contains(DevelopmentKinds, d.Kind) {
+		errors = append(errors, ValidationError{Field: "kind", Message: "kind must be one of: " + strings.Join(DevelopmentKinds, ", ")})
+	}
+	if d.Stage != "" && !slices.Contains(DevelopmentStages, d.Stage) {
+		errors = append(errors, ValidationError{Field: "stage", Message: "stage must be one of: " + strings.Join(DevelopmentStages, ", ")})
+	}
+	if d.DeliveryDate != "" {
+		if _, err := time.Parse(time.DateOnly, d.DeliveryDate); err != nil {
+			errors = append(errors, ValidationError{Field: "delivery_date", Message: "delivery_date must be a YYYY-MM-DD date"})
+		}
+	}
+	for _, msg := range d.Location.Validate() {
+		errors = append(errors, ValidationError{Field: "location", Message: msg})
+	}
+	if d.YearBuilt < 0 {
+		errors = append(errors, ValidationError{Field: "year_built", Message: "year_built cannot be negative"})
+	}
+	for _, a := range d.Amenities {
+		if strings.TrimSpace(a) == "" {
+			errors = append(errors, ValidationError{Field: "amenities", Message: "amenities cannot be empty"})
+			break
+		}
+	}
+
+	return errors
+}
+
+// Inherit fills the fields a unit shares with its development: the
+// location but the unit, the amenities and the year built. A field the unit
+// left empty is inherited, so is one still holding the value of previous,
+// the development before an update; any other value overrides the
+// development's. Amenities are added, and those previous had but the
+// development no longer has are removed. It returns true if the unit
+// changed.
+func (d *Development) Inherit(p *Property, previous *Development) bool {
+	before := struct {
+		Location Location
+		Features Features
+	}{p.Location, p.Features}
+	before.Features.Amenities = slices.Clone(p.Features.Amenities)
+
+	if siteEmpty(p.Location) || (previous != nil && sameSite(p.Location, previous.Location)) {
+		unit := p.Location.Address.Unit
+		p.Location = d.Location
+		p.Location.Raw = maps.Clone(d.Location.Raw)
+		p.Location.Address.Unit = unit
+	}
+
+	if p.Features.YearBuilt == 0 || (previous != nil && p.Features.YearBuilt == previous.YearBuilt) {
+		p.Features.YearBuilt = d.YearBuilt
+	}
+
+	if previous != nil {
+		for _, a := range previous.Amenities {
+			if slices.Contains(d.Amenities, a) {
+				continue
+			}
+			if isAmenityFlag(a) {
+				*amenityFlag(&p.Features, a) = false
+				continue
+			}
+			p.Features.Amenities = slices.DeleteFunc(p.Features.Amenities, func(s string) bool { return s == a })
+		}
+	}
+	for _, a := range d.Amenities {
+		if isAmenityFlag(a) {
+			*amenityFlag(&p.Features, a) = true
+			continue
+		}
+		if !slices.Contains(p.Features.Amenities, a) {
+			p.Features.Amenities = append(p.Features.Amenities, a)
+		}
+	}
+
+	after := struct {
+		Location Location
+		Features Features
+	}{p.Location, p.Features}
+	return !reflect.DeepEqual(before, after)
+}
+
+// siteEmpty returns true if a unit sets no location of its own.
+func siteEmpty(l Location) bool {
+	a := l.Address
+	return a.Street == "" && a.Number == "" && a.City == "" && a.State == "" &&
+		a.PostalCode == "" && a.Country == "" && l.Coordinates.IsZero() && l.ProviderRef == ""
+}
+
+// sameSite returns true if two locations are the same but for the unit.
+func sameSite(a, b Location) bool {
+	a.Address.Unit, b.Address.Unit = "", ""
+	return a.Address == b.Address && a.Coordinates == b.Coordinates && a.Region == b.Region &&
+		a.Provider == b.Provider && a.ProviderRef == b.ProviderRef && a.DisplayName == b.DisplayName
+}
+
+// DevelopmentQuery filters the developments listed. Zero values mean "no
+// filter"; Scoped works like PropertyQuery.Scoped.
+type DevelopmentQuery struct {
+	Kind    string
+	City    string // Case-insensitive exact match
+	Country string // Case-insensitive exact match
+	Scoped  bool
+	Scopes  []string
+	Limit   int
+}
+
+// Normalize fills defaults and validates the query.
+func (q *DevelopmentQuery) Normalize() []ValidationError {
+	var errors []ValidationError
+	if q.Limit <= 0 {
+		q.Limit = DefaultSearchLimit
+	}
+	if q.Limit > MaxSearchLimit {
+		q.Limit = MaxSearchLimit
+	}
+	if q.Kind != "" && !slices.Contains(DevelopmentKinds, q.Kind) {
+		errors = append(errors, ValidationError{Field: "kind", Message: "kind must be one of: " + strings.Join(DevelopmentKinds, ", ")})
+	}
+	q.City = strings.TrimSpace(q.City)
+	q.Country = strings.TrimSpace(q.Country)
+	return errors
+}
+
+// ParseDevelopmentQuery builds a DevelopmentQuery from URL query
+// parameters: kind, city, country and limit.
+func ParseDevelopmentQuery(values url.Values) (DevelopmentQuery, []ValidationError) {
+	p := queryParser{values: values}
+	q := DevelopmentQuery{
+		Kind:    values.Get("kind"),
+		City:    values.Get("city"),
+		Country: values.Get("country"),
+		Limit:   p.int("limit"),
+	}
+	errors := p.errors
+	errors = append(errors, q.Normalize()...)
+	return q, errors
+}
+
+// DevelopmentRepo defines the repository interface for Development
+// aggregates.
+type DevelopmentRepo interface {
+	// CreateDevelopment stores a new development.
+	CreateDevelopment(ctx context.Context, d *Development) error
+
+	// GetDevelopment retrieves a development, or ErrDevelopmentNotFound.
+	GetDevelopment(ctx context.Context, id uuid.UUID) (*Development, error)
+
+	// SaveDevelopment updates a development while its stored revision is
+	// still d.Revision, which is then incremented. It returns
+	// ErrDevelopmentNotFound or ErrRevisionConflict.
+	SaveDevelopment(ctx context.Context, d *Development) error
+
+	// DeleteDevelopment removes a development. A non-zero revision makes it
+	// conditional like SaveDevelopment.
+	DeleteDevelopment(ctx context.Context, id uuid.UUID, revision int64) error
+
+	// ListDevelopments lists the developments matching the query by name.
+	// The query is expected to be normalized.
+	ListDevelopments(ctx context.Context, query DevelopmentQuery) ([]*Development, error)
+}
+
+// UnitStats rolls up the units of a development.
+type UnitStats struct {
+	Units        int            `json:"units"`
+	Available    int            `json:"available"`
+	ByStatus     map[string]int `json:"by_status"`
+	Availability string         `json:"availability"`
+	Prices       []PriceRange   `json:"prices,omitempty"`     // Of the available units, per price type and currency
+	TotalArea    *AreaRange     `json:"total_area,omitempty"` // Of every unit
+	Bedrooms     *CountRange    `json:"bedrooms,omitempty"`   // Of every unit
+}
+
+// PriceRange is the lowest and highest price of a type in a currency.
+type PriceRange struct {
+	Type     string        `json:"type"`
+	Currency string        `json:"currency"`
+	Min      money.Decimal `json:"min"`
+	Max      money.Decimal `json:"max"`
+	Units    int           `json:"units"`
+}
+
+// AreaRange is the smallest and largest area, in square meters.
+type AreaRange struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+// CountRange is the lowest and highest count.
+type CountRange struct {
+	Min int `json:"min"`
+	Max int `json:"max"`
+}
+
+// RollUp computes the stats of the units of a development.
+func RollUp(units []*Property) UnitStats {
+	stats := UnitStats{Units: len(units), ByStatus: map[string]int{}}
+	prices := map[[2]string]*PriceRange{}
+	var keys [][2]string
+
+	for _, u := range units {
+		stats.ByStatus[u.Status]++
+
+		if a := u.Features.TotalArea; a > 0 {
+			if stats.TotalArea == nil {
+				stats.TotalArea = &AreaRange{Min: a, Max: a}
+			}
+			stats.TotalArea.Min = min(stats.TotalArea.Min, a)
+			stats.TotalArea.Max = max(stats.TotalArea.Max, a)
+		}
+		b := u.Features.Bedrooms
+		if stats.Bedrooms == nil {
+			stats.Bedrooms = &CountRange{Min: b, Max: b}
+		}
+		stats.Bedrooms.Min = min(stats.Bedrooms.Min, b)
+		stats.Bedrooms.Max = max(stats.Bedrooms.Max, b)
+
+		if u.Status != StatusAvailable {
+			continue
+		}
+		for _, p := range u.Prices {
+			key := [2]string{p.Type, p.Currency}
+			r, ok := prices[key]
+			if !ok {
+				r = &PriceRange{Type: p.Type, Currency: p.Currency, Min: p.Amount, Max: p.Amount}
+				prices[key] = r
+				keys = append(keys, key)
+			}
+			if p.Amount.Cmp(r.Min) < 0 {
+				r.Min = p.Amount
+			}
+			if p.Amount.Cmp(r.Max) > 0 {
+				r.Max = p.Amount
+			}
+			r.Units++
+		}
+	}
+
+	slices.SortFunc(keys, func(a, b [2]string) int { return strings.Compare(a[0]+" "+a[1], b[0]+" "+b[1]) })
+	for _, k := range keys {
+		stats.Prices = append(stats.Prices, *prices[k])
+	}
+
+	stats.Available = stats.ByStatus[StatusAvailable]
+	switch {
+	case stats.Units == 0:
+		stats.Availability = AvailabilityNoUnits
+	case stats.Available > 0:
+		stats.Availability = AvailabilityAvailable
+	case stats.ByStatus[StatusReserved] > 0:
+		stats.Availability = AvailabilityReserved
+	case stats.ByStatus[StatusSold]+stats.ByStatus[StatusRented] == stats.Units:
+		stats.Availability = AvailabilitySoldOut
+	default:
+		stats.Availability = AvailabilityUnavailable
+	}
+	return stats
+}
+
+// Developments manages developments and keeps their units in step with
+// them.
+type Developments struct {
+	repo Repo
+	devs DevelopmentRepo
+}
+
+// NewDevelopments returns the developments stored in devs, whose units are
+// properties of repo.
+func NewDevelopments(repo Repo, devs DevelopmentRepo) *Developments {
+	return &Developments{repo: repo, devs: devs}
+}
+
+// Create stores a new development.
+func (s *Developments) Create(ctx context.Context, d *Development) error {
+	return s.devs.CreateDevelopment(ctx, d)
+}
+
+// Get retrieves a development.
+func (s *Developments) Get(ctx context.Context, id uuid.UUID) (*Development, error) {
+	return s.devs.GetDevelopment(ctx, id)
+}
+
+// List lists the developments matching the query.
+func (s *Developments) List(ctx context.Context, query DevelopmentQuery) ([]*Development, error) {
+	return s.devs.ListDevelopments(ctx, query)
+}
+
+// Save updates a development, then passes the changes its units inherit on
+// to them. It returns the number of units updated; a unit modified
+// meanwhile is skipped, keeping its values.
+func (s *Developments) Save(ctx context.Context, d *Development) (int, error) {
+	previous, err := s.devs.GetDevelopment(ctx, d.ID)
+	if err != nil {
+		return 0, err
+	}
+	if err := s.devs.SaveDevelopment(ctx, d); err != nil {
+		return 0, err
+	}
+
+	units, err := s.units(ctx, d.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	updated := 0
+	for _, u := range units {
+		if !d.Inherit(u, previous) {
+			continue
+		}
+		u.UpdatedBy = d.UpdatedBy
+		if err := s.repo.Save(ctx, u); err != nil {
+			if errors.Is(err, ErrRevisionConflict) || errors.Is(err, ErrNotFound) {
+				continue
+			}
+			return updated, fmt.Errorf("cannot update unit %s: %w", u.ID, err)
+		}
+		updated++
+	}
+	return updated, nil
+}
+
+// Delete removes a development without units.
+func (s *Developments) Delete(ctx context.Context, id uuid.UUID, revision int64) error {
+	page, err := s.repo.Search(ctx, UnitQuery(id, 1))
+	if err != nil {
+		return fmt.Errorf("cannot search units: %w", err)
+	}
+	if len(page.Items) > 0 {
+		return fmt.Errorf("%w: %d", ErrDevelopmentHasUnits, page.Total)
+	}
+	return s.devs.DeleteDevelopment(ctx, id, revision)
+}
+
+// InheritUnit fills the fields a unit leaves empty from its development. It
+// returns ErrDevelopmentNotFound if the development does not exist; units
+// without a development are left alone.
+func (s *Developments) InheritUnit(ctx context.Context, p *Property) error {
+	if p.DevelopmentID == nil {
+		return nil
+	}
+	d, err := s.devs.GetDevelopment(ctx, *p.DevelopmentID)
+	if err != nil {
+		return err
+	}
+	d.Inherit(p, nil)
+	return nil
+}
+
+// Stats rolls up every unit of a development.
+func (s *Developments) Stats(ctx context.Context, id uuid.UUID) (UnitStats, error) {
+	units, err := s.units(ctx, id)
+	if err != nil {
+		return UnitStats{}, err
+	}
+	return RollUp(units), nil
+}
+
+// units loads every unit of a development.
+func (s *Developments) units(ctx context.Context, id uuid.UUID) ([]*Property, error) {
+	query := UnitQuery(id, MaxSearchLimit)
+	var units []*Property
+	for {
+		page, err := s.repo.Search(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("cannot search units: %w", err)
+		}
+		units = append(units, page.Items...)
+		if page.NextCursor == "" {
+			return units, nil
+		}
+		query.Cursor = page.NextCursor
+	}
+}
+
+// UnitQuery returns the normalized query of the units of a development.
+func UnitQuery(id uuid.UUID, limit int) PropertyQuery {
+	query := PropertyQuery{DevelopmentIDs: []uuid.UUID{id}, Limit: limit}
+	query.Normalize()
+	return query
+}
@@ -0,0 +1,227 @@
+package estate
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/services/estate/internal/money"
+)
+
+func TestDevelopmentInherit(t *testing.T) {
+	d := developmentTestBuilding()
+
+	unit := &Property{Location: Location{Address: Address{Unit: "4B"}}, Features: Features{Bedrooms: 2}}
+	if !d.Inherit(unit, nil) {
+		t.Fatal("expected an empty unit to change")
+	}
+	if unit.Location.Address.Street != "Paseo de la Ribera" || unit.Location.Address.Unit != "4B" || unit.Location.Coordinates != d.Location.Coordinates {
+		t.Errorf("expected the building location with the unit kept, got %+v", unit.Location)
+	}
+	if unit.Features.YearBuilt != 2027 || !unit.Features.Pool || !unit.Features.Elevator {
+		t.Errorf("expected year built and amenity flags inherited, got %+v", unit.Features)
+	}
+	if !slices.Equal(unit.Features.Amenities, []string{"concierge"}) {
+		t.Errorf("expected the concierge amenity, got %v", unit.Features.Amenities)
+	}
+	if d.Inherit(unit, nil) {
+		t.Error("expected inheriting twice to change nothing")
+	}
+
+	// A unit setting its own location and year built keeps them
+	own := &Property{
+		Location: Location{Address: Address{Street: "Calle Río", Number: "1", City: "Madrid", Country: "ES"}},
+		Features: Features{YearBuilt: 1990, Amenities: []string{"gym"}},
+	}
+	d.Inherit(own, nil)
+	if own.Location.Address.Street != "Calle Río" || own.Features.YearBuilt != 1990 {
+		t.Errorf("expected the unit overrides kept, got %+v %+v", own.Location.Address, own.Features)
+	}
+	if !slices.Equal(own.Features.Amenities, []string{"gym", "concierge"}) {
+		t.Errorf("expected amenities added to the unit ones, got %v", own.Features.Amenities)
+	}
+}
+
+func TestDevelopmentInheritChanges(t *testing.T) {
+	previous := developmentTestBuilding()
+	unit := &Property{Location: Location{Address: Address{Unit: "4B"}}}
+	previous.Inherit(unit, nil)
+
+	own := &Property{
+		Location: Location{Address: Address{Street: "Calle Río", City: "Madrid", Country: "ES"}},
+		Features: Features{YearBuilt: 1990},
+	}
+	previous.Inherit(own, nil)
+
+	d := developmentTestBuilding()
+	d.Location.Address.Number = "6"
+	d.Location.ProviderRef = "way/457"
+	d.YearBuilt = 2028
+	d.Amenities = []string{"pool", "gym"}
+
+	if !d.Inherit(unit, previous) {
+		t.Fatal("expected the unit to follow the building")
+	}
+	if unit.Location.Address.Number != "6" || unit.Location.Address.Unit != "4B" || unit.Features.YearBuilt != 2028 {
+		t.Errorf("expected the new location and year built, got %+v %d", unit.Location.Address, unit.Features.YearBuilt)
+	}
+	if !unit.Features.Pool || unit.Features.Elevator {
+		t.Errorf("expected the elevator dropped and the pool kept, got %+v", unit.Features)
+	}
+	if !slices.Equal(unit.Features.Amenities, []string{"gym"}) {
+		t.Errorf("expected concierge replaced by gym, got %v", unit.Features.Amenities)
+	}
+
+	d.Inherit(own, previous)
+	if own.Location.Address.Street != "Calle Río" || own.Features.YearBuilt != 1990 {
+		t.Errorf("expected the unit overrides kept, got %+v %d", own.Location.Address, own.Features.YearBuilt)
+	}
+}
+
+func TestRollUp(t *testing.T) {
+	unit := func(status string, area float64, bedrooms int, prices ...Price) *Property {
+		return &Property{Status: status, Features: Features{TotalArea: area, Bedrooms: bedrooms}, Prices: prices}
+	}
+	sale := func(amount string) Price {
+		return Price{Amount: money.MustParse(amount), Currency: "EUR", Type: "sale"}
+	}
+
+	stats := RollUp([]*Property{
+		unit(StatusAvailable, 62, 1, sale("210000")),
+		unit(StatusAvailable, 95, 3, sale("345000.50"), Price{Amount: money.MustParse("1400"), Currency: "EUR", Type: "rent_monthly"}),
+		unit(StatusReserved, 80, 2, sale("290000")),
+		unit(StatusSold, 120, 4, sale("99000")),
+	})
+
+	if stats.Units != 4 || stats.Available != 2 || stats.Availability != AvailabilityAvailable {
+		t.Errorf("unexpected counts: %+v", stats)
+	}
+	if stats.ByStatus[StatusReserved] != 1 || stats.ByStatus[StatusSold] != 1 {
+		t.Errorf("unexpected statuses: %v", stats.ByStatus)
+	}
+	if len(stats.Prices) != 2 {
+		t.Fatalf("expected rent and sale ranges, got %+v", stats.Prices)
+	}
+	if p := stats.Prices[1]; p.Type != "sale" || p.Min.String() != "210000" || p.Max.String() != "345000.50" || p.Units != 2 {
+		t.Errorf("expected sale prices of the available units only, got %+v", p)
+	}
+	if stats.TotalArea == nil || stats.TotalArea.Min != 62 || stats.TotalArea.Max != 120 {
+		t.Errorf("unexpected area range: %+v", stats.TotalArea)
+	}
+	if stats.Bedrooms == nil || stats.Bedrooms.Min != 1 || stats.Bedrooms.Max != 4 {
+		t.Errorf("unexpected bedrooms range: %+v", stats.Bedrooms)
+	}
+
+	tests := []struct {
+		name     string
+		statuses []string
+		want     string
+	}{
+		{"no units", nil, AvailabilityNoUnits},
+		{"reserved", []string{StatusReserved, StatusSold}, AvailabilityReserved},
+		{"sold out", []string{StatusSold, StatusRented}, AvailabilitySoldOut},
+		{"unavailable", []string{StatusSold, StatusDraft}, AvailabilityUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var units []*Property
+			for _, s := range tt.statuses {
+				units = append(units, unit(s, 0, 0))
+			}
+			if got := RollUp(units).Availability; got != tt.want {
+				t.Errorf("availability = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDevelopmentsSave(t *testing.T) {
+	d := developmentTestBuilding()
+	devs := &developmentTestRepo{items: map[uuid.UUID]*Development{d.ID: d}}
+
+	follower := &Property{ID: uuid.New(), DevelopmentID: &d.ID, Revision: 1}
+	d.Inherit(follower, nil)
+	owner := &Property{ID: uuid.New(), DevelopmentID: &d.ID, Revision: 1, Location: Location{Address: Address{Street: "Calle Río", City: "Madrid", Country: "ES"}}, Features: Features{YearBuilt: 2027}}
+	d.Inherit(owner, nil)
+	repo := &developmentTestUnits{items: []*Property{follower, owner}}
+
+	s := NewDevelopments(repo, devs)
+	changed := *d
+	changed.Location.Address.Number = "6"
+	changed.UpdatedBy = "editor"
+
+	updated, err := s.Save(context.Background(), &changed)
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if updated != 1 || len(repo.saved) != 1 || repo.saved[0] != follower.ID {
+		t.Errorf("expected only the following unit saved, got %d %v", updated, repo.saved)
+	}
+	if follower.Location.Address.Number != "6" || follower.UpdatedBy != "editor" {
+		t.Errorf("unexpected unit after save: %+v, by %q", follower.Location.Address, follower.UpdatedBy)
+	}
+
+	if _, err := s.Stats(context.Background(), d.ID); err != nil {
+		t.Errorf("Stats() error = %v", err)
+	}
+	if err := s.Delete(context.Background(), d.ID, 0); err == nil {
+		t.Error("expected deleting a development with units to fail")
+	}
+}
+
+func developmentTestBuilding() *Development {
+	return &Development{
+		ID:   uuid.New(),
+		Kind: DevelopmentBuilding,
+		Name: "Ribera",
+		Location: Location{
+			Address:     Address{Street: "Paseo de la Ribera", Number: "4", City: "Madrid", Country: "ES"},
+			Coordinates: Coordinates{Latitude: 40.4010, Longitude: -3.7190},
+			ProviderRef: "way/456",
+		},
+		Amenities: []string{"pool", "elevator", "concierge"},
+		YearBuilt: 2027,
+		Revision:  1,
+	}
+}
+
+// developmentTestRepo keeps developments in memory.
+type developmentTestRepo struct {
+	DevelopmentRepo
+	items map[uuid.UUID]*Development
+}
+
+func (r *developmentTestRepo) GetDevelopment(ctx context.Context, id uuid.UUID) (*Development, error) {
+	d, ok := r.items[id]
+	if !ok {
+		return nil, ErrDevelopmentNotFound
+	}
+	clone := *d
+	return &clone, nil
+}
+
+func (r *developmentTestRepo) SaveDevelopment(ctx context.Context, d *Development) error {
+	d.Revision++
+	clone := *d
+	r.items[d.ID] = &clone
+	return nil
+}
+
+// developmentTestUnits answers searches with every unit and records saves.
+type developmentTestUnits struct {
+	Repo
+	items []*Property
+	saved []uuid.UUID
+}
+
+func (r *developmentTestUnits) Search(ctx context.Context, q PropertyQuery) (*PropertyPage, error) {
+	return &PropertyPage{Items: r.items, Total: int64(len(r.items))}, nil
+}
+
+func (r *developmentTestUnits) Save(ctx context.Context, p *Property) error {
+	r.saved = append(r.saved, p.ID)
+	p.Revision++
+	return nil
+}
@@ -0,0 +1,408 @@
+package estate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/pkg/lib/core"
+	"github.com/pulap/pulap/pkg/lib/telemetry"
+	"github.com/pulap/pulap/services/estate/internal/config"
+)
+
+// DevelopmentHandler handles HTTP requests for the Development aggregate.
+type DevelopmentHandler struct {
+	*PropertyAccess
+	developments *Developments
+	tlm          *telemetry.HTTP
+}
+
+// NewDevelopmentHandler creates a new DevelopmentHandler for the
+// developments in developments.
+func NewDevelopmentHandler(developments *Developments, access *PropertyAccess, xparams config.XParams) *DevelopmentHandler {
+	return &DevelopmentHandler{
+		PropertyAccess: access,
+		developments:   developments,
+		tlm: telemetry.NewHTTP(
+			telemetry.WithTracer(xparams.Tracer()),
+			telemetry.WithMetrics(xparams.Metrics()),
+		),
+	}
+}
+
+// RegisterRoutes registers the development routes.
+func (h *DevelopmentHandler) RegisterRoutes(r chi.Router) {
+	r.Route("/developments", func(r chi.Router) {
+		r.Use(h.authn())
+		r.Post("/", h.CreateDevelopment)
+		r.Get("/", h.ListDevelopments)
+		r.Get("/{id}", h.GetDevelopment)
+		r.Put("/{id}", h.UpdateDevelopment)
+		r.Delete("/{id}", h.DeleteDevelopment)
+		r.Get("/{id}/units", h.ListUnits)
+	})
+}
+
+// DevelopmentListMeta describes a development listing.
+type DevelopmentListMeta struct {
+	Count int `json:"count"`
+	Limit int `json:"limit"`
+}
+
+// DevelopmentUpdateMeta reports the units a development update was passed on to.
+type DevelopmentUpdateMeta struct {
+	UnitsUpdated int `json:"units_updated"`
+}
+
+// UnitsMeta describes a page of the units of a development and rolls up
+// all of them.
+type UnitsMeta struct {
+	PageMeta
+	Stats UnitStats `json:"stats"`
+}
+
+// CreateDevelopment handles POST /developments
+// A development without owner or team is owned by the authenticated user,
+// who needs PermissionWrite in its estate scope, as for properties.
+func (h *DevelopmentHandler) CreateDevelopment(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "DevelopmentHandler.CreateDevelopment")
+	defer finish()
+	log := h.log(r)
+	ctx := r.Context()
+
+	d, ok := h.decodeDevelopmentPayload(w, r)
+	if !ok {
+		return
+	}
+
+	if d.OwnerID == "" && d.TeamID == "" {
+		d.OwnerID = requestActor(r)
+	}
+	access, ok := h.access(w, r, PermissionWrite)
+	if !ok {
+		return
+	}
+	if !access.AllowsOwner(d.OwnerID, d.TeamID) {
+		log.Info("development create denied", "owner_id", d.OwnerID, "team_id", d.TeamID)
+		core.RespondError(w, http.StatusForbidden, fmt.Sprintf("Permission %s is required", PermissionWrite))
+		return
+	}
+
+	if !respondDevelopmentInvalid(w, d, log) {
+		return
+	}
+
+	d.CreatedBy = requestActor(r)
+	d.UpdatedBy = d.CreatedBy
+	if err := h.developments.Create(ctx, d); err != nil {
+		log.Error("cannot create development", "error", err)
+		core.RespondError(w, http.StatusInternalServerError, "Could not create development")
+		return
+	}
+
+	w.Header().Set("ETag", ETag(d.Revision))
+	w.WriteHeader(http.StatusCreated)
+	core.RespondSuccess(w, d, core.RESTfulLinksFor(d)...)
+}
+
+// ListDevelopments handles GET /developments
+// See ParseDevelopmentQuery for the supported parameters. Only the
+// developments the user may read are listed, by name.
+func (h *DevelopmentHandler) ListDevelopments(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "DevelopmentHandler.ListDevelopments")
+	defer finish()
+	log := h.log(r)
+	ctx := r.Context()
+
+	query, validationErrors := ParseDevelopmentQuery(r.URL.Query())
+	if len(validationErrors) > 0 {
+		log.Debug("invalid development query", "errors", validationErrors)
+		core.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid query: %s", validationErrors[0].Message))
+		return
+	}
+
+	access, ok := h.access(w, r, PermissionRead)
+	if !ok {
+		return
+	}
+	access.RestrictDevelopments(&query)
+
+	developments, err := h.developments.List(ctx, query)
+	if err != nil {
+		log.Error("error listing developments", "error", err)
+		core.RespondError(w, http.StatusInternalServerError, "Could not retrieve developments")
+		return
+	}
+	if developments == nil {
+		developments = []*Development{}
+	}
+
+	core.RespondSuccessWithMeta(w, developments, DevelopmentListMeta{Count: len(developments), Limit: query.Limit})
+}
+
+// GetDevelopment handles GET /developments/{id}
+// The development comes with the stats of its units in meta.
+func (h *DevelopmentHandler) GetDevelopment(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "DevelopmentHandler.GetDevelopment")
+	defer finish()
+	log := h.log(r)
+	ctx := r.Context()
+
+	d, ok := h.loadDevelopment(w, r, PermissionRead)
+	if !ok {
+		return
+	}
+
+	stats, err := h.developments.Stats(ctx, d.ID)
+	if err != nil {
+		log.Error("cannot roll up units", "error", err, "id", d.ID.String())
+		core.RespondError(w, http.StatusInternalServerError, "Could not retrieve units")
+		return
+	}
+
+	w.Header().Set("ETag", ETag(d.Revision))
+	core.RespondSuccessWithMeta(w, d, stats, core.RESTfulLinksFor(d)...)
+}
+
+// UpdateDevelopment handles PUT /developments/{id}
+// The units inherit the changes to the fields they do not override; meta
+// reports how many were updated. An If-Match header makes the update
+// conditional on the development revision.
+func (h *DevelopmentHandler) UpdateDevelopment(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "DevelopmentHandler.UpdateDevelopment")
+	defer finish()
+	log := h.log(r)
+	ctx := r.Context()
+
+	d, ok := h.decodeDevelopmentPayload(w, r)
+	if !ok {
+		return
+	}
+
+	current, ok := h.loadDevelopment(w, r, PermissionWrite)
+	if !ok {
+		return
+	}
+	if header := r.Header.Get("If-Match"); header != "" && !IfMatch(header, current.Revision) {
+		w.Header().Set("ETag", ETag(current.Revision))
+		core.RespondError(w, http.StatusPreconditionFailed, "Development was modified, reload and try again")
+		return
+	}
+
+	d.ID = current.ID
+	d.Revision = current.Revision
+	d.CreatedAt, d.CreatedBy = current.CreatedAt, current.CreatedBy
+
+	// The user must keep write access after reassigning the development
+	if d.OwnerID != current.OwnerID || d.TeamID != current.TeamID {
+		access, ok := h.access(w, r, PermissionWrite)
+		if !ok {
+			return
+		}
+		if !access.AllowsOwner(d.OwnerID, d.TeamID) {
+			log.Info("development reassignment denied", "id", d.ID.String(), "owner_id", d.OwnerID, "team_id", d.TeamID)
+			core.RespondError(w, http.StatusForbidden, fmt.Sprintf("Permission %s is required on the new owner or team", PermissionWrite))
+			return
+		}
+	}
+
+	if !respondDevelopmentInvalid(w, d, log) {
+		return
+	}
+
+	d.UpdatedBy = requestActor(r)
+	updated, err := h.developments.Save(WithActor(ctx, d.UpdatedBy), d)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrDevelopmentNotFound):
+			core.RespondError(w, http.StatusNotFound, "Development not found")
+		case errors.Is(err, ErrRevisionConflict) && r.Header.Get("If-Match") != "":
+			core.RespondError(w, http.StatusPreconditionFailed, "Development was modified, reload and try again")
+		case errors.Is(err, ErrRevisionConflict):
+			core.RespondError(w, http.StatusConflict, "Development was modified concurrently, reload and try again")
+		default:
+			log.Error("cannot update development", "error", err, "units_updated", updated)
+			core.RespondError(w, http.StatusInternalServerError, "Could not update development")
+		}
+		return
+	}
+
+	w.Header().Set("ETag", ETag(d.Revision))
+	core.RespondSuccessWithMeta(w, d, DevelopmentUpdateMeta{UnitsUpdated: updated}, core.RESTfulLinksFor(d)...)
+}
+
+// DeleteDevelopment handles DELETE /developments/{id}
+// Only developments without units can be deleted. Requires PermissionDelete
+// on the development. An If-Match header makes the delete conditional on
+// the development revision.
+func (h *DevelopmentHandler) DeleteDevelopment(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "DevelopmentHandler.DeleteDevelopment")
+	defer finish()
+	log := h.log(r)
+	ctx := r.Context()
+
+	current, ok := h.loadDevelopment(w, r, PermissionDelete)
+	if !ok {
+		return
+	}
+
+	var revision int64
+	if header := r.Header.Get("If-Match"); header != "" {
+		if !IfMatch(header, current.Revision) {
+			w.Header().Set("ETag", ETag(current.Revision))
+			core.RespondError(w, http.StatusPreconditionFailed, "Development was modified, reload and try again")
+			return
+		}
+		revision = current.Revision
+	}
+
+	if err := h.developments.Delete(ctx, current.ID, revision); err != nil {
+		switch {
+		case errors.Is(err, ErrDevelopmentNotFound):
+			core.RespondError(w, http.StatusNotFound, "Development not found")
+		case errors.Is(err, ErrDevelopmentHasUnits):
+			core.RespondError(w, http.StatusConflict, "Development has units; move or delete them first")
+		case errors.Is(err, ErrRevisionConflict):
+			core.RespondError(w, http.StatusPreconditionFailed, "Development was modified, reload and try again")
+		default:
+			log.Error("cannot delete development", "error", err)
+			core.RespondError(w, http.StatusInternalServerError, "Could not delete development")
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListUnits handles GET /developments/{id}/units
+// The filters, sort and pagination of GET /estates apply to the units; only
+// those the user may read are listed. Meta rolls up every unit: how many are
+// available, the availability of the development and the price and area
+// ranges.
+func (h *DevelopmentHandler) ListUnits(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "DevelopmentHandler.ListUnits")
+	defer finish()
+	log := h.log(r)
+	ctx := r.Context()
+
+	d, ok := h.loadDevelopment(w, r, PermissionRead)
+	if !ok {
+		return
+	}
+
+	query, validationErrors := ParsePropertyQuery(r.URL.Query())
+	if len(validationErrors) > 0 {
+		log.Debug("invalid units query", "errors", validationErrors)
+		core.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid query: %s", validationErrors[0].Message))
+		return
+	}
+	query.DevelopmentIDs = []uuid.UUID{d.ID}
+
+	access, ok := h.access(w, r, PermissionRead)
+	if !ok {
+		return
+	}
+	access.Restrict(&query)
+
+	page, err := h.repo.Search(ctx, query)
+	if err != nil {
+		log.Error("error searching units", "error", err)
+		core.RespondError(w, http.StatusInternalServerError, "Could not retrieve units")
+		return
+	}
+
+	stats, err := h.developments.Stats(ctx, d.ID)
+	if err != nil {
+		log.Error("cannot roll up units", "error", err, "id", d.ID.String())
+		core.RespondError(w, http.StatusInternalServerError, "Could not retrieve units")
+		return
+	}
+
+	units := page.Items
+	if units == nil {
+		units = []*Property{}
+	}
+	meta := UnitsMeta{PageMeta: PageMeta{Total: page.Total, Limit: query.Limit, NextCursor: page.NextCursor}, Stats: stats}
+
+	var links []core.Link
+	if page.NextCursor != "" {
+		next := r.URL.Query()
+		next.Set("cursor", page.NextCursor)
+		links = append(links, core.Link{Rel: core.RelNext, Href: r.URL.Path + "?" + next.Encode()})
+	}
+	core.RespondSuccessWithMeta(w, units, meta, links...)
+}
+
+// loadDevelopment loads the development of the request and checks the user
+// holds the permission on it, responding with the error when it returns
+// false. Developments the user cannot read are reported as not found.
+func (h *DevelopmentHandler) loadDevelopment(w http.ResponseWriter, r *http.Request, permission string) (*Development, bool) {
+	log := h.log(r)
+
+	id, ok := h.parseIDParam(w, r, log)
+	if !ok {
+		return nil, false
+	}
+
+	d, err := h.developments.Get(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, ErrDevelopmentNotFound) {
+			core.RespondError(w, http.StatusNotFound, "Development not found")
+			return nil, false
+		}
+		log.Error("error loading development", "error", err, "id", id.String())
+		core.RespondError(w, http.StatusInternalServerError, "Could not retrieve development")
+		return nil, false
+	}
+
+	access, ok := h.access(w, r, permission)
+	if !ok {
+		return nil, false
+	}
+	if access.AllowsOwner(d.OwnerID, d.TeamID) {
+		return d, true
+	}
+
+	if permission != PermissionRead {
+		read, ok := h.access(w, r, PermissionRead)
+		if !ok {
+			return nil, false
+		}
+		if read.AllowsOwner(d.OwnerID, d.TeamID) {
+			log.Info("development access denied", "id", id.String(), "perm", permission)
+			core.RespondError(w, http.StatusForbidden, fmt.Sprintf("Permission %s is required", permission))
+			return nil, false
+		}
+	}
+	core.RespondError(w, http.StatusNotFound, "Development not found")
+	return nil, false
+}
+
+func (h *DevelopmentHandler) decodeDevelopmentPayload(w http.ResponseWriter, r *http.Request) (*Development, bool) {
+	r.Body = http.MaxBytesReader(w, r.Body, MaxBodyBytes)
+	defer r.Body.Close()
+
+	var d Development
+	if err := json.NewDecoder(r.Body).Decode(&d); err != nil {
+		h.log(r).Debug("error decoding JSON", "error", err)
+		core.RespondError(w, http.StatusBadRequest, "Invalid JSON payload")
+		return nil, false
+	}
+	return &d, true
+}
+
+// respondDevelopmentInvalid responds 400 with the first validation error
+// and returns false when the development is invalid.
+func respondDevelopmentInvalid(w http.ResponseWriter, d *Development, log core.Logger) bool {
+	validationErrors := d.Validate()
+	if len(validationErrors) == 0 {
+		return true
+	}
+	log.Debug("validation failed", "errors", validationErrors)
+	core.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Validation failed: %s", validationErrors[0].Message))
+	return false
+}
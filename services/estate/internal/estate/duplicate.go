@@ -0,0 +1,454 @@
+package estate
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+	"golang.org/x/text/unicode/norm"
+)
+
+// DefaultDuplicateThreshold is the score from which a property is reported
+// as a likely duplicate of another.
+const DefaultDuplicateThreshold = 0.6
+
+// Duplicate match reasons, listing the signals that added to a score.
+const (
+	DuplicateProviderRef = "provider_ref" // Same geocoder place
+	DuplicateAddress     = "address"      // Same or nearly the same normalized address
+	DuplicateNearby      = "nearby"       // Coordinates within duplicateNearMeters
+	DuplicateUnit        = "unit"         // Same unit
+	DuplicateFloor       = "floor"        // Same floor
+	DuplicateArea        = "area"         // Total area within duplicateCloseArea
+)
+
+// Duplicate score weights. A candidate reaches the default threshold with
+// the same address and area, or the same geocoder place and address; a
+// different unit or floor rules it out.
+const (
+	duplicateProviderRefWeight = 0.4
+	duplicateAddressWeight     = 0.45
+	duplicateNearbyWeight      = 0.15
+	duplicateUnitWeight        = 0.05
+	duplicateFloorWeight       = 0.05
+	duplicateMismatchPenalty   = 1.0
+	duplicateAreaWeight        = 0.15
+	duplicateAreaPenalty       = 0.2
+)
+
+// Duplicate matching tolerances
+const (
+	duplicateSameMeters    = 25.0  // Positions this close count fully
+	duplicateNearMeters    = 100.0 // Positions further apart do not count
+	duplicateAddressTokens = 0.8   // Token overlap from which addresses nearly match
+	duplicateSameArea      = 0.05  // Relative area difference counting fully
+	duplicateCloseArea     = 0.15  // Relative area difference counting half
+	duplicateFarArea       = 0.30  // Relative area difference from which the penalty applies
+)
+
+// Duplicate candidate limits
+const (
+	maxDuplicateNearby     = 50   // Properties around the position compared
+	maxDuplicateCandidates = 1000 // Properties of the city compared
+	duplicateCellDegrees   = 0.001
+)
+
+// DuplicateMatch is an existing property that is likely the same as the
+// one being checked. Property is only set when the caller may read it.
+type DuplicateMatch struct {
+	ID       uuid.UUID `json:"id"`
+	Score    float64   `json:"score"`
+	Reasons  []string  `json:"reasons"`
+	Property *Property `json:"property,omitempty"`
+}
+
+// DuplicatePair is two properties of a cluster that match each other.
+type DuplicatePair struct {
+	A       uuid.UUID `json:"a"`
+	B       uuid.UUID `json:"b"`
+	Score   float64   `json:"score"`
+	Reasons []string  `json:"reasons"`
+}
+
+// DuplicateCluster is a group of properties linked by likely duplicate
+// pairs, to be merged into one. Score is the best score of its pairs.
+type DuplicateCluster struct {
+	Score      float64         `json:"score"`
+	Properties []*Property     `json:"properties"`
+	Pairs      []DuplicatePair `json:"pairs"`
+}
+
+// NormalizeAddress returns the full address in a form that compares equal
+// across spelling variants: lower case, without diacritics or punctuation
+// and with single spaces between words.
+func NormalizeAddress(a Address) string {
+	var sb strings.Builder
+	space := false
+	for _, r := range norm.NFD.String(a.FullAddress()) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			continue
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if space && sb.Len() > 0 {
+				sb.WriteByte(' ')
+			}
+			space = false
+			sb.WriteRune(unicode.ToLower(r))
+		default:
+			space = true
+		}
+	}
+	return sb.String()
+}
+
+// ScoreDuplicate scores how likely two properties are the same one, from 0
+// to 1, and lists the signals that added to the score.
+func ScoreDuplicate(a, b *Property) (float64, []string) {
+	score := 0.0
+	var reasons []string
+
+	la, lb := a.Location, b.Location
+	if la.ProviderRef != "" && la.ProviderRef == lb.ProviderRef && strings.EqualFold(la.Provider, lb.Provider) {
+		score += duplicateProviderRefWeight
+		reasons = append(reasons, DuplicateProviderRef)
+	}
+
+	// The unit is compared on its own, so "2B" and "2-B" do not split the address
+	if overlap := addressOverlap(buildingAddress(la.Address), buildingAddress(lb.Address)); overlap >= duplicateAddressTokens {
+		score += duplicateAddressWeight * overlap
+		reasons = append(reasons, DuplicateAddress)
+	}
+
+	if pa, ok := PointOf(a); ok {
+		if pb, ok := PointOf(b); ok {
+			if d := DistanceMeters(pa, pb); d < duplicateNearMeters {
+				weight := duplicateNearbyWeight
+				if d > duplicateSameMeters {
+					weight *= (duplicateNearMeters - d) / (duplicateNearMeters - duplicateSameMeters)
+				}
+				score += weight
+				reasons = append(reasons, DuplicateNearby)
+			}
+		}
+	}
+
+	ua, ub := normalizeUnit(la.Address.Unit), normalizeUnit(lb.Address.Unit)
+	if ua != "" && ub != "" {
+		if ua == ub {
+			score += duplicateUnitWeight
+			reasons = append(reasons, DuplicateUnit)
+		} else {
+			score -= duplicateMismatchPenalty
+		}
+	}
+
+	// Zero is both the ground floor and unset, so it is not compared
+	fa, fb := a.Features.Floor, b.Features.Floor
+	if fa != 0 && fb != 0 {
+		if fa == fb {
+			score += duplicateFloorWeight
+			reasons = append(reasons, DuplicateFloor)
+		} else {
+			score -= duplicateMismatchPenalty
+		}
+	}
+
+	if aa, ab := a.Features.TotalArea, b.Features.TotalArea; aa > 0 && ab > 0 {
+		diff := math.Abs(aa-ab) / math.Max(aa, ab)
+		switch {
+		case diff <= duplicateSameArea:
+			score += duplicateAreaWeight
+			reasons = append(reasons, DuplicateArea)
+		case diff <= duplicateCloseArea:
+			score += duplicateAreaWeight / 2
+			reasons = append(reasons, DuplicateArea)
+		case diff > duplicateFarArea:
+			score -= duplicateAreaPenalty
+		}
+	}
+
+	return math.Round(math.Max(0, math.Min(1, score))*100) / 100, reasons
+}
+
+// addressOverlap returns 1 for equal normalized addresses, otherwise the
+// share of words they have in common.
+func addressOverlap(a, b string) float64 {
+	if a == "" || b == "" {
+		return 0
+	}
+	if a == b {
+		return 1
+	}
+
+	words := make(map[string]bool)
+	for _, w := range strings.Fields(a) {
+		words[w] = true
+	}
+	union := len(words)
+	common := 0
+	seen := make(map[string]bool)
+	for _, w := range strings.Fields(b) {
+		if seen[w] {
+			continue
+		}
+		seen[w] = true
+		if words[w] {
+			common++
+		} else {
+			union++
+		}
+	}
+	return float64(common) / float64(union)
+}
+
+// buildingAddress returns the normalized address without the unit.
+func buildingAddress(a Address) string {
+	a.Unit = ""
+	return NormalizeAddress(a)
+}
+
+// normalizeUnit reduces a unit to its letters and digits, so "Apt. 2-B"
+// and "apt 2b" compare equal.
+func normalizeUnit(unit string) string {
+	var sb strings.Builder
+	for _, r := range norm.NFD.String(unit) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return sb.String()
+}
+
+// DuplicateDetector finds the existing properties a property likely
+// duplicates. Candidates are the properties near its coordinates and, up
+// to maxDuplicateCandidates, those in the same city.
+type DuplicateDetector struct {
+	repo      Repo
+	threshold float64
+}
+
+// NewDuplicateDetector returns a detector reporting matches scoring at
+// least threshold.
+func NewDuplicateDetector(repo Repo, threshold float64) (*DuplicateDetector, error) {
+	if threshold <= 0 || threshold > 1 {
+		return nil, fmt.Errorf("invalid duplicate threshold %v", threshold)
+	}
+	return &DuplicateDetector{repo: repo, threshold: threshold}, nil
+}
+
+// Threshold returns the score from which matches are reported.
+func (d *DuplicateDetector) Threshold() float64 {
+	return d.threshold
+}
+
+// Find returns the live properties likely duplicated by p, best first.
+// The property itself is not a match, so p may already be stored.
+func (d *DuplicateDetector) Find(ctx context.Context, p *Property) ([]DuplicateMatch, error) {
+	candidates := make(map[uuid.UUID]*Property)
+
+	if point, ok := PointOf(p); ok {
+		query := GeoQuery{Shape: GeoRadius, RadiusMeters: duplicateNearMeters, Limit: maxDuplicateNearby}
+		query.SetCenter(point)
+		if errs := query.Normalize(); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid duplicate search: %v", errs)
+		}
+		results, err := d.repo.SearchGeo(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("cannot search nearby properties: %w", err)
+		}
+		for _, r := range results {
+			candidates[r.Property.ID] = r.Property
+		}
+	}
+
+	if a := p.Location.Address; a.City != "" && a.Country != "" {
+		query := PropertyQuery{City: a.City, Country: a.Country, Limit: MaxSearchLimit}
+		if errs := query.Normalize(); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid duplicate search: %v", errs)
+		}
+		for seen := 0; seen < maxDuplicateCandidates; {
+			page, err := d.repo.Search(ctx, query)
+			if err != nil {
+				return nil, fmt.Errorf("cannot search properties of the city: %w", err)
+			}
+			for _, c := range page.Items {
+				candidates[c.ID] = c
+			}
+			seen += len(page.Items)
+			if page.NextCursor == "" {
+				break
+			}
+			query.Cursor = page.NextCursor
+		}
+	}
+
+	var matches []DuplicateMatch
+	for id, c := range candidates {
+		if id == p.ID {
+			continue
+		}
+		if score, reasons := ScoreDuplicate(p, c); score >= d.threshold {
+			matches = append(matches, DuplicateMatch{ID: id, Score: score, Reasons: reasons, Property: c})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].ID.String() < matches[j].ID.String()
+	})
+	return matches, nil
+}
+
+// Clusters groups the live properties matching the query into clusters of
+// likely duplicates, best first. Only properties sharing a geocoder place,
+// a street address or a position are compared.
+func (d *DuplicateDetector) Clusters(ctx context.Context, query PropertyQuery) ([]DuplicateCluster, error) {
+	query.Limit = MaxSearchLimit
+	query.Cursor = ""
+	if errs := query.Normalize(); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid duplicate search: %v", errs)
+	}
+
+	var properties []*Property
+	for {
+		page, err := d.repo.Search(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("cannot search properties: %w", err)
+		}
+		properties = append(properties, page.Items...)
+		if page.NextCursor == "" {
+			break
+		}
+		query.Cursor = page.NextCursor
+	}
+
+	return ClusterDuplicates(properties, d.threshold), nil
+}
+
+// ClusterDuplicates groups the properties into clusters of likely
+// duplicates, best first. Properties are compared when they share a
+// geocoder place or a street address, or lie in neighbouring cells of
+// about duplicateCellDegrees.
+func ClusterDuplicates(properties []*Property, threshold float64) []DuplicateCluster {
+	blocks := make(map[string][]int)
+	cells := make(map[[2]int64][]int)
+	for i, p := range properties {
+		l := p.Location
+		if l.ProviderRef != "" {
+			key := "ref:" + strings.ToLower(l.Provider) + ":" + l.ProviderRef
+			blocks[key] = append(blocks[key], i)
+		}
+		street := l.Address
+		street.Unit, street.State, street.PostalCode = "", "", ""
+		if key := NormalizeAddress(street); key != "" {
+			blocks["addr:"+key] = append(blocks["addr:"+key], i)
+		}
+		if point, ok := PointOf(p); ok {
+			cell := duplicateCell(point)
+			cells[cell] = append(cells[cell], i)
+		}
+	}
+
+	compared := make(map[[2]int]bool)
+	var pairs []DuplicatePair
+	parent := make([]int, len(properties))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	compare := func(i, j int) {
+		if i == j {
+			return
+		}
+		if i > j {
+			i, j = j, i
+		}
+		if compared[[2]int{i, j}] {
+			return
+		}
+		compared[[2]int{i, j}] = true
+
+		score, reasons := ScoreDuplicate(properties[i], properties[j])
+		if score < threshold {
+			return
+		}
+		pairs = append(pairs, DuplicatePair{A: properties[i].ID, B: properties[j].ID, Score: score, Reasons: reasons})
+		parent[find(i)] = find(j)
+	}
+
+	for _, members := range blocks {
+		for x := range members {
+			for y := x + 1; y < len(members); y++ {
+				compare(members[x], members[y])
+			}
+		}
+	}
+	for cell, members := range cells {
+		for dLat := int64(-1); dLat <= 1; dLat++ {
+			for dLng := int64(-1); dLng <= 1; dLng++ {
+				for _, j := range cells[[2]int64{cell[0] + dLat, cell[1] + dLng}] {
+					for _, i := range members {
+						compare(i, j)
+					}
+				}
+			}
+		}
+	}
+
+	index := make(map[uuid.UUID]int, len(properties))
+	for i, p := range properties {
+		index[p.ID] = i
+	}
+	byRoot := make(map[int]*DuplicateCluster)
+	var roots []int
+	for _, pair := range pairs {
+		root := find(index[pair.A])
+		c, ok := byRoot[root]
+		if !ok {
+			c = &DuplicateCluster{}
+			byRoot[root] = c
+			roots = append(roots, root)
+		}
+		c.Pairs = append(c.Pairs, pair)
+		c.Score = math.Max(c.Score, pair.Score)
+	}
+	for i, p := range properties {
+		if c, ok := byRoot[find(i)]; ok {
+			c.Properties = append(c.Properties, p)
+		}
+	}
+
+	clusters := make([]DuplicateCluster, 0, len(roots))
+	for _, root := range roots {
+		c := byRoot[root]
+		sort.Slice(c.Pairs, func(i, j int) bool { return c.Pairs[i].Score > c.Pairs[j].Score })
+		clusters = append(clusters, *c)
+	}
+	sort.Slice(clusters, func(i, j int) bool {
+		if clusters[i].Score != clusters[j].Score {
+			return clusters[i].Score > clusters[j].Score
+		}
+		if len(clusters[i].Properties) != len(clusters[j].Properties) {
+			return len(clusters[i].Properties) > len(clusters[j].Properties)
+		}
+		return clusters[i].Properties[0].ID.String() < clusters[j].Properties[0].ID.String()
+	})
+	return clusters
+}
+
+// duplicateCell returns the grid cell of a position.
+func duplicateCell(p GeoPoint) [2]int64 {
+	return [2]int64{int64(math.Floor(p.Lat / duplicateCellDegrees)), int64(math.Floor(p.Lng / duplicateCellDegrees))}
+}
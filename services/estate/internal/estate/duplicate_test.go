@@ -0,0 +1,262 @@
+package estate
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestNormalizeAddress(t *testing.T) {
+	tests := []struct {
+		name    string
+		address Address
+		want    string
+	}{
+		{
+			name:    "diacritics and punctuation",
+			address: Address{Street: "Calle Ñúñez de Balboa", Number: "12", Unit: "3º-B", City: "Málaga", Country: "ES"},
+			want:    "calle nunez de balboa 12 3º b malaga es",
+		},
+		{
+			name:    "spacing",
+			address: Address{Street: "  Rua  São Bento ", City: "Lisboa", PostalCode: "1200-109", Country: "PT"},
+			want:    "rua sao bento lisboa 1200 109 pt",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeAddress(tt.address); got != tt.want {
+				t.Errorf("NormalizeAddress() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScoreDuplicate(t *testing.T) {
+	base := duplicateTestProperty("Calle Mayor", "12", "2B", 2, 85)
+
+	tests := []struct {
+		name        string
+		edit        func(p *Property)
+		wantDup     bool
+		wantReasons []string
+	}{
+		{
+			name:        "same apartment spelled differently",
+			edit:        func(p *Property) { p.Location.Address.Street = "calle MAYOR."; p.Location.Address.Unit = "2-b" },
+			wantDup:     true,
+			wantReasons: []string{DuplicateProviderRef, DuplicateAddress, DuplicateNearby, DuplicateUnit, DuplicateFloor, DuplicateArea},
+		},
+		{
+			name: "same address without geocoding",
+			edit: func(p *Property) {
+				p.Location.Provider, p.Location.ProviderRef = "", ""
+				p.Location.Coordinates = Coordinates{}
+				p.Features.Floor = 0
+			},
+			wantDup:     true,
+			wantReasons: []string{DuplicateAddress, DuplicateUnit, DuplicateArea},
+		},
+		{
+			name:        "same place and address, area rounded",
+			edit:        func(p *Property) { p.Location.Coordinates = Coordinates{}; p.Features.TotalArea = 80 },
+			wantDup:     true,
+			wantReasons: []string{DuplicateProviderRef, DuplicateAddress, DuplicateUnit, DuplicateFloor, DuplicateArea},
+		},
+		{
+			name: "other apartment of the building",
+			edit: func(p *Property) {
+				p.Location.Address.Unit = "5A"
+				p.Features.Floor = 5
+			},
+			wantDup:     false,
+			wantReasons: []string{DuplicateProviderRef, DuplicateAddress, DuplicateNearby, DuplicateArea},
+		},
+		{
+			name: "different size",
+			edit: func(p *Property) {
+				p.Location.Provider, p.Location.ProviderRef = "", ""
+				p.Features.TotalArea = 150
+			},
+			wantDup:     false,
+			wantReasons: []string{DuplicateAddress, DuplicateNearby, DuplicateUnit, DuplicateFloor},
+		},
+		{
+			name: "elsewhere",
+			edit: func(p *Property) {
+				*p = *duplicateTestProperty("Gran Vía", "40", "2B", 2, 85)
+				p.Location.ProviderRef = "node/2"
+				p.Location.Coordinates = Coordinates{Latitude: 40.4200, Longitude: -3.7050}
+			},
+			wantDup:     false,
+			wantReasons: []string{DuplicateUnit, DuplicateFloor, DuplicateArea},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			other := duplicateTestProperty("Calle Mayor", "12", "2B", 2, 85)
+			tt.edit(other)
+
+			score, reasons := ScoreDuplicate(base, other)
+			if dup := score >= DefaultDuplicateThreshold; dup != tt.wantDup {
+				t.Errorf("score = %v, want duplicate %v", score, tt.wantDup)
+			}
+			if !slices.Equal(reasons, tt.wantReasons) {
+				t.Errorf("reasons = %v, want %v", reasons, tt.wantReasons)
+			}
+			if score < 0 || score > 1 {
+				t.Errorf("score = %v, want within [0, 1]", score)
+			}
+
+			if back, _ := ScoreDuplicate(other, base); back != score {
+				t.Errorf("score is not symmetric: %v and %v", score, back)
+			}
+		})
+	}
+}
+
+func TestDuplicateDetectorFind(t *testing.T) {
+	existing := duplicateTestProperty("Calle Mayor", "12", "2B", 2, 85)
+	neighbour := duplicateTestProperty("Calle Mayor", "12", "4A", 4, 85)
+	ungeocoded := duplicateTestProperty("Calle Mayor", "12", "", 0, 86)
+	ungeocoded.Location.Provider, ungeocoded.Location.ProviderRef = "", ""
+	ungeocoded.Location.Coordinates = Coordinates{}
+	repo := &duplicateTestRepo{items: []*Property{existing, neighbour, ungeocoded}}
+
+	detector, err := NewDuplicateDetector(repo, DefaultDuplicateThreshold)
+	if err != nil {
+		t.Fatalf("NewDuplicateDetector() error = %v", err)
+	}
+
+	p := duplicateTestProperty("Calle Mayor", "12", "2 B", 2, 84)
+	matches, err := detector.Find(context.Background(), p)
+	if err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+	if len(matches) != 2 {
+		t.Fatalf("Find() = %d matches, want 2: %+v", len(matches), matches)
+	}
+	if matches[0].ID != existing.ID || matches[1].ID != ungeocoded.ID {
+		t.Errorf("matches = %s, %s, want %s, %s", matches[0].ID, matches[1].ID, existing.ID, ungeocoded.ID)
+	}
+	if matches[0].Score < matches[1].Score {
+		t.Errorf("matches are not sorted by score: %v, %v", matches[0].Score, matches[1].Score)
+	}
+	if !repo.geo || !repo.city {
+		t.Errorf("expected nearby and city candidates, got geo %v city %v", repo.geo, repo.city)
+	}
+
+	// A stored property does not match itself
+	matches, err = detector.Find(context.Background(), existing)
+	if err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+	for _, m := range matches {
+		if m.ID == existing.ID {
+			t.Error("expected the property not to match itself")
+		}
+	}
+}
+
+func TestNewDuplicateDetectorInvalid(t *testing.T) {
+	for _, threshold := range []float64{0, -0.5, 1.5} {
+		if _, err := NewDuplicateDetector(nil, threshold); err == nil {
+			t.Errorf("expected error for threshold %v", threshold)
+		}
+	}
+}
+
+func TestClusterDuplicates(t *testing.T) {
+	a := duplicateTestProperty("Calle Mayor", "12", "2B", 2, 85)
+	b := duplicateTestProperty("calle mayor", "12", "2-B", 2, 86)
+	c := duplicateTestProperty("Calle Mayor", "12", "2b", 2, 84)
+	c.Location.Provider, c.Location.ProviderRef = "", ""
+	other := duplicateTestProperty("Calle Mayor", "12", "5A", 5, 85)
+	x := duplicateTestProperty("Gran Vía", "40", "", 0, 120)
+	x.Location.ProviderRef = "node/2"
+	x.Location.Coordinates = Coordinates{Latitude: 40.4200, Longitude: -3.7050}
+	y := duplicateTestProperty("Gran Via", "40", "", 0, 118)
+	y.Location.ProviderRef = "node/3"
+	y.Location.Coordinates = Coordinates{Latitude: 40.4201, Longitude: -3.7051}
+	alone := duplicateTestProperty("Alcalá", "1", "", 0, 60)
+	alone.Location.ProviderRef = "node/4"
+	alone.Location.Coordinates = Coordinates{Latitude: 40.4300, Longitude: -3.6900}
+
+	clusters := ClusterDuplicates([]*Property{a, x, other, b, alone, y, c}, DefaultDuplicateThreshold)
+	if len(clusters) != 2 {
+		t.Fatalf("ClusterDuplicates() = %d clusters, want 2: %+v", len(clusters), clusters)
+	}
+
+	if got := clusterIDs(clusters[0]); !slices.Equal(got, []uuid.UUID{a.ID, b.ID, c.ID}) {
+		t.Errorf("first cluster = %v, want %v", got, []uuid.UUID{a.ID, b.ID, c.ID})
+	}
+	if len(clusters[0].Pairs) != 3 {
+		t.Errorf("first cluster has %d pairs, want 3", len(clusters[0].Pairs))
+	}
+	if got := clusterIDs(clusters[1]); !slices.Equal(got, []uuid.UUID{x.ID, y.ID}) {
+		t.Errorf("second cluster = %v, want %v", got, []uuid.UUID{x.ID, y.ID})
+	}
+	if clusters[0].Score < clusters[1].Score {
+		t.Errorf("clusters are not sorted by score: %v, %v", clusters[0].Score, clusters[1].Score)
+	}
+}
+
+func clusterIDs(c DuplicateCluster) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(c.Properties))
+	for _, p := range c.Properties {
+		ids = append(ids, p.ID)
+	}
+	return ids
+}
+
+// duplicateTestProperty returns a geocoded Madrid property.
+func duplicateTestProperty(street, number, unit string, floor int, area float64) *Property {
+	return &Property{
+		ID:   uuid.New(),
+		Name: "Piso " + street,
+		Location: Location{
+			Address:     Address{Street: street, Number: number, Unit: unit, City: "Madrid", Country: "ES"},
+			Coordinates: Coordinates{Latitude: 40.4168, Longitude: -3.7038},
+			Provider:    "nominatim",
+			ProviderRef: "node/1",
+		},
+		Features: Features{TotalArea: area, Floor: floor},
+	}
+}
+
+// duplicateTestRepo answers geo searches by distance and searches by city.
+type duplicateTestRepo struct {
+	Repo
+	items     []*Property
+	geo, city bool
+}
+
+func (r *duplicateTestRepo) SearchGeo(ctx context.Context, q GeoQuery) ([]GeoResult, error) {
+	r.geo = true
+	var results []GeoResult
+	for _, p := range r.items {
+		if point, ok := PointOf(p); ok {
+			if d := DistanceMeters(q.Center, point); d <= q.RadiusMeters {
+				results = append(results, GeoResult{Property: p, DistanceMeters: d})
+			}
+		}
+	}
+	return results, nil
+}
+
+func (r *duplicateTestRepo) Search(ctx context.Context, q PropertyQuery) (*PropertyPage, error) {
+	r.city = true
+	page := &PropertyPage{}
+	for _, p := range r.items {
+		if strings.EqualFold(p.Location.Address.City, q.City) && strings.EqualFold(p.Location.Address.Country, q.Country) {
+			page.Items = append(page.Items, p)
+		}
+	}
+	page.Total = int64(len(page.Items))
+	return page, nil
+}
@@ -0,0 +1,119 @@
+package estate
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/pulap/pulap/pkg/lib/core"
+)
+
+// DuplicateConflictResponse is the 409 answer to a create matching existing
+// properties: the error envelope with the likely duplicates. Matches the
+// user may not read carry their ID and score only.
+type DuplicateConflictResponse struct {
+	Error      core.ErrorPayload `json:"error"`
+	Duplicates []DuplicateMatch  `json:"duplicates"`
+}
+
+// DuplicateMeta describes a duplicate cluster report.
+type DuplicateMeta struct {
+	Count     int     `json:"count"`
+	Threshold float64 `json:"threshold"`
+}
+
+// ListDuplicates handles GET /estates/duplicates
+// Clusters of likely duplicate properties, to be merged, are listed best
+// first. The filters of GET /estates narrow the properties compared; its
+// sort and pagination are ignored. Only the properties the user may read
+// are compared.
+func (h *Handler) ListDuplicates(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "Handler.ListDuplicates")
+	defer finish()
+	log := h.log(r)
+	ctx := r.Context()
+
+	if h.duplicates == nil {
+		core.RespondError(w, http.StatusServiceUnavailable, "Duplicate detection is not available")
+		return
+	}
+
+	query, validationErrors := ParsePropertyQuery(r.URL.Query())
+	if len(validationErrors) > 0 {
+		log.Debug("invalid duplicates query", "errors", validationErrors)
+		core.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid query: %s", validationErrors[0].Message))
+		return
+	}
+
+	access, ok := h.access(w, r, PermissionRead)
+	if !ok {
+		return
+	}
+	access.Restrict(&query)
+
+	clusters, err := h.duplicates.Clusters(ctx, query)
+	if err != nil {
+		log.Error("error finding duplicates", "error", err)
+		core.RespondError(w, http.StatusInternalServerError, "Could not retrieve duplicates")
+		return
+	}
+
+	core.RespondSuccessWithMeta(w, clusters, DuplicateMeta{Count: len(clusters), Threshold: h.duplicates.Threshold()})
+}
+
+// parseAllowDuplicates parses the override of the duplicate check, responding
+// with the error when it returns false.
+func parseAllowDuplicates(w http.ResponseWriter, r *http.Request, param string) (bool, bool) {
+	v := r.URL.Query().Get(param)
+	if v == "" {
+		return false, true
+	}
+	allow, err := strconv.ParseBool(v)
+	if err != nil {
+		core.RespondError(w, http.StatusBadRequest, param+" must be true or false")
+		return false, false
+	}
+	return allow, true
+}
+
+// checkDuplicates responds with 409 and the likely duplicates of a property
+// about to be created, returning false, when it matches existing ones.
+func (h *Handler) checkDuplicates(w http.ResponseWriter, r *http.Request, property *Property) bool {
+	if h.duplicates == nil {
+		return true
+	}
+	log := h.log(r)
+
+	matches, err := h.duplicates.Find(r.Context(), property)
+	if err != nil {
+		log.Error("cannot check duplicates", "error", err)
+		core.RespondError(w, http.StatusInternalServerError, "Could not check for duplicates")
+		return false
+	}
+	if len(matches) == 0 {
+		return true
+	}
+
+	read, ok := h.access(w, r, PermissionRead)
+	if !ok {
+		return false
+	}
+	for i := range matches {
+		if !read.Allows(matches[i].Property) {
+			matches[i].Property = nil
+		}
+	}
+
+	log.Info("likely duplicate property", "name", property.Name, "matches", len(matches))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusConflict)
+	json.NewEncoder(w).Encode(DuplicateConflictResponse{
+		Error: core.ErrorPayload{
+			Code:    http.StatusText(http.StatusConflict),
+			Message: "The property looks like a duplicate; set allow_duplicate=true to create it anyway",
+		},
+		Duplicates: matches,
+	})
+	return false
+}
@@ -0,0 +1,126 @@
+package estate
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Property event types.
+const (
+	EventPropertyCreated       = "PropertyCreated"
+	EventPropertyUpdated       = "PropertyUpdated"
+	EventPropertyStatusChanged = "PropertyStatusChanged"
+	EventPropertyDeleted       = "PropertyDeleted"
+)
+
+// EventTypes lists the property event types.
+var EventTypes = []string{EventPropertyCreated, EventPropertyUpdated, EventPropertyStatusChanged, EventPropertyDeleted}
+
+// Event is a domain event describing a write to a property. Repositories
+// implementing OutboxRepo store one with each revision, in the same write as
+// the aggregate, and the Relay publishes them. Sequence is the revision
+// number, so the events of a property are ordered by it; consumers may
+// receive an event more than once and should deduplicate by ID.
+type Event struct {
+	ID          uuid.UUID         `json:"id"`
+	Type        string            `json:"type"`
+	AggregateID uuid.UUID         `json:"aggregate_id"`
+	Sequence    int64             `json:"sequence"`
+	Actor       string            `json:"actor"`
+	At          time.Time         `json:"at"`
+	Data        PropertyEventData `json:"data"`
+	Position    int64             `json:"position,omitempty"` // Order in the outbox replays follow; may be unset until published
+	PublishedAt *time.Time        `json:"published_at,omitempty"`
+}
+
+// PropertyEventData is the payload of a property event.
+type PropertyEventData struct {
+	Action   string        `json:"action"`            // Revision action, e.g. "restore" or "undelete" for PropertyUpdated
+	Property *Property     `json:"property"`          // State after the write; the last state for PropertyDeleted
+	Changes  []FieldChange `json:"changes,omitempty"` // Not set for PropertyDeleted
+	From     string        `json:"from,omitempty"`    // Previous status, for PropertyStatusChanged
+	To       string        `json:"to,omitempty"`      // New status, for PropertyStatusChanged
+}
+
+// NewEvent builds the event recording the write of a revision. before is
+// the property before the write, nil for creates.
+func NewEvent(rev *PropertyRevision, before *Property) *Event {
+	e := &Event{
+		ID:          uuid.New(),
+		AggregateID: rev.PropertyID,
+		Sequence:    rev.Number,
+		Actor:       rev.Actor,
+		At:          rev.At,
+		Data: PropertyEventData{
+			Action:   rev.Action,
+			Property: rev.Snapshot,
+			Changes:  rev.Changes,
+		},
+	}
+
+	switch rev.Action {
+	case ActionCreate:
+		e.Type = EventPropertyCreated
+	case ActionDelete:
+		e.Type = EventPropertyDeleted
+		e.Data.Changes = nil
+	case ActionTransition:
+		e.Type = EventPropertyStatusChanged
+		if before != nil {
+			e.Data.From = before.Status
+		}
+		if rev.Snapshot != nil {
+			e.Data.To = rev.Snapshot.Status
+		}
+	default:
+		e.Type = EventPropertyUpdated
+	}
+
+	return e
+}
+
+// EventQuery selects published events to replay, in outbox order.
+type EventQuery struct {
+	AggregateID uuid.UUID // Zero for every property
+	Types       []string  // Empty for every type
+	Since       time.Time // Zero for every event; compared with Event.At
+	After       int64     // Only events positioned after it
+	Limit       int
+}
+
+// OutboxRepo is implemented by property repositories that store an Event
+// with each revision, in the same write as the aggregate.
+type OutboxRepo interface {
+	// PendingEvents returns up to limit unpublished events, oldest first,
+	// leaving out those of the excluded properties. The events of a
+	// property are in sequence order.
+	PendingEvents(ctx context.Context, limit int, exclude []uuid.UUID) ([]Event, error)
+
+	// MarkPublished records that the events were published at the given
+	// time. Marking an event again is not an error.
+	MarkPublished(ctx context.Context, events []Event, at time.Time) error
+
+	// Events retrieves the published events matching the query, ordered
+	// by Position.
+	Events(ctx context.Context, query EventQuery) ([]Event, error)
+}
+
+// EventBus publishes property events to other services. Publish returns
+// once the event was accepted; an error leaves it pending.
+type EventBus interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+// ValidateEventTypes reports the first unknown event type.
+func ValidateEventTypes(types []string) error {
+	for _, t := range types {
+		if !slices.Contains(EventTypes, t) {
+			return fmt.Errorf("unknown event type %q", t)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package estate
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/pkg/lib/core"
+	"github.com/pulap/pulap/pkg/lib/telemetry"
+	"github.com/pulap/pulap/services/estate/internal/config"
+)
+
+// EventHandler handles HTTP requests for the property event outbox.
+type EventHandler struct {
+	*PropertyAccess
+	relay *Relay
+	tlm   *telemetry.HTTP
+}
+
+// NewEventHandler creates a new EventHandler replaying events through
+// relay, which is nil when no relay is configured; replays then answer
+// 503.
+func NewEventHandler(relay *Relay, access *PropertyAccess, xparams config.XParams) *EventHandler {
+	return &EventHandler{
+		PropertyAccess: access,
+		relay:          relay,
+		tlm: telemetry.NewHTTP(
+			telemetry.WithTracer(xparams.Tracer()),
+			telemetry.WithMetrics(xparams.Metrics()),
+		),
+	}
+}
+
+// RegisterRoutes registers the event routes.
+func (h *EventHandler) RegisterRoutes(r chi.Router) {
+	r.With(h.authn()).Post("/estates/events/replay", h.ReplayEvents)
+}
+
+// PermissionEventsReplay allows publishing property events again.
+const PermissionEventsReplay = "estates:events_replay"
+
+// eventsResource is the authz resource event replays are checked on.
+const eventsResource = "estate-events"
+
+// ReplayRequest selects the published events to replay. Every field is
+// optional; an empty request replays the whole outbox.
+type ReplayRequest struct {
+	AggregateID uuid.UUID  `json:"aggregate_id"`
+	Types       []string   `json:"types"`
+	Since       *time.Time `json:"since"`
+	After       int64      `json:"after"` // Outbox position to resume after
+	Limit       int        `json:"limit"`
+}
+
+// ReplayResult reports a finished replay.
+type ReplayResult struct {
+	Replayed int `json:"replayed"`
+}
+
+// ReplayEvents handles POST /estates/events/replay
+// The published events matching the ReplayRequest are published again, in
+// outbox order, e.g. to rebuild a consumer's projection. The replay stops at
+// the first event the bus rejects. Requires PermissionEventsReplay.
+func (h *EventHandler) ReplayEvents(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "EventHandler.ReplayEvents")
+	defer finish()
+	log := h.log(r)
+	ctx := r.Context()
+
+	if h.relay == nil {
+		core.RespondError(w, http.StatusServiceUnavailable, "Events are not available")
+		return
+	}
+
+	actor := requestActor(r)
+	if actor == "" {
+		core.RespondError(w, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+	if status, msg := h.checkPermission(ctx, actor, PermissionEventsReplay, eventsResource); status != 0 {
+		log.Info("event replay denied", "actor", actor)
+		core.RespondError(w, status, msg)
+		return
+	}
+
+	var req ReplayRequest
+	if !decodeOptionalBody(w, r, log, &req) {
+		return
+	}
+	if err := ValidateEventTypes(req.Types); err != nil {
+		core.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.After < 0 || req.Limit < 0 {
+		core.RespondError(w, http.StatusBadRequest, "after and limit cannot be negative")
+		return
+	}
+
+	query := EventQuery{AggregateID: req.AggregateID, Types: req.Types, After: req.After, Limit: req.Limit}
+	if req.Since != nil {
+		query.Since = *req.Since
+	}
+
+	n, err := h.relay.Replay(ctx, query)
+	if err != nil {
+		log.Error("cannot replay events", "error", err, "replayed", n)
+		core.RespondError(w, http.StatusBadGateway, fmt.Sprintf("Replay stopped after %d events", n))
+		return
+	}
+
+	log.Info("events replayed", "actor", actor, "replayed", n)
+	core.RespondSuccess(w, ReplayResult{Replayed: n})
+}
@@ -0,0 +1,484 @@
+package estate
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/services/estate/internal/money"
+	"github.com/pulap/pulap/services/estate/internal/xlsx"
+)
+
+// Export formats
+const (
+	ExportFormatCSV     = "csv"
+	ExportFormatGeoJSON = "geojson"
+	ExportFormatXLSX    = "xlsx"
+)
+
+// ExportFormats lists the formats of GET /estates/export.
+var ExportFormats = []string{ExportFormatCSV, ExportFormatGeoJSON, ExportFormatXLSX}
+
+// exportRow is a property being exported with its classification labels.
+type exportRow struct {
+	*Property
+	Labels ClassificationLabels
+}
+
+// ClassificationLabels holds the dictionary labels of a classification.
+type ClassificationLabels struct {
+	Category string
+	Type     string
+	Subtype  string
+}
+
+// exportColumn is a column of CSV and XLSX exports and a property of
+// GeoJSON features. Value returns nil for an empty cell.
+type exportColumn struct {
+	Name  string
+	Value func(r *exportRow) any
+}
+
+// exportColumns flattens a property. Column names match the import columns
+// where both exist; prices have a group of columns per price type.
+var exportColumns = buildExportColumns()
+
+func buildExportColumns() []exportColumn {
+	columns := []exportColumn{
+		{"id", func(r *exportRow) any { return r.ID }},
+		{"name", func(r *exportRow) any { return r.Name }},
+		{"description", func(r *exportRow) any { return r.Description }},
+		{"status", func(r *exportRow) any { return r.Status }},
+		{"category", func(r *exportRow) any { return r.Labels.Category }},
+		{"type", func(r *exportRow) any { return r.Labels.Type }},
+		{"subtype", func(r *exportRow) any { return r.Labels.Subtype }},
+		{"category_id", func(r *exportRow) any { return optionalID(r.Classification.CategoryID) }},
+		{"type_id", func(r *exportRow) any { return optionalID(r.Classification.TypeID) }},
+		{"subtype_id", func(r *exportRow) any { return optionalID(r.Classification.SubtypeID) }},
+
+		{"street", func(r *exportRow) any { return r.Location.Address.Street }},
+		{"number", func(r *exportRow) any { return r.Location.Address.Number }},
+		{"unit", func(r *exportRow) any { return r.Location.Address.Unit }},
+		{"city", func(r *exportRow) any { return r.Location.Address.City }},
+		{"state", func(r *exportRow) any { return r.Location.Address.State }},
+		{"postal_code", func(r *exportRow) any { return r.Location.Address.PostalCode }},
+		{"country", func(r *exportRow) any { return r.Location.Address.Country }},
+		{"region", func(r *exportRow) any { return r.Location.Region }},
+		{"latitude", func(r *exportRow) any { return coordinate(r.Location.Coordinates, r.Location.Coordinates.Latitude) }},
+		{"longitude", func(r *exportRow) any { return coordinate(r.Location.Coordinates, r.Location.Coordinates.Longitude) }},
+
+		{"total_area", func(r *exportRow) any { return r.Features.TotalArea }},
+		{"covered_area", func(r *exportRow) any { return r.Features.CoveredArea }},
+		{"land_area", func(r *exportRow) any { return r.Features.LandArea }},
+		{"bedrooms", func(r *exportRow) any { return r.Features.Bedrooms }},
+		{"bathrooms", func(r *exportRow) any { return r.Features.Bathrooms }},
+		{"half_baths", func(r *exportRow) any { return r.Features.HalfBaths }},
+		{"rooms", func(r *exportRow) any { return r.Features.Rooms }},
+		{"parking", func(r *exportRow) any { return r.Features.Parking }},
+		{"covered_parking", func(r *exportRow) any { return r.Features.CoveredParking }},
+		{"floors", func(r *exportRow) any { return r.Features.Floors }},
+		{"floor", func(r *exportRow) any { return r.Features.Floor }},
+		{"year_built", func(r *exportRow) any { return r.Features.YearBuilt }},
+		{"condition", func(r *exportRow) any { return r.Features.Condition }},
+	}
+
+	for _, flag := range AmenityFlags {
+		columns = append(columns, exportColumn{flag, func(r *exportRow) any { return *amenityFlag(&r.Features, flag) }})
+	}
+	columns = append(columns, exportColumn{"amenities", func(r *exportRow) any { return strings.Join(r.Features.Amenities, "; ") }})
+
+	for _, priceType := range PriceTypes {
+		price := func(r *exportRow) *Price {
+			for i := range r.Prices {
+				if r.Prices[i].Type == priceType {
+					return &r.Prices[i]
+				}
+			}
+			return nil
+		}
+		columns = append(columns,
+			exportColumn{priceType + "_price", func(r *exportRow) any {
+				if p := price(r); p != nil {
+					return p.Amount
+				}
+				return nil
+			}},
+			exportColumn{priceType + "_currency", func(r *exportRow) any {
+				if p := price(r); p != nil {
+					return p.Currency
+				}
+				return nil
+			}},
+			exportColumn{priceType + "_negotiable", func(r *exportRow) any {
+				if p := price(r); p != nil {
+					return p.Negotiable
+				}
+				return nil
+			}},
+		)
+	}
+
+	return append(columns,
+		exportColumn{"valuation_amount", func(r *exportRow) any {
+			if r.Valuation == nil {
+				return nil
+			}
+			return r.Valuation.Amount
+		}},
+		exportColumn{"valuation_currency", func(r *exportRow) any {
+			if r.Valuation == nil {
+				return nil
+			}
+			return r.Valuation.Currency
+		}},
+		exportColumn{"valuation_per_m2", func(r *exportRow) any {
+			if r.Valuation == nil || r.Valuation.PerSquareMeter.IsZero() {
+				return nil
+			}
+			return r.Valuation.PerSquareMeter
+		}},
+		exportColumn{"owner_id", func(r *exportRow) any { return r.OwnerID }},
+		exportColumn{"team_id", func(r *exportRow) any { return r.TeamID }},
+		exportColumn{"development_id", func(r *exportRow) any {
+			if r.DevelopmentID == nil {
+				return nil
+			}
+			return r.DevelopmentID.String()
+		}},
+		exportColumn{"revision", func(r *exportRow) any { return r.Revision }},
+		exportColumn{"created_at", func(r *exportRow) any { return r.CreatedAt }},
+		exportColumn{"created_by", func(r *exportRow) any { return r.CreatedBy }},
+		exportColumn{"updated_at", func(r *exportRow) any { return r.UpdatedAt }},
+		exportColumn{"updated_by", func(r *exportRow) any { return r.UpdatedBy }},
+	)
+}
+
+// ExportColumns returns the columns of CSV and XLSX exports, in order.
+func ExportColumns() []string {
+	names := make([]string, len(exportColumns))
+	for i, c := range exportColumns {
+		names[i] = c.Name
+	}
+	return names
+}
+
+func optionalID(id uuid.UUID) any {
+	if id == uuid.Nil {
+		return nil
+	}
+	return id
+}
+
+func coordinate(c Coordinates, v float64) any {
+	if c.IsZero() {
+		return nil
+	}
+	return v
+}
+
+// exportWriter writes export rows in a format.
+type exportWriter interface {
+	Write(r *exportRow) error
+	// Flush sends the buffered rows to the underlying writer.
+	Flush() error
+	// Close completes the file; it does not close the underlying writer.
+	Close() error
+}
+
+// newExportWriter starts an export in the given format on w.
+func newExportWriter(format string, w io.Writer) (exportWriter, error) {
+	switch format {
+	case ExportFormatCSV:
+		return newCSVExportWriter(w)
+	case ExportFormatGeoJSON:
+		return newGeoJSONExportWriter(w)
+	case ExportFormatXLSX:
+		return newXLSXExportWriter(w)
+	default:
+		return nil, fmt.Errorf("unknown export format %q", format)
+	}
+}
+
+// ExportContentType returns the media type and file extension of a format.
+func ExportContentType(format string) (string, string) {
+	switch format {
+	case ExportFormatGeoJSON:
+		return "application/geo+json", ".geojson"
+	case ExportFormatXLSX:
+		return xlsx.ContentType, ".xlsx"
+	default:
+		return "text/csv; charset=utf-8", ".csv"
+	}
+}
+
+type csvExportWriter struct {
+	w      *csv.Writer
+	record []string
+}
+
+func newCSVExportWriter(w io.Writer) (*csvExportWriter, error) {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(ExportColumns()); err != nil {
+		return nil, err
+	}
+	return &csvExportWriter{w: cw, record: make([]string, len(exportColumns))}, nil
+}
+
+func (e *csvExportWriter) Write(r *exportRow) error {
+	for i, c := range exportColumns {
+		e.record[i] = exportText(c.Value(r))
+	}
+	return e.w.Write(e.record)
+}
+
+func (e *csvExportWriter) Flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvExportWriter) Close() error {
+	return e.Flush()
+}
+
+// exportText renders a value in a CSV cell.
+func exportText(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return neutralizeFormula(v)
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.UTC().Format(time.RFC3339)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// neutralizeFormula prefixes text a spreadsheet would read as a formula
+// with an apostrophe, so user input such as "=HYPERLINK(...)" stays text.
+func neutralizeFormula(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+type xlsxExportWriter struct {
+	w      *xlsx.Writer
+	values []any
+}
+
+func newXLSXExportWriter(w io.Writer) (*xlsxExportWriter, error) {
+	xw, err := xlsx.NewWriter(w, "Properties")
+	if err != nil {
+		return nil, err
+	}
+	header := make([]any, len(exportColumns))
+	for i, c := range exportColumns {
+		header[i] = c.Name
+	}
+	if err := xw.WriteRow(header...); err != nil {
+		return nil, err
+	}
+	return &xlsxExportWriter{w: xw, values: make([]any, len(exportColumns))}, nil
+}
+
+func (e *xlsxExportWriter) Write(r *exportRow) error {
+	for i, c := range exportColumns {
+		switch v := c.Value(r).(type) {
+		case money.Decimal:
+			e.values[i] = xlsx.Number(v.String())
+		case uuid.UUID:
+			e.values[i] = v.String()
+		case string:
+			e.values[i] = neutralizeFormula(v)
+		default:
+			e.values[i] = v
+		}
+	}
+	return e.w.WriteRow(e.values...)
+}
+
+func (e *xlsxExportWriter) Flush() error {
+	return e.w.Flush()
+}
+
+func (e *xlsxExportWriter) Close() error {
+	return e.w.Close()
+}
+
+// geoJSONExportWriter writes a FeatureCollection with a Point feature per
+// property; properties without coordinates have a null geometry.
+type geoJSONExportWriter struct {
+	w     io.Writer
+	count int
+}
+
+type geoJSONFeature struct {
+	Type       string         `json:"type"`
+	ID         uuid.UUID      `json:"id"`
+	Geometry   *geoJSONPoint  `json:"geometry"`
+	Properties map[string]any `json:"properties"`
+}
+
+type geoJSONPoint struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"` // Longitude, latitude
+}
+
+func newGeoJSONExportWriter(w io.Writer) (*geoJSONExportWriter, error) {
+	if _, err := io.WriteString(w, `{"type":"FeatureCollection","features":[`); err != nil {
+		return nil, err
+	}
+	return &geoJSONExportWriter{w: w}, nil
+}
+
+func (e *geoJSONExportWriter) Write(r *exportRow) error {
+	feature := geoJSONFeature{Type: "Feature", ID: r.ID, Properties: make(map[string]any, len(exportColumns))}
+	if c := r.Location.Coordinates; !c.IsZero() {
+		feature.Geometry = &geoJSONPoint{Type: "Point", Coordinates: [2]float64{c.Longitude, c.Latitude}}
+	}
+	for _, c := range exportColumns {
+		feature.Properties[c.Name] = c.Value(r)
+	}
+
+	data, err := json.Marshal(feature)
+	if err != nil {
+		return err
+	}
+	if e.count > 0 {
+		data = append([]byte{','}, data...)
+	}
+	e.count++
+	_, err = e.w.Write(data)
+	return err
+}
+
+func (e *geoJSONExportWriter) Flush() error {
+	return nil
+}
+
+func (e *geoJSONExportWriter) Close() error {
+	_, err := io.WriteString(e.w, "]}")
+	return err
+}
+
+// writeExport writes the properties of page and of the pages after it,
+// flushing after every page. It returns the number of rows written; on
+// error the output is incomplete.
+func writeExport(ctx context.Context, repo Repo, query PropertyQuery, page *PropertyPage, labels *classificationLabeler, ew exportWriter, flush func()) (int, error) {
+	count := 0
+	for {
+		for _, p := range page.Items {
+			if err := ew.Write(&exportRow{Property: p, Labels: labels.labels(ctx, p.Classification)}); err != nil {
+				return count, err
+			}
+			count++
+		}
+		if err := ew.Flush(); err != nil {
+			return count, err
+		}
+		flush()
+
+		if page.NextCursor == "" {
+			return count, ew.Close()
+		}
+		query.Cursor = page.NextCursor
+
+		var err error
+		if page, err = repo.Search(ctx, query); err != nil {
+			return count, fmt.Errorf("cannot search properties: %w", err)
+		}
+	}
+}
+
+// classificationLabeler resolves classification IDs to dictionary labels
+// in a locale, caching them for the lifetime of an export. Options are
+// stored per locale, so a label in another locale is the one of the option
+// with the same key under the localized parent. Without a locale, or when
+// the option has no translation, the label of the stored option is used.
+type classificationLabeler struct {
+	dict    Client
+	locale  string
+	options *classificationResolver
+	cache   map[uuid.UUID]localizedOption
+}
+
+type localizedOption struct {
+	id    uuid.UUID // The option in the requested locale
+	label string
+}
+
+func newClassificationLabeler(dict Client, locale string) *classificationLabeler {
+	return &classificationLabeler{
+		dict:    dict,
+		locale:  strings.ToLower(strings.TrimSpace(locale)),
+		options: newClassificationResolver(dict),
+		cache:   map[uuid.UUID]localizedOption{},
+	}
+}
+
+// labels returns the labels of a classification. Options the dictionary
+// cannot provide have an empty label.
+func (l *classificationLabeler) labels(ctx context.Context, c Classification) ClassificationLabels {
+	category := l.localize(ctx, CategorySetName, c.CategoryID, uuid.Nil)
+	typ := l.localize(ctx, TypeSetName, c.TypeID, category.id)
+	subtype := l.localize(ctx, SubtypeSetName, c.SubtypeID, typ.id)
+	return ClassificationLabels{Category: category.label, Type: typ.label, Subtype: subtype.label}
+}
+
+func (l *classificationLabeler) localize(ctx context.Context, set string, id, parent uuid.UUID) localizedOption {
+	if id == uuid.Nil {
+		return localizedOption{}
+	}
+	if lo, ok := l.cache[id]; ok {
+		return lo
+	}
+
+	lo := localizedOption{id: id}
+	opt, err := l.dict.GetOption(ctx, id)
+	if err != nil {
+		l.cache[id] = lo
+		return lo
+	}
+	lo.label = opt.Label
+
+	if l.locale != "" && opt.Locale != "" && !strings.EqualFold(opt.Locale, l.locale) {
+		var parentID *uuid.UUID
+		if set != CategorySetName {
+			parentID = &parent
+		}
+		if parentID == nil || parent != uuid.Nil {
+			options, err := l.options.options(ctx, set, parentID)
+			if err == nil {
+				for _, o := range options {
+					if strings.EqualFold(o.Locale, l.locale) && o.Key == opt.Key {
+						lo = localizedOption{id: o.ID, label: o.Label}
+						break
+					}
+				}
+			}
+		}
+	}
+
+	l.cache[id] = lo
+	return lo
+}
@@ -0,0 +1,310 @@
+package estate
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/services/estate/internal/money"
+)
+
+var (
+	testResidencial = Option{ID: uuid.MustParse("00000000-0000-0000-0001-0000000000e5"), Locale: "es", Key: "residential", Label: "Residencial"}
+	testApartamento = Option{ID: uuid.MustParse("00000000-0000-0000-0002-0000000000e5"), ParentID: &testResidencial.ID, Locale: "es", Key: "apartment", Label: "Apartamento"}
+)
+
+func TestExportCSV(t *testing.T) {
+	repo := newExportTestRepo(5)
+	var buf bytes.Buffer
+	count := runTestExport(t, repo, ExportFormatCSV, "", &buf)
+	if count != 5 {
+		t.Errorf("expected 5 rows, got %d", count)
+	}
+	if repo.searches != 3 {
+		t.Errorf("expected 3 pages, got %d", repo.searches)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("reading CSV: %v", err)
+	}
+	if len(records) != 6 || !slices.Equal(records[0], ExportColumns()) {
+		t.Fatalf("expected a header and 5 rows, got %d records", len(records))
+	}
+
+	row := map[string]string{}
+	for i, name := range records[0] {
+		row[name] = records[1][i]
+	}
+	want := map[string]string{
+		"name":                  "Piso 0",
+		"category":              "Residential",
+		"type":                  "Apartment",
+		"subtype":               "",
+		"subtype_id":            "",
+		"city":                  "Madrid",
+		"latitude":              "40.4168",
+		"total_area":            "85.5",
+		"bedrooms":              "2",
+		"pool":                  "true",
+		"garden":                "false",
+		"amenities":             "gym; concierge",
+		"sale_price":            "250000.00",
+		"sale_currency":         "EUR",
+		"rent_monthly_price":    "",
+		"rent_monthly_currency": "",
+		"valuation_amount":      "275000.00",
+		"created_at":            "2024-05-01T10:00:00Z",
+	}
+	for name, value := range want {
+		if row[name] != value {
+			t.Errorf("%s: expected %q, got %q", name, value, row[name])
+		}
+	}
+}
+
+func TestExportGeoJSON(t *testing.T) {
+	var buf bytes.Buffer
+	runTestExport(t, newExportTestRepo(3), ExportFormatGeoJSON, "es", &buf)
+
+	var collection struct {
+		Type     string `json:"type"`
+		Features []struct {
+			Type     string `json:"type"`
+			ID       string `json:"id"`
+			Geometry *struct {
+				Type        string     `json:"type"`
+				Coordinates [2]float64 `json:"coordinates"`
+			} `json:"geometry"`
+			Properties map[string]any `json:"properties"`
+		} `json:"features"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &collection); err != nil {
+		t.Fatalf("invalid GeoJSON: %v\n%s", err, buf.String())
+	}
+	if collection.Type != "FeatureCollection" || len(collection.Features) != 3 {
+		t.Fatalf("unexpected collection: %s", buf.String())
+	}
+
+	first := collection.Features[0]
+	if first.Geometry == nil || first.Geometry.Type != "Point" || first.Geometry.Coordinates != [2]float64{-3.7038, 40.4168} {
+		t.Errorf("unexpected geometry: %+v", first.Geometry)
+	}
+	if first.Properties["category"] != "Residencial" || first.Properties["type"] != "Apartamento" {
+		t.Errorf("expected Spanish labels, got %v / %v", first.Properties["category"], first.Properties["type"])
+	}
+	if collection.Features[1].Geometry != nil {
+		t.Errorf("expected a null geometry without coordinates, got %+v", collection.Features[1].Geometry)
+	}
+}
+
+func TestExportXLSX(t *testing.T) {
+	var buf bytes.Buffer
+	runTestExport(t, newExportTestRepo(2), ExportFormatXLSX, "", &buf)
+
+	sheet := readTestSheet(t, buf.Bytes())
+	if strings.Count(sheet, "<row ") != 3 {
+		t.Errorf("expected a header and 2 rows, got %s", sheet)
+	}
+	if !strings.Contains(sheet, "<v>250000.00</v>") {
+		t.Errorf("expected prices as numbers, got %s", sheet)
+	}
+}
+
+func TestExportNeutralizesFormulas(t *testing.T) {
+	repo := newExportTestRepo(1)
+	repo.items[0].Name = "=HYPERLINK(\"http://evil.example\")"
+	repo.items[0].Location.Address.City = "@SUM(A1)"
+	repo.items[0].Location.Address.Street = "-2+3"
+	repo.items[0].Features.Amenities = []string{"+cmd", "gym"}
+
+	var buf bytes.Buffer
+	runTestExport(t, repo, ExportFormatCSV, "", &buf)
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("reading CSV: %v", err)
+	}
+	row := map[string]string{}
+	for i, name := range records[0] {
+		row[name] = records[1][i]
+	}
+	want := map[string]string{
+		"name":       "'=HYPERLINK(\"http://evil.example\")",
+		"city":       "'@SUM(A1)",
+		"street":     "'-2+3",
+		"amenities":  "'+cmd; gym",
+		"longitude":  "-3.7038",
+		"sale_price": "250000.00",
+	}
+	for name, value := range want {
+		if row[name] != value {
+			t.Errorf("CSV %s: expected %q, got %q", name, value, row[name])
+		}
+	}
+
+	buf.Reset()
+	runTestExport(t, repo, ExportFormatXLSX, "", &buf)
+	sheet := readTestSheet(t, buf.Bytes())
+	if !strings.Contains(sheet, `<t xml:space="preserve">&#39;@SUM(A1)</t>`) || strings.Contains(sheet, `<t xml:space="preserve">@SUM(A1)</t>`) {
+		t.Errorf("expected the XLSX city as neutralized text, got %s", sheet)
+	}
+}
+
+func TestExportSearchError(t *testing.T) {
+	repo := newExportTestRepo(5)
+	repo.failAfter = 1
+
+	var buf bytes.Buffer
+	ew, err := newExportWriter(ExportFormatCSV, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := PropertyQuery{Limit: 2}
+	page, _ := repo.Search(context.Background(), query)
+	count, err := writeExport(context.Background(), repo, query, page, newClassificationLabeler(newExportDictionary(), ""), ew, func() {})
+	if err == nil || count != 2 {
+		t.Errorf("expected an error after 2 rows, got %d rows and %v", count, err)
+	}
+}
+
+func TestClassificationLabeler(t *testing.T) {
+	ctx := context.Background()
+	english := Classification{CategoryID: testResidential.ID, TypeID: testApartment.ID, SubtypeID: testLoft.ID}
+
+	tests := []struct {
+		name   string
+		locale string
+		c      Classification
+		want   ClassificationLabels
+	}{
+		{name: "stored locale", c: english, want: ClassificationLabels{"Residential", "Apartment", "Loft"}},
+		{name: "translated", locale: "ES", c: english, want: ClassificationLabels{"Residencial", "Apartamento", "Loft"}},
+		{name: "same locale", locale: "en", c: english, want: ClassificationLabels{"Residential", "Apartment", "Loft"}},
+		{name: "unknown option", c: Classification{CategoryID: uuid.New(), TypeID: testApartment.ID}, want: ClassificationLabels{Type: "Apartment"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newClassificationLabeler(newExportDictionary(), tt.locale).labels(ctx, tt.c)
+			if got != tt.want {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+// readTestSheet returns the XML of the first sheet of a workbook.
+func readTestSheet(t *testing.T, workbook []byte) string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(workbook), int64(len(workbook)))
+	if err != nil {
+		t.Fatalf("invalid workbook: %v", err)
+	}
+	var sheet string
+	for _, f := range zr.File {
+		if f.Name == "xl/worksheets/sheet1.xml" {
+			rc, _ := f.Open()
+			var b bytes.Buffer
+			b.ReadFrom(rc)
+			rc.Close()
+			sheet = b.String()
+		}
+	}
+	return sheet
+}
+
+func runTestExport(t *testing.T, repo *exportTestRepo, format, locale string, buf *bytes.Buffer) int {
+	t.Helper()
+	ctx := context.Background()
+
+	ew, err := newExportWriter(format, buf)
+	if err != nil {
+		t.Fatalf("newExportWriter: %v", err)
+	}
+	query := PropertyQuery{Limit: 2}
+	page, err := repo.Search(ctx, query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	count, err := writeExport(ctx, repo, query, page, newClassificationLabeler(newExportDictionary(), locale), ew, func() {})
+	if err != nil {
+		t.Fatalf("writeExport: %v", err)
+	}
+	return count
+}
+
+// exportTestRepo pages through a fixed list of properties; the cursor is
+// the offset of the next page.
+type exportTestRepo struct {
+	Repo
+	items     []*Property
+	searches  int
+	failAfter int
+}
+
+func newExportTestRepo(n int) *exportTestRepo {
+	repo := &exportTestRepo{}
+	for i := 0; i < n; i++ {
+		p := &Property{
+			ID:             uuid.New(),
+			Name:           "Piso " + strconv.Itoa(i),
+			Classification: Classification{CategoryID: testResidential.ID, TypeID: testApartment.ID},
+			Location:       Location{Address: Address{Street: "Mayor 12", City: "Madrid", Country: "ES"}},
+			Features:       Features{TotalArea: 85.5, Bedrooms: 2, Pool: true, Amenities: []string{"gym", "concierge"}},
+			Prices:         []Price{{Amount: money.MustParse("250000.00"), Currency: "EUR", Type: "sale"}},
+			Valuation:      &Valuation{Currency: "USD", PriceType: "sale", Amount: money.MustParse("275000.00")},
+			Status:         "available",
+			CreatedAt:      mustTime("2024-05-01T10:00:00Z"),
+		}
+		if i%2 == 0 {
+			p.Location.Coordinates = Coordinates{Latitude: 40.4168, Longitude: -3.7038}
+		}
+		repo.items = append(repo.items, p)
+	}
+	return repo
+}
+
+func (r *exportTestRepo) Search(ctx context.Context, q PropertyQuery) (*PropertyPage, error) {
+	r.searches++
+	if r.failAfter > 0 && r.searches > r.failAfter {
+		return nil, errors.New("search failed")
+	}
+	offset, _ := strconv.Atoi(q.Cursor)
+	end := min(offset+q.Limit, len(r.items))
+	page := &PropertyPage{Items: r.items[offset:end], Total: int64(len(r.items))}
+	if end < len(r.items) {
+		page.NextCursor = strconv.Itoa(end)
+	}
+	return page, nil
+}
+
+// newExportDictionary returns the import test dictionary with the
+// residential options translated to Spanish.
+func newExportDictionary() *importDictionary {
+	dict := newImportDictionary()
+	for _, set := range []string{CategorySetName, TypeSetName, SubtypeSetName} {
+		for i := range dict.options[set] {
+			dict.options[set][i].Locale = "en"
+		}
+	}
+	dict.options[CategorySetName] = append(dict.options[CategorySetName], testResidencial)
+	dict.options[TypeSetName] = append(dict.options[TypeSetName], testApartamento)
+	return dict
+}
+
+func mustTime(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
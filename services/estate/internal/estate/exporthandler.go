@@ -0,0 +1,79 @@
+package estate
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pulap/pulap/pkg/lib/core"
+)
+
+// ExportProperties handles GET /estates/export
+// ?format= is csv (default), geojson or xlsx. The filters and sort of
+// GET /estates apply; limit and cursor do not, every matching property the
+// user may read is exported. ?locale= selects the language of the classification labels.
+// The file is streamed page by page; when a later page cannot be read the
+// download ends early and the error is only logged.
+func (h *Handler) ExportProperties(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "Handler.ExportProperties")
+	defer finish()
+	log := h.log(r)
+	ctx := r.Context()
+
+	values := r.URL.Query()
+	format := strings.ToLower(values.Get("format"))
+	if format == "" {
+		format = ExportFormatCSV
+	}
+	if !slices.Contains(ExportFormats, format) {
+		core.RespondError(w, http.StatusBadRequest, "format must be one of: "+strings.Join(ExportFormats, ", "))
+		return
+	}
+
+	values.Del("limit")
+	values.Del("cursor")
+	query, validationErrors := ParsePropertyQuery(values)
+	if len(validationErrors) > 0 {
+		log.Debug("invalid export query", "errors", validationErrors)
+		core.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid query: %s", validationErrors[0].Message))
+		return
+	}
+	query.Limit = MaxSearchLimit
+
+	access, ok := h.access(w, r, PermissionRead)
+	if !ok {
+		return
+	}
+	access.Restrict(&query)
+
+	// The first page is read before answering, so that a failing search is
+	// still reported with an error status
+	page, err := h.repo.Search(ctx, query)
+	if err != nil {
+		log.Error("error searching properties", "error", err)
+		core.RespondError(w, http.StatusInternalServerError, "Could not retrieve properties")
+		return
+	}
+
+	contentType, ext := ExportContentType(format)
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "properties-"+time.Now().UTC().Format("20060102")+ext))
+	w.Header().Set("Cache-Control", "no-store")
+
+	ew, err := newExportWriter(format, w)
+	if err != nil {
+		log.Error("cannot start export", "error", err, "format", format)
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	labels := newClassificationLabeler(h.dictClient, values.Get("locale"))
+	count, err := writeExport(ctx, h.repo, query, page, labels, ew, func() { _ = rc.Flush() })
+	if err != nil {
+		log.Error("export interrupted", "error", err, "format", format, "rows", count)
+		return
+	}
+	log.Debug("properties exported", "format", format, "rows", count)
+}
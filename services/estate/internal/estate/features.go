@@ -3,42 +3,47 @@ package estate
 // Features represents the physical characteristics and amenities of a property.
 type Features struct {
 	// Basic measurements
-	TotalArea    float64 `json:"total_area"`     // Total area in square meters
-	CoveredArea  float64 `json:"covered_area"`   // Covered/built area in square meters
-	LandArea     float64 `json:"land_area"`      // Land/lot area in square meters
+	TotalArea   float64 `json:"total_area" bson:"total_area"`     // Total area in square meters
+	CoveredArea float64 `json:"covered_area" bson:"covered_area"` // Covered/built area in square meters
+	LandArea    float64 `json:"land_area" bson:"land_area"`       // Land/lot area in square meters
 
 	// Rooms
-	Bedrooms   int `json:"bedrooms"`
-	Bathrooms  int `json:"bathrooms"`
-	HalfBaths  int `json:"half_baths"`  // Toilets without shower/tub
-	Rooms      int `json:"rooms"`       // Total rooms
+	Bedrooms  int `json:"bedrooms" bson:"bedrooms"`
+	Bathrooms int `json:"bathrooms" bson:"bathrooms"`
+	HalfBaths int `json:"half_baths" bson:"half_baths"` // Toilets without shower/tub
+	Rooms     int `json:"rooms" bson:"rooms"`           // Total rooms
 
 	// Parking
-	Parking        int  `json:"parking"`          // Number of parking spaces
-	CoveredParking int  `json:"covered_parking"`  // Covered/garage spaces
+	Parking        int `json:"parking" bson:"parking"`                 // Number of parking spaces
+	CoveredParking int `json:"covered_parking" bson:"covered_parking"` // Covered/garage spaces
 
 	// Building details
-	Floors      int    `json:"floors"`       // Number of floors in the property
-	Floor       int    `json:"floor"`        // Floor number (for apartments)
-	YearBuilt   int    `json:"year_built"`
-	Condition   string `json:"condition"`    // e.g., "new", "excellent", "good", "fair", "needs_work"
+	Floors    int    `json:"floors" bson:"floors"` // Number of floors in the property
+	Floor     int    `json:"floor" bson:"floor"`   // Floor number (for apartments)
+	YearBuilt int    `json:"year_built" bson:"year_built"`
+	Condition string `json:"condition" bson:"condition"` // e.g., "new", "excellent", "good", "fair", "needs_work"
 
 	// Amenities (boolean flags)
-	Pool          bool `json:"pool"`
-	Garden        bool `json:"garden"`
-	Balcony       bool `json:"balcony"`
-	Terrace       bool `json:"terrace"`
-	Elevator      bool `json:"elevator"`
-	AirConditioning bool `json:"air_conditioning"`
-	Heating       bool `json:"heating"`
-	Furnished     bool `json:"furnished"`
-	PetFriendly   bool `json:"pet_friendly"`
-	Storage       bool `json:"storage"`
-	Laundry       bool `json:"laundry"`
-	Fireplace     bool `json:"fireplace"`
+	Pool            bool `json:"pool" bson:"pool"`
+	Garden          bool `json:"garden" bson:"garden"`
+	Balcony         bool `json:"balcony" bson:"balcony"`
+	Terrace         bool `json:"terrace" bson:"terrace"`
+	Elevator        bool `json:"elevator" bson:"elevator"`
+	AirConditioning bool `json:"air_conditioning" bson:"air_conditioning"`
+	Heating         bool `json:"heating" bson:"heating"`
+	Furnished       bool `json:"furnished" bson:"furnished"`
+	PetFriendly     bool `json:"pet_friendly" bson:"pet_friendly"`
+	Storage         bool `json:"storage" bson:"storage"`
+	Laundry         bool `json:"laundry" bson:"laundry"`
+	Fireplace       bool `json:"fireplace" bson:"fireplace"`
 
 	// Additional amenities as flexible list
-	Amenities []string `json:"amenities,omitempty"` // e.g., ["gym", "security", "concierge"]
+	Amenities []string `json:"amenities,omitempty" bson:"amenities,omitempty"` // e.g., ["gym", "security", "concierge"]
+}
+
+// Has reports whether the amenity flag, one of AmenityFlags, is set.
+func (f Features) Has(flag string) bool {
+	return *amenityFlag(&f, flag)
 }
 
 // Validate performs basic validation on the features.
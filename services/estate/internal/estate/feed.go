@@ -0,0 +1,404 @@
+package estate
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/pkg/lib/core"
+)
+
+// MaxFeedExclusions caps the excluded properties reported by a feed status.
+const MaxFeedExclusions = 100
+
+var (
+	ErrFeedNotFound = errors.New("feed not found")
+	ErrFeedNotReady = errors.New("feed has not been generated yet")
+)
+
+// FeedFormatter renders properties in the XML format of a property portal.
+type FeedFormatter interface {
+	// Name is the format feeds are configured with, e.g. "kyero".
+	Name() string
+
+	// ContentType is the media type of rendered feeds.
+	ContentType() string
+
+	// Check reports feed settings the format does not support.
+	Check(feed *Feed) error
+
+	// Validate checks an item against the portal schema. Items with errors
+	// are left out of the feed.
+	Validate(feed *Feed, item *FeedItem) []ValidationError
+
+	// Write renders a feed of valid items.
+	Write(w io.Writer, feed *Feed, items []*FeedItem) error
+}
+
+// Feed is a syndication feed published to a portal: the properties matching
+// its filters, rendered by the formatter of its format.
+type Feed struct {
+	Name       string
+	Format     string
+	Statuses   []string // Empty for any status
+	OwnerID    string   // Empty for any owner
+	PriceTypes []string // Accepted price types, in order of preference; empty for the first price
+	Language   string   // Language of descriptions and links, e.g. "en"
+
+	// Types and Features override the portal codes of the formatter: Types
+	// is keyed by dictionary option key, Features by amenity flag or
+	// Features.Amenities entry. An empty code leaves the feature out, or
+	// the property when no other type code applies.
+	Types    map[string]string
+	Features map[string]string
+}
+
+// FeedItem is a property prepared for a feed.
+type FeedItem struct {
+	*Property
+	Price          Price              // The price selected by the feed price types
+	Classification FeedClassification // Dictionary keys of the classification
+	URL            string             // Public listing page, empty when not configured
+	Images         []string           // Public photo URLs, cover first
+}
+
+// FeedClassification holds the dictionary keys of a classification, which
+// formatters map to portal codes.
+type FeedClassification struct {
+	Category string
+	Type     string
+	Subtype  string
+}
+
+// FeedLinks builds the public URLs a feed points to.
+type FeedLinks struct {
+	Listing string // Listing page URL, "{id}" is replaced by the property ID
+	Media   string // Base URL media content paths are appended to
+}
+
+// FeedStatus describes the last generation of a feed.
+type FeedStatus struct {
+	Name        string          `json:"name"`
+	Format      string          `json:"format"`
+	GeneratedAt *time.Time      `json:"generated_at,omitempty"`
+	Items       int             `json:"items"`
+	Excluded    int             `json:"excluded"`
+	Exclusions  []FeedExclusion `json:"exclusions,omitempty"` // The first MaxFeedExclusions
+	Error       string          `json:"error,omitempty"`      // Last failed generation; the previous feed is still served
+}
+
+// FeedExclusion is a property left out of a feed and the reasons.
+type FeedExclusion struct {
+	PropertyID uuid.UUID         `json:"property_id"`
+	Name       string            `json:"name"`
+	Errors     []ValidationError `json:"errors"`
+}
+
+// FeedContent is a rendered feed.
+type FeedContent struct {
+	Data        []byte
+	ContentType string
+	ETag        string
+	GeneratedAt time.Time
+}
+
+// Feeds keeps the configured feeds rendered, regenerating them periodically
+// so portals pulling them never wait for a search.
+type Feeds struct {
+	repo       Repo
+	dict       Client
+	media      MediaRepo // nil leaves images out
+	feeds      []Feed
+	formatters map[string]FeedFormatter
+	links      FeedLinks
+	refresh    time.Duration
+	log        core.Logger
+	now        func() time.Time
+
+	mu       sync.RWMutex
+	status   map[string]FeedStatus
+	contents map[string]*FeedContent
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewFeeds returns the feeds regenerated every refresh. It fails if a feed
+// uses an unknown format or settings its formatter does not support.
+func NewFeeds(repo Repo, dict Client, media MediaRepo, feeds []Feed, formatters []FeedFormatter, links FeedLinks, refresh time.Duration, log core.Logger) (*Feeds, error) {
+	f := &Feeds{
+		repo:       repo,
+		dict:       dict,
+		media:      media,
+		formatters: map[string]FeedFormatter{},
+		links:      links,
+		refresh:    refresh,
+		log:        log,
+		now:        time.Now,
+		status:     map[string]FeedStatus{},
+		contents:   map[string]*FeedContent{},
+	}
+	for _, formatter := range formatters {
+		f.formatters[formatter.Name()] = formatter
+	}
+
+	for _, feed := range feeds {
+		formatter, ok := f.formatters[feed.Format]
+		if !ok {
+			return nil, fmt.Errorf("feed %s: unknown format %q", feed.Name, feed.Format)
+		}
+		if slices.ContainsFunc(f.feeds, func(o Feed) bool { return o.Name == feed.Name }) {
+			return nil, fmt.Errorf("feed %s is defined twice", feed.Name)
+		}
+		for _, t := range feed.PriceTypes {
+			if !slices.Contains(PriceTypes, t) {
+				return nil, fmt.Errorf("feed %s: unknown price type %q", feed.Name, t)
+			}
+		}
+		if err := formatter.Check(&feed); err != nil {
+			return nil, fmt.Errorf("feed %s: %w", feed.Name, err)
+		}
+		f.feeds = append(f.feeds, feed)
+		f.status[feed.Name] = FeedStatus{Name: feed.Name, Format: feed.Format}
+	}
+	return f, nil
+}
+
+// Start generates the feeds in the background, then every refresh.
+func (f *Feeds) Start(ctx context.Context) error {
+	if len(f.feeds) == 0 {
+		return nil
+	}
+
+	workerCtx, cancel := context.WithCancel(context.Background())
+	f.cancel = cancel
+	f.wg.Add(1)
+	go func() {
+		defer f.wg.Done()
+		ticker := time.NewTicker(f.refresh)
+		defer ticker.Stop()
+		for {
+			f.GenerateAll(workerCtx)
+			select {
+			case <-workerCtx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop stops the regeneration, waiting for a running one to be interrupted.
+func (f *Feeds) Stop(ctx context.Context) error {
+	if f.cancel == nil {
+		return nil
+	}
+	f.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		f.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// GenerateAll regenerates every feed. Failures are logged and recorded in
+// the feed status.
+func (f *Feeds) GenerateAll(ctx context.Context) {
+	for i := range f.feeds {
+		if ctx.Err() != nil {
+			return
+		}
+		if _, err := f.Generate(ctx, f.feeds[i].Name); err != nil {
+			f.log.Error("cannot generate feed", "feed", f.feeds[i].Name, "error", err)
+		}
+	}
+}
+
+// Generate regenerates a feed. On failure the previous content is kept.
+func (f *Feeds) Generate(ctx context.Context, name string) (FeedStatus, error) {
+	i := slices.IndexFunc(f.feeds, func(feed Feed) bool { return feed.Name == name })
+	if i < 0 {
+		return FeedStatus{}, ErrFeedNotFound
+	}
+	feed := &f.feeds[i]
+
+	status, content, err := f.render(ctx, feed)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err != nil {
+		status = f.status[name]
+		status.Error = err.Error()
+		f.status[name] = status
+		return status, err
+	}
+	f.status[name] = status
+	f.contents[name] = content
+	return status, nil
+}
+
+// Status returns the status of every feed, in configuration order.
+func (f *Feeds) Status() []FeedStatus {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	list := make([]FeedStatus, 0, len(f.feeds))
+	for _, feed := range f.feeds {
+		list = append(list, f.status[feed.Name])
+	}
+	return list
+}
+
+// Content returns the last rendered content of a feed.
+func (f *Feeds) Content(name string) (*FeedContent, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if _, ok := f.status[name]; !ok {
+		return nil, ErrFeedNotFound
+	}
+	content, ok := f.contents[name]
+	if !ok {
+		return nil, ErrFeedNotReady
+	}
+	return content, nil
+}
+
+func (f *Feeds) render(ctx context.Context, feed *Feed) (FeedStatus, *FeedContent, error) {
+	formatter := f.formatters[feed.Format]
+	status := FeedStatus{Name: feed.Name, Format: feed.Format}
+
+	query := PropertyQuery{
+		Statuses: feed.Statuses,
+		OwnerID:  feed.OwnerID,
+		Limit:    MaxSearchLimit,
+		Sort:     []SortOrder{{Field: SortCreatedAt}},
+	}
+	if len(feed.PriceTypes) == 1 {
+		query.Price = &PriceFilter{Type: feed.PriceTypes[0]}
+	}
+	if errs := query.Normalize(); len(errs) > 0 {
+		return status, nil, fmt.Errorf("invalid filters: %s", errs[0].Message)
+	}
+
+	keys := newClassificationKeys(f.dict)
+	var items []*FeedItem
+	for {
+		page, err := f.repo.Search(ctx, query)
+		if err != nil {
+			return status, nil, fmt.Errorf("cannot search properties: %w", err)
+		}
+		for _, p := range page.Items {
+			item, errs, err := f.item(ctx, feed, p, keys)
+			if err != nil {
+				return status, nil, err
+			}
+			if len(errs) == 0 {
+				errs = formatter.Validate(feed, item)
+			}
+			if len(errs) > 0 {
+				status.Excluded++
+				if len(status.Exclusions) < MaxFeedExclusions {
+					status.Exclusions = append(status.Exclusions, FeedExclusion{PropertyID: p.ID, Name: p.Name, Errors: errs})
+				}
+				continue
+			}
+			items = append(items, item)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		query.Cursor = page.NextCursor
+	}
+
+	var buf bytes.Buffer
+	if err := formatter.Write(&buf, feed, items); err != nil {
+		return status, nil, fmt.Errorf("cannot render feed: %w", err)
+	}
+
+	sum := sha256.Sum256(buf.Bytes())
+	generated := f.now().UTC()
+	status.GeneratedAt = &generated
+	status.Items = len(items)
+	return status, &FeedContent{
+		Data:        buf.Bytes(),
+		ContentType: formatter.ContentType(),
+		ETag:        `"` + hex.EncodeToString(sum[:16]) + `"`,
+		GeneratedAt: generated,
+	}, nil
+}
+
+// item prepares a property for a feed. It returns validation errors for
+// properties that cannot be listed whatever the format, and an error when
+// a dependency fails: an unreachable dictionary aborts the generation
+// rather than delisting properties.
+func (f *Feeds) item(ctx context.Context, feed *Feed, p *Property, keys *classificationKeys) (*FeedItem, []ValidationError, error) {
+	price, ok := feedPrice(p.Prices, feed.PriceTypes)
+	if !ok {
+		return nil, []ValidationError{{Field: "prices", Message: "no price of types " + strings.Join(feed.PriceTypes, ", ")}}, nil
+	}
+
+	classification, err := keys.classification(ctx, p.Classification)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	item := &FeedItem{Property: p, Price: price, Classification: classification}
+	if f.links.Listing != "" {
+		item.URL = strings.ReplaceAll(f.links.Listing, "{id}", p.ID.String())
+	}
+
+	if f.media != nil && f.links.Media != "" {
+		media, err := f.media.ListMedia(ctx, p.ID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot list media of %s: %w", p.ID, err)
+		}
+		base := strings.TrimSuffix(f.links.Media, "/")
+		for _, m := range media {
+			if m.Kind != MediaPhoto {
+				continue
+			}
+			url := fmt.Sprintf("%s/estates/%s/media/%s/content", base, m.PropertyID, m.ID)
+			if m.Cover {
+				item.Images = append([]string{url}, item.Images...)
+				continue
+			}
+			item.Images = append(item.Images, url)
+		}
+	}
+	return item, nil, nil
+}
+
+// feedPrice returns the first price of the preferred types, or the first
+// price when no type is preferred.
+func feedPrice(prices []Price, types []string) (Price, bool) {
+	if len(types) == 0 {
+		if len(prices) == 0 {
+			return Price{}, false
+		}
+		return prices[0], true
+	}
+	for _, t := range types {
+		for _, p := range prices {
+			if p.Type == t {
+				return p, true
+			}
+		}
+	}
+	return Price{}, false
+}
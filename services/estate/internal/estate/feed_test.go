@@ -0,0 +1,218 @@
+package estate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/pkg/lib/core"
+	"github.com/pulap/pulap/services/estate/internal/money"
+)
+
+func TestNewFeedsInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		feeds []Feed
+		want  string
+	}{
+		{"unknown format", []Feed{{Name: "a", Format: "idealista"}}, `unknown format "idealista"`},
+		{"duplicate", []Feed{{Name: "a", Format: "test"}, {Name: "a", Format: "test"}}, "defined twice"},
+		{"price type", []Feed{{Name: "a", Format: "test", PriceTypes: []string{"lease"}}}, `unknown price type "lease"`},
+		{"formatter check", []Feed{{Name: "a", Format: "test", Language: "xx"}}, "unsupported language"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewFeeds(newExportTestRepo(0), newImportDictionary(), nil, tt.feeds, []FeedFormatter{testFeedFormatter{}}, FeedLinks{}, time.Minute, core.NewNoopLogger())
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestFeedsGenerate(t *testing.T) {
+	repo := newExportTestRepo(5)
+	repo.items[1].Prices = []Price{{Amount: money.MustParse("900"), Currency: "EUR", Type: "rent_monthly"}}
+	repo.items[2].Prices = append(repo.items[2].Prices, Price{Amount: money.MustParse("1200"), Currency: "EUR", Type: "rent_monthly"})
+	repo.items[3].Prices = []Price{{Amount: money.MustParse("50"), Currency: "EUR", Type: "rent_daily"}}
+	repo.items[4].Location.Address.City = ""
+
+	media := &memMediaRepo{items: map[uuid.UUID]Media{}}
+	photo := Media{ID: uuid.New(), PropertyID: repo.items[0].ID, Kind: MediaPhoto, Position: 0}
+	cover := Media{ID: uuid.New(), PropertyID: repo.items[0].ID, Kind: MediaPhoto, Position: 1, Cover: true}
+	plan := Media{ID: uuid.New(), PropertyID: repo.items[0].ID, Kind: MediaFloorPlan, Position: 2}
+	for _, m := range []Media{photo, cover, plan} {
+		media.items[m.ID] = m
+	}
+
+	feeds := newTestFeeds(t, repo, media, Feed{Name: "portal", Format: "test", PriceTypes: []string{"rent_monthly", "sale"}})
+	status, err := feeds.Generate(context.Background(), "portal")
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if status.Items != 3 || status.Excluded != 2 || status.GeneratedAt == nil {
+		t.Fatalf("expected 3 items and 2 exclusions, got %+v", status)
+	}
+	excluded := map[uuid.UUID]string{}
+	for _, e := range status.Exclusions {
+		excluded[e.PropertyID] = e.Errors[0].Field
+	}
+	if excluded[repo.items[3].ID] != "prices" || excluded[repo.items[4].ID] != "town" {
+		t.Errorf("unexpected exclusions: %+v", status.Exclusions)
+	}
+
+	content, err := feeds.Content("portal")
+	if err != nil {
+		t.Fatalf("Content: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(content.Data)), "\n")
+	base := "https://estate.example.com/estates/" + repo.items[0].ID.String() + "/media/"
+	want := []string{
+		fmt.Sprintf("%s sale 250000.00 residential/apartment https://example.com/p/%s %scontent %scontent", repo.items[0].ID, repo.items[0].ID, base+cover.ID.String()+"/", base+photo.ID.String()+"/"),
+		fmt.Sprintf("%s rent_monthly 900 residential/apartment https://example.com/p/%s", repo.items[1].ID, repo.items[1].ID),
+		fmt.Sprintf("%s rent_monthly 1200 residential/apartment https://example.com/p/%s", repo.items[2].ID, repo.items[2].ID),
+	}
+	if !slices.Equal(lines, want) {
+		t.Errorf("unexpected feed:\n%s\nexpected:\n%s", strings.Join(lines, "\n"), strings.Join(want, "\n"))
+	}
+	if content.ContentType != "text/plain" || !strings.HasPrefix(content.ETag, `"`) || len(content.ETag) != 34 {
+		t.Errorf("unexpected content metadata: %q %q", content.ContentType, content.ETag)
+	}
+}
+
+func TestFeedsGenerateSinglePriceType(t *testing.T) {
+	repo := &feedQueryRepo{}
+	feeds := newTestFeeds(t, repo, nil, Feed{Name: "portal", Format: "test", Statuses: []string{"available"}, OwnerID: "agency-1", PriceTypes: []string{"sale"}})
+	if _, err := feeds.Generate(context.Background(), "portal"); err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	q := repo.query
+	if !slices.Equal(q.Statuses, []string{"available"}) || q.OwnerID != "agency-1" || q.Price == nil || q.Price.Type != "sale" {
+		t.Errorf("unexpected query: %+v", q)
+	}
+}
+
+func TestFeedsGenerateKeepsContentOnError(t *testing.T) {
+	repo := newExportTestRepo(2)
+	feeds := newTestFeeds(t, repo, nil, Feed{Name: "portal", Format: "test"})
+	ctx := context.Background()
+
+	if _, err := feeds.Content("portal"); !errors.Is(err, ErrFeedNotReady) {
+		t.Fatalf("expected ErrFeedNotReady, got %v", err)
+	}
+	if _, err := feeds.Content("other"); !errors.Is(err, ErrFeedNotFound) {
+		t.Fatalf("expected ErrFeedNotFound, got %v", err)
+	}
+
+	if _, err := feeds.Generate(ctx, "portal"); err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	before, _ := feeds.Content("portal")
+
+	repo.failAfter = repo.searches
+	status, err := feeds.Generate(ctx, "portal")
+	if err == nil {
+		t.Fatal("expected the generation to fail")
+	}
+	if status.Error == "" || status.Items != 2 {
+		t.Errorf("expected the previous status with an error, got %+v", status)
+	}
+	after, err := feeds.Content("portal")
+	if err != nil || after != before {
+		t.Errorf("expected the previous content to be kept, got %v", err)
+	}
+}
+
+func TestFeedsGenerateDictionaryError(t *testing.T) {
+	repo := newExportTestRepo(1)
+	repo.items[0].Classification.CategoryID = uuid.New()
+	feeds := newTestFeeds(t, repo, nil, Feed{Name: "portal", Format: "test"})
+	if _, err := feeds.Generate(context.Background(), "portal"); err == nil {
+		t.Fatal("expected an unresolvable classification to fail the generation")
+	}
+}
+
+func TestFeedPrice(t *testing.T) {
+	sale := Price{Type: "sale"}
+	rent := Price{Type: "rent_monthly"}
+	tests := []struct {
+		name   string
+		prices []Price
+		types  []string
+		want   Price
+		ok     bool
+	}{
+		{"first price", []Price{rent, sale}, nil, rent, true},
+		{"preferred", []Price{sale, rent}, []string{"rent_monthly", "sale"}, rent, true},
+		{"fallback", []Price{sale}, []string{"rent_monthly", "sale"}, sale, true},
+		{"none", []Price{sale}, []string{"rent_weekly"}, Price{}, false},
+		{"no prices", nil, nil, Price{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := feedPrice(tt.prices, tt.types)
+			if ok != tt.ok || got.Type != tt.want.Type {
+				t.Errorf("expected %q %v, got %q %v", tt.want.Type, tt.ok, got.Type, ok)
+			}
+		})
+	}
+}
+
+func newTestFeeds(t *testing.T, repo Repo, media MediaRepo, feeds ...Feed) *Feeds {
+	t.Helper()
+	links := FeedLinks{Listing: "https://example.com/p/{id}", Media: "https://estate.example.com/"}
+	f, err := NewFeeds(repo, newImportDictionary(), media, feeds, []FeedFormatter{testFeedFormatter{}}, links, time.Minute, core.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewFeeds: %v", err)
+	}
+	return f
+}
+
+// testFeedFormatter writes a line per item and requires a town.
+type testFeedFormatter struct{}
+
+func (testFeedFormatter) Name() string        { return "test" }
+func (testFeedFormatter) ContentType() string { return "text/plain" }
+
+func (testFeedFormatter) Check(feed *Feed) error {
+	if feed.Language != "" && feed.Language != "en" {
+		return errors.New("unsupported language")
+	}
+	return nil
+}
+
+func (testFeedFormatter) Validate(feed *Feed, item *FeedItem) []ValidationError {
+	if item.Location.Address.City == "" {
+		return []ValidationError{{Field: "town", Message: "is required"}}
+	}
+	return nil
+}
+
+func (testFeedFormatter) Write(w io.Writer, feed *Feed, items []*FeedItem) error {
+	for _, item := range items {
+		fields := []string{item.ID.String(), item.Price.Type, item.Price.Amount.String(), item.Classification.Category + "/" + item.Classification.Type, item.URL}
+		fields = append(fields, item.Images...)
+		if _, err := fmt.Fprintln(w, strings.Join(fields, " ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// feedQueryRepo records the query of an empty search.
+type feedQueryRepo struct {
+	Repo
+	query PropertyQuery
+}
+
+func (r *feedQueryRepo) Search(ctx context.Context, q PropertyQuery) (*PropertyPage, error) {
+	r.query = q
+	return &PropertyPage{}, nil
+}
@@ -0,0 +1,105 @@
+package estate
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/pulap/pulap/pkg/lib/core"
+	"github.com/pulap/pulap/pkg/lib/telemetry"
+	"github.com/pulap/pulap/services/estate/internal/config"
+)
+
+// FeedHandler handles HTTP requests for the portal feeds.
+type FeedHandler struct {
+	*PropertyAccess
+	feeds *Feeds
+	tlm   *telemetry.HTTP
+}
+
+// NewFeedHandler creates a new FeedHandler serving the feeds generated by
+// feeds, which is nil when none is configured.
+func NewFeedHandler(feeds *Feeds, access *PropertyAccess, xparams config.XParams) *FeedHandler {
+	return &FeedHandler{
+		PropertyAccess: access,
+		feeds:          feeds,
+		tlm: telemetry.NewHTTP(
+			telemetry.WithTracer(xparams.Tracer()),
+			telemetry.WithMetrics(xparams.Metrics()),
+		),
+	}
+}
+
+// RegisterRoutes registers the feed routes. Feeds take no bearer token, as
+// portals pull them; listing them does.
+func (h *FeedHandler) RegisterRoutes(r chi.Router) {
+	r.Get("/estates/feeds/{name}", h.GetFeed)
+	r.With(h.authn()).Get("/estates/feeds", h.ListFeeds)
+}
+
+// FeedMeta describes a feed list response.
+type FeedMeta struct {
+	Count int `json:"count"`
+}
+
+// feedRetryAfter is suggested to portals pulling a feed not generated yet.
+const feedRetryAfter = 60
+
+// ListFeeds handles GET /estates/feeds
+// Each feed reports its last generation and the properties it left out.
+func (h *FeedHandler) ListFeeds(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "FeedHandler.ListFeeds")
+	defer finish()
+
+	var list []FeedStatus
+	if h.feeds != nil {
+		list = h.feeds.Status()
+	}
+	if list == nil {
+		list = []FeedStatus{}
+	}
+
+	links := make([]core.Link, 0, len(list))
+	for _, s := range list {
+		links = append(links, core.Link{Rel: s.Name, Href: "/estates/feeds/" + s.Name})
+	}
+	core.RespondSuccessWithMeta(w, list, FeedMeta{Count: len(list)}, links...)
+}
+
+// GetFeed handles GET /estates/feeds/{name}
+// Portals pull the last generated feed; If-None-Match and
+// If-Modified-Since are honoured.
+func (h *FeedHandler) GetFeed(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "FeedHandler.GetFeed")
+	defer finish()
+	log := h.log(r)
+
+	if h.feeds == nil {
+		core.RespondError(w, http.StatusNotFound, "Feed not found")
+		return
+	}
+
+	name := chi.URLParam(r, "name")
+	content, err := h.feeds.Content(name)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrFeedNotFound):
+			core.RespondError(w, http.StatusNotFound, "Feed not found")
+		case errors.Is(err, ErrFeedNotReady):
+			w.Header().Set("Retry-After", strconv.Itoa(feedRetryAfter))
+			core.RespondError(w, http.StatusServiceUnavailable, "Feed is being generated, try again later")
+		default:
+			log.Error("error loading feed", "error", err, "feed", name)
+			core.RespondError(w, http.StatusInternalServerError, "Could not retrieve feed")
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", content.ContentType)
+	w.Header().Set("ETag", content.ETag)
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	http.ServeContent(w, r, "", content.GeneratedAt, bytes.NewReader(content.Data))
+}
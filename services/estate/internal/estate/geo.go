@@ -0,0 +1,224 @@
+package estate
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	// DefaultGeoLimit is the number of results returned when a geo query does not set one.
+	DefaultGeoLimit = 100
+	// MaxGeoLimit caps the number of results of a geo query.
+	MaxGeoLimit = 500
+	// MaxGeoRadius caps radius queries, in meters.
+	MaxGeoRadius = 200_000
+
+	earthRadiusMeters = 6_371_008.8
+)
+
+// GeoShape is the kind of area a geo query searches in.
+type GeoShape string
+
+const (
+	GeoRadius  GeoShape = "radius"
+	GeoBBox    GeoShape = "bbox"
+	GeoPolygon GeoShape = "polygon"
+)
+
+// GeoPoint is a WGS84 position.
+type GeoPoint struct {
+	Lat float64 `json:"lat"`
+	Lng float64 `json:"lng"`
+}
+
+// BoundingBox is a latitude/longitude aligned rectangle, e.g. a map viewport.
+// Boxes crossing the antimeridian are not supported.
+type BoundingBox struct {
+	South float64 `json:"south"`
+	West  float64 `json:"west"`
+	North float64 `json:"north"`
+	East  float64 `json:"east"`
+}
+
+// Contains returns true if the point lies inside the box, edges included.
+func (b BoundingBox) Contains(p GeoPoint) bool {
+	return p.Lat >= b.South && p.Lat <= b.North && p.Lng >= b.West && p.Lng <= b.East
+}
+
+// Center returns the middle of the box.
+func (b BoundingBox) Center() GeoPoint {
+	return GeoPoint{Lat: (b.South + b.North) / 2, Lng: (b.West + b.East) / 2}
+}
+
+// GeoQuery searches properties located inside an area. Results are sorted by
+// distance to Center, nearest first. Properties without coordinates never match.
+type GeoQuery struct {
+	Shape GeoShape
+
+	// Center is the radius center and the reference point for distances.
+	// For bbox and polygon queries it defaults to the middle of the area
+	// unless set with SetCenter.
+	Center       GeoPoint
+	RadiusMeters float64
+	BBox         BoundingBox
+	Polygon      []GeoPoint // Vertices in order; the ring is closed implicitly
+
+	// Filter applies the regular search filters; its sort and pagination are ignored.
+	Filter PropertyQuery
+	Limit  int
+
+	hasCenter bool
+}
+
+// GeoResult is a property matched by a geo query.
+type GeoResult struct {
+	Property       *Property `json:"property"`
+	DistanceMeters float64   `json:"distance_m"`
+}
+
+// SetCenter sets the distance reference point.
+func (q *GeoQuery) SetCenter(p GeoPoint) {
+	q.Center = p
+	q.hasCenter = true
+}
+
+// Normalize fills defaults and validates the query.
+func (q *GeoQuery) Normalize() []ValidationError {
+	var errors []ValidationError
+
+	if q.Limit <= 0 {
+		q.Limit = DefaultGeoLimit
+	}
+	if q.Limit > MaxGeoLimit {
+		q.Limit = MaxGeoLimit
+	}
+
+	switch q.Shape {
+	case GeoRadius:
+		if q.RadiusMeters <= 0 || q.RadiusMeters > MaxGeoRadius {
+			errors = append(errors, ValidationError{Field: "radius", Message: fmt.Sprintf("radius must be between 0 and %d meters", MaxGeoRadius)})
+		}
+		q.hasCenter = true
+
+	case GeoBBox:
+		b := q.BBox
+		if !validPoint(GeoPoint{Lat: b.South, Lng: b.West}) || !validPoint(GeoPoint{Lat: b.North, Lng: b.East}) {
+			errors = append(errors, ValidationError{Field: "bbox", Message: "bbox coordinates out of range"})
+		} else if b.South > b.North || b.West > b.East {
+			errors = append(errors, ValidationError{Field: "bbox", Message: "bbox must be west,south,east,north and must not cross the antimeridian"})
+		}
+		if !q.hasCenter {
+			q.Center = b.Center()
+		}
+
+	case GeoPolygon:
+		if len(q.Polygon) > 1 && q.Polygon[0] == q.Polygon[len(q.Polygon)-1] {
+			q.Polygon = q.Polygon[:len(q.Polygon)-1]
+		}
+		if len(q.Polygon) < 3 {
+			errors = append(errors, ValidationError{Field: "polygon", Message: "polygon needs at least 3 distinct vertices"})
+		}
+		for _, p := range q.Polygon {
+			if !validPoint(p) {
+				errors = append(errors, ValidationError{Field: "polygon", Message: "polygon coordinates out of range"})
+				break
+			}
+		}
+		if !q.hasCenter && len(q.Polygon) > 0 {
+			q.Center = q.Bounds().Center()
+		}
+
+	default:
+		errors = append(errors, ValidationError{Field: "shape", Message: fmt.Sprintf("unknown geo shape %q", q.Shape)})
+	}
+
+	if !validPoint(q.Center) {
+		errors = append(errors, ValidationError{Field: "center", Message: "lat must be between -90 and 90 and lng between -180 and 180"})
+	}
+
+	return errors
+}
+
+// Bounds returns a bounding box enclosing the searched area.
+func (q *GeoQuery) Bounds() BoundingBox {
+	switch q.Shape {
+	case GeoRadius:
+		dLat := q.RadiusMeters / earthRadiusMeters * 180 / math.Pi
+		dLng := 180.0
+		if cos := math.Cos(q.Center.Lat * math.Pi / 180); cos > 1e-9 {
+			dLng = math.Min(dLat/cos, 180)
+		}
+		return BoundingBox{
+			South: math.Max(q.Center.Lat-dLat, -90),
+			North: math.Min(q.Center.Lat+dLat, 90),
+			West:  math.Max(q.Center.Lng-dLng, -180),
+			East:  math.Min(q.Center.Lng+dLng, 180),
+		}
+
+	case GeoPolygon:
+		b := BoundingBox{South: 90, North: -90, West: 180, East: -180}
+		for _, p := range q.Polygon {
+			b.South = math.Min(b.South, p.Lat)
+			b.North = math.Max(b.North, p.Lat)
+			b.West = math.Min(b.West, p.Lng)
+			b.East = math.Max(b.East, p.Lng)
+		}
+		return b
+
+	default:
+		return q.BBox
+	}
+}
+
+// Matches returns true if the point lies inside the searched area.
+func (q *GeoQuery) Matches(p GeoPoint) bool {
+	switch q.Shape {
+	case GeoRadius:
+		return DistanceMeters(q.Center, p) <= q.RadiusMeters
+	case GeoBBox:
+		return q.BBox.Contains(p)
+	case GeoPolygon:
+		return PolygonContains(q.Polygon, p)
+	default:
+		return false
+	}
+}
+
+// PointOf returns the position of a property, or false if it has no coordinates.
+func PointOf(p *Property) (GeoPoint, bool) {
+	c := p.Location.Coordinates
+	if c.IsZero() {
+		return GeoPoint{}, false
+	}
+	return GeoPoint{Lat: c.Latitude, Lng: c.Longitude}, true
+}
+
+// DistanceMeters returns the great-circle distance between two points (haversine).
+func DistanceMeters(a, b GeoPoint) float64 {
+	lat1 := a.Lat * math.Pi / 180
+	lat2 := b.Lat * math.Pi / 180
+	dLat := (b.Lat - a.Lat) * math.Pi / 180
+	dLng := (b.Lng - a.Lng) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusMeters * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// PolygonContains reports whether p lies inside the polygon using ray casting
+// on planar lat/lng, which is accurate enough for neighbourhood-sized shapes.
+func PolygonContains(polygon []GeoPoint, p GeoPoint) bool {
+	inside := false
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		a, b := polygon[i], polygon[j]
+		if (a.Lat > p.Lat) != (b.Lat > p.Lat) &&
+			p.Lng < (b.Lng-a.Lng)*(p.Lat-a.Lat)/(b.Lat-a.Lat)+a.Lng {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+func validPoint(p GeoPoint) bool {
+	return p.Lat >= -90 && p.Lat <= 90 && p.Lng >= -180 && p.Lng <= 180
+}
@@ -0,0 +1,183 @@
+package estate
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDistanceMeters(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b GeoPoint
+		want float64
+	}{
+		{"same point", GeoPoint{40.4169, -3.7035}, GeoPoint{40.4169, -3.7035}, 0},
+		{"Madrid to Barcelona", GeoPoint{40.4169, -3.7035}, GeoPoint{41.3874, 2.1686}, 505_000},
+		{"one degree of latitude", GeoPoint{0, 0}, GeoPoint{1, 0}, 111_195},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DistanceMeters(tt.a, tt.b)
+			if math.Abs(got-tt.want) > tt.want*0.01+1 {
+				t.Errorf("expected ~%.0f m, got %.0f m", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestPolygonContains(t *testing.T) {
+	// An L-shaped polygon: the square 0..2 x 0..2 without the top-right quarter.
+	polygon := []GeoPoint{{0, 0}, {0, 2}, {1, 2}, {1, 1}, {2, 1}, {2, 0}}
+
+	tests := []struct {
+		point GeoPoint
+		want  bool
+	}{
+		{GeoPoint{0.5, 0.5}, true},
+		{GeoPoint{0.5, 1.5}, true},
+		{GeoPoint{1.5, 0.5}, true},
+		{GeoPoint{1.5, 1.5}, false},
+		{GeoPoint{3, 3}, false},
+		{GeoPoint{-0.5, 0.5}, false},
+	}
+
+	for _, tt := range tests {
+		if got := PolygonContains(polygon, tt.point); got != tt.want {
+			t.Errorf("PolygonContains(%v) = %v, want %v", tt.point, got, tt.want)
+		}
+	}
+}
+
+func TestGeoQueryNormalize(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      GeoQuery
+		wantErr    string
+		wantCenter *GeoPoint
+	}{
+		{name: "radius", query: GeoQuery{Shape: GeoRadius, Center: GeoPoint{40, -3}, RadiusMeters: 2000}},
+		{name: "radius too large", query: GeoQuery{Shape: GeoRadius, RadiusMeters: MaxGeoRadius + 1}, wantErr: "radius"},
+		{name: "radius missing", query: GeoQuery{Shape: GeoRadius}, wantErr: "radius"},
+		{
+			name:       "bbox defaults center",
+			query:      GeoQuery{Shape: GeoBBox, BBox: BoundingBox{South: 40, West: -4, North: 41, East: -3}},
+			wantCenter: &GeoPoint{40.5, -3.5},
+		},
+		{name: "bbox inverted", query: GeoQuery{Shape: GeoBBox, BBox: BoundingBox{South: 41, West: -4, North: 40, East: -3}}, wantErr: "bbox"},
+		{name: "bbox out of range", query: GeoQuery{Shape: GeoBBox, BBox: BoundingBox{South: 40, West: -200, North: 41, East: -3}}, wantErr: "bbox"},
+		{
+			name:       "closed polygon",
+			query:      GeoQuery{Shape: GeoPolygon, Polygon: []GeoPoint{{0, 0}, {0, 2}, {2, 2}, {0, 0}}},
+			wantCenter: &GeoPoint{1, 1},
+		},
+		{name: "degenerate polygon", query: GeoQuery{Shape: GeoPolygon, Polygon: []GeoPoint{{0, 0}, {0, 2}, {0, 0}}}, wantErr: "polygon"},
+		{name: "unknown shape", query: GeoQuery{Shape: "circle"}, wantErr: "shape"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := tt.query
+			errs := q.Normalize()
+
+			if tt.wantErr != "" {
+				if len(errs) == 0 || errs[0].Field != tt.wantErr {
+					t.Fatalf("expected error on %q, got %v", tt.wantErr, errs)
+				}
+				return
+			}
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+			if q.Limit != DefaultGeoLimit {
+				t.Errorf("expected default limit, got %d", q.Limit)
+			}
+			if tt.wantCenter != nil && q.Center != *tt.wantCenter {
+				t.Errorf("expected center %v, got %v", *tt.wantCenter, q.Center)
+			}
+		})
+	}
+}
+
+func TestGeoQueryBoundsContainsRadius(t *testing.T) {
+	q := GeoQuery{Shape: GeoRadius, Center: GeoPoint{60, 10}, RadiusMeters: 5000}
+	b := q.Bounds()
+
+	for _, bearing := range []GeoPoint{{b.North, 10}, {b.South, 10}, {60, b.East}, {60, b.West}} {
+		if d := DistanceMeters(q.Center, bearing); d < 5000*0.99 {
+			t.Errorf("bounds edge %v is only %.0f m away, box is too small", bearing, d)
+		}
+	}
+}
+
+func TestParseGeoJSONPolygon(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    int
+		wantErr bool
+	}{
+		{name: "geometry", body: `{"type":"Polygon","coordinates":[[[-3.72,40.41],[-3.72,40.42],[-3.70,40.42],[-3.72,40.41]]]}`, want: 4},
+		{name: "feature", body: `{"type":"Feature","geometry":{"type":"Polygon","coordinates":[[[0,0],[0,1],[1,1],[0,0]]]},"properties":{}}`, want: 4},
+		{name: "point", body: `{"type":"Point","coordinates":[0,0]}`, wantErr: true},
+		{name: "holes", body: `{"type":"Polygon","coordinates":[[[0,0],[0,3],[3,3],[0,0]],[[1,1],[1,2],[2,2],[1,1]]]}`, wantErr: true},
+		{name: "invalid json", body: `{`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ring, err := ParseGeoJSONPolygon([]byte(tt.body))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(ring) != tt.want {
+				t.Fatalf("expected %d vertices, got %d", tt.want, len(ring))
+			}
+			if tt.name == "geometry" && ring[0] != (GeoPoint{Lat: 40.41, Lng: -3.72}) {
+				t.Errorf("expected [lng, lat] order to be honoured, got %v", ring[0])
+			}
+		})
+	}
+}
+
+func TestParseBBox(t *testing.T) {
+	b, err := parseBBox("-3.72, 40.41, -3.68, 40.42")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if b != (BoundingBox{West: -3.72, South: 40.41, East: -3.68, North: 40.42}) {
+		t.Errorf("unexpected bbox %+v", b)
+	}
+
+	for _, s := range []string{"", "1,2,3", "a,b,c,d"} {
+		if _, err := parseBBox(s); err == nil {
+			t.Errorf("expected error for %q", s)
+		}
+	}
+}
+
+func TestNewFeatureCollection(t *testing.T) {
+	p := New()
+	p.EnsureID()
+	p.Name = "Sol"
+	p.Location.Coordinates = Coordinates{Latitude: 40.4169, Longitude: -3.7035}
+
+	fc := NewFeatureCollection([]GeoResult{{Property: p, DistanceMeters: 12.5}})
+
+	if fc.Type != "FeatureCollection" || len(fc.Features) != 1 {
+		t.Fatalf("unexpected collection %+v", fc)
+	}
+	f := fc.Features[0]
+	if f.Geometry.Coordinates != [2]float64{-3.7035, 40.4169} {
+		t.Errorf("expected [lng, lat] coordinates, got %v", f.Geometry.Coordinates)
+	}
+	if f.ID != p.ID.String() || f.Properties["distance_m"] != 12.5 {
+		t.Errorf("unexpected feature %+v", f)
+	}
+}
@@ -0,0 +1,196 @@
+package estate
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pulap/pulap/pkg/lib/core"
+)
+
+// GeoMeta describes a geo search response.
+type GeoMeta struct {
+	Shape  GeoShape `json:"shape"`
+	Center GeoPoint `json:"center"`
+	Count  int      `json:"count"`
+	Limit  int      `json:"limit"`
+}
+
+// SearchRadius handles GET /estates/geo/radius?lat=..&lng=..&radius=meters
+func (h *Handler) SearchRadius(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "Handler.SearchRadius")
+	defer finish()
+
+	values := r.URL.Query()
+	p := queryParser{values: values}
+
+	query := GeoQuery{Shape: GeoRadius}
+	if center, ok := p.point(); ok {
+		query.SetCenter(center)
+	} else {
+		p.errors = append(p.errors, ValidationError{Field: "lat", Message: "lat and lng are required"})
+	}
+	if radius := p.float("radius"); radius != nil {
+		query.RadiusMeters = *radius
+	}
+
+	h.searchGeo(w, r, query, p.errors)
+}
+
+// SearchBBox handles GET /estates/geo/bbox?bbox=west,south,east,north
+// An optional lat/lng sets the point distances are measured from.
+func (h *Handler) SearchBBox(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "Handler.SearchBBox")
+	defer finish()
+
+	values := r.URL.Query()
+	p := queryParser{values: values}
+
+	query := GeoQuery{Shape: GeoBBox}
+	if center, ok := p.point(); ok {
+		query.SetCenter(center)
+	}
+	if bbox, err := parseBBox(values.Get("bbox")); err != nil {
+		p.errors = append(p.errors, ValidationError{Field: "bbox", Message: err.Error()})
+	} else {
+		query.BBox = bbox
+	}
+
+	h.searchGeo(w, r, query, p.errors)
+}
+
+// SearchPolygon handles POST /estates/geo/polygon with a GeoJSON Polygon (or Feature) body.
+// An optional lat/lng query parameter sets the point distances are measured from.
+func (h *Handler) SearchPolygon(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "Handler.SearchPolygon")
+	defer finish()
+	log := h.log(r)
+
+	r.Body = http.MaxBytesReader(w, r.Body, MaxBodyBytes)
+	defer r.Body.Close()
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		log.Debug("error reading request body", "error", err)
+		core.RespondError(w, http.StatusBadRequest, "Could not read request body")
+		return
+	}
+
+	values := r.URL.Query()
+	p := queryParser{values: values}
+
+	query := GeoQuery{Shape: GeoPolygon}
+	if center, ok := p.point(); ok {
+		query.SetCenter(center)
+	}
+	if polygon, err := ParseGeoJSONPolygon(body); err != nil {
+		p.errors = append(p.errors, ValidationError{Field: "polygon", Message: err.Error()})
+	} else {
+		query.Polygon = polygon
+	}
+
+	h.searchGeo(w, r, query, p.errors)
+}
+
+// searchGeo applies the shared filters, restricted to the properties the
+// user may read, runs the query and writes either the standard envelope or
+// a GeoJSON FeatureCollection (format=geojson or Accept: application/geo+json).
+func (h *Handler) searchGeo(w http.ResponseWriter, r *http.Request, query GeoQuery, errs []ValidationError) {
+	log := h.log(r)
+	ctx := r.Context()
+
+	values := r.URL.Query()
+	if limit := values.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil {
+			errs = append(errs, ValidationError{Field: "limit", Message: "must be an integer"})
+		}
+		query.Limit = n
+	}
+
+	filters := url.Values{}
+	for key, v := range values {
+		switch key {
+		case "lat", "lng", "radius", "bbox", "limit", "format", "sort", "cursor":
+		default:
+			filters[key] = v
+		}
+	}
+	filter, filterErrs := ParsePropertyQuery(filters)
+	query.Filter = filter
+	errs = append(errs, filterErrs...)
+
+	if len(errs) == 0 {
+		errs = query.Normalize()
+	}
+	if len(errs) > 0 {
+		log.Debug("invalid geo query", "errors", errs)
+		core.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid query: %s", errs[0].Message))
+		return
+	}
+
+	access, ok := h.access(w, r, PermissionRead)
+	if !ok {
+		return
+	}
+	access.Restrict(&query.Filter)
+
+	results, err := h.repo.SearchGeo(ctx, query)
+	if err != nil {
+		log.Error("error searching properties by location", "error", err)
+		core.RespondError(w, http.StatusInternalServerError, "Could not retrieve properties")
+		return
+	}
+
+	if wantsGeoJSON(r) {
+		w.Header().Set("Content-Type", GeoJSONContentType)
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(NewFeatureCollection(results))
+		return
+	}
+
+	if results == nil {
+		results = []GeoResult{}
+	}
+	meta := GeoMeta{Shape: query.Shape, Center: query.Center, Count: len(results), Limit: query.Limit}
+	core.RespondSuccessWithMeta(w, results, meta, core.CollectionLinksFor("estate")...)
+}
+
+func wantsGeoJSON(r *http.Request) bool {
+	if strings.EqualFold(r.URL.Query().Get("format"), "geojson") {
+		return true
+	}
+	return strings.Contains(r.Header.Get("Accept"), GeoJSONContentType)
+}
+
+// parseBBox parses "west,south,east,north" (GeoJSON bbox order).
+func parseBBox(s string) (BoundingBox, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 {
+		return BoundingBox{}, fmt.Errorf("bbox must be west,south,east,north")
+	}
+
+	var v [4]float64
+	for i, part := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return BoundingBox{}, fmt.Errorf("bbox must contain numbers")
+		}
+		v[i] = f
+	}
+
+	return BoundingBox{West: v[0], South: v[1], East: v[2], North: v[3]}, nil
+}
+
+// point reads the optional lat/lng pair.
+func (p *queryParser) point() (GeoPoint, bool) {
+	lat, lng := p.float("lat"), p.float("lng")
+	if lat == nil || lng == nil {
+		return GeoPoint{}, false
+	}
+	return GeoPoint{Lat: *lat, Lng: *lng}, true
+}
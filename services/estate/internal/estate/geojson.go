@@ -0,0 +1,102 @@
+package estate
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// GeoJSON media type (RFC 7946).
+const GeoJSONContentType = "application/geo+json"
+
+// FeatureCollection is a GeoJSON FeatureCollection.
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+// Feature is a GeoJSON Feature with a Point geometry.
+type Feature struct {
+	Type       string         `json:"type"`
+	ID         string         `json:"id"`
+	Geometry   PointGeometry  `json:"geometry"`
+	Properties map[string]any `json:"properties"`
+}
+
+// PointGeometry is a GeoJSON Point; coordinates are [longitude, latitude].
+type PointGeometry struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+// NewFeatureCollection converts geo results to a GeoJSON FeatureCollection,
+// keeping their order.
+func NewFeatureCollection(results []GeoResult) FeatureCollection {
+	fc := FeatureCollection{Type: "FeatureCollection", Features: make([]Feature, 0, len(results))}
+
+	for _, r := range results {
+		p := r.Property
+		c := p.Location.Coordinates
+		fc.Features = append(fc.Features, Feature{
+			Type: "Feature",
+			ID:   p.ID.String(),
+			Geometry: PointGeometry{
+				Type:        "Point",
+				Coordinates: [2]float64{c.Longitude, c.Latitude},
+			},
+			Properties: map[string]any{
+				"name":         p.Name,
+				"status":       p.Status,
+				"display_name": p.Location.DisplayName,
+				"city":         p.Location.Address.City,
+				"country":      p.Location.Address.Country,
+				"bedrooms":     p.Features.Bedrooms,
+				"bathrooms":    p.Features.Bathrooms,
+				"total_area":   p.Features.TotalArea,
+				"prices":       p.Prices,
+				"distance_m":   r.DistanceMeters,
+			},
+		})
+	}
+
+	return fc
+}
+
+// ParseGeoJSONPolygon reads the outer ring of a GeoJSON Polygon. The payload
+// may be a bare geometry or a Feature wrapping one; holes are not supported.
+func ParseGeoJSONPolygon(data []byte) ([]GeoPoint, error) {
+	var obj struct {
+		Type        string           `json:"type"`
+		Coordinates [][][]float64    `json:"coordinates"`
+		Geometry    *json.RawMessage `json:"geometry"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, fmt.Errorf("invalid GeoJSON: %w", err)
+	}
+
+	if obj.Type == "Feature" {
+		if obj.Geometry == nil {
+			return nil, fmt.Errorf("feature has no geometry")
+		}
+		return ParseGeoJSONPolygon(*obj.Geometry)
+	}
+
+	if obj.Type != "Polygon" {
+		return nil, fmt.Errorf("expected a Polygon geometry, got %q", obj.Type)
+	}
+	if len(obj.Coordinates) == 0 {
+		return nil, fmt.Errorf("polygon has no coordinates")
+	}
+	if len(obj.Coordinates) > 1 {
+		return nil, fmt.Errorf("polygons with holes are not supported")
+	}
+
+	ring := make([]GeoPoint, 0, len(obj.Coordinates[0]))
+	for _, position := range obj.Coordinates[0] {
+		if len(position) < 2 {
+			return nil, fmt.Errorf("invalid polygon position")
+		}
+		ring = append(ring, GeoPoint{Lat: position[1], Lng: position[0]})
+	}
+
+	return ring, nil
+}
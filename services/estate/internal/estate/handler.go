@@ -2,8 +2,10 @@ package estate
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
@@ -12,24 +14,43 @@ import (
 	"github.com/pulap/pulap/pkg/lib/core"
 	"github.com/pulap/pulap/pkg/lib/telemetry"
 	"github.com/pulap/pulap/services/estate/internal/config"
+	"github.com/pulap/pulap/services/estate/internal/patch"
 )
 
 const MaxBodyBytes = 1 << 20 // 1 MB
 
 // Handler handles HTTP requests for the Property aggregate.
 type Handler struct {
-	repo       Repo
-	dictClient Client
-	xparams    config.XParams
-	tlm        *telemetry.HTTP
+	*PropertyAccess
+	dictClient   Client
+	searcher     TextSearcher
+	trash        *Trash
+	duplicates   *DuplicateDetector
+	developments *Developments
+	tlm          *telemetry.HTTP
+}
+
+// HandlerDeps are the dependencies of a Handler. Access and Dictionary are
+// required; any other left nil disables the endpoints and checks that use
+// it.
+type HandlerDeps struct {
+	Access       *PropertyAccess
+	Dictionary   Client
+	Searcher     TextSearcher
+	Trash        *Trash // Without it deleted properties are kept until restored
+	Duplicates   *DuplicateDetector
+	Developments *Developments // Without it properties cannot join developments
 }
 
 // NewHandler creates a new Handler for Property operations.
-func NewHandler(repo Repo, dictClient Client, xparams config.XParams) *Handler {
+func NewHandler(deps HandlerDeps, xparams config.XParams) *Handler {
 	return &Handler{
-		repo:       repo,
-		dictClient: dictClient,
-		xparams:    xparams,
+		PropertyAccess: deps.Access,
+		dictClient:     deps.Dictionary,
+		searcher:       deps.Searcher,
+		trash:          deps.Trash,
+		duplicates:     deps.Duplicates,
+		developments:   deps.Developments,
 		tlm: telemetry.NewHTTP(
 			telemetry.WithTracer(xparams.Tracer()),
 			telemetry.WithMetrics(xparams.Metrics()),
@@ -37,32 +58,76 @@ func NewHandler(repo Repo, dictClient Client, xparams config.XParams) *Handler {
 	}
 }
 
-// RegisterRoutes registers all routes for the estate service.
+// RegisterRoutes registers the property routes. The records that belong to
+// properties, e.g. their media, listings or leases, are routed by handlers
+// of their own under /estates/{id}.
 func (h *Handler) RegisterRoutes(r chi.Router) {
 	r.Route("/estates", func(r chi.Router) {
+		r.Use(h.authn())
 		r.Post("/", h.CreateProperty)
 		r.Get("/", h.ListProperties)
+		r.Get("/search", h.SearchText)
+		r.Get("/export", h.ExportProperties)
+		r.Get("/geo/radius", h.SearchRadius)
+		r.Get("/geo/bbox", h.SearchBBox)
+		r.Post("/geo/polygon", h.SearchPolygon)
+		r.Get("/trash", h.ListTrash)
+		r.Get("/duplicates", h.ListDuplicates)
 		r.Get("/{id}", h.GetProperty)
 		r.Put("/{id}", h.UpdateProperty)
+		r.Patch("/{id}", h.PatchProperty)
 		r.Delete("/{id}", h.DeleteProperty)
+		r.Post("/{id}/restore", h.RestoreProperty)
+		r.Post("/{id}/transitions", h.TransitionProperty)
+		r.Get("/{id}/status-history", h.GetStatusHistory)
+		r.Get("/{id}/revisions", h.ListRevisions)
+		r.Get("/{id}/revisions/{n}", h.GetRevision)
+		r.Post("/{id}/revisions/{n}/restore", h.RestoreRevision)
 	})
 }
 
 // CreateProperty handles POST /estates
+// A property without owner or team is owned by the authenticated user, who
+// needs PermissionWrite in its estate scope. A property matching existing
+// ones is answered with 409 and the likely duplicates, a
+// DuplicateConflictResponse, unless ?allow_duplicate=true.
 func (h *Handler) CreateProperty(w http.ResponseWriter, r *http.Request) {
 	w, r, finish := h.tlm.Start(w, r, "Handler.CreateProperty")
 	defer finish()
 	log := h.log(r)
 	ctx := r.Context()
 
+	allowDuplicate, ok := parseAllowDuplicates(w, r, "allow_duplicate")
+	if !ok {
+		return
+	}
+
 	property, ok := h.decodePropertyPayload(w, r, log)
 	if !ok {
 		return
 	}
 
+	if property.OwnerID == "" && property.TeamID == "" {
+		property.OwnerID = requestActor(r)
+	}
+	access, ok := h.access(w, r, PermissionWrite)
+	if !ok {
+		return
+	}
+	if !access.Allows(property) {
+		log.Info("property create denied", "owner_id", property.OwnerID, "team_id", property.TeamID)
+		core.RespondError(w, http.StatusForbidden, fmt.Sprintf("Permission %s is required", PermissionWrite))
+		return
+	}
+
 	property.EnsureID()
 	property.BeforeCreate()
 
+	// Units inherit what they leave empty from their development
+	if !h.inheritDevelopment(w, r, property) {
+		return
+	}
+
 	// Basic validation
 	if validationErrors := ValidateCreateProperty(ctx, property); len(validationErrors) > 0 {
 		log.Debug("validation failed", "errors", validationErrors)
@@ -81,7 +146,12 @@ func (h *Handler) CreateProperty(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !allowDuplicate && !h.checkDuplicates(w, r, property) {
+		return
+	}
+
 	// Create in repository
+	ctx = WithActor(ctx, requestActor(r))
 	if err := h.repo.Create(ctx, property); err != nil {
 		log.Error("cannot create property", "error", err)
 		core.RespondError(w, http.StatusInternalServerError, "Could not create property")
@@ -89,6 +159,7 @@ func (h *Handler) CreateProperty(w http.ResponseWriter, r *http.Request) {
 	}
 
 	links := core.RESTfulLinksFor(property)
+	w.Header().Set("ETag", ETag(property.Revision))
 	w.WriteHeader(http.StatusCreated)
 	core.RespondSuccess(w, property, links...)
 }
@@ -116,48 +187,74 @@ func (h *Handler) GetProperty(w http.ResponseWriter, r *http.Request) {
 		core.RespondError(w, http.StatusNotFound, "Property not found")
 		return
 	}
+	if !h.authorizeProperty(w, r, property, PermissionRead) {
+		return
+	}
 
 	links := core.RESTfulLinksFor(property)
+	w.Header().Set("ETag", ETag(property.Revision))
+	w.Header().Set("Accept-Patch", acceptPatch)
 	core.RespondSuccess(w, property, links...)
 }
 
 // ListProperties handles GET /estates
+// See ParsePropertyQuery for the supported filter, sort and pagination parameters.
+// Only the properties the user may read are listed.
 func (h *Handler) ListProperties(w http.ResponseWriter, r *http.Request) {
 	w, r, finish := h.tlm.Start(w, r, "Handler.ListProperties")
 	defer finish()
 	log := h.log(r)
 	ctx := r.Context()
 
-	// Check for query parameters
-	ownerID := r.URL.Query().Get("owner_id")
-	status := r.URL.Query().Get("status")
-
-	var properties []*Property
-	var err error
+	query, validationErrors := ParsePropertyQuery(r.URL.Query())
+	if len(validationErrors) > 0 {
+		log.Debug("invalid search query", "errors", validationErrors)
+		core.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid query: %s", validationErrors[0].Message))
+		return
+	}
 
-	if ownerID != "" {
-		properties, err = h.repo.ListByOwner(ctx, ownerID)
-	} else if status != "" {
-		properties, err = h.repo.ListByStatus(ctx, status)
-	} else {
-		properties, err = h.repo.List(ctx)
+	access, ok := h.access(w, r, PermissionRead)
+	if !ok {
+		return
 	}
+	access.Restrict(&query)
 
+	page, err := h.repo.Search(ctx, query)
 	if err != nil {
-		log.Error("error retrieving properties", "error", err)
+		log.Error("error searching properties", "error", err)
 		core.RespondError(w, http.StatusInternalServerError, "Could not retrieve properties")
 		return
 	}
 
-	core.RespondCollection(w, properties, "estate")
+	items := page.Items
+	if items == nil {
+		items = []*Property{}
+	}
+
+	meta := PageMeta{Total: page.Total, Limit: query.Limit, NextCursor: page.NextCursor}
+	links := core.CollectionLinksFor("estate")
+	if page.NextCursor != "" {
+		next := r.URL.Query()
+		next.Set("cursor", page.NextCursor)
+		links = append(links, core.Link{Rel: core.RelNext, Href: r.URL.Path + "?" + next.Encode()})
+	}
+
+	core.RespondSuccessWithMeta(w, items, meta, links...)
+}
+
+// PageMeta is the pagination metadata returned with search results.
+type PageMeta struct {
+	Total      int64  `json:"total"`
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 // UpdateProperty handles PUT /estates/{id}
+// An If-Match header makes the update conditional on the property revision.
 func (h *Handler) UpdateProperty(w http.ResponseWriter, r *http.Request) {
 	w, r, finish := h.tlm.Start(w, r, "Handler.UpdateProperty")
 	defer finish()
 	log := h.log(r)
-	ctx := r.Context()
 
 	id, ok := h.parseIDParam(w, r, log)
 	if !ok {
@@ -169,8 +266,152 @@ func (h *Handler) UpdateProperty(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	current, ok := h.loadForUpdate(w, r, id)
+	if !ok {
+		return
+	}
+
+	h.saveUpdate(w, r, property, current)
+}
+
+// PatchProperty handles PATCH /estates/{id}
+// The body is a JSON Merge Patch (application/merge-patch+json) or a JSON
+// Patch (application/json-patch+json) applied to the current property; JSON
+// Patch allows editing single elements of prices and features.amenities.
+// An If-Match header makes the update conditional on the property revision.
+func (h *Handler) PatchProperty(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "Handler.PatchProperty")
+	defer finish()
+	log := h.log(r)
+
+	id, ok := h.parseIDParam(w, r, log)
+	if !ok {
+		return
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	var apply func(doc, patch []byte) ([]byte, error)
+	switch mediaType {
+	case patch.MergePatchType:
+		apply = patch.Merge
+	case patch.JSONPatchType:
+		apply = patch.Apply
+	default:
+		w.Header().Set("Accept-Patch", acceptPatch)
+		core.RespondError(w, http.StatusUnsupportedMediaType, "Content-Type must be one of: "+acceptPatch)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, MaxBodyBytes)
+	defer r.Body.Close()
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		log.Debug("error reading request body", "error", err)
+		core.RespondError(w, http.StatusBadRequest, "Could not read request body")
+		return
+	}
+
+	current, ok := h.loadForUpdate(w, r, id)
+	if !ok {
+		return
+	}
+
+	doc, err := json.Marshal(current)
+	if err != nil {
+		log.Error("cannot encode property", "error", err, "id", id.String())
+		core.RespondError(w, http.StatusInternalServerError, "Could not patch property")
+		return
+	}
+
+	patched, err := apply(doc, body)
+	if err != nil {
+		log.Debug("cannot apply patch", "error", err, "id", id.String())
+		status := http.StatusBadRequest
+		if errors.Is(err, patch.ErrConflict) {
+			status = http.StatusConflict
+		}
+		core.RespondError(w, status, capitalize(err.Error()))
+		return
+	}
+
+	var property Property
+	if err := json.Unmarshal(patched, &property); err != nil {
+		log.Debug("patched property is invalid", "error", err, "id", id.String())
+		core.RespondError(w, http.StatusBadRequest, "Patched property is not a valid property")
+		return
+	}
+
+	h.saveUpdate(w, r, &property, current)
+}
+
+// acceptPatch lists the media types accepted by PATCH /estates/{id}.
+const acceptPatch = patch.MergePatchType + ", " + patch.JSONPatchType
+
+// loadForUpdate loads the property to update, checks the user may write it
+// and checks the If-Match precondition, responding with the error when it
+// returns false.
+func (h *Handler) loadForUpdate(w http.ResponseWriter, r *http.Request, id uuid.UUID) (*Property, bool) {
+	current, err := h.repo.Get(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			core.RespondError(w, http.StatusNotFound, "Property not found")
+			return nil, false
+		}
+		h.log(r).Error("error loading property", "error", err, "id", id.String())
+		core.RespondError(w, http.StatusInternalServerError, "Could not retrieve property")
+		return nil, false
+	}
+
+	if !h.authorizeProperty(w, r, current, PermissionWrite) {
+		return nil, false
+	}
+
+	if !h.checkIfMatch(w, r, current) {
+		return nil, false
+	}
+	return current, true
+}
+
+// saveUpdate validates the new version of the current property and saves it
+// at the current revision.
+func (h *Handler) saveUpdate(w http.ResponseWriter, r *http.Request, property, current *Property) {
+	log := h.log(r)
+	ctx := r.Context()
+	id := current.ID
+
 	property.SetID(id)
 	property.BeforeUpdate()
+	property.Revision = current.Revision
+	property.CreatedAt, property.CreatedBy = current.CreatedAt, current.CreatedBy
+	property.DeletedAt, property.DeletedBy = current.DeletedAt, current.DeletedBy
+
+	// Status changes go through the lifecycle (POST /estates/{id}/transitions)
+	if property.Status == "" {
+		property.Status = current.Status
+	}
+	if property.Status != current.Status {
+		log.Debug("status change rejected", "id", id.String(), "from", current.Status, "to", property.Status)
+		core.RespondError(w, http.StatusConflict, fmt.Sprintf("Status changes must use POST /estates/%s/transitions", id))
+		return
+	}
+
+	// The user must keep write access after reassigning the property
+	if property.OwnerID != current.OwnerID || property.TeamID != current.TeamID {
+		access, ok := h.access(w, r, PermissionWrite)
+		if !ok {
+			return
+		}
+		if !access.Allows(property) {
+			log.Info("property reassignment denied", "id", id.String(), "owner_id", property.OwnerID, "team_id", property.TeamID)
+			core.RespondError(w, http.StatusForbidden, fmt.Sprintf("Permission %s is required on the new owner or team", PermissionWrite))
+			return
+		}
+	}
+
+	if !h.inheritDevelopment(w, r, property) {
+		return
+	}
 
 	// Basic validation
 	if validationErrors := ValidateUpdateProperty(ctx, id, property); len(validationErrors) > 0 {
@@ -191,17 +432,29 @@ func (h *Handler) UpdateProperty(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Update in repository
+	ctx = WithActor(ctx, requestActor(r))
 	if err := h.repo.Save(ctx, property); err != nil {
-		log.Error("cannot update property", "error", err)
-		core.RespondError(w, http.StatusInternalServerError, "Could not update property")
+		switch {
+		case errors.Is(err, ErrNotFound):
+			core.RespondError(w, http.StatusNotFound, "Property not found")
+		case errors.Is(err, ErrRevisionConflict):
+			h.respondRevisionConflict(w, r)
+		default:
+			log.Error("cannot update property", "error", err)
+			core.RespondError(w, http.StatusInternalServerError, "Could not update property")
+		}
 		return
 	}
 
 	links := core.RESTfulLinksFor(property)
+	w.Header().Set("ETag", ETag(property.Revision))
 	core.RespondSuccess(w, property, links...)
 }
 
 // DeleteProperty handles DELETE /estates/{id}
+// The property is moved to the trash, from where it can be restored until it
+// is purged, media included. Requires PermissionDelete on the property. An
+// If-Match header makes the delete conditional on the property revision.
 func (h *Handler) DeleteProperty(w http.ResponseWriter, r *http.Request) {
 	w, r, finish := h.tlm.Start(w, r, "Handler.DeleteProperty")
 	defer finish()
@@ -219,37 +472,105 @@ func (h *Handler) DeleteProperty(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.repo.Delete(ctx, id); err != nil {
-		log.Error("cannot delete property", "error", err)
-		core.RespondError(w, http.StatusInternalServerError, "Could not delete property")
+	current, err := h.repo.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			core.RespondError(w, http.StatusNotFound, "Property not found")
+			return
+		}
+		log.Error("error loading property", "error", err, "id", id.String())
+		core.RespondError(w, http.StatusInternalServerError, "Could not retrieve property")
+		return
+	}
+	if !h.authorizeProperty(w, r, current, PermissionDelete) {
+		return
+	}
+
+	var revision int64
+	if r.Header.Get("If-Match") != "" {
+		if !h.checkIfMatch(w, r, current) {
+			return
+		}
+		revision = current.Revision
+	}
+
+	ctx = WithActor(ctx, requestActor(r))
+	if err := h.repo.Delete(ctx, id, revision); err != nil {
+		switch {
+		case errors.Is(err, ErrNotFound):
+			core.RespondError(w, http.StatusNotFound, "Property not found")
+		case errors.Is(err, ErrRevisionConflict):
+			h.respondRevisionConflict(w, r)
+		default:
+			log.Error("cannot delete property", "error", err)
+			core.RespondError(w, http.StatusInternalServerError, "Could not delete property")
+		}
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
-// Helper methods
+// checkIfMatch responds 412 and returns false when the request carries an
+// If-Match header that does not match the property revision.
+func (h *Handler) checkIfMatch(w http.ResponseWriter, r *http.Request, property *Property) bool {
+	header := r.Header.Get("If-Match")
+	if header == "" || IfMatch(header, property.Revision) {
+		return true
+	}
 
-func (h *Handler) log(r *http.Request) core.Logger {
-	return h.xparams.Log().With("request_id", r.Context().Value("request_id"))
+	h.log(r).Debug("precondition failed", "id", property.ID.String(), "if_match", header, "revision", property.Revision)
+	w.Header().Set("ETag", ETag(property.Revision))
+	core.RespondError(w, http.StatusPreconditionFailed, "Property was modified, reload and try again")
+	return false
 }
 
-func (h *Handler) parseIDParam(w http.ResponseWriter, r *http.Request, log core.Logger) (uuid.UUID, bool) {
-	idStr := chi.URLParam(r, "id")
-	if idStr == "" {
-		log.Debug("missing id parameter")
-		core.RespondError(w, http.StatusBadRequest, "Missing id parameter")
-		return uuid.Nil, false
+// respondRevisionConflict reports a write that lost a race with another one:
+// 412 when the client sent a precondition, 409 otherwise.
+func (h *Handler) respondRevisionConflict(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("If-Match") != "" {
+		core.RespondError(w, http.StatusPreconditionFailed, "Property was modified, reload and try again")
+		return
 	}
+	core.RespondError(w, http.StatusConflict, "Property was modified concurrently, reload and try again")
+}
 
-	id, err := uuid.Parse(idStr)
-	if err != nil {
-		log.Debug("invalid id parameter", "id", idStr, "error", err)
-		core.RespondError(w, http.StatusBadRequest, "Invalid id parameter")
-		return uuid.Nil, false
+// inheritDevelopment fills the fields a unit leaves empty from its
+// development, responding with the error when it returns false. The
+// development must exist and be readable by the user.
+func (h *Handler) inheritDevelopment(w http.ResponseWriter, r *http.Request, property *Property) bool {
+	if property.DevelopmentID == nil {
+		return true
+	}
+	if h.developments == nil {
+		core.RespondError(w, http.StatusServiceUnavailable, "Developments are not available")
+		return false
+	}
+	log := h.log(r)
+
+	d, err := h.developments.Get(r.Context(), *property.DevelopmentID)
+	if err != nil && !errors.Is(err, ErrDevelopmentNotFound) {
+		log.Error("error loading development", "error", err, "id", property.DevelopmentID.String())
+		core.RespondError(w, http.StatusInternalServerError, "Could not retrieve development")
+		return false
+	}
+	if d != nil {
+		read, ok := h.access(w, r, PermissionRead)
+		if !ok {
+			return false
+		}
+		if !read.AllowsOwner(d.OwnerID, d.TeamID) {
+			d = nil
+		}
+	}
+	if d == nil {
+		log.Debug("unknown development", "development_id", property.DevelopmentID.String())
+		core.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Development %s not found", property.DevelopmentID))
+		return false
 	}
 
-	return id, true
+	d.Inherit(property, nil)
+	return true
 }
 
 func (h *Handler) decodePropertyPayload(w http.ResponseWriter, r *http.Request, log core.Logger) (*Property, bool) {
@@ -0,0 +1,240 @@
+package estate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Revision actions.
+const (
+	ActionCreate     = "create"
+	ActionUpdate     = "update"
+	ActionRestore    = "restore"
+	ActionTransition = "transition"
+	ActionDelete     = "delete"
+	ActionUndelete   = "undelete"
+)
+
+// ErrRevisionNotFound is returned by repositories when a property revision does not exist.
+var ErrRevisionNotFound = errors.New("property revision not found")
+
+// PropertyRevision is an immutable record of a write to a property. Number
+// matches the property revision after the write; for deletes it is one past
+// the last revision and the snapshot holds the deleted state.
+type PropertyRevision struct {
+	PropertyID   uuid.UUID     `json:"property_id"`
+	Number       int64         `json:"number"`
+	Action       string        `json:"action"`
+	Actor        string        `json:"actor"`
+	At           time.Time     `json:"at"`
+	RestoredFrom int64         `json:"restored_from,omitempty"`
+	Changes      []FieldChange `json:"changes"`
+	Snapshot     *Property     `json:"snapshot,omitempty"`
+}
+
+// FieldChange is a field-level difference between two property versions.
+// Path is a JSON Pointer into the property, e.g. /prices/0/amount; From is
+// nil for added fields and To is nil for removed ones.
+type FieldChange struct {
+	Path string `json:"path"`
+	From any    `json:"from"`
+	To   any    `json:"to"`
+}
+
+// untrackedPaths are bookkeeping fields left out of diffs.
+var untrackedPaths = []string{"/revision", "/created_at", "/updated_at", "/updated_by"}
+
+type revisionContextKey struct{}
+
+type revisionContext struct {
+	actor        string
+	restoredFrom int64
+}
+
+// WithActor returns a context whose writes are recorded as made by actor.
+func WithActor(ctx context.Context, actor string) context.Context {
+	rc := revisionContextFrom(ctx)
+	rc.actor = actor
+	return context.WithValue(ctx, revisionContextKey{}, rc)
+}
+
+// WithRestoredFrom returns a context whose saves are recorded as restores of
+// the given revision number.
+func WithRestoredFrom(ctx context.Context, number int64) context.Context {
+	rc := revisionContextFrom(ctx)
+	rc.restoredFrom = number
+	return context.WithValue(ctx, revisionContextKey{}, rc)
+}
+
+// ActorFrom returns the actor set on the context with WithActor, if any.
+func ActorFrom(ctx context.Context) string {
+	return revisionContextFrom(ctx).actor
+}
+
+func revisionContextFrom(ctx context.Context) revisionContext {
+	rc, _ := ctx.Value(revisionContextKey{}).(revisionContext)
+	return rc
+}
+
+// NewRevision builds the revision recording a write. before is nil for
+// creates and after is nil for deletes. The actor comes from the context
+// (see WithActor) or else from the written property.
+func NewRevision(ctx context.Context, action string, before, after *Property, at time.Time) (*PropertyRevision, error) {
+	rc := revisionContextFrom(ctx)
+
+	rev := &PropertyRevision{Action: action, Actor: rc.actor, At: at.UTC(), Snapshot: after}
+	switch {
+	case after != nil:
+		rev.PropertyID = after.ID
+		rev.Number = after.Revision
+		if rev.Actor == "" {
+			rev.Actor = after.UpdatedBy
+		}
+		if action == ActionCreate && rev.Actor == "" {
+			rev.Actor = after.CreatedBy
+		}
+	case before != nil:
+		rev.PropertyID = before.ID
+		rev.Number = before.Revision + 1
+		rev.Snapshot = before
+	default:
+		return nil, fmt.Errorf("revision needs a property")
+	}
+
+	if action == ActionUpdate && rc.restoredFrom > 0 {
+		rev.Action = ActionRestore
+		rev.RestoredFrom = rc.restoredFrom
+	}
+
+	if action != ActionDelete {
+		changes, err := DiffProperties(before, after)
+		if err != nil {
+			return nil, err
+		}
+		rev.Changes = changes
+	}
+	if rev.Changes == nil {
+		rev.Changes = []FieldChange{}
+	}
+
+	return rev, nil
+}
+
+// DiffProperties returns the field-level changes from before to after,
+// sorted by path. Arrays are compared element by element, so changing one
+// price yields a single /prices/N/... change. A nil property counts as the
+// zero Property, so a create lists only the fields that were set.
+func DiffProperties(before, after *Property) ([]FieldChange, error) {
+	if before == nil {
+		before = &Property{}
+	}
+	if after == nil {
+		after = &Property{}
+	}
+
+	from, err := flattenProperty(before)
+	if err != nil {
+		return nil, err
+	}
+	to, err := flattenProperty(after)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []FieldChange
+	for path, value := range to {
+		if old, ok := from[path]; !ok || !reflect.DeepEqual(old, value) {
+			changes = append(changes, FieldChange{Path: path, From: old, To: value})
+		}
+	}
+	for path, old := range from {
+		if _, ok := to[path]; !ok {
+			changes = append(changes, FieldChange{Path: path, From: old})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+// flattenProperty maps the JSON Pointer of every scalar in the property's
+// JSON form to its value.
+func flattenProperty(p *Property) (map[string]any, error) {
+	b, err := json.Marshal(p)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode property: %w", err)
+	}
+	var doc any
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("cannot decode property: %w", err)
+	}
+
+	fields := map[string]any{}
+	flatten("", doc, fields)
+	for _, path := range untrackedPaths {
+		delete(fields, path)
+	}
+	return fields, nil
+}
+
+func flatten(prefix string, value any, fields map[string]any) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			key = strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+			flatten(prefix+"/"+key, item, fields)
+		}
+	case []any:
+		for i, item := range v {
+			flatten(prefix+"/"+strconv.Itoa(i), item, fields)
+		}
+	case nil:
+		// Null and absent are the same to the diff.
+	default:
+		fields[prefix] = v
+	}
+}
+
+// Transitioned returns a copy of the property as Repo.Transition stores it
+// after t: with the new status and the next revision.
+func (p *Property) Transitioned(t *StatusTransition) *Property {
+	after := *p
+	after.Status = t.To
+	after.UpdatedAt = t.At
+	after.UpdatedBy = t.Actor
+	after.Revision++
+	return &after
+}
+
+// Trashed returns a copy of the property as Repo.Delete stores it: in the
+// trash since at, deleted by actor, with the next revision.
+func (p *Property) Trashed(actor string, at time.Time) *Property {
+	after := *p
+	after.DeletedAt = &at
+	after.DeletedBy = actor
+	after.Revision++
+	return &after
+}
+
+// Untrashed returns a copy of the property as Repo.Undelete stores it: out
+// of the trash, updated at at by actor, if known, with the next revision.
+func (p *Property) Untrashed(actor string, at time.Time) *Property {
+	after := *p
+	after.DeletedAt = nil
+	after.DeletedBy = ""
+	after.UpdatedAt = at
+	if actor != "" {
+		after.UpdatedBy = actor
+	}
+	after.Revision++
+	return &after
+}
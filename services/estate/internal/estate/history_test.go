@@ -0,0 +1,117 @@
+package estate
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/services/estate/internal/money"
+)
+
+func TestDiffProperties(t *testing.T) {
+	before := &Property{
+		ID:       uuid.New(),
+		Name:     "Loft",
+		Status:   StatusAvailable,
+		Features: Features{Bedrooms: 2, Amenities: []string{"gym"}},
+		Prices:   []Price{{Amount: money.FromInt(100), Currency: "USD", Type: "sale"}},
+		Revision: 1,
+	}
+	after := *before
+	after.Name = "Loft Soho"
+	after.Features.Amenities = []string{"gym", "pool"}
+	after.Prices = []Price{{Amount: money.FromInt(120), Currency: "USD", Type: "sale"}}
+	after.Revision = 2
+	after.UpdatedAt = time.Now()
+	after.UpdatedBy = "agent-1"
+
+	changes, err := DiffProperties(before, &after)
+	if err != nil {
+		t.Fatalf("DiffProperties: %v", err)
+	}
+
+	want := []FieldChange{
+		{Path: "/features/amenities/1", To: "pool"},
+		{Path: "/name", From: "Loft", To: "Loft Soho"},
+		{Path: "/prices/0/amount", From: float64(100), To: float64(120)},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("got %+v, want %+v", changes, want)
+	}
+}
+
+func TestDiffPropertiesRemovedField(t *testing.T) {
+	before := &Property{Prices: []Price{{Currency: "USD"}, {Currency: "EUR"}}}
+	after := &Property{Prices: []Price{{Currency: "USD"}}}
+
+	changes, err := DiffProperties(before, after)
+	if err != nil {
+		t.Fatalf("DiffProperties: %v", err)
+	}
+
+	paths := map[string]bool{}
+	for _, c := range changes {
+		if c.To != nil {
+			t.Errorf("expected %s to be removed, got %v", c.Path, c.To)
+		}
+		paths[c.Path] = true
+	}
+	if !paths["/prices/1/currency"] || !paths["/prices/1/amount"] {
+		t.Errorf("expected removed second price, got %+v", changes)
+	}
+}
+
+func TestNewRevision(t *testing.T) {
+	at := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	before := &Property{ID: uuid.New(), Name: "Loft", Revision: 3, UpdatedBy: "agent-1"}
+	after := *before
+	after.Name = "Loft Soho"
+	after.Revision = 4
+	after.UpdatedBy = "agent-2"
+
+	tests := []struct {
+		name         string
+		ctx          context.Context
+		action       string
+		before       *Property
+		after        *Property
+		wantAction   string
+		wantNumber   int64
+		wantActor    string
+		wantChanges  int
+		wantRestored int64
+	}{
+		{"create", context.Background(), ActionCreate, nil, before, ActionCreate, 3, "agent-1", 2, 0},
+		{"update", context.Background(), ActionUpdate, before, &after, ActionUpdate, 4, "agent-2", 1, 0},
+		{"context actor", WithActor(context.Background(), "admin"), ActionUpdate, before, &after, ActionUpdate, 4, "admin", 1, 0},
+		{"restore", WithRestoredFrom(context.Background(), 2), ActionUpdate, before, &after, ActionRestore, 4, "agent-2", 1, 2},
+		{"delete", WithActor(context.Background(), "admin"), ActionDelete, before, nil, ActionDelete, 4, "admin", 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rev, err := NewRevision(tt.ctx, tt.action, tt.before, tt.after, at)
+			if err != nil {
+				t.Fatalf("NewRevision: %v", err)
+			}
+			if rev.PropertyID != before.ID || rev.Number != tt.wantNumber || rev.Action != tt.wantAction {
+				t.Errorf("got %s #%d %s, want %s #%d %s", rev.PropertyID, rev.Number, rev.Action, before.ID, tt.wantNumber, tt.wantAction)
+			}
+			if rev.Actor != tt.wantActor {
+				t.Errorf("expected actor %q, got %q", tt.wantActor, rev.Actor)
+			}
+			if len(rev.Changes) != tt.wantChanges {
+				t.Errorf("expected %d changes, got %+v", tt.wantChanges, rev.Changes)
+			}
+			if rev.RestoredFrom != tt.wantRestored {
+				t.Errorf("expected restored from %d, got %d", tt.wantRestored, rev.RestoredFrom)
+			}
+			if rev.Snapshot == nil || !rev.At.Equal(at) {
+				t.Errorf("expected snapshot at %v, got %v at %v", at, rev.Snapshot, rev.At)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package estate
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ICalendarContentType is the media type of iCalendar feeds.
+const ICalendarContentType = "text/calendar; charset=utf-8"
+
+// icalLineOctets is the longest content line RFC 5545 allows, without the
+// line break; longer lines are folded.
+const icalLineOctets = 75
+
+const icalTime = "20060102T150405Z"
+
+// CalendarEvent is a VEVENT of an iCalendar feed.
+type CalendarEvent struct {
+	UID         string
+	Summary     string
+	Location    string
+	Description string
+	Status      string // TENTATIVE, CONFIRMED or CANCELLED
+	Start       time.Time
+	End         time.Time
+	Sequence    int64
+	Modified    time.Time
+}
+
+// AppointmentEvent returns the calendar event of an appointment of a
+// property. Completed and missed appointments stay confirmed, as they took
+// place in the calendar of the agent.
+func AppointmentEvent(a *Appointment, p *Property) CalendarEvent {
+	e := CalendarEvent{
+		UID:      a.ID.String() + "@pulap",
+		Summary:  "Viewing",
+		Start:    a.StartsAt,
+		End:      a.EndsAt,
+		Sequence: a.Revision - 1,
+		Modified: a.UpdatedAt,
+	}
+
+	switch a.Status {
+	case AppointmentScheduled:
+		e.Status = "TENTATIVE"
+	case AppointmentCancelled:
+		e.Status = "CANCELLED"
+	default:
+		e.Status = "CONFIRMED"
+	}
+
+	if p != nil {
+		e.Summary = "Viewing: " + p.Name
+		addr := p.Location.Address
+		var parts []string
+		for _, s := range []string{strings.TrimSpace(addr.Street + " " + addr.Number), strings.TrimSpace(addr.PostalCode + " " + addr.City), addr.Country} {
+			if s != "" {
+				parts = append(parts, s)
+			}
+		}
+		e.Location = strings.Join(parts, ", ")
+	}
+
+	lines := []string{"Client: " + a.Client.Name}
+	if a.Client.Email != "" {
+		lines = append(lines, "Email: "+a.Client.Email)
+	}
+	if a.Client.Phone != "" {
+		lines = append(lines, "Phone: "+a.Client.Phone)
+	}
+	lines = append(lines, "Status: "+a.Status)
+	if a.Notes != "" {
+		lines = append(lines, "", a.Notes)
+	}
+	e.Description = strings.Join(lines, "\n")
+	return e
+}
+
+// WriteICalendar writes the events as an RFC 5545 calendar published under
+// a name, e.g. for calendar apps to subscribe to.
+func WriteICalendar(w io.Writer, name string, events []CalendarEvent, now time.Time) error {
+	bw := bufio.NewWriter(w)
+	line := func(name, value string) {
+		writeICalLine(bw, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//Pulap//Estate//EN")
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	line("X-WR-CALNAME", icalText(name))
+
+	stamp := now.UTC().Format(icalTime)
+	for _, e := range events {
+		line("BEGIN", "VEVENT")
+		line("UID", icalText(e.UID))
+		line("DTSTAMP", stamp)
+		line("DTSTART", e.Start.UTC().Format(icalTime))
+		line("DTEND", e.End.UTC().Format(icalTime))
+		line("SUMMARY", icalText(e.Summary))
+		if e.Location != "" {
+			line("LOCATION", icalText(e.Location))
+		}
+		if e.Description != "" {
+			line("DESCRIPTION", icalText(e.Description))
+		}
+		line("STATUS", e.Status)
+		line("SEQUENCE", fmt.Sprint(e.Sequence))
+		if !e.Modified.IsZero() {
+			line("LAST-MODIFIED", e.Modified.UTC().Format(icalTime))
+		}
+		line("END", "VEVENT")
+	}
+
+	line("END", "VCALENDAR")
+	return bw.Flush()
+}
+
+// icalText escapes a TEXT value.
+var icalText = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`).Replace
+
+// writeICalLine writes a content line ending in CRLF, folded so no line
+// is longer than icalLineOctets without splitting a UTF-8 sequence.
+func writeICalLine(w *bufio.Writer, s string) {
+	limit := icalLineOctets
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		w.WriteString(s[:cut])
+		w.WriteString("\r\n ")
+		s = s[cut:]
+		limit = icalLineOctets - 1 // The folding space counts
+	}
+	w.WriteString(s)
+	w.WriteString("\r\n")
+}
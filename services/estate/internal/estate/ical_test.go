@@ -0,0 +1,91 @@
+package estate
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+func TestWriteICalendar(t *testing.T) {
+	p := &Property{ID: uuid.New(), Name: "Flat, terrace; views"}
+	p.Location.Address = Address{Street: "Calle Mayor", Number: "1", PostalCode: "28013", City: "Madrid", Country: "ES"}
+	a := newTestAppointment(p.ID, "agent-1", 10, 0)
+	a.Status = AppointmentConfirmed
+	a.Revision = 3
+	a.Notes = strings.Repeat("Wants to see the storage room. ", 5)
+
+	var b strings.Builder
+	now := time.Date(2026, 5, 1, 8, 0, 0, 0, time.UTC)
+	if err := WriteICalendar(&b, "Viewings", []CalendarEvent{AppointmentEvent(a, p)}, now); err != nil {
+		t.Fatalf("WriteICalendar: %v", err)
+	}
+	out := b.String()
+
+	if !strings.HasPrefix(out, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n") || !strings.HasSuffix(out, "END:VCALENDAR\r\n") {
+		t.Errorf("unexpected calendar envelope:\n%s", out)
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		if len(line) > icalLineOctets {
+			t.Errorf("line longer than %d octets: %q", icalLineOctets, line)
+		}
+		if strings.Contains(line, "\n") {
+			t.Errorf("bare line feed in %q", line)
+		}
+	}
+
+	unfolded := strings.ReplaceAll(out, "\r\n ", "")
+	for _, want := range []string{
+		"UID:" + a.ID.String() + "@pulap\r\n",
+		"DTSTAMP:20260501T080000Z\r\n",
+		"DTSTART:20260504T100000Z\r\n",
+		"DTEND:20260504T104500Z\r\n",
+		`SUMMARY:Viewing: Flat\, terrace\; views` + "\r\n",
+		`LOCATION:Calle Mayor 1\, 28013 Madrid\, ES` + "\r\n",
+		`DESCRIPTION:Client: Marta Ruiz\nEmail: marta@example.com\nStatus: confirmed\n\nWants to see`,
+		"STATUS:CONFIRMED\r\n",
+		"SEQUENCE:2\r\n",
+	} {
+		if !strings.Contains(unfolded, want) {
+			t.Errorf("expected %q in:\n%s", want, unfolded)
+		}
+	}
+}
+
+func TestAppointmentEventStatus(t *testing.T) {
+	tests := map[string]string{
+		AppointmentScheduled: "TENTATIVE",
+		AppointmentConfirmed: "CONFIRMED",
+		AppointmentCompleted: "CONFIRMED",
+		AppointmentCancelled: "CANCELLED",
+	}
+	for status, want := range tests {
+		a := newTestAppointment(uuid.New(), "agent-1", 10, 0)
+		a.Status = status
+		if got := AppointmentEvent(a, nil); got.Status != want || got.Summary != "Viewing" {
+			t.Errorf("%s: expected a %s viewing, got %q %q", status, want, got.Status, got.Summary)
+		}
+	}
+}
+
+func TestWriteICalLineFolding(t *testing.T) {
+	var b strings.Builder
+	// Two-octet runes straddle the fold
+	line := "DESCRIPTION:" + strings.Repeat("ñ", 100)
+	if err := WriteICalendar(&b, line, nil, time.Time{}); err != nil {
+		t.Fatalf("WriteICalendar: %v", err)
+	}
+	for _, l := range strings.Split(b.String(), "\r\n") {
+		if len(l) > icalLineOctets {
+			t.Errorf("line longer than %d octets: %q", icalLineOctets, l)
+		}
+		if !utf8.ValidString(l) {
+			t.Errorf("fold split a rune: %q", l)
+		}
+	}
+	if unfolded := strings.ReplaceAll(b.String(), "\r\n ", ""); !strings.Contains(unfolded, strings.Repeat("ñ", 100)) {
+		t.Errorf("expected the folded line to unfold intact:\n%s", b.String())
+	}
+}
@@ -0,0 +1,507 @@
+package estate
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/pkg/lib/core"
+)
+
+// Import formats
+const (
+	ImportFormatCSV   = "csv"
+	ImportFormatJSONL = "jsonl"
+)
+
+// Import job statuses
+const (
+	ImportQueued    = "queued"
+	ImportRunning   = "running"
+	ImportCompleted = "completed"
+	ImportFailed    = "failed"
+)
+
+// Row outcomes, as reported in the result file
+const (
+	ImportRowCreated = "created" // Stored as a new property
+	ImportRowValid   = "valid"   // Would be created; dry runs only
+	ImportRowInvalid = "invalid" // Rejected by validation
+	ImportRowFailed  = "failed"  // Valid, but the dictionary or repository failed
+)
+
+// MaxImportJobErrors bounds the row errors kept on a job; every row is
+// listed in the result file.
+const MaxImportJobErrors = 100
+
+// importProgressEvery is how many rows are processed between progress saves.
+const importProgressEvery = 25
+
+// importQueueSize bounds the jobs waiting to run.
+const importQueueSize = 64
+
+var (
+	// ErrImportNotFound is returned when an import job does not exist.
+	ErrImportNotFound = errors.New("import not found")
+
+	// ErrInvalidImport is returned when an import file or its options
+	// cannot be used at all.
+	ErrInvalidImport = errors.New("invalid import")
+
+	// ErrImportTooLarge is returned when an import file exceeds the size limit.
+	ErrImportTooLarge = errors.New("import too large")
+
+	// ErrImportNotReady is returned when the result of an unfinished job is requested.
+	ErrImportNotReady = errors.New("import not finished")
+
+	// ErrImportBusy is returned when too many imports are waiting to run.
+	ErrImportBusy = errors.New("too many pending imports")
+)
+
+// ImportJob is a bulk property import running in the background. The
+// uploaded file and the per-row result file are kept in the BlobStore.
+type ImportJob struct {
+	ID              uuid.UUID         `json:"id"`
+	Format          string            `json:"format"`
+	Filename        string            `json:"filename,omitempty"`
+	DryRun          bool              `json:"dry_run"`
+	AllowDuplicates bool              `json:"allow_duplicates"`  // Rows matching existing properties are not rejected
+	Mapping         map[string]string `json:"mapping,omitempty"` // CSV column to import column
+	Ignored         []string          `json:"ignored_columns,omitempty"`
+	Status          string            `json:"status"`
+	Total           int               `json:"total"`     // Rows in the file
+	Processed       int               `json:"processed"` // Rows handled so far
+	Valid           int               `json:"valid"`     // Rows that passed validation
+	Created         int               `json:"created"`
+	Invalid         int               `json:"invalid"`
+	Failed          int               `json:"failed"`
+	Errors          []ImportRowResult `json:"errors,omitempty"` // First MaxImportJobErrors rejected rows
+	Error           string            `json:"error,omitempty"`  // Why the job failed
+	CreatedAt       time.Time         `json:"created_at"`
+	CreatedBy       string            `json:"created_by"`
+	StartedAt       *time.Time        `json:"started_at,omitempty"`
+	FinishedAt      *time.Time        `json:"finished_at,omitempty"`
+}
+
+// ImportRowResult is the outcome of a row of an import file.
+type ImportRowResult struct {
+	Row        int               `json:"row"`
+	Status     string            `json:"status"`
+	Name       string            `json:"name,omitempty"`
+	PropertyID *uuid.UUID        `json:"property_id,omitempty"`
+	Errors     []ValidationError `json:"errors,omitempty"`
+}
+
+// Finished reports whether the job has completed or failed.
+func (j *ImportJob) Finished() bool {
+	return j.Status == ImportCompleted || j.Status == ImportFailed
+}
+
+// SourceKey returns the blob key of the uploaded file.
+func (j *ImportJob) SourceKey() string {
+	return ImportPrefix(j.ID) + "source." + j.Format
+}
+
+// ResultKey returns the blob key of the result file.
+func (j *ImportJob) ResultKey() string {
+	return ImportPrefix(j.ID) + "result.csv"
+}
+
+// ImportPrefix returns the blob key prefix of the files of an import job.
+func ImportPrefix(id uuid.UUID) string {
+	return "imports/" + id.String() + "/"
+}
+
+// ImportRepo stores import jobs. ListImports returns the newest first.
+type ImportRepo interface {
+	CreateImport(ctx context.Context, job *ImportJob) error
+	GetImport(ctx context.Context, id uuid.UUID) (*ImportJob, error)
+	SaveImport(ctx context.Context, job *ImportJob) error
+	ListImports(ctx context.Context, limit int) ([]ImportJob, error)
+}
+
+// ImportRequest describes an import file being submitted.
+type ImportRequest struct {
+	Format          string
+	Filename        string
+	DryRun          bool
+	AllowDuplicates bool
+	Mapping         map[string]string
+	Actor           string
+}
+
+// Importer runs bulk property imports one at a time in the background.
+// Each row is validated like POST /estates, with the classification given
+// by dictionary key or label, and created unless the job is a dry run.
+// Rows likely duplicating existing properties, including those created by
+// earlier rows, are rejected unless the job allows duplicates.
+type Importer struct {
+	repo       Repo
+	jobs       ImportRepo
+	store      BlobStore
+	dict       Client
+	duplicates *DuplicateDetector
+	maxSize    int64
+	now        func() time.Time
+
+	queue  chan uuid.UUID
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewImporter returns an Importer creating properties in repo and accepting
+// files up to maxSize bytes. duplicates may be nil, in which case rows are
+// not checked for duplicates.
+func NewImporter(repo Repo, jobs ImportRepo, store BlobStore, dict Client, duplicates *DuplicateDetector, maxSize int64) *Importer {
+	return &Importer{
+		repo:       repo,
+		jobs:       jobs,
+		store:      store,
+		dict:       dict,
+		duplicates: duplicates,
+		maxSize:    maxSize,
+		now:        time.Now,
+		queue:      make(chan uuid.UUID, importQueueSize),
+	}
+}
+
+// MaxSize returns the import file size limit in bytes.
+func (im *Importer) MaxSize() int64 {
+	return im.maxSize
+}
+
+// Start resumes queued jobs, fails jobs interrupted by a previous shutdown
+// and starts the worker. The stores must be started before.
+func (im *Importer) Start(ctx context.Context) error {
+	pending, err := im.jobs.ListImports(ctx, 0)
+	if err != nil {
+		return fmt.Errorf("cannot list imports: %w", err)
+	}
+
+	var queued []uuid.UUID
+	for i := len(pending) - 1; i >= 0; i-- {
+		job := &pending[i]
+		switch job.Status {
+		case ImportQueued:
+			queued = append(queued, job.ID)
+		case ImportRunning:
+			// Rows may already have been created; running it again would duplicate them
+			if err := im.fail(ctx, job, "interrupted by a service restart"); err != nil {
+				return err
+			}
+		}
+	}
+
+	workerCtx, cancel := context.WithCancel(context.Background())
+	im.cancel = cancel
+	im.wg.Add(1)
+	go im.work(workerCtx)
+
+	for _, id := range queued {
+		select {
+		case im.queue <- id:
+		default:
+		}
+	}
+	return nil
+}
+
+// Stop stops the worker, waiting for the running job to be interrupted.
+func (im *Importer) Stop(ctx context.Context) error {
+	if im.cancel == nil {
+		return nil
+	}
+	im.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		im.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Submit checks and stores an import file and queues the job. Files that
+// cannot be read as a whole are rejected with ErrInvalidImport; problems in
+// single rows are reported by the job.
+func (im *Importer) Submit(ctx context.Context, req ImportRequest, r io.Reader) (*ImportJob, error) {
+	data, err := io.ReadAll(io.LimitReader(r, im.maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("cannot read import file: %w", err)
+	}
+	if int64(len(data)) > im.maxSize {
+		return nil, fmt.Errorf("%w: limit is %d bytes", ErrImportTooLarge, im.maxSize)
+	}
+
+	records, ignored, err := parseImport(data, req.Format, req.Mapping)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: the file has no rows", ErrInvalidImport)
+	}
+
+	job := &ImportJob{
+		ID:              core.GenerateNewID(),
+		Format:          req.Format,
+		Filename:        req.Filename,
+		DryRun:          req.DryRun,
+		AllowDuplicates: req.AllowDuplicates,
+		Mapping:         req.Mapping,
+		Ignored:         ignored,
+		Status:          ImportQueued,
+		Total:           len(records),
+		CreatedAt:       im.now(),
+		CreatedBy:       req.Actor,
+	}
+
+	if err := im.store.Put(ctx, job.SourceKey(), importContentType(job.Format), bytes.NewReader(data)); err != nil {
+		return nil, fmt.Errorf("cannot store import file: %w", err)
+	}
+	if err := im.jobs.CreateImport(ctx, job); err != nil {
+		_ = im.store.DeletePrefix(ctx, ImportPrefix(job.ID))
+		return nil, err
+	}
+
+	select {
+	case im.queue <- job.ID:
+	default:
+		if err := im.fail(ctx, job, ErrImportBusy.Error()); err != nil {
+			return nil, err
+		}
+		return nil, ErrImportBusy
+	}
+	return job, nil
+}
+
+// Get retrieves an import job.
+func (im *Importer) Get(ctx context.Context, id uuid.UUID) (*ImportJob, error) {
+	return im.jobs.GetImport(ctx, id)
+}
+
+// List lists the most recent import jobs.
+func (im *Importer) List(ctx context.Context, limit int) ([]ImportJob, error) {
+	return im.jobs.ListImports(ctx, limit)
+}
+
+// Result opens the result file of a completed job: a CSV with the row,
+// status, name, property_id and errors of every row.
+func (im *Importer) Result(ctx context.Context, job *ImportJob) (io.ReadCloser, error) {
+	if job.Status != ImportCompleted {
+		return nil, fmt.Errorf("%w: import is %s", ErrImportNotReady, job.Status)
+	}
+	return im.store.Open(ctx, job.ResultKey())
+}
+
+func (im *Importer) work(ctx context.Context) {
+	defer im.wg.Done()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-im.queue:
+			im.run(ctx, id)
+		}
+	}
+}
+
+// run processes a queued job. Failures are recorded on the job.
+func (im *Importer) run(ctx context.Context, id uuid.UUID) {
+	job, err := im.jobs.GetImport(ctx, id)
+	if err != nil || job.Status != ImportQueued {
+		return
+	}
+
+	started := im.now()
+	job.Status, job.StartedAt = ImportRunning, &started
+	if err := im.jobs.SaveImport(ctx, job); err != nil {
+		return
+	}
+
+	if err := im.process(ctx, job); err != nil {
+		// The job context is gone when stopping; record the failure regardless
+		_ = im.fail(context.WithoutCancel(ctx), job, err.Error())
+		return
+	}
+
+	finished := im.now()
+	job.Status, job.FinishedAt = ImportCompleted, &finished
+	_ = im.jobs.SaveImport(ctx, job)
+}
+
+func (im *Importer) process(ctx context.Context, job *ImportJob) error {
+	source, err := im.store.Open(ctx, job.SourceKey())
+	if err != nil {
+		return fmt.Errorf("cannot open import file: %w", err)
+	}
+	data, err := io.ReadAll(source)
+	source.Close()
+	if err != nil {
+		return fmt.Errorf("cannot read import file: %w", err)
+	}
+
+	records, _, err := parseImport(data, job.Format, job.Mapping)
+	if err != nil {
+		return err
+	}
+	job.Total = len(records)
+
+	var out bytes.Buffer
+	w := csv.NewWriter(&out)
+	w.Write([]string{"row", "status", "name", "property_id", "errors"})
+
+	resolver := newClassificationResolver(im.dict)
+	for i, rec := range records {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("interrupted after %d rows", job.Processed)
+		}
+
+		result := im.importRow(ctx, job, rec, resolver)
+		job.record(result)
+		w.Write(result.csv())
+
+		if (i+1)%importProgressEvery == 0 {
+			if err := im.jobs.SaveImport(ctx, job); err != nil {
+				return err
+			}
+		}
+	}
+
+	w.Flush()
+	if err := im.store.Put(ctx, job.ResultKey(), "text/csv", &out); err != nil {
+		return fmt.Errorf("cannot store result file: %w", err)
+	}
+	return nil
+}
+
+// importRow validates a record and, unless the job is a dry run, creates
+// the property.
+func (im *Importer) importRow(ctx context.Context, job *ImportJob, rec importRecord, resolver *classificationResolver) ImportRowResult {
+	p := rec.Property
+	result := ImportRowResult{Row: rec.Row, Name: p.Name, Errors: rec.Errors}
+
+	resolveErrs, err := resolver.resolve(ctx, rec.Classification, &p.Classification)
+	if err != nil {
+		result.Status = ImportRowFailed
+		result.Errors = append(result.Errors, ValidationError{Field: "classification", Message: "could not reach the dictionary"})
+		return result
+	}
+	result.Errors = append(result.Errors, resolveErrs...)
+
+	if p.CreatedBy == "" {
+		p.CreatedBy = job.CreatedBy
+	}
+	p.BeforeCreate()
+	result.Errors = append(result.Errors, ValidateCreateProperty(ctx, p)...)
+
+	if len(result.Errors) == 0 {
+		valid, errs, err := im.dict.ValidateClassification(ctx, p.Classification)
+		if err != nil {
+			result.Status = ImportRowFailed
+			result.Errors = append(result.Errors, ValidationError{Field: "classification", Message: "could not validate classification"})
+			return result
+		}
+		if !valid {
+			for _, e := range errs {
+				result.Errors = append(result.Errors, ValidationError{Field: "classification", Message: e})
+			}
+		}
+	}
+
+	if len(result.Errors) == 0 && !job.AllowDuplicates && im.duplicates != nil {
+		matches, err := im.duplicates.Find(ctx, p)
+		if err != nil {
+			result.Status = ImportRowFailed
+			result.Errors = append(result.Errors, ValidationError{Field: "", Message: "could not check for duplicates"})
+			return result
+		}
+		for _, m := range matches {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "duplicate",
+				Message: fmt.Sprintf("likely duplicate of %s (score %.2f)", m.ID, m.Score),
+			})
+		}
+	}
+
+	if len(result.Errors) > 0 {
+		result.Status = ImportRowInvalid
+		return result
+	}
+	if job.DryRun {
+		result.Status = ImportRowValid
+		return result
+	}
+
+	if err := im.repo.Create(WithActor(ctx, job.CreatedBy), p); err != nil {
+		result.Status = ImportRowFailed
+		result.Errors = []ValidationError{{Field: "", Message: "could not create property"}}
+		return result
+	}
+	result.Status = ImportRowCreated
+	result.PropertyID = &p.ID
+	return result
+}
+
+// fail marks the job as failed with a reason.
+func (im *Importer) fail(ctx context.Context, job *ImportJob, reason string) error {
+	finished := im.now()
+	job.Status, job.Error, job.FinishedAt = ImportFailed, reason, &finished
+	return im.jobs.SaveImport(ctx, job)
+}
+
+// record adds the outcome of a row to the job counters.
+func (j *ImportJob) record(result ImportRowResult) {
+	j.Processed++
+	switch result.Status {
+	case ImportRowCreated:
+		j.Valid++
+		j.Created++
+	case ImportRowValid:
+		j.Valid++
+	case ImportRowInvalid:
+		j.Invalid++
+	case ImportRowFailed:
+		j.Failed++
+	}
+	if len(result.Errors) > 0 && len(j.Errors) < MaxImportJobErrors {
+		j.Errors = append(j.Errors, result)
+	}
+}
+
+// csv returns the result file record of the row.
+func (r ImportRowResult) csv() []string {
+	id := ""
+	if r.PropertyID != nil {
+		id = r.PropertyID.String()
+	}
+	msgs := make([]string, 0, len(r.Errors))
+	for _, e := range r.Errors {
+		if e.Field == "" {
+			msgs = append(msgs, e.Message)
+			continue
+		}
+		msgs = append(msgs, e.Field+": "+e.Message)
+	}
+	return []string{strconv.Itoa(r.Row), r.Status, r.Name, id, strings.Join(msgs, "; ")}
+}
+
+func importContentType(format string) string {
+	if format == ImportFormatJSONL {
+		return "application/x-ndjson"
+	}
+	return "text/csv"
+}
@@ -0,0 +1,436 @@
+package estate
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+var (
+	testResidential = Option{ID: uuid.MustParse("00000000-0000-0000-0001-000000000001"), Key: "residential", ShortCode: "res", Label: "Residential"}
+	testApartment   = Option{ID: uuid.MustParse("00000000-0000-0000-0002-000000000002"), ParentID: &testResidential.ID, Key: "apartment", ShortCode: "apt", Label: "Apartment"}
+	testLoft        = Option{ID: uuid.MustParse("00000000-0000-0000-0003-000000000003"), ParentID: &testApartment.ID, Key: "loft", Label: "Loft"}
+)
+
+func TestParseImportMapping(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    map[string]string
+		wantErr bool
+	}{
+		{name: "empty", in: "", want: nil},
+		{name: "columns", in: "Titulo:name, Ciudad:city", want: map[string]string{"titulo": "name", "ciudad": "city"}},
+		{name: "alias target", in: "superficie:area", want: map[string]string{"superficie": "total_area"}},
+		{name: "ignored", in: "notes:-", want: map[string]string{"notes": "-"}},
+		{name: "unknown target", in: "titulo:headline", wantErr: true},
+		{name: "missing target", in: "titulo", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseImportMapping(tt.in)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidImport) {
+					t.Fatalf("expected ErrInvalidImport, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got) != len(tt.want) || (len(got) > 0 && !reflect.DeepEqual(got, tt.want)) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseImportCSV(t *testing.T) {
+	data := "\ufeffTitle,Category,Type,Street,City,Country,Area,Price,Currency,Pool,Notes\n" +
+		"Loft Centro,residential,Apartment,Mayor 12,Madrid,ES,\"85,5\",250000,eur,yes,call first\n" +
+		",,,,,,,,,,\n" +
+		"Bad,res,apt,Sol 1,Madrid,ES,big,1,EUR,maybe,\n"
+
+	records, ignored, err := parseImport([]byte(data), ImportFormatCSV, nil)
+	if err != nil {
+		t.Fatalf("parseImport: %v", err)
+	}
+	if !slices.Equal(ignored, []string{"Notes"}) {
+		t.Errorf("expected Notes to be ignored, got %v", ignored)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+
+	first := records[0]
+	if first.Row != 2 || len(first.Errors) != 0 {
+		t.Errorf("unexpected first record: row %d, errors %v", first.Row, first.Errors)
+	}
+	p := first.Property
+	if p.Name != "Loft Centro" || p.Location.Address.City != "Madrid" || p.Features.TotalArea != 85.5 {
+		t.Errorf("unexpected property: %+v", p)
+	}
+	if len(p.Prices) != 1 || p.Prices[0].Amount.String() != "250000" || p.Prices[0].Currency != "EUR" || p.Prices[0].Type != "sale" {
+		t.Errorf("unexpected prices: %+v", p.Prices)
+	}
+	if want := (importClassification{Category: "residential", Type: "Apartment"}); first.Classification != want {
+		t.Errorf("expected classification %+v, got %+v", want, first.Classification)
+	}
+
+	second := records[1]
+	if second.Row != 4 {
+		t.Errorf("expected the blank line to keep row numbers, got row %d", second.Row)
+	}
+	var fields []string
+	for _, e := range second.Errors {
+		fields = append(fields, e.Field)
+	}
+	sort.Strings(fields)
+	if !slices.Equal(fields, []string{"pool", "total_area"}) {
+		t.Errorf("expected total_area and pool errors, got %v", second.Errors)
+	}
+}
+
+func TestParseImportCSVHeader(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		mapping  map[string]string
+		wantErr  bool
+		wantCity string
+	}{
+		{name: "semicolons", data: "name;city\nPiso;Sevilla\n", wantCity: "Sevilla"},
+		{name: "mapping", data: "titulo,ciudad\nPiso,Sevilla\n", mapping: map[string]string{"titulo": "name", "ciudad": "city"}, wantCity: "Sevilla"},
+		{name: "no name column", data: "city\nSevilla\n", wantErr: true},
+		{name: "duplicate column", data: "name,title\nPiso,Piso\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, _, err := parseImport([]byte(tt.data), ImportFormatCSV, tt.mapping)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidImport) {
+					t.Fatalf("expected ErrInvalidImport, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseImport: %v", err)
+			}
+			if len(records) != 1 || records[0].Property.Location.Address.City != tt.wantCity {
+				t.Errorf("unexpected records: %+v", records)
+			}
+		})
+	}
+}
+
+func TestParseImportJSONL(t *testing.T) {
+	data := `{"name":"Loft","classification":{"category":"residential","type":"apt"},"revision":7}
+not json
+
+{"name":"By ID","classification":{"category_id":"00000000-0000-0000-0001-000000000001"}}
+`
+	records, _, err := parseImport([]byte(data), ImportFormatJSONL, nil)
+	if err != nil {
+		t.Fatalf("parseImport: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(records))
+	}
+	if records[0].Classification.Type != "apt" || records[0].Property.Revision != 0 {
+		t.Errorf("unexpected first record: %+v", records[0])
+	}
+	if records[1].Row != 2 || len(records[1].Errors) == 0 {
+		t.Errorf("expected an error on row 2, got %+v", records[1])
+	}
+	if records[2].Row != 4 || records[2].Classification.Category != testResidential.ID.String() {
+		t.Errorf("unexpected last record: %+v", records[2])
+	}
+}
+
+func TestClassificationResolver(t *testing.T) {
+	ctx := context.Background()
+	dict := newImportDictionary()
+
+	tests := []struct {
+		name       string
+		names      importClassification
+		want       Classification
+		wantFields []string
+	}{
+		{
+			name:  "keys",
+			names: importClassification{Category: "residential", Type: "apartment", Subtype: "loft"},
+			want:  Classification{CategoryID: testResidential.ID, TypeID: testApartment.ID, SubtypeID: testLoft.ID},
+		},
+		{
+			name:  "labels and codes",
+			names: importClassification{Category: "RES", Type: "Apartment"},
+			want:  Classification{CategoryID: testResidential.ID, TypeID: testApartment.ID},
+		},
+		{
+			name:  "ids",
+			names: importClassification{Category: testResidential.ID.String()},
+			want:  Classification{CategoryID: testResidential.ID},
+		},
+		{
+			name:       "unknown",
+			names:      importClassification{Category: "castle", Type: "apartment"},
+			wantFields: []string{"category", "type"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Classification
+			errs, err := newClassificationResolver(dict).resolve(ctx, tt.names, &got)
+			if err != nil {
+				t.Fatalf("resolve: %v", err)
+			}
+			var fields []string
+			for _, e := range errs {
+				fields = append(fields, e.Field)
+			}
+			if !slices.Equal(fields, tt.wantFields) {
+				t.Errorf("expected errors on %v, got %v", tt.wantFields, errs)
+			}
+			if got != tt.want {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestImporterRun(t *testing.T) {
+	data := "name,category,type,street,city,country,total_area,price,currency\n" +
+		"Loft Centro,residential,apartment,Mayor 12,Madrid,ES,85,250000,EUR\n" +
+		"Castle,castle,apartment,Sol 1,Madrid,ES,900,1,EUR\n" +
+		"No Area,res,apt,Sol 2,Madrid,ES,,1,EUR\n"
+
+	tests := []struct {
+		name        string
+		dryRun      bool
+		wantStatus  []string
+		wantCreated int
+	}{
+		{name: "dry run", dryRun: true, wantStatus: []string{ImportRowValid, ImportRowInvalid, ImportRowInvalid}},
+		{name: "import", wantStatus: []string{ImportRowCreated, ImportRowInvalid, ImportRowInvalid}, wantCreated: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			im, repo, _ := newTestImporter(1 << 20)
+
+			job, err := im.Submit(ctx, ImportRequest{Format: ImportFormatCSV, DryRun: tt.dryRun, Actor: "tester"}, strings.NewReader(data))
+			if err != nil {
+				t.Fatalf("Submit: %v", err)
+			}
+			if job.Status != ImportQueued || job.Total != 3 {
+				t.Fatalf("unexpected job: %+v", job)
+			}
+			if _, err := im.Result(ctx, job); !errors.Is(err, ErrImportNotReady) {
+				t.Errorf("expected ErrImportNotReady before running, got %v", err)
+			}
+
+			im.run(ctx, <-im.queue)
+
+			job, err = im.Get(ctx, job.ID)
+			if err != nil {
+				t.Fatalf("Get: %v", err)
+			}
+			if job.Status != ImportCompleted || job.Processed != 3 || job.Invalid != 2 || job.Created != tt.wantCreated {
+				t.Errorf("unexpected job: %+v", job)
+			}
+			if len(job.Errors) != 2 {
+				t.Errorf("expected 2 reported rows, got %+v", job.Errors)
+			}
+			if len(repo.created) != tt.wantCreated {
+				t.Errorf("expected %d created properties, got %d", tt.wantCreated, len(repo.created))
+			}
+			if tt.wantCreated > 0 {
+				p := repo.created[0]
+				if p.Classification.CategoryID != testResidential.ID || p.Classification.TypeID != testApartment.ID || p.CreatedBy != "tester" {
+					t.Errorf("unexpected created property: %+v", p)
+				}
+			}
+
+			content, err := im.Result(ctx, job)
+			if err != nil {
+				t.Fatalf("Result: %v", err)
+			}
+			defer content.Close()
+			rows, err := csv.NewReader(content).ReadAll()
+			if err != nil {
+				t.Fatalf("reading result: %v", err)
+			}
+			if len(rows) != 4 {
+				t.Fatalf("expected a header and 3 rows, got %v", rows)
+			}
+			for i, want := range tt.wantStatus {
+				if rows[i+1][1] != want {
+					t.Errorf("row %s: expected %s, got %s (%s)", rows[i+1][0], want, rows[i+1][1], rows[i+1][4])
+				}
+			}
+		})
+	}
+}
+
+func TestImporterSubmitInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		maxSize int64
+		wantErr error
+	}{
+		{name: "too large", data: "name\nLoft\n", maxSize: 4, wantErr: ErrImportTooLarge},
+		{name: "no rows", data: "name\n", maxSize: 1 << 10, wantErr: ErrInvalidImport},
+		{name: "no name column", data: "city\nMadrid\n", maxSize: 1 << 10, wantErr: ErrInvalidImport},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			im, _, store := newTestImporter(tt.maxSize)
+			_, err := im.Submit(context.Background(), ImportRequest{Format: ImportFormatCSV, Actor: "tester"}, strings.NewReader(tt.data))
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v, got %v", tt.wantErr, err)
+			}
+			if len(store.blobs) != 0 {
+				t.Errorf("expected nothing stored, got %d blobs", len(store.blobs))
+			}
+		})
+	}
+}
+
+func TestImporterStartFailsInterruptedJobs(t *testing.T) {
+	ctx := context.Background()
+	im, _, _ := newTestImporter(1 << 10)
+	jobs := im.jobs.(*memImportRepo)
+
+	running := &ImportJob{ID: uuid.New(), Status: ImportRunning}
+	if err := jobs.CreateImport(ctx, running); err != nil {
+		t.Fatal(err)
+	}
+	if err := im.Start(ctx); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer im.Stop(ctx)
+
+	got, err := im.Get(ctx, running.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.Status != ImportFailed || got.Error == "" || got.FinishedAt == nil {
+		t.Errorf("expected the interrupted job to fail, got %+v", got)
+	}
+}
+
+func newTestImporter(maxSize int64) (*Importer, *importTestRepo, *memBlobStore) {
+	repo := &importTestRepo{}
+	store := &memBlobStore{blobs: map[string][]byte{}}
+	return NewImporter(repo, &memImportRepo{jobs: map[uuid.UUID]ImportJob{}}, store, newImportDictionary(), nil, maxSize), repo, store
+}
+
+// importTestRepo records the properties created by an import.
+type importTestRepo struct {
+	Repo
+	created []*Property
+}
+
+func (r *importTestRepo) Create(ctx context.Context, p *Property) error {
+	r.created = append(r.created, p)
+	return nil
+}
+
+// memImportRepo is an in-memory ImportRepo.
+type memImportRepo struct {
+	mu   sync.Mutex
+	jobs map[uuid.UUID]ImportJob
+}
+
+func (r *memImportRepo) CreateImport(ctx context.Context, job *ImportJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.jobs[job.ID] = *job
+	return nil
+}
+
+func (r *memImportRepo) GetImport(ctx context.Context, id uuid.UUID) (*ImportJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job, ok := r.jobs[id]
+	if !ok {
+		return nil, ErrImportNotFound
+	}
+	return &job, nil
+}
+
+func (r *memImportRepo) SaveImport(ctx context.Context, job *ImportJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.jobs[job.ID]; !ok {
+		return ErrImportNotFound
+	}
+	r.jobs[job.ID] = *job
+	return nil
+}
+
+func (r *memImportRepo) ListImports(ctx context.Context, limit int) ([]ImportJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var jobs []ImportJob
+	for _, job := range r.jobs {
+		jobs = append(jobs, job)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].CreatedAt.After(jobs[j].CreatedAt) })
+	if limit > 0 && len(jobs) > limit {
+		jobs = jobs[:limit]
+	}
+	return jobs, nil
+}
+
+// importDictionary is a Client over a fixed residential classification.
+type importDictionary struct {
+	options map[string][]Option
+}
+
+func newImportDictionary() *importDictionary {
+	return &importDictionary{options: map[string][]Option{
+		CategorySetName: {testResidential},
+		TypeSetName:     {testApartment},
+		SubtypeSetName:  {testLoft},
+	}}
+}
+
+func (d *importDictionary) GetOption(ctx context.Context, id uuid.UUID) (*Option, error) {
+	for _, options := range d.options {
+		for _, opt := range options {
+			if opt.ID == id {
+				return &opt, nil
+			}
+		}
+	}
+	return nil, io.EOF
+}
+
+func (d *importDictionary) ListOptionsByParent(ctx context.Context, setName string, parentID *uuid.UUID) ([]Option, error) {
+	var options []Option
+	for _, opt := range d.options[setName] {
+		if (parentID == nil) == (opt.ParentID == nil) && (parentID == nil || *parentID == *opt.ParentID) {
+			options = append(options, opt)
+		}
+	}
+	return options, nil
+}
+
+func (d *importDictionary) ValidateClassification(ctx context.Context, c Classification) (bool, []string, error) {
+	if c.CategoryID != testResidential.ID {
+		return false, []string{"unknown category"}, nil
+	}
+	return true, nil, nil
+}
@@ -0,0 +1,337 @@
+package estate
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/pkg/lib/core"
+	"github.com/pulap/pulap/pkg/lib/telemetry"
+	"github.com/pulap/pulap/services/estate/internal/config"
+)
+
+// ImportHandler handles HTTP requests for bulk property imports.
+type ImportHandler struct {
+	*PropertyAccess
+	importer *Importer
+	tlm      *telemetry.HTTP
+}
+
+// NewImportHandler creates a new ImportHandler running imports with
+// importer, which is nil when the repository stores no imports; every
+// import route then answers 503.
+func NewImportHandler(importer *Importer, access *PropertyAccess, xparams config.XParams) *ImportHandler {
+	return &ImportHandler{
+		PropertyAccess: access,
+		importer:       importer,
+		tlm: telemetry.NewHTTP(
+			telemetry.WithTracer(xparams.Tracer()),
+			telemetry.WithMetrics(xparams.Metrics()),
+		),
+	}
+}
+
+// RegisterRoutes registers the import routes.
+func (h *ImportHandler) RegisterRoutes(r chi.Router) {
+	r.Group(func(r chi.Router) {
+		r.Use(h.authn())
+		r.Get("/estates/imports", h.ListImports)
+		r.Post("/estates/imports", h.CreateImport)
+		r.Get("/estates/imports/{importID}", h.GetImport)
+		r.Get("/estates/imports/{importID}/result", h.GetImportResult)
+	})
+}
+
+// PermissionImport allows bulk property imports.
+const PermissionImport = "estates:import"
+
+// importsResource is the authz resource imports are checked on.
+const importsResource = "estate-imports"
+
+// Import list limits
+const (
+	DefaultImportListLimit = 20
+	MaxImportListLimit     = 100
+)
+
+// ImportMeta describes an import list response.
+type ImportMeta struct {
+	Count   int      `json:"count"`
+	Columns []string `json:"columns"` // CSV columns an import understands
+}
+
+// CreateImport handles POST /estates/imports
+// The file is the request body (Content-Type text/csv or
+// application/x-ndjson) or the "file" part of a multipart form. ?format=
+// (csv or jsonl) overrides the detected format, ?dry_run=true validates
+// without creating properties, ?allow_duplicates=true creates rows likely
+// duplicating existing properties and ?map=Source:column,... renames CSV
+// columns. The job runs in the background; poll GET /estates/imports/{id}.
+// Requires PermissionImport.
+func (h *ImportHandler) CreateImport(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "ImportHandler.CreateImport")
+	defer finish()
+	log := h.log(r)
+	ctx := r.Context()
+
+	if h.importer == nil {
+		core.RespondError(w, http.StatusServiceUnavailable, "Imports are not available")
+		return
+	}
+
+	q := r.URL.Query()
+	actor := requestActor(r)
+	if actor == "" {
+		core.RespondError(w, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+	if status, msg := h.checkPermission(ctx, actor, PermissionImport, importsResource); status != 0 {
+		log.Info("import denied", "actor", actor)
+		core.RespondError(w, status, msg)
+		return
+	}
+
+	dryRun, err := strconv.ParseBool(q.Get("dry_run"))
+	if err != nil && q.Get("dry_run") != "" {
+		core.RespondError(w, http.StatusBadRequest, "dry_run must be true or false")
+		return
+	}
+	allowDuplicates, ok := parseAllowDuplicates(w, r, "allow_duplicates")
+	if !ok {
+		return
+	}
+	mapping, err := ParseImportMapping(q.Get("map"))
+	if err != nil {
+		core.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.importer.MaxSize()+MaxBodyBytes)
+	file, filename, contentType, err := importFile(r)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			core.RespondError(w, http.StatusRequestEntityTooLarge, "Import file is too large")
+			return
+		}
+		log.Debug("error reading import file", "error", err)
+		core.RespondError(w, http.StatusBadRequest, "Could not read import file")
+		return
+	}
+	defer file.Close()
+	if r.MultipartForm != nil {
+		defer r.MultipartForm.RemoveAll()
+	}
+
+	format := importFormat(q.Get("format"), contentType, filename)
+	if format == "" {
+		core.RespondError(w, http.StatusUnsupportedMediaType, "Import file must be CSV or JSON Lines")
+		return
+	}
+
+	job, err := h.importer.Submit(ctx, ImportRequest{
+		Format:          format,
+		Filename:        filename,
+		DryRun:          dryRun,
+		AllowDuplicates: allowDuplicates,
+		Mapping:         mapping,
+		Actor:           actor,
+	}, file)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		switch {
+		case errors.Is(err, ErrImportTooLarge), errors.As(err, &tooLarge):
+			core.RespondError(w, http.StatusRequestEntityTooLarge, "Import file is too large")
+		case errors.Is(err, ErrInvalidImport):
+			core.RespondError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, ErrImportBusy):
+			core.RespondError(w, http.StatusServiceUnavailable, "Too many imports are pending, try again later")
+		default:
+			log.Error("cannot submit import", "error", err)
+			core.RespondError(w, http.StatusInternalServerError, "Could not start import")
+		}
+		return
+	}
+
+	log.Info("import queued", "id", job.ID.String(), "actor", actor, "rows", job.Total, "dry_run", job.DryRun)
+	w.Header().Set("Location", importLink(job.ID))
+	w.WriteHeader(http.StatusAccepted)
+	core.RespondSuccess(w, job, importLinks(job)...)
+}
+
+// ListImports handles GET /estates/imports
+// The most recent jobs come first; ?limit= caps the list.
+func (h *ImportHandler) ListImports(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "ImportHandler.ListImports")
+	defer finish()
+	log := h.log(r)
+
+	if h.importer == nil {
+		core.RespondError(w, http.StatusServiceUnavailable, "Imports are not available")
+		return
+	}
+
+	limit := DefaultImportListLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			core.RespondError(w, http.StatusBadRequest, "limit must be a positive number")
+			return
+		}
+		limit = min(n, MaxImportListLimit)
+	}
+
+	jobs, err := h.importer.List(r.Context(), limit)
+	if err != nil {
+		log.Error("error listing imports", "error", err)
+		core.RespondError(w, http.StatusInternalServerError, "Could not retrieve imports")
+		return
+	}
+	if jobs == nil {
+		jobs = []ImportJob{}
+	}
+
+	core.RespondSuccessWithMeta(w, jobs, ImportMeta{Count: len(jobs), Columns: ImportColumns()})
+}
+
+// GetImport handles GET /estates/imports/{importID}
+// The job reports its progress and the first rejected rows.
+func (h *ImportHandler) GetImport(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "ImportHandler.GetImport")
+	defer finish()
+	log := h.log(r)
+
+	job, ok := h.loadImport(w, r, log)
+	if !ok {
+		return
+	}
+
+	core.RespondSuccess(w, job, importLinks(job)...)
+}
+
+// GetImportResult handles GET /estates/imports/{importID}/result
+// The result is a CSV listing the outcome of every row.
+func (h *ImportHandler) GetImportResult(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "ImportHandler.GetImportResult")
+	defer finish()
+	log := h.log(r)
+
+	job, ok := h.loadImport(w, r, log)
+	if !ok {
+		return
+	}
+
+	content, err := h.importer.Result(r.Context(), job)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrImportNotReady):
+			core.RespondError(w, http.StatusConflict, fmt.Sprintf("Import is %s, the result is not available", job.Status))
+		case errors.Is(err, ErrBlobNotFound):
+			core.RespondError(w, http.StatusNotFound, "Import result not found")
+		default:
+			log.Error("error opening import result", "error", err, "id", job.ID.String())
+			core.RespondError(w, http.StatusInternalServerError, "Could not retrieve import result")
+		}
+		return
+	}
+	defer content.Close()
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "import-"+job.ID.String()+".csv"))
+	if _, err := io.Copy(w, content); err != nil {
+		log.Debug("error writing import result", "error", err, "id", job.ID.String())
+	}
+}
+
+func (h *ImportHandler) loadImport(w http.ResponseWriter, r *http.Request, log core.Logger) (*ImportJob, bool) {
+	if h.importer == nil {
+		core.RespondError(w, http.StatusServiceUnavailable, "Imports are not available")
+		return nil, false
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "importID"))
+	if err != nil {
+		core.RespondError(w, http.StatusBadRequest, "Invalid import ID")
+		return nil, false
+	}
+
+	job, err := h.importer.Get(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, ErrImportNotFound) {
+			core.RespondError(w, http.StatusNotFound, "Import not found")
+			return nil, false
+		}
+		log.Error("error loading import", "error", err, "id", id.String())
+		core.RespondError(w, http.StatusInternalServerError, "Could not retrieve import")
+		return nil, false
+	}
+	return job, true
+}
+
+// importFile returns the uploaded file: the "file" part of a multipart
+// form, or else the request body.
+func importFile(r *http.Request) (io.ReadCloser, string, string, error) {
+	contentType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if contentType != "multipart/form-data" {
+		return r.Body, "", contentType, nil
+	}
+
+	if err := r.ParseMultipartForm(mediaFormMemory); err != nil {
+		return nil, "", "", err
+	}
+	file, fh, err := r.FormFile("file")
+	if err != nil {
+		return nil, "", "", err
+	}
+	partType, _, _ := mime.ParseMediaType(fh.Header.Get("Content-Type"))
+	return file, path.Base(strings.ReplaceAll(fh.Filename, `\`, "/")), partType, nil
+}
+
+// importFormat picks the format from the explicit parameter, the content
+// type or the file extension, in that order. It returns "" if none applies.
+func importFormat(param, contentType, filename string) string {
+	switch strings.ToLower(param) {
+	case ImportFormatCSV:
+		return ImportFormatCSV
+	case ImportFormatJSONL, "ndjson":
+		return ImportFormatJSONL
+	case "":
+	default:
+		return ""
+	}
+
+	switch contentType {
+	case "text/csv":
+		return ImportFormatCSV
+	case "application/x-ndjson", "application/jsonl", "application/x-jsonlines":
+		return ImportFormatJSONL
+	}
+
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".csv":
+		return ImportFormatCSV
+	case ".jsonl", ".ndjson":
+		return ImportFormatJSONL
+	}
+	return ""
+}
+
+func importLink(id uuid.UUID) string {
+	return "/estates/imports/" + id.String()
+}
+
+func importLinks(job *ImportJob) []core.Link {
+	links := []core.Link{{Rel: "self", Href: importLink(job.ID)}}
+	if job.Status == ImportCompleted {
+		links = append(links, core.Link{Rel: "result", Href: importLink(job.ID) + "/result"})
+	}
+	return append(links, core.Link{Rel: "collection", Href: "/estates/imports"})
+}
@@ -0,0 +1,486 @@
+package estate
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/services/estate/internal/money"
+)
+
+// importColumn sets a Property field from the text of a CSV cell.
+type importColumn func(p *Property, c *importClassification, value string) error
+
+// importColumns maps the CSV columns an import understands to Property
+// fields. Classification columns take a dictionary key, label, short code
+// or ID. The price columns set the primary price.
+var importColumns = map[string]importColumn{
+	"name":        func(p *Property, _ *importClassification, v string) error { p.Name = v; return nil },
+	"description": func(p *Property, _ *importClassification, v string) error { p.Description = v; return nil },
+	"status":      func(p *Property, _ *importClassification, v string) error { p.Status = strings.ToLower(v); return nil },
+	"owner_id":    func(p *Property, _ *importClassification, v string) error { p.OwnerID = v; return nil },
+	"team_id":     func(p *Property, _ *importClassification, v string) error { p.TeamID = v; return nil },
+
+	"category": func(_ *Property, c *importClassification, v string) error { c.Category = v; return nil },
+	"type":     func(_ *Property, c *importClassification, v string) error { c.Type = v; return nil },
+	"subtype":  func(_ *Property, c *importClassification, v string) error { c.Subtype = v; return nil },
+
+	"street": func(p *Property, _ *importClassification, v string) error { p.Location.Address.Street = v; return nil },
+	"number": func(p *Property, _ *importClassification, v string) error { p.Location.Address.Number = v; return nil },
+	"unit":   func(p *Property, _ *importClassification, v string) error { p.Location.Address.Unit = v; return nil },
+	"city":   func(p *Property, _ *importClassification, v string) error { p.Location.Address.City = v; return nil },
+	"state":  func(p *Property, _ *importClassification, v string) error { p.Location.Address.State = v; return nil },
+	"postal_code": func(p *Property, _ *importClassification, v string) error {
+		p.Location.Address.PostalCode = v
+		return nil
+	},
+	"country":   func(p *Property, _ *importClassification, v string) error { p.Location.Address.Country = v; return nil },
+	"region":    func(p *Property, _ *importClassification, v string) error { p.Location.Region = v; return nil },
+	"latitude":  floatColumn(func(p *Property) *float64 { return &p.Location.Coordinates.Latitude }),
+	"longitude": floatColumn(func(p *Property) *float64 { return &p.Location.Coordinates.Longitude }),
+
+	"total_area":      floatColumn(func(p *Property) *float64 { return &p.Features.TotalArea }),
+	"covered_area":    floatColumn(func(p *Property) *float64 { return &p.Features.CoveredArea }),
+	"land_area":       floatColumn(func(p *Property) *float64 { return &p.Features.LandArea }),
+	"bedrooms":        intColumn(func(p *Property) *int { return &p.Features.Bedrooms }),
+	"bathrooms":       intColumn(func(p *Property) *int { return &p.Features.Bathrooms }),
+	"half_baths":      intColumn(func(p *Property) *int { return &p.Features.HalfBaths }),
+	"rooms":           intColumn(func(p *Property) *int { return &p.Features.Rooms }),
+	"parking":         intColumn(func(p *Property) *int { return &p.Features.Parking }),
+	"covered_parking": intColumn(func(p *Property) *int { return &p.Features.CoveredParking }),
+	"floors":          intColumn(func(p *Property) *int { return &p.Features.Floors }),
+	"floor":           intColumn(func(p *Property) *int { return &p.Features.Floor }),
+	"year_built":      intColumn(func(p *Property) *int { return &p.Features.YearBuilt }),
+	"condition":       func(p *Property, _ *importClassification, v string) error { p.Features.Condition = v; return nil },
+	"amenities": func(p *Property, _ *importClassification, v string) error {
+		p.Features.Amenities = splitCell(v)
+		return nil
+	},
+
+	"price": func(p *Property, _ *importClassification, v string) error {
+		amount, err := money.Parse(v)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", v)
+		}
+		primaryPrice(p).Amount = amount
+		return nil
+	},
+	"currency": func(p *Property, _ *importClassification, v string) error {
+		primaryPrice(p).Currency = strings.ToUpper(v)
+		return nil
+	},
+	"price_type": func(p *Property, _ *importClassification, v string) error {
+		primaryPrice(p).Type = strings.ToLower(v)
+		return nil
+	},
+	"negotiable": boolColumn(func(p *Property) *bool { return &primaryPrice(p).Negotiable }),
+}
+
+// importColumnAliases maps alternative column names to importColumns.
+var importColumnAliases = map[string]string{
+	"title":       "name",
+	"category_id": "category",
+	"type_id":     "type",
+	"subtype_id":  "subtype",
+	"address":     "street",
+	"zip":         "postal_code",
+	"lat":         "latitude",
+	"lng":         "longitude",
+	"lon":         "longitude",
+	"area":        "total_area",
+	"amount":      "price",
+}
+
+func init() {
+	for _, flag := range AmenityFlags {
+		importColumns[flag] = boolColumn(func(p *Property) *bool { return amenityFlag(&p.Features, flag) })
+	}
+}
+
+// importRecord is a parsed row of an import file.
+type importRecord struct {
+	Row            int // Line in the file, the CSV header being line 1
+	Property       *Property
+	Classification importClassification
+	Errors         []ValidationError
+}
+
+// importClassification holds the classification as written in the file,
+// resolved against the dictionary before validation.
+type importClassification struct {
+	Category string `json:"category"`
+	Type     string `json:"type"`
+	Subtype  string `json:"subtype"`
+}
+
+// importLine is a JSON Lines row: a Property whose classification may also
+// be given by dictionary key or label.
+type importLine struct {
+	Property
+	Classification struct {
+		importClassification
+		CategoryID uuid.UUID `json:"category_id"`
+		TypeID     uuid.UUID `json:"type_id"`
+		SubtypeID  uuid.UUID `json:"subtype_id"`
+	} `json:"classification"`
+}
+
+// ParseImportMapping parses a column mapping such as "Título:name,Ciudad:city".
+// A target of "-" ignores the column.
+func ParseImportMapping(s string) (map[string]string, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+
+	mapping := map[string]string{}
+	for _, pair := range strings.Split(s, ",") {
+		from, to, ok := strings.Cut(pair, ":")
+		from, to = normalizeColumn(from), strings.TrimSpace(to)
+		if !ok || from == "" || to == "" {
+			return nil, fmt.Errorf("%w: mapping %q must be source:target", ErrInvalidImport, pair)
+		}
+		if to == "-" {
+			mapping[from] = to
+			continue
+		}
+		to = normalizeColumn(to)
+		if alias, ok := importColumnAliases[to]; ok {
+			to = alias
+		}
+		if _, known := importColumns[to]; !known {
+			return nil, fmt.Errorf("%w: unknown mapping target %q", ErrInvalidImport, to)
+		}
+		mapping[from] = to
+	}
+	return mapping, nil
+}
+
+// ImportColumns returns the CSV columns an import understands.
+func ImportColumns() []string {
+	columns := make([]string, 0, len(importColumns))
+	for name := range importColumns {
+		columns = append(columns, name)
+	}
+	slices.Sort(columns)
+	return columns
+}
+
+// parseImport reads the rows of an import file. It fails only when the file
+// cannot be read as a whole; row level problems are kept in each record.
+// For CSV it also returns the columns that are not imported.
+func parseImport(data []byte, format string, mapping map[string]string) ([]importRecord, []string, error) {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+	switch format {
+	case ImportFormatCSV:
+		return parseImportCSV(data, mapping)
+	case ImportFormatJSONL:
+		records, err := parseImportJSONL(data)
+		return records, nil, err
+	default:
+		return nil, nil, fmt.Errorf("%w: unsupported format %q", ErrInvalidImport, format)
+	}
+}
+
+func parseImportCSV(data []byte, mapping map[string]string) ([]importRecord, []string, error) {
+	cr := csv.NewReader(bytes.NewReader(data))
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	// Spreadsheets in locales with a decimal comma export with semicolons
+	if header, _, _ := bytes.Cut(data, []byte("\n")); bytes.Count(header, []byte(";")) > bytes.Count(header, []byte(",")) {
+		cr.Comma = ';'
+	}
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, nil, fmt.Errorf("%w: the file is empty", ErrInvalidImport)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: cannot read header: %v", ErrInvalidImport, err)
+	}
+
+	columns := make([]string, len(header))
+	seen := map[string]string{}
+	var ignored []string
+	for i, h := range header {
+		name := normalizeColumn(h)
+		if target, ok := mapping[name]; ok {
+			name = target
+		} else if alias, ok := importColumnAliases[name]; ok {
+			name = alias
+		}
+		if _, ok := importColumns[name]; !ok {
+			if name != "-" && strings.TrimSpace(h) != "" {
+				ignored = append(ignored, strings.TrimSpace(h))
+			}
+			continue
+		}
+		if prev, dup := seen[name]; dup {
+			return nil, nil, fmt.Errorf("%w: columns %q and %q both map to %s", ErrInvalidImport, prev, h, name)
+		}
+		seen[name] = h
+		columns[i] = name
+	}
+	if _, ok := seen["name"]; !ok {
+		return nil, nil, fmt.Errorf("%w: missing name column", ErrInvalidImport)
+	}
+
+	var records []importRecord
+	for {
+		fields, err := cr.Read()
+		if err == io.EOF {
+			return records, ignored, nil
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+		}
+		if isBlankRecord(fields) {
+			continue
+		}
+
+		line, _ := cr.FieldPos(0)
+		rec := importRecord{Row: line, Property: &Property{}}
+		for i, value := range fields {
+			value = strings.TrimSpace(value)
+			if i >= len(columns) || columns[i] == "" || value == "" {
+				continue
+			}
+			if err := importColumns[columns[i]](rec.Property, &rec.Classification, value); err != nil {
+				rec.Errors = append(rec.Errors, ValidationError{Field: columns[i], Message: err.Error()})
+			}
+		}
+		if len(rec.Property.Prices) > 0 && rec.Property.Prices[0].Type == "" {
+			rec.Property.Prices[0].Type = "sale"
+		}
+		records = append(records, rec)
+	}
+}
+
+func parseImportJSONL(data []byte) ([]importRecord, error) {
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(nil, MaxBodyBytes)
+
+	var records []importRecord
+	for line := 1; sc.Scan(); line++ {
+		text := bytes.TrimSpace(sc.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+
+		rec := importRecord{Row: line}
+		var in importLine
+		if err := json.Unmarshal(text, &in); err != nil {
+			rec.Property = &Property{}
+			rec.Errors = []ValidationError{{Field: "line", Message: fmt.Sprintf("invalid JSON: %v", err)}}
+			records = append(records, rec)
+			continue
+		}
+
+		c := in.Classification
+		rec.Classification = c.importClassification
+		for _, id := range []struct {
+			name *string
+			id   uuid.UUID
+		}{{&rec.Classification.Category, c.CategoryID}, {&rec.Classification.Type, c.TypeID}, {&rec.Classification.Subtype, c.SubtypeID}} {
+			if *id.name == "" && id.id != uuid.Nil {
+				*id.name = id.id.String()
+			}
+		}
+
+		// Identity and bookkeeping are assigned by the import
+		p := in.Property
+		p.ID, p.Revision, p.Valuation = uuid.Nil, 0, nil
+		rec.Property = &p
+		records = append(records, rec)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+	}
+	return records, nil
+}
+
+// classificationResolver resolves classification names against the
+// dictionary, caching the options of an import.
+type classificationResolver struct {
+	dict  Client
+	cache map[string][]Option
+}
+
+func newClassificationResolver(dict Client) *classificationResolver {
+	return &classificationResolver{dict: dict, cache: map[string][]Option{}}
+}
+
+// resolve sets the classification IDs of the property. It returns the
+// names that match no option, or an error when the dictionary fails.
+func (cr *classificationResolver) resolve(ctx context.Context, names importClassification, c *Classification) ([]ValidationError, error) {
+	var errs []ValidationError
+	steps := []struct {
+		field, set, name string
+		parent           *uuid.UUID
+		id               *uuid.UUID
+	}{
+		{"category", CategorySetName, names.Category, nil, &c.CategoryID},
+		{"type", TypeSetName, names.Type, &c.CategoryID, &c.TypeID},
+		{"subtype", SubtypeSetName, names.Subtype, &c.TypeID, &c.SubtypeID},
+	}
+	for _, s := range steps {
+		if s.name == "" {
+			continue
+		}
+		if s.parent != nil && *s.parent == uuid.Nil {
+			errs = append(errs, ValidationError{Field: s.field, Message: fmt.Sprintf("%q cannot be resolved without its parent", s.name)})
+			continue
+		}
+
+		options, err := cr.options(ctx, s.set, s.parent)
+		if err != nil {
+			return nil, err
+		}
+		opt, ok := matchOption(options, s.name)
+		if !ok {
+			errs = append(errs, ValidationError{Field: s.field, Message: fmt.Sprintf("%q is not a known %s", s.name, s.field)})
+			continue
+		}
+		*s.id = opt.ID
+	}
+	return errs, nil
+}
+
+func (cr *classificationResolver) options(ctx context.Context, set string, parent *uuid.UUID) ([]Option, error) {
+	key := set
+	if parent != nil {
+		key += "/" + parent.String()
+	}
+	if options, ok := cr.cache[key]; ok {
+		return options, nil
+	}
+
+	options, err := cr.dict.ListOptionsByParent(ctx, set, parent)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list %s options: %w", set, err)
+	}
+	cr.cache[key] = options
+	return options, nil
+}
+
+// matchOption finds an option by ID, key, short code or label, ignoring case.
+func matchOption(options []Option, name string) (Option, bool) {
+	id, idErr := uuid.Parse(name)
+	for _, opt := range options {
+		if idErr == nil && opt.ID == id {
+			return opt, true
+		}
+		if strings.EqualFold(opt.Key, name) || strings.EqualFold(opt.ShortCode, name) || strings.EqualFold(opt.Label, name) {
+			return opt, true
+		}
+	}
+	return Option{}, false
+}
+
+func floatColumn(field func(*Property) *float64) importColumn {
+	return func(p *Property, _ *importClassification, v string) error {
+		f, err := strconv.ParseFloat(strings.ReplaceAll(v, ",", "."), 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", v)
+		}
+		*field(p) = f
+		return nil
+	}
+}
+
+func intColumn(field func(*Property) *int) importColumn {
+	return func(p *Property, _ *importClassification, v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("%q is not a whole number", v)
+		}
+		*field(p) = n
+		return nil
+	}
+}
+
+func boolColumn(field func(*Property) *bool) importColumn {
+	return func(p *Property, _ *importClassification, v string) error {
+		switch strings.ToLower(v) {
+		case "true", "yes", "y", "1", "x", "si", "sí", "tak":
+			*field(p) = true
+		case "false", "no", "n", "0", "nie":
+			*field(p) = false
+		default:
+			return fmt.Errorf("%q is not yes or no", v)
+		}
+		return nil
+	}
+}
+
+// primaryPrice returns the first price of the property, adding it if needed.
+func primaryPrice(p *Property) *Price {
+	if len(p.Prices) == 0 {
+		p.Prices = []Price{{}}
+	}
+	return &p.Prices[0]
+}
+
+// amenityFlag returns the Features field of an AmenityFlags entry.
+func amenityFlag(f *Features, flag string) *bool {
+	switch flag {
+	case "pool":
+		return &f.Pool
+	case "garden":
+		return &f.Garden
+	case "balcony":
+		return &f.Balcony
+	case "terrace":
+		return &f.Terrace
+	case "elevator":
+		return &f.Elevator
+	case "air_conditioning":
+		return &f.AirConditioning
+	case "heating":
+		return &f.Heating
+	case "furnished":
+		return &f.Furnished
+	case "pet_friendly":
+		return &f.PetFriendly
+	case "storage":
+		return &f.Storage
+	case "laundry":
+		return &f.Laundry
+	case "fireplace":
+		return &f.Fireplace
+	}
+	panic("estate: unknown amenity flag " + flag)
+}
+
+// splitCell splits a multi-value cell on ";" or "|".
+func splitCell(v string) []string {
+	var out []string
+	for _, part := range strings.FieldsFunc(v, func(r rune) bool { return r == ';' || r == '|' }) {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+func normalizeColumn(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(s)
+}
+
+func isBlankRecord(fields []string) bool {
+	for _, f := range fields {
+		if strings.TrimSpace(f) != "" {
+			return false
+		}
+	}
+	return true
+}
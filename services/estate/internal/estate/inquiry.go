@@ -0,0 +1,635 @@
+package estate
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	authpkg "github.com/pulap/pulap/pkg/lib/auth"
+	"github.com/pulap/pulap/pkg/lib/core"
+)
+
+// Inquiry pipeline stages
+const (
+	InquiryNew       = "new"
+	InquiryContacted = "contacted"
+	InquiryViewing   = "viewing"
+	InquiryOffer     = "offer"
+	InquiryClosed    = "closed" // With an outcome, won or lost
+)
+
+// InquiryStages lists every inquiry stage in pipeline order.
+var InquiryStages = []string{InquiryNew, InquiryContacted, InquiryViewing, InquiryOffer, InquiryClosed}
+
+// Inquiry outcomes, set when an inquiry is closed
+const (
+	InquiryWon  = "won" // The inquiry ended in a sale or a lease
+	InquiryLost = "lost"
+)
+
+// InquiryOutcomes lists every outcome of a closed inquiry.
+var InquiryOutcomes = []string{InquiryWon, InquiryLost}
+
+// Inquiry sources
+const (
+	InquiryManual = "manual"
+	InquiryPhone  = "phone"
+	InquiryEmail  = "email"
+	InquiryWeb    = "web" // Submitted through the public endpoint
+	InquiryPortal = "portal"
+)
+
+// InquirySources lists every source an inquiry can arrive from.
+var InquirySources = []string{InquiryManual, InquiryPhone, InquiryEmail, InquiryWeb, InquiryPortal}
+
+// Limits of the free text of inquiries
+const (
+	MaxInquiryMessage = 4000
+	MaxInquiryNote    = 4000
+)
+
+var (
+	// ErrInquiryNotFound is returned when an inquiry does not exist.
+	ErrInquiryNotFound = errors.New("inquiry not found")
+
+	// ErrInquiryClosed is returned when changing a closed inquiry.
+	ErrInquiryClosed = errors.New("inquiry is closed")
+
+	// ErrInvalidOutcome is returned when closing an inquiry without a valid
+	// outcome, or giving one to an open inquiry.
+	ErrInvalidOutcome = errors.New("invalid inquiry outcome")
+
+	// ErrInquirySpam is returned when a submitted inquiry looks like spam.
+	ErrInquirySpam = errors.New("inquiry looks like spam")
+
+	// ErrInquiryDuplicate is returned when a contact submits the same
+	// inquiry again within the duplicate window.
+	ErrInquiryDuplicate = errors.New("inquiry was already submitted")
+
+	// ErrInquiryRateLimited is returned when a client submits more inquiries
+	// than the rate limit allows.
+	ErrInquiryRateLimited = errors.New("too many inquiries")
+)
+
+// Inquiry is a lead: someone asking about a property, worked by an agent
+// through the pipeline stages until it is closed as won or lost.
+//
+// The contact is only held in the clear in memory. Repositories store it
+// sealed in the Contact* fields, encrypted with auth.EncryptEmail and found
+// by an auth.ComputeLookupHash of its email or phone, like authn stores the
+// emails of users.
+type Inquiry struct {
+	ID            uuid.UUID  `json:"id"`
+	PropertyID    uuid.UUID  `json:"property_id"`
+	Contact       Contact    `json:"contact"`
+	Message       string     `json:"message,omitempty"`
+	Source        string     `json:"source"`             // One of InquirySources
+	AgentID       string     `json:"agent_id,omitempty"` // Assigned agent, empty while unassigned
+	Stage         string     `json:"stage"`              // One of InquiryStages
+	Outcome       string     `json:"outcome,omitempty"`  // One of InquiryOutcomes once closed
+	ClosedAt      *time.Time `json:"closed_at,omitempty"`
+	ContactCT     []byte     `json:"-"`
+	ContactIV     []byte     `json:"-"`
+	ContactTag    []byte     `json:"-"`
+	ContactLookup []byte     `json:"-"`
+	Revision      int64      `json:"revision"` // Incremented on every write, exposed as the ETag
+	CreatedAt     time.Time  `json:"created_at"`
+	CreatedBy     string     `json:"created_by"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	UpdatedBy     string     `json:"updated_by"`
+}
+
+// InquiryNote is a note an agent attached to an inquiry.
+type InquiryNote struct {
+	ID        uuid.UUID `json:"id"`
+	InquiryID uuid.UUID `json:"inquiry_id"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+	CreatedBy string    `json:"created_by"`
+}
+
+// GetID returns the ID of the Inquiry (implements Identifiable interface).
+func (i *Inquiry) GetID() uuid.UUID {
+	return i.ID
+}
+
+// ResourceType returns the resource type for URL generation.
+func (i *Inquiry) ResourceType() string {
+	return "inquiry"
+}
+
+// BeforeCreate sets the ID, timestamps and first revision.
+func (i *Inquiry) BeforeCreate() {
+	if i.ID == uuid.Nil {
+		i.ID = core.GenerateNewID()
+	}
+	if i.Stage == "" {
+		i.Stage = InquiryNew
+	}
+	i.CreatedAt = time.Now()
+	i.UpdatedAt = i.CreatedAt
+	i.Revision = 1
+}
+
+// BeforeUpdate sets the update timestamp.
+func (i *Inquiry) BeforeUpdate() {
+	i.UpdatedAt = time.Now()
+}
+
+// Normalize trims the inquiry.
+func (i *Inquiry) Normalize() {
+	i.Contact.Normalize()
+	i.Message = strings.TrimSpace(i.Message)
+	i.Source = strings.ToLower(strings.TrimSpace(i.Source))
+	i.AgentID = strings.TrimSpace(i.AgentID)
+}
+
+// Validate checks the inquiry before it is stored.
+func (i *Inquiry) Validate() []ValidationError {
+	var errors []ValidationError
+
+	if i.PropertyID == uuid.Nil {
+		errors = append(errors, ValidationError{Field: "property_id", Message: "property_id is required"})
+	}
+	errors = append(errors, i.Contact.Validate("contact")...)
+	if len(i.Message) > MaxInquiryMessage {
+		errors = append(errors, ValidationError{Field: "message", Message: fmt.Sprintf("message cannot be longer than %d characters", MaxInquiryMessage)})
+	}
+	if !slices.Contains(InquirySources, i.Source) {
+		errors = append(errors, ValidationError{Field: "source", Message: "source must be one of: " + strings.Join(InquirySources, ", ")})
+	}
+	if !slices.Contains(InquiryStages, i.Stage) {
+		errors = append(errors, ValidationError{Field: "stage", Message: "stage must be one of: " + strings.Join(InquiryStages, ", ")})
+	}
+	if (i.Stage == InquiryClosed) != slices.Contains(InquiryOutcomes, i.Outcome) {
+		errors = append(errors, ValidationError{Field: "outcome", Message: "outcome must be one of " + strings.Join(InquiryOutcomes, ", ") + " once closed, and empty before"})
+	}
+
+	return errors
+}
+
+// Closed returns true if the inquiry was closed as won or lost.
+func (i *Inquiry) Closed() bool {
+	return i.Stage == InquiryClosed
+}
+
+// AllowedInquiryStages returns the stages an inquiry can move to: any
+// other stage while it is open, none once closed. A client coming back
+// after that is a new inquiry.
+func AllowedInquiryStages(stage string) []string {
+	if stage == InquiryClosed {
+		return []string{}
+	}
+	var allowed []string
+	for _, s := range InquiryStages {
+		if s != stage {
+			allowed = append(allowed, s)
+		}
+	}
+	return allowed
+}
+
+// Transition moves the inquiry to another stage at a time. Closing it
+// takes the outcome, which other stages do not.
+func (i *Inquiry) Transition(to, outcome string, at time.Time) error {
+	if !slices.Contains(InquiryStages, to) {
+		return fmt.Errorf("%w: %q", ErrInvalidStatus, to)
+	}
+	if i.Closed() {
+		return fmt.Errorf("%w: it was closed as %s", ErrInquiryClosed, i.Outcome)
+	}
+	if to == i.Stage {
+		return fmt.Errorf("%w: inquiry is already %s", ErrInvalidTransition, to)
+	}
+	if (to == InquiryClosed) != slices.Contains(InquiryOutcomes, outcome) {
+		if to == InquiryClosed {
+			return fmt.Errorf("%w: closing takes one of %s", ErrInvalidOutcome, strings.Join(InquiryOutcomes, ", "))
+		}
+		return fmt.Errorf("%w: only closing takes an outcome", ErrInvalidOutcome)
+	}
+
+	i.Stage, i.Outcome = to, outcome
+	if to == InquiryClosed {
+		closedAt := at.UTC()
+		i.ClosedAt = &closedAt
+	}
+	return nil
+}
+
+// Assign hands an open inquiry to an agent, or unassigns it when agentID
+// is empty.
+func (i *Inquiry) Assign(agentID string) error {
+	if i.Closed() {
+		return fmt.Errorf("%w: it was closed as %s", ErrInquiryClosed, i.Outcome)
+	}
+	i.AgentID = strings.TrimSpace(agentID)
+	return nil
+}
+
+// BeforeCreate sets the ID and timestamp.
+func (n *InquiryNote) BeforeCreate() {
+	if n.ID == uuid.Nil {
+		n.ID = core.GenerateNewID()
+	}
+	n.CreatedAt = time.Now()
+}
+
+// Normalize trims the note.
+func (n *InquiryNote) Normalize() {
+	n.Body = strings.TrimSpace(n.Body)
+}
+
+// Validate checks the note before it is stored.
+func (n *InquiryNote) Validate() []ValidationError {
+	var errors []ValidationError
+	if n.Body == "" {
+		errors = append(errors, ValidationError{Field: "body", Message: "body is required"})
+	}
+	if len(n.Body) > MaxInquiryNote {
+		errors = append(errors, ValidationError{Field: "body", Message: fmt.Sprintf("body cannot be longer than %d characters", MaxInquiryNote)})
+	}
+	return errors
+}
+
+// InquiryQuery filters the inquiries listed. Zero values mean "no filter".
+type InquiryQuery struct {
+	PropertyIDs []uuid.UUID
+	AgentIDs    []string
+	Unassigned  bool // Only inquiries without an agent
+	Stages      []string
+	Sources     []string
+	Limit       int
+}
+
+// Normalize fills defaults and validates the query.
+func (q *InquiryQuery) Normalize() []ValidationError {
+	var errors []ValidationError
+	if q.Limit <= 0 {
+		q.Limit = DefaultSearchLimit
+	}
+	if q.Limit > MaxSearchLimit {
+		q.Limit = MaxSearchLimit
+	}
+	for _, s := range q.Stages {
+		if !slices.Contains(InquiryStages, s) {
+			errors = append(errors, ValidationError{Field: "stage", Message: "stage must be one of: " + strings.Join(InquiryStages, ", ")})
+			break
+		}
+	}
+	for _, s := range q.Sources {
+		if !slices.Contains(InquirySources, s) {
+			errors = append(errors, ValidationError{Field: "source", Message: "source must be one of: " + strings.Join(InquirySources, ", ")})
+			break
+		}
+	}
+	if q.Unassigned && len(q.AgentIDs) > 0 {
+		errors = append(errors, ValidationError{Field: "unassigned", Message: "unassigned cannot be combined with agent"})
+	}
+	return errors
+}
+
+// ParseInquiryQuery reads an InquiryQuery from URL parameters: agent, stage
+// and source take comma-separated lists, unassigned=true lists the
+// inquiries nobody was assigned.
+func ParseInquiryQuery(values url.Values) (InquiryQuery, []ValidationError) {
+	p := queryParser{values: values}
+	q := InquiryQuery{
+		AgentIDs:   splitList(values.Get("agent")),
+		Unassigned: values.Get("unassigned") == "true",
+		Stages:     splitList(values.Get("stage")),
+		Sources:    splitList(values.Get("source")),
+		Limit:      p.int("limit"),
+	}
+	errors := p.errors
+	errors = append(errors, q.Normalize()...)
+	return q, errors
+}
+
+// InquiryCount is how many inquiries about a property, assigned to an
+// agent, are at a stage with an outcome.
+type InquiryCount struct {
+	PropertyID uuid.UUID
+	AgentID    string
+	Stage      string
+	Outcome    string
+	Count      int
+}
+
+// Inquiry conversion report groupings
+const (
+	ConversionByProperty = "property"
+	ConversionByAgent    = "agent"
+)
+
+// InquiryConversion is how the inquiries of a property or an agent went.
+type InquiryConversion struct {
+	Key    string         `json:"key"` // Property ID or agent ID, empty for unassigned inquiries
+	Total  int            `json:"total"`
+	Stages map[string]int `json:"stages"` // Inquiries at each stage
+	Won    int            `json:"won"`
+	Lost   int            `json:"lost"`
+	Rate   float64        `json:"rate"` // Share of the inquiries won
+}
+
+// ConvertInquiries reports the conversion of the counted inquiries by
+// property or agent, most inquiries first.
+func ConvertInquiries(counts []InquiryCount, by string) []InquiryConversion {
+	groups := map[string]*InquiryConversion{}
+	for _, c := range counts {
+		key := c.AgentID
+		if by == ConversionByProperty {
+			key = c.PropertyID.String()
+		}
+		g := groups[key]
+		if g == nil {
+			g = &InquiryConversion{Key: key, Stages: map[string]int{}}
+			for _, s := range InquiryStages {
+				g.Stages[s] = 0
+			}
+			groups[key] = g
+		}
+		g.Total += c.Count
+		g.Stages[c.Stage] += c.Count
+		switch c.Outcome {
+		case InquiryWon:
+			g.Won += c.Count
+		case InquiryLost:
+			g.Lost += c.Count
+		}
+	}
+
+	report := make([]InquiryConversion, 0, len(groups))
+	for _, g := range groups {
+		if g.Total > 0 {
+			g.Rate = math.Round(float64(g.Won)/float64(g.Total)*1000) / 1000
+		}
+		report = append(report, *g)
+	}
+	slices.SortFunc(report, func(a, b InquiryConversion) int {
+		return cmp.Or(cmp.Compare(b.Total, a.Total), cmp.Compare(a.Key, b.Key))
+	})
+	return report
+}
+
+// InquiryRepo defines the repository interface for Inquiry aggregates.
+// Repositories only see sealed contacts.
+type InquiryRepo interface {
+	// CreateInquiry stores a new inquiry.
+	CreateInquiry(ctx context.Context, i *Inquiry) error
+
+	// GetInquiry retrieves an inquiry, or ErrInquiryNotFound.
+	GetInquiry(ctx context.Context, id uuid.UUID) (*Inquiry, error)
+
+	// SaveInquiry updates an inquiry while its stored revision is still
+	// i.Revision, which is then incremented. It returns ErrInquiryNotFound
+	// or ErrRevisionConflict.
+	SaveInquiry(ctx context.Context, i *Inquiry) error
+
+	// ListInquiries lists the inquiries matching the query, newest first.
+	// The query is expected to be normalized.
+	ListInquiries(ctx context.Context, query InquiryQuery) ([]*Inquiry, error)
+
+	// HasInquirySince returns true if the contact with the lookup hash
+	// inquired about the property since a time.
+	HasInquirySince(ctx context.Context, propertyID uuid.UUID, contactLookup []byte, since time.Time) (bool, error)
+
+	// AddInquiryNote attaches a note to an inquiry, or returns
+	// ErrInquiryNotFound.
+	AddInquiryNote(ctx context.Context, n *InquiryNote) error
+
+	// ListInquiryNotes lists the notes of an inquiry, oldest first.
+	ListInquiryNotes(ctx context.Context, inquiryID uuid.UUID) ([]*InquiryNote, error)
+
+	// CountInquiries counts the inquiries created since a time by
+	// property, agent, stage and outcome.
+	CountInquiries(ctx context.Context, since time.Time) ([]InquiryCount, error)
+}
+
+// InquiryGuard screens the inquiries submitted through the public
+// endpoint. Zero values disable each check.
+type InquiryGuard struct {
+	RateLimit       int           // Submissions a client can make per RateWindow
+	RateWindow      time.Duration //
+	DuplicateWindow time.Duration // Repeated inquiries of a contact about a property within it are dropped
+	MaxLinks        int           // Messages with more links are spam
+}
+
+// DefaultInquiryGuard allows five submissions per client an hour, drops
+// repeated inquiries within a day and messages with more than two links.
+var DefaultInquiryGuard = InquiryGuard{RateLimit: 5, RateWindow: time.Hour, DuplicateWindow: 24 * time.Hour, MaxLinks: 2}
+
+var inquiryLink = regexp.MustCompile(`(?i)https?://|www\.|\[url`)
+
+// Inquiries manages inquiries, sealing their contacts before they reach
+// the repository.
+type Inquiries struct {
+	repo          InquiryRepo
+	encryptionKey []byte
+	lookupKey     []byte
+	guard         InquiryGuard
+
+	mu          sync.Mutex
+	submissions map[string][]time.Time // Recent public submissions by client
+}
+
+// NewInquiries returns the inquiries stored in repo. Contacts are
+// encrypted with encryptionKey, an AES key of 16, 24 or 32 bytes, and
+// looked up by an HMAC with lookupKey.
+func NewInquiries(repo InquiryRepo, encryptionKey, lookupKey []byte, guard InquiryGuard) (*Inquiries, error) {
+	switch len(encryptionKey) {
+	case 16, 24, 32:
+	default:
+		return nil, fmt.Errorf("inquiry encryption key must be 16, 24 or 32 bytes, got %d", len(encryptionKey))
+	}
+	if len(lookupKey) == 0 {
+		return nil, fmt.Errorf("inquiry lookup key is required")
+	}
+	if guard.RateLimit < 0 || guard.RateWindow < 0 || guard.DuplicateWindow < 0 || guard.MaxLinks < 0 {
+		return nil, fmt.Errorf("inquiry guard limits cannot be negative")
+	}
+	return &Inquiries{
+		repo:          repo,
+		encryptionKey: encryptionKey,
+		lookupKey:     lookupKey,
+		guard:         guard,
+		submissions:   map[string][]time.Time{},
+	}, nil
+}
+
+// Create stores a new inquiry.
+func (s *Inquiries) Create(ctx context.Context, i *Inquiry) error {
+	if err := s.seal(i); err != nil {
+		return err
+	}
+	return s.repo.CreateInquiry(ctx, i)
+}
+
+// Submit stores an inquiry made through the public endpoint by a client,
+// e.g. its IP address, unless it is screened out by the guard with
+// ErrInquirySpam, ErrInquiryDuplicate or ErrInquiryRateLimited.
+func (s *Inquiries) Submit(ctx context.Context, i *Inquiry, client string, now time.Time) error {
+	if s.guard.MaxLinks > 0 && len(inquiryLink.FindAllStringIndex(i.Message, -1)) > s.guard.MaxLinks {
+		return ErrInquirySpam
+	}
+	if !s.allow(client, now) {
+		return ErrInquiryRateLimited
+	}
+
+	if err := s.seal(i); err != nil {
+		return err
+	}
+	if s.guard.DuplicateWindow > 0 {
+		found, err := s.repo.HasInquirySince(ctx, i.PropertyID, i.ContactLookup, now.Add(-s.guard.DuplicateWindow))
+		if err != nil {
+			return err
+		}
+		if found {
+			return ErrInquiryDuplicate
+		}
+	}
+	return s.repo.CreateInquiry(ctx, i)
+}
+
+// RetryAfter returns how long a rate limited client should wait.
+func (s *Inquiries) RetryAfter() time.Duration {
+	return s.guard.RateWindow
+}
+
+// Get retrieves an inquiry.
+func (s *Inquiries) Get(ctx context.Context, id uuid.UUID) (*Inquiry, error) {
+	i, err := s.repo.GetInquiry(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.open(i); err != nil {
+		return nil, err
+	}
+	return i, nil
+}
+
+// Save updates an inquiry.
+func (s *Inquiries) Save(ctx context.Context, i *Inquiry) error {
+	if err := s.seal(i); err != nil {
+		return err
+	}
+	return s.repo.SaveInquiry(ctx, i)
+}
+
+// List lists the inquiries matching the query, newest first.
+func (s *Inquiries) List(ctx context.Context, query InquiryQuery) ([]*Inquiry, error) {
+	inquiries, err := s.repo.ListInquiries(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	for _, i := range inquiries {
+		if err := s.open(i); err != nil {
+			return nil, err
+		}
+	}
+	return inquiries, nil
+}
+
+// AddNote attaches a note to an inquiry.
+func (s *Inquiries) AddNote(ctx context.Context, n *InquiryNote) error {
+	return s.repo.AddInquiryNote(ctx, n)
+}
+
+// ListNotes lists the notes of an inquiry, oldest first.
+func (s *Inquiries) ListNotes(ctx context.Context, inquiryID uuid.UUID) ([]*InquiryNote, error) {
+	return s.repo.ListInquiryNotes(ctx, inquiryID)
+}
+
+// Count counts the inquiries created since a time.
+func (s *Inquiries) Count(ctx context.Context, since time.Time) ([]InquiryCount, error) {
+	return s.repo.CountInquiries(ctx, since)
+}
+
+// seal encrypts the contact of an inquiry into its Contact* fields.
+func (s *Inquiries) seal(i *Inquiry) error {
+	plain, err := json.Marshal(i.Contact)
+	if err != nil {
+		return fmt.Errorf("encode inquiry contact: %w", err)
+	}
+	encrypted, err := authpkg.EncryptEmail(string(plain), s.encryptionKey)
+	if err != nil {
+		return fmt.Errorf("encrypt inquiry contact: %w", err)
+	}
+	i.ContactCT, i.ContactIV, i.ContactTag = encrypted.Ciphertext, encrypted.IV, encrypted.Tag
+	i.ContactLookup = authpkg.ComputeLookupHash(contactLookupKey(i.Contact), s.lookupKey)
+	return nil
+}
+
+// open decrypts the contact of an inquiry from its Contact* fields.
+func (s *Inquiries) open(i *Inquiry) error {
+	plain, err := authpkg.DecryptEmail(&authpkg.EncryptedData{Ciphertext: i.ContactCT, IV: i.ContactIV, Tag: i.ContactTag}, s.encryptionKey)
+	if err != nil {
+		return fmt.Errorf("decrypt contact of inquiry %s: %w", i.ID, err)
+	}
+	if err := json.Unmarshal([]byte(plain), &i.Contact); err != nil {
+		return fmt.Errorf("decode contact of inquiry %s: %w", i.ID, err)
+	}
+	return nil
+}
+
+// contactLookupKey is what identifies a contact: the email, or the digits
+// of the phone without one.
+func contactLookupKey(c Contact) string {
+	if c.Email != "" {
+		return "email:" + authpkg.NormalizeEmail(c.Email)
+	}
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, c.Phone)
+	return "phone:" + digits
+}
+
+// allow records a public submission of a client and returns false if it
+// exceeds the rate limit.
+func (s *Inquiries) allow(client string, now time.Time) bool {
+	if s.guard.RateLimit == 0 || s.guard.RateWindow == 0 {
+		return true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	from := now.Add(-s.guard.RateWindow)
+	recent := func(times []time.Time) []time.Time {
+		return slices.DeleteFunc(times, func(t time.Time) bool { return !t.After(from) })
+	}
+	// Forget idle clients now and then so the map does not grow unbounded
+	if len(s.submissions) > maxTrackedClients {
+		for c, times := range s.submissions {
+			if times = recent(times); len(times) == 0 {
+				delete(s.submissions, c)
+			} else {
+				s.submissions[c] = times
+			}
+		}
+	}
+
+	times := recent(s.submissions[client])
+	if len(times) >= s.guard.RateLimit {
+		s.submissions[client] = times
+		return false
+	}
+	s.submissions[client] = append(times, now)
+	return true
+}
+
+// maxTrackedClients is how many clients are tracked before idle ones are
+// forgotten.
+const maxTrackedClients = 10000
@@ -0,0 +1,324 @@
+package estate
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/pkg/lib/core"
+)
+
+var (
+	testInquiryKey    = []byte("0123456789abcdef0123456789abcdef")
+	testInquiryLookup = []byte("lookup-secret")
+)
+
+func newTestInquiry(propertyID uuid.UUID) *Inquiry {
+	return &Inquiry{
+		PropertyID: propertyID,
+		Contact:    Contact{Name: "Marta Ruiz", Email: "marta@example.com", Phone: "+34 600 123 456"},
+		Message:    "Is the flat still available?",
+		Source:     InquiryWeb,
+		Stage:      InquiryNew,
+	}
+}
+
+// memInquiryRepo stores inquiries in memory for the Inquiries tests.
+type memInquiryRepo struct {
+	inquiries []*Inquiry
+}
+
+func (m *memInquiryRepo) CreateInquiry(_ context.Context, i *Inquiry) error {
+	i.BeforeCreate()
+	stored := *i
+	m.inquiries = append(m.inquiries, &stored)
+	return nil
+}
+
+func (m *memInquiryRepo) GetInquiry(_ context.Context, id uuid.UUID) (*Inquiry, error) {
+	for _, i := range m.inquiries {
+		if i.ID == id {
+			stored := *i
+			stored.Contact = Contact{}
+			return &stored, nil
+		}
+	}
+	return nil, ErrInquiryNotFound
+}
+
+func (m *memInquiryRepo) SaveInquiry(context.Context, *Inquiry) error { return nil }
+
+func (m *memInquiryRepo) ListInquiries(context.Context, InquiryQuery) ([]*Inquiry, error) {
+	return nil, nil
+}
+
+func (m *memInquiryRepo) HasInquirySince(_ context.Context, propertyID uuid.UUID, lookup []byte, since time.Time) (bool, error) {
+	for _, i := range m.inquiries {
+		if i.PropertyID == propertyID && bytes.Equal(i.ContactLookup, lookup) && !i.CreatedAt.Before(since) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *memInquiryRepo) AddInquiryNote(context.Context, *InquiryNote) error { return nil }
+
+func (m *memInquiryRepo) ListInquiryNotes(context.Context, uuid.UUID) ([]*InquiryNote, error) {
+	return nil, nil
+}
+
+func (m *memInquiryRepo) CountInquiries(context.Context, time.Time) ([]InquiryCount, error) {
+	return nil, nil
+}
+
+func TestInquiryNormalizeAndValidate(t *testing.T) {
+	i := newTestInquiry(uuid.New())
+	i.Contact.Email = " Marta@Example.com "
+	i.Source = " Web "
+	i.Normalize()
+
+	if i.Contact.Email != "marta@example.com" || i.Source != InquiryWeb {
+		t.Errorf("unexpected normalized inquiry: %+v", i)
+	}
+	if errs := i.Validate(); len(errs) > 0 {
+		t.Fatalf("expected a valid inquiry, got %v", errs)
+	}
+
+	tests := []struct {
+		name   string
+		change func(i *Inquiry)
+		field  string
+	}{
+		{"property", func(i *Inquiry) { i.PropertyID = uuid.Nil }, "property_id"},
+		{"contact", func(i *Inquiry) { i.Contact.Email, i.Contact.Phone = "", "" }, "contact"},
+		{"message", func(i *Inquiry) { i.Message = strings.Repeat("a", MaxInquiryMessage+1) }, "message"},
+		{"source", func(i *Inquiry) { i.Source = "fax" }, "source"},
+		{"stage", func(i *Inquiry) { i.Stage = "won" }, "stage"},
+		{"outcome while open", func(i *Inquiry) { i.Outcome = InquiryWon }, "outcome"},
+		{"closed without outcome", func(i *Inquiry) { i.Stage = InquiryClosed }, "outcome"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			invalid := *i
+			tt.change(&invalid)
+			errs := invalid.Validate()
+			if len(errs) != 1 || errs[0].Field != tt.field {
+				t.Errorf("expected one %s error, got %v", tt.field, errs)
+			}
+		})
+	}
+}
+
+func TestInquiryTransition(t *testing.T) {
+	i := newTestInquiry(uuid.New())
+	now := time.Date(2026, 5, 4, 12, 0, 0, 0, time.UTC)
+
+	if err := i.Transition("won", "", now); !errors.Is(err, ErrInvalidStatus) {
+		t.Errorf("expected ErrInvalidStatus, got %v", err)
+	}
+	if err := i.Transition(InquiryNew, "", now); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("expected ErrInvalidTransition to the same stage, got %v", err)
+	}
+	if err := i.Transition(InquiryOffer, "", now); err != nil {
+		t.Fatalf("Transition: %v", err)
+	}
+	if err := i.Transition(InquiryViewing, InquiryWon, now); !errors.Is(err, ErrInvalidOutcome) {
+		t.Errorf("expected ErrInvalidOutcome for an open stage, got %v", err)
+	}
+	if err := i.Transition(InquiryClosed, "", now); !errors.Is(err, ErrInvalidOutcome) {
+		t.Errorf("expected ErrInvalidOutcome closing without one, got %v", err)
+	}
+	if err := i.Transition(InquiryClosed, InquiryLost, now); err != nil {
+		t.Fatalf("Transition: %v", err)
+	}
+	if !i.Closed() || i.Outcome != InquiryLost || i.ClosedAt == nil || !i.ClosedAt.Equal(now) {
+		t.Errorf("expected a lost inquiry closed at %v, got %+v", now, i)
+	}
+
+	if err := i.Transition(InquiryContacted, "", now); !errors.Is(err, ErrInquiryClosed) {
+		t.Errorf("expected ErrInquiryClosed, got %v", err)
+	}
+	if err := i.Assign("agent-1"); !errors.Is(err, ErrInquiryClosed) {
+		t.Errorf("expected ErrInquiryClosed assigning, got %v", err)
+	}
+	if allowed := AllowedInquiryStages(i.Stage); len(allowed) != 0 {
+		t.Errorf("expected no stages after closing, got %v", allowed)
+	}
+	if allowed := AllowedInquiryStages(InquiryNew); len(allowed) != len(InquiryStages)-1 {
+		t.Errorf("expected every other stage, got %v", allowed)
+	}
+}
+
+func TestParseInquiryQuery(t *testing.T) {
+	q, errs := ParseInquiryQuery(url.Values{"agent": {"agent-1,agent-2"}, "stage": {"new,offer"}, "source": {"web"}})
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if len(q.AgentIDs) != 2 || len(q.Stages) != 2 || len(q.Sources) != 1 || q.Limit != DefaultSearchLimit {
+		t.Errorf("unexpected query: %+v", q)
+	}
+
+	if _, errs := ParseInquiryQuery(url.Values{"stage": {"won"}}); len(errs) != 1 || errs[0].Field != "stage" {
+		t.Errorf("expected a stage error, got %v", errs)
+	}
+	if _, errs := ParseInquiryQuery(url.Values{"agent": {"agent-1"}, "unassigned": {"true"}}); len(errs) != 1 || errs[0].Field != "unassigned" {
+		t.Errorf("expected an unassigned error, got %v", errs)
+	}
+}
+
+func TestConvertInquiries(t *testing.T) {
+	flat, house := uuid.New(), uuid.New()
+	counts := []InquiryCount{
+		{PropertyID: flat, AgentID: "agent-1", Stage: InquiryClosed, Outcome: InquiryWon, Count: 1},
+		{PropertyID: flat, AgentID: "agent-1", Stage: InquiryClosed, Outcome: InquiryLost, Count: 2},
+		{PropertyID: flat, AgentID: "agent-2", Stage: InquiryViewing, Count: 1},
+		{PropertyID: house, AgentID: "agent-2", Stage: InquiryClosed, Outcome: InquiryWon, Count: 1},
+		{PropertyID: house, Stage: InquiryNew, Count: 1},
+	}
+
+	byProperty := ConvertInquiries(counts, ConversionByProperty)
+	if len(byProperty) != 2 || byProperty[0].Key != flat.String() {
+		t.Fatalf("expected the flat first, got %+v", byProperty)
+	}
+	if c := byProperty[0]; c.Total != 4 || c.Won != 1 || c.Lost != 2 || c.Rate != 0.25 || c.Stages[InquiryClosed] != 3 || c.Stages[InquiryNew] != 0 {
+		t.Errorf("unexpected flat conversion: %+v", c)
+	}
+
+	byAgent := ConvertInquiries(counts, ConversionByAgent)
+	if len(byAgent) != 3 || byAgent[0].Key != "agent-1" || byAgent[2].Key != "" {
+		t.Fatalf("expected agent-1, agent-2 then unassigned, got %+v", byAgent)
+	}
+	if c := byAgent[1]; c.Total != 2 || c.Won != 1 || c.Rate != 0.5 {
+		t.Errorf("unexpected agent-2 conversion: %+v", c)
+	}
+}
+
+func TestInquiriesSealContact(t *testing.T) {
+	if _, err := NewInquiries(nil, []byte("short"), testInquiryLookup, DefaultInquiryGuard); err == nil {
+		t.Errorf("expected a short key to be rejected")
+	}
+	if _, err := NewInquiries(nil, testInquiryKey, nil, DefaultInquiryGuard); err == nil {
+		t.Errorf("expected a missing lookup key to be rejected")
+	}
+
+	repo := &memInquiryRepo{}
+	s, err := NewInquiries(repo, testInquiryKey, testInquiryLookup, DefaultInquiryGuard)
+	if err != nil {
+		t.Fatalf("NewInquiries: %v", err)
+	}
+	ctx := context.Background()
+	i := newTestInquiry(uuid.New())
+	if err := s.Create(ctx, i); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	stored := repo.inquiries[0]
+	if len(stored.ContactCT) == 0 || bytes.Contains(stored.ContactCT, []byte("marta")) || len(stored.ContactLookup) == 0 {
+		t.Errorf("expected the contact to be stored encrypted, got %q", stored.ContactCT)
+	}
+
+	got, err := s.Get(ctx, i.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.Contact != i.Contact {
+		t.Errorf("expected the contact %+v, got %+v", i.Contact, got.Contact)
+	}
+
+	// The lookup identifies the contact by email, whatever its case
+	other := newTestInquiry(uuid.New())
+	other.Contact = Contact{Name: "M. Ruiz", Email: "MARTA@example.com"}
+	if err := s.seal(other); err != nil {
+		t.Fatalf("seal: %v", err)
+	}
+	if !bytes.Equal(other.ContactLookup, stored.ContactLookup) {
+		t.Errorf("expected the same lookup for the same email")
+	}
+	phone := Contact{Name: "Jan", Phone: "+48 600-100-200"}
+	if contactLookupKey(phone) != "phone:48600100200" {
+		t.Errorf("unexpected phone lookup key %q", contactLookupKey(phone))
+	}
+}
+
+func TestInquiriesSubmitGuard(t *testing.T) {
+	guard := InquiryGuard{RateLimit: 2, RateWindow: time.Hour, DuplicateWindow: 24 * time.Hour, MaxLinks: 1}
+	repo := &memInquiryRepo{}
+	s, err := NewInquiries(repo, testInquiryKey, testInquiryLookup, guard)
+	if err != nil {
+		t.Fatalf("NewInquiries: %v", err)
+	}
+	ctx := context.Background()
+	now := time.Now()
+	flat := uuid.New()
+
+	spam := newTestInquiry(flat)
+	spam.Message = "Cheap loans at https://example.com and www.example.org"
+	if err := s.Submit(ctx, spam, "10.0.0.1", now); !errors.Is(err, ErrInquirySpam) {
+		t.Errorf("expected ErrInquirySpam, got %v", err)
+	}
+
+	if err := s.Submit(ctx, newTestInquiry(flat), "10.0.0.1", now); err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	if err := s.Submit(ctx, newTestInquiry(flat), "10.0.0.1", now); !errors.Is(err, ErrInquiryDuplicate) {
+		t.Errorf("expected ErrInquiryDuplicate, got %v", err)
+	}
+	if err := s.Submit(ctx, newTestInquiry(uuid.New()), "10.0.0.1", now); !errors.Is(err, ErrInquiryRateLimited) {
+		t.Errorf("expected ErrInquiryRateLimited, got %v", err)
+	}
+	if err := s.Submit(ctx, newTestInquiry(uuid.New()), "10.0.0.2", now); err != nil {
+		t.Errorf("expected another client to submit, got %v", err)
+	}
+	if err := s.Submit(ctx, newTestInquiry(uuid.New()), "10.0.0.1", now.Add(time.Hour)); err != nil {
+		t.Errorf("expected the client to submit after the window, got %v", err)
+	}
+	if len(repo.inquiries) != 3 {
+		t.Errorf("expected 3 stored inquiries, got %d", len(repo.inquiries))
+	}
+}
+
+func TestClientAddress(t *testing.T) {
+	trusted, err := core.ParseTrustedProxies("10.0.0.0/8")
+	if err != nil {
+		t.Fatalf("ParseTrustedProxies: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		remote    string
+		forwarded string
+		want      string
+	}{
+		{"direct", "203.0.113.7:5000", "", "203.0.113.7"},
+		{"forged header", "203.0.113.7:5000", "198.51.100.1", "203.0.113.7"},
+		{"trusted proxy", "10.0.0.5:5000", "198.51.100.1", "198.51.100.1"},
+		{"forged hop behind proxy", "10.0.0.5:5000", "192.0.2.1, 198.51.100.1", "198.51.100.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			handler := core.RealIPMiddleware(trusted)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = clientAddress(r)
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, "/estates/"+uuid.NewString()+"/inquire", nil)
+			req.RemoteAddr = tt.remote
+			if tt.forwarded != "" {
+				req.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
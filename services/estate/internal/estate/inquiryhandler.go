@@ -0,0 +1,661 @@
+package estate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/pkg/lib/core"
+	"github.com/pulap/pulap/pkg/lib/telemetry"
+	"github.com/pulap/pulap/services/estate/internal/config"
+)
+
+// InquiryHandler handles HTTP requests for the Inquiry aggregate.
+type InquiryHandler struct {
+	*PropertyAccess
+	inquiries *Inquiries
+	tlm       *telemetry.HTTP
+}
+
+// NewInquiryHandler creates a new InquiryHandler for inquiries, which is
+// nil when inquiries are disabled; every inquiry route then answers 503.
+func NewInquiryHandler(inquiries *Inquiries, access *PropertyAccess, xparams config.XParams) *InquiryHandler {
+	return &InquiryHandler{
+		PropertyAccess: access,
+		inquiries:      inquiries,
+		tlm: telemetry.NewHTTP(
+			telemetry.WithTracer(xparams.Tracer()),
+			telemetry.WithMetrics(xparams.Metrics()),
+		),
+	}
+}
+
+// RegisterRoutes registers the inquiry routes. The inquiry form of public
+// listings takes no bearer token.
+func (h *InquiryHandler) RegisterRoutes(r chi.Router) {
+	authn := h.authn()
+
+	r.Post("/estates/{id}/inquire", h.SubmitInquiry)
+	r.With(authn).Get("/estates/{id}/inquiries", h.ListPropertyInquiries)
+	r.With(authn).Post("/estates/{id}/inquiries", h.CreateInquiry)
+	r.Route("/inquiries", func(r chi.Router) {
+		r.Use(authn)
+		r.Get("/", h.ListInquiries)
+		r.Get("/conversion", h.GetInquiryConversion)
+		r.Get("/{id}", h.GetInquiry)
+		r.Post("/{id}/assign", h.AssignInquiry)
+		r.Post("/{id}/transitions", h.TransitionInquiry)
+		r.Get("/{id}/notes", h.ListInquiryNotes)
+		r.Post("/{id}/notes", h.AddInquiryNote)
+	})
+}
+
+// DefaultConversionPeriod is how far back the conversion report looks
+// unless since is given.
+const DefaultConversionPeriod = 365 * 24 * time.Hour
+
+// InquirySubmission is the payload of POST /estates/{id}/inquire, the form
+// on public listings.
+type InquirySubmission struct {
+	Contact Contact `json:"contact"`
+	Message string  `json:"message"`
+	Website string  `json:"website"` // Honeypot, hidden from people and left empty
+}
+
+// InquiryReceipt acknowledges a public inquiry without revealing whether
+// it was stored.
+type InquiryReceipt struct {
+	Status string `json:"status"`
+}
+
+// InquiryAssignRequest is the payload of POST /inquiries/{id}/assign.
+type InquiryAssignRequest struct {
+	AgentID string `json:"agent_id"` // Empty to unassign
+}
+
+// InquiryTransitionRequest is the payload of POST
+// /inquiries/{id}/transitions.
+type InquiryTransitionRequest struct {
+	To      string `json:"to"`
+	Outcome string `json:"outcome,omitempty"` // Required to close
+}
+
+// InquiryMeta describes an inquiry: the stages it can move to.
+type InquiryMeta struct {
+	Allowed []string `json:"allowed"`
+}
+
+// InquiryListMeta describes a list of inquiries and names the properties
+// they are about, by ID.
+type InquiryListMeta struct {
+	Count      int               `json:"count"`
+	Limit      int               `json:"limit"`
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+// InquiryConversionMeta describes a conversion report and names the
+// properties in it, by ID.
+type InquiryConversionMeta struct {
+	By         string            `json:"by"`
+	Since      string            `json:"since"`
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+// CreateInquiry handles POST /estates/{id}/inquiries
+// Records an inquiry that arrived by phone, email or a portal. Inquiries
+// start new, assigned to agent_id if given. Requires PermissionWrite on
+// the property.
+func (h *InquiryHandler) CreateInquiry(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "InquiryHandler.CreateInquiry")
+	defer finish()
+	log := h.log(r)
+	ctx := r.Context()
+
+	if !h.inquiriesAvailable(w) {
+		return
+	}
+	property, ok := h.loadProperty(w, r, PermissionWrite)
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, MaxBodyBytes)
+	defer r.Body.Close()
+
+	var i Inquiry
+	if err := json.NewDecoder(r.Body).Decode(&i); err != nil {
+		log.Debug("error decoding JSON", "error", err)
+		core.RespondError(w, http.StatusBadRequest, "Invalid JSON payload")
+		return
+	}
+
+	i.ID = uuid.Nil
+	i.PropertyID = property.ID
+	i.Stage, i.Outcome, i.ClosedAt = InquiryNew, "", nil
+	if i.Normalize(); i.Source == "" {
+		i.Source = InquiryManual
+	}
+	if !respondInquiryInvalid(w, &i, log) {
+		return
+	}
+
+	i.CreatedBy = requestActor(r)
+	i.UpdatedBy = i.CreatedBy
+	if err := h.inquiries.Create(ctx, &i); err != nil {
+		h.respondInquirySaveError(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", ETag(i.Revision))
+	w.WriteHeader(http.StatusCreated)
+	core.RespondSuccessWithMeta(w, &i, inquiryMeta(&i), inquiryLinks(&i)...)
+}
+
+// SubmitInquiry handles POST /estates/{id}/inquire
+// The public form of available and reserved properties, without
+// credentials. Submissions filling the website honeypot, with too many
+// links or repeating an inquiry of the same contact are dropped but
+// acknowledged like the rest, so bots learn nothing; clients over the rate
+// limit get 429.
+func (h *InquiryHandler) SubmitInquiry(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "InquiryHandler.SubmitInquiry")
+	defer finish()
+	log := h.log(r)
+	ctx := r.Context()
+
+	if !h.inquiriesAvailable(w) {
+		return
+	}
+	id, ok := h.parseIDParam(w, r, log)
+	if !ok {
+		return
+	}
+
+	property, err := h.repo.Get(ctx, id)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		log.Error("error loading property", "error", err, "id", id.String())
+		core.RespondError(w, http.StatusInternalServerError, "Could not retrieve property")
+		return
+	}
+	if property == nil || !IsListedStatus(property.Status) {
+		core.RespondError(w, http.StatusNotFound, "Property not found")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, MaxBodyBytes)
+	defer r.Body.Close()
+
+	var req InquirySubmission
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Debug("error decoding JSON", "error", err)
+		core.RespondError(w, http.StatusBadRequest, "Invalid JSON payload")
+		return
+	}
+	if strings.TrimSpace(req.Website) != "" {
+		log.Debug("inquiry dropped", "reason", "honeypot", "property_id", id.String())
+		respondInquiryReceived(w)
+		return
+	}
+
+	i := Inquiry{PropertyID: property.ID, Contact: req.Contact, Message: req.Message, Source: InquiryWeb, Stage: InquiryNew}
+	i.Normalize()
+	if !respondInquiryInvalid(w, &i, log) {
+		return
+	}
+
+	err = h.inquiries.Submit(ctx, &i, clientAddress(r), time.Now())
+	switch {
+	case err == nil:
+		log.Info("inquiry received", "id", i.ID.String(), "property_id", id.String())
+	case errors.Is(err, ErrInquiryRateLimited):
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(h.inquiries.RetryAfter().Seconds()))))
+		core.RespondError(w, http.StatusTooManyRequests, "Too many inquiries, try again later")
+		return
+	case errors.Is(err, ErrInquirySpam), errors.Is(err, ErrInquiryDuplicate):
+		log.Debug("inquiry dropped", "reason", err.Error(), "property_id", id.String())
+	default:
+		log.Error("cannot save inquiry", "error", err, "property_id", id.String())
+		core.RespondError(w, http.StatusInternalServerError, "Could not save inquiry")
+		return
+	}
+	respondInquiryReceived(w)
+}
+
+// ListPropertyInquiries handles GET /estates/{id}/inquiries
+// The inquiries about the property, newest first. agent, stage and source
+// filter by comma-separated lists, unassigned=true to the inquiries
+// nobody was assigned.
+func (h *InquiryHandler) ListPropertyInquiries(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "InquiryHandler.ListPropertyInquiries")
+	defer finish()
+	log := h.log(r)
+	ctx := r.Context()
+
+	if !h.inquiriesAvailable(w) {
+		return
+	}
+	property, ok := h.loadProperty(w, r, PermissionRead)
+	if !ok {
+		return
+	}
+
+	query, validationErrors := ParseInquiryQuery(r.URL.Query())
+	if len(validationErrors) > 0 {
+		log.Debug("invalid inquiry query", "errors", validationErrors)
+		core.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid query: %s", validationErrors[0].Message))
+		return
+	}
+	query.PropertyIDs = []uuid.UUID{property.ID}
+
+	inquiries, err := h.inquiries.List(ctx, query)
+	if err != nil {
+		log.Error("error listing inquiries", "error", err, "property_id", property.ID.String())
+		core.RespondError(w, http.StatusInternalServerError, "Could not retrieve inquiries")
+		return
+	}
+	if inquiries == nil {
+		inquiries = []*Inquiry{}
+	}
+
+	core.RespondSuccessWithMeta(w, inquiries, InquiryListMeta{Count: len(inquiries), Limit: query.Limit})
+}
+
+// ListInquiries handles GET /inquiries
+// The inquiries about readable properties, newest first, filtered like
+// GET /estates/{id}/inquiries, as the pipeline across properties. limit
+// caps the inquiries considered before access is checked.
+func (h *InquiryHandler) ListInquiries(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "InquiryHandler.ListInquiries")
+	defer finish()
+	log := h.log(r)
+	ctx := r.Context()
+
+	if !h.inquiriesAvailable(w) {
+		return
+	}
+
+	query, validationErrors := ParseInquiryQuery(r.URL.Query())
+	if len(validationErrors) > 0 {
+		log.Debug("invalid inquiry query", "errors", validationErrors)
+		core.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid query: %s", validationErrors[0].Message))
+		return
+	}
+
+	inquiries, err := h.inquiries.List(ctx, query)
+	if err != nil {
+		log.Error("error listing inquiries", "error", err)
+		core.RespondError(w, http.StatusInternalServerError, "Could not retrieve inquiries")
+		return
+	}
+
+	ids := make([]uuid.UUID, 0, len(inquiries))
+	for _, i := range inquiries {
+		ids = append(ids, i.PropertyID)
+	}
+	readable, ok := h.readableProperties(w, r, ids)
+	if !ok {
+		return
+	}
+
+	list := []*Inquiry{}
+	names := map[string]string{}
+	for _, i := range inquiries {
+		if p := readable[i.PropertyID]; p != nil {
+			list = append(list, i)
+			names[p.ID.String()] = p.Name
+		}
+	}
+
+	core.RespondSuccessWithMeta(w, list, InquiryListMeta{Count: len(list), Limit: query.Limit, Properties: names})
+}
+
+// GetInquiryConversion handles GET /inquiries/conversion
+// How the inquiries about readable properties created since a date,
+// YYYY-MM-DD and a year ago by default, went: by property, or by agent
+// with by=agent. Inquiries nobody was assigned are reported under an
+// empty agent.
+func (h *InquiryHandler) GetInquiryConversion(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "InquiryHandler.GetInquiryConversion")
+	defer finish()
+	log := h.log(r)
+	ctx := r.Context()
+
+	if !h.inquiriesAvailable(w) {
+		return
+	}
+
+	by := r.URL.Query().Get("by")
+	if by == "" {
+		by = ConversionByProperty
+	}
+	if by != ConversionByProperty && by != ConversionByAgent {
+		core.RespondError(w, http.StatusBadRequest, "Invalid query: by must be one of: property, agent")
+		return
+	}
+	since := time.Now().Add(-DefaultConversionPeriod).UTC().Truncate(24 * time.Hour)
+	if param := r.URL.Query().Get("since"); param != "" {
+		day, err := time.Parse(time.DateOnly, param)
+		if err != nil {
+			core.RespondError(w, http.StatusBadRequest, "Invalid query: since must be a date as YYYY-MM-DD")
+			return
+		}
+		since = day
+	}
+
+	counts, err := h.inquiries.Count(ctx, since)
+	if err != nil {
+		log.Error("error counting inquiries", "error", err)
+		core.RespondError(w, http.StatusInternalServerError, "Could not retrieve conversion")
+		return
+	}
+
+	ids := make([]uuid.UUID, 0, len(counts))
+	for _, c := range counts {
+		ids = append(ids, c.PropertyID)
+	}
+	readable, ok := h.readableProperties(w, r, ids)
+	if !ok {
+		return
+	}
+
+	var visible []InquiryCount
+	names := map[string]string{}
+	for _, c := range counts {
+		if p := readable[c.PropertyID]; p != nil {
+			visible = append(visible, c)
+			names[p.ID.String()] = p.Name
+		}
+	}
+	meta := InquiryConversionMeta{By: by, Since: since.Format(time.DateOnly)}
+	if by == ConversionByProperty {
+		meta.Properties = names
+	}
+
+	core.RespondSuccessWithMeta(w, ConvertInquiries(visible, by), meta)
+}
+
+// GetInquiry handles GET /inquiries/{id}
+// Requires PermissionRead on the property of the inquiry.
+func (h *InquiryHandler) GetInquiry(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "InquiryHandler.GetInquiry")
+	defer finish()
+
+	i, ok := h.loadInquiry(w, r, PermissionRead)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("ETag", ETag(i.Revision))
+	core.RespondSuccessWithMeta(w, i, inquiryMeta(i), inquiryLinks(i)...)
+}
+
+// AssignInquiry handles POST /inquiries/{id}/assign
+// Hands an open inquiry to an agent, or unassigns it with an empty
+// agent_id. Requires PermissionWrite on the property of the inquiry; an
+// If-Match header makes it conditional on the inquiry revision.
+func (h *InquiryHandler) AssignInquiry(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "InquiryHandler.AssignInquiry")
+	defer finish()
+	log := h.log(r)
+	ctx := r.Context()
+
+	r.Body = http.MaxBytesReader(w, r.Body, MaxBodyBytes)
+	defer r.Body.Close()
+
+	var req InquiryAssignRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Debug("error decoding assignment", "error", err)
+		core.RespondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	i, ok := h.loadInquiry(w, r, PermissionWrite)
+	if !ok {
+		return
+	}
+	if header := r.Header.Get("If-Match"); header != "" && !IfMatch(header, i.Revision) {
+		w.Header().Set("ETag", ETag(i.Revision))
+		core.RespondError(w, http.StatusPreconditionFailed, "Inquiry was modified, reload and try again")
+		return
+	}
+
+	if err := i.Assign(req.AgentID); err != nil {
+		core.RespondError(w, http.StatusConflict, capitalize(err.Error()))
+		return
+	}
+
+	i.UpdatedBy = requestActor(r)
+	if err := h.inquiries.Save(ctx, i); err != nil {
+		h.respondInquirySaveError(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", ETag(i.Revision))
+	core.RespondSuccessWithMeta(w, i, inquiryMeta(i), inquiryLinks(i)...)
+}
+
+// TransitionInquiry handles POST /inquiries/{id}/transitions
+// Moves an open inquiry along the pipeline, or closes it with the outcome
+// won or lost. Requires PermissionWrite on the property of the inquiry.
+func (h *InquiryHandler) TransitionInquiry(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "InquiryHandler.TransitionInquiry")
+	defer finish()
+	log := h.log(r)
+	ctx := r.Context()
+
+	r.Body = http.MaxBytesReader(w, r.Body, MaxBodyBytes)
+	defer r.Body.Close()
+
+	var req InquiryTransitionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Debug("error decoding transition", "error", err)
+		core.RespondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	req.To = strings.ToLower(strings.TrimSpace(req.To))
+	req.Outcome = strings.ToLower(strings.TrimSpace(req.Outcome))
+
+	i, ok := h.loadInquiry(w, r, PermissionWrite)
+	if !ok {
+		return
+	}
+	if header := r.Header.Get("If-Match"); header != "" && !IfMatch(header, i.Revision) {
+		w.Header().Set("ETag", ETag(i.Revision))
+		core.RespondError(w, http.StatusPreconditionFailed, "Inquiry was modified, reload and try again")
+		return
+	}
+
+	if err := i.Transition(req.To, req.Outcome, time.Now()); err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidStatus):
+			core.RespondError(w, http.StatusBadRequest, "Stage must be one of: "+strings.Join(InquiryStages, ", "))
+		case errors.Is(err, ErrInvalidOutcome):
+			core.RespondError(w, http.StatusBadRequest, capitalize(err.Error()))
+		default:
+			core.RespondError(w, http.StatusConflict, capitalize(err.Error()))
+		}
+		return
+	}
+
+	i.UpdatedBy = requestActor(r)
+	if err := h.inquiries.Save(ctx, i); err != nil {
+		h.respondInquirySaveError(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", ETag(i.Revision))
+	core.RespondSuccessWithMeta(w, i, inquiryMeta(i), inquiryLinks(i)...)
+}
+
+// ListInquiryNotes handles GET /inquiries/{id}/notes
+// The notes of the inquiry, oldest first.
+func (h *InquiryHandler) ListInquiryNotes(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "InquiryHandler.ListInquiryNotes")
+	defer finish()
+	log := h.log(r)
+
+	i, ok := h.loadInquiry(w, r, PermissionRead)
+	if !ok {
+		return
+	}
+
+	notes, err := h.inquiries.ListNotes(r.Context(), i.ID)
+	if err != nil {
+		log.Error("error listing inquiry notes", "error", err, "inquiry_id", i.ID.String())
+		core.RespondError(w, http.StatusInternalServerError, "Could not retrieve notes")
+		return
+	}
+	if notes == nil {
+		notes = []*InquiryNote{}
+	}
+
+	core.RespondSuccess(w, notes, core.Link{Rel: core.RelParent, Href: "/inquiries/" + i.ID.String()})
+}
+
+// AddInquiryNote handles POST /inquiries/{id}/notes
+// Attaches a note to the inquiry, closed ones included. Requires
+// PermissionWrite on the property of the inquiry.
+func (h *InquiryHandler) AddInquiryNote(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "InquiryHandler.AddInquiryNote")
+	defer finish()
+	log := h.log(r)
+
+	r.Body = http.MaxBytesReader(w, r.Body, MaxBodyBytes)
+	defer r.Body.Close()
+
+	var n InquiryNote
+	if err := json.NewDecoder(r.Body).Decode(&n); err != nil {
+		log.Debug("error decoding JSON", "error", err)
+		core.RespondError(w, http.StatusBadRequest, "Invalid JSON payload")
+		return
+	}
+
+	i, ok := h.loadInquiry(w, r, PermissionWrite)
+	if !ok {
+		return
+	}
+
+	n = InquiryNote{InquiryID: i.ID, Body: n.Body, CreatedBy: requestActor(r)}
+	n.Normalize()
+	if validationErrors := n.Validate(); len(validationErrors) > 0 {
+		log.Debug("validation failed", "errors", validationErrors)
+		core.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Validation failed: %s", validationErrors[0].Message))
+		return
+	}
+
+	if err := h.inquiries.AddNote(r.Context(), &n); err != nil {
+		h.respondInquirySaveError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	core.RespondSuccess(w, &n, core.Link{Rel: core.RelCollection, Href: "/inquiries/" + i.ID.String() + "/notes"})
+}
+
+// loadInquiry loads the inquiry of the request and checks the user holds
+// the permission on its property, responding with the error when it
+// returns false. Inquiries about properties the user cannot read, or that
+// no longer exist, are reported as not found.
+func (h *InquiryHandler) loadInquiry(w http.ResponseWriter, r *http.Request, permission string) (*Inquiry, bool) {
+	log := h.log(r)
+	if !h.inquiriesAvailable(w) {
+		return nil, false
+	}
+
+	id, ok := h.parseIDParam(w, r, log)
+	if !ok {
+		return nil, false
+	}
+
+	i, err := h.inquiries.Get(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, ErrInquiryNotFound) {
+			core.RespondError(w, http.StatusNotFound, "Inquiry not found")
+			return nil, false
+		}
+		log.Error("error loading inquiry", "error", err, "id", id.String())
+		core.RespondError(w, http.StatusInternalServerError, "Could not retrieve inquiry")
+		return nil, false
+	}
+
+	if !h.authorizePropertyOf(w, r, i.PropertyID, permission, "Inquiry not found") {
+		return nil, false
+	}
+	return i, true
+}
+
+// respondInquirySaveError responds to a failed inquiry write.
+func (h *InquiryHandler) respondInquirySaveError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, ErrInquiryNotFound):
+		core.RespondError(w, http.StatusNotFound, "Inquiry not found")
+	case errors.Is(err, ErrRevisionConflict) && r.Header.Get("If-Match") != "":
+		core.RespondError(w, http.StatusPreconditionFailed, "Inquiry was modified, reload and try again")
+	case errors.Is(err, ErrRevisionConflict):
+		core.RespondError(w, http.StatusConflict, "Inquiry was modified concurrently, try again")
+	default:
+		h.log(r).Error("cannot save inquiry", "error", err)
+		core.RespondError(w, http.StatusInternalServerError, "Could not save inquiry")
+	}
+}
+
+// inquiriesAvailable responds 503 and returns false when no encryption
+// key is configured.
+func (h *InquiryHandler) inquiriesAvailable(w http.ResponseWriter) bool {
+	if h.inquiries == nil {
+		core.RespondError(w, http.StatusServiceUnavailable, "Inquiries are not available")
+		return false
+	}
+	return true
+}
+
+// respondInquiryInvalid responds 400 and returns false if the inquiry does
+// not validate.
+func respondInquiryInvalid(w http.ResponseWriter, i *Inquiry, log core.Logger) bool {
+	validationErrors := i.Validate()
+	if len(validationErrors) == 0 {
+		return true
+	}
+	log.Debug("validation failed", "errors", validationErrors)
+	core.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Validation failed: %s", validationErrors[0].Message))
+	return false
+}
+
+func respondInquiryReceived(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusAccepted)
+	core.RespondSuccess(w, InquiryReceipt{Status: "received"})
+}
+
+// clientAddress returns the IP address of the client of a request. Behind a
+// trusted proxy the router already took it from the forwarding headers (see
+// server.trusted_proxies); the headers of anyone else are ignored.
+func clientAddress(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+func inquiryMeta(i *Inquiry) InquiryMeta {
+	return InquiryMeta{Allowed: AllowedInquiryStages(i.Stage)}
+}
+
+// inquiryLinks links an inquiry, its notes and the inquiries about its
+// property.
+func inquiryLinks(i *Inquiry) []core.Link {
+	self := "/inquiries/" + i.ID.String()
+	return []core.Link{
+		{Rel: core.RelSelf, Href: self},
+		{Rel: "notes", Href: self + "/notes"},
+		{Rel: core.RelCollection, Href: "/estates/" + i.PropertyID.String() + "/inquiries"},
+	}
+}
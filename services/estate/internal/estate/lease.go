@@ -0,0 +1,372 @@
+package estate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/pkg/lib/core"
+	"github.com/pulap/pulap/services/estate/internal/money"
+)
+
+// Lease statuses
+const (
+	LeaseActive     = "active"
+	LeaseTerminated = "terminated" // Ended before its end date, see TerminatedOn
+)
+
+// LeaseStatuses lists every lease status.
+var LeaseStatuses = []string{LeaseActive, LeaseTerminated}
+
+// Indexation methods
+const (
+	IndexationNone  = "none"
+	IndexationIndex = "index" // Follows a published index, e.g. a CPI
+	IndexationFixed = "fixed" // Rises by a fixed rate
+)
+
+// IndexationMethods lists every indexation method.
+var IndexationMethods = []string{IndexationNone, IndexationIndex, IndexationFixed}
+
+// DefaultIndexationInterval is the months between rent reviews when an
+// indexed lease does not set it.
+const DefaultIndexationInterval = 12
+
+// DefaultExpiringDays and MaxExpiringDays bound how far ahead the expiring
+// leases report looks.
+const (
+	DefaultExpiringDays = 60
+	MaxExpiringDays     = 366
+)
+
+// RentFrequencies are the payment frequencies of a lease, the rent price
+// types.
+var RentFrequencies = slices.DeleteFunc(slices.Clone(PriceTypes), func(t string) bool {
+	return !strings.HasPrefix(t, "rent_")
+})
+
+var (
+	// ErrLeaseNotFound is returned when a lease does not exist.
+	ErrLeaseNotFound = errors.New("lease not found")
+
+	// ErrLeaseOverlap is returned when a lease overlaps another of the same
+	// property.
+	ErrLeaseOverlap = errors.New("lease overlaps another lease of the property")
+
+	// ErrLeaseTerminated is returned when terminating a terminated lease.
+	ErrLeaseTerminated = errors.New("lease is already terminated")
+
+	// ErrInvalidTermination is returned when a termination date is not
+	// valid for the lease.
+	ErrInvalidTermination = errors.New("invalid termination")
+)
+
+// Lease is the tenancy of a property: who rents it, for how long and on
+// which terms. Dates are YYYY-MM-DD days, both ends included.
+type Lease struct {
+	ID                uuid.UUID     `json:"id"`
+	PropertyID        uuid.UUID     `json:"property_id"`
+	Tenant            Contact       `json:"tenant"`
+	StartDate         string        `json:"start_date"` // First day of the tenancy
+	EndDate           string        `json:"end_date"`   // Last day of the tenancy as agreed
+	Rent              money.Decimal `json:"rent"`
+	Currency          string        `json:"currency"`
+	Frequency         string        `json:"frequency"` // One of RentFrequencies
+	Deposit           money.Decimal `json:"deposit"`
+	Indexation        Indexation    `json:"indexation"`
+	Renewal           Renewal       `json:"renewal"`
+	Status            string        `json:"status"`                  // One of LeaseStatuses
+	TerminatedOn      string        `json:"terminated_on,omitempty"` // Last day of a terminated lease
+	TerminationReason string        `json:"termination_reason,omitempty"`
+	Revision          int64         `json:"revision"` // Incremented on every write, exposed as the ETag
+	CreatedAt         time.Time     `json:"created_at"`
+	CreatedBy         string        `json:"created_by"`
+	UpdatedAt         time.Time     `json:"updated_at"`
+	UpdatedBy         string        `json:"updated_by"`
+}
+
+// Indexation is how the rent of a lease is reviewed.
+type Indexation struct {
+	Method         string  `json:"method"`                    // One of IndexationMethods
+	Index          string  `json:"index,omitempty"`           // Index followed, e.g. "CPI", for the index method
+	Rate           float64 `json:"rate,omitempty"`            // Percent per review for the fixed method
+	Cap            float64 `json:"cap,omitempty"`             // Maximum percent per review, 0 for none
+	IntervalMonths int     `json:"interval_months,omitempty"` // Months between reviews
+}
+
+// Renewal is what happens when a lease reaches its end date.
+type Renewal struct {
+	Automatic   bool `json:"automatic"`              // Renews unless notice is given
+	TermMonths  int  `json:"term_months,omitempty"`  // Length of each renewal
+	NoticeDays  int  `json:"notice_days,omitempty"`  // Notice required before the end date not to renew
+	MaxRenewals int  `json:"max_renewals,omitempty"` // 0 for no limit
+}
+
+// LeaseExpiry is an entry of the expiring leases report.
+type LeaseExpiry struct {
+	*Lease
+	DaysLeft int    `json:"days_left"`
+	NoticeBy string `json:"notice_by,omitempty"` // Last day to give notice, when the lease requires it
+}
+
+// GetID returns the ID of the Lease (implements Identifiable interface).
+func (l *Lease) GetID() uuid.UUID {
+	return l.ID
+}
+
+// ResourceType returns the resource type for URL generation.
+func (l *Lease) ResourceType() string {
+	return "lease"
+}
+
+// BeforeCreate sets the ID, timestamps and first revision.
+func (l *Lease) BeforeCreate() {
+	if l.ID == uuid.Nil {
+		l.ID = core.GenerateNewID()
+	}
+	if l.Status == "" {
+		l.Status = LeaseActive
+	}
+	l.CreatedAt = time.Now()
+	l.UpdatedAt = l.CreatedAt
+	l.Revision = 1
+}
+
+// BeforeUpdate sets the update timestamp.
+func (l *Lease) BeforeUpdate() {
+	l.UpdatedAt = time.Now()
+}
+
+// Normalize trims the lease and fills the indexation defaults.
+func (l *Lease) Normalize() {
+	l.Tenant.Normalize()
+	l.StartDate = strings.TrimSpace(l.StartDate)
+	l.EndDate = strings.TrimSpace(l.EndDate)
+	l.Currency = strings.ToUpper(strings.TrimSpace(l.Currency))
+	l.Frequency = strings.ToLower(strings.TrimSpace(l.Frequency))
+
+	ix := &l.Indexation
+	ix.Method = strings.ToLower(strings.TrimSpace(ix.Method))
+	ix.Index = strings.TrimSpace(ix.Index)
+	if ix.Method == "" {
+		ix.Method = IndexationNone
+	}
+	if ix.Method != IndexationNone && ix.IntervalMonths == 0 {
+		ix.IntervalMonths = DefaultIndexationInterval
+	}
+}
+
+// Validate checks the lease before it is stored.
+func (l *Lease) Validate() []ValidationError {
+	var errors []ValidationError
+
+	if l.PropertyID == uuid.Nil {
+		errors = append(errors, ValidationError{Field: "property_id", Message: "property_id is required"})
+	}
+	errors = append(errors, l.Tenant.Validate("tenant")...)
+
+	start, startErr := time.Parse(time.DateOnly, l.StartDate)
+	if startErr != nil {
+		errors = append(errors, ValidationError{Field: "start_date", Message: "start_date must be a YYYY-MM-DD date"})
+	}
+	end, endErr := time.Parse(time.DateOnly, l.EndDate)
+	if endErr != nil {
+		errors = append(errors, ValidationError{Field: "end_date", Message: "end_date must be a YYYY-MM-DD date"})
+	}
+	if startErr == nil && endErr == nil && end.Before(start) {
+		errors = append(errors, ValidationError{Field: "end_date", Message: "end_date cannot be before start_date"})
+	}
+
+	if l.Rent.Sign() <= 0 {
+		errors = append(errors, ValidationError{Field: "rent", Message: "rent must be greater than 0"})
+	}
+	if l.Deposit.Sign() < 0 {
+		errors = append(errors, ValidationError{Field: "deposit", Message: "deposit cannot be negative"})
+	}
+	if l.Rent.Digits() > money.MaxStoredDigits || l.Deposit.Digits() > money.MaxStoredDigits {
+		errors = append(errors, ValidationError{Field: "rent", Message: fmt.Sprintf("rent and deposit cannot have more than %d digits", money.MaxStoredDigits)})
+	}
+	if currency, err := money.LookupCurrency(l.Currency); err != nil {
+		errors = append(errors, ValidationError{Field: "currency", Message: "currency must be an ISO 4217 code"})
+	} else if !currency.Fits(l.Rent) || !currency.Fits(l.Deposit) {
+		errors = append(errors, ValidationError{Field: "rent", Message: fmt.Sprintf("rent and deposit cannot have more than %d decimals in %s", currency.Minor, l.Currency)})
+	}
+	if !slices.Contains(RentFrequencies, l.Frequency) {
+		errors = append(errors, ValidationError{Field: "frequency", Message: "frequency must be one of: " + strings.Join(RentFrequencies, ", ")})
+	}
+
+	errors = append(errors, l.Indexation.validate()...)
+	errors = append(errors, l.Renewal.validate()...)
+
+	if !slices.Contains(LeaseStatuses, l.Status) {
+		errors = append(errors, ValidationError{Field: "status", Message: "status must be one of: " + strings.Join(LeaseStatuses, ", ")})
+	}
+
+	return errors
+}
+
+func (ix Indexation) validate() []ValidationError {
+	var errors []ValidationError
+
+	switch ix.Method {
+	case IndexationNone:
+		return nil
+	case IndexationIndex:
+		if ix.Index == "" {
+			errors = append(errors, ValidationError{Field: "indexation", Message: "indexation.index is required for the index method"})
+		}
+	case IndexationFixed:
+		if ix.Rate == 0 {
+			errors = append(errors, ValidationError{Field: "indexation", Message: "indexation.rate is required for the fixed method"})
+		}
+	default:
+		return []ValidationError{{Field: "indexation", Message: "indexation.method must be one of: " + strings.Join(IndexationMethods, ", ")}}
+	}
+
+	if ix.Cap < 0 {
+		errors = append(errors, ValidationError{Field: "indexation", Message: "indexation.cap cannot be negative"})
+	}
+	if ix.IntervalMonths < 1 || ix.IntervalMonths > 120 {
+		errors = append(errors, ValidationError{Field: "indexation", Message: "indexation.interval_months must be between 1 and 120"})
+	}
+	return errors
+}
+
+func (r Renewal) validate() []ValidationError {
+	var errors []ValidationError
+
+	if r.TermMonths < 0 || r.NoticeDays < 0 || r.MaxRenewals < 0 {
+		errors = append(errors, ValidationError{Field: "renewal", Message: "renewal terms cannot be negative"})
+	}
+	if r.Automatic && r.TermMonths == 0 {
+		errors = append(errors, ValidationError{Field: "renewal", Message: "renewal.term_months is required for automatic renewals"})
+	}
+	return errors
+}
+
+// LastDay returns the last day the lease runs: its termination date when
+// terminated early, otherwise its end date.
+func (l *Lease) LastDay() string {
+	if l.TerminatedOn != "" && l.TerminatedOn < l.EndDate {
+		return l.TerminatedOn
+	}
+	return l.EndDate
+}
+
+// Void returns true if the lease was terminated before it started.
+func (l *Lease) Void() bool {
+	return l.LastDay() < l.StartDate
+}
+
+// Overlaps returns true if both leases run on a common day. Void leases
+// overlap nothing.
+func (l *Lease) Overlaps(other *Lease) bool {
+	if l.Void() || other.Void() {
+		return false
+	}
+	return l.StartDate <= other.LastDay() && other.StartDate <= l.LastDay()
+}
+
+// Terminate ends the lease after the given day, which cannot be after its
+// end date. Terminating before the start date voids the lease.
+func (l *Lease) Terminate(on, reason string) error {
+	if l.Status == LeaseTerminated {
+		return ErrLeaseTerminated
+	}
+	if _, err := time.Parse(time.DateOnly, on); err != nil {
+		return fmt.Errorf("%w: terminated_on must be a YYYY-MM-DD date", ErrInvalidTermination)
+	}
+	if on > l.EndDate {
+		return fmt.Errorf("%w: terminated_on cannot be after end_date %s", ErrInvalidTermination, l.EndDate)
+	}
+
+	l.Status = LeaseTerminated
+	l.TerminatedOn = on
+	l.TerminationReason = strings.TrimSpace(reason)
+	return nil
+}
+
+// Expiry reports how many days are left until the lease ends, counted from
+// a day, and when notice must be given not to renew it.
+func (l *Lease) Expiry(today time.Time) LeaseExpiry {
+	e := LeaseExpiry{Lease: l}
+	end, err := time.Parse(time.DateOnly, l.EndDate)
+	if err != nil {
+		return e
+	}
+	day := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	e.DaysLeft = int(end.Sub(day).Hours() / 24)
+	if l.Renewal.NoticeDays > 0 {
+		e.NoticeBy = end.AddDate(0, 0, -l.Renewal.NoticeDays).Format(time.DateOnly)
+	}
+	return e
+}
+
+// LeaseQuery filters the leases listed. Zero values mean "no filter".
+type LeaseQuery struct {
+	PropertyIDs []uuid.UUID
+	Statuses    []string
+	Limit       int
+}
+
+// Normalize fills defaults and validates the query.
+func (q *LeaseQuery) Normalize() []ValidationError {
+	var errors []ValidationError
+	if q.Limit <= 0 {
+		q.Limit = DefaultSearchLimit
+	}
+	if q.Limit > MaxSearchLimit {
+		q.Limit = MaxSearchLimit
+	}
+	for _, s := range q.Statuses {
+		if !slices.Contains(LeaseStatuses, s) {
+			errors = append(errors, ValidationError{Field: "status", Message: "status must be one of: " + strings.Join(LeaseStatuses, ", ")})
+			break
+		}
+	}
+	return errors
+}
+
+// ParseLeaseQuery reads a LeaseQuery from URL parameters: status takes a
+// comma-separated list.
+func ParseLeaseQuery(values url.Values) (LeaseQuery, []ValidationError) {
+	p := queryParser{values: values}
+	q := LeaseQuery{
+		Statuses: splitList(values.Get("status")),
+		Limit:    p.int("limit"),
+	}
+	errors := p.errors
+	errors = append(errors, q.Normalize()...)
+	return q, errors
+}
+
+// LeaseRepo defines the repository interface for Lease aggregates.
+type LeaseRepo interface {
+	// CreateLease stores a new lease unless it overlaps another lease of
+	// the property, returning ErrLeaseOverlap. Concurrent creations of
+	// overlapping leases may all fail, but never all succeed.
+	CreateLease(ctx context.Context, l *Lease) error
+
+	// GetLease retrieves a lease, or ErrLeaseNotFound.
+	GetLease(ctx context.Context, id uuid.UUID) (*Lease, error)
+
+	// SaveLease updates a lease while its stored revision is still
+	// l.Revision, which is then incremented. It returns ErrLeaseNotFound or
+	// ErrRevisionConflict. Dates are not checked for overlaps, so a save may
+	// only shorten a lease.
+	SaveLease(ctx context.Context, l *Lease) error
+
+	// ListLeases lists the leases matching the query, latest start first.
+	// The query is expected to be normalized.
+	ListLeases(ctx context.Context, query LeaseQuery) ([]*Lease, error)
+
+	// ListExpiringLeases lists the active leases ending between two days,
+	// both included, soonest first.
+	ListExpiringLeases(ctx context.Context, from, to string, limit int) ([]*Lease, error)
+}
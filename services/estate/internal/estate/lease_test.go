@@ -0,0 +1,174 @@
+package estate
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/services/estate/internal/money"
+)
+
+func newTestLease(start, end string) *Lease {
+	return &Lease{
+		PropertyID: uuid.New(),
+		Tenant:     Contact{Name: "Lucía Ferrer", Email: "lucia@example.com"},
+		StartDate:  start,
+		EndDate:    end,
+		Rent:       money.MustParse("950"),
+		Currency:   "EUR",
+		Frequency:  "rent_monthly",
+		Deposit:    money.MustParse("1900"),
+		Status:     LeaseActive,
+	}
+}
+
+func TestLeaseNormalizeAndValidate(t *testing.T) {
+	l := newTestLease(" 2026-01-01 ", "2026-12-31")
+	l.Tenant = Contact{Name: " Lucía Ferrer ", Email: " Lucia@Example.com "}
+	l.Currency, l.Frequency = "eur", "Rent_Monthly"
+	l.Indexation = Indexation{Method: "Index", Index: " CPI "}
+	l.Normalize()
+
+	if l.StartDate != "2026-01-01" || l.Currency != "EUR" || l.Frequency != "rent_monthly" {
+		t.Errorf("unexpected normalized lease: %q %q %q", l.StartDate, l.Currency, l.Frequency)
+	}
+	if l.Tenant.Name != "Lucía Ferrer" || l.Tenant.Email != "lucia@example.com" {
+		t.Errorf("unexpected normalized tenant: %+v", l.Tenant)
+	}
+	if l.Indexation != (Indexation{Method: IndexationIndex, Index: "CPI", IntervalMonths: DefaultIndexationInterval}) {
+		t.Errorf("unexpected normalized indexation: %+v", l.Indexation)
+	}
+	if errs := l.Validate(); len(errs) > 0 {
+		t.Fatalf("expected a valid lease, got %v", errs)
+	}
+
+	tests := []struct {
+		name   string
+		change func(l *Lease)
+		field  string
+	}{
+		{"tenant contact", func(l *Lease) { l.Tenant.Email = "" }, "tenant"},
+		{"tenant email", func(l *Lease) { l.Tenant.Email = "not an email" }, "tenant"},
+		{"start date", func(l *Lease) { l.StartDate = "01/01/2026" }, "start_date"},
+		{"end before start", func(l *Lease) { l.EndDate = "2025-12-31" }, "end_date"},
+		{"rent", func(l *Lease) { l.Rent = money.MustParse("0") }, "rent"},
+		{"deposit", func(l *Lease) { l.Deposit = money.MustParse("-1") }, "deposit"},
+		{"currency", func(l *Lease) { l.Currency = "EURO" }, "currency"},
+		{"decimals", func(l *Lease) { l.Rent = money.MustParse("950.125") }, "rent"},
+		{"sale frequency", func(l *Lease) { l.Frequency = "sale" }, "frequency"},
+		{"indexation method", func(l *Lease) { l.Indexation.Method = "cpi" }, "indexation"},
+		{"index", func(l *Lease) { l.Indexation.Index = "" }, "indexation"},
+		{"fixed rate", func(l *Lease) { l.Indexation = Indexation{Method: IndexationFixed, IntervalMonths: 12} }, "indexation"},
+		{"renewal term", func(l *Lease) { l.Renewal = Renewal{Automatic: true} }, "renewal"},
+		{"renewal notice", func(l *Lease) { l.Renewal = Renewal{NoticeDays: -1} }, "renewal"},
+		{"status", func(l *Lease) { l.Status = "expired" }, "status"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			invalid := *l
+			tt.change(&invalid)
+			errs := invalid.Validate()
+			if len(errs) != 1 || errs[0].Field != tt.field {
+				t.Errorf("expected one %s error, got %v", tt.field, errs)
+			}
+		})
+	}
+}
+
+func TestLeaseOverlaps(t *testing.T) {
+	year := newTestLease("2026-01-01", "2026-12-31")
+
+	tests := []struct {
+		name       string
+		start, end string
+		terminated string
+		want       bool
+	}{
+		{"inside", "2026-03-01", "2026-03-31", "", true},
+		{"sharing the last day", "2026-12-31", "2027-12-31", "", true},
+		{"sharing the first day", "2025-01-01", "2026-01-01", "", true},
+		{"after", "2027-01-01", "2027-12-31", "", false},
+		{"terminated before", "2025-01-01", "2026-12-31", "2025-12-31", false},
+		{"terminated after the first day", "2025-01-01", "2026-12-31", "2026-01-01", true},
+		{"void", "2026-03-01", "2026-03-31", "2026-02-28", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			other := newTestLease(tt.start, tt.end)
+			other.TerminatedOn = tt.terminated
+			if got := year.Overlaps(other); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+			if got := other.Overlaps(year); got != tt.want {
+				t.Errorf("expected a symmetric %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestLeaseTerminate(t *testing.T) {
+	l := newTestLease("2026-01-01", "2026-12-31")
+
+	if err := l.Terminate("2027-01-31", ""); !errors.Is(err, ErrInvalidTermination) {
+		t.Errorf("expected ErrInvalidTermination after the end date, got %v", err)
+	}
+	if err := l.Terminate("30/06/2026", ""); !errors.Is(err, ErrInvalidTermination) {
+		t.Errorf("expected ErrInvalidTermination for a malformed date, got %v", err)
+	}
+	if l.Status != LeaseActive {
+		t.Fatalf("expected a failed termination to keep the lease active, got %q", l.Status)
+	}
+
+	if err := l.Terminate("2026-06-30", " moved abroad "); err != nil {
+		t.Fatalf("Terminate: %v", err)
+	}
+	if l.Status != LeaseTerminated || l.LastDay() != "2026-06-30" || l.TerminationReason != "moved abroad" || l.Void() {
+		t.Errorf("unexpected terminated lease: %+v", l)
+	}
+	if err := l.Terminate("2026-05-31", ""); !errors.Is(err, ErrLeaseTerminated) {
+		t.Errorf("expected ErrLeaseTerminated, got %v", err)
+	}
+
+	early := newTestLease("2026-01-01", "2026-12-31")
+	if err := early.Terminate("2025-12-15", ""); err != nil {
+		t.Fatalf("Terminate: %v", err)
+	}
+	if !early.Void() {
+		t.Errorf("expected a termination before the start to void the lease")
+	}
+}
+
+func TestLeaseExpiry(t *testing.T) {
+	l := newTestLease("2025-04-01", "2026-03-31")
+	l.Renewal = Renewal{Automatic: true, TermMonths: 12, NoticeDays: 30}
+
+	e := l.Expiry(time.Date(2026, 3, 1, 18, 30, 0, 0, time.UTC))
+	if e.DaysLeft != 30 || e.NoticeBy != "2026-03-01" {
+		t.Errorf("expected 30 days left and notice by 2026-03-01, got %d and %q", e.DaysLeft, e.NoticeBy)
+	}
+
+	l.Renewal = Renewal{}
+	if e := l.Expiry(time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)); e.DaysLeft != 0 || e.NoticeBy != "" {
+		t.Errorf("expected no days left and no notice, got %d and %q", e.DaysLeft, e.NoticeBy)
+	}
+}
+
+func TestParseLeaseQuery(t *testing.T) {
+	q, errs := ParseLeaseQuery(url.Values{"status": {"active,terminated"}, "limit": {"500"}})
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if len(q.Statuses) != 2 || q.Limit != MaxSearchLimit {
+		t.Errorf("unexpected query: %+v", q)
+	}
+
+	if _, errs := ParseLeaseQuery(url.Values{"status": {"expired"}}); len(errs) != 1 || errs[0].Field != "status" {
+		t.Errorf("expected a status error, got %v", errs)
+	}
+	if _, errs := ParseLeaseQuery(url.Values{"limit": {"ten"}}); len(errs) != 1 || errs[0].Field != "limit" {
+		t.Errorf("expected a limit error, got %v", errs)
+	}
+}
@@ -0,0 +1,339 @@
+package estate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/pkg/lib/core"
+	"github.com/pulap/pulap/pkg/lib/telemetry"
+	"github.com/pulap/pulap/services/estate/internal/config"
+)
+
+// LeaseHandler handles HTTP requests for the Lease aggregate.
+type LeaseHandler struct {
+	*PropertyAccess
+	leases LeaseRepo
+	tlm    *telemetry.HTTP
+}
+
+// NewLeaseHandler creates a new LeaseHandler for the leases in leases.
+func NewLeaseHandler(leases LeaseRepo, access *PropertyAccess, xparams config.XParams) *LeaseHandler {
+	return &LeaseHandler{
+		PropertyAccess: access,
+		leases:         leases,
+		tlm: telemetry.NewHTTP(
+			telemetry.WithTracer(xparams.Tracer()),
+			telemetry.WithMetrics(xparams.Metrics()),
+		),
+	}
+}
+
+// RegisterRoutes registers the lease routes.
+func (h *LeaseHandler) RegisterRoutes(r chi.Router) {
+	authn := h.authn()
+
+	r.With(authn).Get("/estates/{id}/leases", h.ListPropertyLeases)
+	r.With(authn).Post("/estates/{id}/leases", h.CreateLease)
+	r.Route("/leases", func(r chi.Router) {
+		r.Use(authn)
+		r.Get("/expiring", h.ListExpiringLeases)
+		r.Get("/{id}", h.GetLease)
+		r.Post("/{id}/terminate", h.TerminateLease)
+	})
+}
+
+// LeaseTerminationRequest is the payload of POST /leases/{id}/terminate.
+type LeaseTerminationRequest struct {
+	On     string `json:"on"` // Last day of the tenancy, today when empty
+	Reason string `json:"reason"`
+}
+
+// LeaseListMeta describes the leases of a property.
+type LeaseListMeta struct {
+	Count int `json:"count"`
+	Limit int `json:"limit"`
+}
+
+// ExpiringLeasesMeta describes the expiring leases report.
+type ExpiringLeasesMeta struct {
+	Count int    `json:"count"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// CreateLease handles POST /estates/{id}/leases
+// Leases start active and cannot overlap another lease of the property.
+// Requires PermissionWrite on the property.
+func (h *LeaseHandler) CreateLease(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "LeaseHandler.CreateLease")
+	defer finish()
+	log := h.log(r)
+	ctx := r.Context()
+
+	property, ok := h.leaseProperty(w, r, PermissionWrite)
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, MaxBodyBytes)
+	defer r.Body.Close()
+
+	var l Lease
+	if err := json.NewDecoder(r.Body).Decode(&l); err != nil {
+		log.Debug("error decoding JSON", "error", err)
+		core.RespondError(w, http.StatusBadRequest, "Invalid JSON payload")
+		return
+	}
+
+	l.ID = uuid.Nil
+	l.PropertyID = property.ID
+	l.Status = LeaseActive
+	l.TerminatedOn, l.TerminationReason = "", ""
+	l.Normalize()
+	if validationErrors := l.Validate(); len(validationErrors) > 0 {
+		log.Debug("validation failed", "errors", validationErrors)
+		core.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Validation failed: %s", validationErrors[0].Message))
+		return
+	}
+
+	l.CreatedBy = requestActor(r)
+	l.UpdatedBy = l.CreatedBy
+	if err := h.leases.CreateLease(ctx, &l); err != nil {
+		switch {
+		case errors.Is(err, ErrLeaseOverlap):
+			core.RespondError(w, http.StatusConflict, capitalize(err.Error()))
+		default:
+			log.Error("cannot create lease", "error", err, "property_id", property.ID.String())
+			core.RespondError(w, http.StatusInternalServerError, "Could not create lease")
+		}
+		return
+	}
+
+	w.Header().Set("ETag", ETag(l.Revision))
+	w.WriteHeader(http.StatusCreated)
+	core.RespondSuccess(w, &l, leaseLinks(&l)...)
+}
+
+// ListPropertyLeases handles GET /estates/{id}/leases
+// Every lease of the property is listed, latest start first, terminated
+// ones included. status filters by a comma-separated list.
+func (h *LeaseHandler) ListPropertyLeases(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "LeaseHandler.ListPropertyLeases")
+	defer finish()
+	log := h.log(r)
+	ctx := r.Context()
+
+	property, ok := h.leaseProperty(w, r, PermissionRead)
+	if !ok {
+		return
+	}
+
+	query, validationErrors := ParseLeaseQuery(r.URL.Query())
+	if len(validationErrors) > 0 {
+		log.Debug("invalid lease query", "errors", validationErrors)
+		core.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid query: %s", validationErrors[0].Message))
+		return
+	}
+	query.PropertyIDs = []uuid.UUID{property.ID}
+
+	leases, err := h.leases.ListLeases(ctx, query)
+	if err != nil {
+		log.Error("error listing leases", "error", err, "property_id", property.ID.String())
+		core.RespondError(w, http.StatusInternalServerError, "Could not retrieve leases")
+		return
+	}
+	if leases == nil {
+		leases = []*Lease{}
+	}
+
+	core.RespondSuccessWithMeta(w, leases, LeaseListMeta{Count: len(leases), Limit: query.Limit})
+}
+
+// ListExpiringLeases handles GET /leases/expiring
+// Reports the active leases of readable properties ending from today to
+// within_days days ahead (60 by default, at most 366), soonest first, with
+// the days left and the last day to give notice so agents can follow up.
+// limit caps the leases considered before access is checked.
+func (h *LeaseHandler) ListExpiringLeases(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "LeaseHandler.ListExpiringLeases")
+	defer finish()
+	log := h.log(r)
+	ctx := r.Context()
+
+	p := queryParser{values: r.URL.Query()}
+	days := p.int("within_days")
+	limit := p.int("limit")
+	if len(p.errors) > 0 {
+		core.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid query: %s", p.errors[0].Message))
+		return
+	}
+	if days <= 0 {
+		days = DefaultExpiringDays
+	}
+	if days > MaxExpiringDays {
+		core.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid query: within_days cannot be greater than %d", MaxExpiringDays))
+		return
+	}
+	if limit <= 0 {
+		limit = DefaultSearchLimit
+	}
+	limit = min(limit, MaxSearchLimit)
+
+	now := time.Now()
+	from := now.Format(time.DateOnly)
+	to := now.AddDate(0, 0, days).Format(time.DateOnly)
+
+	leases, err := h.leases.ListExpiringLeases(ctx, from, to, limit)
+	if err != nil {
+		log.Error("error listing expiring leases", "error", err)
+		core.RespondError(w, http.StatusInternalServerError, "Could not retrieve leases")
+		return
+	}
+
+	ids := make([]uuid.UUID, 0, len(leases))
+	for _, l := range leases {
+		ids = append(ids, l.PropertyID)
+	}
+	readable, ok := h.readableProperties(w, r, ids)
+	if !ok {
+		return
+	}
+
+	report := []LeaseExpiry{}
+	for _, l := range leases {
+		if readable[l.PropertyID] != nil {
+			report = append(report, l.Expiry(now))
+		}
+	}
+
+	core.RespondSuccessWithMeta(w, report, ExpiringLeasesMeta{Count: len(report), From: from, To: to})
+}
+
+// GetLease handles GET /leases/{id}
+// Requires PermissionRead on the leased property.
+func (h *LeaseHandler) GetLease(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "LeaseHandler.GetLease")
+	defer finish()
+
+	l, ok := h.loadLease(w, r, PermissionRead)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("ETag", ETag(l.Revision))
+	core.RespondSuccess(w, l, leaseLinks(l)...)
+}
+
+// TerminateLease handles POST /leases/{id}/terminate
+// Ends an active lease after the given day, today by default, freeing the
+// property from the next day on. A termination before the start date voids
+// the lease. An If-Match header makes it conditional on the lease revision.
+func (h *LeaseHandler) TerminateLease(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "LeaseHandler.TerminateLease")
+	defer finish()
+	log := h.log(r)
+	ctx := r.Context()
+
+	r.Body = http.MaxBytesReader(w, r.Body, MaxBodyBytes)
+	defer r.Body.Close()
+
+	var req LeaseTerminationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Debug("error decoding termination", "error", err)
+		core.RespondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if req.On == "" {
+		req.On = time.Now().Format(time.DateOnly)
+	}
+
+	l, ok := h.loadLease(w, r, PermissionWrite)
+	if !ok {
+		return
+	}
+	if header := r.Header.Get("If-Match"); header != "" && !IfMatch(header, l.Revision) {
+		w.Header().Set("ETag", ETag(l.Revision))
+		core.RespondError(w, http.StatusPreconditionFailed, "Lease was modified, reload and try again")
+		return
+	}
+
+	if err := l.Terminate(req.On, req.Reason); err != nil {
+		if errors.Is(err, ErrInvalidTermination) {
+			core.RespondError(w, http.StatusBadRequest, capitalize(err.Error()))
+			return
+		}
+		core.RespondError(w, http.StatusConflict, capitalize(err.Error()))
+		return
+	}
+
+	l.UpdatedBy = requestActor(r)
+	if err := h.leases.SaveLease(ctx, l); err != nil {
+		switch {
+		case errors.Is(err, ErrLeaseNotFound):
+			core.RespondError(w, http.StatusNotFound, "Lease not found")
+		case errors.Is(err, ErrRevisionConflict) && r.Header.Get("If-Match") != "":
+			core.RespondError(w, http.StatusPreconditionFailed, "Lease was modified, reload and try again")
+		case errors.Is(err, ErrRevisionConflict):
+			core.RespondError(w, http.StatusConflict, "Lease was modified concurrently, reload and try again")
+		default:
+			log.Error("cannot save lease", "error", err)
+			core.RespondError(w, http.StatusInternalServerError, "Could not terminate lease")
+		}
+		return
+	}
+
+	w.Header().Set("ETag", ETag(l.Revision))
+	core.RespondSuccess(w, l, leaseLinks(l)...)
+}
+
+// leaseProperty loads the property of the request and checks the user
+// holds the permission on it, responding with the error when it returns
+// false.
+func (h *LeaseHandler) leaseProperty(w http.ResponseWriter, r *http.Request, permission string) (*Property, bool) {
+	return h.loadProperty(w, r, permission)
+}
+
+// loadLease loads the lease of the request and checks the user holds the
+// permission on its property, responding with the error when it returns
+// false. Leases of properties the user cannot read, or that no longer
+// exist, are reported as not found.
+func (h *LeaseHandler) loadLease(w http.ResponseWriter, r *http.Request, permission string) (*Lease, bool) {
+	log := h.log(r)
+
+	id, ok := h.parseIDParam(w, r, log)
+	if !ok {
+		return nil, false
+	}
+
+	l, err := h.leases.GetLease(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, ErrLeaseNotFound) {
+			core.RespondError(w, http.StatusNotFound, "Lease not found")
+			return nil, false
+		}
+		log.Error("error loading lease", "error", err, "id", id.String())
+		core.RespondError(w, http.StatusInternalServerError, "Could not retrieve lease")
+		return nil, false
+	}
+
+	if !h.authorizePropertyOf(w, r, l.PropertyID, permission, "Lease not found") {
+		return nil, false
+	}
+	return l, true
+}
+
+// leaseLinks links a lease and the leases of its property, as leases have
+// no collection of their own.
+func leaseLinks(l *Lease) []core.Link {
+	self := "/leases/" + l.ID.String()
+	return []core.Link{
+		{Rel: core.RelSelf, Href: self},
+		{Rel: core.RelCollection, Href: "/estates/" + l.PropertyID.String() + "/leases"},
+	}
+}
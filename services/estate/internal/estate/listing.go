@@ -0,0 +1,290 @@
+package estate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/text/language"
+
+	"github.com/pulap/pulap/pkg/lib/core"
+)
+
+// Listing statuses
+const (
+	ListingDraft     = "draft"
+	ListingActive    = "active"    // Published within its window
+	ListingPaused    = "paused"    // Temporarily off the market
+	ListingWithdrawn = "withdrawn" // Taken off the market without a deal
+	ListingClosed    = "closed"    // Ended with a sale or lease
+)
+
+// ListingStatuses lists every listing status in lifecycle order.
+var ListingStatuses = []string{ListingDraft, ListingActive, ListingPaused, ListingWithdrawn, ListingClosed}
+
+// listingTransitions is the listing lifecycle: the statuses reachable from
+// each status. Withdrawn and closed are final, so ended listings stay as
+// they were for reporting.
+var listingTransitions = map[string][]string{
+	ListingDraft:     {ListingActive, ListingWithdrawn},
+	ListingActive:    {ListingPaused, ListingWithdrawn, ListingClosed},
+	ListingPaused:    {ListingActive, ListingWithdrawn, ListingClosed},
+	ListingWithdrawn: {},
+	ListingClosed:    {},
+}
+
+var (
+	// ErrListingNotFound is returned when a listing does not exist.
+	ErrListingNotFound = errors.New("listing not found")
+
+	// ErrListingEnded is returned when changing a withdrawn or closed listing.
+	ErrListingEnded = errors.New("listing has ended")
+)
+
+var channelPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]{0,63}$`)
+
+// Listing is a commercial offer of a property on a channel: its price,
+// texts and publication window. A property may have several listings, in
+// parallel, e.g. for sale and for rent, or over time; ended listings and
+// their price history are kept.
+type Listing struct {
+	ID         uuid.UUID                 `json:"id"`
+	PropertyID uuid.UUID                 `json:"property_id"`
+	Channel    string                    `json:"channel"` // Where it is published, e.g. "website", "idealista"
+	Price      Price                     `json:"price"`
+	Content    map[string]ListingContent `json:"content"`             // By BCP 47 locale, e.g. "es", "en-GB"
+	Status     string                    `json:"status"`              // One of ListingStatuses
+	StartsAt   *time.Time                `json:"starts_at,omitempty"` // Set on activation when empty
+	EndsAt     *time.Time                `json:"ends_at,omitempty"`
+	EndedAt    *time.Time                `json:"ended_at,omitempty"` // When it was withdrawn or closed
+	Revision   int64                     `json:"revision"`           // Incremented on every write, exposed as the ETag
+	CreatedAt  time.Time                 `json:"created_at"`
+	CreatedBy  string                    `json:"created_by"`
+	UpdatedAt  time.Time                 `json:"updated_at"`
+	UpdatedBy  string                    `json:"updated_by"`
+}
+
+// ListingContent is the text of a listing in one locale.
+type ListingContent struct {
+	Headline    string `json:"headline"`
+	Description string `json:"description"`
+}
+
+// ListingPrice is an entry of the price history of a listing.
+type ListingPrice struct {
+	ListingID uuid.UUID `json:"listing_id"`
+	Price     Price     `json:"price"`
+	Actor     string    `json:"actor"`
+	At        time.Time `json:"at"`
+}
+
+// GetID returns the ID of the Listing (implements Identifiable interface).
+func (l *Listing) GetID() uuid.UUID {
+	return l.ID
+}
+
+// ResourceType returns the resource type for URL generation.
+func (l *Listing) ResourceType() string {
+	return "listing"
+}
+
+// BeforeCreate sets the ID, timestamps and first revision.
+func (l *Listing) BeforeCreate() {
+	if l.ID == uuid.Nil {
+		l.ID = core.GenerateNewID()
+	}
+	if l.Status == "" {
+		l.Status = ListingDraft
+	}
+	l.CreatedAt = time.Now()
+	l.UpdatedAt = l.CreatedAt
+	l.Revision = 1
+}
+
+// BeforeUpdate sets the update timestamp.
+func (l *Listing) BeforeUpdate() {
+	l.UpdatedAt = time.Now()
+}
+
+// Ended returns true if the listing was withdrawn or closed.
+func (l *Listing) Ended() bool {
+	return l.Status == ListingWithdrawn || l.Status == ListingClosed
+}
+
+// LiveAt returns true if the listing is active and within its window at t.
+func (l *Listing) LiveAt(t time.Time) bool {
+	return l.Status == ListingActive &&
+		(l.StartsAt == nil || !l.StartsAt.After(t)) &&
+		(l.EndsAt == nil || l.EndsAt.After(t))
+}
+
+// Normalize trims the channel and content and canonicalizes the locales.
+// Invalid locales are kept for Validate to report.
+func (l *Listing) Normalize() {
+	l.Channel = strings.ToLower(strings.TrimSpace(l.Channel))
+	l.Price.Currency = strings.ToUpper(strings.TrimSpace(l.Price.Currency))
+
+	content := make(map[string]ListingContent, len(l.Content))
+	for locale, c := range l.Content {
+		locale = strings.TrimSpace(locale)
+		if tag, err := language.Parse(locale); err == nil {
+			locale = tag.String()
+		}
+		content[locale] = ListingContent{Headline: strings.TrimSpace(c.Headline), Description: strings.TrimSpace(c.Description)}
+	}
+	l.Content = content
+}
+
+// Validate checks the listing before it is stored.
+func (l *Listing) Validate() []ValidationError {
+	var errors []ValidationError
+
+	if l.PropertyID == uuid.Nil {
+		errors = append(errors, ValidationError{Field: "property_id", Message: "property_id is required"})
+	}
+	if !channelPattern.MatchString(l.Channel) {
+		errors = append(errors, ValidationError{Field: "channel", Message: "channel must be a lowercase identifier of up to 64 characters"})
+	}
+	for _, msg := range l.Price.Validate() {
+		errors = append(errors, ValidationError{Field: "price", Message: msg})
+	}
+	if !slices.Contains(ListingStatuses, l.Status) {
+		errors = append(errors, ValidationError{Field: "status", Message: "status must be one of: " + strings.Join(ListingStatuses, ", ")})
+	}
+
+	if len(l.Content) == 0 {
+		errors = append(errors, ValidationError{Field: "content", Message: "content is required in at least one locale"})
+	}
+	for _, locale := range slices.Sorted(maps.Keys(l.Content)) {
+		if _, err := language.Parse(locale); err != nil {
+			errors = append(errors, ValidationError{Field: "content", Message: fmt.Sprintf("invalid locale %q", locale)})
+			continue
+		}
+		if l.Content[locale].Headline == "" {
+			errors = append(errors, ValidationError{Field: "content", Message: fmt.Sprintf("content.%s.headline is required", locale)})
+		}
+	}
+
+	if l.StartsAt != nil && l.EndsAt != nil && !l.EndsAt.After(*l.StartsAt) {
+		errors = append(errors, ValidationError{Field: "ends_at", Message: "ends_at must be after starts_at"})
+	}
+
+	return errors
+}
+
+// CanTransitionListing returns true if the lifecycle allows going from one
+// listing status to another.
+func CanTransitionListing(from, to string) bool {
+	return slices.Contains(listingTransitions[from], to)
+}
+
+// AllowedListingTransitions returns the statuses reachable from a listing status.
+func AllowedListingTransitions(status string) []string {
+	return listingTransitions[status]
+}
+
+// Transition moves the listing to another status at a time. Activating
+// opens the window if it has no start; withdrawing or closing ends it.
+func (l *Listing) Transition(to string, at time.Time) error {
+	if !slices.Contains(ListingStatuses, to) {
+		return fmt.Errorf("%w: %q", ErrInvalidStatus, to)
+	}
+	if !CanTransitionListing(l.Status, to) {
+		return fmt.Errorf("%w: from %s to %s", ErrInvalidTransition, l.Status, to)
+	}
+
+	l.Status = to
+	switch to {
+	case ListingActive:
+		if l.StartsAt == nil {
+			l.StartsAt = &at
+		}
+	case ListingWithdrawn, ListingClosed:
+		l.EndedAt = &at
+	}
+	return nil
+}
+
+// ListingQuery filters the listings listed. Zero values mean "no filter".
+type ListingQuery struct {
+	PropertyIDs []uuid.UUID
+	Channels    []string
+	Statuses    []string
+	LiveAt      *time.Time // Listings active and within their window at this time
+	Limit       int
+}
+
+// Normalize fills defaults and validates the query.
+func (q *ListingQuery) Normalize() []ValidationError {
+	var errors []ValidationError
+	if q.Limit <= 0 {
+		q.Limit = DefaultSearchLimit
+	}
+	if q.Limit > MaxSearchLimit {
+		q.Limit = MaxSearchLimit
+	}
+	for _, s := range q.Statuses {
+		if !slices.Contains(ListingStatuses, s) {
+			errors = append(errors, ValidationError{Field: "status", Message: "status must be one of: " + strings.Join(ListingStatuses, ", ")})
+			break
+		}
+	}
+	for i, c := range q.Channels {
+		q.Channels[i] = strings.ToLower(c)
+	}
+	return errors
+}
+
+// ParseListingQuery reads a ListingQuery from URL parameters: channel and
+// status take comma-separated lists, live=true keeps the listings live now
+// and live_at those live at an RFC 3339 time.
+func ParseListingQuery(values url.Values) (ListingQuery, []ValidationError) {
+	p := queryParser{values: values}
+	q := ListingQuery{
+		Channels: splitList(values.Get("channel")),
+		Statuses: splitList(values.Get("status")),
+		LiveAt:   p.time("live_at"),
+		Limit:    p.int("limit"),
+	}
+	if q.LiveAt == nil && values.Get("live") == "true" {
+		now := time.Now()
+		q.LiveAt = &now
+	}
+	errors := p.errors
+	errors = append(errors, q.Normalize()...)
+	return q, errors
+}
+
+// ListingRepo defines the repository interface for Listing aggregates.
+type ListingRepo interface {
+	// CreateListing stores a new listing and the first entry of its price
+	// history.
+	CreateListing(ctx context.Context, l *Listing) error
+
+	// GetListing retrieves a listing, or ErrListingNotFound.
+	GetListing(ctx context.Context, id uuid.UUID) (*Listing, error)
+
+	// SaveListing updates a listing while its stored revision is still
+	// l.Revision, which is then incremented, and appends its price to the
+	// history when it changed. It returns ErrListingNotFound or
+	// ErrRevisionConflict.
+	SaveListing(ctx context.Context, l *Listing) error
+
+	// DeleteListing removes a listing and its price history. A non-zero
+	// revision makes it conditional like SaveListing.
+	DeleteListing(ctx context.Context, id uuid.UUID, revision int64) error
+
+	// ListListings lists the listings matching the query, newest first. The
+	// query is expected to be normalized.
+	ListListings(ctx context.Context, query ListingQuery) ([]*Listing, error)
+
+	// ListListingPrices lists the price history of a listing, oldest first.
+	ListListingPrices(ctx context.Context, id uuid.UUID) ([]ListingPrice, error)
+}
@@ -0,0 +1,155 @@
+package estate
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/services/estate/internal/money"
+)
+
+func TestListingNormalizeAndValidate(t *testing.T) {
+	l := &Listing{
+		PropertyID: uuid.New(),
+		Channel:    " Website ",
+		Price:      Price{Amount: money.MustParse("1200"), Currency: "eur", Type: "rent_monthly"},
+		Content: map[string]ListingContent{
+			"en-gb": {Headline: " Bright flat ", Description: " Near the park "},
+			"ES":    {Headline: "Piso luminoso"},
+		},
+		Status: ListingDraft,
+	}
+	l.Normalize()
+
+	if l.Channel != "website" || l.Price.Currency != "EUR" {
+		t.Errorf("unexpected channel or currency: %q %q", l.Channel, l.Price.Currency)
+	}
+	if c, ok := l.Content["en-GB"]; !ok || c.Headline != "Bright flat" || c.Description != "Near the park" {
+		t.Errorf("expected canonical, trimmed en-GB content, got %v", l.Content)
+	}
+	if _, ok := l.Content["es"]; !ok {
+		t.Errorf("expected the es locale, got %v", l.Content)
+	}
+	if errs := l.Validate(); len(errs) > 0 {
+		t.Fatalf("expected a valid listing, got %v", errs)
+	}
+
+	starts := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	ends := starts.Add(-time.Hour)
+	tests := []struct {
+		name   string
+		change func(l *Listing)
+		field  string
+	}{
+		{"channel", func(l *Listing) { l.Channel = "web site" }, "channel"},
+		{"price", func(l *Listing) { l.Price.Type = "lease" }, "price"},
+		{"status", func(l *Listing) { l.Status = "published" }, "status"},
+		{"no content", func(l *Listing) { l.Content = nil }, "content"},
+		{"locale", func(l *Listing) { l.Content["not a locale"] = ListingContent{Headline: "x"} }, "content"},
+		{"headline", func(l *Listing) { l.Content["es"] = ListingContent{Description: "x"} }, "content"},
+		{"window", func(l *Listing) { l.StartsAt, l.EndsAt = &starts, &ends }, "ends_at"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			invalid := *l
+			invalid.Content = map[string]ListingContent{"es": l.Content["es"]}
+			tt.change(&invalid)
+			errs := invalid.Validate()
+			if len(errs) != 1 || errs[0].Field != tt.field {
+				t.Errorf("expected one %s error, got %v", tt.field, errs)
+			}
+		})
+	}
+}
+
+func TestListingTransition(t *testing.T) {
+	at := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	l := &Listing{Status: ListingDraft}
+
+	if err := l.Transition(ListingPaused, at); err == nil {
+		t.Error("expected pausing a draft to fail")
+	}
+	if err := l.Transition("published", at); err == nil {
+		t.Error("expected an unknown status to fail")
+	}
+
+	if err := l.Transition(ListingActive, at); err != nil {
+		t.Fatalf("Transition(active) error = %v", err)
+	}
+	if l.StartsAt == nil || !l.StartsAt.Equal(at) {
+		t.Errorf("expected activation to open the window, got %v", l.StartsAt)
+	}
+	if !l.LiveAt(at) || l.LiveAt(at.Add(-time.Second)) {
+		t.Error("expected the listing live from its start")
+	}
+
+	if err := l.Transition(ListingPaused, at.Add(time.Hour)); err != nil {
+		t.Fatalf("Transition(paused) error = %v", err)
+	}
+	if l.LiveAt(at.Add(2 * time.Hour)) {
+		t.Error("expected a paused listing not to be live")
+	}
+	if err := l.Transition(ListingActive, at.Add(2*time.Hour)); err != nil {
+		t.Fatalf("Transition(active) error = %v", err)
+	}
+	if !l.StartsAt.Equal(at) {
+		t.Errorf("expected reactivation to keep the start, got %v", l.StartsAt)
+	}
+
+	closed := at.Add(3 * time.Hour)
+	if err := l.Transition(ListingClosed, closed); err != nil {
+		t.Fatalf("Transition(closed) error = %v", err)
+	}
+	if !l.Ended() || l.EndedAt == nil || !l.EndedAt.Equal(closed) {
+		t.Errorf("expected the listing ended at %v, got %v", closed, l.EndedAt)
+	}
+	if len(AllowedListingTransitions(l.Status)) != 0 {
+		t.Error("expected closed to be final")
+	}
+}
+
+func TestListingLiveAtWindow(t *testing.T) {
+	starts := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	ends := starts.Add(30 * 24 * time.Hour)
+	l := &Listing{Status: ListingActive, StartsAt: &starts, EndsAt: &ends}
+
+	tests := []struct {
+		at   time.Time
+		want bool
+	}{
+		{starts.Add(-time.Second), false},
+		{starts, true},
+		{ends.Add(-time.Second), true},
+		{ends, false},
+	}
+	for _, tt := range tests {
+		if got := l.LiveAt(tt.at); got != tt.want {
+			t.Errorf("LiveAt(%v) = %v, want %v", tt.at, got, tt.want)
+		}
+	}
+}
+
+func TestParseListingQuery(t *testing.T) {
+	q, errs := ParseListingQuery(url.Values{"channel": {"Website,idealista"}, "status": {"active,paused"}, "live_at": {"2026-05-01T10:00:00Z"}})
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if len(q.Channels) != 2 || q.Channels[0] != "website" || len(q.Statuses) != 2 || q.Limit != DefaultSearchLimit {
+		t.Errorf("unexpected query: %+v", q)
+	}
+	if q.LiveAt == nil || !q.LiveAt.Equal(time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected live_at: %v", q.LiveAt)
+	}
+
+	if q, _ := ParseListingQuery(url.Values{"live": {"true"}}); q.LiveAt == nil {
+		t.Error("expected live=true to filter by now")
+	}
+	if _, errs := ParseListingQuery(url.Values{"status": {"sold"}}); len(errs) == 0 {
+		t.Error("expected an unknown status to be rejected")
+	}
+	if _, errs := ParseListingQuery(url.Values{"live_at": {"tomorrow"}}); len(errs) == 0 {
+		t.Error("expected an invalid live_at to be rejected")
+	}
+}
@@ -0,0 +1,437 @@
+package estate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/pkg/lib/core"
+	"github.com/pulap/pulap/pkg/lib/telemetry"
+	"github.com/pulap/pulap/services/estate/internal/config"
+)
+
+// ListingHandler handles HTTP requests for the Listing aggregate.
+type ListingHandler struct {
+	*PropertyAccess
+	listings ListingRepo
+	tlm      *telemetry.HTTP
+}
+
+// NewListingHandler creates a new ListingHandler for the listings in
+// listings.
+func NewListingHandler(listings ListingRepo, access *PropertyAccess, xparams config.XParams) *ListingHandler {
+	return &ListingHandler{
+		PropertyAccess: access,
+		listings:       listings,
+		tlm: telemetry.NewHTTP(
+			telemetry.WithTracer(xparams.Tracer()),
+			telemetry.WithMetrics(xparams.Metrics()),
+		),
+	}
+}
+
+// RegisterRoutes registers the listing routes.
+func (h *ListingHandler) RegisterRoutes(r chi.Router) {
+	authn := h.authn()
+
+	r.With(authn).Get("/estates/{id}/listings", h.ListPropertyListings)
+	r.With(authn).Post("/estates/{id}/listings", h.CreateListing)
+	r.Route("/listings", func(r chi.Router) {
+		r.Use(authn)
+		r.Get("/{id}", h.GetListing)
+		r.Put("/{id}", h.UpdateListing)
+		r.Delete("/{id}", h.DeleteListing)
+		r.Post("/{id}/transitions", h.TransitionListing)
+		r.Get("/{id}/prices", h.ListListingPrices)
+	})
+}
+
+// ListingTransitionRequest is the payload of POST /listings/{id}/transitions.
+type ListingTransitionRequest struct {
+	To string `json:"to"`
+}
+
+// ListingMeta describes a listing: where it can move to and whether it is
+// live now.
+type ListingMeta struct {
+	Allowed []string `json:"allowed"`
+	Live    bool     `json:"live"`
+}
+
+// ListingListMeta describes the listings of a property.
+type ListingListMeta struct {
+	Count int `json:"count"`
+	Limit int `json:"limit"`
+}
+
+// ListingPricesMeta describes the price history of a listing.
+type ListingPricesMeta struct {
+	Count int `json:"count"`
+}
+
+// CreateListing handles POST /estates/{id}/listings
+// Listings start as drafts and are published with a transition. Requires
+// PermissionWrite on the property.
+func (h *ListingHandler) CreateListing(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "ListingHandler.CreateListing")
+	defer finish()
+	log := h.log(r)
+	ctx := r.Context()
+
+	property, ok := h.listingProperty(w, r, PermissionWrite)
+	if !ok {
+		return
+	}
+
+	l, ok := h.decodeListingPayload(w, r)
+	if !ok {
+		return
+	}
+	if l.Status != "" && l.Status != ListingDraft {
+		core.RespondError(w, http.StatusBadRequest, "Listings start as draft; publish them with a transition")
+		return
+	}
+
+	l.ID = uuid.Nil
+	l.PropertyID = property.ID
+	l.Status = ListingDraft
+	l.EndedAt = nil
+	l.Normalize()
+	if !respondListingInvalid(w, l, log) {
+		return
+	}
+
+	l.CreatedBy = requestActor(r)
+	l.UpdatedBy = l.CreatedBy
+	if err := h.listings.CreateListing(ctx, l); err != nil {
+		log.Error("cannot create listing", "error", err, "property_id", property.ID.String())
+		core.RespondError(w, http.StatusInternalServerError, "Could not create listing")
+		return
+	}
+
+	w.Header().Set("ETag", ETag(l.Revision))
+	w.WriteHeader(http.StatusCreated)
+	core.RespondSuccessWithMeta(w, l, listingMeta(l), listingLinks(l)...)
+}
+
+// ListPropertyListings handles GET /estates/{id}/listings
+// Every listing of the property is listed, newest first, ended ones
+// included. channel and status filter by comma-separated values, live=true
+// keeps those live now and live_at those live at an RFC 3339 time.
+func (h *ListingHandler) ListPropertyListings(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "ListingHandler.ListPropertyListings")
+	defer finish()
+	log := h.log(r)
+	ctx := r.Context()
+
+	property, ok := h.listingProperty(w, r, PermissionRead)
+	if !ok {
+		return
+	}
+
+	query, validationErrors := ParseListingQuery(r.URL.Query())
+	if len(validationErrors) > 0 {
+		log.Debug("invalid listing query", "errors", validationErrors)
+		core.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid query: %s", validationErrors[0].Message))
+		return
+	}
+	query.PropertyIDs = []uuid.UUID{property.ID}
+
+	listings, err := h.listings.ListListings(ctx, query)
+	if err != nil {
+		log.Error("error listing listings", "error", err, "property_id", property.ID.String())
+		core.RespondError(w, http.StatusInternalServerError, "Could not retrieve listings")
+		return
+	}
+	if listings == nil {
+		listings = []*Listing{}
+	}
+
+	core.RespondSuccessWithMeta(w, listings, ListingListMeta{Count: len(listings), Limit: query.Limit})
+}
+
+// GetListing handles GET /listings/{id}
+// Requires PermissionRead on the listed property.
+func (h *ListingHandler) GetListing(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "ListingHandler.GetListing")
+	defer finish()
+
+	l, ok := h.loadListing(w, r, PermissionRead)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("ETag", ETag(l.Revision))
+	core.RespondSuccessWithMeta(w, l, listingMeta(l), listingLinks(l)...)
+}
+
+// UpdateListing handles PUT /listings/{id}
+// Replaces the channel, price, content and window of a listing; a new
+// price is added to its history. The status changes through transitions
+// only, and withdrawn or closed listings are kept as they ended. An
+// If-Match header makes the update conditional on the listing revision.
+func (h *ListingHandler) UpdateListing(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "ListingHandler.UpdateListing")
+	defer finish()
+	log := h.log(r)
+	ctx := r.Context()
+
+	l, ok := h.decodeListingPayload(w, r)
+	if !ok {
+		return
+	}
+
+	current, ok := h.loadListing(w, r, PermissionWrite)
+	if !ok {
+		return
+	}
+	if header := r.Header.Get("If-Match"); header != "" && !IfMatch(header, current.Revision) {
+		w.Header().Set("ETag", ETag(current.Revision))
+		core.RespondError(w, http.StatusPreconditionFailed, "Listing was modified, reload and try again")
+		return
+	}
+	if current.Ended() {
+		core.RespondError(w, http.StatusConflict, capitalize(ErrListingEnded.Error()))
+		return
+	}
+	if l.Status != "" && l.Status != current.Status {
+		core.RespondError(w, http.StatusConflict, "Status cannot be updated; use POST /listings/{id}/transitions")
+		return
+	}
+
+	l.ID, l.PropertyID = current.ID, current.PropertyID
+	l.Status, l.EndedAt = current.Status, current.EndedAt
+	l.Revision = current.Revision
+	l.CreatedAt, l.CreatedBy = current.CreatedAt, current.CreatedBy
+	l.Normalize()
+	if !respondListingInvalid(w, l, log) {
+		return
+	}
+
+	l.UpdatedBy = requestActor(r)
+	if err := h.listings.SaveListing(ctx, l); err != nil {
+		h.respondListingSaveError(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", ETag(l.Revision))
+	core.RespondSuccessWithMeta(w, l, listingMeta(l), listingLinks(l)...)
+}
+
+// TransitionListing handles POST /listings/{id}/transitions
+// Moves a listing through its lifecycle: activating publishes it, from now
+// if it has no start; withdrawing or closing ends it for good. Requires
+// PermissionWrite on the listed property.
+func (h *ListingHandler) TransitionListing(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "ListingHandler.TransitionListing")
+	defer finish()
+	log := h.log(r)
+	ctx := r.Context()
+
+	r.Body = http.MaxBytesReader(w, r.Body, MaxBodyBytes)
+	defer r.Body.Close()
+
+	var req ListingTransitionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Debug("error decoding transition", "error", err)
+		core.RespondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	req.To = strings.ToLower(strings.TrimSpace(req.To))
+
+	l, ok := h.loadListing(w, r, PermissionWrite)
+	if !ok {
+		return
+	}
+
+	if err := l.Transition(req.To, time.Now()); err != nil {
+		if errors.Is(err, ErrInvalidStatus) {
+			core.RespondError(w, http.StatusBadRequest, "Status must be one of: "+strings.Join(ListingStatuses, ", "))
+			return
+		}
+		core.RespondError(w, http.StatusConflict, capitalize(err.Error()))
+		return
+	}
+
+	l.UpdatedBy = requestActor(r)
+	if err := h.listings.SaveListing(ctx, l); err != nil {
+		h.respondListingSaveError(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", ETag(l.Revision))
+	core.RespondSuccessWithMeta(w, l, listingMeta(l), listingLinks(l)...)
+}
+
+// ListListingPrices handles GET /listings/{id}/prices
+// The price history of the listing, oldest first, starting with the price
+// it was created with.
+func (h *ListingHandler) ListListingPrices(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "ListingHandler.ListListingPrices")
+	defer finish()
+	log := h.log(r)
+
+	l, ok := h.loadListing(w, r, PermissionRead)
+	if !ok {
+		return
+	}
+
+	prices, err := h.listings.ListListingPrices(r.Context(), l.ID)
+	if err != nil {
+		log.Error("error listing listing prices", "error", err, "id", l.ID.String())
+		core.RespondError(w, http.StatusInternalServerError, "Could not retrieve listing prices")
+		return
+	}
+	if prices == nil {
+		prices = []ListingPrice{}
+	}
+
+	core.RespondSuccessWithMeta(w, prices, ListingPricesMeta{Count: len(prices)})
+}
+
+// DeleteListing handles DELETE /listings/{id}
+// Only drafts can be deleted; listings that were published are withdrawn
+// instead, so their history is kept. Requires PermissionDelete on the
+// listed property. An If-Match header makes the delete conditional on the
+// listing revision.
+func (h *ListingHandler) DeleteListing(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "ListingHandler.DeleteListing")
+	defer finish()
+	log := h.log(r)
+	ctx := r.Context()
+
+	current, ok := h.loadListing(w, r, PermissionDelete)
+	if !ok {
+		return
+	}
+	if current.Status != ListingDraft {
+		core.RespondError(w, http.StatusConflict, "Only draft listings can be deleted; withdraw it instead")
+		return
+	}
+
+	var revision int64
+	if header := r.Header.Get("If-Match"); header != "" {
+		if !IfMatch(header, current.Revision) {
+			w.Header().Set("ETag", ETag(current.Revision))
+			core.RespondError(w, http.StatusPreconditionFailed, "Listing was modified, reload and try again")
+			return
+		}
+		revision = current.Revision
+	}
+
+	if err := h.listings.DeleteListing(ctx, current.ID, revision); err != nil {
+		switch {
+		case errors.Is(err, ErrListingNotFound):
+			core.RespondError(w, http.StatusNotFound, "Listing not found")
+		case errors.Is(err, ErrRevisionConflict):
+			core.RespondError(w, http.StatusPreconditionFailed, "Listing was modified, reload and try again")
+		default:
+			log.Error("cannot delete listing", "error", err)
+			core.RespondError(w, http.StatusInternalServerError, "Could not delete listing")
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// listingProperty loads the property of the request and checks the user
+// holds the permission on it, responding with the error when it returns
+// false.
+func (h *ListingHandler) listingProperty(w http.ResponseWriter, r *http.Request, permission string) (*Property, bool) {
+	return h.loadProperty(w, r, permission)
+}
+
+// loadListing loads the listing of the request and checks the user holds
+// the permission on its property, responding with the error when it
+// returns false. Listings of properties the user cannot read, or that no
+// longer exist, are reported as not found.
+func (h *ListingHandler) loadListing(w http.ResponseWriter, r *http.Request, permission string) (*Listing, bool) {
+	log := h.log(r)
+
+	id, ok := h.parseIDParam(w, r, log)
+	if !ok {
+		return nil, false
+	}
+
+	l, err := h.listings.GetListing(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, ErrListingNotFound) {
+			core.RespondError(w, http.StatusNotFound, "Listing not found")
+			return nil, false
+		}
+		log.Error("error loading listing", "error", err, "id", id.String())
+		core.RespondError(w, http.StatusInternalServerError, "Could not retrieve listing")
+		return nil, false
+	}
+
+	if !h.authorizePropertyOf(w, r, l.PropertyID, permission, "Listing not found") {
+		return nil, false
+	}
+	return l, true
+}
+
+func (h *ListingHandler) respondListingSaveError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, ErrListingNotFound):
+		core.RespondError(w, http.StatusNotFound, "Listing not found")
+	case errors.Is(err, ErrRevisionConflict) && r.Header.Get("If-Match") != "":
+		core.RespondError(w, http.StatusPreconditionFailed, "Listing was modified, reload and try again")
+	case errors.Is(err, ErrRevisionConflict):
+		core.RespondError(w, http.StatusConflict, "Listing was modified concurrently, reload and try again")
+	default:
+		h.log(r).Error("cannot save listing", "error", err)
+		core.RespondError(w, http.StatusInternalServerError, "Could not update listing")
+	}
+}
+
+func (h *ListingHandler) decodeListingPayload(w http.ResponseWriter, r *http.Request) (*Listing, bool) {
+	r.Body = http.MaxBytesReader(w, r.Body, MaxBodyBytes)
+	defer r.Body.Close()
+
+	var l Listing
+	if err := json.NewDecoder(r.Body).Decode(&l); err != nil {
+		h.log(r).Debug("error decoding JSON", "error", err)
+		core.RespondError(w, http.StatusBadRequest, "Invalid JSON payload")
+		return nil, false
+	}
+	return &l, true
+}
+
+// respondListingInvalid responds 400 with the first validation error and
+// returns false when the listing is invalid.
+func respondListingInvalid(w http.ResponseWriter, l *Listing, log core.Logger) bool {
+	validationErrors := l.Validate()
+	if len(validationErrors) == 0 {
+		return true
+	}
+	log.Debug("validation failed", "errors", validationErrors)
+	core.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Validation failed: %s", validationErrors[0].Message))
+	return false
+}
+
+func listingMeta(l *Listing) ListingMeta {
+	allowed := AllowedListingTransitions(l.Status)
+	if allowed == nil {
+		allowed = []string{}
+	}
+	return ListingMeta{Allowed: allowed, Live: l.LiveAt(time.Now())}
+}
+
+// listingLinks links a listing and the listings of its property, as
+// listings have no collection of their own.
+func listingLinks(l *Listing) []core.Link {
+	self := "/listings/" + l.ID.String()
+	return []core.Link{
+		{Rel: core.RelSelf, Href: self},
+		{Rel: core.RelUpdate, Href: self},
+		{Rel: core.RelDelete, Href: self},
+		{Rel: core.RelCollection, Href: "/estates/" + l.PropertyID.String() + "/listings"},
+	}
+}
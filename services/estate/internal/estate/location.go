@@ -2,31 +2,31 @@ package estate
 
 // Location represents the physical location of a property.
 type Location struct {
-	Address     Address        `json:"address"`
-	Coordinates Coordinates    `json:"coordinates"`
-	Region      string         `json:"region,omitempty"` // e.g., "EUROPE", "North America"
-	Provider    string         `json:"provider,omitempty"`
-	ProviderURL string         `json:"provider_url,omitempty"`
-	ProviderRef string         `json:"provider_ref,omitempty"`
-	Raw         map[string]any `json:"raw,omitempty"`
-	DisplayName string         `json:"display_name,omitempty"`
+	Address     Address        `json:"address" bson:"address"`
+	Coordinates Coordinates    `json:"coordinates" bson:"coordinates"`
+	Region      string         `json:"region,omitempty" bson:"region,omitempty"` // e.g., "EUROPE", "North America"
+	Provider    string         `json:"provider,omitempty" bson:"provider,omitempty"`
+	ProviderURL string         `json:"provider_url,omitempty" bson:"provider_url,omitempty"`
+	ProviderRef string         `json:"provider_ref,omitempty" bson:"provider_ref,omitempty"`
+	Raw         map[string]any `json:"raw,omitempty" bson:"raw,omitempty"`
+	DisplayName string         `json:"display_name,omitempty" bson:"display_name,omitempty"`
 }
 
 // Address represents a structured physical address.
 type Address struct {
-	Street     string `json:"street"`
-	Number     string `json:"number,omitempty"`
-	Unit       string `json:"unit,omitempty"` // Apartment, suite, etc.
-	City       string `json:"city"`
-	State      string `json:"state,omitempty"` // Province, state, department
-	PostalCode string `json:"postal_code,omitempty"`
-	Country    string `json:"country"`
+	Street     string `json:"street" bson:"street"`
+	Number     string `json:"number,omitempty" bson:"number,omitempty"`
+	Unit       string `json:"unit,omitempty" bson:"unit,omitempty"` // Apartment, suite, etc.
+	City       string `json:"city" bson:"city"`
+	State      string `json:"state,omitempty" bson:"state,omitempty"` // Province, state, department
+	PostalCode string `json:"postal_code,omitempty" bson:"postal_code,omitempty"`
+	Country    string `json:"country" bson:"country"`
 }
 
 // Coordinates represents geographic coordinates.
 type Coordinates struct {
-	Latitude  float64 `json:"latitude"`
-	Longitude float64 `json:"longitude"`
+	Latitude  float64 `json:"latitude" bson:"latitude"`
+	Longitude float64 `json:"longitude" bson:"longitude"`
 }
 
 // IsZero returns true if coordinates are not set.
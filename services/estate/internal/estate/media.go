@@ -0,0 +1,426 @@
+package estate
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/pkg/lib/core"
+	"github.com/pulap/pulap/services/estate/internal/imaging"
+)
+
+// Media kinds
+const (
+	MediaPhoto     = "photo"
+	MediaFloorPlan = "floor_plan"
+	MediaDocument  = "document"
+)
+
+var (
+	// ErrMediaNotFound is returned when a media item does not exist.
+	ErrMediaNotFound = errors.New("media not found")
+
+	// ErrUnsupportedMedia is returned when the content of an upload is not
+	// accepted for its kind.
+	ErrUnsupportedMedia = errors.New("unsupported media")
+
+	// ErrMediaTooLarge is returned when an upload exceeds the size limit.
+	ErrMediaTooLarge = errors.New("media too large")
+
+	// ErrInvalidMedia is returned for invalid ordering, cover or caption changes.
+	ErrInvalidMedia = errors.New("invalid media change")
+
+	// ErrBlobNotFound is returned by a BlobStore when a key does not exist.
+	ErrBlobNotFound = errors.New("blob not found")
+)
+
+// mediaContentTypes lists the sniffed content types accepted per kind.
+var mediaContentTypes = map[string][]string{
+	MediaPhoto:     {"image/jpeg", "image/png", "image/gif"},
+	MediaFloorPlan: {"image/jpeg", "image/png", "image/gif", "application/pdf"},
+	MediaDocument:  {"application/pdf"},
+}
+
+// ThumbnailSizes are the thumbnails rendered for every image.
+var ThumbnailSizes = []imaging.Size{
+	{Name: "small", MaxSide: 320},
+	{Name: "medium", MaxSide: 800},
+	{Name: "large", MaxSide: 1600},
+}
+
+// Media is a photo, floor plan or document attached to a property. The
+// original and its thumbnails are kept in a BlobStore; the metadata in the
+// MediaRepo.
+type Media struct {
+	ID          uuid.UUID         `json:"id"`
+	PropertyID  uuid.UUID         `json:"property_id"`
+	Kind        string            `json:"kind"`
+	Filename    string            `json:"filename"`
+	ContentType string            `json:"content_type"`
+	Size        int64             `json:"size"`
+	Width       int               `json:"width,omitempty"`
+	Height      int               `json:"height,omitempty"`
+	Position    int               `json:"position"` // Display order, starting at 0
+	Cover       bool              `json:"cover"`    // At most one photo per property
+	Captions    map[string]string `json:"captions,omitempty"`
+	Thumbnails  []Thumbnail       `json:"thumbnails,omitempty"`
+	URL         string            `json:"url,omitempty"` // Set by the handler
+	CreatedAt   time.Time         `json:"created_at"`
+	CreatedBy   string            `json:"created_by"`
+}
+
+// Thumbnail is a scaled-down rendition of an image.
+type Thumbnail struct {
+	Size        string `json:"size"`
+	ContentType string `json:"content_type"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	URL         string `json:"url,omitempty"` // Set by the handler
+}
+
+// Key returns the blob key of the original, or of a thumbnail when size is set.
+func (m *Media) Key(size string) string {
+	if size == "" {
+		size = "original"
+	}
+	return m.prefix() + size
+}
+
+// prefix returns the blob key prefix of the original and its thumbnails.
+func (m *Media) prefix() string {
+	return MediaPrefix(m.PropertyID) + m.ID.String() + "/"
+}
+
+// Thumbnail returns the thumbnail of the given size.
+func (m *Media) Thumbnail(size string) (Thumbnail, bool) {
+	for _, t := range m.Thumbnails {
+		if t.Size == size {
+			return t, true
+		}
+	}
+	return Thumbnail{}, false
+}
+
+// MediaPrefix returns the blob key prefix holding every media item of a property.
+func MediaPrefix(propertyID uuid.UUID) string {
+	return "properties/" + propertyID.String() + "/"
+}
+
+// MediaRepo stores media metadata. ListMedia returns items by position.
+// Deleting a property deletes its media metadata; blobs are removed by
+// MediaLibrary.Purge.
+type MediaRepo interface {
+	CreateMedia(ctx context.Context, m *Media) error
+	GetMedia(ctx context.Context, propertyID, id uuid.UUID) (*Media, error)
+	ListMedia(ctx context.Context, propertyID uuid.UUID) ([]Media, error)
+	SaveMedia(ctx context.Context, m *Media) error
+	// ArrangeMedia saves the position and cover flag of every given item at once.
+	ArrangeMedia(ctx context.Context, propertyID uuid.UUID, items []Media) error
+	DeleteMedia(ctx context.Context, propertyID, id uuid.UUID) error
+}
+
+// BlobStore keeps binary objects under slash-separated keys, in the manner
+// of S3: a local directory, or a bucket in an S3-compatible service.
+type BlobStore interface {
+	// Put stores the object, replacing any object with the same key.
+	Put(ctx context.Context, key, contentType string, r io.Reader) error
+	// Open returns the object, or ErrBlobNotFound.
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the object; missing objects are not an error.
+	Delete(ctx context.Context, key string) error
+	// DeletePrefix removes every object whose key starts with prefix.
+	DeletePrefix(ctx context.Context, prefix string) error
+}
+
+// MediaUpload is a file to be added to a property.
+type MediaUpload struct {
+	Kind     string
+	Filename string
+	Data     []byte
+	Captions map[string]string
+	Actor    string
+}
+
+// MediaLibrary manages the media of properties: it validates and processes
+// uploads, stores blobs and metadata and keeps ordering and cover consistent.
+type MediaLibrary struct {
+	repo    MediaRepo
+	store   BlobStore
+	maxSize int64
+}
+
+// NewMediaLibrary returns a library accepting uploads up to maxSize bytes.
+func NewMediaLibrary(repo MediaRepo, store BlobStore, maxSize int64) *MediaLibrary {
+	return &MediaLibrary{repo: repo, store: store, maxSize: maxSize}
+}
+
+// MaxSize returns the upload size limit in bytes.
+func (l *MediaLibrary) MaxSize() int64 {
+	return l.maxSize
+}
+
+// Upload validates, processes and stores a file. The content type is
+// sniffed from the data; the client supplied one is ignored. Images are
+// stripped of EXIF data and thumbnails are rendered. The first photo of a
+// property becomes its cover.
+func (l *MediaLibrary) Upload(ctx context.Context, propertyID uuid.UUID, up MediaUpload) (*Media, error) {
+	if up.Kind == "" {
+		up.Kind = MediaPhoto
+	}
+	allowed, ok := mediaContentTypes[up.Kind]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown kind %q", ErrUnsupportedMedia, up.Kind)
+	}
+	if int64(len(up.Data)) > l.maxSize {
+		return nil, fmt.Errorf("%w: %d bytes, limit is %d", ErrMediaTooLarge, len(up.Data), l.maxSize)
+	}
+	if err := validateCaptions(up.Captions); err != nil {
+		return nil, err
+	}
+
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(up.Data))
+	if !slices.Contains(allowed, contentType) {
+		return nil, fmt.Errorf("%w: %s is not accepted for %s", ErrUnsupportedMedia, contentType, up.Kind)
+	}
+
+	m := &Media{
+		ID:          core.GenerateNewID(),
+		PropertyID:  propertyID,
+		Kind:        up.Kind,
+		Filename:    up.Filename,
+		ContentType: contentType,
+		Captions:    up.Captions,
+		CreatedAt:   time.Now(),
+		CreatedBy:   up.Actor,
+	}
+
+	blobs := map[string]imaging.Image{"": {Data: up.Data, ContentType: contentType}}
+	if contentType != "application/pdf" {
+		processed, err := imaging.Process(up.Data, ThumbnailSizes)
+		if err != nil {
+			if errors.Is(err, imaging.ErrTooLarge) {
+				return nil, fmt.Errorf("%w: %v", ErrMediaTooLarge, err)
+			}
+			return nil, fmt.Errorf("%w: %v", ErrUnsupportedMedia, err)
+		}
+		blobs[""] = processed.Original
+		m.Width, m.Height = processed.Original.Width, processed.Original.Height
+		for _, t := range processed.Thumbnails {
+			blobs[t.Name] = t.Image
+			m.Thumbnails = append(m.Thumbnails, Thumbnail{Size: t.Name, ContentType: t.ContentType, Width: t.Width, Height: t.Height})
+		}
+	}
+	m.ContentType = blobs[""].ContentType
+	m.Size = int64(len(blobs[""].Data))
+
+	existing, err := l.repo.ListMedia(ctx, propertyID)
+	if err != nil {
+		return nil, err
+	}
+	m.Position = len(existing)
+	m.Cover = m.Kind == MediaPhoto && !slices.ContainsFunc(existing, func(e Media) bool { return e.Cover })
+
+	for size, blob := range blobs {
+		if err := l.store.Put(ctx, m.Key(size), blob.ContentType, bytes.NewReader(blob.Data)); err != nil {
+			l.removeBlobs(m)
+			return nil, fmt.Errorf("cannot store media: %w", err)
+		}
+	}
+
+	if err := l.repo.CreateMedia(ctx, m); err != nil {
+		l.removeBlobs(m)
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// List returns the media of a property by position.
+func (l *MediaLibrary) List(ctx context.Context, propertyID uuid.UUID) ([]Media, error) {
+	return l.repo.ListMedia(ctx, propertyID)
+}
+
+// Get returns a media item.
+func (l *MediaLibrary) Get(ctx context.Context, propertyID, id uuid.UUID) (*Media, error) {
+	return l.repo.GetMedia(ctx, propertyID, id)
+}
+
+// Open returns the content of a media item, or of one of its thumbnails
+// when size is set, along with its content type.
+func (l *MediaLibrary) Open(ctx context.Context, m *Media, size string) (io.ReadCloser, string, error) {
+	contentType := m.ContentType
+	if size != "" {
+		t, ok := m.Thumbnail(size)
+		if !ok {
+			return nil, "", fmt.Errorf("thumbnail %q of media %s: %w", size, m.ID, ErrMediaNotFound)
+		}
+		contentType = t.ContentType
+	}
+
+	r, err := l.store.Open(ctx, m.Key(size))
+	if errors.Is(err, ErrBlobNotFound) {
+		return nil, "", fmt.Errorf("content of media %s: %w", m.ID, ErrMediaNotFound)
+	}
+	return r, contentType, err
+}
+
+// UpdateCaptions merges captions into the captions of a media item; an
+// empty caption removes the locale.
+func (l *MediaLibrary) UpdateCaptions(ctx context.Context, propertyID, id uuid.UUID, captions map[string]string) (*Media, error) {
+	if err := validateCaptions(captions); err != nil {
+		return nil, err
+	}
+
+	m, err := l.repo.GetMedia(ctx, propertyID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if m.Captions == nil {
+		m.Captions = map[string]string{}
+	}
+	for locale, caption := range captions {
+		if caption == "" {
+			delete(m.Captions, locale)
+			continue
+		}
+		m.Captions[locale] = caption
+	}
+	if len(m.Captions) == 0 {
+		m.Captions = nil
+	}
+
+	if err := l.repo.SaveMedia(ctx, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Reorder sets the display order. ids must list every media item of the
+// property exactly once.
+func (l *MediaLibrary) Reorder(ctx context.Context, propertyID uuid.UUID, ids []uuid.UUID) ([]Media, error) {
+	items, err := l.repo.ListMedia(ctx, propertyID)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) != len(items) {
+		return nil, fmt.Errorf("%w: expected %d media IDs, got %d", ErrInvalidMedia, len(items), len(ids))
+	}
+
+	byID := make(map[uuid.UUID]Media, len(items))
+	for _, m := range items {
+		byID[m.ID] = m
+	}
+	ordered := make([]Media, 0, len(ids))
+	for i, id := range ids {
+		m, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("%w: media %s is missing or listed twice", ErrInvalidMedia, id)
+		}
+		delete(byID, id)
+		m.Position = i
+		ordered = append(ordered, m)
+	}
+
+	if err := l.repo.ArrangeMedia(ctx, propertyID, ordered); err != nil {
+		return nil, err
+	}
+	return ordered, nil
+}
+
+// SetCover makes a photo the cover of its property.
+func (l *MediaLibrary) SetCover(ctx context.Context, propertyID, id uuid.UUID) ([]Media, error) {
+	items, err := l.repo.ListMedia(ctx, propertyID)
+	if err != nil {
+		return nil, err
+	}
+
+	i := slices.IndexFunc(items, func(m Media) bool { return m.ID == id })
+	if i < 0 {
+		return nil, fmt.Errorf("media %s: %w", id, ErrMediaNotFound)
+	}
+	if items[i].Kind != MediaPhoto {
+		return nil, fmt.Errorf("%w: only photos can be the cover", ErrInvalidMedia)
+	}
+
+	for i := range items {
+		items[i].Cover = items[i].ID == id
+	}
+	if err := l.repo.ArrangeMedia(ctx, propertyID, items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// Delete removes a media item and its blobs. The remaining items are
+// renumbered and, when the cover was removed, the first photo becomes the
+// cover.
+func (l *MediaLibrary) Delete(ctx context.Context, propertyID, id uuid.UUID) error {
+	m, err := l.repo.GetMedia(ctx, propertyID, id)
+	if err != nil {
+		return err
+	}
+
+	if err := l.repo.DeleteMedia(ctx, propertyID, id); err != nil {
+		return err
+	}
+	if err := l.store.DeletePrefix(ctx, m.prefix()); err != nil {
+		return fmt.Errorf("cannot delete media content: %w", err)
+	}
+
+	items, err := l.repo.ListMedia(ctx, propertyID)
+	if err != nil {
+		return err
+	}
+	hasCover := false
+	for i := range items {
+		items[i].Position = i
+		hasCover = hasCover || items[i].Cover
+	}
+	if !hasCover {
+		if i := slices.IndexFunc(items, func(m Media) bool { return m.Kind == MediaPhoto }); i >= 0 {
+			items[i].Cover = true
+		}
+	}
+	return l.repo.ArrangeMedia(ctx, propertyID, items)
+}
+
+// Purge removes the blobs of every media item of a deleted property.
+func (l *MediaLibrary) Purge(ctx context.Context, propertyID uuid.UUID) error {
+	if err := l.store.DeletePrefix(ctx, MediaPrefix(propertyID)); err != nil {
+		return fmt.Errorf("cannot purge media of property %s: %w", propertyID, err)
+	}
+	return nil
+}
+
+// removeBlobs cleans up after a failed upload.
+func (l *MediaLibrary) removeBlobs(m *Media) {
+	_ = l.store.DeletePrefix(context.Background(), m.prefix())
+}
+
+// localePattern matches locales such as "en" or "pt-BR".
+var localePattern = regexp.MustCompile(`^[a-z]{2}(-[A-Z]{2})?$`)
+
+// MaxCaptionLength bounds a caption, in characters.
+const MaxCaptionLength = 500
+
+func validateCaptions(captions map[string]string) error {
+	for locale, caption := range captions {
+		if !localePattern.MatchString(locale) {
+			return fmt.Errorf("%w: invalid caption locale %q", ErrInvalidMedia, locale)
+		}
+		if len([]rune(caption)) > MaxCaptionLength {
+			return fmt.Errorf("%w: %s caption exceeds %d characters", ErrInvalidMedia, locale, MaxCaptionLength)
+		}
+	}
+	return nil
+}
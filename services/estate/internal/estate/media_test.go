@@ -0,0 +1,382 @@
+package estate
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestMediaUpload(t *testing.T) {
+	pdf := []byte("%PDF-1.7\n1 0 obj\n<<>>\nendobj\n")
+
+	tests := []struct {
+		name        string
+		kind        string
+		data        []byte
+		wantType    string
+		wantThumbs  int
+		wantErr     error
+		wantCovered bool
+	}{
+		{name: "photo", kind: "", data: testPNG(t, 40, 20), wantType: "image/png", wantThumbs: len(ThumbnailSizes), wantCovered: true},
+		{name: "floor plan pdf", kind: MediaFloorPlan, data: pdf, wantType: "application/pdf"},
+		{name: "document", kind: MediaDocument, data: pdf, wantType: "application/pdf"},
+		{name: "pdf as photo", kind: MediaPhoto, data: pdf, wantErr: ErrUnsupportedMedia},
+		{name: "image as document", kind: MediaDocument, data: testPNG(t, 4, 4), wantErr: ErrUnsupportedMedia},
+		{name: "text", kind: MediaPhoto, data: []byte("hello"), wantErr: ErrUnsupportedMedia},
+		{name: "unknown kind", kind: "video", data: testPNG(t, 4, 4), wantErr: ErrUnsupportedMedia},
+		{name: "too large", kind: MediaDocument, data: append(pdf, make([]byte, 1024)...), wantErr: ErrMediaTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lib, repo, store := newTestMediaLibrary(512)
+			propertyID := uuid.New()
+
+			m, err := lib.Upload(context.Background(), propertyID, MediaUpload{Kind: tt.kind, Filename: "file", Data: tt.data, Actor: "agent-1"})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				if len(store.blobs) != 0 || len(repo.items) != 0 {
+					t.Errorf("expected nothing stored, got %d blobs and %d items", len(store.blobs), len(repo.items))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Upload: %v", err)
+			}
+
+			if m.ContentType != tt.wantType || len(m.Thumbnails) != tt.wantThumbs || m.Cover != tt.wantCovered {
+				t.Errorf("got %s with %d thumbnails cover=%v", m.ContentType, len(m.Thumbnails), m.Cover)
+			}
+			if m.CreatedBy != "agent-1" || m.Size != int64(len(store.blobs[m.Key("")])) {
+				t.Errorf("unexpected metadata %+v", m)
+			}
+			if len(store.blobs) != 1+tt.wantThumbs {
+				t.Errorf("expected %d blobs, got %d", 1+tt.wantThumbs, len(store.blobs))
+			}
+			for _, thumb := range m.Thumbnails {
+				if _, ok := store.blobs[m.Key(thumb.Size)]; !ok {
+					t.Errorf("thumbnail %s not stored", thumb.Size)
+				}
+			}
+		})
+	}
+}
+
+func TestMediaUploadKeepsSingleCover(t *testing.T) {
+	ctx := context.Background()
+	lib, _, _ := newTestMediaLibrary(1 << 20)
+	propertyID := uuid.New()
+
+	plan := uploadTestMedia(t, lib, propertyID, MediaFloorPlan)
+	first := uploadTestMedia(t, lib, propertyID, MediaPhoto)
+	second := uploadTestMedia(t, lib, propertyID, MediaPhoto)
+
+	if plan.Cover || !first.Cover || second.Cover {
+		t.Errorf("expected only the first photo as cover, got %v %v %v", plan.Cover, first.Cover, second.Cover)
+	}
+	if plan.Position != 0 || first.Position != 1 || second.Position != 2 {
+		t.Errorf("unexpected positions %d %d %d", plan.Position, first.Position, second.Position)
+	}
+
+	if _, err := lib.SetCover(ctx, propertyID, plan.ID); !errors.Is(err, ErrInvalidMedia) {
+		t.Errorf("expected floor plan to be refused as cover, got %v", err)
+	}
+	items, err := lib.SetCover(ctx, propertyID, second.ID)
+	if err != nil {
+		t.Fatalf("SetCover: %v", err)
+	}
+	if covers := coverIDs(items); !slices.Equal(covers, []uuid.UUID{second.ID}) {
+		t.Errorf("expected second photo as only cover, got %v", covers)
+	}
+}
+
+func TestMediaReorder(t *testing.T) {
+	ctx := context.Background()
+	lib, _, _ := newTestMediaLibrary(1 << 20)
+	propertyID := uuid.New()
+
+	a := uploadTestMedia(t, lib, propertyID, MediaPhoto)
+	b := uploadTestMedia(t, lib, propertyID, MediaPhoto)
+	c := uploadTestMedia(t, lib, propertyID, MediaDocument)
+
+	tests := []struct {
+		name    string
+		ids     []uuid.UUID
+		wantErr bool
+	}{
+		{name: "missing item", ids: []uuid.UUID{a.ID, b.ID}, wantErr: true},
+		{name: "duplicate item", ids: []uuid.UUID{a.ID, a.ID, b.ID}, wantErr: true},
+		{name: "unknown item", ids: []uuid.UUID{a.ID, b.ID, uuid.New()}, wantErr: true},
+		{name: "permutation", ids: []uuid.UUID{c.ID, a.ID, b.ID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := lib.Reorder(ctx, propertyID, tt.ids)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidMedia) {
+					t.Errorf("expected ErrInvalidMedia, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Reorder: %v", err)
+			}
+
+			items, err := lib.List(ctx, propertyID)
+			if err != nil {
+				t.Fatalf("List: %v", err)
+			}
+			if got := mediaIDs(items); !slices.Equal(got, tt.ids) {
+				t.Errorf("got order %v, want %v", got, tt.ids)
+			}
+		})
+	}
+}
+
+func TestMediaDelete(t *testing.T) {
+	ctx := context.Background()
+	lib, _, store := newTestMediaLibrary(1 << 20)
+	propertyID := uuid.New()
+
+	plan := uploadTestMedia(t, lib, propertyID, MediaFloorPlan)
+	cover := uploadTestMedia(t, lib, propertyID, MediaPhoto)
+	photo := uploadTestMedia(t, lib, propertyID, MediaPhoto)
+
+	if err := lib.Delete(ctx, propertyID, cover.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	for key := range store.blobs {
+		if strings.Contains(key, cover.ID.String()) {
+			t.Errorf("blob %s not deleted", key)
+		}
+	}
+
+	items, err := lib.List(ctx, propertyID)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if got := mediaIDs(items); !slices.Equal(got, []uuid.UUID{plan.ID, photo.ID}) {
+		t.Errorf("unexpected remaining media %v", got)
+	}
+	if items[1].Position != 1 {
+		t.Errorf("expected positions to be renumbered, got %d", items[1].Position)
+	}
+	if covers := coverIDs(items); !slices.Equal(covers, []uuid.UUID{photo.ID}) {
+		t.Errorf("expected remaining photo to become cover, got %v", covers)
+	}
+
+	if err := lib.Delete(ctx, propertyID, cover.ID); !errors.Is(err, ErrMediaNotFound) {
+		t.Errorf("expected ErrMediaNotFound, got %v", err)
+	}
+}
+
+func TestMediaUpdateCaptions(t *testing.T) {
+	ctx := context.Background()
+	lib, _, _ := newTestMediaLibrary(1 << 20)
+	propertyID := uuid.New()
+	m := uploadTestMedia(t, lib, propertyID, MediaPhoto)
+
+	tests := []struct {
+		name     string
+		captions map[string]string
+		want     map[string]string
+		wantErr  bool
+	}{
+		{name: "add", captions: map[string]string{"en": "Kitchen", "pt-BR": "Cozinha"}, want: map[string]string{"en": "Kitchen", "pt-BR": "Cozinha"}},
+		{name: "merge", captions: map[string]string{"es": "Cocina", "en": "Open kitchen"}, want: map[string]string{"en": "Open kitchen", "es": "Cocina", "pt-BR": "Cozinha"}},
+		{name: "remove", captions: map[string]string{"pt-BR": ""}, want: map[string]string{"en": "Open kitchen", "es": "Cocina"}},
+		{name: "invalid locale", captions: map[string]string{"english": "Kitchen"}, wantErr: true},
+		{name: "too long", captions: map[string]string{"en": strings.Repeat("a", MaxCaptionLength+1)}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := lib.UpdateCaptions(ctx, propertyID, m.ID, tt.captions)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidMedia) {
+					t.Errorf("expected ErrInvalidMedia, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UpdateCaptions: %v", err)
+			}
+			if len(got.Captions) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got.Captions, tt.want)
+			}
+			for locale, caption := range tt.want {
+				if got.Captions[locale] != caption {
+					t.Errorf("%s: got %q, want %q", locale, got.Captions[locale], caption)
+				}
+			}
+		})
+	}
+}
+
+func TestMediaPurge(t *testing.T) {
+	ctx := context.Background()
+	lib, _, store := newTestMediaLibrary(1 << 20)
+	deleted, kept := uuid.New(), uuid.New()
+	uploadTestMedia(t, lib, deleted, MediaPhoto)
+	uploadTestMedia(t, lib, kept, MediaPhoto)
+
+	if err := lib.Purge(ctx, deleted); err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+	for key := range store.blobs {
+		if strings.HasPrefix(key, MediaPrefix(deleted)) {
+			t.Errorf("blob %s not purged", key)
+		}
+	}
+	if len(store.blobs) != 1+len(ThumbnailSizes) {
+		t.Errorf("expected blobs of the other property to remain, got %d", len(store.blobs))
+	}
+}
+
+func newTestMediaLibrary(maxSize int64) (*MediaLibrary, *memMediaRepo, *memBlobStore) {
+	repo := &memMediaRepo{items: map[uuid.UUID]Media{}}
+	store := &memBlobStore{blobs: map[string][]byte{}}
+	return NewMediaLibrary(repo, store, maxSize), repo, store
+}
+
+func uploadTestMedia(t *testing.T, lib *MediaLibrary, propertyID uuid.UUID, kind string) *Media {
+	t.Helper()
+	data := testPNG(t, 8, 8)
+	if kind == MediaDocument {
+		data = []byte("%PDF-1.7\n")
+	}
+	m, err := lib.Upload(context.Background(), propertyID, MediaUpload{Kind: kind, Filename: kind, Data: data})
+	if err != nil {
+		t.Fatalf("Upload %s: %v", kind, err)
+	}
+	return m
+}
+
+func testPNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, w, h))); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func mediaIDs(items []Media) []uuid.UUID {
+	ids := make([]uuid.UUID, len(items))
+	for i, m := range items {
+		ids[i] = m.ID
+	}
+	return ids
+}
+
+func coverIDs(items []Media) []uuid.UUID {
+	var ids []uuid.UUID
+	for _, m := range items {
+		if m.Cover {
+			ids = append(ids, m.ID)
+		}
+	}
+	return ids
+}
+
+// memMediaRepo is an in-memory MediaRepo.
+type memMediaRepo struct {
+	items map[uuid.UUID]Media
+}
+
+func (r *memMediaRepo) CreateMedia(ctx context.Context, m *Media) error {
+	r.items[m.ID] = *m
+	return nil
+}
+
+func (r *memMediaRepo) GetMedia(ctx context.Context, propertyID, id uuid.UUID) (*Media, error) {
+	m, ok := r.items[id]
+	if !ok || m.PropertyID != propertyID {
+		return nil, ErrMediaNotFound
+	}
+	return &m, nil
+}
+
+func (r *memMediaRepo) ListMedia(ctx context.Context, propertyID uuid.UUID) ([]Media, error) {
+	var items []Media
+	for _, m := range r.items {
+		if m.PropertyID == propertyID {
+			items = append(items, m)
+		}
+	}
+	slices.SortFunc(items, func(a, b Media) int { return a.Position - b.Position })
+	return items, nil
+}
+
+func (r *memMediaRepo) SaveMedia(ctx context.Context, m *Media) error {
+	if _, ok := r.items[m.ID]; !ok {
+		return ErrMediaNotFound
+	}
+	r.items[m.ID] = *m
+	return nil
+}
+
+func (r *memMediaRepo) ArrangeMedia(ctx context.Context, propertyID uuid.UUID, items []Media) error {
+	for _, m := range items {
+		stored, ok := r.items[m.ID]
+		if !ok {
+			return ErrMediaNotFound
+		}
+		stored.Position, stored.Cover = m.Position, m.Cover
+		r.items[m.ID] = stored
+	}
+	return nil
+}
+
+func (r *memMediaRepo) DeleteMedia(ctx context.Context, propertyID, id uuid.UUID) error {
+	if _, err := r.GetMedia(ctx, propertyID, id); err != nil {
+		return err
+	}
+	delete(r.items, id)
+	return nil
+}
+
+// memBlobStore is an in-memory BlobStore.
+type memBlobStore struct {
+	blobs map[string][]byte
+}
+
+func (s *memBlobStore) Put(ctx context.Context, key, contentType string, r io.Reader) error {
+	data, err := io.ReadAll(r)
+	s.blobs[key] = data
+	return err
+}
+
+func (s *memBlobStore) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	data, ok := s.blobs[key]
+	if !ok {
+		return nil, ErrBlobNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (s *memBlobStore) Delete(ctx context.Context, key string) error {
+	delete(s.blobs, key)
+	return nil
+}
+
+func (s *memBlobStore) DeletePrefix(ctx context.Context, prefix string) error {
+	for key := range s.blobs {
+		if strings.HasPrefix(key, prefix) {
+			delete(s.blobs, key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,500 @@
+package estate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/pkg/lib/core"
+	"github.com/pulap/pulap/pkg/lib/telemetry"
+	"github.com/pulap/pulap/services/estate/internal/config"
+)
+
+// MediaHandler handles HTTP requests for the media of properties.
+type MediaHandler struct {
+	*PropertyAccess
+	media *MediaLibrary
+	tlm   *telemetry.HTTP
+}
+
+// NewMediaHandler creates a new MediaHandler storing media in media, which
+// is nil when the repository stores none; every media route then answers
+// 503.
+func NewMediaHandler(media *MediaLibrary, access *PropertyAccess, xparams config.XParams) *MediaHandler {
+	return &MediaHandler{
+		PropertyAccess: access,
+		media:          media,
+		tlm: telemetry.NewHTTP(
+			telemetry.WithTracer(xparams.Tracer()),
+			telemetry.WithMetrics(xparams.Metrics()),
+		),
+	}
+}
+
+// RegisterRoutes registers the media routes. Media content takes a bearer
+// token when one is sent, as portals pull the photos of listed properties
+// without one.
+func (h *MediaHandler) RegisterRoutes(r chi.Router) {
+	authn := h.authn()
+
+	r.With(optionalAuthn(authn)).Get("/estates/{id}/media/{mediaID}/content", h.GetMediaContent)
+	r.Group(func(r chi.Router) {
+		r.Use(authn)
+		r.Get("/estates/{id}/media", h.ListMedia)
+		r.Post("/estates/{id}/media", h.UploadMedia)
+		r.Put("/estates/{id}/media/order", h.ReorderMedia)
+		r.Get("/estates/{id}/media/{mediaID}", h.GetMedia)
+		r.Patch("/estates/{id}/media/{mediaID}", h.UpdateMedia)
+		r.Delete("/estates/{id}/media/{mediaID}", h.DeleteMedia)
+		r.Post("/estates/{id}/media/{mediaID}/cover", h.SetMediaCover)
+	})
+}
+
+// MaxMediaFiles bounds the number of files in a single upload request.
+const MaxMediaFiles = 10
+
+// mediaFormMemory is the part of a multipart upload kept in memory; the
+// rest is spooled to temporary files.
+const mediaFormMemory = 32 << 20
+
+// MediaUpdateRequest is the payload of PATCH /estates/{id}/media/{mediaID}.
+// Captions are merged by locale; an empty or null caption removes it.
+type MediaUpdateRequest struct {
+	Captions map[string]string `json:"captions"`
+}
+
+// MediaOrderRequest is the payload of PUT /estates/{id}/media/order.
+type MediaOrderRequest struct {
+	IDs []uuid.UUID `json:"ids"`
+}
+
+// MediaMeta describes a media list response.
+type MediaMeta struct {
+	Count int `json:"count"`
+}
+
+// UploadMedia handles POST /estates/{id}/media
+// The multipart form carries one or more "file" parts, an optional "kind"
+// (photo, floor_plan or document; photo by default) and optional captions
+// as "caption.<locale>" fields, applied to every file. Either every file
+// is stored or none is.
+func (h *MediaHandler) UploadMedia(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "MediaHandler.UploadMedia")
+	defer finish()
+	log := h.log(r)
+	ctx := r.Context()
+
+	id, ok := h.mediaProperty(w, r, log, PermissionWrite)
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, MaxMediaFiles*h.media.MaxSize()+MaxBodyBytes)
+	if err := r.ParseMultipartForm(mediaFormMemory); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			core.RespondError(w, http.StatusRequestEntityTooLarge, "Upload is too large")
+			return
+		}
+		log.Debug("error parsing multipart form", "error", err)
+		core.RespondError(w, http.StatusBadRequest, "Invalid multipart form")
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	files := r.MultipartForm.File["file"]
+	switch {
+	case len(files) == 0:
+		core.RespondError(w, http.StatusBadRequest, "No file uploaded")
+		return
+	case len(files) > MaxMediaFiles:
+		core.RespondError(w, http.StatusBadRequest, fmt.Sprintf("At most %d files can be uploaded at once", MaxMediaFiles))
+		return
+	}
+
+	captions := map[string]string{}
+	for key, values := range r.MultipartForm.Value {
+		if locale, ok := strings.CutPrefix(key, "caption."); ok && len(values) > 0 && values[0] != "" {
+			captions[locale] = values[0]
+		}
+	}
+	if len(captions) == 0 {
+		captions = nil
+	}
+
+	var created []Media
+	for _, fh := range files {
+		up := MediaUpload{
+			Kind:     strings.TrimSpace(r.FormValue("kind")),
+			Filename: path.Base(strings.ReplaceAll(fh.Filename, `\`, "/")),
+			Captions: captions,
+			Actor:    requestActor(r),
+		}
+
+		m, err := h.uploadFile(ctx, id, fh, up)
+		if err != nil {
+			for _, c := range created {
+				if err := h.media.Delete(ctx, id, c.ID); err != nil {
+					log.Error("cannot roll back media upload", "error", err, "id", id.String(), "media_id", c.ID.String())
+				}
+			}
+			code, msg := mediaErrorStatus(err)
+			if code == http.StatusInternalServerError {
+				log.Error("cannot upload media", "error", err, "id", id.String())
+				msg = "Could not store media"
+			}
+			core.RespondError(w, code, fmt.Sprintf("%s: %s", up.Filename, msg))
+			return
+		}
+		created = append(created, *m)
+	}
+
+	log.Info("media uploaded", "id", id.String(), "count", len(created))
+	w.WriteHeader(http.StatusCreated)
+	core.RespondSuccessWithMeta(w, withMediaURLs(created), MediaMeta{Count: len(created)})
+}
+
+// uploadFile reads a multipart file, refusing it before reading when its
+// declared size is over the limit.
+func (h *MediaHandler) uploadFile(ctx context.Context, id uuid.UUID, fh *multipart.FileHeader, up MediaUpload) (*Media, error) {
+	if fh.Size > h.media.MaxSize() {
+		return nil, fmt.Errorf("%w: %d bytes, limit is %d", ErrMediaTooLarge, fh.Size, h.media.MaxSize())
+	}
+
+	f, err := fh.Open()
+	if err != nil {
+		return nil, fmt.Errorf("cannot open upload: %w", err)
+	}
+	defer f.Close()
+
+	up.Data, err = io.ReadAll(io.LimitReader(f, h.media.MaxSize()+1))
+	if err != nil {
+		return nil, fmt.Errorf("cannot read upload: %w", err)
+	}
+
+	return h.media.Upload(ctx, id, up)
+}
+
+// ListMedia handles GET /estates/{id}/media
+func (h *MediaHandler) ListMedia(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "MediaHandler.ListMedia")
+	defer finish()
+	log := h.log(r)
+
+	id, ok := h.mediaProperty(w, r, log, PermissionRead)
+	if !ok {
+		return
+	}
+
+	items, err := h.media.List(r.Context(), id)
+	if err != nil {
+		log.Error("error listing media", "error", err, "id", id.String())
+		core.RespondError(w, http.StatusInternalServerError, "Could not retrieve media")
+		return
+	}
+
+	core.RespondSuccessWithMeta(w, withMediaURLs(items), MediaMeta{Count: len(items)})
+}
+
+// GetMedia handles GET /estates/{id}/media/{mediaID}
+func (h *MediaHandler) GetMedia(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "MediaHandler.GetMedia")
+	defer finish()
+	log := h.log(r)
+
+	m, ok := h.loadMedia(w, r, log, PermissionRead)
+	if !ok {
+		return
+	}
+
+	core.RespondSuccess(w, withMediaURLs([]Media{*m})[0])
+}
+
+// GetMediaContent handles GET /estates/{id}/media/{mediaID}/content
+// The original is served unless ?size= names a thumbnail. Photos of listed
+// properties are served without a token, as portals link them from the
+// feeds; other content only to users who can read the property.
+func (h *MediaHandler) GetMediaContent(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "MediaHandler.GetMediaContent")
+	defer finish()
+	log := h.log(r)
+
+	property, ok := h.loadMediaProperty(w, r, log, "")
+	if !ok {
+		return
+	}
+	m, ok := h.findMedia(w, r, log, property.ID)
+	if !ok {
+		return
+	}
+	// Photos of listed properties are public, as feeds link to them; any
+	// other content needs a caller who can read the property.
+	if m.Kind != MediaPhoto || !IsListedStatus(property.Status) {
+		if _, ok := core.GetUserIDFromContext(r.Context()); !ok {
+			core.RespondError(w, http.StatusNotFound, "Media not found")
+			return
+		}
+		if !h.authorizeProperty(w, r, property, PermissionRead) {
+			return
+		}
+	}
+
+	content, contentType, err := h.media.Open(r.Context(), m, r.URL.Query().Get("size"))
+	if err != nil {
+		if errors.Is(err, ErrMediaNotFound) {
+			core.RespondError(w, http.StatusNotFound, "Media content not found")
+			return
+		}
+		log.Error("error opening media", "error", err, "media_id", m.ID.String())
+		core.RespondError(w, http.StatusInternalServerError, "Could not retrieve media content")
+		return
+	}
+	defer content.Close()
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	// Content never changes for a media ID
+	w.Header().Set("Cache-Control", "private, max-age=31536000, immutable")
+	if m.Kind == MediaDocument {
+		w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", m.Filename))
+	}
+	if _, err := io.Copy(w, content); err != nil {
+		log.Debug("error writing media content", "error", err, "media_id", m.ID.String())
+	}
+}
+
+// UpdateMedia handles PATCH /estates/{id}/media/{mediaID}
+func (h *MediaHandler) UpdateMedia(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "MediaHandler.UpdateMedia")
+	defer finish()
+	log := h.log(r)
+
+	m, ok := h.loadMedia(w, r, log, PermissionWrite)
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, MaxBodyBytes)
+	defer r.Body.Close()
+
+	var req MediaUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Debug("error decoding media update", "error", err)
+		core.RespondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	updated, err := h.media.UpdateCaptions(r.Context(), m.PropertyID, m.ID, req.Captions)
+	if err != nil {
+		h.respondMediaError(w, log, err)
+		return
+	}
+
+	core.RespondSuccess(w, withMediaURLs([]Media{*updated})[0])
+}
+
+// ReorderMedia handles PUT /estates/{id}/media/order
+// The payload lists every media ID of the property in the new order.
+func (h *MediaHandler) ReorderMedia(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "MediaHandler.ReorderMedia")
+	defer finish()
+	log := h.log(r)
+
+	id, ok := h.mediaProperty(w, r, log, PermissionWrite)
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, MaxBodyBytes)
+	defer r.Body.Close()
+
+	var req MediaOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Debug("error decoding media order", "error", err)
+		core.RespondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	items, err := h.media.Reorder(r.Context(), id, req.IDs)
+	if err != nil {
+		h.respondMediaError(w, log, err)
+		return
+	}
+
+	core.RespondSuccessWithMeta(w, withMediaURLs(items), MediaMeta{Count: len(items)})
+}
+
+// SetMediaCover handles POST /estates/{id}/media/{mediaID}/cover
+func (h *MediaHandler) SetMediaCover(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "MediaHandler.SetMediaCover")
+	defer finish()
+	log := h.log(r)
+
+	m, ok := h.loadMedia(w, r, log, PermissionWrite)
+	if !ok {
+		return
+	}
+
+	items, err := h.media.SetCover(r.Context(), m.PropertyID, m.ID)
+	if err != nil {
+		h.respondMediaError(w, log, err)
+		return
+	}
+
+	core.RespondSuccessWithMeta(w, withMediaURLs(items), MediaMeta{Count: len(items)})
+}
+
+// DeleteMedia handles DELETE /estates/{id}/media/{mediaID}
+func (h *MediaHandler) DeleteMedia(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "MediaHandler.DeleteMedia")
+	defer finish()
+	log := h.log(r)
+
+	m, ok := h.loadMedia(w, r, log, PermissionWrite)
+	if !ok {
+		return
+	}
+
+	if err := h.media.Delete(r.Context(), m.PropertyID, m.ID); err != nil {
+		h.respondMediaError(w, log, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// mediaProperty parses the property ID and checks that the property exists,
+// that the user holds the permission on it, unless empty, and that media is
+// available, responding with the error when it returns false.
+func (h *MediaHandler) mediaProperty(w http.ResponseWriter, r *http.Request, log core.Logger, permission string) (uuid.UUID, bool) {
+	property, ok := h.loadMediaProperty(w, r, log, permission)
+	if !ok {
+		return uuid.Nil, false
+	}
+	return property.ID, true
+}
+
+// loadMediaProperty is mediaProperty returning the property.
+func (h *MediaHandler) loadMediaProperty(w http.ResponseWriter, r *http.Request, log core.Logger, permission string) (*Property, bool) {
+	if h.media == nil {
+		core.RespondError(w, http.StatusServiceUnavailable, "Media is not available")
+		return nil, false
+	}
+
+	id, ok := h.parseIDParam(w, r, log)
+	if !ok {
+		return nil, false
+	}
+
+	property, err := h.repo.Get(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			core.RespondError(w, http.StatusNotFound, "Property not found")
+			return nil, false
+		}
+		log.Error("error loading property", "error", err, "id", id.String())
+		core.RespondError(w, http.StatusInternalServerError, "Could not retrieve property")
+		return nil, false
+	}
+	if permission != "" && !h.authorizeProperty(w, r, property, permission) {
+		return nil, false
+	}
+
+	return property, true
+}
+
+// loadMedia loads the media item addressed by the id and mediaID URL
+// parameters, checking permission as mediaProperty does, responding with the
+// error when it returns false.
+func (h *MediaHandler) loadMedia(w http.ResponseWriter, r *http.Request, log core.Logger, permission string) (*Media, bool) {
+	id, ok := h.mediaProperty(w, r, log, permission)
+	if !ok {
+		return nil, false
+	}
+	return h.findMedia(w, r, log, id)
+}
+
+// findMedia loads the media item of a property addressed by the mediaID URL
+// parameter, responding with the error when it returns false.
+func (h *MediaHandler) findMedia(w http.ResponseWriter, r *http.Request, log core.Logger, id uuid.UUID) (*Media, bool) {
+	mediaID, err := uuid.Parse(chi.URLParam(r, "mediaID"))
+	if err != nil {
+		log.Debug("invalid media ID", "media_id", chi.URLParam(r, "mediaID"))
+		core.RespondError(w, http.StatusBadRequest, "Invalid media ID")
+		return nil, false
+	}
+
+	m, err := h.media.Get(r.Context(), id, mediaID)
+	if err != nil {
+		h.respondMediaError(w, log, err)
+		return nil, false
+	}
+
+	return m, true
+}
+
+func (h *MediaHandler) respondMediaError(w http.ResponseWriter, log core.Logger, err error) {
+	code, msg := mediaErrorStatus(err)
+	if code == http.StatusInternalServerError {
+		log.Error("media operation failed", "error", err)
+		msg = "Could not update media"
+	}
+	core.RespondError(w, code, msg)
+}
+
+// mediaErrorStatus maps a MediaLibrary error to a status code and message.
+func mediaErrorStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return http.StatusNotFound, "Property not found"
+	case errors.Is(err, ErrMediaNotFound):
+		return http.StatusNotFound, "Media not found"
+	case errors.Is(err, ErrMediaTooLarge):
+		return http.StatusRequestEntityTooLarge, err.Error()
+	case errors.Is(err, ErrUnsupportedMedia):
+		return http.StatusUnsupportedMediaType, err.Error()
+	case errors.Is(err, ErrInvalidMedia):
+		return http.StatusBadRequest, err.Error()
+	default:
+		return http.StatusInternalServerError, err.Error()
+	}
+}
+
+// withMediaURLs sets the content URLs of items.
+func withMediaURLs(items []Media) []Media {
+	out := make([]Media, len(items))
+	for i, m := range items {
+		base := fmt.Sprintf("/estates/%s/media/%s/content", m.PropertyID, m.ID)
+		m.URL = base
+		m.Thumbnails = append([]Thumbnail(nil), m.Thumbnails...)
+		for j := range m.Thumbnails {
+			m.Thumbnails[j].URL = base + "?size=" + m.Thumbnails[j].Size
+		}
+		out[i] = m
+	}
+	return out
+}
+
+// optionalAuthn applies authn to the requests that carry an Authorization
+// header and passes the others on anonymously.
+func optionalAuthn(authn func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		authenticated := authn(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			authenticated.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package estate
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/services/estate/internal/money"
+)
+
+// Valuation is the primary price of a property (the first of Prices)
+// converted to the base currency, so properties priced in different
+// currencies can be compared. It is derived by Pricing on every write and
+// when exchange rates change; values sent by clients are ignored.
+type Valuation struct {
+	Currency       string        `json:"currency"`   // Base currency
+	PriceType      string        `json:"price_type"` // Type of the primary price
+	Amount         money.Decimal `json:"amount"`
+	PerSquareMeter money.Decimal `json:"per_square_meter"`    // Amount per m² of total area, zero when the area is unknown
+	RateDate       string        `json:"rate_date,omitempty"` // Oldest rate used (YYYY-MM-DD), empty when priced in the base currency
+}
+
+// Equal reports whether both valuations hold the same values.
+func (v *Valuation) Equal(o *Valuation) bool {
+	if v == nil || o == nil {
+		return v == o
+	}
+	return v.Currency == o.Currency && v.PriceType == o.PriceType && v.RateDate == o.RateDate &&
+		v.Amount.Equal(o.Amount) && v.PerSquareMeter.Equal(o.PerSquareMeter)
+}
+
+// PricingRepo stores exchange rates and the derived property valuations.
+type PricingRepo interface {
+	// SaveRates stores rates, replacing any with the same pair and date.
+	SaveRates(ctx context.Context, rates []money.Rate) error
+
+	// ListRates retrieves every stored rate.
+	ListRates(ctx context.Context) ([]money.Rate, error)
+
+	// SaveValuation replaces the valuation of a property, nil clearing it.
+	// It does not change the revision, as valuations are derived data.
+	// It returns ErrNotFound if the property does not exist.
+	SaveValuation(ctx context.Context, id uuid.UUID, v *Valuation) error
+}
+
+// RateImport summarizes an ImportRates run.
+type RateImport struct {
+	Imported    int `json:"imported"`    // Rates received
+	Stored      int `json:"stored"`      // Distinct rates stored afterwards
+	Revalued    int `json:"revalued"`    // Properties whose valuation changed
+	Unconverted int `json:"unconverted"` // Properties without a rate to the base currency
+}
+
+// Pricing values properties in the base currency using the stored exchange
+// rates, always with the latest rate in effect.
+type Pricing struct {
+	repo  Repo
+	store PricingRepo
+	base  string
+	now   func() time.Time
+
+	mu    sync.RWMutex
+	rates *money.RateTable
+}
+
+// NewPricing creates a Pricing valuing properties of repo in the base
+// currency with the rates kept in store.
+func NewPricing(repo Repo, store PricingRepo, base string) *Pricing {
+	return &Pricing{
+		repo:  repo,
+		store: store,
+		base:  base,
+		now:   time.Now,
+		rates: money.NewRateTable(nil),
+	}
+}
+
+// Start validates the base currency and loads the stored rates. The store
+// must be started before.
+func (p *Pricing) Start(ctx context.Context) error {
+	if !money.IsCurrency(p.base) {
+		return fmt.Errorf("pricing base currency: %w: %q", money.ErrUnknownCurrency, p.base)
+	}
+	return p.reload(ctx)
+}
+
+// Base returns the base currency.
+func (p *Pricing) Base() string {
+	return p.base
+}
+
+// Rates retrieves the stored rates.
+func (p *Pricing) Rates(ctx context.Context) ([]money.Rate, error) {
+	return p.store.ListRates(ctx)
+}
+
+// Value sets the valuation of the property from its primary price. It is
+// cleared when the property has no price or no rate reaches the base currency.
+func (p *Pricing) Value(property *Property) {
+	p.mu.RLock()
+	rates := p.rates
+	p.mu.RUnlock()
+
+	property.Valuation = p.valuation(property, rates)
+}
+
+// ImportRates validates and stores rates, then revalues every property with
+// the updated table.
+func (p *Pricing) ImportRates(ctx context.Context, rates []money.Rate) (*RateImport, error) {
+	for i, r := range rates {
+		if err := r.Validate(); err != nil {
+			return nil, fmt.Errorf("rate %d: %w", i+1, err)
+		}
+	}
+
+	if err := p.store.SaveRates(ctx, rates); err != nil {
+		return nil, err
+	}
+	if err := p.reload(ctx); err != nil {
+		return nil, err
+	}
+
+	result, err := p.Revalue(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result.Imported = len(rates)
+	return result, nil
+}
+
+// Revalue recomputes the valuation of every property, saving those that
+// changed.
+func (p *Pricing) Revalue(ctx context.Context) (*RateImport, error) {
+	properties, err := p.repo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list properties: %w", err)
+	}
+
+	p.mu.RLock()
+	rates := p.rates
+	p.mu.RUnlock()
+
+	result := &RateImport{Stored: rates.Len()}
+	for _, property := range properties {
+		v := p.valuation(property, rates)
+		if v == nil && len(property.Prices) > 0 {
+			result.Unconverted++
+		}
+		if v.Equal(property.Valuation) {
+			continue
+		}
+		if err := p.store.SaveValuation(ctx, property.ID, v); err != nil {
+			return nil, fmt.Errorf("cannot revalue property %s: %w", property.ID, err)
+		}
+		result.Revalued++
+	}
+	return result, nil
+}
+
+func (p *Pricing) reload(ctx context.Context) error {
+	rates, err := p.store.ListRates(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot load exchange rates: %w", err)
+	}
+
+	table := money.NewRateTable(rates)
+	p.mu.Lock()
+	p.rates = table
+	p.mu.Unlock()
+	return nil
+}
+
+func (p *Pricing) valuation(property *Property, rates *money.RateTable) *Valuation {
+	if len(property.Prices) == 0 {
+		return nil
+	}
+	price := property.Prices[0]
+
+	base, err := money.LookupCurrency(p.base)
+	if err != nil || !money.IsCurrency(price.Currency) {
+		return nil
+	}
+	amount, date, err := rates.Convert(price.Amount, price.Currency, p.base, p.now())
+	if err != nil {
+		return nil
+	}
+
+	v := &Valuation{
+		Currency:  p.base,
+		PriceType: price.Type,
+		Amount:    base.Round(amount),
+	}
+	if !date.IsZero() {
+		v.RateDate = date.Format(money.DateLayout)
+	}
+	if area, err := money.FromFloat(property.Features.TotalArea); err == nil && area.Sign() > 0 {
+		v.PerSquareMeter = amount.Quo(area, base.Minor)
+	}
+	return v
+}
+
+// PricedRepo decorates a Repo valuing properties before they are created or
+// saved, so stored valuations follow price and area changes.
+type PricedRepo struct {
+	Repo
+	pricing *Pricing
+}
+
+// NewPricedRepo wraps repo so that writes are valued with pricing.
+func NewPricedRepo(repo Repo, pricing *Pricing) *PricedRepo {
+	return &PricedRepo{Repo: repo, pricing: pricing}
+}
+
+// Create values the property and creates it.
+func (r *PricedRepo) Create(ctx context.Context, property *Property) error {
+	r.pricing.Value(property)
+	return r.Repo.Create(ctx, property)
+}
+
+// Save values the property and saves it.
+func (r *PricedRepo) Save(ctx context.Context, property *Property) error {
+	r.pricing.Value(property)
+	return r.Repo.Save(ctx, property)
+}
@@ -0,0 +1,113 @@
+package estate
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/services/estate/internal/money"
+)
+
+func TestPriceValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		price   Price
+		wantErr string
+	}{
+		{name: "valid", price: Price{Amount: money.MustParse("1250.50"), Currency: "EUR", Type: "sale"}},
+		{name: "zero decimals", price: Price{Amount: money.MustParse("150000"), Currency: "JPY", Type: "rent_monthly"}},
+		{name: "negative", price: Price{Amount: money.MustParse("-1"), Currency: "EUR", Type: "sale"}, wantErr: "cannot be negative"},
+		{name: "unknown currency", price: Price{Amount: money.FromInt(1), Currency: "EURO", Type: "sale"}, wantErr: "ISO 4217"},
+		{name: "too many decimals", price: Price{Amount: money.MustParse("10.5"), Currency: "JPY", Type: "sale"}, wantErr: "more than 0 decimals in JPY"},
+		{name: "too many digits", price: Price{Amount: money.MustParse("1234567890123456789012345678901234.5"), Currency: "EUR", Type: "sale"}, wantErr: "more than 34 digits"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := tt.price.Validate()
+			if tt.wantErr == "" {
+				if len(errs) > 0 {
+					t.Fatalf("unexpected errors: %v", errs)
+				}
+				return
+			}
+			if !slices.ContainsFunc(errs, func(e string) bool { return strings.Contains(e, tt.wantErr) }) {
+				t.Errorf("expected an error containing %q, got %v", tt.wantErr, errs)
+			}
+		})
+	}
+}
+
+func TestPricingValue(t *testing.T) {
+	store := &memPricingRepo{rates: []money.Rate{
+		{From: "EUR", To: "USD", Date: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Value: money.MustParse("1.0950")},
+		{From: "EUR", To: "USD", Date: time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC), Value: money.MustParse("9")},
+	}}
+	pricing := NewPricing(nil, store, "USD")
+	if err := pricing.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		prices []Price
+		area   float64
+		want   *Valuation
+	}{
+		{
+			name:   "converted",
+			prices: []Price{{Amount: money.MustParse("350000.50"), Currency: "EUR", Type: "sale"}, {Amount: money.FromInt(1500), Currency: "EUR", Type: "rent_monthly"}},
+			area:   85,
+			want:   &Valuation{Currency: "USD", PriceType: "sale", Amount: money.MustParse("383250.55"), PerSquareMeter: money.MustParse("4508.83"), RateDate: "2024-01-01"},
+		},
+		{
+			name:   "base currency",
+			prices: []Price{{Amount: money.FromInt(990), Currency: "USD", Type: "rent_monthly"}},
+			want:   &Valuation{Currency: "USD", PriceType: "rent_monthly", Amount: money.MustParse("990.00")},
+		},
+		{name: "no rate", prices: []Price{{Amount: money.FromInt(1000), Currency: "JPY", Type: "sale"}}},
+		{name: "no price"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := New()
+			p.Prices = tt.prices
+			p.Features.TotalArea = tt.area
+			p.Valuation = &Valuation{Currency: "USD"}
+
+			pricing.Value(p)
+			if !p.Valuation.Equal(tt.want) {
+				t.Errorf("expected %+v, got %+v", tt.want, p.Valuation)
+			}
+		})
+	}
+}
+
+func TestPricingStartUnknownBase(t *testing.T) {
+	pricing := NewPricing(nil, &memPricingRepo{}, "XYZ")
+	if err := pricing.Start(context.Background()); err == nil {
+		t.Error("expected an error for an unknown base currency")
+	}
+}
+
+type memPricingRepo struct {
+	rates []money.Rate
+}
+
+func (r *memPricingRepo) SaveRates(ctx context.Context, rates []money.Rate) error {
+	r.rates = append(r.rates, rates...)
+	return nil
+}
+
+func (r *memPricingRepo) ListRates(ctx context.Context) ([]money.Rate, error) {
+	return r.rates, nil
+}
+
+func (r *memPricingRepo) SaveValuation(ctx context.Context, id uuid.UUID, v *Valuation) error {
+	return nil
+}
@@ -0,0 +1,159 @@
+package estate
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/pulap/pulap/pkg/lib/core"
+	"github.com/pulap/pulap/pkg/lib/telemetry"
+	"github.com/pulap/pulap/services/estate/internal/config"
+	"github.com/pulap/pulap/services/estate/internal/money"
+)
+
+// PricingHandler handles HTTP requests for exchange rates.
+type PricingHandler struct {
+	*PropertyAccess
+	pricing *Pricing
+	tlm     *telemetry.HTTP
+}
+
+// NewPricingHandler creates a new PricingHandler converting prices with
+// pricing, which is nil when the repository stores no rates; every rate
+// route then answers 503.
+func NewPricingHandler(pricing *Pricing, access *PropertyAccess, xparams config.XParams) *PricingHandler {
+	return &PricingHandler{
+		PropertyAccess: access,
+		pricing:        pricing,
+		tlm: telemetry.NewHTTP(
+			telemetry.WithTracer(xparams.Tracer()),
+			telemetry.WithMetrics(xparams.Metrics()),
+		),
+	}
+}
+
+// RegisterRoutes registers the exchange rate routes.
+func (h *PricingHandler) RegisterRoutes(r chi.Router) {
+	r.Route("/exchange-rates", func(r chi.Router) {
+		r.Use(h.authn())
+		r.Get("/", h.ListRates)
+		r.Post("/", h.ImportRates)
+	})
+}
+
+// PermissionRatesWrite allows importing exchange rates.
+const PermissionRatesWrite = "estates:rates_write"
+
+// ratesResource is the authz resource exchange rate imports are checked on.
+const ratesResource = "exchange-rates"
+
+// MaxRatesBodyBytes bounds the size of an exchange rate import.
+const MaxRatesBodyBytes = 4 << 20 // 4 MB
+
+// RatesMeta is the meta of the exchange rates list.
+type RatesMeta struct {
+	Base  string `json:"base"`
+	Count int    `json:"count"`
+}
+
+// ListRates handles GET /exchange-rates
+// Rates are sorted by pair and date; ?from= and ?to= filter by currency.
+func (h *PricingHandler) ListRates(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "PricingHandler.ListRates")
+	defer finish()
+	log := h.log(r)
+
+	if h.pricing == nil {
+		core.RespondError(w, http.StatusServiceUnavailable, "Pricing is not available")
+		return
+	}
+
+	rates, err := h.pricing.Rates(r.Context())
+	if err != nil {
+		log.Error("error listing exchange rates", "error", err)
+		core.RespondError(w, http.StatusInternalServerError, "Could not retrieve exchange rates")
+		return
+	}
+
+	from, to := r.URL.Query().Get("from"), r.URL.Query().Get("to")
+	out := make([]money.Rate, 0, len(rates))
+	for _, rate := range rates {
+		if (from == "" || rate.From == from) && (to == "" || rate.To == to) {
+			out = append(out, rate)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if a.To != b.To {
+			return a.To < b.To
+		}
+		return a.Date.Before(b.Date)
+	})
+
+	core.RespondSuccessWithMeta(w, out, RatesMeta{Base: h.pricing.Base(), Count: len(out)})
+}
+
+// ImportRates handles POST /exchange-rates
+// The body is a JSON array of rates or, with Content-Type text/csv, a CSV
+// file with date, from, to and rate columns. Rates of an existing pair and
+// date are replaced and every property is revalued. Requires
+// PermissionRatesWrite.
+func (h *PricingHandler) ImportRates(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "PricingHandler.ImportRates")
+	defer finish()
+	log := h.log(r)
+	ctx := r.Context()
+
+	if h.pricing == nil {
+		core.RespondError(w, http.StatusServiceUnavailable, "Pricing is not available")
+		return
+	}
+
+	actor := requestActor(r)
+	if actor == "" {
+		core.RespondError(w, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+	if status, msg := h.checkPermission(ctx, actor, PermissionRatesWrite, ratesResource); status != 0 {
+		log.Info("exchange rate import denied", "actor", actor)
+		core.RespondError(w, status, msg)
+		return
+	}
+
+	format := "json"
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "text/csv" {
+		format = "csv"
+	}
+
+	rates, err := money.ReadRates(http.MaxBytesReader(w, r.Body, MaxRatesBodyBytes), format)
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			core.RespondError(w, http.StatusRequestEntityTooLarge, "Request body too large")
+			return
+		}
+		core.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid exchange rates: %v", err))
+		return
+	}
+	if len(rates) == 0 {
+		core.RespondError(w, http.StatusBadRequest, "At least one exchange rate is required")
+		return
+	}
+
+	result, err := h.pricing.ImportRates(ctx, rates)
+	if err != nil {
+		log.Error("cannot import exchange rates", "error", err)
+		core.RespondError(w, http.StatusInternalServerError, "Could not import exchange rates")
+		return
+	}
+
+	log.Info("exchange rates imported", "actor", actor, "imported", result.Imported, "revalued", result.Revalued)
+	core.RespondSuccess(w, result)
+}
@@ -1,10 +1,15 @@
 package estate
 
 import (
+	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/pulap/pulap/pkg/lib/core"
+
+	"github.com/pulap/pulap/services/estate/internal/money"
 )
 
 // Property is the aggregate root for the real estate domain.
@@ -12,39 +17,55 @@ import (
 // with its classification, location, physical features, and pricing information.
 type Property struct {
 	ID             uuid.UUID      `json:"id"`
-	Name           string         `json:"name"`               // Short name/title for the property
-	Description    string         `json:"description"`        // Detailed description
-	Classification Classification `json:"classification"`     // Category, Type, Subtype (fake refs)
-	Location       Location       `json:"location"`           // Address and coordinates
-	Features       Features       `json:"features"`           // Physical characteristics
-	Prices         []Price        `json:"prices"`             // Pricing information by type
-	Status         string         `json:"status"`             // e.g., "available", "sold", "rented", "reserved"
-	OwnerID        string         `json:"owner_id,omitempty"` // Reference to owner/user
+	Name           string         `json:"name"`                     // Short name/title for the property
+	Description    string         `json:"description"`              // Detailed description
+	Classification Classification `json:"classification"`           // Category, Type, Subtype (fake refs)
+	Location       Location       `json:"location"`                 // Address and coordinates
+	Features       Features       `json:"features"`                 // Physical characteristics
+	Prices         []Price        `json:"prices"`                   // Pricing information by type
+	Valuation      *Valuation     `json:"valuation,omitempty"`      // Primary price in the base currency, derived (see Pricing)
+	Status         string         `json:"status"`                   // e.g., "available", "sold", "rented", "reserved"
+	OwnerID        string         `json:"owner_id,omitempty"`       // Reference to owner/user
+	TeamID         string         `json:"team_id,omitempty"`        // Team of the owner the property is shared with
+	DevelopmentID  *uuid.UUID     `json:"development_id,omitempty"` // Building or project the property is a unit of
 	SchemaVersion  int            `json:"schema_version"`
+	Revision       int64          `json:"revision"` // Incremented on every write, exposed as the ETag
 	CreatedAt      time.Time      `json:"created_at"`
 	CreatedBy      string         `json:"created_by"`
 	UpdatedAt      time.Time      `json:"updated_at"`
 	UpdatedBy      string         `json:"updated_by"`
+	DeletedAt      *time.Time     `json:"deleted_at,omitempty"` // Set while the property is in the trash
+	DeletedBy      string         `json:"deleted_by,omitempty"`
 }
 
+// PriceTypes lists the accepted Price.Type values.
+var PriceTypes = []string{"sale", "rent_monthly", "rent_daily", "rent_weekly", "rent_yearly"}
+
 // Price represents pricing information for a property.
 type Price struct {
-	Amount     float64 `json:"amount"`     // Price amount
-	Currency   string  `json:"currency"`   // e.g., "USD", "EUR", "ARS"
-	Type       string  `json:"type"`       // e.g., "sale", "rent_monthly", "rent_daily"
-	Negotiable bool    `json:"negotiable"` // Whether price is negotiable
+	Amount     money.Decimal `json:"amount"`     // Price amount, exact
+	Currency   string        `json:"currency"`   // ISO 4217 code, e.g., "USD", "EUR", "ARS"
+	Type       string        `json:"type"`       // e.g., "sale", "rent_monthly", "rent_daily"
+	Negotiable bool          `json:"negotiable"` // Whether price is negotiable
 }
 
 // Validate performs basic validation on the price.
 func (p Price) Validate() []string {
 	var errors []string
 
-	if p.Amount < 0 {
+	if p.Amount.Sign() < 0 {
 		errors = append(errors, "price.amount cannot be negative")
 	}
+	if p.Amount.Digits() > money.MaxStoredDigits {
+		errors = append(errors, fmt.Sprintf("price.amount cannot have more than %d digits", money.MaxStoredDigits))
+	}
 
 	if p.Currency == "" {
 		errors = append(errors, "price.currency is required")
+	} else if currency, err := money.LookupCurrency(p.Currency); err != nil {
+		errors = append(errors, "price.currency must be an ISO 4217 code")
+	} else if !currency.Fits(p.Amount) {
+		errors = append(errors, fmt.Sprintf("price.amount cannot have more than %d decimals in %s", currency.Minor, p.Currency))
 	}
 
 	if p.Type == "" {
@@ -52,15 +73,8 @@ func (p Price) Validate() []string {
 	}
 
 	// Validate price type
-	validPriceTypes := map[string]bool{
-		"sale":         true,
-		"rent_monthly": true,
-		"rent_daily":   true,
-		"rent_weekly":  true,
-		"rent_yearly":  true,
-	}
-	if !validPriceTypes[p.Type] {
-		errors = append(errors, "price.type must be one of: sale, rent_monthly, rent_daily, rent_weekly, rent_yearly")
+	if !slices.Contains(PriceTypes, p.Type) {
+		errors = append(errors, "price.type must be one of: "+strings.Join(PriceTypes, ", "))
 	}
 
 	return errors
@@ -105,7 +119,8 @@ func (p *Property) EnsureID() {
 	}
 }
 
-// BeforeCreate sets creation timestamps.
+// BeforeCreate sets creation timestamps. Properties are always written in
+// the current schema version, whatever version a client sent.
 func (p *Property) BeforeCreate() {
 	p.EnsureID()
 	p.CreatedAt = time.Now()
@@ -113,15 +128,12 @@ func (p *Property) BeforeCreate() {
 	if p.Status == "" {
 		p.Status = "available"
 	}
-	if p.SchemaVersion == 0 {
-		p.SchemaVersion = currentSchemaVersion
-	}
+	p.SchemaVersion = currentSchemaVersion
+	p.Revision = 1
 }
 
-// BeforeUpdate sets update timestamps.
+// BeforeUpdate sets update timestamps and the current schema version.
 func (p *Property) BeforeUpdate() {
 	p.UpdatedAt = time.Now()
-	if p.SchemaVersion == 0 {
-		p.SchemaVersion = currentSchemaVersion
-	}
+	p.SchemaVersion = currentSchemaVersion
 }
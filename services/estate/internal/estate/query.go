@@ -0,0 +1,472 @@
+package estate
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/services/estate/internal/money"
+)
+
+const (
+	// DefaultSearchLimit is the page size used when a query does not set one.
+	DefaultSearchLimit = 50
+	// MaxSearchLimit caps the page size a client may request.
+	MaxSearchLimit = 200
+)
+
+// PropertyQuery describes a filtered, sorted and paginated property search.
+// All filters are combined with AND; zero values mean "no filter".
+type PropertyQuery struct {
+	IDs      []uuid.UUID // Restricts results to these properties, e.g. full-text hits
+	OwnerID  string
+	TeamID   string
+	Statuses []string
+
+	// Scoped restricts results to the properties owned by, or assigned to the
+	// team of, one of Scopes; see Access.
+	Scoped bool
+	Scopes []string
+
+	CategoryIDs []uuid.UUID
+	TypeIDs     []uuid.UUID
+	SubtypeIDs  []uuid.UUID
+
+	// DevelopmentIDs restricts results to the units of these developments.
+	DevelopmentIDs []uuid.UUID
+
+	City    string // Case-insensitive exact match
+	Country string // Case-insensitive exact match
+
+	// Price matches properties having at least one price that satisfies every field set.
+	Price *PriceFilter
+
+	Bedrooms    IntRange
+	Bathrooms   IntRange
+	TotalArea   FloatRange
+	CoveredArea FloatRange
+	YearBuilt   IntRange
+
+	// Flags lists boolean amenity flags (see AmenityFlags) that must be true.
+	Flags []string
+	// Amenities lists entries of Features.Amenities that must all be present.
+	Amenities []string
+
+	// Deleted searches the properties in the trash instead of the live ones.
+	Deleted bool
+
+	Sort   []SortOrder
+	Limit  int
+	Cursor string
+}
+
+// PriceFilter restricts the price range for a price type and currency.
+type PriceFilter struct {
+	Type     string
+	Currency string
+	Min      *float64
+	Max      *float64
+}
+
+// IntRange is an inclusive integer range; nil bounds are open.
+type IntRange struct {
+	Min *int
+	Max *int
+}
+
+// IsZero returns true if no bound is set.
+func (r IntRange) IsZero() bool {
+	return r.Min == nil && r.Max == nil
+}
+
+// FloatRange is an inclusive decimal range; nil bounds are open.
+type FloatRange struct {
+	Min *float64
+	Max *float64
+}
+
+// IsZero returns true if no bound is set.
+func (r FloatRange) IsZero() bool {
+	return r.Min == nil && r.Max == nil
+}
+
+// PropertyPage is a page of search results.
+type PropertyPage struct {
+	Items      []*Property
+	Total      int64  // Matches for the filters, regardless of pagination
+	NextCursor string // Empty on the last page
+}
+
+// SortField is a property attribute results can be ordered by.
+type SortField string
+
+const (
+	SortCreatedAt   SortField = "created_at"
+	SortUpdatedAt   SortField = "updated_at"
+	SortName        SortField = "name"
+	SortBedrooms    SortField = "bedrooms"
+	SortBathrooms   SortField = "bathrooms"
+	SortTotalArea   SortField = "total_area"
+	SortCoveredArea SortField = "covered_area"
+	SortYearBuilt   SortField = "year_built"
+	SortPrice       SortField = "price"        // Valuation amount in the base currency
+	SortPricePerM2  SortField = "price_per_m2" // Valuation amount per m² of total area
+	SortDeletedAt   SortField = "deleted_at"   // Only for the trash (PropertyQuery.Deleted)
+)
+
+var sortFields = map[SortField]bool{
+	SortCreatedAt:   true,
+	SortUpdatedAt:   true,
+	SortName:        true,
+	SortBedrooms:    true,
+	SortBathrooms:   true,
+	SortTotalArea:   true,
+	SortCoveredArea: true,
+	SortYearBuilt:   true,
+	SortPrice:       true,
+	SortPricePerM2:  true,
+	SortDeletedAt:   true,
+}
+
+// SortOrder orders results by a field.
+type SortOrder struct {
+	Field SortField
+	Desc  bool
+}
+
+// DefaultSort is applied when a query does not specify an order: newest first.
+var DefaultSort = []SortOrder{{Field: SortCreatedAt, Desc: true}}
+
+// TrashSort is applied when a trash query does not specify an order: most
+// recently deleted first.
+var TrashSort = []SortOrder{{Field: SortDeletedAt, Desc: true}}
+
+// AmenityFlags lists the boolean Features fields accepted in PropertyQuery.Flags.
+var AmenityFlags = []string{
+	"pool", "garden", "balcony", "terrace", "elevator", "air_conditioning",
+	"heating", "furnished", "pet_friendly", "storage", "laundry", "fireplace",
+}
+
+// Normalize fills defaults and validates the query.
+func (q *PropertyQuery) Normalize() []ValidationError {
+	var errors []ValidationError
+
+	if q.Limit <= 0 {
+		q.Limit = DefaultSearchLimit
+	}
+	if q.Limit > MaxSearchLimit {
+		q.Limit = MaxSearchLimit
+	}
+
+	if len(q.Sort) == 0 {
+		q.Sort = DefaultSort
+		if q.Deleted {
+			q.Sort = TrashSort
+		}
+	}
+	for _, s := range q.Sort {
+		if !sortFields[s.Field] || (s.Field == SortDeletedAt && !q.Deleted) {
+			errors = append(errors, ValidationError{Field: "sort", Message: fmt.Sprintf("cannot sort by %q", s.Field)})
+		}
+	}
+
+	for _, flag := range q.Flags {
+		if !isAmenityFlag(flag) {
+			errors = append(errors, ValidationError{Field: "features", Message: fmt.Sprintf("unknown amenity flag %q", flag)})
+		}
+	}
+
+	if q.Price != nil && q.Price.Min != nil && q.Price.Max != nil && *q.Price.Min > *q.Price.Max {
+		errors = append(errors, ValidationError{Field: "price", Message: "price_min cannot be greater than price_max"})
+	}
+
+	q.Country = strings.TrimSpace(q.Country)
+	q.City = strings.TrimSpace(q.City)
+
+	if q.Cursor != "" {
+		if _, err := q.CursorValues(); err != nil {
+			errors = append(errors, ValidationError{Field: "cursor", Message: err.Error()})
+		}
+	}
+
+	return errors
+}
+
+// ParsePropertyQuery builds a PropertyQuery from URL query parameters.
+//
+// Supported parameters: owner_id, team_id, status, category_id, type_id, subtype_id,
+// development_id (comma separated lists), city, country, price_type, currency, price_min,
+// price_max, bedrooms_min, bedrooms_max, bathrooms_min, bathrooms_max,
+// total_area_min, total_area_max, covered_area_min, covered_area_max,
+// year_built_min, year_built_max, features (amenity flags), amenities,
+// sort (e.g. "-created_at,name"), limit and cursor.
+func ParsePropertyQuery(values url.Values) (PropertyQuery, []ValidationError) {
+	return parsePropertyQuery(values, false)
+}
+
+// ParseTrashQuery builds a query of the properties in the trash from the
+// parameters of ParsePropertyQuery; they may also be sorted by deleted_at.
+func ParseTrashQuery(values url.Values) (PropertyQuery, []ValidationError) {
+	return parsePropertyQuery(values, true)
+}
+
+func parsePropertyQuery(values url.Values, deleted bool) (PropertyQuery, []ValidationError) {
+	p := queryParser{values: values}
+
+	q := PropertyQuery{
+		Deleted:        deleted,
+		OwnerID:        values.Get("owner_id"),
+		TeamID:         values.Get("team_id"),
+		Statuses:       splitList(values.Get("status")),
+		CategoryIDs:    p.uuids("category_id"),
+		TypeIDs:        p.uuids("type_id"),
+		SubtypeIDs:     p.uuids("subtype_id"),
+		DevelopmentIDs: p.uuids("development_id"),
+		City:           values.Get("city"),
+		Country:        values.Get("country"),
+		Bedrooms:       p.intRange("bedrooms"),
+		Bathrooms:      p.intRange("bathrooms"),
+		TotalArea:      p.floatRange("total_area"),
+		CoveredArea:    p.floatRange("covered_area"),
+		YearBuilt:      p.intRange("year_built"),
+		Flags:          splitList(values.Get("features")),
+		Amenities:      splitList(values.Get("amenities")),
+		Limit:          p.int("limit"),
+		Cursor:         values.Get("cursor"),
+	}
+
+	price := PriceFilter{
+		Type:     values.Get("price_type"),
+		Currency: strings.ToUpper(values.Get("currency")),
+		Min:      p.float("price_min"),
+		Max:      p.float("price_max"),
+	}
+	if price != (PriceFilter{}) {
+		q.Price = &price
+	}
+
+	for _, field := range splitList(values.Get("sort")) {
+		order := SortOrder{Field: SortField(strings.TrimPrefix(field, "-")), Desc: strings.HasPrefix(field, "-")}
+		q.Sort = append(q.Sort, order)
+	}
+
+	errors := p.errors
+	errors = append(errors, q.Normalize()...)
+	return q, errors
+}
+
+// CursorValues decodes the cursor into the sort values of the last seen item,
+// followed by its ID. Values are typed per sort field: time.Time, string, int,
+// float64 or money.Decimal.
+func (q *PropertyQuery) CursorValues() ([]any, error) {
+	if q.Cursor == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(q.Cursor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor encoding")
+	}
+
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil || len(raw) != len(q.Sort)+1 {
+		return nil, fmt.Errorf("cursor does not match sort order")
+	}
+
+	values := make([]any, 0, len(raw))
+	for i, s := range q.Sort {
+		v, err := decodeSortValue(s.Field, raw[i])
+		if err != nil {
+			return nil, fmt.Errorf("cursor does not match sort order")
+		}
+		values = append(values, v)
+	}
+
+	var id string
+	if err := json.Unmarshal(raw[len(raw)-1], &id); err != nil {
+		return nil, fmt.Errorf("invalid cursor id")
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, fmt.Errorf("invalid cursor id")
+	}
+
+	return append(values, id), nil
+}
+
+// NextCursor encodes the position after p for the given sort order.
+func NextCursor(p *Property, sort []SortOrder) string {
+	values := make([]any, 0, len(sort)+1)
+	for _, s := range sort {
+		values = append(values, SortValue(p, s.Field))
+	}
+	values = append(values, p.ID.String())
+
+	data, _ := json.Marshal(values)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// SortValue returns the value of a sort field for a property.
+// Timestamps are returned in UTC so backends compare them consistently.
+// Prices are money.Decimal values, zero for properties without a valuation.
+func SortValue(p *Property, field SortField) any {
+	switch field {
+	case SortCreatedAt:
+		return p.CreatedAt.UTC()
+	case SortUpdatedAt:
+		return p.UpdatedAt.UTC()
+	case SortName:
+		return p.Name
+	case SortBedrooms:
+		return p.Features.Bedrooms
+	case SortBathrooms:
+		return p.Features.Bathrooms
+	case SortTotalArea:
+		return p.Features.TotalArea
+	case SortCoveredArea:
+		return p.Features.CoveredArea
+	case SortYearBuilt:
+		return p.Features.YearBuilt
+	case SortPrice:
+		if p.Valuation == nil {
+			return money.Decimal{}
+		}
+		return p.Valuation.Amount
+	case SortPricePerM2:
+		if p.Valuation == nil {
+			return money.Decimal{}
+		}
+		return p.Valuation.PerSquareMeter
+	case SortDeletedAt:
+		if p.DeletedAt == nil {
+			return time.Time{}
+		}
+		return p.DeletedAt.UTC()
+	default:
+		return nil
+	}
+}
+
+func decodeSortValue(field SortField, raw json.RawMessage) (any, error) {
+	switch field {
+	case SortCreatedAt, SortUpdatedAt, SortDeletedAt:
+		var t time.Time
+		err := json.Unmarshal(raw, &t)
+		return t.UTC(), err
+	case SortName:
+		var s string
+		err := json.Unmarshal(raw, &s)
+		return s, err
+	case SortBedrooms, SortBathrooms, SortYearBuilt:
+		var n int
+		err := json.Unmarshal(raw, &n)
+		return n, err
+	case SortTotalArea, SortCoveredArea:
+		var f float64
+		err := json.Unmarshal(raw, &f)
+		return f, err
+	case SortPrice, SortPricePerM2:
+		var d money.Decimal
+		err := json.Unmarshal(raw, &d)
+		return d, err
+	default:
+		return nil, fmt.Errorf("unknown sort field %q", field)
+	}
+}
+
+func isAmenityFlag(flag string) bool {
+	for _, f := range AmenityFlags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// queryParser collects conversion errors while reading query parameters.
+type queryParser struct {
+	values url.Values
+	errors []ValidationError
+}
+
+func (p *queryParser) uuids(key string) []uuid.UUID {
+	var ids []uuid.UUID
+	for _, s := range splitList(p.values.Get(key)) {
+		id, err := uuid.Parse(s)
+		if err != nil {
+			p.errors = append(p.errors, ValidationError{Field: key, Message: fmt.Sprintf("invalid UUID %q", s)})
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func (p *queryParser) int(key string) int {
+	if v := p.intPtr(key); v != nil {
+		return *v
+	}
+	return 0
+}
+
+func (p *queryParser) intPtr(key string) *int {
+	s := p.values.Get(key)
+	if s == "" {
+		return nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		p.errors = append(p.errors, ValidationError{Field: key, Message: "must be an integer"})
+		return nil
+	}
+	return &n
+}
+
+func (p *queryParser) float(key string) *float64 {
+	s := p.values.Get(key)
+	if s == "" {
+		return nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		p.errors = append(p.errors, ValidationError{Field: key, Message: "must be a number"})
+		return nil
+	}
+	return &f
+}
+
+func (p *queryParser) time(key string) *time.Time {
+	s := p.values.Get(key)
+	if s == "" {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		p.errors = append(p.errors, ValidationError{Field: key, Message: "must be an RFC 3339 time"})
+		return nil
+	}
+	return &t
+}
+
+func (p *queryParser) intRange(prefix string) IntRange {
+	return IntRange{Min: p.intPtr(prefix + "_min"), Max: p.intPtr(prefix + "_max")}
+}
+
+func (p *queryParser) floatRange(prefix string) FloatRange {
+	return FloatRange{Min: p.float(prefix + "_min"), Max: p.float(prefix + "_max")}
+}
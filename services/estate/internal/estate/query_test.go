@@ -0,0 +1,166 @@
+package estate
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestParsePropertyQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		wantErr string
+		check   func(t *testing.T, q PropertyQuery)
+	}{
+		{
+			name:  "defaults",
+			query: "",
+			check: func(t *testing.T, q PropertyQuery) {
+				if q.Limit != DefaultSearchLimit {
+					t.Errorf("expected limit %d, got %d", DefaultSearchLimit, q.Limit)
+				}
+				if len(q.Sort) != 1 || q.Sort[0] != (SortOrder{Field: SortCreatedAt, Desc: true}) {
+					t.Errorf("expected default sort, got %v", q.Sort)
+				}
+				if q.Price != nil {
+					t.Errorf("expected no price filter, got %+v", q.Price)
+				}
+			},
+		},
+		{
+			name:  "filters",
+			query: "status=available,reserved&type_id=00000000-0000-0000-0002-000000000001&city=Madrid&price_type=sale&currency=eur&price_min=100000&bedrooms_min=2&total_area_max=90.5&features=pool,garden&amenities=gym",
+			check: func(t *testing.T, q PropertyQuery) {
+				if len(q.Statuses) != 2 || q.Statuses[1] != "reserved" {
+					t.Errorf("unexpected statuses %v", q.Statuses)
+				}
+				if len(q.TypeIDs) != 1 || q.TypeIDs[0] != uuid.MustParse("00000000-0000-0000-0002-000000000001") {
+					t.Errorf("unexpected type IDs %v", q.TypeIDs)
+				}
+				if q.Price == nil || q.Price.Currency != "EUR" || q.Price.Type != "sale" || *q.Price.Min != 100000 || q.Price.Max != nil {
+					t.Errorf("unexpected price filter %+v", q.Price)
+				}
+				if *q.Bedrooms.Min != 2 || q.Bedrooms.Max != nil {
+					t.Errorf("unexpected bedrooms %+v", q.Bedrooms)
+				}
+				if *q.TotalArea.Max != 90.5 {
+					t.Errorf("unexpected total area %+v", q.TotalArea)
+				}
+				if len(q.Flags) != 2 || len(q.Amenities) != 1 {
+					t.Errorf("unexpected flags %v amenities %v", q.Flags, q.Amenities)
+				}
+			},
+		},
+		{
+			name:  "owner and team",
+			query: "owner_id=agent-1&team_id=team-1",
+			check: func(t *testing.T, q PropertyQuery) {
+				if q.OwnerID != "agent-1" || q.TeamID != "team-1" {
+					t.Errorf("unexpected owner %q team %q", q.OwnerID, q.TeamID)
+				}
+				if q.Scoped {
+					t.Error("expected query parameters not to scope the query")
+				}
+			},
+		},
+		{
+			name:  "sort and limit cap",
+			query: "sort=-bedrooms,name&limit=1000",
+			check: func(t *testing.T, q PropertyQuery) {
+				want := []SortOrder{{Field: SortBedrooms, Desc: true}, {Field: SortName}}
+				if len(q.Sort) != 2 || q.Sort[0] != want[0] || q.Sort[1] != want[1] {
+					t.Errorf("expected %v, got %v", want, q.Sort)
+				}
+				if q.Limit != MaxSearchLimit {
+					t.Errorf("expected limit %d, got %d", MaxSearchLimit, q.Limit)
+				}
+			},
+		},
+		{name: "invalid uuid", query: "category_id=nope", wantErr: "category_id"},
+		{name: "invalid number", query: "bedrooms_min=two", wantErr: "bedrooms_min"},
+		{name: "unknown sort field", query: "sort=owner_id", wantErr: "sort"},
+		{name: "trash sort field", query: "sort=-deleted_at", wantErr: "sort"},
+		{name: "unknown flag", query: "features=sauna", wantErr: "features"},
+		{name: "inverted price range", query: "price_min=10&price_max=5", wantErr: "price"},
+		{name: "invalid cursor", query: "cursor=abc", wantErr: "cursor"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("parse query: %v", err)
+			}
+
+			q, errs := ParsePropertyQuery(values)
+			if tt.wantErr != "" {
+				if len(errs) == 0 || errs[0].Field != tt.wantErr {
+					t.Fatalf("expected error on %q, got %v", tt.wantErr, errs)
+				}
+				return
+			}
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+			tt.check(t, q)
+		})
+	}
+}
+
+func TestParseTrashQuery(t *testing.T) {
+	q, errs := ParseTrashQuery(url.Values{"city": {"Madrid"}})
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if !q.Deleted || len(q.Sort) != 1 || q.Sort[0] != TrashSort[0] {
+		t.Errorf("expected the trash sorted by deletion, got deleted=%v sort=%v", q.Deleted, q.Sort)
+	}
+
+	q, errs = ParseTrashQuery(url.Values{"sort": {"deleted_at,name"}})
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if len(q.Sort) != 2 || q.Sort[0] != (SortOrder{Field: SortDeletedAt}) {
+		t.Errorf("expected deleted_at ascending first, got %v", q.Sort)
+	}
+}
+
+func TestNextCursorRoundTrip(t *testing.T) {
+	p := New()
+	p.EnsureID()
+	p.Name = "Mayor 12"
+	p.Features.Bedrooms = 3
+	p.Features.TotalArea = 85.5
+	p.CreatedAt = time.Date(2025, 3, 1, 10, 30, 0, 123456789, time.FixedZone("CET", 3600))
+
+	q := PropertyQuery{Sort: []SortOrder{
+		{Field: SortCreatedAt, Desc: true},
+		{Field: SortName},
+		{Field: SortBedrooms},
+		{Field: SortTotalArea},
+	}}
+	q.Cursor = NextCursor(p, q.Sort)
+
+	values, err := q.CursorValues()
+	if err != nil {
+		t.Fatalf("CursorValues: %v", err)
+	}
+
+	want := []any{p.CreatedAt.UTC(), "Mayor 12", 3, 85.5, p.ID.String()}
+	if len(values) != len(want) {
+		t.Fatalf("expected %d values, got %d", len(want), len(values))
+	}
+	for i := range want {
+		if values[i] != want[i] {
+			t.Errorf("value %d: expected %#v, got %#v", i, want[i], values[i])
+		}
+	}
+
+	q.Sort = q.Sort[:1]
+	if _, err := q.CursorValues(); err == nil {
+		t.Error("expected error when the cursor does not match the sort order")
+	}
+}
@@ -0,0 +1,178 @@
+package estate
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/pkg/lib/core"
+)
+
+// maxReplayBatch caps the events loaded per page while replaying.
+const maxReplayBatch = 500
+
+// Relay publishes the pending events of the outbox to the bus, checking
+// every interval. Delivery is at least once: an event is marked published
+// after the bus accepted it, so a crash in between publishes it again. When
+// publishing an event fails, the later events of the same property wait for
+// the next run, so each property's events are published in sequence order;
+// the events of the other properties are still published.
+type Relay struct {
+	outbox   OutboxRepo
+	bus      EventBus
+	interval time.Duration
+	batch    int
+	log      core.Logger
+	now      func() time.Time
+
+	mu     sync.Mutex // Serializes runs, so events are not published concurrently
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewRelay returns a relay publishing up to batch events at a time.
+func NewRelay(outbox OutboxRepo, bus EventBus, interval time.Duration, batch int, log core.Logger) (*Relay, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("invalid event relay interval %s", interval)
+	}
+	if batch <= 0 {
+		return nil, fmt.Errorf("invalid event relay batch size %d", batch)
+	}
+	return &Relay{
+		outbox:   outbox,
+		bus:      bus,
+		interval: interval,
+		batch:    batch,
+		log:      log,
+		now:      time.Now,
+	}, nil
+}
+
+// Start relays pending events in the background, then every interval.
+func (r *Relay) Start(ctx context.Context) error {
+	workerCtx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+		for {
+			if _, err := r.Relay(workerCtx); err != nil && workerCtx.Err() == nil {
+				r.log.Error("cannot relay events", "error", err)
+			}
+			select {
+			case <-workerCtx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop stops the relay, waiting for a running one to be interrupted.
+func (r *Relay) Stop(ctx context.Context) error {
+	if r.cancel == nil {
+		return nil
+	}
+	r.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Relay publishes the pending events until none are left but those of the
+// properties with a failed event, and returns how many were published.
+// Publish errors are logged; the events stay pending. Later pages leave out
+// the blocked properties, so they cannot hold back the others.
+func (r *Relay) Relay(ctx context.Context) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	total := 0
+	blocked := make(map[uuid.UUID]bool)
+	var exclude []uuid.UUID
+	for {
+		events, err := r.outbox.PendingEvents(ctx, r.batch, exclude)
+		if err != nil {
+			return total, err
+		}
+
+		published := make([]Event, 0, len(events))
+		for _, e := range events {
+			if blocked[e.AggregateID] {
+				continue
+			}
+			if err := r.bus.Publish(ctx, e); err != nil {
+				if ctx.Err() != nil {
+					break
+				}
+				r.log.Error("cannot publish event", "error", err, "id", e.ID.String(), "type", e.Type, "property", e.AggregateID.String())
+				blocked[e.AggregateID] = true
+				exclude = append(exclude, e.AggregateID)
+				continue
+			}
+			published = append(published, e)
+		}
+
+		if len(published) > 0 {
+			if err := r.outbox.MarkPublished(ctx, published, r.now().UTC()); err != nil {
+				return total, err
+			}
+			total += len(published)
+		}
+
+		// Every event of a full page was published or blocked its property,
+		// so the next page moves on.
+		if len(events) < r.batch || ctx.Err() != nil {
+			return total, ctx.Err()
+		}
+	}
+}
+
+// Replay publishes the published events matching the query again, in
+// publish order, and returns how many were published. It stops at the
+// first event the bus does not accept. query.Limit caps the events
+// replayed, zero for all.
+func (r *Relay) Replay(ctx context.Context, query EventQuery) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	limit := query.Limit
+	total := 0
+	for {
+		query.Limit = maxReplayBatch
+		if limit > 0 && limit-total < maxReplayBatch {
+			query.Limit = limit - total
+		}
+
+		events, err := r.outbox.Events(ctx, query)
+		if err != nil {
+			return total, err
+		}
+		for _, e := range events {
+			if err := r.bus.Publish(ctx, e); err != nil {
+				return total, fmt.Errorf("cannot publish event %s: %w", e.ID, err)
+			}
+			total++
+		}
+
+		if len(events) < query.Limit || (limit > 0 && total >= limit) {
+			return total, nil
+		}
+		query.After = events[len(events)-1].Position
+	}
+}
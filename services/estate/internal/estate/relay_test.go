@@ -0,0 +1,231 @@
+package estate
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/pkg/lib/core"
+)
+
+func TestNewEvent(t *testing.T) {
+	before := New()
+	after := before.Transitioned(&StatusTransition{From: StatusAvailable, To: StatusReserved, Actor: "agent-1", At: time.Now()})
+
+	tests := []struct {
+		action string
+		before *Property
+		want   string
+	}{
+		{ActionCreate, nil, EventPropertyCreated},
+		{ActionUpdate, before, EventPropertyUpdated},
+		{ActionRestore, before, EventPropertyUpdated},
+		{ActionUndelete, before, EventPropertyUpdated},
+		{ActionTransition, before, EventPropertyStatusChanged},
+		{ActionDelete, before, EventPropertyDeleted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.action, func(t *testing.T) {
+			rev := &PropertyRevision{PropertyID: before.ID, Number: 3, Action: tt.action, Actor: "agent-1", Snapshot: after,
+				Changes: []FieldChange{{Path: "/status", From: StatusAvailable, To: StatusReserved}}}
+			e := NewEvent(rev, tt.before)
+
+			if e.Type != tt.want || e.AggregateID != before.ID || e.Sequence != 3 || e.Actor != "agent-1" || e.Data.Action != tt.action {
+				t.Errorf("unexpected event %+v", e)
+			}
+			if tt.want == EventPropertyStatusChanged && (e.Data.From != StatusAvailable || e.Data.To != StatusReserved) {
+				t.Errorf("expected available to reserved, got %q to %q", e.Data.From, e.Data.To)
+			}
+			if tt.want == EventPropertyDeleted && e.Data.Changes != nil {
+				t.Errorf("expected no changes for a delete, got %v", e.Data.Changes)
+			}
+		})
+	}
+}
+
+func TestRelayPublishesInOrder(t *testing.T) {
+	a, b := uuid.New(), uuid.New()
+	outbox := &memoryOutbox{}
+	outbox.add(a, 1, 2, 3)
+	outbox.add(b, 1, 2)
+
+	bus := &recordingBus{fail: map[string]bool{eventKey(a, 2): true}}
+	relay, err := NewRelay(outbox, bus, time.Second, 10, core.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewRelay: %v", err)
+	}
+
+	n, err := relay.Relay(context.Background())
+	if err != nil {
+		t.Fatalf("Relay: %v", err)
+	}
+	if n != 3 {
+		t.Errorf("expected 3 published, got %d", n)
+	}
+	if got := bus.keys(); !equalKeys(got, eventKey(a, 1), eventKey(b, 1), eventKey(b, 2)) {
+		t.Errorf("expected a failed event to hold back the later events of its property, got %v", got)
+	}
+
+	bus.fail = nil
+	bus.published = nil
+	if n, err := relay.Relay(context.Background()); err != nil || n != 2 {
+		t.Fatalf("expected the 2 held back events published, got %d (%v)", n, err)
+	}
+	if got := bus.keys(); !equalKeys(got, eventKey(a, 2), eventKey(a, 3)) {
+		t.Errorf("unexpected retry order %v", got)
+	}
+	if pending, _ := outbox.PendingEvents(context.Background(), 10, nil); len(pending) != 0 {
+		t.Errorf("expected nothing pending, got %d", len(pending))
+	}
+}
+
+func TestRelayBatches(t *testing.T) {
+	outbox := &memoryOutbox{}
+	outbox.add(uuid.New(), 1, 2, 3, 4, 5)
+
+	relay, _ := NewRelay(outbox, &recordingBus{}, time.Second, 2, core.NewNoopLogger())
+	if n, err := relay.Relay(context.Background()); err != nil || n != 5 {
+		t.Errorf("expected every batch relayed, got %d (%v)", n, err)
+	}
+}
+
+func TestRelaySkipsBlockedProperties(t *testing.T) {
+	a, b := uuid.New(), uuid.New()
+	outbox := &memoryOutbox{}
+	outbox.add(a, 1, 2, 3)
+	outbox.add(b, 1, 2)
+
+	bus := &recordingBus{fail: map[string]bool{eventKey(a, 1): true}}
+	relay, _ := NewRelay(outbox, bus, time.Second, 2, core.NewNoopLogger())
+	if n, err := relay.Relay(context.Background()); err != nil || n != 2 {
+		t.Fatalf("expected the events of b published past a full page of a, got %d (%v)", n, err)
+	}
+	if got := bus.keys(); !equalKeys(got, eventKey(b, 1), eventKey(b, 2)) {
+		t.Errorf("unexpected events published %v", got)
+	}
+}
+
+func TestRelayReplay(t *testing.T) {
+	a, b := uuid.New(), uuid.New()
+	outbox := &memoryOutbox{}
+	outbox.add(a, 1, 2)
+	outbox.add(b, 1)
+	relay, _ := NewRelay(outbox, &recordingBus{}, time.Second, 10, core.NewNoopLogger())
+	if _, err := relay.Relay(context.Background()); err != nil {
+		t.Fatalf("Relay: %v", err)
+	}
+
+	bus := &recordingBus{}
+	relay.bus = bus
+	n, err := relay.Replay(context.Background(), EventQuery{AggregateID: a})
+	if err != nil || n != 2 {
+		t.Fatalf("expected 2 replayed, got %d (%v)", n, err)
+	}
+	if got := bus.keys(); !equalKeys(got, eventKey(a, 1), eventKey(a, 2)) {
+		t.Errorf("unexpected replay %v", got)
+	}
+
+	bus.published = nil
+	if n, err := relay.Replay(context.Background(), EventQuery{Limit: 1}); err != nil || n != 1 {
+		t.Errorf("expected the limit honored, got %d (%v)", n, err)
+	}
+
+	bus.fail = map[string]bool{eventKey(a, 1): true}
+	if n, err := relay.Replay(context.Background(), EventQuery{}); err == nil || n != 0 {
+		t.Errorf("expected the replay to stop at the failed event, got %d (%v)", n, err)
+	}
+}
+
+func TestNewRelayInvalid(t *testing.T) {
+	if _, err := NewRelay(nil, nil, 0, 10, core.NewNoopLogger()); err == nil {
+		t.Error("expected error for a zero interval")
+	}
+	if _, err := NewRelay(nil, nil, time.Second, 0, core.NewNoopLogger()); err == nil {
+		t.Error("expected error for a zero batch size")
+	}
+}
+
+// memoryOutbox is an OutboxRepo keeping events in insertion order.
+type memoryOutbox struct {
+	events   []Event
+	position int64
+}
+
+func (o *memoryOutbox) add(aggregateID uuid.UUID, sequences ...int64) {
+	for _, seq := range sequences {
+		o.events = append(o.events, Event{ID: uuid.New(), Type: EventPropertyUpdated, AggregateID: aggregateID, Sequence: seq})
+	}
+}
+
+func (o *memoryOutbox) PendingEvents(ctx context.Context, limit int, exclude []uuid.UUID) ([]Event, error) {
+	var pending []Event
+	for _, e := range o.events {
+		if e.PublishedAt == nil && !slices.Contains(exclude, e.AggregateID) && len(pending) < limit {
+			pending = append(pending, e)
+		}
+	}
+	return pending, nil
+}
+
+func (o *memoryOutbox) MarkPublished(ctx context.Context, events []Event, at time.Time) error {
+	for _, published := range events {
+		for i := range o.events {
+			if o.events[i].ID == published.ID && o.events[i].PublishedAt == nil {
+				o.position++
+				o.events[i].Position = o.position
+				o.events[i].PublishedAt = &at
+			}
+		}
+	}
+	return nil
+}
+
+func (o *memoryOutbox) Events(ctx context.Context, query EventQuery) ([]Event, error) {
+	var events []Event
+	for pos := query.After + 1; pos <= o.position; pos++ {
+		for _, e := range o.events {
+			if e.Position != pos || (query.AggregateID != uuid.Nil && e.AggregateID != query.AggregateID) {
+				continue
+			}
+			if query.Limit == 0 || len(events) < query.Limit {
+				events = append(events, e)
+			}
+		}
+	}
+	return events, nil
+}
+
+// recordingBus records published events, failing those in fail.
+type recordingBus struct {
+	fail      map[string]bool
+	published []Event
+}
+
+func (b *recordingBus) Publish(ctx context.Context, e Event) error {
+	if b.fail[eventKey(e.AggregateID, e.Sequence)] {
+		return errors.New("bus unavailable")
+	}
+	b.published = append(b.published, e)
+	return nil
+}
+
+func (b *recordingBus) keys() []string {
+	keys := make([]string, 0, len(b.published))
+	for _, e := range b.published {
+		keys = append(keys, eventKey(e.AggregateID, e.Sequence))
+	}
+	return keys
+}
+
+func eventKey(id uuid.UUID, seq int64) string {
+	return id.String() + "/" + strconv.FormatInt(seq, 10)
+}
+
+func equalKeys(got []string, want ...string) bool {
+	return slices.Equal(got, want)
+}
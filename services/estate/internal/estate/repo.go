@@ -2,12 +2,19 @@ package estate
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/google/uuid"
 )
 
+// ErrNotFound is returned by repositories when a Property aggregate does not exist.
+var ErrNotFound = errors.New("property not found")
+
 // Repo defines the interface for Property aggregate operations.
 // This repository manages the Property aggregate root as a single unit.
+// Properties stored with an older schema version are returned upgraded to
+// the current one (see Schema).
 type Repo interface {
 	// Create creates a new Property aggregate.
 	Create(ctx context.Context, property *Property) error
@@ -16,10 +23,40 @@ type Repo interface {
 	Get(ctx context.Context, id uuid.UUID) (*Property, error)
 
 	// Save performs a unit-of-work save operation on the aggregate.
+	// The save only applies while the stored revision is still
+	// property.Revision, which is then incremented. It returns ErrNotFound if
+	// the property does not exist and ErrRevisionConflict if it was modified.
+	// CreatedAt and CreatedBy keep their stored values.
 	Save(ctx context.Context, property *Property) error
 
-	// Delete removes the entire Property aggregate.
-	Delete(ctx context.Context, id uuid.UUID) error
+	// Delete moves the Property aggregate to the trash: it is kept with
+	// DeletedAt and DeletedBy set (see Property.Trashed), but Get, the List
+	// methods, Search and SearchGeo no longer return it. A non-zero revision
+	// makes the delete conditional on the stored revision, returning
+	// ErrRevisionConflict if it no longer matches. Deleting a property that is
+	// already in the trash returns ErrNotFound.
+	Delete(ctx context.Context, id uuid.UUID, revision int64) error
+
+	// Undelete takes a property out of the trash (see Property.Untrashed).
+	// A non-zero revision makes it conditional like Delete. It returns
+	// ErrNotFound if the property is not in the trash.
+	Undelete(ctx context.Context, id uuid.UUID, revision int64) error
+
+	// Purge permanently removes the properties moved to the trash before the
+	// given time, together with their media metadata, status history,
+	// listings, leases, appointments and inquiries, and returns their IDs.
+	// Revisions are kept, and so are their pending events until relayed.
+	Purge(ctx context.Context, before time.Time) ([]uuid.UUID, error)
+
+	// Revisions retrieves the revisions of a property, newest first and
+	// without snapshots. Create, Save, Transition, Delete and Undelete each
+	// record one (see NewRevision), and they are kept after the property is
+	// purged.
+	Revisions(ctx context.Context, id uuid.UUID) ([]PropertyRevision, error)
+
+	// Revision retrieves a single property revision including its snapshot.
+	// It returns ErrRevisionNotFound if it does not exist.
+	Revision(ctx context.Context, id uuid.UUID, number int64) (*PropertyRevision, error)
 
 	// List retrieves all Property aggregates.
 	List(ctx context.Context) ([]*Property, error)
@@ -29,4 +66,22 @@ type Repo interface {
 
 	// ListByStatus retrieves all properties with a specific status.
 	ListByStatus(ctx context.Context, status string) ([]*Property, error)
+
+	// Search retrieves a page of properties matching the query, or of the
+	// properties in the trash when query.Deleted is set.
+	// The query is expected to be normalized (see PropertyQuery.Normalize).
+	Search(ctx context.Context, query PropertyQuery) (*PropertyPage, error)
+
+	// Transition stores a status change: it sets the property status to t.To,
+	// increments its revision and appends t to the property status history.
+	// It returns ErrNotFound if the property does not exist and
+	// ErrStatusConflict if its stored status is no longer t.From.
+	Transition(ctx context.Context, t *StatusTransition) error
+
+	// StatusHistory retrieves the status transitions of a property, oldest first.
+	StatusHistory(ctx context.Context, id uuid.UUID) ([]StatusTransition, error)
+
+	// SearchGeo retrieves properties inside an area, nearest to the query center first.
+	// The query is expected to be normalized (see GeoQuery.Normalize).
+	SearchGeo(ctx context.Context, query GeoQuery) ([]GeoResult, error)
 }
@@ -0,0 +1,318 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/services/estate/internal/estate"
+)
+
+// appointmentBuffers are the buffers the contract schedules under.
+var appointmentBuffers = estate.AppointmentBuffers{Agent: 30 * time.Minute, Property: 15 * time.Minute}
+
+// NewAppointmentRepoFunc returns an empty, ready-to-use appointment
+// repository for a single subtest, with the property repository it stores
+// appointments alongside.
+type NewAppointmentRepoFunc func(t *testing.T) (estate.Repo, estate.AppointmentRepo)
+
+// RunAppointmentRepo runs the estate.AppointmentRepo contract against the
+// repository returned by newRepo.
+func RunAppointmentRepo(t *testing.T, newRepo NewAppointmentRepoFunc) {
+	appointments := func(t *testing.T) estate.AppointmentRepo {
+		_, ar := newRepo(t)
+		return ar
+	}
+
+	t.Run("CreateAndGet", func(t *testing.T) { testAppointmentCreateAndGet(t, appointments(t)) })
+	t.Run("Conflicts", func(t *testing.T) { testAppointmentConflicts(t, appointments(t)) })
+	t.Run("ConcurrentConflicts", func(t *testing.T) { testAppointmentConcurrentConflicts(t, appointments(t)) })
+	t.Run("Save", func(t *testing.T) { testAppointmentSave(t, appointments(t)) })
+	t.Run("List", func(t *testing.T) { testAppointmentList(t, appointments(t)) })
+	t.Run("Missing", func(t *testing.T) { testAppointmentMissing(t, appointments(t)) })
+	t.Run("PurgedWithProperty", func(t *testing.T) { testAppointmentPurgedWithProperty(t, newRepo) })
+}
+
+// NewAppointment returns a valid appointment of an agent at a property
+// starting at a time and lasting 45 minutes.
+func NewAppointment(propertyID uuid.UUID, agentID string, startsAt time.Time) *estate.Appointment {
+	return &estate.Appointment{
+		PropertyID: propertyID,
+		AgentID:    agentID,
+		Client:     estate.Contact{Name: "Marta Ruiz", Email: "marta@example.com"},
+		StartsAt:   startsAt.UTC(),
+		EndsAt:     startsAt.Add(45 * time.Minute).UTC(),
+		Notes:      "Interested in the terrace",
+		CreatedBy:  "tester",
+		UpdatedBy:  "tester",
+	}
+}
+
+// appointmentDay is the morning the contract schedules appointments on.
+var appointmentDay = time.Date(2026, 5, 4, 9, 0, 0, 0, time.UTC)
+
+func at(hour, minute int) time.Time {
+	return appointmentDay.Add(time.Duration(hour-9)*time.Hour + time.Duration(minute)*time.Minute)
+}
+
+func testAppointmentCreateAndGet(t *testing.T, ar estate.AppointmentRepo) {
+	ctx := context.Background()
+	a := NewAppointment(uuid.New(), "agent-1", at(10, 0))
+	if err := ar.CreateAppointment(ctx, a, appointmentBuffers); err != nil {
+		t.Fatalf("CreateAppointment: %v", err)
+	}
+	if a.ID == uuid.Nil || a.Revision != 1 || a.Status != estate.AppointmentScheduled {
+		t.Fatalf("expected an ID, revision 1 and a scheduled appointment, got %s, %d and %q", a.ID, a.Revision, a.Status)
+	}
+
+	got, err := ar.GetAppointment(ctx, a.ID)
+	if err != nil {
+		t.Fatalf("GetAppointment: %v", err)
+	}
+	if !got.StartsAt.Equal(a.StartsAt) || !got.EndsAt.Equal(a.EndsAt) {
+		t.Errorf("expected the slot %v to %v, got %v to %v", a.StartsAt, a.EndsAt, got.StartsAt, got.EndsAt)
+	}
+	if !got.CreatedAt.Equal(a.CreatedAt) || !got.UpdatedAt.Equal(a.UpdatedAt) {
+		t.Errorf("expected times %v and %v, got %v and %v", a.CreatedAt, a.UpdatedAt, got.CreatedAt, got.UpdatedAt)
+	}
+	got.StartsAt, got.EndsAt = a.StartsAt, a.EndsAt
+	got.CreatedAt, got.UpdatedAt = a.CreatedAt, a.UpdatedAt
+	if !reflect.DeepEqual(got, a) {
+		t.Errorf("round trip mismatch:\nwant %+v\ngot  %+v", a, got)
+	}
+}
+
+func testAppointmentConflicts(t *testing.T, ar estate.AppointmentRepo) {
+	ctx := context.Background()
+	flat, house := uuid.New(), uuid.New()
+
+	// 10:00 to 10:45 with agent-1 at the flat
+	if err := ar.CreateAppointment(ctx, NewAppointment(flat, "agent-1", at(10, 0)), appointmentBuffers); err != nil {
+		t.Fatalf("CreateAppointment: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		property  uuid.UUID
+		agent     string
+		startsAt  time.Time
+		conflicts bool
+	}{
+		{"same slot", house, "agent-1", at(10, 0), true},
+		{"within the agent buffer after", house, "agent-1", at(11, 0), true},
+		{"within the agent buffer before", house, "agent-1", at(8, 50), true},
+		{"within the property buffer", flat, "agent-2", at(10, 55), true},
+		{"other agent and property", house, "agent-2", at(10, 0), false},
+		{"after the property buffer", flat, "agent-3", at(11, 0), false},
+		{"after the agent buffer", house, "agent-1", at(11, 15), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ar.CreateAppointment(ctx, NewAppointment(tt.property, tt.agent, tt.startsAt), appointmentBuffers)
+			if tt.conflicts && !errors.Is(err, estate.ErrAppointmentConflict) {
+				t.Errorf("expected ErrAppointmentConflict, got %v", err)
+			}
+			if !tt.conflicts && err != nil {
+				t.Errorf("CreateAppointment: %v", err)
+			}
+		})
+	}
+
+	// Without buffers back-to-back appointments fit
+	if err := ar.CreateAppointment(ctx, NewAppointment(uuid.New(), "agent-1", at(9, 15)), estate.AppointmentBuffers{}); err != nil {
+		t.Errorf("CreateAppointment without buffers: %v", err)
+	}
+}
+
+// testAppointmentConcurrentConflicts books the same agent at the same time
+// from several goroutines at once: at most one booking may be stored.
+func testAppointmentConcurrentConflicts(t *testing.T, ar estate.AppointmentRepo) {
+	ctx := context.Background()
+	agent := "agent-" + uuid.NewString()
+
+	const writers = 8
+	errs := make(chan error, writers)
+	var wg sync.WaitGroup
+	for range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- ar.CreateAppointment(ctx, NewAppointment(uuid.New(), agent, at(10, 0)), appointmentBuffers)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	created := 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			created++
+		case !errors.Is(err, estate.ErrAppointmentConflict):
+			t.Errorf("expected ErrAppointmentConflict, got %v", err)
+		}
+	}
+	if created > 1 {
+		t.Errorf("expected at most one appointment created, got %d", created)
+	}
+
+	stored, err := ar.ListAppointments(ctx, estate.AppointmentQuery{AgentIDs: []string{agent}, Limit: writers})
+	if err != nil {
+		t.Fatalf("ListAppointments: %v", err)
+	}
+	if len(stored) != created {
+		t.Errorf("expected %d appointments stored, got %d", created, len(stored))
+	}
+}
+
+func testAppointmentSave(t *testing.T, ar estate.AppointmentRepo) {
+	ctx := context.Background()
+	property := uuid.New()
+	first := NewAppointment(property, "agent-1", at(10, 0))
+	second := NewAppointment(uuid.New(), "agent-1", at(12, 0))
+	for _, a := range []*estate.Appointment{first, second} {
+		if err := ar.CreateAppointment(ctx, a, appointmentBuffers); err != nil {
+			t.Fatalf("CreateAppointment: %v", err)
+		}
+	}
+
+	// Rescheduling into the slot of the other appointment conflicts
+	moved := *second
+	if err := moved.Reschedule(at(10, 30), at(11, 0)); err != nil {
+		t.Fatalf("Reschedule: %v", err)
+	}
+	if err := ar.SaveAppointment(ctx, &moved, appointmentBuffers); !errors.Is(err, estate.ErrAppointmentConflict) {
+		t.Errorf("expected ErrAppointmentConflict, got %v", err)
+	}
+	stored, err := ar.GetAppointment(ctx, second.ID)
+	if err != nil {
+		t.Fatalf("GetAppointment: %v", err)
+	}
+	if !stored.StartsAt.Equal(second.StartsAt) || stored.Revision != 1 {
+		t.Errorf("expected the conflicting save undone, got %v at %d", stored.StartsAt, stored.Revision)
+	}
+
+	// Moving within its own slot does not conflict with itself
+	if err := second.Reschedule(at(12, 15), at(13, 0)); err != nil {
+		t.Fatalf("Reschedule: %v", err)
+	}
+	second.UpdatedBy = "editor"
+	if err := ar.SaveAppointment(ctx, second, appointmentBuffers); err != nil {
+		t.Fatalf("SaveAppointment: %v", err)
+	}
+	if second.Revision != 2 {
+		t.Errorf("expected revision 2, got %d", second.Revision)
+	}
+
+	// Cancelling frees the slot
+	if err := first.Transition(estate.AppointmentCancelled); err != nil {
+		t.Fatalf("Transition: %v", err)
+	}
+	if err := ar.SaveAppointment(ctx, first, appointmentBuffers); err != nil {
+		t.Fatalf("SaveAppointment: %v", err)
+	}
+	got, err := ar.GetAppointment(ctx, first.ID)
+	if err != nil {
+		t.Fatalf("GetAppointment: %v", err)
+	}
+	if got.Status != estate.AppointmentCancelled || got.Revision != 2 {
+		t.Errorf("expected a cancelled appointment at revision 2, got %q at %d", got.Status, got.Revision)
+	}
+	if err := ar.CreateAppointment(ctx, NewAppointment(property, "agent-1", at(10, 0)), appointmentBuffers); err != nil {
+		t.Errorf("CreateAppointment in the cancelled slot: %v", err)
+	}
+
+	stale := *got
+	stale.Revision = 1
+	if err := ar.SaveAppointment(ctx, &stale, appointmentBuffers); !errors.Is(err, estate.ErrRevisionConflict) {
+		t.Errorf("expected ErrRevisionConflict, got %v", err)
+	}
+}
+
+func testAppointmentList(t *testing.T, ar estate.AppointmentRepo) {
+	ctx := context.Background()
+	flat, house := uuid.New(), uuid.New()
+
+	names := map[uuid.UUID]string{}
+	create := func(name string, a *estate.Appointment) *estate.Appointment {
+		if err := ar.CreateAppointment(ctx, a, appointmentBuffers); err != nil {
+			t.Fatalf("CreateAppointment %s: %v", name, err)
+		}
+		names[a.ID] = name
+		return a
+	}
+	create("noon", NewAppointment(house, "agent-2", at(12, 0)))
+	early := create("early", NewAppointment(flat, "agent-1", at(9, 0)))
+	create("late", NewAppointment(flat, "agent-1", at(16, 0)))
+
+	if err := early.Transition(estate.AppointmentConfirmed); err != nil {
+		t.Fatalf("Transition: %v", err)
+	}
+	if err := ar.SaveAppointment(ctx, early, appointmentBuffers); err != nil {
+		t.Fatalf("SaveAppointment: %v", err)
+	}
+
+	from, to := at(9, 30), at(12, 30)
+	tests := []struct {
+		name  string
+		query estate.AppointmentQuery
+		want  []string
+	}{
+		{"property soonest first", estate.AppointmentQuery{PropertyIDs: []uuid.UUID{flat}}, []string{"early", "late"}},
+		{"agent", estate.AppointmentQuery{AgentIDs: []string{"agent-2"}, PropertyIDs: []uuid.UUID{flat, house}}, []string{"noon"}},
+		{"status", estate.AppointmentQuery{PropertyIDs: []uuid.UUID{flat, house}, Statuses: []string{estate.AppointmentConfirmed}}, []string{"early"}},
+		{"overlapping the range", estate.AppointmentQuery{PropertyIDs: []uuid.UUID{flat, house}, From: &from, To: &to}, []string{"early", "noon"}},
+		{"limit", estate.AppointmentQuery{PropertyIDs: []uuid.UUID{flat, house}, Limit: 1}, []string{"early"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := tt.query
+			if errs := query.Normalize(); len(errs) > 0 {
+				t.Fatalf("Normalize: %v", errs)
+			}
+			list, err := ar.ListAppointments(ctx, query)
+			if err != nil {
+				t.Fatalf("ListAppointments: %v", err)
+			}
+			var got []string
+			for _, a := range list {
+				got = append(got, names[a.ID])
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func testAppointmentMissing(t *testing.T, ar estate.AppointmentRepo) {
+	ctx := context.Background()
+	if _, err := ar.GetAppointment(ctx, uuid.New()); !errors.Is(err, estate.ErrAppointmentNotFound) {
+		t.Errorf("GetAppointment: expected ErrAppointmentNotFound, got %v", err)
+	}
+
+	a := NewAppointment(uuid.New(), "agent-1", at(10, 0))
+	a.ID, a.Revision, a.Status = uuid.New(), 1, estate.AppointmentScheduled
+	if err := ar.SaveAppointment(ctx, a, appointmentBuffers); !errors.Is(err, estate.ErrAppointmentNotFound) {
+		t.Errorf("SaveAppointment: expected ErrAppointmentNotFound, got %v", err)
+	}
+}
+
+func testAppointmentPurgedWithProperty(t *testing.T, newRepo NewAppointmentRepoFunc) {
+	repo, ar := newRepo(t)
+	ctx := context.Background()
+
+	testPurgedWithProperty(t, repo, func(p *estate.Property) func() error {
+		a := NewAppointment(p.ID, "agent-"+p.ID.String(), appointmentDay)
+		if err := ar.CreateAppointment(ctx, a, estate.AppointmentBuffers{}); err != nil {
+			t.Fatalf("CreateAppointment: %v", err)
+		}
+		return func() error { _, err := ar.GetAppointment(ctx, a.ID); return err }
+	})
+}
@@ -0,0 +1,207 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/services/estate/internal/estate"
+)
+
+// NewDevelopmentRepoFunc returns an empty, ready-to-use development
+// repository for a single subtest.
+type NewDevelopmentRepoFunc func(t *testing.T) estate.DevelopmentRepo
+
+// RunDevelopmentRepo runs the estate.DevelopmentRepo contract against the
+// repository returned by newRepo.
+func RunDevelopmentRepo(t *testing.T, newRepo NewDevelopmentRepoFunc) {
+	t.Run("CreateAndGet", func(t *testing.T) { testDevelopmentCreateAndGet(t, newRepo(t)) })
+	t.Run("Save", func(t *testing.T) { testDevelopmentSave(t, newRepo(t)) })
+	t.Run("Delete", func(t *testing.T) { testDevelopmentDelete(t, newRepo(t)) })
+	t.Run("List", func(t *testing.T) { testDevelopmentList(t, newRepo(t)) })
+	t.Run("Missing", func(t *testing.T) { testDevelopmentMissing(t, newRepo(t)) })
+}
+
+// NewDevelopment returns a fully populated, valid building.
+func NewDevelopment(name string) *estate.Development {
+	return &estate.Development{
+		Kind:         estate.DevelopmentBuilding,
+		Name:         name,
+		Description:  "Twelve storeys facing the river",
+		Developer:    "Obras del Sur",
+		Stage:        estate.StageUnderConstruction,
+		DeliveryDate: "2027-06-30",
+		Location: estate.Location{
+			Address: estate.Address{
+				Street:     "Paseo de la Ribera",
+				Number:     "4",
+				City:       "Madrid",
+				PostalCode: "28005",
+				Country:    "ES",
+			},
+			Coordinates: estate.Coordinates{Latitude: 40.4010, Longitude: -3.7190},
+			Provider:    "osm",
+			ProviderRef: "way/456",
+			Raw:         map[string]any{"osm_type": "way"},
+		},
+		Amenities: []string{"pool", "elevator", "concierge"},
+		YearBuilt: 2027,
+		OwnerID:   "owner-1",
+		TeamID:    "team-1",
+		CreatedBy: "tester",
+		UpdatedBy: "tester",
+	}
+}
+
+func testDevelopmentCreateAndGet(t *testing.T, dr estate.DevelopmentRepo) {
+	ctx := context.Background()
+	d := NewDevelopment("Ribera")
+	if err := dr.CreateDevelopment(ctx, d); err != nil {
+		t.Fatalf("CreateDevelopment: %v", err)
+	}
+	if d.ID == uuid.Nil || d.Revision != 1 {
+		t.Fatalf("expected an ID and revision 1, got %s and %d", d.ID, d.Revision)
+	}
+
+	got, err := dr.GetDevelopment(ctx, d.ID)
+	if err != nil {
+		t.Fatalf("GetDevelopment: %v", err)
+	}
+	if !got.CreatedAt.Equal(d.CreatedAt) || !got.UpdatedAt.Equal(d.UpdatedAt) {
+		t.Errorf("expected times %v and %v, got %v and %v", d.CreatedAt, d.UpdatedAt, got.CreatedAt, got.UpdatedAt)
+	}
+	got.CreatedAt, got.UpdatedAt = d.CreatedAt, d.UpdatedAt
+	if !reflect.DeepEqual(got, d) {
+		t.Errorf("round trip mismatch:\nwant %+v\ngot  %+v", d, got)
+	}
+}
+
+func testDevelopmentSave(t *testing.T, dr estate.DevelopmentRepo) {
+	ctx := context.Background()
+	d := NewDevelopment("Ribera")
+	if err := dr.CreateDevelopment(ctx, d); err != nil {
+		t.Fatalf("CreateDevelopment: %v", err)
+	}
+
+	d.Stage = estate.StageCompleted
+	d.Amenities = []string{"pool"}
+	d.Location.Address.City = "Getafe"
+	if err := dr.SaveDevelopment(ctx, d); err != nil {
+		t.Fatalf("SaveDevelopment: %v", err)
+	}
+	if d.Revision != 2 {
+		t.Errorf("expected revision 2, got %d", d.Revision)
+	}
+
+	got, err := dr.GetDevelopment(ctx, d.ID)
+	if err != nil {
+		t.Fatalf("GetDevelopment: %v", err)
+	}
+	if got.Stage != estate.StageCompleted || got.Revision != 2 || !reflect.DeepEqual(got.Amenities, []string{"pool"}) {
+		t.Errorf("unexpected development after save: %+v", got)
+	}
+
+	list, err := dr.ListDevelopments(ctx, estate.DevelopmentQuery{City: "getafe", Limit: 10})
+	if err != nil {
+		t.Fatalf("ListDevelopments: %v", err)
+	}
+	if len(list) != 1 {
+		t.Errorf("expected the development in its new city, got %d", len(list))
+	}
+
+	stale := *d
+	stale.Revision = 1
+	if err := dr.SaveDevelopment(ctx, &stale); !errors.Is(err, estate.ErrRevisionConflict) {
+		t.Errorf("expected ErrRevisionConflict, got %v", err)
+	}
+}
+
+func testDevelopmentDelete(t *testing.T, dr estate.DevelopmentRepo) {
+	ctx := context.Background()
+	d := NewDevelopment("Ribera")
+	if err := dr.CreateDevelopment(ctx, d); err != nil {
+		t.Fatalf("CreateDevelopment: %v", err)
+	}
+
+	if err := dr.DeleteDevelopment(ctx, d.ID, d.Revision+1); !errors.Is(err, estate.ErrRevisionConflict) {
+		t.Errorf("expected ErrRevisionConflict, got %v", err)
+	}
+	if err := dr.DeleteDevelopment(ctx, d.ID, d.Revision); err != nil {
+		t.Fatalf("DeleteDevelopment: %v", err)
+	}
+	if _, err := dr.GetDevelopment(ctx, d.ID); !errors.Is(err, estate.ErrDevelopmentNotFound) {
+		t.Errorf("expected ErrDevelopmentNotFound after delete, got %v", err)
+	}
+}
+
+func testDevelopmentList(t *testing.T, dr estate.DevelopmentRepo) {
+	ctx := context.Background()
+
+	c := NewDevelopment("Cerro")
+	a := NewDevelopment("Alameda")
+	a.Kind = estate.DevelopmentProject
+	a.OwnerID, a.TeamID = "owner-2", ""
+	b := NewDevelopment("Bahía")
+	b.Location.Address.City = "Cádiz"
+	b.OwnerID, b.TeamID = "owner-3", "team-3"
+	for _, d := range []*estate.Development{c, a, b} {
+		if err := dr.CreateDevelopment(ctx, d); err != nil {
+			t.Fatalf("CreateDevelopment %s: %v", d.Name, err)
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	tests := []struct {
+		name  string
+		query estate.DevelopmentQuery
+		want  []string
+	}{
+		{"all by name", estate.DevelopmentQuery{}, []string{"Alameda", "Bahía", "Cerro"}},
+		{"kind", estate.DevelopmentQuery{Kind: estate.DevelopmentBuilding}, []string{"Bahía", "Cerro"}},
+		{"city case-insensitive", estate.DevelopmentQuery{City: "CÁDIZ"}, []string{"Bahía"}},
+		{"country", estate.DevelopmentQuery{Country: "es"}, []string{"Alameda", "Bahía", "Cerro"}},
+		{"scoped", estate.DevelopmentQuery{Scoped: true, Scopes: []string{"owner-2", "team-3"}}, []string{"Alameda", "Bahía"}},
+		{"scoped to nothing", estate.DevelopmentQuery{Scoped: true}, nil},
+		{"limit", estate.DevelopmentQuery{Limit: 2}, []string{"Alameda", "Bahía"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := tt.query
+			if errs := query.Normalize(); len(errs) > 0 {
+				t.Fatalf("Normalize: %v", errs)
+			}
+			list, err := dr.ListDevelopments(ctx, query)
+			if err != nil {
+				t.Fatalf("ListDevelopments: %v", err)
+			}
+			var got []string
+			for _, d := range list {
+				got = append(got, d.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func testDevelopmentMissing(t *testing.T, dr estate.DevelopmentRepo) {
+	ctx := context.Background()
+	if _, err := dr.GetDevelopment(ctx, uuid.New()); !errors.Is(err, estate.ErrDevelopmentNotFound) {
+		t.Errorf("GetDevelopment: expected ErrDevelopmentNotFound, got %v", err)
+	}
+
+	d := NewDevelopment("Ghost")
+	d.ID, d.Revision = uuid.New(), 1
+	if err := dr.SaveDevelopment(ctx, d); !errors.Is(err, estate.ErrDevelopmentNotFound) {
+		t.Errorf("SaveDevelopment: expected ErrDevelopmentNotFound, got %v", err)
+	}
+	if err := dr.DeleteDevelopment(ctx, uuid.New(), 0); !errors.Is(err, estate.ErrDevelopmentNotFound) {
+		t.Errorf("DeleteDevelopment: expected ErrDevelopmentNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,321 @@
+package repotest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/services/estate/internal/estate"
+)
+
+// NewInquiryRepoFunc returns an empty, ready-to-use inquiry repository
+// for a single subtest, with the property repository it stores inquiries
+// alongside.
+type NewInquiryRepoFunc func(t *testing.T) (estate.Repo, estate.InquiryRepo)
+
+// RunInquiryRepo runs the estate.InquiryRepo contract against the
+// repository returned by newRepo.
+func RunInquiryRepo(t *testing.T, newRepo NewInquiryRepoFunc) {
+	inquiries := func(t *testing.T) estate.InquiryRepo {
+		_, ir := newRepo(t)
+		return ir
+	}
+
+	t.Run("CreateAndGet", func(t *testing.T) { testInquiryCreateAndGet(t, inquiries(t)) })
+	t.Run("Save", func(t *testing.T) { testInquirySave(t, inquiries(t)) })
+	t.Run("List", func(t *testing.T) { testInquiryList(t, inquiries(t)) })
+	t.Run("Lookup", func(t *testing.T) { testInquiryLookup(t, inquiries(t)) })
+	t.Run("Notes", func(t *testing.T) { testInquiryNotes(t, inquiries(t)) })
+	t.Run("Count", func(t *testing.T) { testInquiryCount(t, inquiries(t)) })
+	t.Run("Missing", func(t *testing.T) { testInquiryMissing(t, inquiries(t)) })
+	t.Run("PurgedWithProperty", func(t *testing.T) { testInquiryPurgedWithProperty(t, newRepo) })
+	t.Run("NotesPurgedWithProperty", func(t *testing.T) { testInquiryNotesPurgedWithProperty(t, newRepo) })
+}
+
+// NewInquiry returns a valid inquiry about a property with a sealed
+// contact identified by lookup. Repositories never see the contact itself.
+func NewInquiry(propertyID uuid.UUID, lookup string) *estate.Inquiry {
+	return &estate.Inquiry{
+		PropertyID:    propertyID,
+		Message:       "Is the flat still available?",
+		Source:        estate.InquiryWeb,
+		ContactCT:     []byte("ciphertext of " + lookup),
+		ContactIV:     []byte("0123456789ab"),
+		ContactTag:    []byte("0123456789abcdef"),
+		ContactLookup: []byte(lookup),
+		CreatedBy:     "tester",
+		UpdatedBy:     "tester",
+	}
+}
+
+func testInquiryCreateAndGet(t *testing.T, ir estate.InquiryRepo) {
+	ctx := context.Background()
+	i := NewInquiry(uuid.New(), "marta")
+	if err := ir.CreateInquiry(ctx, i); err != nil {
+		t.Fatalf("CreateInquiry: %v", err)
+	}
+	if i.ID == uuid.Nil || i.Revision != 1 || i.Stage != estate.InquiryNew {
+		t.Fatalf("expected an ID, revision 1 and a new inquiry, got %s, %d and %q", i.ID, i.Revision, i.Stage)
+	}
+
+	got, err := ir.GetInquiry(ctx, i.ID)
+	if err != nil {
+		t.Fatalf("GetInquiry: %v", err)
+	}
+	if got.PropertyID != i.PropertyID || got.Message != i.Message || got.Source != i.Source || got.Stage != i.Stage {
+		t.Errorf("round trip mismatch:\nwant %+v\ngot  %+v", i, got)
+	}
+	if !bytes.Equal(got.ContactCT, i.ContactCT) || !bytes.Equal(got.ContactIV, i.ContactIV) ||
+		!bytes.Equal(got.ContactTag, i.ContactTag) || !bytes.Equal(got.ContactLookup, i.ContactLookup) {
+		t.Errorf("expected the sealed contact to round trip, got %q", got.ContactCT)
+	}
+	if got.AgentID != "" || got.Outcome != "" || got.ClosedAt != nil {
+		t.Errorf("expected an unassigned open inquiry, got %+v", got)
+	}
+}
+
+func testInquirySave(t *testing.T, ir estate.InquiryRepo) {
+	ctx := context.Background()
+	i := NewInquiry(uuid.New(), "marta")
+	if err := ir.CreateInquiry(ctx, i); err != nil {
+		t.Fatalf("CreateInquiry: %v", err)
+	}
+
+	stale := *i
+	closedAt := time.Date(2026, 5, 4, 12, 0, 0, 0, time.UTC)
+	i.AgentID, i.Stage, i.Outcome, i.ClosedAt = "agent-1", estate.InquiryClosed, estate.InquiryWon, &closedAt
+	if err := ir.SaveInquiry(ctx, i); err != nil {
+		t.Fatalf("SaveInquiry: %v", err)
+	}
+	if i.Revision != 2 {
+		t.Errorf("expected revision 2, got %d", i.Revision)
+	}
+
+	got, err := ir.GetInquiry(ctx, i.ID)
+	if err != nil {
+		t.Fatalf("GetInquiry: %v", err)
+	}
+	if got.AgentID != "agent-1" || got.Outcome != estate.InquiryWon || got.ClosedAt == nil || !got.ClosedAt.Equal(closedAt) || got.Revision != 2 {
+		t.Errorf("expected the saved inquiry, got %+v", got)
+	}
+
+	if err := ir.SaveInquiry(ctx, &stale); !errors.Is(err, estate.ErrRevisionConflict) {
+		t.Errorf("expected ErrRevisionConflict for a stale save, got %v", err)
+	}
+}
+
+func testInquiryList(t *testing.T, ir estate.InquiryRepo) {
+	ctx := context.Background()
+	flat, house := uuid.New(), uuid.New()
+	first := NewInquiry(flat, "marta")
+	second := NewInquiry(flat, "jan")
+	second.AgentID = "agent-1"
+	third := NewInquiry(house, "ola")
+	third.Source = estate.InquiryPhone
+	for _, i := range []*estate.Inquiry{first, second, third} {
+		if err := ir.CreateInquiry(ctx, i); err != nil {
+			t.Fatalf("CreateInquiry: %v", err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	tests := []struct {
+		name  string
+		query estate.InquiryQuery
+		want  []uuid.UUID
+	}{
+		{"property newest first", estate.InquiryQuery{PropertyIDs: []uuid.UUID{flat}}, []uuid.UUID{second.ID, first.ID}},
+		{"agent", estate.InquiryQuery{PropertyIDs: []uuid.UUID{flat, house}, AgentIDs: []string{"agent-1"}}, []uuid.UUID{second.ID}},
+		{"unassigned", estate.InquiryQuery{PropertyIDs: []uuid.UUID{flat, house}, Unassigned: true}, []uuid.UUID{third.ID, first.ID}},
+		{"source", estate.InquiryQuery{PropertyIDs: []uuid.UUID{flat, house}, Sources: []string{estate.InquiryPhone}}, []uuid.UUID{third.ID}},
+		{"stage", estate.InquiryQuery{PropertyIDs: []uuid.UUID{flat, house}, Stages: []string{estate.InquiryOffer}}, nil},
+		{"limit", estate.InquiryQuery{PropertyIDs: []uuid.UUID{flat, house}, Limit: 1}, []uuid.UUID{third.ID}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.query.Normalize()
+			got, err := ir.ListInquiries(ctx, tt.query)
+			if err != nil {
+				t.Fatalf("ListInquiries: %v", err)
+			}
+			var ids []uuid.UUID
+			for _, i := range got {
+				ids = append(ids, i.ID)
+			}
+			if len(ids) != len(tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, ids)
+			}
+			for k := range ids {
+				if ids[k] != tt.want[k] {
+					t.Errorf("expected %v, got %v", tt.want, ids)
+					break
+				}
+			}
+		})
+	}
+}
+
+func testInquiryLookup(t *testing.T, ir estate.InquiryRepo) {
+	ctx := context.Background()
+	flat := uuid.New()
+	i := NewInquiry(flat, "marta")
+	if err := ir.CreateInquiry(ctx, i); err != nil {
+		t.Fatalf("CreateInquiry: %v", err)
+	}
+
+	hour := time.Hour
+	tests := []struct {
+		name     string
+		property uuid.UUID
+		lookup   string
+		since    time.Duration
+		want     bool
+	}{
+		{"same contact and property", flat, "marta", hour, true},
+		{"other contact", flat, "jan", hour, false},
+		{"other property", uuid.New(), "marta", hour, false},
+		{"before the window", flat, "marta", -hour, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, err := ir.HasInquirySince(ctx, tt.property, []byte(tt.lookup), time.Now().Add(-tt.since))
+			if err != nil {
+				t.Fatalf("HasInquirySince: %v", err)
+			}
+			if found != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, found)
+			}
+		})
+	}
+}
+
+func testInquiryNotes(t *testing.T, ir estate.InquiryRepo) {
+	ctx := context.Background()
+	i := NewInquiry(uuid.New(), "marta")
+	if err := ir.CreateInquiry(ctx, i); err != nil {
+		t.Fatalf("CreateInquiry: %v", err)
+	}
+
+	for _, body := range []string{"Called, no answer", "Wants a viewing on Saturday"} {
+		n := &estate.InquiryNote{InquiryID: i.ID, Body: body, CreatedBy: "agent-1"}
+		if err := ir.AddInquiryNote(ctx, n); err != nil {
+			t.Fatalf("AddInquiryNote: %v", err)
+		}
+		if n.ID == uuid.Nil || n.CreatedAt.IsZero() {
+			t.Errorf("expected an ID and a creation time, got %+v", n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	notes, err := ir.ListInquiryNotes(ctx, i.ID)
+	if err != nil {
+		t.Fatalf("ListInquiryNotes: %v", err)
+	}
+	if len(notes) != 2 || notes[0].Body != "Called, no answer" || notes[1].CreatedBy != "agent-1" || notes[1].InquiryID != i.ID {
+		t.Errorf("expected both notes oldest first, got %+v", notes)
+	}
+
+	err = ir.AddInquiryNote(ctx, &estate.InquiryNote{InquiryID: uuid.New(), Body: "Lost"})
+	if !errors.Is(err, estate.ErrInquiryNotFound) {
+		t.Errorf("expected ErrInquiryNotFound for a missing inquiry, got %v", err)
+	}
+}
+
+func testInquiryCount(t *testing.T, ir estate.InquiryRepo) {
+	ctx := context.Background()
+	since := time.Now().Add(-time.Minute)
+	flat := uuid.New()
+	for k, agent := range []string{"agent-1", "agent-1", "agent-2"} {
+		i := NewInquiry(flat, agent)
+		i.AgentID = agent
+		if k == 0 {
+			i.Stage, i.Outcome = estate.InquiryClosed, estate.InquiryWon
+		}
+		if err := ir.CreateInquiry(ctx, i); err != nil {
+			t.Fatalf("CreateInquiry: %v", err)
+		}
+	}
+
+	counts, err := ir.CountInquiries(ctx, since)
+	if err != nil {
+		t.Fatalf("CountInquiries: %v", err)
+	}
+	got := map[estate.InquiryCount]int{}
+	for _, c := range counts {
+		if c.PropertyID == flat {
+			got[estate.InquiryCount{PropertyID: c.PropertyID, AgentID: c.AgentID, Stage: c.Stage, Outcome: c.Outcome}] = c.Count
+		}
+	}
+	want := map[estate.InquiryCount]int{
+		{PropertyID: flat, AgentID: "agent-1", Stage: estate.InquiryClosed, Outcome: estate.InquiryWon}: 1,
+		{PropertyID: flat, AgentID: "agent-1", Stage: estate.InquiryNew}:                                1,
+		{PropertyID: flat, AgentID: "agent-2", Stage: estate.InquiryNew}:                                1,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected counts %v, got %v", want, got)
+	}
+	for k, n := range want {
+		if got[k] != n {
+			t.Errorf("expected %d inquiries for %+v, got %d", n, k, got[k])
+		}
+	}
+
+	later, err := ir.CountInquiries(ctx, time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatalf("CountInquiries: %v", err)
+	}
+	if len(later) != 0 {
+		t.Errorf("expected no inquiries after now, got %v", later)
+	}
+}
+
+func testInquiryMissing(t *testing.T, ir estate.InquiryRepo) {
+	ctx := context.Background()
+	if _, err := ir.GetInquiry(ctx, uuid.New()); !errors.Is(err, estate.ErrInquiryNotFound) {
+		t.Errorf("expected ErrInquiryNotFound, got %v", err)
+	}
+	i := NewInquiry(uuid.New(), "marta")
+	i.ID, i.Revision = uuid.New(), 1
+	if err := ir.SaveInquiry(ctx, i); !errors.Is(err, estate.ErrInquiryNotFound) {
+		t.Errorf("expected ErrInquiryNotFound, got %v", err)
+	}
+}
+
+func testInquiryPurgedWithProperty(t *testing.T, newRepo NewInquiryRepoFunc) {
+	repo, ir := newRepo(t)
+	ctx := context.Background()
+
+	testPurgedWithProperty(t, repo, func(p *estate.Property) func() error {
+		i := NewInquiry(p.ID, p.Name)
+		if err := ir.CreateInquiry(ctx, i); err != nil {
+			t.Fatalf("CreateInquiry: %v", err)
+		}
+		return func() error { _, err := ir.GetInquiry(ctx, i.ID); return err }
+	})
+}
+
+func testInquiryNotesPurgedWithProperty(t *testing.T, newRepo NewInquiryRepoFunc) {
+	repo, ir := newRepo(t)
+	ctx := context.Background()
+
+	testPurgedWithProperty(t, repo, func(p *estate.Property) func() error {
+		i := NewInquiry(p.ID, p.Name)
+		if err := ir.CreateInquiry(ctx, i); err != nil {
+			t.Fatalf("CreateInquiry: %v", err)
+		}
+		if err := ir.AddInquiryNote(ctx, &estate.InquiryNote{InquiryID: i.ID, Body: "Called back", CreatedBy: "tester"}); err != nil {
+			t.Fatalf("AddInquiryNote: %v", err)
+		}
+		return func() error {
+			notes, err := ir.ListInquiryNotes(ctx, i.ID)
+			if err == nil && len(notes) == 0 {
+				err = estate.ErrInquiryNotFound
+			}
+			return err
+		}
+	})
+}
@@ -0,0 +1,325 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/services/estate/internal/estate"
+	"github.com/pulap/pulap/services/estate/internal/money"
+)
+
+// NewLeaseRepoFunc returns an empty, ready-to-use lease repository for a
+// single subtest, with the property repository it stores leases
+// alongside.
+type NewLeaseRepoFunc func(t *testing.T) (estate.Repo, estate.LeaseRepo)
+
+// RunLeaseRepo runs the estate.LeaseRepo contract against the repository
+// returned by newRepo.
+func RunLeaseRepo(t *testing.T, newRepo NewLeaseRepoFunc) {
+	leases := func(t *testing.T) estate.LeaseRepo {
+		_, lr := newRepo(t)
+		return lr
+	}
+
+	t.Run("CreateAndGet", func(t *testing.T) { testLeaseCreateAndGet(t, leases(t)) })
+	t.Run("Overlap", func(t *testing.T) { testLeaseOverlap(t, leases(t)) })
+	t.Run("ConcurrentOverlap", func(t *testing.T) { testLeaseConcurrentOverlap(t, leases(t)) })
+	t.Run("Terminate", func(t *testing.T) { testLeaseTerminate(t, leases(t)) })
+	t.Run("List", func(t *testing.T) { testLeaseList(t, leases(t)) })
+	t.Run("Expiring", func(t *testing.T) { testLeaseExpiring(t, leases(t)) })
+	t.Run("Missing", func(t *testing.T) { testLeaseMissing(t, leases(t)) })
+	t.Run("PurgedWithProperty", func(t *testing.T) { testLeasePurgedWithProperty(t, newRepo) })
+}
+
+// NewLease returns a valid, indexed monthly lease of a property between
+// two days.
+func NewLease(propertyID uuid.UUID, start, end string) *estate.Lease {
+	return &estate.Lease{
+		PropertyID: propertyID,
+		Tenant:     estate.Contact{Name: "Lucía Ferrer", Email: "lucia@example.com", Phone: "+34 600 000 000"},
+		StartDate:  start,
+		EndDate:    end,
+		Rent:       money.MustParse("1150.00"),
+		Currency:   "EUR",
+		Frequency:  "rent_monthly",
+		Deposit:    money.MustParse("2300.00"),
+		Indexation: estate.Indexation{Method: estate.IndexationIndex, Index: "CPI", Cap: 3, IntervalMonths: 12},
+		Renewal:    estate.Renewal{Automatic: true, TermMonths: 12, NoticeDays: 60, MaxRenewals: 3},
+		CreatedBy:  "tester",
+		UpdatedBy:  "tester",
+	}
+}
+
+func testLeaseCreateAndGet(t *testing.T, lr estate.LeaseRepo) {
+	ctx := context.Background()
+	l := NewLease(uuid.New(), "2026-01-01", "2026-12-31")
+	if err := lr.CreateLease(ctx, l); err != nil {
+		t.Fatalf("CreateLease: %v", err)
+	}
+	if l.ID == uuid.Nil || l.Revision != 1 || l.Status != estate.LeaseActive {
+		t.Fatalf("expected an ID, revision 1 and an active lease, got %s, %d and %q", l.ID, l.Revision, l.Status)
+	}
+
+	got, err := lr.GetLease(ctx, l.ID)
+	if err != nil {
+		t.Fatalf("GetLease: %v", err)
+	}
+	if !got.CreatedAt.Equal(l.CreatedAt) || !got.UpdatedAt.Equal(l.UpdatedAt) {
+		t.Errorf("expected times %v and %v, got %v and %v", l.CreatedAt, l.UpdatedAt, got.CreatedAt, got.UpdatedAt)
+	}
+	if got.Rent.String() != "1150.00" || got.Deposit.String() != "2300.00" {
+		t.Errorf("expected the amount scales kept, got %s and %s", got.Rent, got.Deposit)
+	}
+	got.CreatedAt, got.UpdatedAt = l.CreatedAt, l.UpdatedAt
+	if !reflect.DeepEqual(got, l) {
+		t.Errorf("round trip mismatch:\nwant %+v\ngot  %+v", l, got)
+	}
+}
+
+func testLeaseOverlap(t *testing.T, lr estate.LeaseRepo) {
+	ctx := context.Background()
+	property := uuid.New()
+	first := NewLease(property, "2026-01-01", "2026-12-31")
+	if err := lr.CreateLease(ctx, first); err != nil {
+		t.Fatalf("CreateLease: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		start, end string
+		overlaps   bool
+	}{
+		{"inside", "2026-03-01", "2026-04-30", true},
+		{"same first day as the last", "2026-12-31", "2027-06-30", true},
+		{"ending on the first day", "2025-06-01", "2026-01-01", true},
+		{"covering", "2025-01-01", "2027-12-31", true},
+		{"before", "2025-01-01", "2025-12-31", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := lr.CreateLease(ctx, NewLease(property, tt.start, tt.end))
+			if tt.overlaps && !errors.Is(err, estate.ErrLeaseOverlap) {
+				t.Errorf("expected ErrLeaseOverlap, got %v", err)
+			}
+			if !tt.overlaps && err != nil {
+				t.Errorf("CreateLease: %v", err)
+			}
+		})
+	}
+
+	// Leases of other properties never overlap
+	if err := lr.CreateLease(ctx, NewLease(uuid.New(), "2026-03-01", "2026-04-30")); err != nil {
+		t.Errorf("CreateLease on another property: %v", err)
+	}
+}
+
+// testLeaseConcurrentOverlap creates the same lease from several
+// goroutines at once: at most one may be stored.
+func testLeaseConcurrentOverlap(t *testing.T, lr estate.LeaseRepo) {
+	ctx := context.Background()
+	property := uuid.New()
+
+	const writers = 8
+	errs := make(chan error, writers)
+	var wg sync.WaitGroup
+	for range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- lr.CreateLease(ctx, NewLease(property, "2026-01-01", "2026-12-31"))
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	created := 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			created++
+		case !errors.Is(err, estate.ErrLeaseOverlap):
+			t.Errorf("expected ErrLeaseOverlap, got %v", err)
+		}
+	}
+	if created > 1 {
+		t.Errorf("expected at most one lease created, got %d", created)
+	}
+
+	stored, err := lr.ListLeases(ctx, estate.LeaseQuery{PropertyIDs: []uuid.UUID{property}, Limit: writers})
+	if err != nil {
+		t.Fatalf("ListLeases: %v", err)
+	}
+	if len(stored) != created {
+		t.Errorf("expected %d leases stored, got %d", created, len(stored))
+	}
+}
+
+func testLeaseTerminate(t *testing.T, lr estate.LeaseRepo) {
+	ctx := context.Background()
+	property := uuid.New()
+	l := NewLease(property, "2026-01-01", "2026-12-31")
+	if err := lr.CreateLease(ctx, l); err != nil {
+		t.Fatalf("CreateLease: %v", err)
+	}
+
+	if err := l.Terminate("2026-06-30", "tenant moved abroad"); err != nil {
+		t.Fatalf("Terminate: %v", err)
+	}
+	l.UpdatedBy = "editor"
+	if err := lr.SaveLease(ctx, l); err != nil {
+		t.Fatalf("SaveLease: %v", err)
+	}
+	if l.Revision != 2 {
+		t.Errorf("expected revision 2, got %d", l.Revision)
+	}
+
+	got, err := lr.GetLease(ctx, l.ID)
+	if err != nil {
+		t.Fatalf("GetLease: %v", err)
+	}
+	if got.Status != estate.LeaseTerminated || got.TerminatedOn != "2026-06-30" || got.TerminationReason != "tenant moved abroad" || got.UpdatedBy != "editor" {
+		t.Errorf("unexpected lease after termination: %+v", got)
+	}
+
+	// The days after the termination are free again
+	if err := lr.CreateLease(ctx, NewLease(property, "2026-06-30", "2026-12-31")); !errors.Is(err, estate.ErrLeaseOverlap) {
+		t.Errorf("expected the last day to be taken, got %v", err)
+	}
+	if err := lr.CreateLease(ctx, NewLease(property, "2026-07-01", "2027-06-30")); err != nil {
+		t.Errorf("CreateLease after the termination: %v", err)
+	}
+
+	stale := *got
+	stale.Revision = 1
+	if err := lr.SaveLease(ctx, &stale); !errors.Is(err, estate.ErrRevisionConflict) {
+		t.Errorf("expected ErrRevisionConflict, got %v", err)
+	}
+}
+
+func testLeaseList(t *testing.T, lr estate.LeaseRepo) {
+	ctx := context.Background()
+	house, flat := uuid.New(), uuid.New()
+
+	names := map[uuid.UUID]string{}
+	create := func(name string, l *estate.Lease) *estate.Lease {
+		if err := lr.CreateLease(ctx, l); err != nil {
+			t.Fatalf("CreateLease %s: %v", name, err)
+		}
+		names[l.ID] = name
+		return l
+	}
+	old := create("old", NewLease(house, "2024-01-01", "2024-12-31"))
+	create("current", NewLease(house, "2025-01-01", "2026-12-31"))
+	create("flat", NewLease(flat, "2025-06-01", "2026-05-31"))
+
+	if err := old.Terminate("2024-09-30", ""); err != nil {
+		t.Fatalf("Terminate: %v", err)
+	}
+	if err := lr.SaveLease(ctx, old); err != nil {
+		t.Fatalf("SaveLease: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		query estate.LeaseQuery
+		want  []string
+	}{
+		{"property latest first", estate.LeaseQuery{PropertyIDs: []uuid.UUID{house}}, []string{"current", "old"}},
+		{"properties", estate.LeaseQuery{PropertyIDs: []uuid.UUID{house, flat}}, []string{"flat", "current", "old"}},
+		{"status", estate.LeaseQuery{PropertyIDs: []uuid.UUID{house, flat}, Statuses: []string{estate.LeaseTerminated}}, []string{"old"}},
+		{"limit", estate.LeaseQuery{PropertyIDs: []uuid.UUID{house, flat}, Limit: 1}, []string{"flat"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := tt.query
+			if errs := query.Normalize(); len(errs) > 0 {
+				t.Fatalf("Normalize: %v", errs)
+			}
+			list, err := lr.ListLeases(ctx, query)
+			if err != nil {
+				t.Fatalf("ListLeases: %v", err)
+			}
+			var got []string
+			for _, l := range list {
+				got = append(got, names[l.ID])
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func testLeaseExpiring(t *testing.T, lr estate.LeaseRepo) {
+	ctx := context.Background()
+
+	names := map[uuid.UUID]string{}
+	for name, l := range map[string]*estate.Lease{
+		"march":      NewLease(uuid.New(), "2025-04-01", "2026-03-31"),
+		"february":   NewLease(uuid.New(), "2025-03-01", "2026-02-28"),
+		"later":      NewLease(uuid.New(), "2025-07-01", "2026-06-30"),
+		"past":       NewLease(uuid.New(), "2025-01-01", "2025-12-31"),
+		"terminated": NewLease(uuid.New(), "2025-03-01", "2026-03-15"),
+	} {
+		if err := lr.CreateLease(ctx, l); err != nil {
+			t.Fatalf("CreateLease %s: %v", name, err)
+		}
+		if name == "terminated" {
+			if err := l.Terminate("2025-10-31", ""); err != nil {
+				t.Fatalf("Terminate: %v", err)
+			}
+			if err := lr.SaveLease(ctx, l); err != nil {
+				t.Fatalf("SaveLease: %v", err)
+			}
+		}
+		names[l.ID] = name
+	}
+
+	list, err := lr.ListExpiringLeases(ctx, "2026-01-01", "2026-03-31", 10)
+	if err != nil {
+		t.Fatalf("ListExpiringLeases: %v", err)
+	}
+	var got []string
+	for _, l := range list {
+		got = append(got, names[l.ID])
+	}
+	if want := []string{"february", "march"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	if list, _ := lr.ListExpiringLeases(ctx, "2026-01-01", "2026-12-31", 1); len(list) != 1 || names[list[0].ID] != "february" {
+		t.Errorf("expected the limit to keep the soonest, got %d leases", len(list))
+	}
+}
+
+func testLeaseMissing(t *testing.T, lr estate.LeaseRepo) {
+	ctx := context.Background()
+	if _, err := lr.GetLease(ctx, uuid.New()); !errors.Is(err, estate.ErrLeaseNotFound) {
+		t.Errorf("GetLease: expected ErrLeaseNotFound, got %v", err)
+	}
+
+	l := NewLease(uuid.New(), "2026-01-01", "2026-12-31")
+	l.ID, l.Revision = uuid.New(), 1
+	if err := lr.SaveLease(ctx, l); !errors.Is(err, estate.ErrLeaseNotFound) {
+		t.Errorf("SaveLease: expected ErrLeaseNotFound, got %v", err)
+	}
+}
+
+func testLeasePurgedWithProperty(t *testing.T, newRepo NewLeaseRepoFunc) {
+	repo, lr := newRepo(t)
+	ctx := context.Background()
+
+	testPurgedWithProperty(t, repo, func(p *estate.Property) func() error {
+		l := NewLease(p.ID, "2026-01-01", "2026-12-31")
+		if err := lr.CreateLease(ctx, l); err != nil {
+			t.Fatalf("CreateLease: %v", err)
+		}
+		return func() error { _, err := lr.GetLease(ctx, l.ID); return err }
+	})
+}
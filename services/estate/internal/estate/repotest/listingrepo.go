@@ -0,0 +1,279 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/services/estate/internal/estate"
+	"github.com/pulap/pulap/services/estate/internal/money"
+)
+
+// NewListingRepoFunc returns an empty, ready-to-use listing repository
+// for a single subtest, with the property repository it stores listings
+// alongside.
+type NewListingRepoFunc func(t *testing.T) (estate.Repo, estate.ListingRepo)
+
+// RunListingRepo runs the estate.ListingRepo contract against the
+// repository returned by newRepo.
+func RunListingRepo(t *testing.T, newRepo NewListingRepoFunc) {
+	listings := func(t *testing.T) estate.ListingRepo {
+		_, lr := newRepo(t)
+		return lr
+	}
+
+	t.Run("CreateAndGet", func(t *testing.T) { testListingCreateAndGet(t, listings(t)) })
+	t.Run("Save", func(t *testing.T) { testListingSave(t, listings(t)) })
+	t.Run("Delete", func(t *testing.T) { testListingDelete(t, listings(t)) })
+	t.Run("List", func(t *testing.T) { testListingList(t, listings(t)) })
+	t.Run("Missing", func(t *testing.T) { testListingMissing(t, listings(t)) })
+	t.Run("PurgedWithProperty", func(t *testing.T) { testListingPurgedWithProperty(t, newRepo) })
+}
+
+// NewListing returns a valid draft listing of a property for sale.
+func NewListing(propertyID uuid.UUID, channel string) *estate.Listing {
+	return &estate.Listing{
+		PropertyID: propertyID,
+		Channel:    channel,
+		Price:      estate.Price{Amount: money.MustParse("250000.00"), Currency: "EUR", Type: "sale", Negotiable: true},
+		Content: map[string]estate.ListingContent{
+			"es": {Headline: "Piso luminoso junto al parque", Description: "Tres dormitorios y terraza."},
+			"en": {Headline: "Bright flat by the park", Description: "Three bedrooms and a terrace."},
+		},
+		CreatedBy: "tester",
+		UpdatedBy: "tester",
+	}
+}
+
+func testListingCreateAndGet(t *testing.T, lr estate.ListingRepo) {
+	ctx := context.Background()
+	l := NewListing(uuid.New(), "website")
+	ends := time.Date(2030, 1, 31, 0, 0, 0, 0, time.UTC)
+	l.EndsAt = &ends
+	if err := lr.CreateListing(ctx, l); err != nil {
+		t.Fatalf("CreateListing: %v", err)
+	}
+	if l.ID == uuid.Nil || l.Revision != 1 || l.Status != estate.ListingDraft {
+		t.Fatalf("expected an ID, revision 1 and a draft, got %s, %d and %q", l.ID, l.Revision, l.Status)
+	}
+
+	got, err := lr.GetListing(ctx, l.ID)
+	if err != nil {
+		t.Fatalf("GetListing: %v", err)
+	}
+	if !got.CreatedAt.Equal(l.CreatedAt) || !got.UpdatedAt.Equal(l.UpdatedAt) {
+		t.Errorf("expected times %v and %v, got %v and %v", l.CreatedAt, l.UpdatedAt, got.CreatedAt, got.UpdatedAt)
+	}
+	if got.EndsAt == nil || !got.EndsAt.Equal(ends) || got.StartsAt != nil || got.EndedAt != nil {
+		t.Errorf("expected only the window end, got %v, %v and %v", got.StartsAt, got.EndsAt, got.EndedAt)
+	}
+	if got.Price.Amount.String() != "250000.00" {
+		t.Errorf("expected the amount scale kept, got %s", got.Price.Amount)
+	}
+	got.CreatedAt, got.UpdatedAt, got.EndsAt = l.CreatedAt, l.UpdatedAt, l.EndsAt
+	if !reflect.DeepEqual(got, l) {
+		t.Errorf("round trip mismatch:\nwant %+v\ngot  %+v", l, got)
+	}
+
+	prices, err := lr.ListListingPrices(ctx, l.ID)
+	if err != nil {
+		t.Fatalf("ListListingPrices: %v", err)
+	}
+	if len(prices) != 1 || prices[0].ListingID != l.ID || prices[0].Price.Amount.String() != "250000.00" || prices[0].Actor != "tester" {
+		t.Errorf("expected the initial price in the history, got %+v", prices)
+	}
+}
+
+func testListingSave(t *testing.T, lr estate.ListingRepo) {
+	ctx := context.Background()
+	l := NewListing(uuid.New(), "website")
+	if err := lr.CreateListing(ctx, l); err != nil {
+		t.Fatalf("CreateListing: %v", err)
+	}
+
+	// Changing only texts and status leaves the price history alone
+	if err := l.Transition(estate.ListingActive, time.Now()); err != nil {
+		t.Fatalf("Transition: %v", err)
+	}
+	l.Content["en"] = estate.ListingContent{Headline: "Sunny flat by the park"}
+	if err := lr.SaveListing(ctx, l); err != nil {
+		t.Fatalf("SaveListing: %v", err)
+	}
+
+	// The same amount at another scale is not a new price
+	l.Price.Amount = money.MustParse("250000")
+	if err := lr.SaveListing(ctx, l); err != nil {
+		t.Fatalf("SaveListing: %v", err)
+	}
+
+	l.Price.Amount = money.MustParse("239000")
+	l.UpdatedBy = "editor"
+	if err := lr.SaveListing(ctx, l); err != nil {
+		t.Fatalf("SaveListing: %v", err)
+	}
+	if l.Revision != 4 {
+		t.Errorf("expected revision 4, got %d", l.Revision)
+	}
+
+	got, err := lr.GetListing(ctx, l.ID)
+	if err != nil {
+		t.Fatalf("GetListing: %v", err)
+	}
+	if got.Status != estate.ListingActive || got.StartsAt == nil || got.Revision != 4 || got.Content["en"].Headline != "Sunny flat by the park" {
+		t.Errorf("unexpected listing after save: %+v", got)
+	}
+
+	prices, err := lr.ListListingPrices(ctx, l.ID)
+	if err != nil {
+		t.Fatalf("ListListingPrices: %v", err)
+	}
+	var amounts []string
+	for _, p := range prices {
+		amounts = append(amounts, p.Price.Amount.String()+" by "+p.Actor)
+	}
+	if want := []string{"250000.00 by tester", "239000 by editor"}; !reflect.DeepEqual(amounts, want) {
+		t.Errorf("expected price history %v, got %v", want, amounts)
+	}
+
+	stale := *got
+	stale.Revision = 3
+	stale.Price.Amount = money.MustParse("1")
+	if err := lr.SaveListing(ctx, &stale); !errors.Is(err, estate.ErrRevisionConflict) {
+		t.Errorf("expected ErrRevisionConflict, got %v", err)
+	}
+	if prices, _ := lr.ListListingPrices(ctx, l.ID); len(prices) != 2 {
+		t.Errorf("expected a conflicting save to record no price, got %d entries", len(prices))
+	}
+}
+
+func testListingDelete(t *testing.T, lr estate.ListingRepo) {
+	ctx := context.Background()
+	l := NewListing(uuid.New(), "website")
+	if err := lr.CreateListing(ctx, l); err != nil {
+		t.Fatalf("CreateListing: %v", err)
+	}
+
+	if err := lr.DeleteListing(ctx, l.ID, l.Revision+1); !errors.Is(err, estate.ErrRevisionConflict) {
+		t.Errorf("expected ErrRevisionConflict, got %v", err)
+	}
+	if err := lr.DeleteListing(ctx, l.ID, l.Revision); err != nil {
+		t.Fatalf("DeleteListing: %v", err)
+	}
+	if _, err := lr.GetListing(ctx, l.ID); !errors.Is(err, estate.ErrListingNotFound) {
+		t.Errorf("expected ErrListingNotFound after delete, got %v", err)
+	}
+	if prices, _ := lr.ListListingPrices(ctx, l.ID); len(prices) != 0 {
+		t.Errorf("expected the price history deleted, got %d entries", len(prices))
+	}
+}
+
+func testListingList(t *testing.T, lr estate.ListingRepo) {
+	ctx := context.Background()
+	house, flat := uuid.New(), uuid.New()
+	now := time.Now().UTC()
+	later := now.Add(24 * time.Hour)
+
+	// One property sold and rented in parallel on two channels, with an
+	// earlier listing withdrawn, and another scheduled to start later
+	old := NewListing(house, "website")
+	sale := NewListing(house, "website")
+	rent := NewListing(house, "idealista")
+	rent.Price = estate.Price{Amount: money.MustParse("1200"), Currency: "EUR", Type: "rent_monthly"}
+	scheduled := NewListing(flat, "website")
+	scheduled.StartsAt = &later
+
+	names := map[uuid.UUID]string{}
+	for _, item := range []struct {
+		name string
+		l    *estate.Listing
+		to   []string
+	}{
+		{"old", old, []string{estate.ListingActive, estate.ListingWithdrawn}},
+		{"sale", sale, []string{estate.ListingActive}},
+		{"rent", rent, []string{estate.ListingActive, estate.ListingPaused}},
+		{"scheduled", scheduled, []string{estate.ListingActive}},
+	} {
+		if err := lr.CreateListing(ctx, item.l); err != nil {
+			t.Fatalf("CreateListing %s: %v", item.name, err)
+		}
+		for _, to := range item.to {
+			if err := item.l.Transition(to, now); err != nil {
+				t.Fatalf("Transition %s: %v", item.name, err)
+			}
+		}
+		if err := lr.SaveListing(ctx, item.l); err != nil {
+			t.Fatalf("SaveListing %s: %v", item.name, err)
+		}
+		names[item.l.ID] = item.name
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	soon := now.Add(time.Minute)
+	tomorrow := later.Add(time.Minute)
+	tests := []struct {
+		name  string
+		query estate.ListingQuery
+		want  []string
+	}{
+		{"all newest first", estate.ListingQuery{}, []string{"scheduled", "rent", "sale", "old"}},
+		{"property", estate.ListingQuery{PropertyIDs: []uuid.UUID{house}}, []string{"rent", "sale", "old"}},
+		{"channel", estate.ListingQuery{Channels: []string{"IDEALISTA"}}, []string{"rent"}},
+		{"status", estate.ListingQuery{Statuses: []string{estate.ListingWithdrawn, estate.ListingPaused}}, []string{"rent", "old"}},
+		{"live now", estate.ListingQuery{LiveAt: &soon}, []string{"sale"}},
+		{"live tomorrow", estate.ListingQuery{LiveAt: &tomorrow}, []string{"scheduled", "sale"}},
+		{"limit", estate.ListingQuery{Limit: 2}, []string{"scheduled", "rent"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := tt.query
+			if errs := query.Normalize(); len(errs) > 0 {
+				t.Fatalf("Normalize: %v", errs)
+			}
+			list, err := lr.ListListings(ctx, query)
+			if err != nil {
+				t.Fatalf("ListListings: %v", err)
+			}
+			var got []string
+			for _, l := range list {
+				got = append(got, names[l.ID])
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func testListingMissing(t *testing.T, lr estate.ListingRepo) {
+	ctx := context.Background()
+	if _, err := lr.GetListing(ctx, uuid.New()); !errors.Is(err, estate.ErrListingNotFound) {
+		t.Errorf("GetListing: expected ErrListingNotFound, got %v", err)
+	}
+
+	l := NewListing(uuid.New(), "website")
+	l.ID, l.Revision = uuid.New(), 1
+	if err := lr.SaveListing(ctx, l); !errors.Is(err, estate.ErrListingNotFound) {
+		t.Errorf("SaveListing: expected ErrListingNotFound, got %v", err)
+	}
+	if err := lr.DeleteListing(ctx, uuid.New(), 0); !errors.Is(err, estate.ErrListingNotFound) {
+		t.Errorf("DeleteListing: expected ErrListingNotFound, got %v", err)
+	}
+}
+
+func testListingPurgedWithProperty(t *testing.T, newRepo NewListingRepoFunc) {
+	repo, lr := newRepo(t)
+	ctx := context.Background()
+
+	testPurgedWithProperty(t, repo, func(p *estate.Property) func() error {
+		l := NewListing(p.ID, "web")
+		if err := lr.CreateListing(ctx, l); err != nil {
+			t.Fatalf("CreateListing: %v", err)
+		}
+		return func() error { _, err := lr.GetListing(ctx, l.ID); return err }
+	})
+}
@@ -0,0 +1,244 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/services/estate/internal/estate"
+)
+
+// RunPropertyEvents runs the estate.OutboxRepo contract. It is skipped for
+// repositories without an event outbox.
+func RunPropertyEvents(t *testing.T, newRepo NewRepoFunc) {
+	outbox := func(t *testing.T) estate.Repo {
+		repo := newRepo(t)
+		if _, ok := repo.(estate.OutboxRepo); !ok {
+			t.Skipf("%T does not implement estate.OutboxRepo", repo)
+		}
+		return repo
+	}
+
+	t.Run("WrittenWithAggregate", func(t *testing.T) { testEventsWrittenWithAggregate(t, outbox(t)) })
+	t.Run("NotWrittenOnConflict", func(t *testing.T) { testEventsNotWrittenOnConflict(t, outbox(t)) })
+	t.Run("Publish", func(t *testing.T) { testEventsPublish(t, outbox(t)) })
+	t.Run("PendingExclude", func(t *testing.T) { testEventsPendingExclude(t, outbox(t)) })
+	t.Run("KeptAfterPurge", func(t *testing.T) { testEventsKeptAfterPurge(t, outbox(t)) })
+}
+
+func testEventsWrittenWithAggregate(t *testing.T, repo estate.Repo) {
+	outbox := repo.(estate.OutboxRepo)
+	ctx := estate.WithActor(context.Background(), "agent-1")
+	p := writeEventHistory(t, ctx, repo)
+
+	pending, err := outbox.PendingEvents(ctx, 10, nil)
+	if err != nil {
+		t.Fatalf("PendingEvents: %v", err)
+	}
+
+	want := []string{estate.EventPropertyCreated, estate.EventPropertyUpdated, estate.EventPropertyStatusChanged, estate.EventPropertyDeleted}
+	if len(pending) != len(want) {
+		t.Fatalf("expected %d pending events, got %d", len(want), len(pending))
+	}
+	for i, e := range pending {
+		if e.Type != want[i] || e.AggregateID != p.ID || e.Sequence != int64(i+1) || e.Actor != "agent-1" || e.PublishedAt != nil {
+			t.Errorf("unexpected event %d: %+v", i, e)
+		}
+		if e.Data.Property == nil || e.Data.Property.ID != p.ID {
+			t.Errorf("expected event %d to carry the property, got %+v", i, e.Data)
+		}
+	}
+
+	if got := pending[1].Data; got.Action != estate.ActionUpdate || len(got.Changes) != 1 || got.Changes[0].Path != "/name" {
+		t.Errorf("expected the update to carry the name change, got %+v", got)
+	}
+	if got := pending[2].Data; got.From != estate.StatusAvailable || got.To != estate.StatusReserved {
+		t.Errorf("expected available to reserved, got %q to %q", got.From, got.To)
+	}
+
+	if pending, err := outbox.PendingEvents(ctx, 2, nil); err != nil || len(pending) != 2 || pending[0].Sequence != 1 {
+		t.Errorf("expected the 2 oldest events, got %v (%v)", pending, err)
+	}
+}
+
+func testEventsNotWrittenOnConflict(t *testing.T, repo estate.Repo) {
+	outbox := repo.(estate.OutboxRepo)
+	ctx := context.Background()
+	p := NewProperty("Mayor 12")
+	if err := repo.Create(ctx, p); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	stale := *p
+	p.Name = "Mayor 14"
+	if err := repo.Save(ctx, p); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	stale.Name = "Mayor 16"
+	if err := repo.Save(ctx, &stale); err == nil {
+		t.Fatal("expected the stale save to fail")
+	}
+
+	pending, err := outbox.PendingEvents(ctx, 10, nil)
+	if err != nil {
+		t.Fatalf("PendingEvents: %v", err)
+	}
+	if len(pending) != 2 {
+		t.Errorf("expected the events of the create and the save, got %d", len(pending))
+	}
+}
+
+func testEventsPendingExclude(t *testing.T, repo estate.Repo) {
+	outbox := repo.(estate.OutboxRepo)
+	ctx := context.Background()
+	p := writeEventHistory(t, ctx, repo)
+	other := NewProperty("Mayor 20")
+	if err := repo.Create(ctx, other); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	pending, err := outbox.PendingEvents(ctx, 2, []uuid.UUID{p.ID})
+	if err != nil {
+		t.Fatalf("PendingEvents: %v", err)
+	}
+	if len(pending) != 1 || pending[0].AggregateID != other.ID {
+		t.Errorf("expected only the create of the other property, got %+v", pending)
+	}
+}
+
+func testEventsPublish(t *testing.T, repo estate.Repo) {
+	outbox := repo.(estate.OutboxRepo)
+	ctx := context.Background()
+	p := writeEventHistory(t, ctx, repo)
+	other := NewProperty("Mayor 20")
+	if err := repo.Create(ctx, other); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	pending, err := outbox.PendingEvents(ctx, 3, nil)
+	if err != nil {
+		t.Fatalf("PendingEvents: %v", err)
+	}
+	at := time.Now().UTC().Truncate(time.Millisecond)
+	if err := outbox.MarkPublished(ctx, pending, at); err != nil {
+		t.Fatalf("MarkPublished: %v", err)
+	}
+	if err := outbox.MarkPublished(ctx, pending[:1], at.Add(time.Minute)); err != nil {
+		t.Fatalf("MarkPublished again: %v", err)
+	}
+
+	left, err := outbox.PendingEvents(ctx, 10, nil)
+	if err != nil {
+		t.Fatalf("PendingEvents: %v", err)
+	}
+	if len(left) != 2 || left[0].Sequence != 4 || left[0].AggregateID != p.ID || left[1].AggregateID != other.ID {
+		t.Fatalf("expected the delete and the other create left, got %+v", left)
+	}
+	if err := outbox.MarkPublished(ctx, left, at); err != nil {
+		t.Fatalf("MarkPublished: %v", err)
+	}
+
+	published, err := outbox.Events(ctx, estate.EventQuery{})
+	if err != nil {
+		t.Fatalf("Events: %v", err)
+	}
+	if len(published) != 5 {
+		t.Fatalf("expected 5 published events, got %d", len(published))
+	}
+	for i, e := range published {
+		if e.PublishedAt == nil || (i > 0 && e.Position <= published[i-1].Position) {
+			t.Errorf("expected published events in position order, got %+v", e)
+		}
+	}
+	if !published[0].PublishedAt.Equal(at) {
+		t.Errorf("expected the first publish time %s kept, got %s", at, published[0].PublishedAt)
+	}
+
+	tests := []struct {
+		name  string
+		query estate.EventQuery
+		want  int
+	}{
+		{"aggregate", estate.EventQuery{AggregateID: other.ID}, 1},
+		{"types", estate.EventQuery{Types: []string{estate.EventPropertyCreated, estate.EventPropertyDeleted}}, 3},
+		{"after", estate.EventQuery{After: published[1].Position}, 3},
+		{"limit", estate.EventQuery{Limit: 2}, 2},
+		{"since", estate.EventQuery{Since: time.Now().Add(time.Hour)}, 0},
+		{"unknown aggregate", estate.EventQuery{AggregateID: uuid.New()}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := outbox.Events(ctx, tt.query)
+			if err != nil {
+				t.Fatalf("Events: %v", err)
+			}
+			if len(got) != tt.want {
+				t.Errorf("expected %d events, got %d", tt.want, len(got))
+			}
+		})
+	}
+}
+
+func testEventsKeptAfterPurge(t *testing.T, repo estate.Repo) {
+	outbox := repo.(estate.OutboxRepo)
+	ctx := context.Background()
+	p := writeEventHistory(t, ctx, repo)
+
+	purged, err := repo.Purge(ctx, time.Now().Add(time.Second))
+	if err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+	if len(purged) != 1 || purged[0] != p.ID {
+		t.Fatalf("expected the property purged with pending events, got %v", purged)
+	}
+
+	pending, err := outbox.PendingEvents(ctx, 10, nil)
+	if err != nil {
+		t.Fatalf("PendingEvents: %v", err)
+	}
+	if len(pending) != 4 {
+		t.Fatalf("expected the 4 events of the purged property pending, got %d", len(pending))
+	}
+	for i, e := range pending {
+		if e.AggregateID != p.ID || e.Sequence != int64(i+1) {
+			t.Errorf("expected event %d in sequence order, got %+v", i, e)
+		}
+	}
+
+	if err := outbox.MarkPublished(ctx, pending, time.Now().UTC()); err != nil {
+		t.Fatalf("MarkPublished: %v", err)
+	}
+	if left, err := outbox.PendingEvents(ctx, 10, nil); err != nil || len(left) != 0 {
+		t.Errorf("expected no pending events, got %d (%v)", len(left), err)
+	}
+	if published, err := outbox.Events(ctx, estate.EventQuery{AggregateID: p.ID}); err != nil || len(published) != 4 {
+		t.Errorf("expected 4 published events, got %d (%v)", len(published), err)
+	}
+}
+
+// writeEventHistory creates, renames, reserves and deletes a property.
+func writeEventHistory(t *testing.T, ctx context.Context, repo estate.Repo) *estate.Property {
+	t.Helper()
+
+	p := NewProperty("Mayor 12")
+	if err := repo.Create(ctx, p); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	p.Name = "Mayor 14"
+	if err := repo.Save(ctx, p); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	tr, err := p.Transition(estate.StatusReserved, "deposit received", "agent-1", false)
+	if err != nil {
+		t.Fatalf("Transition: %v", err)
+	}
+	if err := repo.Transition(ctx, tr); err != nil {
+		t.Fatalf("repo.Transition: %v", err)
+	}
+	if err := repo.Delete(ctx, p.ID, 0); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	return p
+}
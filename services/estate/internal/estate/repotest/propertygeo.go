@@ -0,0 +1,104 @@
+package repotest
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/pulap/pulap/services/estate/internal/estate"
+)
+
+// RunPropertyGeo runs the estate.Repo SearchGeo contract against the repository returned by newRepo.
+func RunPropertyGeo(t *testing.T, newRepo NewRepoFunc) {
+	repo := newRepo(t)
+	ctx := context.Background()
+
+	// Madrid landmarks, roughly 1 km apart, plus one far away and one without coordinates.
+	places := []struct {
+		name     string
+		lat, lng float64
+		status   string
+	}{
+		{"Sol", 40.4169, -3.7035, "available"},
+		{"Opera", 40.4180, -3.7100, "available"},
+		{"Retiro", 40.4153, -3.6845, "sold"},
+		{"Barcelona", 41.3874, 2.1686, "available"},
+		{"Unknown", 0, 0, "available"},
+	}
+	for _, pl := range places {
+		p := NewProperty(pl.name)
+		p.Location.Coordinates = estate.Coordinates{Latitude: pl.lat, Longitude: pl.lng}
+		p.Status = pl.status
+		if err := repo.Create(ctx, p); err != nil {
+			t.Fatalf("Create %s: %v", pl.name, err)
+		}
+	}
+
+	sol := estate.GeoPoint{Lat: 40.4169, Lng: -3.7035}
+
+	tests := []struct {
+		name  string
+		query estate.GeoQuery
+		want  []string // in distance order
+	}{
+		{
+			name:  "radius",
+			query: estate.GeoQuery{Shape: estate.GeoRadius, Center: sol, RadiusMeters: 1000},
+			want:  []string{"Sol", "Opera"},
+		},
+		{
+			name:  "radius with filter",
+			query: estate.GeoQuery{Shape: estate.GeoRadius, Center: sol, RadiusMeters: 5000, Filter: estate.PropertyQuery{Statuses: []string{"available"}}},
+			want:  []string{"Sol", "Opera"},
+		},
+		{
+			name:  "radius limit",
+			query: estate.GeoQuery{Shape: estate.GeoRadius, Center: sol, RadiusMeters: 5000, Limit: 2},
+			want:  []string{"Sol", "Opera"},
+		},
+		{
+			name:  "bbox",
+			query: estate.GeoQuery{Shape: estate.GeoBBox, BBox: estate.BoundingBox{South: 40.41, West: -3.705, North: 40.42, East: -3.68}},
+			want:  []string{"Retiro", "Sol"},
+		},
+		{
+			name: "polygon",
+			query: estate.GeoQuery{Shape: estate.GeoPolygon, Polygon: []estate.GeoPoint{
+				{Lat: 40.41, Lng: -3.72}, {Lat: 40.42, Lng: -3.72}, {Lat: 40.42, Lng: -3.70}, {Lat: 40.41, Lng: -3.70},
+			}},
+			want: []string{"Opera", "Sol"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := tt.query
+			if errs := q.Normalize(); len(errs) > 0 {
+				t.Fatalf("Normalize: %v", errs)
+			}
+
+			results, err := repo.SearchGeo(ctx, q)
+			if err != nil {
+				t.Fatalf("SearchGeo: %v", err)
+			}
+
+			var got []string
+			for _, r := range results {
+				got = append(got, r.Property.Name)
+				point, _ := estate.PointOf(r.Property)
+				if want := estate.DistanceMeters(q.Center, point); math.Abs(r.DistanceMeters-want) > want*0.01+1 {
+					t.Errorf("%s: expected distance ~%.0fm, got %.0fm", r.Property.Name, want, r.DistanceMeters)
+				}
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("expected %v, got %v", tt.want, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/services/estate/internal/estate"
+	"github.com/pulap/pulap/services/estate/internal/money"
+)
+
+// RunPropertyHistory runs the revision history contract.
+func RunPropertyHistory(t *testing.T, newRepo NewRepoFunc) {
+	t.Run("RecordsEveryWrite", func(t *testing.T) { testHistoryRecordsEveryWrite(t, newRepo(t)) })
+	t.Run("RevisionSnapshot", func(t *testing.T) { testHistoryRevisionSnapshot(t, newRepo(t)) })
+	t.Run("SurvivesDelete", func(t *testing.T) { testHistorySurvivesDelete(t, newRepo(t)) })
+	t.Run("StaleSaveNotRecorded", func(t *testing.T) { testHistoryStaleSaveNotRecorded(t, newRepo(t)) })
+	t.Run("RevisionMissing", func(t *testing.T) { testHistoryRevisionMissing(t, newRepo(t)) })
+}
+
+func testHistoryRecordsEveryWrite(t *testing.T, repo estate.Repo) {
+	ctx := context.Background()
+	p := NewProperty("Mayor 12")
+	if err := repo.Create(ctx, p); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	p.Name = "Mayor 12 renovated"
+	p.UpdatedBy = "editor"
+	if err := repo.Save(ctx, p); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	tr, err := p.Transition(estate.StatusReserved, "deposit received", "agent-1", false)
+	if err != nil {
+		t.Fatalf("Transition: %v", err)
+	}
+	if err := repo.Transition(ctx, tr); err != nil {
+		t.Fatalf("repo.Transition: %v", err)
+	}
+
+	revisions, err := repo.Revisions(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("Revisions: %v", err)
+	}
+
+	want := []struct {
+		number int64
+		action string
+		actor  string
+		change string
+	}{
+		{3, estate.ActionTransition, "agent-1", "/status"},
+		{2, estate.ActionUpdate, "editor", "/name"},
+		{1, estate.ActionCreate, "tester", "/name"},
+	}
+	if len(revisions) != len(want) {
+		t.Fatalf("expected %d revisions, got %+v", len(want), revisions)
+	}
+	for i, w := range want {
+		rev := revisions[i]
+		if rev.Number != w.number || rev.Action != w.action || rev.Actor != w.actor {
+			t.Errorf("revision %d: got #%d %s by %q, want #%d %s by %q",
+				i, rev.Number, rev.Action, rev.Actor, w.number, w.action, w.actor)
+		}
+		if rev.Snapshot != nil {
+			t.Errorf("revision %d: expected list without snapshot", i)
+		}
+		if !hasChange(rev.Changes, w.change) {
+			t.Errorf("revision %d: expected change to %s, got %+v", i, w.change, rev.Changes)
+		}
+	}
+	if len(revisions[0].Changes) != 1 {
+		t.Errorf("expected transition to change only the status, got %+v", revisions[0].Changes)
+	}
+}
+
+func testHistoryRevisionSnapshot(t *testing.T, repo estate.Repo) {
+	ctx := context.Background()
+	p := NewProperty("Mayor 12")
+	if err := repo.Create(ctx, p); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	original, err := repo.Get(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+
+	p.Prices[0].Amount = money.FromInt(340000)
+	if err := repo.Save(ctx, p); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	rev, err := repo.Revision(ctx, p.ID, 1)
+	if err != nil {
+		t.Fatalf("Revision: %v", err)
+	}
+	AssertSameProperty(t, original, rev.Snapshot)
+
+	rev, err = repo.Revision(ctx, p.ID, 2)
+	if err != nil {
+		t.Fatalf("Revision: %v", err)
+	}
+	if rev.Snapshot == nil || rev.Snapshot.Revision != 2 || !rev.Snapshot.Prices[0].Amount.Equal(money.FromInt(340000)) {
+		t.Errorf("expected snapshot of the saved version, got %+v", rev.Snapshot)
+	}
+	if len(rev.Changes) != 1 || rev.Changes[0].Path != "/prices/0/amount" {
+		t.Errorf("expected a single price change, got %+v", rev.Changes)
+	}
+}
+
+func testHistorySurvivesDelete(t *testing.T, repo estate.Repo) {
+	ctx := context.Background()
+	p := NewProperty("Mayor 12")
+	if err := repo.Create(ctx, p); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	if err := repo.Delete(ctx, p.ID, 0); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	revisions, err := repo.Revisions(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("Revisions: %v", err)
+	}
+	if len(revisions) != 2 || revisions[0].Action != estate.ActionDelete || revisions[0].Number != 2 {
+		t.Fatalf("expected delete as revision 2, got %+v", revisions)
+	}
+
+	rev, err := repo.Revision(ctx, p.ID, 2)
+	if err != nil {
+		t.Fatalf("Revision: %v", err)
+	}
+	if rev.Snapshot == nil || rev.Snapshot.Name != "Mayor 12" {
+		t.Errorf("expected snapshot of the deleted property, got %+v", rev.Snapshot)
+	}
+}
+
+func testHistoryStaleSaveNotRecorded(t *testing.T, repo estate.Repo) {
+	ctx := context.Background()
+	p := NewProperty("Mayor 12")
+	if err := repo.Create(ctx, p); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	stale := *p
+	if err := repo.Save(ctx, p); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if err := repo.Save(ctx, &stale); !errors.Is(err, estate.ErrRevisionConflict) {
+		t.Fatalf("expected ErrRevisionConflict, got %v", err)
+	}
+
+	revisions, err := repo.Revisions(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("Revisions: %v", err)
+	}
+	if len(revisions) != 2 {
+		t.Errorf("expected 2 revisions, got %+v", revisions)
+	}
+}
+
+func testHistoryRevisionMissing(t *testing.T, repo estate.Repo) {
+	ctx := context.Background()
+	p := NewProperty("Mayor 12")
+	if err := repo.Create(ctx, p); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	if _, err := repo.Revision(ctx, p.ID, 2); !errors.Is(err, estate.ErrRevisionNotFound) {
+		t.Errorf("expected ErrRevisionNotFound, got %v", err)
+	}
+
+	revisions, err := repo.Revisions(ctx, uuid.New())
+	if err != nil {
+		t.Fatalf("Revisions: %v", err)
+	}
+	if len(revisions) != 0 {
+		t.Errorf("expected no revisions for unknown property, got %+v", revisions)
+	}
+}
+
+func hasChange(changes []estate.FieldChange, path string) bool {
+	for _, c := range changes {
+		if c.Path == path {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,150 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/services/estate/internal/estate"
+)
+
+// RunPropertyImports runs the estate.ImportRepo contract. It is skipped for
+// repositories that do not store import jobs.
+func RunPropertyImports(t *testing.T, newRepo NewRepoFunc) {
+	imports := func(t *testing.T) estate.ImportRepo {
+		repo := newRepo(t)
+		ir, ok := repo.(estate.ImportRepo)
+		if !ok {
+			t.Skipf("%T does not implement estate.ImportRepo", repo)
+		}
+		return ir
+	}
+
+	t.Run("CreateAndGet", func(t *testing.T) { testImportCreateAndGet(t, imports(t)) })
+	t.Run("Save", func(t *testing.T) { testImportSave(t, imports(t)) })
+	t.Run("List", func(t *testing.T) { testImportList(t, imports(t)) })
+	t.Run("Missing", func(t *testing.T) { testImportMissing(t, imports(t)) })
+}
+
+// NewImportJob returns a queued CSV import job created at the given time.
+func NewImportJob(createdAt time.Time) *estate.ImportJob {
+	return &estate.ImportJob{
+		ID:        uuid.New(),
+		Format:    estate.ImportFormatCSV,
+		Filename:  "agency.csv",
+		DryRun:    true,
+		Mapping:   map[string]string{"titulo": "name", "ciudad": "city"},
+		Ignored:   []string{"Notes"},
+		Status:    estate.ImportQueued,
+		Total:     120,
+		CreatedAt: createdAt.UTC().Truncate(time.Millisecond),
+		CreatedBy: "tester",
+	}
+}
+
+func testImportCreateAndGet(t *testing.T, ir estate.ImportRepo) {
+	ctx := context.Background()
+	job := NewImportJob(time.Now())
+	if err := ir.CreateImport(ctx, job); err != nil {
+		t.Fatalf("CreateImport: %v", err)
+	}
+
+	got, err := ir.GetImport(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("GetImport: %v", err)
+	}
+	if !got.CreatedAt.Equal(job.CreatedAt) {
+		t.Errorf("expected created_at %v, got %v", job.CreatedAt, got.CreatedAt)
+	}
+	got.CreatedAt = job.CreatedAt
+	if !reflect.DeepEqual(got, job) {
+		t.Errorf("round trip mismatch:\nwant %+v\ngot  %+v", job, got)
+	}
+}
+
+func testImportSave(t *testing.T, ir estate.ImportRepo) {
+	ctx := context.Background()
+	job := NewImportJob(time.Now())
+	if err := ir.CreateImport(ctx, job); err != nil {
+		t.Fatalf("CreateImport: %v", err)
+	}
+
+	started := time.Now().UTC().Truncate(time.Millisecond)
+	finished := started.Add(time.Second)
+	propertyID := uuid.New()
+	job.Status, job.StartedAt, job.FinishedAt = estate.ImportCompleted, &started, &finished
+	job.Processed, job.Valid, job.Created, job.Invalid, job.Failed = 120, 118, 117, 2, 1
+	job.Errors = []estate.ImportRowResult{
+		{Row: 3, Status: estate.ImportRowInvalid, Name: "Mayor 12", Errors: []estate.ValidationError{{Field: "city", Message: "address.city is required"}}},
+		{Row: 9, Status: estate.ImportRowFailed, Name: "Sol 1", PropertyID: &propertyID, Errors: []estate.ValidationError{{Message: "could not create property"}}},
+	}
+	if err := ir.SaveImport(ctx, job); err != nil {
+		t.Fatalf("SaveImport: %v", err)
+	}
+
+	got, err := ir.GetImport(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("GetImport: %v", err)
+	}
+	if got.Status != estate.ImportCompleted || got.Processed != 120 || got.Created != 117 || got.Failed != 1 {
+		t.Errorf("unexpected counters: %+v", got)
+	}
+	if got.StartedAt == nil || !got.StartedAt.Equal(started) || got.FinishedAt == nil || !got.FinishedAt.Equal(finished) {
+		t.Errorf("unexpected times: started %v, finished %v", got.StartedAt, got.FinishedAt)
+	}
+	if !reflect.DeepEqual(got.Errors, job.Errors) {
+		t.Errorf("expected errors %+v, got %+v", job.Errors, got.Errors)
+	}
+}
+
+func testImportList(t *testing.T, ir estate.ImportRepo) {
+	ctx := context.Background()
+	base := time.Now().Add(-time.Hour)
+
+	var ids []uuid.UUID
+	for i := 0; i < 3; i++ {
+		job := NewImportJob(base.Add(time.Duration(i) * time.Minute))
+		if err := ir.CreateImport(ctx, job); err != nil {
+			t.Fatalf("CreateImport: %v", err)
+		}
+		ids = append(ids, job.ID)
+	}
+
+	all, err := ir.ListImports(ctx, 0)
+	if err != nil {
+		t.Fatalf("ListImports: %v", err)
+	}
+	if len(all) != 3 || all[0].ID != ids[2] || all[2].ID != ids[0] {
+		t.Errorf("expected the newest first, got %v", importIDs(all))
+	}
+
+	limited, err := ir.ListImports(ctx, 2)
+	if err != nil {
+		t.Fatalf("ListImports: %v", err)
+	}
+	if len(limited) != 2 || limited[0].ID != ids[2] {
+		t.Errorf("expected the two newest, got %v", importIDs(limited))
+	}
+}
+
+func testImportMissing(t *testing.T, ir estate.ImportRepo) {
+	ctx := context.Background()
+	if _, err := ir.GetImport(ctx, uuid.New()); !errors.Is(err, estate.ErrImportNotFound) {
+		t.Errorf("GetImport: expected ErrImportNotFound, got %v", err)
+	}
+	if err := ir.SaveImport(ctx, NewImportJob(time.Now())); !errors.Is(err, estate.ErrImportNotFound) {
+		t.Errorf("SaveImport: expected ErrImportNotFound, got %v", err)
+	}
+}
+
+func importIDs(jobs []estate.ImportJob) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(jobs))
+	for _, j := range jobs {
+		ids = append(ids, j.ID)
+	}
+	return ids
+}
@@ -0,0 +1,262 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/services/estate/internal/estate"
+)
+
+// RunPropertyMedia runs the estate.MediaRepo contract. It is skipped for
+// repositories that do not store media.
+func RunPropertyMedia(t *testing.T, newRepo NewRepoFunc) {
+	media := func(t *testing.T) (estate.Repo, estate.MediaRepo) {
+		repo := newRepo(t)
+		mr, ok := repo.(estate.MediaRepo)
+		if !ok {
+			t.Skipf("%T does not implement estate.MediaRepo", repo)
+		}
+		return repo, mr
+	}
+
+	t.Run("CreateAndList", func(t *testing.T) { testMediaCreateAndList(t, media) })
+	t.Run("Save", func(t *testing.T) { testMediaSave(t, media) })
+	t.Run("Arrange", func(t *testing.T) { testMediaArrange(t, media) })
+	t.Run("Delete", func(t *testing.T) { testMediaDelete(t, media) })
+	t.Run("MissingProperty", func(t *testing.T) { testMediaMissingProperty(t, media) })
+	t.Run("PurgedWithProperty", func(t *testing.T) { testMediaPurgedWithProperty(t, media) })
+}
+
+type newMediaRepoFunc func(t *testing.T) (estate.Repo, estate.MediaRepo)
+
+// NewMedia returns a photo of the property at the given position.
+func NewMedia(propertyID uuid.UUID, position int) *estate.Media {
+	return &estate.Media{
+		ID:          uuid.New(),
+		PropertyID:  propertyID,
+		Kind:        estate.MediaPhoto,
+		Filename:    "living-room.jpg",
+		ContentType: "image/jpeg",
+		Size:        2048,
+		Width:       1200,
+		Height:      800,
+		Position:    position,
+		Cover:       position == 0,
+		Captions:    map[string]string{"en": "Living room", "es": "Salón"},
+		Thumbnails: []estate.Thumbnail{
+			{Size: "small", ContentType: "image/jpeg", Width: 320, Height: 213},
+			{Size: "medium", ContentType: "image/jpeg", Width: 800, Height: 533},
+		},
+		CreatedAt: time.Now().UTC().Truncate(time.Millisecond),
+		CreatedBy: "tester",
+	}
+}
+
+func createWithMedia(t *testing.T, repo estate.Repo, mr estate.MediaRepo, count int) (*estate.Property, []*estate.Media) {
+	t.Helper()
+	ctx := context.Background()
+	p := NewProperty("Mayor 12")
+	if err := repo.Create(ctx, p); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	var items []*estate.Media
+	for i := 0; i < count; i++ {
+		m := NewMedia(p.ID, i)
+		if err := mr.CreateMedia(ctx, m); err != nil {
+			t.Fatalf("CreateMedia: %v", err)
+		}
+		items = append(items, m)
+	}
+	return p, items
+}
+
+func testMediaCreateAndList(t *testing.T, newRepo newMediaRepoFunc) {
+	ctx := context.Background()
+	repo, mr := newRepo(t)
+	p, items := createWithMedia(t, repo, mr, 2)
+
+	got, err := mr.GetMedia(ctx, p.ID, items[0].ID)
+	if err != nil {
+		t.Fatalf("GetMedia: %v", err)
+	}
+	assertSameMedia(t, items[0], got)
+
+	list, err := mr.ListMedia(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("ListMedia: %v", err)
+	}
+	if len(list) != 2 || list[0].ID != items[0].ID || list[1].ID != items[1].ID {
+		t.Fatalf("expected media by position, got %+v", list)
+	}
+
+	other, err := mr.ListMedia(ctx, uuid.New())
+	if err != nil {
+		t.Fatalf("ListMedia: %v", err)
+	}
+	if len(other) != 0 {
+		t.Errorf("expected no media for unknown property, got %+v", other)
+	}
+
+	if _, err := mr.GetMedia(ctx, uuid.New(), items[0].ID); !errors.Is(err, estate.ErrMediaNotFound) {
+		t.Errorf("expected ErrMediaNotFound for another property, got %v", err)
+	}
+}
+
+func testMediaSave(t *testing.T, newRepo newMediaRepoFunc) {
+	ctx := context.Background()
+	repo, mr := newRepo(t)
+	p, items := createWithMedia(t, repo, mr, 1)
+
+	m := items[0]
+	m.Captions = map[string]string{"pl": "Salon"}
+	if err := mr.SaveMedia(ctx, m); err != nil {
+		t.Fatalf("SaveMedia: %v", err)
+	}
+	got, err := mr.GetMedia(ctx, p.ID, m.ID)
+	if err != nil {
+		t.Fatalf("GetMedia: %v", err)
+	}
+	assertSameMedia(t, m, got)
+
+	m.Captions = nil
+	if err := mr.SaveMedia(ctx, m); err != nil {
+		t.Fatalf("SaveMedia: %v", err)
+	}
+	got, err = mr.GetMedia(ctx, p.ID, m.ID)
+	if err != nil {
+		t.Fatalf("GetMedia: %v", err)
+	}
+	if len(got.Captions) != 0 {
+		t.Errorf("expected captions to be cleared, got %v", got.Captions)
+	}
+
+	missing := NewMedia(p.ID, 1)
+	if err := mr.SaveMedia(ctx, missing); !errors.Is(err, estate.ErrMediaNotFound) {
+		t.Errorf("expected ErrMediaNotFound, got %v", err)
+	}
+}
+
+func testMediaArrange(t *testing.T, newRepo newMediaRepoFunc) {
+	ctx := context.Background()
+	repo, mr := newRepo(t)
+	p, items := createWithMedia(t, repo, mr, 3)
+
+	arranged := []estate.Media{*items[2], *items[0], *items[1]}
+	for i := range arranged {
+		arranged[i].Position = i
+		arranged[i].Cover = i == 1
+	}
+	if err := mr.ArrangeMedia(ctx, p.ID, arranged); err != nil {
+		t.Fatalf("ArrangeMedia: %v", err)
+	}
+
+	list, err := mr.ListMedia(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("ListMedia: %v", err)
+	}
+	for i, m := range list {
+		if m.ID != arranged[i].ID || m.Position != i || m.Cover != (i == 1) {
+			t.Errorf("position %d: got %s cover=%v, want %s cover=%v", i, m.ID, m.Cover, arranged[i].ID, i == 1)
+		}
+	}
+
+	unknown := []estate.Media{*NewMedia(p.ID, 0)}
+	if err := mr.ArrangeMedia(ctx, p.ID, unknown); !errors.Is(err, estate.ErrMediaNotFound) {
+		t.Errorf("expected ErrMediaNotFound, got %v", err)
+	}
+}
+
+func testMediaDelete(t *testing.T, newRepo newMediaRepoFunc) {
+	ctx := context.Background()
+	repo, mr := newRepo(t)
+	p, items := createWithMedia(t, repo, mr, 2)
+
+	if err := mr.DeleteMedia(ctx, p.ID, items[0].ID); err != nil {
+		t.Fatalf("DeleteMedia: %v", err)
+	}
+	if _, err := mr.GetMedia(ctx, p.ID, items[0].ID); !errors.Is(err, estate.ErrMediaNotFound) {
+		t.Errorf("expected ErrMediaNotFound after delete, got %v", err)
+	}
+	if err := mr.DeleteMedia(ctx, p.ID, items[0].ID); !errors.Is(err, estate.ErrMediaNotFound) {
+		t.Errorf("expected ErrMediaNotFound on second delete, got %v", err)
+	}
+
+	list, err := mr.ListMedia(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("ListMedia: %v", err)
+	}
+	if len(list) != 1 || list[0].ID != items[1].ID {
+		t.Errorf("expected the other media to remain, got %+v", list)
+	}
+}
+
+func testMediaMissingProperty(t *testing.T, newRepo newMediaRepoFunc) {
+	_, mr := newRepo(t)
+
+	if err := mr.CreateMedia(context.Background(), NewMedia(uuid.New(), 0)); !errors.Is(err, estate.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func testMediaPurgedWithProperty(t *testing.T, newRepo newMediaRepoFunc) {
+	ctx := context.Background()
+	repo, mr := newRepo(t)
+	p, _ := createWithMedia(t, repo, mr, 2)
+
+	if err := repo.Delete(ctx, p.ID, 0); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	list, err := mr.ListMedia(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("ListMedia: %v", err)
+	}
+	if len(list) != 2 {
+		t.Errorf("expected media kept in the trash, got %+v", list)
+	}
+
+	if _, err := repo.Purge(ctx, time.Now().Add(time.Second)); err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+	list, err = mr.ListMedia(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("ListMedia: %v", err)
+	}
+	if len(list) != 0 {
+		t.Errorf("expected media to be purged with the property, got %+v", list)
+	}
+}
+
+func assertSameMedia(t *testing.T, want, got *estate.Media) {
+	t.Helper()
+
+	if got.ID != want.ID || got.PropertyID != want.PropertyID || got.Kind != want.Kind ||
+		got.Filename != want.Filename || got.ContentType != want.ContentType || got.Size != want.Size ||
+		got.Width != want.Width || got.Height != want.Height || got.Position != want.Position ||
+		got.Cover != want.Cover || got.CreatedBy != want.CreatedBy {
+		t.Errorf("media mismatch:\n got  %+v\n want %+v", got, want)
+	}
+	if !got.CreatedAt.Equal(want.CreatedAt) {
+		t.Errorf("CreatedAt: got %v, want %v", got.CreatedAt, want.CreatedAt)
+	}
+	if len(got.Captions) != len(want.Captions) {
+		t.Errorf("Captions: got %v, want %v", got.Captions, want.Captions)
+	}
+	for locale, caption := range want.Captions {
+		if got.Captions[locale] != caption {
+			t.Errorf("Captions[%s]: got %q, want %q", locale, got.Captions[locale], caption)
+		}
+	}
+	if len(got.Thumbnails) != len(want.Thumbnails) {
+		t.Fatalf("Thumbnails: got %+v, want %+v", got.Thumbnails, want.Thumbnails)
+	}
+	for i := range want.Thumbnails {
+		if got.Thumbnails[i] != want.Thumbnails[i] {
+			t.Errorf("Thumbnails[%d]: got %+v, want %+v", i, got.Thumbnails[i], want.Thumbnails[i])
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/services/estate/internal/estate"
+	"github.com/pulap/pulap/services/estate/internal/money"
+)
+
+// RunPropertyPricing runs the estate.PricingRepo contract and the price
+// sorting of Search. It is skipped for repositories that do not store rates.
+func RunPropertyPricing(t *testing.T, newRepo NewRepoFunc) {
+	pricing := func(t *testing.T) (estate.Repo, estate.PricingRepo) {
+		repo := newRepo(t)
+		pr, ok := repo.(estate.PricingRepo)
+		if !ok {
+			t.Skipf("%T does not implement estate.PricingRepo", repo)
+		}
+		return repo, pr
+	}
+
+	t.Run("Rates", func(t *testing.T) { testPricingRates(t, pricing) })
+	t.Run("Valuation", func(t *testing.T) { testPricingValuation(t, pricing) })
+	t.Run("SortByPrice", func(t *testing.T) { testPricingSort(t, pricing) })
+}
+
+type newPricingRepoFunc func(t *testing.T) (estate.Repo, estate.PricingRepo)
+
+func rate(from, to, date, value string) money.Rate {
+	d, err := money.ParseDate(date)
+	if err != nil {
+		panic(err)
+	}
+	return money.Rate{From: from, To: to, Date: d, Value: money.MustParse(value)}
+}
+
+func testPricingRates(t *testing.T, newRepo newPricingRepoFunc) {
+	_, pr := newRepo(t)
+	ctx := context.Background()
+
+	err := pr.SaveRates(ctx, []money.Rate{
+		rate("EUR", "USD", "2024-01-01", "1.0950"),
+		rate("EUR", "USD", "2024-02-01", "1.0812"),
+		rate("PLN", "EUR", "2024-01-01", "0.2301"),
+	})
+	if err != nil {
+		t.Fatalf("SaveRates: %v", err)
+	}
+	if err := pr.SaveRates(ctx, []money.Rate{rate("EUR", "USD", "2024-02-01", "1.0799")}); err != nil {
+		t.Fatalf("SaveRates replace: %v", err)
+	}
+
+	rates, err := pr.ListRates(ctx)
+	if err != nil {
+		t.Fatalf("ListRates: %v", err)
+	}
+	if len(rates) != 3 {
+		t.Fatalf("expected 3 rates, got %v", rates)
+	}
+
+	found := false
+	for _, r := range rates {
+		if r.From == "EUR" && r.To == "USD" && r.Date.Format(money.DateLayout) == "2024-02-01" {
+			found = true
+			if r.Value.String() != "1.0799" {
+				t.Errorf("expected replaced rate 1.0799, got %s", r.Value)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("replaced rate missing from %v", rates)
+	}
+}
+
+func testPricingValuation(t *testing.T, newRepo newPricingRepoFunc) {
+	repo, pr := newRepo(t)
+	ctx := context.Background()
+
+	p := NewProperty("Valued")
+	p.Prices[0].Amount = money.MustParse("350000.50")
+	p.Valuation = &estate.Valuation{
+		Currency:       "USD",
+		PriceType:      "sale",
+		Amount:         money.MustParse("382995.55"),
+		PerSquareMeter: money.MustParse("4505.83"),
+		RateDate:       "2024-02-01",
+	}
+	if err := repo.Create(ctx, p); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	got, err := repo.Get(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.Prices[0].Amount.String() != "350000.50" {
+		t.Errorf("expected exact amount 350000.50, got %s", got.Prices[0].Amount)
+	}
+	if !got.Valuation.Equal(p.Valuation) {
+		t.Errorf("expected valuation %+v, got %+v", p.Valuation, got.Valuation)
+	}
+
+	if err := pr.SaveValuation(ctx, p.ID, nil); err != nil {
+		t.Fatalf("SaveValuation: %v", err)
+	}
+	got, err = repo.Get(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.Valuation != nil {
+		t.Errorf("expected valuation cleared, got %+v", got.Valuation)
+	}
+	if got.Revision != p.Revision {
+		t.Errorf("expected revision %d kept, got %d", p.Revision, got.Revision)
+	}
+
+	if err := pr.SaveValuation(ctx, uuid.New(), p.Valuation); !errors.Is(err, estate.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a missing property, got %v", err)
+	}
+}
+
+func testPricingSort(t *testing.T, newRepo newPricingRepoFunc) {
+	repo, pr := newRepo(t)
+	ctx := context.Background()
+
+	err := pr.SaveRates(ctx, []money.Rate{
+		rate("EUR", "USD", "2020-01-01", "1.10"),
+		rate("PLN", "EUR", "2020-01-01", "0.23"),
+	})
+	if err != nil {
+		t.Fatalf("SaveRates: %v", err)
+	}
+	pricing := estate.NewPricing(repo, pr, "USD")
+	if err := pricing.Start(ctx); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	priced := estate.NewPricedRepo(repo, pricing)
+
+	seed := []struct {
+		name, amount, currency string
+		area                   float64
+	}{
+		{"A", "350000", "EUR", 85},   // 385000 USD, 4529.41/m²
+		{"B", "720000", "EUR", 85},   // 792000 USD, 9317.65/m²
+		{"C", "900000", "PLN", 120},  // 227700 USD through EUR, 1897.50/m²
+		{"D", "900", "EUR", 40},      // 990 USD, 24.75/m²
+		{"E", "1122.33", "USD", 40},  // 1122.33 USD, 28.06/m²
+		{"F", "1000000", "JPY", 100}, // No rate: no valuation
+		{"G", "990", "USD", 40},      // Ties with D
+	}
+	ids := map[string]uuid.UUID{}
+	for _, s := range seed {
+		p := NewProperty(s.name)
+		p.Prices = []estate.Price{{Amount: money.MustParse(s.amount), Currency: s.currency, Type: "sale"}}
+		p.Features.TotalArea = s.area
+		if err := priced.Create(ctx, p); err != nil {
+			t.Fatalf("Create %s: %v", s.name, err)
+		}
+		ids[s.name] = p.ID
+	}
+
+	c, err := repo.Get(ctx, ids["C"])
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if c.Valuation == nil || c.Valuation.Amount.String() != "227700.00" || c.Valuation.PerSquareMeter.String() != "1897.50" {
+		t.Errorf("unexpected valuation of C: %+v", c.Valuation)
+	}
+
+	// D and G tie on price and price per m²; the id breaks the tie.
+	tie := []string{"D", "G"}
+	if ids["G"].String() < ids["D"].String() {
+		tie = []string{"G", "D"}
+	}
+
+	tests := []struct {
+		name string
+		sort []estate.SortOrder
+		want []string
+	}{
+		{"price", []estate.SortOrder{{Field: estate.SortPrice}}, append(append([]string{"F"}, tie...), "E", "C", "A", "B")},
+		{"price per m2 desc", []estate.SortOrder{{Field: estate.SortPricePerM2, Desc: true}}, append([]string{"B", "A", "C", "E"}, append(tie, "F")...)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := estate.PropertyQuery{Sort: tt.sort, Limit: 2}
+			if errs := q.Normalize(); len(errs) > 0 {
+				t.Fatalf("Normalize: %v", errs)
+			}
+
+			var got []string
+			for pages := 0; ; pages++ {
+				if pages > len(tt.want) {
+					t.Fatal("pagination did not terminate")
+				}
+				page, err := repo.Search(ctx, q)
+				if err != nil {
+					t.Fatalf("Search: %v", err)
+				}
+				got = append(got, names(page.Items)...)
+				if page.NextCursor == "" {
+					break
+				}
+				q.Cursor = page.NextCursor
+			}
+
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	// New rates revalue stored properties without new revisions.
+	result, err := pricing.ImportRates(ctx, []money.Rate{rate("JPY", "USD", time.Now().UTC().Format(money.DateLayout), "0.0067")})
+	if err != nil {
+		t.Fatalf("ImportRates: %v", err)
+	}
+	if result.Revalued != 1 || result.Unconverted != 0 {
+		t.Errorf("expected only F revalued, got %+v", result)
+	}
+	f, err := repo.Get(ctx, ids["F"])
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if f.Valuation == nil || f.Valuation.Amount.String() != "6700.00" || f.Revision != 1 {
+		t.Errorf("unexpected valuation of F: %+v (revision %d)", f.Valuation, f.Revision)
+	}
+}
@@ -0,0 +1,308 @@
+// Package repotest provides backend-agnostic contract tests for estate repositories.
+// Each repository implementation runs the same suite from its own _test.go file.
+package repotest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/services/estate/internal/estate"
+	"github.com/pulap/pulap/services/estate/internal/money"
+)
+
+// NewRepoFunc returns an empty, ready-to-use repository for a single subtest.
+type NewRepoFunc func(t *testing.T) estate.Repo
+
+// RunPropertyRepo runs the estate.Repo contract against the repository returned by newRepo.
+func RunPropertyRepo(t *testing.T, newRepo NewRepoFunc) {
+	t.Run("CreateAndGet", func(t *testing.T) { testCreateAndGet(t, newRepo(t)) })
+	t.Run("CreateNil", func(t *testing.T) { testCreateNil(t, newRepo(t)) })
+	t.Run("GetMissing", func(t *testing.T) { testGetMissing(t, newRepo(t)) })
+	t.Run("Save", func(t *testing.T) { testSave(t, newRepo(t)) })
+	t.Run("SaveKeepsCreation", func(t *testing.T) { testSaveKeepsCreation(t, newRepo(t)) })
+	t.Run("SaveMissing", func(t *testing.T) { testSaveMissing(t, newRepo(t)) })
+	t.Run("Delete", func(t *testing.T) { testDelete(t, newRepo(t)) })
+	t.Run("List", func(t *testing.T) { testList(t, newRepo(t)) })
+	t.Run("Search", func(t *testing.T) { RunPropertySearch(t, newRepo) })
+	t.Run("Geo", func(t *testing.T) { RunPropertyGeo(t, newRepo) })
+	t.Run("Status", func(t *testing.T) { RunPropertyStatus(t, newRepo) })
+	t.Run("Revision", func(t *testing.T) { RunPropertyRevision(t, newRepo) })
+	t.Run("History", func(t *testing.T) { RunPropertyHistory(t, newRepo) })
+	t.Run("Trash", func(t *testing.T) { RunPropertyTrash(t, newRepo) })
+	t.Run("Media", func(t *testing.T) { RunPropertyMedia(t, newRepo) })
+	t.Run("Pricing", func(t *testing.T) { RunPropertyPricing(t, newRepo) })
+	t.Run("Imports", func(t *testing.T) { RunPropertyImports(t, newRepo) })
+	t.Run("Events", func(t *testing.T) { RunPropertyEvents(t, newRepo) })
+}
+
+// NewProperty returns a fully populated, valid Property.
+func NewProperty(name string) *estate.Property {
+	p := estate.New()
+	p.Name = name
+	p.Description = "Bright apartment close to the park"
+	p.Classification = estate.Classification{
+		CategoryID: uuid.MustParse("00000000-0000-0000-0001-000000000001"),
+		TypeID:     uuid.MustParse("00000000-0000-0000-0002-000000000002"),
+		SubtypeID:  uuid.MustParse("00000000-0000-0000-0003-000000000002"),
+	}
+	p.Location = estate.Location{
+		Address: estate.Address{
+			Street:     "Calle Mayor",
+			Number:     "12",
+			Unit:       "3B",
+			City:       "Madrid",
+			State:      "Madrid",
+			PostalCode: "28013",
+			Country:    "ES",
+		},
+		Coordinates: estate.Coordinates{Latitude: 40.4168, Longitude: -3.7038},
+		Region:      "EUROPE",
+		Provider:    "osm",
+		ProviderURL: "https://nominatim.openstreetmap.org",
+		ProviderRef: "node/123",
+		Raw:         map[string]any{"osm_type": "node", "class": "place"},
+		DisplayName: "Calle Mayor 12, Madrid",
+	}
+	p.Features = estate.Features{
+		TotalArea:       85,
+		CoveredArea:     80,
+		Bedrooms:        2,
+		Bathrooms:       1,
+		Rooms:           4,
+		Parking:         1,
+		CoveredParking:  1,
+		Floors:          1,
+		Floor:           3,
+		YearBuilt:       1998,
+		Condition:       "good",
+		Balcony:         true,
+		Elevator:        true,
+		AirConditioning: true,
+		Amenities:       []string{"gym", "concierge"},
+	}
+	p.Prices = []estate.Price{
+		{Amount: money.FromInt(350000), Currency: "EUR", Type: "sale", Negotiable: true},
+		{Amount: money.FromInt(1400), Currency: "EUR", Type: "rent_monthly"},
+	}
+	p.OwnerID = "owner-1"
+	p.CreatedBy = "tester"
+	p.UpdatedBy = "tester"
+	return p
+}
+
+// AssertSameProperty fails the test if got differs from want in any persisted field.
+// Timestamps are compared with a tolerance because backends may lose precision.
+func AssertSameProperty(t *testing.T, want, got *estate.Property) {
+	t.Helper()
+
+	if got == nil {
+		t.Fatal("expected property, got nil")
+	}
+
+	w, g := *want, *got
+	if !sameTime(w.CreatedAt, g.CreatedAt) || !sameTime(w.UpdatedAt, g.UpdatedAt) {
+		t.Errorf("timestamps differ: want created=%v updated=%v, got created=%v updated=%v",
+			w.CreatedAt, w.UpdatedAt, g.CreatedAt, g.UpdatedAt)
+	}
+	w.CreatedAt, w.UpdatedAt = time.Time{}, time.Time{}
+	g.CreatedAt, g.UpdatedAt = time.Time{}, time.Time{}
+
+	if !reflect.DeepEqual(w, g) {
+		t.Errorf("property mismatch:\nwant %+v\ngot  %+v", w, g)
+	}
+}
+
+func sameTime(a, b time.Time) bool {
+	d := a.Sub(b)
+	if d < 0 {
+		d = -d
+	}
+	return d < time.Millisecond
+}
+
+func testCreateAndGet(t *testing.T, repo estate.Repo) {
+	ctx := context.Background()
+	p := NewProperty("Mayor 12")
+
+	if err := repo.Create(ctx, p); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if p.CreatedAt.IsZero() || p.UpdatedAt.IsZero() {
+		t.Error("expected Create to set timestamps")
+	}
+
+	got, err := repo.Get(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	AssertSameProperty(t, p, got)
+}
+
+func testCreateNil(t *testing.T, repo estate.Repo) {
+	if err := repo.Create(context.Background(), nil); err == nil {
+		t.Error("expected error creating nil property")
+	}
+}
+
+func testGetMissing(t *testing.T, repo estate.Repo) {
+	_, err := repo.Get(context.Background(), uuid.New())
+	if !errors.Is(err, estate.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func testSave(t *testing.T, repo estate.Repo) {
+	ctx := context.Background()
+	p := NewProperty("Mayor 12")
+	if err := repo.Create(ctx, p); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	p.Name = "Mayor 12 (renovated)"
+	p.Status = "reserved"
+	p.Features.Condition = "excellent"
+	p.Features.Amenities = []string{"pool"}
+	p.Prices = []estate.Price{{Amount: money.FromInt(390000), Currency: "EUR", Type: "sale"}}
+	p.Location.Raw = nil
+	p.UpdatedBy = "editor"
+
+	if err := repo.Save(ctx, p); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	got, err := repo.Get(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	AssertSameProperty(t, p, got)
+}
+
+func testSaveKeepsCreation(t *testing.T, repo estate.Repo) {
+	ctx := context.Background()
+	p := NewProperty("Mayor 12")
+	p.CreatedBy = "creator"
+	if err := repo.Create(ctx, p); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	created, err := repo.Get(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+
+	p.CreatedAt = time.Now().Add(time.Hour)
+	p.CreatedBy = "editor"
+	if err := repo.Save(ctx, p); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	got, err := repo.Get(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if !sameTime(got.CreatedAt, created.CreatedAt) || got.CreatedBy != "creator" {
+		t.Errorf("expected creation kept at %v by creator, got %v by %q", created.CreatedAt, got.CreatedAt, got.CreatedBy)
+	}
+}
+
+func testSaveMissing(t *testing.T, repo estate.Repo) {
+	err := repo.Save(context.Background(), NewProperty("ghost"))
+	if !errors.Is(err, estate.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func testDelete(t *testing.T, repo estate.Repo) {
+	ctx := context.Background()
+	p := NewProperty("Mayor 12")
+	if err := repo.Create(ctx, p); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	if err := repo.Delete(ctx, p.ID, 0); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := repo.Get(ctx, p.ID); !errors.Is(err, estate.ErrNotFound) {
+		t.Errorf("expected ErrNotFound after delete, got %v", err)
+	}
+	if err := repo.Delete(ctx, p.ID, 0); !errors.Is(err, estate.ErrNotFound) {
+		t.Errorf("expected ErrNotFound deleting twice, got %v", err)
+	}
+}
+
+func testList(t *testing.T, repo estate.Repo) {
+	ctx := context.Background()
+
+	a := NewProperty("A")
+	b := NewProperty("B")
+	b.OwnerID = "owner-2"
+	b.Status = "sold"
+	c := NewProperty("C")
+	c.Status = "sold"
+
+	for _, p := range []*estate.Property{a, b, c} {
+		if err := repo.Create(ctx, p); err != nil {
+			t.Fatalf("Create %s: %v", p.Name, err)
+		}
+	}
+
+	all, err := repo.List(ctx)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(all) != 3 {
+		t.Errorf("expected 3 properties, got %d", len(all))
+	}
+	for _, p := range all {
+		if len(p.Prices) != 2 || len(p.Features.Amenities) != 2 {
+			t.Errorf("expected children loaded for %s, got prices=%v amenities=%v", p.Name, p.Prices, p.Features.Amenities)
+		}
+	}
+
+	byOwner, err := repo.ListByOwner(ctx, "owner-1")
+	if err != nil {
+		t.Fatalf("ListByOwner: %v", err)
+	}
+	if names := names(byOwner); !sameSet(names, []string{"A", "C"}) {
+		t.Errorf("ListByOwner: expected [A C], got %v", names)
+	}
+
+	byStatus, err := repo.ListByStatus(ctx, "sold")
+	if err != nil {
+		t.Fatalf("ListByStatus: %v", err)
+	}
+	if names := names(byStatus); !sameSet(names, []string{"B", "C"}) {
+		t.Errorf("ListByStatus: expected [B C], got %v", names)
+	}
+}
+
+func names(properties []*estate.Property) []string {
+	var out []string
+	for _, p := range properties {
+		out = append(out, p.Name)
+	}
+	return out
+}
+
+func sameSet(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	seen := make(map[string]int)
+	for _, s := range a {
+		seen[s]++
+	}
+	for _, s := range b {
+		seen[s]--
+	}
+	for _, n := range seen {
+		if n != 0 {
+			return false
+		}
+	}
+	return true
+}
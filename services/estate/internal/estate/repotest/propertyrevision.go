@@ -0,0 +1,138 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/pulap/pulap/services/estate/internal/estate"
+)
+
+// RunPropertyRevision runs the optimistic concurrency contract.
+func RunPropertyRevision(t *testing.T, newRepo NewRepoFunc) {
+	t.Run("SaveIncrementsRevision", func(t *testing.T) { testSaveIncrementsRevision(t, newRepo(t)) })
+	t.Run("StaleSaveConflicts", func(t *testing.T) { testStaleSaveConflicts(t, newRepo(t)) })
+	t.Run("StaleDeleteConflicts", func(t *testing.T) { testStaleDeleteConflicts(t, newRepo(t)) })
+	t.Run("TransitionIncrementsRevision", func(t *testing.T) { testTransitionIncrementsRevision(t, newRepo(t)) })
+}
+
+func testSaveIncrementsRevision(t *testing.T, repo estate.Repo) {
+	ctx := context.Background()
+	p := NewProperty("Mayor 12")
+	if err := repo.Create(ctx, p); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if p.Revision != 1 {
+		t.Fatalf("expected revision 1 after create, got %d", p.Revision)
+	}
+
+	for want := int64(2); want <= 3; want++ {
+		if err := repo.Save(ctx, p); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+		if p.Revision != want {
+			t.Errorf("expected revision %d after save, got %d", want, p.Revision)
+		}
+	}
+
+	got, err := repo.Get(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.Revision != 3 {
+		t.Errorf("expected stored revision 3, got %d", got.Revision)
+	}
+}
+
+func testStaleSaveConflicts(t *testing.T, repo estate.Repo) {
+	ctx := context.Background()
+	p := NewProperty("Mayor 12")
+	if err := repo.Create(ctx, p); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	first, err := repo.Get(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	second, err := repo.Get(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+
+	first.Name = "First editor"
+	if err := repo.Save(ctx, first); err != nil {
+		t.Fatalf("Save first: %v", err)
+	}
+
+	second.Name = "Second editor"
+	if err := repo.Save(ctx, second); !errors.Is(err, estate.ErrRevisionConflict) {
+		t.Fatalf("expected ErrRevisionConflict, got %v", err)
+	}
+	if second.Revision != 1 {
+		t.Errorf("expected failed save to keep revision 1, got %d", second.Revision)
+	}
+
+	got, err := repo.Get(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.Name != "First editor" || got.Revision != 2 {
+		t.Errorf("expected first editor at revision 2, got %q at %d", got.Name, got.Revision)
+	}
+}
+
+func testStaleDeleteConflicts(t *testing.T, repo estate.Repo) {
+	ctx := context.Background()
+	p := NewProperty("Mayor 12")
+	if err := repo.Create(ctx, p); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if err := repo.Save(ctx, p); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	if err := repo.Delete(ctx, p.ID, 1); !errors.Is(err, estate.ErrRevisionConflict) {
+		t.Fatalf("expected ErrRevisionConflict, got %v", err)
+	}
+	if _, err := repo.Get(ctx, p.ID); err != nil {
+		t.Fatalf("expected property to survive stale delete: %v", err)
+	}
+
+	if err := repo.Delete(ctx, p.ID, p.Revision); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := repo.Delete(ctx, p.ID, p.Revision); !errors.Is(err, estate.ErrNotFound) {
+		t.Errorf("expected ErrNotFound deleting twice, got %v", err)
+	}
+}
+
+func testTransitionIncrementsRevision(t *testing.T, repo estate.Repo) {
+	ctx := context.Background()
+	p := NewProperty("Mayor 12")
+	if err := repo.Create(ctx, p); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	tr, err := p.Transition(estate.StatusReserved, "deposit received", "agent-1", false)
+	if err != nil {
+		t.Fatalf("Transition: %v", err)
+	}
+	if err := repo.Transition(ctx, tr); err != nil {
+		t.Fatalf("repo.Transition: %v", err)
+	}
+
+	got, err := repo.Get(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.Revision != 2 {
+		t.Errorf("expected revision 2 after transition, got %d", got.Revision)
+	}
+
+	// A save based on the pre-transition revision must not undo the status change.
+	p.Revision = 1
+	if err := repo.Save(ctx, p); !errors.Is(err, estate.ErrRevisionConflict) {
+		t.Errorf("expected ErrRevisionConflict, got %v", err)
+	}
+}
@@ -0,0 +1,180 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/services/estate/internal/estate"
+	"github.com/pulap/pulap/services/estate/internal/money"
+)
+
+// RunPropertySearch runs the estate.Repo Search contract against the repository returned by newRepo.
+func RunPropertySearch(t *testing.T, newRepo NewRepoFunc) {
+	t.Run("Filters", func(t *testing.T) { testSearchFilters(t, newRepo(t)) })
+	t.Run("SortAndPaginate", func(t *testing.T) { testSearchSortAndPaginate(t, newRepo(t)) })
+}
+
+// seedSearch stores a small, varied catalogue and returns it by name.
+func seedSearch(t *testing.T, repo estate.Repo) map[string]*estate.Property {
+	t.Helper()
+	ctx := context.Background()
+
+	house := uuid.MustParse("00000000-0000-0000-0002-000000000001")
+	tower := uuid.MustParse("00000000-0000-0000-0004-000000000001")
+
+	a := NewProperty("A")
+
+	b := NewProperty("B")
+	b.Status = "sold"
+	b.OwnerID = "owner-2"
+	b.Features.Bedrooms = 4
+	b.Features.Pool = true
+	b.Features.Amenities = []string{"gym"}
+	b.Features.YearBuilt = 2015
+	b.Prices = []estate.Price{{Amount: money.FromInt(720000), Currency: "EUR", Type: "sale"}}
+
+	c := NewProperty("C")
+	c.Classification.TypeID = house
+	c.TeamID = "team-1"
+	c.Location.Address.City = "Kraków"
+	c.Location.Address.Country = "PL"
+	c.Features.Bedrooms = 3
+	c.Features.Bathrooms = 2
+	c.Features.TotalArea = 120
+	c.Features.Pool = true
+	c.Prices = []estate.Price{{Amount: money.FromInt(900000), Currency: "PLN", Type: "sale"}, {Amount: money.FromInt(4000), Currency: "PLN", Type: "rent_monthly"}}
+
+	d := NewProperty("D")
+	d.Features.Bedrooms = 1
+	d.Features.TotalArea = 40
+	d.Features.CoveredArea = 40
+	d.DevelopmentID = &tower
+	d.Prices = []estate.Price{{Amount: money.FromInt(900), Currency: "EUR", Type: "rent_monthly"}}
+
+	out := map[string]*estate.Property{}
+	for _, p := range []*estate.Property{a, b, c, d} {
+		if err := repo.Create(ctx, p); err != nil {
+			t.Fatalf("Create %s: %v", p.Name, err)
+		}
+		out[p.Name] = p
+		// Keep created_at distinct for backends with millisecond precision.
+		time.Sleep(2 * time.Millisecond)
+	}
+	return out
+}
+
+func testSearchFilters(t *testing.T, repo estate.Repo) {
+	seeded := seedSearch(t, repo)
+
+	intp := func(n int) *int { return &n }
+	floatp := func(f float64) *float64 { return &f }
+
+	tests := []struct {
+		name  string
+		query estate.PropertyQuery
+		want  []string
+	}{
+		{"no filters", estate.PropertyQuery{}, []string{"A", "B", "C", "D"}},
+		{"ids", estate.PropertyQuery{IDs: []uuid.UUID{seeded["B"].ID, seeded["D"].ID, uuid.New()}}, []string{"B", "D"}},
+		{"owner", estate.PropertyQuery{OwnerID: "owner-2"}, []string{"B"}},
+		{"team", estate.PropertyQuery{TeamID: "team-1"}, []string{"C"}},
+		{"scoped to owner or team", estate.PropertyQuery{Scoped: true, Scopes: []string{"owner-2", "team-1"}}, []string{"B", "C"}},
+		{"scoped to nothing", estate.PropertyQuery{Scoped: true}, nil},
+		{"scoped and filtered", estate.PropertyQuery{Scoped: true, Scopes: []string{"owner-2", "team-1"}, Flags: []string{"pool"}, Statuses: []string{"sold"}}, []string{"B"}},
+		{"statuses", estate.PropertyQuery{Statuses: []string{"sold", "reserved"}}, []string{"B"}},
+		{"type", estate.PropertyQuery{TypeIDs: []uuid.UUID{uuid.MustParse("00000000-0000-0000-0002-000000000001")}}, []string{"C"}},
+		{"development", estate.PropertyQuery{DevelopmentIDs: []uuid.UUID{uuid.MustParse("00000000-0000-0000-0004-000000000001")}}, []string{"D"}},
+		{"city case-insensitive", estate.PropertyQuery{City: "KRAKÓW"}, []string{"C"}},
+		{"country", estate.PropertyQuery{Country: "es"}, []string{"A", "B", "D"}},
+		{"price type and currency", estate.PropertyQuery{Price: &estate.PriceFilter{Type: "sale", Currency: "EUR"}}, []string{"A", "B"}},
+		{"price range", estate.PropertyQuery{Price: &estate.PriceFilter{Type: "sale", Currency: "EUR", Min: floatp(400000)}}, []string{"B"}},
+		{"price range on one element", estate.PropertyQuery{Price: &estate.PriceFilter{Currency: "PLN", Type: "sale", Max: floatp(5000)}}, nil},
+		{"rent", estate.PropertyQuery{Price: &estate.PriceFilter{Type: "rent_monthly", Max: floatp(1500)}}, []string{"A", "D"}},
+		{"bedrooms range", estate.PropertyQuery{Bedrooms: estate.IntRange{Min: intp(2), Max: intp(3)}}, []string{"A", "C"}},
+		{"bathrooms", estate.PropertyQuery{Bathrooms: estate.IntRange{Min: intp(2)}}, []string{"C"}},
+		{"total area", estate.PropertyQuery{TotalArea: estate.FloatRange{Max: floatp(85)}}, []string{"A", "B", "D"}},
+		{"year built", estate.PropertyQuery{YearBuilt: estate.IntRange{Min: intp(2000)}}, []string{"B"}},
+		{"flags", estate.PropertyQuery{Flags: []string{"pool", "elevator"}}, []string{"B", "C"}},
+		{"amenities all", estate.PropertyQuery{Amenities: []string{"gym", "concierge"}}, []string{"A", "C", "D"}},
+		{"combined", estate.PropertyQuery{Flags: []string{"pool"}, Country: "ES", Statuses: []string{"sold"}}, []string{"B"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := tt.query
+			if errs := q.Normalize(); len(errs) > 0 {
+				t.Fatalf("Normalize: %v", errs)
+			}
+
+			page, err := repo.Search(context.Background(), q)
+			if err != nil {
+				t.Fatalf("Search: %v", err)
+			}
+
+			if got := names(page.Items); !sameSet(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+			if page.Total != int64(len(tt.want)) {
+				t.Errorf("expected total %d, got %d", len(tt.want), page.Total)
+			}
+		})
+	}
+}
+
+func testSearchSortAndPaginate(t *testing.T, repo estate.Repo) {
+	seedSearch(t, repo)
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		sort []estate.SortOrder
+		want []string
+	}{
+		{"default newest first", nil, []string{"D", "C", "B", "A"}},
+		{"bedrooms desc then name", []estate.SortOrder{{Field: estate.SortBedrooms, Desc: true}, {Field: estate.SortName}}, []string{"B", "C", "A", "D"}},
+		{"total area then created desc", []estate.SortOrder{{Field: estate.SortTotalArea}, {Field: estate.SortCreatedAt, Desc: true}}, []string{"D", "B", "A", "C"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := estate.PropertyQuery{Sort: tt.sort, Limit: 3}
+			if errs := q.Normalize(); len(errs) > 0 {
+				t.Fatalf("Normalize: %v", errs)
+			}
+
+			var got []string
+			for pages := 0; ; pages++ {
+				if pages > len(tt.want) {
+					t.Fatal("pagination did not terminate")
+				}
+
+				page, err := repo.Search(ctx, q)
+				if err != nil {
+					t.Fatalf("Search: %v", err)
+				}
+				if page.Total != int64(len(tt.want)) {
+					t.Errorf("expected total %d, got %d", len(tt.want), page.Total)
+				}
+				got = append(got, names(page.Items)...)
+
+				if page.NextCursor == "" {
+					break
+				}
+				q.Cursor = page.NextCursor
+				q.Limit = 2
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("expected %v, got %v", tt.want, got)
+				}
+			}
+		})
+	}
+}
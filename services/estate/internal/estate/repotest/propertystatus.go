@@ -0,0 +1,136 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pulap/pulap/services/estate/internal/estate"
+)
+
+// RunPropertyStatus runs the status transition contract.
+func RunPropertyStatus(t *testing.T, newRepo NewRepoFunc) {
+	t.Run("TransitionAndHistory", func(t *testing.T) { testTransitionAndHistory(t, newRepo(t)) })
+	t.Run("TransitionConflict", func(t *testing.T) { testTransitionConflict(t, newRepo(t)) })
+	t.Run("TransitionMissing", func(t *testing.T) { testTransitionMissing(t, newRepo(t)) })
+	t.Run("HistoryPurgedWithProperty", func(t *testing.T) { testHistoryPurgedWithProperty(t, newRepo(t)) })
+}
+
+func testTransitionAndHistory(t *testing.T, repo estate.Repo) {
+	ctx := context.Background()
+	p := NewProperty("Mayor 12")
+	if err := repo.Create(ctx, p); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	steps := []struct{ to, reason string }{
+		{estate.StatusReserved, "deposit received"},
+		{estate.StatusSold, "deed signed"},
+	}
+	for _, step := range steps {
+		tr, err := p.Transition(step.to, step.reason, "agent-1", false)
+		if err != nil {
+			t.Fatalf("Transition to %s: %v", step.to, err)
+		}
+		if err := repo.Transition(ctx, tr); err != nil {
+			t.Fatalf("repo.Transition to %s: %v", step.to, err)
+		}
+	}
+
+	got, err := repo.Get(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.Status != estate.StatusSold || got.UpdatedBy != "agent-1" {
+		t.Errorf("expected sold by agent-1, got %s by %s", got.Status, got.UpdatedBy)
+	}
+
+	history, err := repo.StatusHistory(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("StatusHistory: %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("expected 2 transitions, got %d", len(history))
+	}
+	first := history[0]
+	if first.From != estate.StatusAvailable || first.To != estate.StatusReserved || first.Reason != "deposit received" || first.Actor != "agent-1" || first.PropertyID != p.ID {
+		t.Errorf("unexpected first transition: %+v", first)
+	}
+	if history[1].From != estate.StatusReserved || history[1].To != estate.StatusSold {
+		t.Errorf("unexpected second transition: %+v", history[1])
+	}
+	if history[1].At.Before(first.At) {
+		t.Errorf("history is not in chronological order")
+	}
+}
+
+func testTransitionConflict(t *testing.T, repo estate.Repo) {
+	ctx := context.Background()
+	p := NewProperty("Mayor 12")
+	if err := repo.Create(ctx, p); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	// Two clients load the available property and transition it concurrently.
+	stale := *p
+	tr, _ := p.Transition(estate.StatusReserved, "first", "agent-1", false)
+	if err := repo.Transition(ctx, tr); err != nil {
+		t.Fatalf("Transition: %v", err)
+	}
+
+	tr, _ = stale.Transition(estate.StatusRented, "second", "agent-2", false)
+	if err := repo.Transition(ctx, tr); !errors.Is(err, estate.ErrStatusConflict) {
+		t.Fatalf("expected ErrStatusConflict, got %v", err)
+	}
+
+	history, err := repo.StatusHistory(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("StatusHistory: %v", err)
+	}
+	if len(history) != 1 {
+		t.Errorf("expected the rejected transition not to be recorded, got %d entries", len(history))
+	}
+}
+
+func testTransitionMissing(t *testing.T, repo estate.Repo) {
+	p := NewProperty("ghost")
+	tr, _ := p.Transition(estate.StatusReserved, "ghost", "agent-1", false)
+	if err := repo.Transition(context.Background(), tr); !errors.Is(err, estate.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func testHistoryPurgedWithProperty(t *testing.T, repo estate.Repo) {
+	ctx := context.Background()
+	p := NewProperty("Mayor 12")
+	if err := repo.Create(ctx, p); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	tr, _ := p.Transition(estate.StatusInactive, "withdrawn", "agent-1", false)
+	if err := repo.Transition(ctx, tr); err != nil {
+		t.Fatalf("Transition: %v", err)
+	}
+
+	if err := repo.Delete(ctx, p.ID, 0); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	history, err := repo.StatusHistory(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("StatusHistory: %v", err)
+	}
+	if len(history) != 1 {
+		t.Errorf("expected history kept in the trash, got %d entries", len(history))
+	}
+
+	if _, err := repo.Purge(ctx, time.Now().Add(time.Second)); err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+	history, err = repo.StatusHistory(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("StatusHistory: %v", err)
+	}
+	if len(history) != 0 {
+		t.Errorf("expected no history after purge, got %d entries", len(history))
+	}
+}
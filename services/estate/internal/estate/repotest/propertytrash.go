@@ -0,0 +1,247 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/services/estate/internal/estate"
+)
+
+// RunPropertyTrash runs the soft delete contract: Delete, Undelete, Purge and
+// trash searches.
+func RunPropertyTrash(t *testing.T, newRepo NewRepoFunc) {
+	t.Run("DeleteHidesProperty", func(t *testing.T) { testTrashDeleteHidesProperty(t, newRepo(t)) })
+	t.Run("Search", func(t *testing.T) { testTrashSearch(t, newRepo(t)) })
+	t.Run("Undelete", func(t *testing.T) { testTrashUndelete(t, newRepo(t)) })
+	t.Run("UndeleteConflict", func(t *testing.T) { testTrashUndeleteConflict(t, newRepo(t)) })
+	t.Run("Purge", func(t *testing.T) { testTrashPurge(t, newRepo(t)) })
+}
+
+func createTrashed(t *testing.T, repo estate.Repo, names ...string) []*estate.Property {
+	t.Helper()
+	ctx := estate.WithActor(context.Background(), "admin")
+
+	var properties []*estate.Property
+	for _, name := range names {
+		p := NewProperty(name)
+		if err := repo.Create(ctx, p); err != nil {
+			t.Fatalf("Create %s: %v", name, err)
+		}
+		if err := repo.Delete(ctx, p.ID, 0); err != nil {
+			t.Fatalf("Delete %s: %v", name, err)
+		}
+		properties = append(properties, p)
+		// Keeps the deletion times apart for backends with millisecond precision.
+		time.Sleep(2 * time.Millisecond)
+	}
+	return properties
+}
+
+func trashSearch(t *testing.T, repo estate.Repo, query estate.PropertyQuery) *estate.PropertyPage {
+	t.Helper()
+	query.Deleted = true
+	if errs := query.Normalize(); len(errs) > 0 {
+		t.Fatalf("Normalize: %v", errs)
+	}
+	page, err := repo.Search(context.Background(), query)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	return page
+}
+
+func testTrashDeleteHidesProperty(t *testing.T, repo estate.Repo) {
+	ctx := context.Background()
+	live := NewProperty("Live")
+	if err := repo.Create(ctx, live); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	createTrashed(t, repo, "Deleted")
+
+	all, err := repo.List(ctx)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	byOwner, err := repo.ListByOwner(ctx, "owner-1")
+	if err != nil {
+		t.Fatalf("ListByOwner: %v", err)
+	}
+	byStatus, err := repo.ListByStatus(ctx, estate.StatusAvailable)
+	if err != nil {
+		t.Fatalf("ListByStatus: %v", err)
+	}
+	for name, list := range map[string][]*estate.Property{"List": all, "ListByOwner": byOwner, "ListByStatus": byStatus} {
+		if got := names(list); !sameSet(got, []string{"Live"}) {
+			t.Errorf("%s: expected [Live], got %v", name, got)
+		}
+	}
+
+	query := estate.PropertyQuery{}
+	query.Normalize()
+	page, err := repo.Search(ctx, query)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if got := names(page.Items); page.Total != 1 || !sameSet(got, []string{"Live"}) {
+		t.Errorf("Search: expected [Live], got %v (total %d)", got, page.Total)
+	}
+
+	geo := estate.GeoQuery{Shape: estate.GeoRadius, Center: estate.GeoPoint{Lat: 40.4168, Lng: -3.7038}, RadiusMeters: 1000}
+	geo.Normalize()
+	results, err := repo.SearchGeo(ctx, geo)
+	if err != nil {
+		t.Fatalf("SearchGeo: %v", err)
+	}
+	if len(results) != 1 || results[0].Property.Name != "Live" {
+		t.Errorf("SearchGeo: expected only Live, got %d results", len(results))
+	}
+}
+
+func testTrashSearch(t *testing.T, repo estate.Repo) {
+	ctx := context.Background()
+	if err := repo.Create(ctx, NewProperty("Live")); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	deleted := createTrashed(t, repo, "A", "B", "C")
+
+	page := trashSearch(t, repo, estate.PropertyQuery{Limit: 2})
+	if got := names(page.Items); page.Total != 3 || len(got) != 2 || got[0] != "C" || got[1] != "B" {
+		t.Fatalf("expected [C B] of 3, most recently deleted first, got %v of %d", got, page.Total)
+	}
+	p := page.Items[0]
+	if p.DeletedAt == nil || p.DeletedBy != "admin" || p.Revision != 2 {
+		t.Errorf("expected deleted by admin at revision 2, got at=%v by=%q revision=%d", p.DeletedAt, p.DeletedBy, p.Revision)
+	}
+
+	page = trashSearch(t, repo, estate.PropertyQuery{Limit: 2, Cursor: page.NextCursor})
+	if got := names(page.Items); len(got) != 1 || got[0] != "A" || page.NextCursor != "" {
+		t.Errorf("expected last page [A], got %v (next %q)", got, page.NextCursor)
+	}
+
+	page = trashSearch(t, repo, estate.PropertyQuery{IDs: []uuid.UUID{deleted[1].ID}})
+	if got := names(page.Items); len(got) != 1 || got[0] != "B" {
+		t.Errorf("expected [B] by ID, got %v", got)
+	}
+}
+
+func testTrashUndelete(t *testing.T, repo estate.Repo) {
+	ctx := estate.WithActor(context.Background(), "restorer")
+	p := createTrashed(t, repo, "Mayor 12")[0]
+
+	if err := repo.Undelete(ctx, p.ID, 0); err != nil {
+		t.Fatalf("Undelete: %v", err)
+	}
+
+	got, err := repo.Get(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.DeletedAt != nil || got.DeletedBy != "" || got.Revision != 3 || got.UpdatedBy != "restorer" {
+		t.Errorf("expected restored at revision 3 by restorer, got deleted=%v by=%q revision=%d updated_by=%q",
+			got.DeletedAt, got.DeletedBy, got.Revision, got.UpdatedBy)
+	}
+	if page := trashSearch(t, repo, estate.PropertyQuery{}); len(page.Items) != 0 {
+		t.Errorf("expected empty trash, got %v", names(page.Items))
+	}
+
+	revisions, err := repo.Revisions(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("Revisions: %v", err)
+	}
+	if len(revisions) != 3 || revisions[0].Action != estate.ActionUndelete || revisions[0].Actor != "restorer" {
+		t.Fatalf("expected undelete as revision 3, got %+v", revisions)
+	}
+	if revisions[1].Action != estate.ActionDelete || revisions[1].Actor != "admin" {
+		t.Errorf("expected delete by admin as revision 2, got %+v", revisions[1])
+	}
+
+	if err := repo.Undelete(ctx, p.ID, 0); !errors.Is(err, estate.ErrNotFound) {
+		t.Errorf("expected ErrNotFound restoring a live property, got %v", err)
+	}
+	if err := repo.Undelete(ctx, uuid.New(), 0); !errors.Is(err, estate.ErrNotFound) {
+		t.Errorf("expected ErrNotFound restoring a missing property, got %v", err)
+	}
+}
+
+func testTrashUndeleteConflict(t *testing.T, repo estate.Repo) {
+	ctx := context.Background()
+	p := createTrashed(t, repo, "Mayor 12")[0]
+
+	if err := repo.Undelete(ctx, p.ID, 1); !errors.Is(err, estate.ErrRevisionConflict) {
+		t.Errorf("expected ErrRevisionConflict, got %v", err)
+	}
+	if err := repo.Undelete(ctx, p.ID, 2); err != nil {
+		t.Errorf("Undelete at revision 2: %v", err)
+	}
+}
+
+func testTrashPurge(t *testing.T, repo estate.Repo) {
+	ctx := context.Background()
+	deleted := createTrashed(t, repo, "A", "B")
+
+	purged, err := repo.Purge(ctx, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+	if len(purged) != 0 {
+		t.Errorf("expected nothing purged before the deletions, got %v", purged)
+	}
+
+	purged, err = repo.Purge(ctx, time.Now().Add(time.Second))
+	if err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+	if len(purged) != 2 || purged[0] != deleted[0].ID || purged[1] != deleted[1].ID {
+		t.Errorf("expected A and B purged, oldest first, got %v", purged)
+	}
+	if page := trashSearch(t, repo, estate.PropertyQuery{}); len(page.Items) != 0 {
+		t.Errorf("expected empty trash, got %v", names(page.Items))
+	}
+	if err := repo.Undelete(ctx, deleted[0].ID, 0); !errors.Is(err, estate.ErrNotFound) {
+		t.Errorf("expected ErrNotFound restoring a purged property, got %v", err)
+	}
+
+	revisions, err := repo.Revisions(ctx, deleted[0].ID)
+	if err != nil {
+		t.Fatalf("Revisions: %v", err)
+	}
+	if len(revisions) != 2 {
+		t.Errorf("expected revisions kept after purge, got %d", len(revisions))
+	}
+}
+
+// testPurgedWithProperty checks that the record create stores about a
+// purged property goes with it, and the one about another property is
+// kept. create returns a function finding the record.
+func testPurgedWithProperty(t *testing.T, repo estate.Repo, create func(p *estate.Property) func() error) {
+	t.Helper()
+	ctx := context.Background()
+
+	purged := NewProperty("Mayor 12")
+	kept := NewProperty("Mayor 14")
+	for _, p := range []*estate.Property{purged, kept} {
+		if err := repo.Create(ctx, p); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+	gone := create(purged)
+	left := create(kept)
+
+	if err := repo.Delete(ctx, purged.ID, 0); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := repo.Purge(ctx, time.Now().Add(time.Second)); err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+
+	if err := gone(); err == nil {
+		t.Errorf("expected the record of the purged property deleted")
+	}
+	if err := left(); err != nil {
+		t.Errorf("expected the record of another property kept, got %v", err)
+	}
+}
@@ -0,0 +1,523 @@
+package estate
+
+import (
+	"context"
+	"math"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/services/estate/internal/odata"
+)
+
+// The RESO Web API exposes properties over OData v4 with the field names
+// of the RESO Data Dictionary. Fields without a Data Dictionary equivalent
+// (ListingTitle, ListPriceCurrency) are local.
+
+// ResoNamespace is the schema namespace of the RESO entity types.
+const ResoNamespace = "org.reso.metadata"
+
+// Units of the RESO area fields; areas are stored in square meters.
+const resoAreaUnits = "Square Meters"
+
+// ResoMedia is the RESO Media resource, expanded from properties.
+var ResoMedia = &odata.EntityType{
+	Name: "Media",
+	Key:  "MediaKey",
+	Properties: []odata.PropertyType{
+		{Name: "MediaKey", Type: "Edm.String"},
+		{Name: "ResourceName", Type: "Edm.String"},
+		{Name: "ResourceRecordKey", Type: "Edm.String"},
+		{Name: "MediaCategory", Type: "Edm.String", Nullable: true},
+		{Name: "MediaURL", Type: "Edm.String"},
+		{Name: "MimeType", Type: "Edm.String"},
+		{Name: "Order", Type: "Edm.Int32"},
+		{Name: "PreferredPhotoYN", Type: "Edm.Boolean"},
+		{Name: "ShortDescription", Type: "Edm.String", Nullable: true},
+		{Name: "ImageWidth", Type: "Edm.Int32", Nullable: true},
+		{Name: "ImageHeight", Type: "Edm.Int32", Nullable: true},
+		{Name: "ModificationTimestamp", Type: "Edm.DateTimeOffset"},
+	},
+}
+
+// ResoProperty is the RESO Property resource. Sortable fields are those
+// the repository can order by (see resoSortFields).
+var ResoProperty = &odata.EntityType{
+	Name: "Property",
+	Key:  "ListingKey",
+	Properties: []odata.PropertyType{
+		{Name: "ListingKey", Type: "Edm.String"},
+		{Name: "ListingTitle", Type: "Edm.String", Sortable: true},
+		{Name: "PublicRemarks", Type: "Edm.String", Nullable: true},
+		{Name: "StandardStatus", Type: "Edm.String"},
+		{Name: "MlsStatus", Type: "Edm.String"},
+		{Name: "PropertyType", Type: "Edm.String", Nullable: true},
+		{Name: "PropertySubType", Type: "Edm.String", Nullable: true},
+		{Name: "ListPrice", Type: "Edm.Decimal", Nullable: true, Sortable: true},
+		{Name: "ListPriceCurrency", Type: "Edm.String", Nullable: true},
+		{Name: "LeaseAmountFrequency", Type: "Edm.String", Nullable: true},
+		{Name: "UnparsedAddress", Type: "Edm.String", Nullable: true},
+		{Name: "StreetNumber", Type: "Edm.String", Nullable: true},
+		{Name: "StreetName", Type: "Edm.String", Nullable: true},
+		{Name: "UnitNumber", Type: "Edm.String", Nullable: true},
+		{Name: "City", Type: "Edm.String", Nullable: true},
+		{Name: "StateOrProvince", Type: "Edm.String", Nullable: true},
+		{Name: "PostalCode", Type: "Edm.String", Nullable: true},
+		{Name: "Country", Type: "Edm.String", Nullable: true},
+		{Name: "Latitude", Type: "Edm.Double", Nullable: true},
+		{Name: "Longitude", Type: "Edm.Double", Nullable: true},
+		{Name: "BedroomsTotal", Type: "Edm.Int32", Sortable: true},
+		{Name: "BathroomsTotalInteger", Type: "Edm.Int32"},
+		{Name: "BathroomsFull", Type: "Edm.Int32", Sortable: true},
+		{Name: "BathroomsHalf", Type: "Edm.Int32"},
+		{Name: "RoomsTotal", Type: "Edm.Int32"},
+		{Name: "ParkingTotal", Type: "Edm.Int32"},
+		{Name: "CoveredSpaces", Type: "Edm.Int32"},
+		{Name: "StoriesTotal", Type: "Edm.Int32", Nullable: true},
+		{Name: "EntryLevel", Type: "Edm.Int32", Nullable: true},
+		{Name: "YearBuilt", Type: "Edm.Int32", Nullable: true, Sortable: true},
+		{Name: "PropertyCondition", Type: "Edm.String", Nullable: true},
+		{Name: "BuildingAreaTotal", Type: "Edm.Decimal", Nullable: true, Sortable: true},
+		{Name: "BuildingAreaUnits", Type: "Edm.String"},
+		{Name: "LivingArea", Type: "Edm.Decimal", Nullable: true, Sortable: true},
+		{Name: "LivingAreaUnits", Type: "Edm.String"},
+		{Name: "LotSizeArea", Type: "Edm.Decimal", Nullable: true},
+		{Name: "LotSizeUnits", Type: "Edm.String"},
+		{Name: "PoolPrivateYN", Type: "Edm.Boolean"},
+		{Name: "FireplaceYN", Type: "Edm.Boolean"},
+		{Name: "CoolingYN", Type: "Edm.Boolean"},
+		{Name: "HeatingYN", Type: "Edm.Boolean"},
+		{Name: "Furnished", Type: "Edm.String"},
+		{Name: "ListAgentKey", Type: "Edm.String", Nullable: true},
+		{Name: "OriginalEntryTimestamp", Type: "Edm.DateTimeOffset", Sortable: true},
+		{Name: "ModificationTimestamp", Type: "Edm.DateTimeOffset", Sortable: true},
+	},
+	Navigation: []odata.NavigationProperty{
+		{Name: "Media", Type: ResoMedia, Collection: true},
+	},
+}
+
+// ResoSchema is the schema served as $metadata.
+var ResoSchema = &odata.Schema{
+	Namespace:   ResoNamespace,
+	Container:   "Default",
+	EntityTypes: []*odata.EntityType{ResoProperty, ResoMedia},
+	EntitySets:  []odata.EntitySet{{Name: "Property", Type: ResoProperty}},
+}
+
+// resoSortFields maps sortable RESO fields to repository sort fields.
+// ListPrice orders by valuation, so prices in different currencies compare
+// by value.
+var resoSortFields = map[string]SortField{
+	"ListingTitle":           SortName,
+	"ListPrice":              SortPrice,
+	"BedroomsTotal":          SortBedrooms,
+	"BathroomsFull":          SortBathrooms,
+	"YearBuilt":              SortYearBuilt,
+	"BuildingAreaTotal":      SortTotalArea,
+	"LivingArea":             SortCoveredArea,
+	"OriginalEntryTimestamp": SortCreatedAt,
+	"ModificationTimestamp":  SortUpdatedAt,
+}
+
+// resoStatuses maps property statuses to RESO StandardStatus values.
+var resoStatuses = map[string]string{
+	StatusDraft:     "Incomplete",
+	StatusAvailable: "Active",
+	StatusReserved:  "Active Under Contract",
+	StatusSold:      "Closed",
+	StatusRented:    "Closed",
+	StatusInactive:  "Withdrawn",
+}
+
+// resoLeaseFrequencies maps rent price types to RESO LeaseAmountFrequency
+// values.
+var resoLeaseFrequencies = map[string]string{
+	"rent_daily":   "Daily",
+	"rent_weekly":  "Weekly",
+	"rent_monthly": "Monthly",
+	"rent_yearly":  "Annually",
+}
+
+// resoPropertyTypes maps category keys to RESO PropertyType values for
+// sale and lease listings.
+var resoPropertyTypes = map[string][2]string{
+	"residential":     {"Residential", "Residential Lease"},
+	"commercial":      {"Commercial Sale", "Commercial Lease"},
+	"mixed_use":       {"Commercial Sale", "Commercial Lease"},
+	"special_purpose": {"Commercial Sale", "Commercial Lease"},
+	"land":            {"Land", "Land"},
+	"agricultural":    {"Farm", "Farm"},
+}
+
+// resoPropertySubTypes maps subtype and type keys to RESO PropertySubType
+// values. The subtype is looked up first.
+var resoPropertySubTypes = map[string]string{
+	"apartment":      "Apartment",
+	"studio":         "Apartment",
+	"loft":           "Apartment",
+	"penthouse":      "Apartment",
+	"condo":          "Condominium",
+	"house":          "Single Family Residence",
+	"detached_house": "Single Family Residence",
+	"bungalow":       "Single Family Residence",
+	"chalet":         "Single Family Residence",
+	"cottage":        "Single Family Residence",
+	"cabin":          "Cabin",
+	"townhouse":      "Townhouse",
+	"row_house":      "Townhouse",
+	"duplex":         "Duplex",
+	"triplex":        "Triplex",
+	"office":         "Office",
+	"retail":         "Retail",
+	"retail_store":   "Retail",
+	"showroom":       "Retail",
+	"warehouse":      "Warehouse",
+	"hotel":          "Hotel/Motel",
+	"farm":           "Farm",
+	"ranch":          "Ranch",
+	"land":           "Unimproved Land",
+	"raw_land":       "Unimproved Land",
+	"mixed_use":      "Mixed Use",
+}
+
+// resoMediaCategories maps media kinds to RESO MediaCategory values.
+var resoMediaCategories = map[string]string{
+	MediaPhoto:     "Photo",
+	MediaFloorPlan: "Floor Plan",
+	MediaDocument:  "Document",
+}
+
+// resoMapper converts properties to RESO entities, resolving their
+// classification keys once per request.
+type resoMapper struct {
+	keys *classificationKeys
+}
+
+func newResoMapper(dict Client) *resoMapper {
+	return &resoMapper{keys: newClassificationKeys(dict)}
+}
+
+// property returns the RESO Property entity of p, without expansions.
+func (m *resoMapper) property(ctx context.Context, p *Property) (*odata.Entity, error) {
+	keys, err := m.keys.classification(ctx, p.Classification)
+	if err != nil {
+		return nil, err
+	}
+
+	var price *Price
+	if len(p.Prices) > 0 {
+		price = &p.Prices[0]
+	}
+	lease := price != nil && strings.HasPrefix(price.Type, "rent_")
+
+	e := odata.NewEntity()
+	e.Set("ListingKey", p.ID.String())
+	e.Set("ListingTitle", p.Name)
+	e.Set("PublicRemarks", nullString(p.Description))
+	e.Set("StandardStatus", resoStatuses[p.Status])
+	e.Set("MlsStatus", p.Status)
+	e.Set("PropertyType", resoPropertyType(keys.Category, lease))
+	e.Set("PropertySubType", resoPropertySubType(keys))
+
+	if price != nil {
+		e.Set("ListPrice", price.Amount)
+		e.Set("ListPriceCurrency", price.Currency)
+		e.Set("LeaseAmountFrequency", nullString(resoLeaseFrequencies[price.Type]))
+	} else {
+		e.Set("ListPrice", nil)
+		e.Set("ListPriceCurrency", nil)
+		e.Set("LeaseAmountFrequency", nil)
+	}
+
+	addr := p.Location.Address
+	e.Set("UnparsedAddress", nullString(addr.FullAddress()))
+	e.Set("StreetNumber", nullString(addr.Number))
+	e.Set("StreetName", nullString(addr.Street))
+	e.Set("UnitNumber", nullString(addr.Unit))
+	e.Set("City", nullString(addr.City))
+	e.Set("StateOrProvince", nullString(addr.State))
+	e.Set("PostalCode", nullString(addr.PostalCode))
+	e.Set("Country", nullString(addr.Country))
+	if c := p.Location.Coordinates; !c.IsZero() {
+		e.Set("Latitude", c.Latitude)
+		e.Set("Longitude", c.Longitude)
+	} else {
+		e.Set("Latitude", nil)
+		e.Set("Longitude", nil)
+	}
+
+	f := p.Features
+	e.Set("BedroomsTotal", f.Bedrooms)
+	e.Set("BathroomsTotalInteger", f.Bathrooms+f.HalfBaths)
+	e.Set("BathroomsFull", f.Bathrooms)
+	e.Set("BathroomsHalf", f.HalfBaths)
+	e.Set("RoomsTotal", f.Rooms)
+	e.Set("ParkingTotal", f.Parking)
+	e.Set("CoveredSpaces", f.CoveredParking)
+	e.Set("StoriesTotal", nullInt(f.Floors))
+	e.Set("EntryLevel", nullInt(f.Floor))
+	e.Set("YearBuilt", nullInt(f.YearBuilt))
+	e.Set("PropertyCondition", nullString(f.Condition))
+	e.Set("BuildingAreaTotal", nullFloat(f.TotalArea))
+	e.Set("BuildingAreaUnits", resoAreaUnits)
+	e.Set("LivingArea", nullFloat(f.CoveredArea))
+	e.Set("LivingAreaUnits", resoAreaUnits)
+	e.Set("LotSizeArea", nullFloat(f.LandArea))
+	e.Set("LotSizeUnits", resoAreaUnits)
+	e.Set("PoolPrivateYN", f.Pool)
+	e.Set("FireplaceYN", f.Fireplace)
+	e.Set("CoolingYN", f.AirConditioning)
+	e.Set("HeatingYN", f.Heating)
+	if f.Furnished {
+		e.Set("Furnished", "Furnished")
+	} else {
+		e.Set("Furnished", "Unfurnished")
+	}
+	e.Set("ListAgentKey", nullString(p.OwnerID))
+	e.Set("OriginalEntryTimestamp", p.CreatedAt)
+	e.Set("ModificationTimestamp", p.UpdatedAt)
+	return e, nil
+}
+
+// media returns the RESO Media entity of m, whose URL is set.
+func (m *resoMapper) media(item Media) *odata.Entity {
+	e := odata.NewEntity()
+	e.Set("MediaKey", item.ID.String())
+	e.Set("ResourceName", ResoProperty.Name)
+	e.Set("ResourceRecordKey", item.PropertyID.String())
+	e.Set("MediaCategory", nullString(resoMediaCategories[item.Kind]))
+	e.Set("MediaURL", item.URL)
+	e.Set("MimeType", item.ContentType)
+	e.Set("Order", item.Position)
+	e.Set("PreferredPhotoYN", item.Cover)
+	e.Set("ShortDescription", nullString(caption(item.Captions)))
+	e.Set("ImageWidth", nullInt(item.Width))
+	e.Set("ImageHeight", nullInt(item.Height))
+	e.Set("ModificationTimestamp", item.CreatedAt)
+	return e
+}
+
+// caption returns the English caption, or the first one by locale.
+func caption(captions map[string]string) string {
+	if c, ok := captions["en"]; ok {
+		return c
+	}
+	first := ""
+	for locale := range captions {
+		if first == "" || locale < first {
+			first = locale
+		}
+	}
+	return captions[first]
+}
+
+func resoPropertyType(category string, lease bool) any {
+	types, ok := resoPropertyTypes[category]
+	if !ok {
+		return nil
+	}
+	if lease {
+		return types[1]
+	}
+	return types[0]
+}
+
+func resoPropertySubType(keys FeedClassification) any {
+	for _, key := range []string{keys.Subtype, keys.Type} {
+		if t, ok := resoPropertySubTypes[key]; ok {
+			return t
+		}
+	}
+	return nil
+}
+
+func nullString(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func nullInt(n int) any {
+	if n == 0 {
+		return nil
+	}
+	return n
+}
+
+func nullFloat(f float64) any {
+	if f == 0 {
+		return nil
+	}
+	return f
+}
+
+// resoQuery returns the repository query for OData options: $orderby
+// mapped to sort orders, by entry time by default, and the conditions of
+// the filter the repository can apply.
+func resoQuery(q *odata.Query) PropertyQuery {
+	query := PropertyQuery{Limit: MaxSearchLimit}
+	for _, o := range q.OrderBy {
+		query.Sort = append(query.Sort, SortOrder{Field: resoSortFields[o.Property], Desc: o.Desc})
+	}
+	if len(query.Sort) == 0 {
+		query.Sort = []SortOrder{{Field: SortCreatedAt}}
+	}
+	if q.Filter != nil {
+		resoPushdown(q.Filter, &query)
+	}
+	return query
+}
+
+// resoPushdown narrows a query with the conditions of the and-chain at the
+// top of a filter that the repository can apply. The whole filter is still
+// evaluated on the results, so a pushed condition only needs to hold for
+// every match, e.g. city matching is case-insensitive in the repository.
+func resoPushdown(e odata.Expr, q *PropertyQuery) {
+	switch e := e.(type) {
+	case odata.Binary:
+		if e.Op == "and" {
+			resoPushdown(e.Left, q)
+			resoPushdown(e.Right, q)
+			return
+		}
+		path, lit, op, ok := comparison(e)
+		if !ok {
+			return
+		}
+		resoPushComparison(path.Name, op, lit.Value, q)
+
+	case odata.In:
+		path, ok := e.X.(odata.Path)
+		if !ok || len(q.Statuses) > 0 {
+			return
+		}
+		var statuses []string
+		for _, lit := range e.List {
+			s, _ := lit.Value.(string)
+			matched := resoLocalStatuses(path.Name, s)
+			if matched == nil {
+				return
+			}
+			statuses = append(statuses, matched...)
+		}
+		q.Statuses = statuses
+	}
+}
+
+// comparison returns the property, literal and operator of a comparison,
+// with the property on the left.
+func comparison(e odata.Binary) (odata.Path, odata.Literal, string, bool) {
+	if path, ok := e.Left.(odata.Path); ok {
+		lit, ok := e.Right.(odata.Literal)
+		return path, lit, e.Op, ok && lit.Value != nil
+	}
+	if path, ok := e.Right.(odata.Path); ok {
+		lit, ok := e.Left.(odata.Literal)
+		flipped := map[string]string{"gt": "lt", "ge": "le", "lt": "gt", "le": "ge"}[e.Op]
+		if flipped == "" {
+			flipped = e.Op
+		}
+		return path, lit, flipped, ok && lit.Value != nil
+	}
+	return odata.Path{}, odata.Literal{}, "", false
+}
+
+func resoPushComparison(name, op string, value any, q *PropertyQuery) {
+	if s, ok := value.(string); ok {
+		if op != "eq" {
+			return
+		}
+		switch name {
+		case "ListingKey":
+			if id, err := uuid.Parse(s); err == nil && len(q.IDs) == 0 {
+				q.IDs = []uuid.UUID{id}
+			}
+		case "StandardStatus", "MlsStatus":
+			if len(q.Statuses) == 0 {
+				q.Statuses = resoLocalStatuses(name, s)
+			}
+		case "ListAgentKey":
+			q.OwnerID = s
+		case "City":
+			q.City = s
+		case "Country":
+			q.Country = s
+		}
+		return
+	}
+
+	var n float64
+	switch v := value.(type) {
+	case int64:
+		n = float64(v)
+	case float64:
+		n = v
+	default:
+		return
+	}
+	switch name {
+	case "BedroomsTotal":
+		pushIntRange(&q.Bedrooms, op, n)
+	case "BathroomsFull":
+		pushIntRange(&q.Bathrooms, op, n)
+	case "YearBuilt":
+		pushIntRange(&q.YearBuilt, op, n)
+	case "BuildingAreaTotal":
+		pushFloatRange(&q.TotalArea, op, n)
+	case "LivingArea":
+		pushFloatRange(&q.CoveredArea, op, n)
+	}
+}
+
+// resoLocalStatuses returns the property statuses of a StandardStatus or
+// MlsStatus value; nil if there are none, which is pushed as no filter.
+func resoLocalStatuses(name, value string) []string {
+	if name == "MlsStatus" {
+		return []string{value}
+	}
+	if name != "StandardStatus" {
+		return nil
+	}
+	var statuses []string
+	for _, s := range Statuses {
+		if resoStatuses[s] == value {
+			statuses = append(statuses, s)
+		}
+	}
+	return statuses
+}
+
+func pushIntRange(r *IntRange, op string, n float64) {
+	lower, upper := math.Ceil(n), math.Floor(n)
+	switch op {
+	case "gt":
+		lower = math.Floor(n) + 1
+	case "lt":
+		upper = math.Ceil(n) - 1
+	}
+	if op == "eq" || op == "ge" || op == "gt" {
+		if v := int(lower); r.Min == nil || v > *r.Min {
+			r.Min = &v
+		}
+	}
+	if op == "eq" || op == "le" || op == "lt" {
+		if v := int(upper); r.Max == nil || v < *r.Max {
+			r.Max = &v
+		}
+	}
+}
+
+func pushFloatRange(r *FloatRange, op string, n float64) {
+	if op == "eq" || op == "ge" || op == "gt" {
+		if r.Min == nil || n > *r.Min {
+			r.Min = &n
+		}
+	}
+	if op == "eq" || op == "le" || op == "lt" {
+		if r.Max == nil || n < *r.Max {
+			r.Max = &n
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package estate
+
+import (
+	"context"
+	"net/url"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/services/estate/internal/money"
+	"github.com/pulap/pulap/services/estate/internal/odata"
+)
+
+func TestResoSchema(t *testing.T) {
+	for _, p := range ResoProperty.Properties {
+		if _, ok := resoSortFields[p.Name]; ok != p.Sortable {
+			t.Errorf("%s: sortable is %v but has sort field %v", p.Name, p.Sortable, ok)
+		}
+	}
+
+	p := newExportTestRepo(1).items[0]
+	e, err := newResoMapper(newImportDictionary()).property(context.Background(), p)
+	if err != nil {
+		t.Fatalf("property: %v", err)
+	}
+	var names []string
+	for _, p := range ResoProperty.Properties {
+		names = append(names, p.Name)
+	}
+	if got := odataNames(t, e); !slices.Equal(got, names) {
+		t.Errorf("entity properties do not match the schema:\n%v\n%v", got, names)
+	}
+}
+
+func TestResoMapperProperty(t *testing.T) {
+	p := newExportTestRepo(1).items[0]
+	p.Classification.SubtypeID = testLoft.ID
+	p.Prices = []Price{{Amount: money.MustParse("1200.50"), Currency: "EUR", Type: "rent_monthly"}}
+	p.Status = StatusRented
+	p.Features.Bathrooms = 1
+	p.Features.HalfBaths = 1
+	p.Features.YearBuilt = 1998
+	p.Features.Furnished = true
+	p.Location.Address.Number = "12"
+
+	e, err := newResoMapper(newImportDictionary()).property(context.Background(), p)
+	if err != nil {
+		t.Fatalf("property: %v", err)
+	}
+	want := map[string]any{
+		"ListingKey":            p.ID.String(),
+		"ListingTitle":          "Piso 0",
+		"PublicRemarks":         nil,
+		"StandardStatus":        "Closed",
+		"MlsStatus":             "rented",
+		"PropertyType":          "Residential Lease",
+		"PropertySubType":       "Apartment",
+		"ListPriceCurrency":     "EUR",
+		"LeaseAmountFrequency":  "Monthly",
+		"StreetName":            "Mayor 12",
+		"StreetNumber":          "12",
+		"City":                  "Madrid",
+		"Latitude":              40.4168,
+		"BedroomsTotal":         2,
+		"BathroomsTotalInteger": 2,
+		"BathroomsHalf":         1,
+		"YearBuilt":             1998,
+		"LivingArea":            nil,
+		"BuildingAreaTotal":     85.5,
+		"PoolPrivateYN":         true,
+		"Furnished":             "Furnished",
+		"ListAgentKey":          nil,
+	}
+	for name, value := range want {
+		if got, _ := e.Get(name); !reflect.DeepEqual(got, value) {
+			t.Errorf("%s: expected %#v, got %#v", name, value, got)
+		}
+	}
+	if price, _ := e.Get("ListPrice"); price.(money.Decimal).String() != "1200.50" {
+		t.Errorf("ListPrice: expected 1200.50, got %v", price)
+	}
+}
+
+func TestResoMapperMedia(t *testing.T) {
+	m := Media{
+		ID:         uuid.New(),
+		PropertyID: uuid.New(),
+		Kind:       MediaFloorPlan,
+		Position:   2,
+		Captions:   map[string]string{"pl": "Plan", "es": "Plano"},
+		URL:        "/estates/x/media/y/content",
+	}
+	e := newResoMapper(newImportDictionary()).media(m)
+	for name, value := range map[string]any{
+		"MediaCategory":     "Floor Plan",
+		"ResourceRecordKey": m.PropertyID.String(),
+		"Order":             2,
+		"ShortDescription":  "Plano",
+		"ImageWidth":        nil,
+		"MediaURL":          m.URL,
+	} {
+		if got, _ := e.Get(name); !reflect.DeepEqual(got, value) {
+			t.Errorf("%s: expected %#v, got %#v", name, value, got)
+		}
+	}
+}
+
+func TestResoQuery(t *testing.T) {
+	id := uuid.New()
+	tests := []struct {
+		name  string
+		query string
+		want  PropertyQuery
+	}{
+		{
+			name:  "default order",
+			query: "",
+			want:  PropertyQuery{Sort: []SortOrder{{Field: SortCreatedAt}}},
+		},
+		{
+			name:  "order",
+			query: "$orderby=ListPrice desc,BedroomsTotal",
+			want:  PropertyQuery{Sort: []SortOrder{{Field: SortPrice, Desc: true}, {Field: SortBedrooms}}},
+		},
+		{
+			name:  "conditions",
+			query: "$filter=StandardStatus eq 'Closed' and City eq 'Madrid' and ListAgentKey eq 'u1' and ListingKey eq " + id.String(),
+			want: PropertyQuery{
+				Sort:     []SortOrder{{Field: SortCreatedAt}},
+				Statuses: []string{StatusSold, StatusRented},
+				City:     "Madrid",
+				OwnerID:  "u1",
+				IDs:      []uuid.UUID{id},
+			},
+		},
+		{
+			name:  "status list",
+			query: "$filter=StandardStatus in ('Active','Withdrawn')",
+			want:  PropertyQuery{Sort: []SortOrder{{Field: SortCreatedAt}}, Statuses: []string{StatusAvailable, StatusInactive}},
+		},
+		{
+			name:  "ranges",
+			query: "$filter=BedroomsTotal gt 1.5 and 4 ge BedroomsTotal and BathroomsFull lt 3 and BuildingAreaTotal gt 50 and LivingArea le 80.5",
+			want: PropertyQuery{
+				Sort:        []SortOrder{{Field: SortCreatedAt}},
+				Bedrooms:    IntRange{Min: ptr(2), Max: ptr(4)},
+				Bathrooms:   IntRange{Max: ptr(2)},
+				TotalArea:   FloatRange{Min: ptr(50.0)},
+				CoveredArea: FloatRange{Max: ptr(80.5)},
+			},
+		},
+		{
+			name:  "or is not pushed",
+			query: "$filter=City eq 'Madrid' or BedroomsTotal eq 2",
+			want:  PropertyQuery{Sort: []SortOrder{{Field: SortCreatedAt}}},
+		},
+		{
+			name:  "not and ne are not pushed",
+			query: "$filter=not (City eq 'Madrid') and BedroomsTotal ne 2 and contains(City,'M')",
+			want:  PropertyQuery{Sort: []SortOrder{{Field: SortCreatedAt}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			q, err := odata.ParseQuery(values)
+			if err != nil {
+				t.Fatalf("ParseQuery: %v", err)
+			}
+			if err := q.Validate(ResoProperty); err != nil {
+				t.Fatalf("Validate: %v", err)
+			}
+			tt.want.Limit = MaxSearchLimit
+			if got := resoQuery(q); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func odataNames(t *testing.T, e *odata.Entity) []string {
+	t.Helper()
+	var names []string
+	for _, p := range ResoProperty.Properties {
+		if _, ok := e.Get(p.Name); ok {
+			names = append(names, p.Name)
+		}
+	}
+	return names
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
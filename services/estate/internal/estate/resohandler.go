@@ -0,0 +1,321 @@
+package estate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/pkg/lib/core"
+	"github.com/pulap/pulap/pkg/lib/telemetry"
+	"github.com/pulap/pulap/services/estate/internal/config"
+	"github.com/pulap/pulap/services/estate/internal/odata"
+)
+
+// ResoHandler handles HTTP requests for the RESO Web API, a read only
+// OData view of properties.
+type ResoHandler struct {
+	*PropertyAccess
+	dictClient Client
+	media      *MediaLibrary
+	tlm        *telemetry.HTTP
+}
+
+// NewResoHandler creates a new ResoHandler naming classifications through
+// dictClient and linking the media in media, which may be nil.
+func NewResoHandler(dictClient Client, media *MediaLibrary, access *PropertyAccess, xparams config.XParams) *ResoHandler {
+	return &ResoHandler{
+		PropertyAccess: access,
+		dictClient:     dictClient,
+		media:          media,
+		tlm: telemetry.NewHTTP(
+			telemetry.WithTracer(xparams.Tracer()),
+			telemetry.WithMetrics(xparams.Metrics()),
+		),
+	}
+}
+
+// RegisterRoutes registers the RESO Web API routes.
+func (h *ResoHandler) RegisterRoutes(r chi.Router) {
+	r.Route(ResoRoot, func(r chi.Router) {
+		r.Use(h.authn())
+		r.Get("/", h.ResoServiceDocument)
+		r.Get("/$metadata", h.ResoMetadata)
+		r.Get("/Property", h.ListResoProperties)
+		r.Get("/Property('{key}')", h.GetResoProperty)
+	})
+}
+
+// ResoRoot is the service root of the RESO Web API.
+const ResoRoot = "/odata"
+
+// MaxResoPageSize caps the entities of a response; larger results are
+// paged with @odata.nextLink.
+const MaxResoPageSize = MaxSearchLimit
+
+// ResoServiceDocument handles GET /odata
+func (h *ResoHandler) ResoServiceDocument(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "ResoHandler.ResoServiceDocument")
+	defer finish()
+
+	respondOData(w, http.StatusOK, odata.ServiceDocument(odata.MetadataURL(ResoRoot), ResoSchema.EntitySets))
+}
+
+// ResoMetadata handles GET /odata/$metadata
+func (h *ResoHandler) ResoMetadata(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "ResoHandler.ResoMetadata")
+	defer finish()
+	log := h.log(r)
+
+	var buf bytes.Buffer
+	if err := odata.WriteMetadata(&buf, ResoSchema); err != nil {
+		log.Error("cannot write metadata", "error", err)
+		core.RespondError(w, http.StatusInternalServerError, "Could not write metadata")
+		return
+	}
+	w.Header().Set("OData-Version", odata.Version)
+	w.Header().Set("Content-Type", odata.MetadataContentType)
+	w.Write(buf.Bytes())
+}
+
+// ListResoProperties handles GET /odata/Property
+// The filter is evaluated on the properties of the repository the user may
+// read, which applies the conditions it supports (see resoPushdown) and the
+// order.
+func (h *ResoHandler) ListResoProperties(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "ResoHandler.ListResoProperties")
+	defer finish()
+	log := h.log(r)
+	ctx := r.Context()
+
+	q, ok := parseResoQuery(w, r)
+	if !ok {
+		return
+	}
+
+	limit := MaxResoPageSize
+	if q.Top != nil {
+		limit = min(limit, *q.Top)
+	}
+
+	access, ok := h.access(w, r, PermissionRead)
+	if !ok {
+		return
+	}
+
+	mapper := newResoMapper(h.dictClient)
+	query := resoQuery(q)
+	access.Restrict(&query)
+	var (
+		items []*odata.Entity
+		count int64
+		more  bool
+	)
+scan:
+	for {
+		page, err := h.repo.Search(ctx, query)
+		if err != nil {
+			log.Error("cannot search properties", "error", err)
+			core.RespondError(w, http.StatusInternalServerError, "Could not search properties")
+			return
+		}
+		for _, p := range page.Items {
+			e, err := mapper.property(ctx, p)
+			if err != nil {
+				log.Error("cannot resolve classification", "error", err, "id", p.ID.String())
+				core.RespondError(w, http.StatusBadGateway, "Could not resolve classification")
+				return
+			}
+			if q.Filter != nil && !odata.Match(q.Filter, e) {
+				continue
+			}
+			count++
+			if count <= int64(q.Skip) {
+				continue
+			}
+			if len(items) < limit {
+				items = append(items, e)
+				continue
+			}
+			more = true
+			if !q.Count {
+				break scan
+			}
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		query.Cursor = page.NextCursor
+	}
+
+	if err := h.expandReso(ctx, q, mapper, items); err != nil {
+		log.Error("cannot expand properties", "error", err)
+		core.RespondError(w, http.StatusInternalServerError, "Could not expand properties")
+		return
+	}
+	for i, e := range items {
+		items[i] = e.Select(resoSelect(q))
+	}
+
+	res := odata.Collection{Context: resoContext(q), Value: items}
+	if q.Count {
+		res.Count = &count
+	}
+	if more && (q.Top == nil || *q.Top > len(items)) {
+		res.NextLink = resoNextLink(r, q, len(items))
+	}
+	respondOData(w, http.StatusOK, res)
+}
+
+// GetResoProperty handles GET /odata/Property('{key}')
+func (h *ResoHandler) GetResoProperty(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "ResoHandler.GetResoProperty")
+	defer finish()
+	log := h.log(r)
+	ctx := r.Context()
+
+	q, ok := parseResoQuery(w, r)
+	if !ok {
+		return
+	}
+	if q.Filter != nil || len(q.OrderBy) > 0 || q.Top != nil || q.Skip > 0 || q.Count {
+		core.RespondError(w, http.StatusBadRequest, "Only $select and $expand apply to a single entity")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "key"))
+	if err != nil {
+		core.RespondError(w, http.StatusNotFound, "Property not found")
+		return
+	}
+	property, err := h.repo.Get(ctx, id)
+	if errors.Is(err, ErrNotFound) || (err == nil && property == nil) {
+		core.RespondError(w, http.StatusNotFound, "Property not found")
+		return
+	}
+	if err != nil {
+		log.Error("error loading property", "error", err, "id", id.String())
+		core.RespondError(w, http.StatusInternalServerError, "Could not load property")
+		return
+	}
+	if !h.authorizeProperty(w, r, property, PermissionRead) {
+		return
+	}
+
+	mapper := newResoMapper(h.dictClient)
+	e, err := mapper.property(ctx, property)
+	if err != nil {
+		log.Error("cannot resolve classification", "error", err, "id", id.String())
+		core.RespondError(w, http.StatusBadGateway, "Could not resolve classification")
+		return
+	}
+	if err := h.expandReso(ctx, q, mapper, []*odata.Entity{e}); err != nil {
+		log.Error("cannot expand property", "error", err, "id", id.String())
+		core.RespondError(w, http.StatusInternalServerError, "Could not expand property")
+		return
+	}
+	respondOData(w, http.StatusOK, e.Select(resoSelect(q)).WithContext(resoContext(q)+"/$entity"))
+}
+
+// parseResoQuery parses and validates the OData options of a request,
+// responding with an error if they are invalid.
+func parseResoQuery(w http.ResponseWriter, r *http.Request) (*odata.Query, bool) {
+	q, err := odata.ParseQuery(r.URL.Query())
+	if err == nil {
+		err = q.Validate(ResoProperty)
+	}
+	switch {
+	case errors.Is(err, odata.ErrNotImplemented):
+		core.RespondError(w, http.StatusNotImplemented, capitalize(err.Error()))
+		return nil, false
+	case err != nil:
+		core.RespondError(w, http.StatusBadRequest, capitalize(err.Error()))
+		return nil, false
+	}
+	return q, true
+}
+
+// expandReso sets the expanded navigation properties of property entities.
+// Media is empty when the media library is unavailable.
+func (h *ResoHandler) expandReso(ctx context.Context, q *odata.Query, mapper *resoMapper, items []*odata.Entity) error {
+	for _, name := range q.Expand {
+		if name != "Media" {
+			continue
+		}
+		for _, e := range items {
+			media := []*odata.Entity{}
+			if h.media != nil {
+				key, _ := e.Get("ListingKey")
+				id, err := uuid.Parse(key.(string))
+				if err != nil {
+					return err
+				}
+				list, err := h.media.List(ctx, id)
+				if err != nil {
+					return fmt.Errorf("cannot list media of %s: %w", id, err)
+				}
+				for _, m := range withMediaURLs(list) {
+					media = append(media, mapper.media(m))
+				}
+			}
+			e.Set("Media", media)
+		}
+	}
+	return nil
+}
+
+// resoSelect returns the properties to serialize: the selected ones, or
+// every structural property, plus the expanded navigation properties.
+func resoSelect(q *odata.Query) []string {
+	if len(q.Select) == 0 {
+		if len(q.Expand) == 0 {
+			return nil
+		}
+		names := make([]string, 0, len(ResoProperty.Properties)+len(q.Expand))
+		for _, p := range ResoProperty.Properties {
+			names = append(names, p.Name)
+		}
+		return append(names, q.Expand...)
+	}
+	return append(append([]string(nil), q.Select...), q.Expand...)
+}
+
+// resoContext returns the context URL of a Property response.
+func resoContext(q *odata.Query) string {
+	context := odata.MetadataURL(ResoRoot) + "#Property"
+	if len(q.Select) > 0 {
+		context += "(" + strings.Join(q.Select, ",") + ")"
+	}
+	return context
+}
+
+// resoNextLink returns the URL of the page following n returned entities.
+func resoNextLink(r *http.Request, q *odata.Query, n int) string {
+	values := r.URL.Query()
+	values.Set("$skip", strconv.Itoa(q.Skip+n))
+	if q.Top != nil {
+		values.Set("$top", strconv.Itoa(*q.Top-n))
+	}
+	return r.URL.Path + "?" + values.Encode()
+}
+
+// respondOData writes an OData JSON response, which is not wrapped in the
+// envelope of the estate API.
+func respondOData(w http.ResponseWriter, status int, v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		core.RespondError(w, http.StatusInternalServerError, "Could not encode response")
+		return
+	}
+	w.Header().Set("OData-Version", odata.Version)
+	w.Header().Set("Content-Type", odata.ContentType)
+	w.WriteHeader(status)
+	w.Write(data)
+}
@@ -0,0 +1,30 @@
+package estate
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ErrRevisionConflict is returned by repositories when the stored revision
+// of a property no longer matches the expected one.
+var ErrRevisionConflict = errors.New("property modified concurrently")
+
+// ETag returns the strong entity tag for a property revision.
+func ETag(revision int64) string {
+	return `"` + strconv.FormatInt(revision, 10) + `"`
+}
+
+// IfMatch reports whether an If-Match header value matches the revision.
+// The header holds "*" or a comma-separated list of entity tags; weak tags
+// never match because If-Match uses the strong comparison.
+func IfMatch(header string, revision int64) bool {
+	etag := ETag(revision)
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
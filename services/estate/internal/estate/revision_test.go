@@ -0,0 +1,35 @@
+package estate
+
+import "testing"
+
+func TestIfMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		revision int64
+		want     bool
+	}{
+		{"same revision", `"3"`, 3, true},
+		{"other revision", `"2"`, 3, false},
+		{"any", "*", 7, true},
+		{"list", `"1", "3"`, 3, true},
+		{"list without match", `"1","2"`, 3, false},
+		{"weak tag", `W/"3"`, 3, false},
+		{"unquoted", "3", 3, false},
+		{"empty", "", 3, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IfMatch(tt.header, tt.revision); got != tt.want {
+				t.Errorf("IfMatch(%q, %d) = %v, want %v", tt.header, tt.revision, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestETag(t *testing.T) {
+	if got := ETag(12); got != `"12"` {
+		t.Errorf("ETag(12) = %s", got)
+	}
+}
@@ -0,0 +1,222 @@
+package estate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/pkg/lib/core"
+)
+
+// RevisionsMeta describes a revision list response.
+type RevisionsMeta struct {
+	Revision int64 `json:"revision"` // Current revision, 0 once the property is deleted
+	Count    int   `json:"count"`
+}
+
+// ListRevisions handles GET /estates/{id}/revisions
+// Revisions are listed newest first without snapshots. They remain
+// available after the property is deleted. Requires PermissionRead on the
+// property.
+func (h *Handler) ListRevisions(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "Handler.ListRevisions")
+	defer finish()
+	log := h.log(r)
+	ctx := r.Context()
+
+	id, ok := h.parseIDParam(w, r, log)
+	if !ok {
+		return
+	}
+
+	revisions, err := h.repo.Revisions(ctx, id)
+	if err != nil {
+		log.Error("error loading revisions", "error", err, "id", id.String())
+		core.RespondError(w, http.StatusInternalServerError, "Could not retrieve revisions")
+		return
+	}
+
+	meta := RevisionsMeta{Count: len(revisions)}
+	property, err := h.repo.Get(ctx, id)
+	switch {
+	case err == nil:
+		meta.Revision = property.Revision
+	case !errors.Is(err, ErrNotFound):
+		log.Error("error loading property", "error", err, "id", id.String())
+		core.RespondError(w, http.StatusInternalServerError, "Could not retrieve property")
+		return
+	case len(revisions) == 0:
+		core.RespondError(w, http.StatusNotFound, "Property not found")
+		return
+	}
+	if !h.authorizeRevisions(w, r, id, property, revisions) {
+		return
+	}
+
+	if revisions == nil {
+		revisions = []PropertyRevision{}
+	}
+	core.RespondSuccessWithMeta(w, revisions, meta)
+}
+
+// GetRevision handles GET /estates/{id}/revisions/{n}
+// The revision includes the snapshot of the property as of that revision.
+// Requires PermissionRead on the property.
+func (h *Handler) GetRevision(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "Handler.GetRevision")
+	defer finish()
+	log := h.log(r)
+
+	rev, ok := h.loadRevision(w, r, log)
+	if !ok {
+		return
+	}
+
+	property, err := h.repo.Get(r.Context(), rev.PropertyID)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		log.Error("error loading property", "error", err, "id", rev.PropertyID.String())
+		core.RespondError(w, http.StatusInternalServerError, "Could not retrieve property")
+		return
+	}
+	if !h.authorizeRevisions(w, r, rev.PropertyID, property, nil) {
+		return
+	}
+
+	core.RespondSuccess(w, rev)
+}
+
+// RestoreRevision handles POST /estates/{id}/revisions/{n}/restore
+// The property is saved with the snapshot of revision n, recorded as a new
+// "restore" revision. The status is kept, since status changes go through
+// the lifecycle. Requires PermissionWrite on the property. An If-Match
+// header makes the restore conditional on the current revision.
+func (h *Handler) RestoreRevision(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "Handler.RestoreRevision")
+	defer finish()
+	log := h.log(r)
+
+	rev, ok := h.loadRevision(w, r, log)
+	if !ok {
+		return
+	}
+
+	current, ok := h.loadForUpdate(w, r, rev.PropertyID)
+	if !ok {
+		return
+	}
+	if current.Revision == rev.Number {
+		core.RespondError(w, http.StatusConflict, fmt.Sprintf("Property is already at revision %d", rev.Number))
+		return
+	}
+
+	restored := *rev.Snapshot
+	restored.Status = current.Status
+	restored.UpdatedBy = requestActor(r)
+
+	log.Info("restoring property revision", "id", current.ID.String(), "revision", rev.Number, "actor", restored.UpdatedBy)
+	h.saveUpdate(w, r.WithContext(WithRestoredFrom(r.Context(), rev.Number)), &restored, current)
+}
+
+// loadRevision loads the revision addressed by the id and n URL parameters,
+// responding with the error when it returns false.
+func (h *Handler) loadRevision(w http.ResponseWriter, r *http.Request, log core.Logger) (*PropertyRevision, bool) {
+	id, ok := h.parseIDParam(w, r, log)
+	if !ok {
+		return nil, false
+	}
+
+	number, err := strconv.ParseInt(chi.URLParam(r, "n"), 10, 64)
+	if err != nil || number < 1 {
+		log.Debug("invalid revision parameter", "n", chi.URLParam(r, "n"))
+		core.RespondError(w, http.StatusBadRequest, "Invalid revision number")
+		return nil, false
+	}
+
+	rev, err := h.repo.Revision(r.Context(), id, number)
+	if err != nil {
+		if errors.Is(err, ErrRevisionNotFound) {
+			core.RespondError(w, http.StatusNotFound, "Revision not found")
+			return nil, false
+		}
+		log.Error("error loading revision", "error", err, "id", id.String(), "revision", number)
+		core.RespondError(w, http.StatusInternalServerError, "Could not retrieve revision")
+		return nil, false
+	}
+
+	return rev, true
+}
+
+// authorizeRevisions checks the user may read the revisions of a property,
+// responding with the error when it returns false. They are authorized on
+// property, the live property if any, then on the property in the trash and,
+// once purged, on its last snapshot. revisions, newest first, may be nil.
+func (h *Handler) authorizeRevisions(w http.ResponseWriter, r *http.Request, id uuid.UUID, property *Property, revisions []PropertyRevision) bool {
+	if property == nil {
+		var err error
+		property, err = h.lastKnown(r.Context(), id, revisions)
+		if err != nil {
+			h.log(r).Error("error loading property", "error", err, "id", id.String())
+			core.RespondError(w, http.StatusInternalServerError, "Could not retrieve property")
+			return false
+		}
+		if property == nil {
+			core.RespondError(w, http.StatusNotFound, "Property not found")
+			return false
+		}
+	}
+	return h.authorizeProperty(w, r, property, PermissionRead)
+}
+
+// lastKnown returns the deleted property, or the snapshot of its last
+// revision once it has been purged; nil if neither exists.
+func (h *Handler) lastKnown(ctx context.Context, id uuid.UUID, revisions []PropertyRevision) (*Property, error) {
+	deleted, err := h.getDeleted(ctx, id)
+	if err == nil {
+		return deleted, nil
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	if revisions == nil {
+		if revisions, err = h.repo.Revisions(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+	if len(revisions) == 0 {
+		return nil, nil
+	}
+	last, err := h.repo.Revision(ctx, id, revisions[0].Number)
+	if err != nil {
+		return nil, err
+	}
+	return last.Snapshot, nil
+}
+
+// decodeOptionalBody decodes the JSON request body into v, if there is one.
+// It responds with an error and returns false when the body is invalid.
+func decodeOptionalBody(w http.ResponseWriter, r *http.Request, log core.Logger, v any) bool {
+	r.Body = http.MaxBytesReader(w, r.Body, MaxBodyBytes)
+	defer r.Body.Close()
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		log.Debug("error reading request body", "error", err)
+		core.RespondError(w, http.StatusBadRequest, "Could not read request body")
+		return false
+	}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, v); err != nil {
+			log.Debug("error decoding JSON", "error", err)
+			core.RespondError(w, http.StatusBadRequest, "Invalid request body")
+			return false
+		}
+	}
+	return true
+}
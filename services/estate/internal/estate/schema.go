@@ -0,0 +1,127 @@
+package estate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// ErrUnknownSchemaVersion is returned when a property has a schema version
+// the registry cannot upgrade from, e.g. one written by a newer release.
+var ErrUnknownSchemaVersion = errors.New("unknown schema version")
+
+// SchemaUpgrader moves a property from schema version From to From+1.
+// Upgrades must be idempotent: applying one to a property that already has
+// the new shape must leave it unchanged.
+type SchemaUpgrader struct {
+	From        int
+	Description string
+	Upgrade     func(p *Property) error
+}
+
+// SchemaRegistry upgrades properties stored with an older schema version to
+// the current one by chaining its upgraders.
+type SchemaRegistry struct {
+	first     int
+	current   int
+	upgraders map[int]SchemaUpgrader
+}
+
+// NewSchemaRegistry returns a registry upgrading from version first, the
+// oldest one ever stored, to version current. There must be exactly one
+// upgrader for every version from first to current-1.
+func NewSchemaRegistry(first, current int, upgraders ...SchemaUpgrader) (*SchemaRegistry, error) {
+	if first < 1 || first > current {
+		return nil, fmt.Errorf("invalid schema versions v%d..v%d", first, current)
+	}
+	r := &SchemaRegistry{first: first, current: current, upgraders: make(map[int]SchemaUpgrader, len(upgraders))}
+	for _, u := range upgraders {
+		if u.From < first || u.From >= current {
+			return nil, fmt.Errorf("upgrader from v%d is outside v%d..v%d", u.From, first, current)
+		}
+		if _, ok := r.upgraders[u.From]; ok {
+			return nil, fmt.Errorf("duplicate upgrader from v%d", u.From)
+		}
+		r.upgraders[u.From] = u
+	}
+	for v := first; v < current; v++ {
+		if _, ok := r.upgraders[v]; !ok {
+			return nil, fmt.Errorf("missing upgrader from v%d", v)
+		}
+	}
+	return r, nil
+}
+
+// Current returns the schema version properties are upgraded to.
+func (r *SchemaRegistry) Current() int {
+	return r.current
+}
+
+// Upgrade brings p to the current schema version and reports whether it ran
+// any upgrader. Properties without a version are treated as the first
+// version. On error p may be partially upgraded and must be discarded.
+func (r *SchemaRegistry) Upgrade(p *Property) (bool, error) {
+	if p.SchemaVersion > r.current || (p.SchemaVersion != 0 && p.SchemaVersion < r.first) {
+		return false, fmt.Errorf("property %s: %w v%d", p.ID, ErrUnknownSchemaVersion, p.SchemaVersion)
+	}
+	if p.SchemaVersion == r.current {
+		return false, nil
+	}
+
+	v := max(p.SchemaVersion, r.first)
+	for ; v < r.current; v++ {
+		u := r.upgraders[v]
+		if err := u.Upgrade(p); err != nil {
+			return false, fmt.Errorf("cannot upgrade property %s from v%d (%s): %w", p.ID, v, u.Description, err)
+		}
+	}
+	p.SchemaVersion = r.current
+	return true, nil
+}
+
+// firstSchemaVersion is the oldest schema version ever stored: properties
+// were always written as v3 before the registry existed, and no v1 or v2
+// shape was ever persisted.
+const firstSchemaVersion = 3
+
+// Schema is the registry used by the repositories to upgrade properties on
+// read. It has no upgraders yet: it only stamps unversioned properties as
+// the current version and rejects unknown ones. When Features or Location
+// change shape, bump currentSchemaVersion and register the upgrader from
+// the previous version here.
+var Schema = mustSchemaRegistry(firstSchemaVersion, currentSchemaVersion)
+
+// UpgradeSchema upgrades p with the default registry (see Schema).
+func UpgradeSchema(p *Property) (bool, error) {
+	return Schema.Upgrade(p)
+}
+
+func mustSchemaRegistry(first, current int, upgraders ...SchemaUpgrader) *SchemaRegistry {
+	r, err := NewSchemaRegistry(first, current, upgraders...)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// SchemaReport summarizes a batch schema migration.
+type SchemaReport struct {
+	Scanned  int             `json:"scanned"`
+	Upgraded int             `json:"upgraded"`
+	Failures []SchemaFailure `json:"failures,omitempty"`
+}
+
+// SchemaFailure is a property a batch schema migration could not rewrite.
+type SchemaFailure struct {
+	ID    uuid.UUID `json:"id"`
+	Error string    `json:"error"`
+}
+
+// SchemaMigrator is implemented by repositories that can rewrite every
+// stored property older than the current schema version. Rewrites keep the
+// revision, since readers already see the upgraded form.
+type SchemaMigrator interface {
+	MigrateSchema(ctx context.Context) (*SchemaReport, error)
+}
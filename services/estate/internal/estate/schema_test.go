@@ -0,0 +1,178 @@
+package estate
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testSchema chains two upgraders from v3, the first stored version, to v5.
+func testSchema(t *testing.T) *SchemaRegistry {
+	t.Helper()
+	r, err := NewSchemaRegistry(3, 5,
+		SchemaUpgrader{From: 4, Description: "trim city", Upgrade: func(p *Property) error {
+			p.Location.Address.City = strings.TrimSpace(p.Location.Address.City)
+			return nil
+		}},
+		SchemaUpgrader{From: 3, Description: "name region", Upgrade: func(p *Property) error {
+			if p.Location.Region == "" {
+				p.Location.Region = "EUROPE"
+			}
+			return nil
+		}},
+	)
+	if err != nil {
+		t.Fatalf("NewSchemaRegistry: %v", err)
+	}
+	return r
+}
+
+func TestSchemaRegistryUpgrade(t *testing.T) {
+	tests := []struct {
+		name     string
+		property Property
+		want     Property
+		upgraded bool
+	}{
+		{
+			name:     "first version runs the chain",
+			property: Property{SchemaVersion: 3, Location: Location{Address: Address{City: " Madrid "}}},
+			want:     Property{SchemaVersion: 5, Location: Location{Address: Address{City: "Madrid"}, Region: "EUROPE"}},
+			upgraded: true,
+		},
+		{
+			name:     "unversioned is treated as the first version",
+			property: Property{Location: Location{Region: "Centro"}},
+			want:     Property{SchemaVersion: 5, Location: Location{Region: "Centro"}},
+			upgraded: true,
+		},
+		{
+			name:     "middle version runs the rest",
+			property: Property{SchemaVersion: 4, Location: Location{Address: Address{City: " Madrid"}}},
+			want:     Property{SchemaVersion: 5, Location: Location{Address: Address{City: "Madrid"}}},
+			upgraded: true,
+		},
+		{
+			name:     "current is left alone",
+			property: Property{SchemaVersion: 5, Location: Location{Address: Address{City: " Madrid "}}},
+			want:     Property{SchemaVersion: 5, Location: Location{Address: Address{City: " Madrid "}}},
+		},
+	}
+
+	r := testSchema(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := tt.property
+			upgraded, err := r.Upgrade(&p)
+			if err != nil {
+				t.Fatalf("Upgrade: %v", err)
+			}
+			if upgraded != tt.upgraded {
+				t.Errorf("upgraded = %v, want %v", upgraded, tt.upgraded)
+			}
+			if !reflect.DeepEqual(p, tt.want) {
+				t.Errorf("got %+v, want %+v", p, tt.want)
+			}
+
+			again, err := r.Upgrade(&p)
+			if err != nil || again {
+				t.Errorf("second upgrade: upgraded = %v, err = %v", again, err)
+			}
+		})
+	}
+}
+
+// TestSchemaUpgradersIdempotent covers the upgraders of the default
+// registry, none until the schema first changes.
+func TestSchemaUpgradersIdempotent(t *testing.T) {
+	for v := Schema.first; v < Schema.Current(); v++ {
+		u := Schema.upgraders[v]
+		t.Run(u.Description, func(t *testing.T) {
+			p := Property{
+				Features: Features{Amenities: []string{"Pool", "Sea View", "gym"}},
+				Location: Location{Address: Address{Street: " Mayor ", City: "Madrid "}},
+			}
+			if err := u.Upgrade(&p); err != nil {
+				t.Fatalf("first upgrade: %v", err)
+			}
+			once := p
+			once.Features.Amenities = append([]string(nil), p.Features.Amenities...)
+
+			if err := u.Upgrade(&p); err != nil {
+				t.Fatalf("second upgrade: %v", err)
+			}
+			if !reflect.DeepEqual(p, once) {
+				t.Errorf("upgrade is not idempotent: got %+v, want %+v", p, once)
+			}
+		})
+	}
+}
+
+func TestUpgradeSchemaUnknownVersion(t *testing.T) {
+	for _, v := range []int{Schema.Current() + 1, firstSchemaVersion - 1} {
+		p := Property{SchemaVersion: v}
+		if _, err := UpgradeSchema(&p); !errors.Is(err, ErrUnknownSchemaVersion) {
+			t.Errorf("v%d: expected ErrUnknownSchemaVersion, got %v", v, err)
+		}
+	}
+}
+
+func TestUpgradeSchemaError(t *testing.T) {
+	fail := errors.New("boom")
+	r, err := NewSchemaRegistry(1, 2, SchemaUpgrader{From: 1, Upgrade: func(*Property) error { return fail }})
+	if err != nil {
+		t.Fatalf("NewSchemaRegistry: %v", err)
+	}
+
+	p := Property{SchemaVersion: 1}
+	if _, err := r.Upgrade(&p); !errors.Is(err, fail) {
+		t.Errorf("expected upgrader error, got %v", err)
+	}
+	if p.SchemaVersion != 1 {
+		t.Errorf("expected version to stay at 1, got %d", p.SchemaVersion)
+	}
+}
+
+func TestPropertyWritesCurrentSchema(t *testing.T) {
+	p := Property{SchemaVersion: 1}
+	p.BeforeCreate()
+	if p.SchemaVersion != currentSchemaVersion {
+		t.Errorf("expected created property at v%d, got v%d", currentSchemaVersion, p.SchemaVersion)
+	}
+
+	p.SchemaVersion = 99
+	p.BeforeUpdate()
+	if p.SchemaVersion != currentSchemaVersion {
+		t.Errorf("expected updated property at v%d, got v%d", currentSchemaVersion, p.SchemaVersion)
+	}
+}
+
+func TestNewSchemaRegistry(t *testing.T) {
+	noop := func(*Property) error { return nil }
+	tests := []struct {
+		name      string
+		first     int
+		current   int
+		upgraders []SchemaUpgrader
+		wantErr   bool
+	}{
+		{name: "single version", first: 3, current: 3},
+		{name: "contiguous chain", first: 1, current: 3, upgraders: []SchemaUpgrader{{From: 2, Upgrade: noop}, {From: 1, Upgrade: noop}}},
+		{name: "chain from first", first: 3, current: 4, upgraders: []SchemaUpgrader{{From: 3, Upgrade: noop}}},
+		{name: "missing upgrader", first: 1, current: 3, upgraders: []SchemaUpgrader{{From: 1, Upgrade: noop}}, wantErr: true},
+		{name: "duplicate upgrader", first: 1, current: 2, upgraders: []SchemaUpgrader{{From: 1, Upgrade: noop}, {From: 1, Upgrade: noop}}, wantErr: true},
+		{name: "upgrader from current", first: 1, current: 2, upgraders: []SchemaUpgrader{{From: 1, Upgrade: noop}, {From: 2, Upgrade: noop}}, wantErr: true},
+		{name: "upgrader before first", first: 3, current: 4, upgraders: []SchemaUpgrader{{From: 2, Upgrade: noop}, {From: 3, Upgrade: noop}}, wantErr: true},
+		{name: "first after current", first: 4, current: 3, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSchemaRegistry(tt.first, tt.current, tt.upgraders...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
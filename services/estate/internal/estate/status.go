@@ -0,0 +1,135 @@
+package estate
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pulap/pulap/pkg/lib/core"
+)
+
+// Property statuses. Keys match the options of the estate_status dictionary set.
+const (
+	StatusDraft     = "draft"
+	StatusAvailable = "available"
+	StatusReserved  = "reserved"
+	StatusSold      = "sold"
+	StatusRented    = "rented"
+	StatusInactive  = "inactive"
+)
+
+// StatusSetName is the dictionary set holding the property statuses.
+const StatusSetName = "estate_status"
+
+// PermissionStatusOverride allows transitions outside the lifecycle, such as
+// leaving sold. It is checked in the estate scope of the property.
+const PermissionStatusOverride = "estates:status_override"
+
+// MaxTransitionReasonLength bounds the free-text reason of a transition.
+const MaxTransitionReasonLength = 500
+
+// Statuses lists every property status in lifecycle order.
+var Statuses = []string{StatusDraft, StatusAvailable, StatusReserved, StatusSold, StatusRented, StatusInactive}
+
+// statusTransitions is the property lifecycle: the statuses reachable from
+// each status without an override. Sold is final.
+var statusTransitions = map[string][]string{
+	StatusDraft:     {StatusAvailable, StatusInactive},
+	StatusAvailable: {StatusReserved, StatusSold, StatusRented, StatusInactive, StatusDraft},
+	StatusReserved:  {StatusAvailable, StatusSold, StatusRented, StatusInactive},
+	StatusRented:    {StatusAvailable, StatusInactive},
+	StatusInactive:  {StatusAvailable, StatusDraft},
+	StatusSold:      {},
+}
+
+var (
+	// ErrInvalidStatus is returned for a status outside Statuses.
+	ErrInvalidStatus = errors.New("invalid status")
+	// ErrInvalidTransition is returned when the lifecycle does not allow a transition.
+	ErrInvalidTransition = errors.New("transition not allowed")
+	// ErrStatusConflict is returned by repositories when the stored status no
+	// longer matches the transition origin.
+	ErrStatusConflict = errors.New("status changed concurrently")
+)
+
+// StatusTransition records a property status change.
+type StatusTransition struct {
+	ID         uuid.UUID `json:"id"`
+	PropertyID uuid.UUID `json:"property_id"`
+	From       string    `json:"from"`
+	To         string    `json:"to"`
+	Reason     string    `json:"reason"`
+	Actor      string    `json:"actor"`
+	Override   bool      `json:"override"`
+	At         time.Time `json:"at"`
+}
+
+// IsStatus returns true if s is a known property status.
+func IsStatus(s string) bool {
+	return slices.Contains(Statuses, s)
+}
+
+// IsListedStatus returns true if properties in status s are on the market
+// and shown to the public, e.g. by the inquiry form and the media content.
+func IsListedStatus(s string) bool {
+	return s == StatusAvailable || s == StatusReserved
+}
+
+// AllowedTransitions returns the statuses reachable from status without an override.
+func AllowedTransitions(status string) []string {
+	return statusTransitions[status]
+}
+
+// CanTransition returns true if the lifecycle allows going from one status to another.
+func CanTransition(from, to string) bool {
+	return slices.Contains(statusTransitions[from], to)
+}
+
+// CheckTransition validates a status change. With override any change
+// between known statuses is accepted; callers are responsible for checking
+// PermissionStatusOverride.
+func CheckTransition(from, to string, override bool) error {
+	if !IsStatus(to) {
+		return fmt.Errorf("%w: %q", ErrInvalidStatus, to)
+	}
+	if from == to {
+		return fmt.Errorf("%w: property is already %s", ErrInvalidTransition, to)
+	}
+	if override || CanTransition(from, to) {
+		return nil
+	}
+
+	allowed := AllowedTransitions(from)
+	if len(allowed) == 0 {
+		return fmt.Errorf("%w: %s is final", ErrInvalidTransition, from)
+	}
+	return fmt.Errorf("%w: %s can only change to %s", ErrInvalidTransition, from, strings.Join(allowed, ", "))
+}
+
+// Transition moves the property to a new status and returns the transition
+// to record. The property is left unchanged when the transition is not allowed.
+func (p *Property) Transition(to, reason, actor string, override bool) (*StatusTransition, error) {
+	if err := CheckTransition(p.Status, to, override); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	t := &StatusTransition{
+		ID:         core.GenerateNewID(),
+		PropertyID: p.ID,
+		From:       p.Status,
+		To:         to,
+		Reason:     reason,
+		Actor:      actor,
+		Override:   override,
+		At:         now,
+	}
+
+	p.Status = to
+	p.UpdatedAt = now
+	p.UpdatedBy = actor
+	return t, nil
+}
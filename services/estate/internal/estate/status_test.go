@@ -0,0 +1,70 @@
+package estate
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckTransition(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+		override bool
+		wantErr  error
+	}{
+		{name: "draft to available", from: StatusDraft, to: StatusAvailable},
+		{name: "available to reserved", from: StatusAvailable, to: StatusReserved},
+		{name: "reserved to sold", from: StatusReserved, to: StatusSold},
+		{name: "reserved back to available", from: StatusReserved, to: StatusAvailable},
+		{name: "rented to available", from: StatusRented, to: StatusAvailable},
+		{name: "draft to sold", from: StatusDraft, to: StatusSold, wantErr: ErrInvalidTransition},
+		{name: "sold is final", from: StatusSold, to: StatusAvailable, wantErr: ErrInvalidTransition},
+		{name: "sold with override", from: StatusSold, to: StatusAvailable, override: true},
+		{name: "same status", from: StatusAvailable, to: StatusAvailable, override: true, wantErr: ErrInvalidTransition},
+		{name: "unknown status", from: StatusAvailable, to: "demolished", override: true, wantErr: ErrInvalidStatus},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckTransition(tt.from, tt.to, tt.override)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("CheckTransition(%s, %s, %v) = %v, want %v", tt.from, tt.to, tt.override, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLifecycleCoversStatuses(t *testing.T) {
+	for _, status := range Statuses {
+		if _, ok := statusTransitions[status]; !ok {
+			t.Errorf("status %q has no lifecycle entry", status)
+		}
+		for _, to := range AllowedTransitions(status) {
+			if !IsStatus(to) {
+				t.Errorf("status %q allows unknown status %q", status, to)
+			}
+		}
+	}
+}
+
+func TestPropertyTransition(t *testing.T) {
+	p := New()
+
+	tr, err := p.Transition(StatusReserved, "deposit received", "agent-1", false)
+	if err != nil {
+		t.Fatalf("Transition: %v", err)
+	}
+	if p.Status != StatusReserved || p.UpdatedBy != "agent-1" {
+		t.Errorf("property not updated: status %s, updated_by %s", p.Status, p.UpdatedBy)
+	}
+	if tr.PropertyID != p.ID || tr.From != StatusAvailable || tr.To != StatusReserved || tr.Reason != "deposit received" || tr.At.IsZero() {
+		t.Errorf("unexpected transition: %+v", tr)
+	}
+
+	if _, err := p.Transition(StatusDraft, "back to draft", "agent-1", false); !errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("expected ErrInvalidTransition, got %v", err)
+	}
+	if p.Status != StatusReserved {
+		t.Errorf("rejected transition changed status to %s", p.Status)
+	}
+}
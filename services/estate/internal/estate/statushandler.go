@@ -0,0 +1,205 @@
+package estate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/pulap/pulap/pkg/lib/core"
+)
+
+// TransitionRequest is the payload of POST /estates/{id}/transitions.
+type TransitionRequest struct {
+	To       string `json:"to"`
+	Reason   string `json:"reason"`
+	Override bool   `json:"override,omitempty"` // Required to leave the lifecycle, e.g. from sold
+}
+
+// StatusHistoryMeta describes a status history response.
+type StatusHistoryMeta struct {
+	Status  string   `json:"status"`
+	Allowed []string `json:"allowed"`
+	Count   int      `json:"count"`
+}
+
+// TransitionProperty handles POST /estates/{id}/transitions
+// Requires PermissionWrite on the property. Transitions outside the lifecycle need "override": true and the
+// estates:status_override permission on the property, granted like the other estate permissions.
+func (h *Handler) TransitionProperty(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "Handler.TransitionProperty")
+	defer finish()
+	log := h.log(r)
+	ctx := r.Context()
+
+	id, ok := h.parseIDParam(w, r, log)
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, MaxBodyBytes)
+	defer r.Body.Close()
+
+	var req TransitionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Debug("error decoding transition", "error", err)
+		core.RespondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	req.To = strings.ToLower(strings.TrimSpace(req.To))
+	req.Reason = strings.TrimSpace(req.Reason)
+	switch {
+	case !IsStatus(req.To):
+		core.RespondError(w, http.StatusBadRequest, "Status must be one of: "+strings.Join(Statuses, ", "))
+		return
+	case req.Reason == "":
+		core.RespondError(w, http.StatusBadRequest, "Reason is required")
+		return
+	case len(req.Reason) > MaxTransitionReasonLength:
+		core.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Reason cannot exceed %d characters", MaxTransitionReasonLength))
+		return
+	}
+
+	actor := requestActor(r)
+	if actor == "" {
+		core.RespondError(w, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	property, err := h.repo.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			core.RespondError(w, http.StatusNotFound, "Property not found")
+			return
+		}
+		log.Error("error loading property", "error", err, "id", id.String())
+		core.RespondError(w, http.StatusInternalServerError, "Could not retrieve property")
+		return
+	}
+	if !h.authorizeProperty(w, r, property, PermissionWrite) {
+		return
+	}
+
+	override := req.Override && !CanTransition(property.Status, req.To)
+	if override {
+		access, ok := h.access(w, r, PermissionStatusOverride)
+		if !ok {
+			return
+		}
+		if !access.Allows(property) {
+			log.Info("status override denied", "actor", actor, "id", id.String(), "from", property.Status, "to", req.To)
+			core.RespondError(w, http.StatusForbidden, fmt.Sprintf("Permission %s is required", PermissionStatusOverride))
+			return
+		}
+	}
+
+	transition, err := property.Transition(req.To, req.Reason, actor, override)
+	if err != nil {
+		core.RespondError(w, http.StatusConflict, capitalize(err.Error()))
+		return
+	}
+
+	if status, msg := h.checkStatusOption(ctx, req.To); status != 0 {
+		core.RespondError(w, status, msg)
+		return
+	}
+
+	if err := h.repo.Transition(ctx, transition); err != nil {
+		switch {
+		case errors.Is(err, ErrNotFound):
+			core.RespondError(w, http.StatusNotFound, "Property not found")
+		case errors.Is(err, ErrStatusConflict):
+			core.RespondError(w, http.StatusConflict, "Property status changed, reload and try again")
+		default:
+			log.Error("cannot transition property", "error", err, "id", id.String())
+			core.RespondError(w, http.StatusInternalServerError, "Could not change property status")
+		}
+		return
+	}
+
+	log.Info("property status changed", "id", id.String(), "from", transition.From, "to", transition.To, "actor", actor, "override", override)
+	w.WriteHeader(http.StatusCreated)
+	core.RespondSuccess(w, transition, core.RESTfulLinksFor(property)...)
+}
+
+// GetStatusHistory handles GET /estates/{id}/status-history
+func (h *Handler) GetStatusHistory(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "Handler.GetStatusHistory")
+	defer finish()
+	log := h.log(r)
+	ctx := r.Context()
+
+	id, ok := h.parseIDParam(w, r, log)
+	if !ok {
+		return
+	}
+
+	property, err := h.repo.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			core.RespondError(w, http.StatusNotFound, "Property not found")
+			return
+		}
+		log.Error("error loading property", "error", err, "id", id.String())
+		core.RespondError(w, http.StatusInternalServerError, "Could not retrieve property")
+		return
+	}
+	if !h.authorizeProperty(w, r, property, PermissionRead) {
+		return
+	}
+
+	history, err := h.repo.StatusHistory(ctx, id)
+	if err != nil {
+		log.Error("error loading status history", "error", err, "id", id.String())
+		core.RespondError(w, http.StatusInternalServerError, "Could not retrieve status history")
+		return
+	}
+	if history == nil {
+		history = []StatusTransition{}
+	}
+
+	allowed := AllowedTransitions(property.Status)
+	if allowed == nil {
+		allowed = []string{}
+	}
+	meta := StatusHistoryMeta{Status: property.Status, Allowed: allowed, Count: len(history)}
+	core.RespondSuccessWithMeta(w, history, meta, core.RESTfulLinksFor(property)...)
+}
+
+// checkStatusOption verifies the status is an active option of the
+// estate_status dictionary set, honoring the dictionary fail-open setting.
+func (h *Handler) checkStatusOption(ctx context.Context, status string) (int, string) {
+	options, err := h.dictClient.ListOptionsByParent(ctx, StatusSetName, nil)
+	if err != nil {
+		if h.xparams.Cfg().Dictionary.FailOpen {
+			h.xparams.Log().Info("dictionary unavailable, accepting status (fail-open)", "error", err)
+			return 0, ""
+		}
+		h.xparams.Log().Error("dictionary error", "error", err)
+		return http.StatusBadGateway, "Could not validate status"
+	}
+
+	for _, opt := range options {
+		if opt.Key == status && opt.Active {
+			return 0, ""
+		}
+	}
+	return http.StatusBadRequest, fmt.Sprintf("Status %s is not enabled in the dictionary", status)
+}
+
+// requestActor returns the authenticated user, or "" when the request is not
+// authenticated. Clients cannot name the actor themselves.
+func requestActor(r *http.Request) string {
+	userID, _ := core.GetUserIDFromContext(r.Context())
+	return userID
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,108 @@
+package estate
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pulap/pulap/pkg/lib/core"
+)
+
+// TextMeta describes a full-text search response.
+type TextMeta struct {
+	Query      string `json:"query"`
+	Locale     string `json:"locale"`
+	Total      int    `json:"total"`
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// SearchText handles GET /estates/search?q=..&locale=es
+// Results are ordered by relevance. Any ListProperties filter can be combined
+// with the text query; sort is not supported. Only the properties the user
+// may read are found.
+func (h *Handler) SearchText(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "Handler.SearchText")
+	defer finish()
+	log := h.log(r)
+	ctx := r.Context()
+
+	if h.searcher == nil {
+		core.RespondError(w, http.StatusServiceUnavailable, "Full-text search is not available")
+		return
+	}
+
+	values := r.URL.Query()
+	var errs []ValidationError
+
+	text := TextQuery{
+		Text:   strings.TrimSpace(values.Get("q")),
+		Locale: strings.ToLower(strings.TrimSpace(values.Get("locale"))),
+	}
+	if text.Text == "" {
+		errs = append(errs, ValidationError{Field: "q", Message: "q is required"})
+	}
+	if text.Locale == "" {
+		text.Locale = h.xparams.Cfg().Search.DefaultLocale
+	}
+	if !IsTextLocale(text.Locale) {
+		errs = append(errs, ValidationError{Field: "locale", Message: fmt.Sprintf("locale must be one of: %s", strings.Join(TextLocales, ", "))})
+	}
+
+	limit := DefaultTextLimit
+	if v := values.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			errs = append(errs, ValidationError{Field: "limit", Message: "limit must be a positive integer"})
+		}
+		limit = min(n, MaxSearchLimit)
+	}
+
+	if _, err := decodeTextCursor(values.Get("cursor")); err != nil {
+		errs = append(errs, ValidationError{Field: "cursor", Message: err.Error()})
+	}
+
+	filters := url.Values{}
+	for key, v := range values {
+		switch key {
+		case "q", "locale", "limit", "cursor":
+		case "sort":
+			errs = append(errs, ValidationError{Field: "sort", Message: "full-text results are ordered by relevance"})
+		default:
+			filters[key] = v
+		}
+	}
+	filter, filterErrs := ParsePropertyQuery(filters)
+	errs = append(errs, filterErrs...)
+
+	if len(errs) > 0 {
+		log.Debug("invalid text search query", "errors", errs)
+		core.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid query: %s", errs[0].Message))
+		return
+	}
+
+	access, ok := h.access(w, r, PermissionRead)
+	if !ok {
+		return
+	}
+	access.Restrict(&filter)
+
+	page, err := SearchText(ctx, h.repo, h.searcher, text, filter, limit, values.Get("cursor"))
+	if err != nil {
+		log.Error("error running text search", "error", err)
+		core.RespondError(w, http.StatusInternalServerError, "Could not search properties")
+		return
+	}
+
+	meta := TextMeta{Query: text.Text, Locale: text.Locale, Total: page.Total, Limit: limit, NextCursor: page.NextCursor}
+	links := core.CollectionLinksFor("estate")
+	if page.NextCursor != "" {
+		next := r.URL.Query()
+		next.Set("cursor", page.NextCursor)
+		links = append(links, core.Link{Rel: core.RelNext, Href: r.URL.Path + "?" + next.Encode()})
+	}
+
+	core.RespondSuccessWithMeta(w, page.Items, meta, links...)
+}
@@ -0,0 +1,156 @@
+package estate
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultTextLimit is the page size of full-text results when a query does not set one.
+	DefaultTextLimit = 20
+	// MaxTextHits caps how many ranked hits are considered before filtering and paginating.
+	MaxTextHits = 1000
+)
+
+// TextLocales lists the locales with a dedicated full-text analyzer.
+var TextLocales = []string{"en", "es", "pl"}
+
+// TextQuery is a full-text query over property name, description and address.
+type TextQuery struct {
+	Text   string
+	Locale string // One of TextLocales
+	Limit  int    // Maximum number of hits
+}
+
+// TextHit is a property matched by a full-text query.
+type TextHit struct {
+	ID         uuid.UUID
+	Score      float64
+	Highlights map[string][]string // Highlighted fragments by field: name, description, address
+}
+
+// TextSearcher runs full-text queries against an index of properties.
+type TextSearcher interface {
+	SearchText(ctx context.Context, query TextQuery) ([]TextHit, error)
+}
+
+// TextResult is a property returned by a full-text search.
+type TextResult struct {
+	Property   *Property           `json:"property"`
+	Score      float64             `json:"score"`
+	Highlights map[string][]string `json:"highlights,omitempty"`
+}
+
+// TextPage is a page of full-text results ordered by relevance.
+type TextPage struct {
+	Items      []TextResult
+	Total      int
+	NextCursor string
+}
+
+// IsTextLocale returns true if the locale has a full-text analyzer.
+func IsTextLocale(locale string) bool {
+	for _, l := range TextLocales {
+		if l == locale {
+			return true
+		}
+	}
+	return false
+}
+
+// SearchText ranks properties with the full-text searcher and keeps those that
+// also match the structured filter. Results keep relevance order and are
+// paginated with an offset cursor; filter sort and cursor are ignored.
+func SearchText(ctx context.Context, repo Repo, searcher TextSearcher, text TextQuery, filter PropertyQuery, limit int, cursor string) (*TextPage, error) {
+	offset, err := decodeTextCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	text.Limit = MaxTextHits
+	hits, err := searcher.SearchText(ctx, text)
+	if err != nil {
+		return nil, fmt.Errorf("could not run full-text search: %w", err)
+	}
+
+	matched, err := filterHits(ctx, repo, hits, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &TextPage{Items: []TextResult{}, Total: len(matched)}
+	if offset >= len(matched) {
+		return page, nil
+	}
+
+	end := offset + limit
+	if end < len(matched) {
+		page.NextCursor = encodeTextCursor(end)
+	} else {
+		end = len(matched)
+	}
+	page.Items = matched[offset:end]
+
+	return page, nil
+}
+
+// filterHits loads the hit properties through the repository, applying the
+// structured filter, and returns the survivors in hit order.
+func filterHits(ctx context.Context, repo Repo, hits []TextHit, filter PropertyQuery) ([]TextResult, error) {
+	found := make(map[uuid.UUID]*Property, len(hits))
+
+	for start := 0; start < len(hits); start += MaxSearchLimit {
+		end := min(start+MaxSearchLimit, len(hits))
+
+		q := filter
+		q.IDs = make([]uuid.UUID, 0, end-start)
+		for _, hit := range hits[start:end] {
+			q.IDs = append(q.IDs, hit.ID)
+		}
+		q.Sort = nil
+		q.Cursor = ""
+		q.Limit = MaxSearchLimit
+		if errs := q.Normalize(); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid filter: %s", errs[0].Message)
+		}
+
+		page, err := repo.Search(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range page.Items {
+			found[p.ID] = p
+		}
+	}
+
+	results := make([]TextResult, 0, len(found))
+	for _, hit := range hits {
+		if p, ok := found[hit.ID]; ok {
+			results = append(results, TextResult{Property: p, Score: hit.Score, Highlights: hit.Highlights})
+		}
+	}
+	return results, nil
+}
+
+func encodeTextCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset)))
+}
+
+func decodeTextCursor(cursor string) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, fmt.Errorf("invalid cursor encoding")
+	}
+	offset, err := strconv.Atoi(string(data))
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("invalid cursor")
+	}
+	return offset, nil
+}
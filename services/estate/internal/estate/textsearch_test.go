@@ -0,0 +1,113 @@
+package estate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// idRepo answers Search with the stored properties whose ID is requested,
+// in storage order, optionally restricted to one status.
+type idRepo struct {
+	Repo
+	properties []*Property
+}
+
+func (r *idRepo) Search(ctx context.Context, q PropertyQuery) (*PropertyPage, error) {
+	wanted := make(map[uuid.UUID]bool, len(q.IDs))
+	for _, id := range q.IDs {
+		wanted[id] = true
+	}
+
+	page := &PropertyPage{}
+	for _, p := range r.properties {
+		if !wanted[p.ID] {
+			continue
+		}
+		if len(q.Statuses) > 0 && p.Status != q.Statuses[0] {
+			continue
+		}
+		page.Items = append(page.Items, p)
+	}
+	page.Total = int64(len(page.Items))
+	return page, nil
+}
+
+type fixedSearcher []TextHit
+
+func (s fixedSearcher) SearchText(ctx context.Context, q TextQuery) ([]TextHit, error) {
+	return s, nil
+}
+
+func TestSearchText(t *testing.T) {
+	a, b, c, d := New(), New(), New(), New()
+	b.Status = "sold"
+	repo := &idRepo{properties: []*Property{d, c, b, a}}
+	searcher := fixedSearcher{
+		{ID: a.ID, Score: 4, Highlights: map[string][]string{"name": {"<mark>a</mark>"}}},
+		{ID: b.ID, Score: 3},
+		{ID: c.ID, Score: 2},
+		{ID: d.ID, Score: 1},
+	}
+	filter := PropertyQuery{Statuses: []string{"available"}}
+	ctx := context.Background()
+
+	var got []*Property
+	cursor := ""
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatal("pagination did not terminate")
+		}
+		page, err := SearchText(ctx, repo, searcher, TextQuery{Text: "x", Locale: "es"}, filter, 2, cursor)
+		if err != nil {
+			t.Fatalf("SearchText: %v", err)
+		}
+		if page.Total != 3 {
+			t.Errorf("expected total 3, got %d", page.Total)
+		}
+		for _, item := range page.Items {
+			got = append(got, item.Property)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+
+	want := []*Property{a, c, d}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d results, got %d", len(want), len(got))
+	}
+	for i := range want {
+		if got[i].ID != want[i].ID {
+			t.Errorf("result %d: expected %s, got %s", i, want[i].ID, got[i].ID)
+		}
+	}
+}
+
+func TestSearchTextKeepsScoreAndHighlights(t *testing.T) {
+	p := New()
+	repo := &idRepo{properties: []*Property{p}}
+	searcher := fixedSearcher{{ID: p.ID, Score: 1.5, Highlights: map[string][]string{"name": {"<mark>piso</mark>"}}}}
+
+	page, err := SearchText(context.Background(), repo, searcher, TextQuery{Text: "piso", Locale: "es"}, PropertyQuery{}, 10, "")
+	if err != nil {
+		t.Fatalf("SearchText: %v", err)
+	}
+	if len(page.Items) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(page.Items))
+	}
+	if r := page.Items[0]; r.Score != 1.5 || r.Highlights["name"][0] != "<mark>piso</mark>" {
+		t.Errorf("unexpected result: %+v", r)
+	}
+}
+
+func TestSearchTextInvalidCursor(t *testing.T) {
+	for _, cursor := range []string{"!!", encodeTextCursor(-1)} {
+		_, err := SearchText(context.Background(), &idRepo{}, fixedSearcher{}, TextQuery{}, PropertyQuery{}, 10, cursor)
+		if err == nil {
+			t.Errorf("expected error for cursor %q", cursor)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package estate
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pulap/pulap/pkg/lib/core"
+)
+
+// Trash permanently removes properties that stay in the trash longer than
+// the retention period, checking every interval. Their media blobs are
+// removed with them, since restoring a property restores its media.
+type Trash struct {
+	repo      Repo
+	media     *MediaLibrary // nil when the repository keeps no media
+	retention time.Duration
+	interval  time.Duration
+	log       core.Logger
+	now       func() time.Time
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewTrash returns the trash purged every interval of the properties deleted
+// more than retention ago. A zero retention keeps deleted properties until
+// they are restored.
+func NewTrash(repo Repo, media *MediaLibrary, retention, interval time.Duration, log core.Logger) (*Trash, error) {
+	if retention < 0 {
+		return nil, fmt.Errorf("invalid trash retention %s", retention)
+	}
+	if retention > 0 && interval <= 0 {
+		return nil, fmt.Errorf("invalid trash purge interval %s", interval)
+	}
+	return &Trash{
+		repo:      repo,
+		media:     media,
+		retention: retention,
+		interval:  interval,
+		log:       log,
+		now:       time.Now,
+	}, nil
+}
+
+// Retention returns how long deleted properties are kept, zero for ever.
+func (t *Trash) Retention() time.Duration {
+	return t.retention
+}
+
+// Start purges the trash in the background, then every interval.
+func (t *Trash) Start(ctx context.Context) error {
+	if t.retention == 0 {
+		return nil
+	}
+
+	workerCtx, cancel := context.WithCancel(context.Background())
+	t.cancel = cancel
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		ticker := time.NewTicker(t.interval)
+		defer ticker.Stop()
+		for {
+			if _, err := t.Purge(workerCtx); err != nil && workerCtx.Err() == nil {
+				t.log.Error("cannot purge trash", "error", err)
+			}
+			select {
+			case <-workerCtx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop stops the purge, waiting for a running one to be interrupted.
+func (t *Trash) Stop(ctx context.Context) error {
+	if t.cancel == nil {
+		return nil
+	}
+	t.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		t.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Purge permanently removes the properties deleted more than the retention
+// period ago and returns how many were removed. Media blobs that cannot be
+// removed are logged and left behind.
+func (t *Trash) Purge(ctx context.Context) (int, error) {
+	if t.retention == 0 {
+		return 0, nil
+	}
+
+	ids, err := t.repo.Purge(ctx, t.now().Add(-t.retention))
+	if err != nil {
+		return 0, err
+	}
+
+	if t.media != nil {
+		for _, id := range ids {
+			if err := t.media.Purge(ctx, id); err != nil {
+				t.log.Error("cannot purge property media", "error", err, "id", id.String())
+			}
+		}
+	}
+	if len(ids) > 0 {
+		t.log.Info("purged trash", "count", len(ids))
+	}
+	return len(ids), nil
+}
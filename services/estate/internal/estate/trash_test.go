@@ -0,0 +1,81 @@
+package estate
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/pkg/lib/core"
+)
+
+func TestNewTrashInvalid(t *testing.T) {
+	if _, err := NewTrash(nil, nil, -time.Hour, time.Hour, core.NewNoopLogger()); err == nil {
+		t.Error("expected error for a negative retention")
+	}
+	if _, err := NewTrash(nil, nil, time.Hour, 0, core.NewNoopLogger()); err == nil {
+		t.Error("expected error for a zero purge interval")
+	}
+	if _, err := NewTrash(nil, nil, 0, 0, core.NewNoopLogger()); err != nil {
+		t.Errorf("expected no purge interval needed without retention, got %v", err)
+	}
+}
+
+func TestTrashPurge(t *testing.T) {
+	lib, _, store := newTestMediaLibrary(1 << 20)
+	purged, kept := uuid.New(), uuid.New()
+	uploadTestMedia(t, lib, purged, MediaPhoto)
+	uploadTestMedia(t, lib, kept, MediaPhoto)
+
+	repo := &purgeTestRepo{purged: []uuid.UUID{purged}}
+	trash, err := NewTrash(repo, lib, 30*24*time.Hour, time.Hour, core.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewTrash: %v", err)
+	}
+	now := time.Date(2024, 5, 31, 12, 0, 0, 0, time.UTC)
+	trash.now = func() time.Time { return now }
+
+	n, err := trash.Purge(context.Background())
+	if err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("expected 1 purged, got %d", n)
+	}
+	if want := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC); !repo.before.Equal(want) {
+		t.Errorf("expected purge before %s, got %s", want, repo.before)
+	}
+	for key := range store.blobs {
+		if strings.HasPrefix(key, MediaPrefix(purged)) {
+			t.Errorf("blob %s not purged", key)
+		}
+	}
+	if len(store.blobs) != 1+len(ThumbnailSizes) {
+		t.Errorf("expected blobs of the kept property to remain, got %d", len(store.blobs))
+	}
+}
+
+func TestTrashPurgeWithoutRetention(t *testing.T) {
+	repo := &purgeTestRepo{purged: []uuid.UUID{uuid.New()}}
+	trash, err := NewTrash(repo, nil, 0, 0, core.NewNoopLogger())
+	if err != nil {
+		t.Fatalf("NewTrash: %v", err)
+	}
+	if n, err := trash.Purge(context.Background()); err != nil || n != 0 || !repo.before.IsZero() {
+		t.Errorf("expected nothing purged, got %d, %v (before %s)", n, err, repo.before)
+	}
+}
+
+// purgeTestRepo records the purge cutoff and reports the purged properties.
+type purgeTestRepo struct {
+	Repo
+	purged []uuid.UUID
+	before time.Time
+}
+
+func (r *purgeTestRepo) Purge(ctx context.Context, before time.Time) ([]uuid.UUID, error) {
+	r.before = before
+	return r.purged, nil
+}
@@ -0,0 +1,147 @@
+package estate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/pkg/lib/core"
+)
+
+// TrashMeta is the pagination metadata returned with the trash.
+type TrashMeta struct {
+	PageMeta
+	RetentionDays int `json:"retention_days"` // Days deleted properties are kept, 0 until restored
+}
+
+// ListTrash handles GET /estates/trash
+// Deleted properties are listed most recently deleted first. See
+// ParseTrashQuery for the supported parameters. Only the properties the user
+// may delete, and so restore, are listed.
+func (h *Handler) ListTrash(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "Handler.ListTrash")
+	defer finish()
+	log := h.log(r)
+	ctx := r.Context()
+
+	query, validationErrors := ParseTrashQuery(r.URL.Query())
+	if len(validationErrors) > 0 {
+		log.Debug("invalid trash query", "errors", validationErrors)
+		core.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid query: %s", validationErrors[0].Message))
+		return
+	}
+
+	access, ok := h.access(w, r, PermissionDelete)
+	if !ok {
+		return
+	}
+	access.Restrict(&query)
+
+	page, err := h.repo.Search(ctx, query)
+	if err != nil {
+		log.Error("error searching trash", "error", err)
+		core.RespondError(w, http.StatusInternalServerError, "Could not retrieve deleted properties")
+		return
+	}
+
+	items := page.Items
+	if items == nil {
+		items = []*Property{}
+	}
+
+	meta := TrashMeta{PageMeta: PageMeta{Total: page.Total, Limit: query.Limit, NextCursor: page.NextCursor}}
+	if h.trash != nil {
+		meta.RetentionDays = int(h.trash.Retention().Hours() / 24)
+	}
+	var links []core.Link
+	if page.NextCursor != "" {
+		next := r.URL.Query()
+		next.Set("cursor", page.NextCursor)
+		links = append(links, core.Link{Rel: core.RelNext, Href: r.URL.Path + "?" + next.Encode()})
+	}
+
+	core.RespondSuccessWithMeta(w, items, meta, links...)
+}
+
+// RestoreProperty handles POST /estates/{id}/restore
+// The property is taken out of the trash as it was deleted, recorded as an
+// "undelete" revision. Requires PermissionDelete on the property. An
+// If-Match header makes the restore conditional on the revision of the
+// deleted property.
+func (h *Handler) RestoreProperty(w http.ResponseWriter, r *http.Request) {
+	w, r, finish := h.tlm.Start(w, r, "Handler.RestoreProperty")
+	defer finish()
+	log := h.log(r)
+	ctx := r.Context()
+
+	id, ok := h.parseIDParam(w, r, log)
+	if !ok {
+		return
+	}
+
+	deleted, err := h.getDeleted(ctx, id)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			core.RespondError(w, http.StatusNotFound, "Property not found in trash")
+			return
+		}
+		log.Error("error loading deleted property", "error", err, "id", id.String())
+		core.RespondError(w, http.StatusInternalServerError, "Could not retrieve property")
+		return
+	}
+	if !h.authorizeProperty(w, r, deleted, PermissionDelete) {
+		return
+	}
+
+	var revision int64
+	if r.Header.Get("If-Match") != "" {
+		if !h.checkIfMatch(w, r, deleted) {
+			return
+		}
+		revision = deleted.Revision
+	}
+
+	ctx = WithActor(ctx, requestActor(r))
+	if err := h.repo.Undelete(ctx, id, revision); err != nil {
+		switch {
+		case errors.Is(err, ErrNotFound):
+			core.RespondError(w, http.StatusNotFound, "Property not found in trash")
+		case errors.Is(err, ErrRevisionConflict):
+			h.respondRevisionConflict(w, r)
+		default:
+			log.Error("cannot restore property", "error", err, "id", id.String())
+			core.RespondError(w, http.StatusInternalServerError, "Could not restore property")
+		}
+		return
+	}
+
+	property, err := h.repo.Get(ctx, id)
+	if err != nil {
+		log.Error("error loading restored property", "error", err, "id", id.String())
+		core.RespondError(w, http.StatusInternalServerError, "Could not retrieve property")
+		return
+	}
+
+	log.Info("restored property from trash", "id", id.String(), "actor", ActorFrom(ctx))
+	links := core.RESTfulLinksFor(property)
+	w.Header().Set("ETag", ETag(property.Revision))
+	core.RespondSuccess(w, property, links...)
+}
+
+// getDeleted retrieves a property in the trash.
+func (h *Handler) getDeleted(ctx context.Context, id uuid.UUID) (*Property, error) {
+	query := PropertyQuery{IDs: []uuid.UUID{id}, Deleted: true, Limit: 1}
+	query.Normalize()
+
+	page, err := h.repo.Search(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if len(page.Items) == 0 {
+		return nil, fmt.Errorf("Property aggregate with ID %s: %w", id, ErrNotFound)
+	}
+	return page.Items[0], nil
+}
@@ -3,6 +3,7 @@ package estate
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/google/uuid"
 )
@@ -94,21 +95,11 @@ func ValidateCreateProperty(ctx context.Context, property *Property) []Validatio
 	}
 
 	// Status should be valid
-	if property.Status != "" {
-		validStatuses := map[string]bool{
-			"available": true,
-			"sold":      true,
-			"rented":    true,
-			"reserved":  true,
-			"draft":     true,
-			"inactive":  true,
-		}
-		if !validStatuses[property.Status] {
-			errors = append(errors, ValidationError{
-				Field:   "status",
-				Message: "Status must be one of: available, sold, rented, reserved, draft, inactive",
-			})
-		}
+	if property.Status != "" && !IsStatus(property.Status) {
+		errors = append(errors, ValidationError{
+			Field:   "status",
+			Message: "Status must be one of: " + strings.Join(Statuses, ", "),
+		})
 	}
 
 	// CreatedAt should be set
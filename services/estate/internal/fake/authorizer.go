@@ -0,0 +1,29 @@
+package fake
+
+import (
+	"context"
+
+	"github.com/pulap/pulap/pkg/lib/core"
+)
+
+// Authorizer is a fake authorizer for testing and development.
+// It grants every permission to any identified user.
+type Authorizer struct{}
+
+// NewAuthorizer creates a new fake Authorizer.
+func NewAuthorizer() *Authorizer {
+	return &Authorizer{}
+}
+
+// CheckPermission returns true for any non-empty user ID.
+func (a *Authorizer) CheckPermission(ctx context.Context, userID, permission, resource string) (bool, error) {
+	return userID != "", nil
+}
+
+// ListScopes returns the global scope for any non-empty user ID.
+func (a *Authorizer) ListScopes(ctx context.Context, userID, permission, scopeType string) ([]core.AuthzScope, error) {
+	if userID == "" {
+		return []core.AuthzScope{}, nil
+	}
+	return []core.AuthzScope{{Type: "global"}}, nil
+}
@@ -117,7 +117,7 @@ func (d *Dictionary) seedData() {
 	// Create sets
 	categorySet := &estate.Set{
 		ID:        uuid.MustParse("00000000-0000-0000-0000-000000000001"),
-		Name:      "estate_category",
+		Name:      estate.CategorySetName,
 		Label:     "Estate Category",
 		Active:    true,
 		CreatedAt: now,
@@ -125,7 +125,7 @@ func (d *Dictionary) seedData() {
 	}
 	typeSet := &estate.Set{
 		ID:        uuid.MustParse("00000000-0000-0000-0000-000000000002"),
-		Name:      "estate_type",
+		Name:      estate.TypeSetName,
 		Label:     "Estate Type",
 		Active:    true,
 		CreatedAt: now,
@@ -133,16 +133,25 @@ func (d *Dictionary) seedData() {
 	}
 	subtypeSet := &estate.Set{
 		ID:        uuid.MustParse("00000000-0000-0000-0000-000000000003"),
-		Name:      "estate_subtype",
+		Name:      estate.SubtypeSetName,
 		Label:     "Estate Subtype",
 		Active:    true,
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
+	statusSet := &estate.Set{
+		ID:        uuid.MustParse("00000000-0000-0000-0000-000000000004"),
+		Name:      estate.StatusSetName,
+		Label:     "Estate Status",
+		Active:    true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
 
-	d.sets["estate_category"] = categorySet
-	d.sets["estate_type"] = typeSet
-	d.sets["estate_subtype"] = subtypeSet
+	d.sets[estate.CategorySetName] = categorySet
+	d.sets[estate.TypeSetName] = typeSet
+	d.sets[estate.SubtypeSetName] = subtypeSet
+	d.sets[estate.StatusSetName] = statusSet
 
 	// Categories (no parent)
 	residential := d.addOption("00000000-0000-0000-0001-000000000001", categorySet.ID, nil, "res", "residential", "Residential", "Residential", 1)
@@ -163,6 +172,14 @@ func (d *Dictionary) seedData() {
 	d.addOption("00000000-0000-0000-0003-000000000002", subtypeSet.ID, &apartment, "loft", "loft", "Loft", "Loft", 2)
 	d.addOption("00000000-0000-0000-0003-000000000003", subtypeSet.ID, &retail, "shw", "showroom", "Showroom", "Showroom", 3)
 
+	// Statuses (no parent)
+	d.addOption("00000000-0000-0000-0004-000000000001", statusSet.ID, nil, "avl", "available", "Available", "Available", 1)
+	d.addOption("00000000-0000-0000-0004-000000000002", statusSet.ID, nil, "sld", "sold", "Sold", "Sold", 2)
+	d.addOption("00000000-0000-0000-0004-000000000003", statusSet.ID, nil, "rnt", "rented", "Rented", "Rented", 3)
+	d.addOption("00000000-0000-0000-0004-000000000004", statusSet.ID, nil, "rsv", "reserved", "Reserved", "Reserved", 4)
+	d.addOption("00000000-0000-0000-0004-000000000005", statusSet.ID, nil, "drf", "draft", "Draft", "Draft", 5)
+	d.addOption("00000000-0000-0000-0004-000000000006", statusSet.ID, nil, "ina", "inactive", "Inactive", "Inactive", 6)
+
 	// Prevent unused variable warnings
 	_ = land
 	_ = agricultural
@@ -220,3 +220,25 @@ func TestFakeValidateClassificationNonExistentIDs(t *testing.T) {
 		t.Error("expected validation errors for non-existent category")
 	}
 }
+
+func TestFakeStatusesMatchLifecycle(t *testing.T) {
+	fake := NewDictionary()
+
+	options, err := fake.ListOptionsByParent(context.Background(), estate.StatusSetName, nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	keys := make(map[string]bool, len(options))
+	for _, opt := range options {
+		keys[opt.Key] = true
+	}
+	if len(keys) != len(estate.Statuses) {
+		t.Errorf("expected %d statuses, got %d", len(estate.Statuses), len(keys))
+	}
+	for _, status := range estate.Statuses {
+		if !keys[status] {
+			t.Errorf("status %q missing from the %s set", status, estate.StatusSetName)
+		}
+	}
+}
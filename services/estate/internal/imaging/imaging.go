@@ -0,0 +1,160 @@
+// Package imaging prepares uploaded photos for publishing: it removes
+// embedded metadata such as EXIF (GPS position, camera serials), applies the
+// EXIF orientation and renders thumbnails. It only depends on the standard
+// library decoders, so JPEG, PNG and GIF are supported.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // Registers the GIF decoder
+	"image/jpeg"
+	"image/png"
+	"sort"
+)
+
+// MaxPixels bounds the decoded size of an image, protecting against small
+// files that expand to huge bitmaps.
+const MaxPixels = 50_000_000
+
+var (
+	// ErrUnsupported is returned for content that is not a JPEG, PNG or GIF.
+	ErrUnsupported = errors.New("unsupported image format")
+
+	// ErrTooLarge is returned for images above MaxPixels.
+	ErrTooLarge = errors.New("image dimensions too large")
+)
+
+// Size is a thumbnail variant, bounded by MaxSide on its longest side.
+type Size struct {
+	Name    string
+	MaxSide int
+}
+
+// Image is a processed image.
+type Image struct {
+	Data        []byte
+	ContentType string
+	Width       int
+	Height      int
+}
+
+// Thumbnail is a rendered thumbnail variant.
+type Thumbnail struct {
+	Image
+	Name string
+}
+
+// Result is the outcome of Process.
+type Result struct {
+	Original   Image
+	Thumbnails []Thumbnail
+}
+
+// Process strips metadata from data and renders one thumbnail per size.
+// Thumbnails are never larger than the original. JPEG sources produce JPEG
+// thumbnails; PNG and GIF sources produce PNG thumbnails to keep transparency.
+func Process(data []byte, sizes []Size) (*Result, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupported, err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > MaxPixels {
+		return nil, fmt.Errorf("%w: %dx%d", ErrTooLarge, cfg.Width, cfg.Height)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode %s image: %w", format, err)
+	}
+
+	var original Image
+	switch format {
+	case "jpeg":
+		if o := jpegOrientation(data); o > 1 {
+			// Re-encoding applies the orientation and drops every metadata segment.
+			img = orient(img, o)
+			if original.Data, err = encodeJPEG(img); err != nil {
+				return nil, err
+			}
+		} else if original.Data, err = stripJPEG(data); err != nil {
+			return nil, err
+		}
+		original.ContentType = "image/jpeg"
+	case "png":
+		if original.Data, err = stripPNG(data); err != nil {
+			return nil, err
+		}
+		original.ContentType = "image/png"
+	case "gif":
+		// GIF has no EXIF; keeping the bytes preserves animations.
+		original.Data = data
+		original.ContentType = "image/gif"
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupported, format)
+	}
+	b := img.Bounds()
+	original.Width, original.Height = b.Dx(), b.Dy()
+
+	result := &Result{Original: original}
+
+	// Render from the largest size down, each from the previous one, so only
+	// the first thumbnail is scaled from the full image.
+	ordered := append([]Size(nil), sizes...)
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].MaxSide > ordered[j].MaxSide })
+
+	src := img
+	thumbs := make(map[string]Thumbnail, len(ordered))
+	for _, size := range ordered {
+		w, h := fit(b.Dx(), b.Dy(), size.MaxSide)
+		src = resize(src, w, h)
+
+		thumb := Thumbnail{Name: size.Name, Image: Image{Width: w, Height: h}}
+		if format == "jpeg" {
+			thumb.Data, err = encodeJPEG(src)
+			thumb.ContentType = "image/jpeg"
+		} else {
+			thumb.Data, err = encodePNG(src)
+			thumb.ContentType = "image/png"
+		}
+		if err != nil {
+			return nil, err
+		}
+		thumbs[size.Name] = thumb
+	}
+	for _, size := range sizes {
+		result.Thumbnails = append(result.Thumbnails, thumbs[size.Name])
+	}
+
+	return result, nil
+}
+
+// fit returns the dimensions of a w×h image scaled down so that its longest
+// side is at most maxSide.
+func fit(w, h, maxSide int) (int, int) {
+	if w <= maxSide && h <= maxSide {
+		return w, h
+	}
+	if w >= h {
+		return maxSide, max(1, h*maxSide/w)
+	}
+	return max(1, w*maxSide/h), maxSide
+}
+
+func encodeJPEG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85}); err != nil {
+		return nil, fmt.Errorf("cannot encode jpeg: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func encodePNG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("cannot encode png: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,223 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func TestProcessStripsEXIF(t *testing.T) {
+	tests := []struct {
+		name        string
+		orientation uint16
+		wantW       int
+		wantH       int
+	}{
+		{name: "upright", orientation: 1, wantW: 40, wantH: 20},
+		{name: "rotated", orientation: 6, wantW: 20, wantH: 40},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := withEXIF(t, encode(t, "jpeg", 40, 20), tt.orientation)
+			if jpegOrientation(data) != int(tt.orientation) {
+				t.Fatalf("fixture orientation = %d", jpegOrientation(data))
+			}
+
+			result, err := Process(data, nil)
+			if err != nil {
+				t.Fatalf("Process: %v", err)
+			}
+			if bytes.Contains(result.Original.Data, []byte("Exif\x00\x00")) {
+				t.Error("EXIF segment not removed")
+			}
+			if result.Original.ContentType != "image/jpeg" {
+				t.Errorf("content type = %s", result.Original.ContentType)
+			}
+			cfg, err := jpeg.DecodeConfig(bytes.NewReader(result.Original.Data))
+			if err != nil {
+				t.Fatalf("decode stripped: %v", err)
+			}
+			if cfg.Width != tt.wantW || cfg.Height != tt.wantH {
+				t.Errorf("got %dx%d, want %dx%d", cfg.Width, cfg.Height, tt.wantW, tt.wantH)
+			}
+			if result.Original.Width != tt.wantW || result.Original.Height != tt.wantH {
+				t.Errorf("reported %dx%d, want %dx%d", result.Original.Width, result.Original.Height, tt.wantW, tt.wantH)
+			}
+		})
+	}
+}
+
+func TestProcessStripsPNGText(t *testing.T) {
+	data := encode(t, "png", 10, 10)
+	// Insert a tEXt chunk after IHDR (8 byte signature + 25 byte IHDR chunk).
+	text := pngChunk("tEXt", []byte("Comment\x00taken at home"))
+	data = append(append(append([]byte{}, data[:33]...), text...), data[33:]...)
+
+	result, err := Process(data, nil)
+	if err != nil {
+		t.Fatalf("Process: %v", err)
+	}
+	if bytes.Contains(result.Original.Data, []byte("taken at home")) {
+		t.Error("tEXt chunk not removed")
+	}
+	if _, err := png.Decode(bytes.NewReader(result.Original.Data)); err != nil {
+		t.Errorf("stripped png does not decode: %v", err)
+	}
+}
+
+func TestProcessThumbnails(t *testing.T) {
+	sizes := []Size{{Name: "small", MaxSide: 16}, {Name: "large", MaxSide: 64}, {Name: "medium", MaxSide: 32}}
+
+	tests := []struct {
+		format      string
+		contentType string
+		w, h        int
+		want        map[string][2]int
+	}{
+		{
+			format: "jpeg", contentType: "image/jpeg", w: 48, h: 24,
+			want: map[string][2]int{"small": {16, 8}, "medium": {32, 16}, "large": {48, 24}},
+		},
+		{
+			format: "png", contentType: "image/png", w: 10, h: 100,
+			want: map[string][2]int{"small": {1, 16}, "medium": {3, 32}, "large": {6, 64}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			result, err := Process(encode(t, tt.format, tt.w, tt.h), sizes)
+			if err != nil {
+				t.Fatalf("Process: %v", err)
+			}
+			if len(result.Thumbnails) != len(sizes) {
+				t.Fatalf("expected %d thumbnails, got %d", len(sizes), len(result.Thumbnails))
+			}
+			for i, thumb := range result.Thumbnails {
+				if thumb.Name != sizes[i].Name {
+					t.Errorf("thumbnail %d is %s, want %s", i, thumb.Name, sizes[i].Name)
+				}
+				want := tt.want[thumb.Name]
+				if thumb.Width != want[0] || thumb.Height != want[1] || thumb.ContentType != tt.contentType {
+					t.Errorf("%s: got %dx%d %s, want %dx%d %s", thumb.Name, thumb.Width, thumb.Height, thumb.ContentType, want[0], want[1], tt.contentType)
+				}
+				img, _, err := image.Decode(bytes.NewReader(thumb.Data))
+				if err != nil {
+					t.Fatalf("%s: decode: %v", thumb.Name, err)
+				}
+				if b := img.Bounds(); b.Dx() != want[0] || b.Dy() != want[1] {
+					t.Errorf("%s: encoded as %dx%d", thumb.Name, b.Dx(), b.Dy())
+				}
+			}
+		})
+	}
+}
+
+func TestProcessRejects(t *testing.T) {
+	if _, err := Process([]byte("%PDF-1.7 not an image"), nil); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("expected ErrUnsupported, got %v", err)
+	}
+
+	// A PNG header claiming huge dimensions is rejected before decoding.
+	huge := encode(t, "png", 1, 1)
+	binary.BigEndian.PutUint32(huge[16:], 100_000)
+	binary.BigEndian.PutUint32(huge[20:], 100_000)
+	binary.BigEndian.PutUint32(huge[29:], crc32.ChecksumIEEE(huge[12:29]))
+	if _, err := Process(huge, nil); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("expected ErrTooLarge, got %v", err)
+	}
+}
+
+func TestOrient(t *testing.T) {
+	// 2x1 image: red, blue.
+	src := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	red := color.RGBA{R: 255, A: 255}
+	blue := color.RGBA{B: 255, A: 255}
+	src.Set(0, 0, red)
+	src.Set(1, 0, blue)
+
+	tests := []struct {
+		orientation int
+		want        [][]color.RGBA // rows
+	}{
+		{1, [][]color.RGBA{{red, blue}}},
+		{2, [][]color.RGBA{{blue, red}}},
+		{3, [][]color.RGBA{{blue, red}}},
+		{4, [][]color.RGBA{{red, blue}}},
+		{5, [][]color.RGBA{{red}, {blue}}},
+		{6, [][]color.RGBA{{red}, {blue}}},
+		{7, [][]color.RGBA{{blue}, {red}}},
+		{8, [][]color.RGBA{{blue}, {red}}},
+	}
+
+	for _, tt := range tests {
+		img := orient(src, tt.orientation)
+		b := img.Bounds()
+		if b.Dy() != len(tt.want) || b.Dx() != len(tt.want[0]) {
+			t.Errorf("orientation %d: got %dx%d", tt.orientation, b.Dx(), b.Dy())
+			continue
+		}
+		for y, row := range tt.want {
+			for x, want := range row {
+				if got := color.RGBAModel.Convert(img.At(x, y)); got != want {
+					t.Errorf("orientation %d: pixel (%d,%d) = %v, want %v", tt.orientation, x, y, got, want)
+				}
+			}
+		}
+	}
+}
+
+func encode(t *testing.T, format string, w, h int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x * 5), G: uint8(y * 5), B: 128, A: 255})
+		}
+	}
+
+	var buf bytes.Buffer
+	var err error
+	if format == "jpeg" {
+		err = jpeg.Encode(&buf, img, nil)
+	} else {
+		err = png.Encode(&buf, img)
+	}
+	if err != nil {
+		t.Fatalf("encode %s: %v", format, err)
+	}
+	return buf.Bytes()
+}
+
+// withEXIF inserts an APP1 EXIF segment holding only an orientation tag.
+func withEXIF(t *testing.T, data []byte, orientation uint16) []byte {
+	t.Helper()
+	tiff := []byte("II*\x00\x08\x00\x00\x00")        // Little endian, IFD at offset 8
+	tiff = binary.LittleEndian.AppendUint16(tiff, 1) // One entry
+	tiff = binary.LittleEndian.AppendUint16(tiff, 0x0112)
+	tiff = binary.LittleEndian.AppendUint16(tiff, 3) // SHORT
+	tiff = binary.LittleEndian.AppendUint32(tiff, 1)
+	tiff = binary.LittleEndian.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0, 0, 0, 0, 0, 0) // Padding and next IFD
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := []byte{0xFF, 0xE1}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	return append(append(append([]byte{}, data[:2]...), segment...), data[2:]...)
+}
+
+func pngChunk(kind string, data []byte) []byte {
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(data)))
+	chunk = append(chunk, kind...)
+	chunk = append(chunk, data...)
+	return binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+}
@@ -0,0 +1,155 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+var errCorrupt = errors.New("corrupt image data")
+
+// stripJPEG removes metadata segments from a JPEG without re-encoding it:
+// APP1 (EXIF, XMP), APP3–APP15 (IPTC and vendor data) and comments. APP0
+// (JFIF) and APP2 (ICC color profile) are kept as they affect rendering.
+func stripJPEG(data []byte) ([]byte, error) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil, fmt.Errorf("jpeg: %w", errCorrupt)
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, 0xFF, 0xD8)
+	i := 2
+	for {
+		if i+4 > len(data) || data[i] != 0xFF {
+			return nil, fmt.Errorf("jpeg: %w", errCorrupt)
+		}
+		marker := data[i+1]
+		if marker == 0xFF { // Fill byte
+			i++
+			continue
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		end := i + 2 + length
+		if length < 2 || end > len(data) {
+			return nil, fmt.Errorf("jpeg: %w", errCorrupt)
+		}
+
+		if marker == 0xDA { // Start of scan: the rest is image data
+			return append(out, data[i:]...), nil
+		}
+		if !isJPEGMetadata(marker) {
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+}
+
+func isJPEGMetadata(marker byte) bool {
+	return marker == 0xE1 || (marker >= 0xE3 && marker <= 0xEF) || marker == 0xFE
+}
+
+// jpegOrientation returns the EXIF orientation (1–8) of a JPEG, or 0 when
+// it has none.
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 0
+	}
+
+	i := 2
+	for i+4 <= len(data) && data[i] == 0xFF {
+		marker := data[i+1]
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		end := i + 2 + length
+		if marker == 0xDA || length < 2 || end > len(data) {
+			return 0
+		}
+		if marker == 0xE1 && bytes.HasPrefix(data[i+4:end], []byte("Exif\x00\x00")) {
+			return exifOrientation(data[i+10 : end])
+		}
+		i = end
+	}
+	return 0
+}
+
+// exifOrientation reads the orientation tag from the first IFD of a TIFF
+// structure.
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 0
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 0
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for n := 0; n < entries; n++ {
+		entry := ifd + 2 + n*12
+		if entry+12 > len(tiff) {
+			return 0
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			o := int(order.Uint16(tiff[entry+8:]))
+			if o < 1 || o > 8 {
+				return 0
+			}
+			return o
+		}
+	}
+	return 0
+}
+
+// pngSignature starts every PNG file.
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// pngMetadata are the ancillary chunks removed by stripPNG.
+var pngMetadata = map[string]bool{
+	"eXIf": true,
+	"tEXt": true,
+	"zTXt": true,
+	"iTXt": true,
+	"tIME": true,
+}
+
+// stripPNG removes EXIF, text and timestamp chunks from a PNG without
+// re-encoding it.
+func stripPNG(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return nil, fmt.Errorf("png: %w", errCorrupt)
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, pngSignature...)
+	i := len(pngSignature)
+	for i < len(data) {
+		if i+12 > len(data) {
+			return nil, fmt.Errorf("png: %w", errCorrupt)
+		}
+		length := int(binary.BigEndian.Uint32(data[i:]))
+		end := i + 12 + length
+		if length < 0 || end > len(data) {
+			return nil, fmt.Errorf("png: %w", errCorrupt)
+		}
+
+		kind := string(data[i+4 : i+8])
+		if !pngMetadata[kind] {
+			out = append(out, data[i:end]...)
+		}
+		i = end
+		if kind == "IEND" {
+			break
+		}
+	}
+	return out, nil
+}
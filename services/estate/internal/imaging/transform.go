@@ -0,0 +1,97 @@
+package imaging
+
+import (
+	"image"
+	"image/draw"
+)
+
+// orient returns img as it should be displayed given its EXIF orientation.
+func orient(img image.Image, orientation int) image.Image {
+	src := toRGBA(img)
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+
+	// at maps a destination pixel to the source pixel it shows.
+	var at func(x, y int) (int, int)
+	dw, dh := w, h
+	switch orientation {
+	case 2: // Flip horizontal
+		at = func(x, y int) (int, int) { return w - 1 - x, y }
+	case 3: // Rotate 180°
+		at = func(x, y int) (int, int) { return w - 1 - x, h - 1 - y }
+	case 4: // Flip vertical
+		at = func(x, y int) (int, int) { return x, h - 1 - y }
+	case 5: // Transpose
+		dw, dh = h, w
+		at = func(x, y int) (int, int) { return y, x }
+	case 6: // Rotate 90° clockwise
+		dw, dh = h, w
+		at = func(x, y int) (int, int) { return y, h - 1 - x }
+	case 7: // Transverse
+		dw, dh = h, w
+		at = func(x, y int) (int, int) { return w - 1 - y, h - 1 - x }
+	case 8: // Rotate 90° counter-clockwise
+		dw, dh = h, w
+		at = func(x, y int) (int, int) { return w - 1 - y, x }
+	default:
+		return img
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			sx, sy := at(x, y)
+			copy(dst.Pix[dst.PixOffset(x, y):dst.PixOffset(x, y)+4], src.Pix[src.PixOffset(sx, sy):src.PixOffset(sx, sy)+4])
+		}
+	}
+	return dst
+}
+
+// resize scales img to w×h by averaging the source pixels covered by each
+// destination pixel, which gives clean results when scaling down.
+func resize(img image.Image, w, h int) image.Image {
+	b := img.Bounds()
+	if b.Dx() == w && b.Dy() == h {
+		return img
+	}
+
+	src := toRGBA(img)
+	sw, sh := src.Rect.Dx(), src.Rect.Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		y0 := y * sh / h
+		y1 := max(y0+1, (y+1)*sh/h)
+		for x := 0; x < w; x++ {
+			x0 := x * sw / w
+			x1 := max(x0+1, (x+1)*sw/w)
+
+			var sum [4]uint64
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[src.PixOffset(x0, sy):src.PixOffset(x1, sy)]
+				for i := 0; i < len(row); i += 4 {
+					sum[0] += uint64(row[i])
+					sum[1] += uint64(row[i+1])
+					sum[2] += uint64(row[i+2])
+					sum[3] += uint64(row[i+3])
+				}
+			}
+
+			n := uint64((x1 - x0) * (y1 - y0))
+			px := dst.Pix[dst.PixOffset(x, y):]
+			for c := range sum {
+				px[c] = uint8(sum[c] / n)
+			}
+		}
+	}
+	return dst
+}
+
+// toRGBA converts img to an RGBA image anchored at the origin.
+func toRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok && rgba.Rect.Min == (image.Point{}) {
+		return rgba
+	}
+	b := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(rgba, rgba.Rect, img, b.Min, draw.Src)
+	return rgba
+}
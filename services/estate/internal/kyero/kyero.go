@@ -0,0 +1,415 @@
+// Package kyero renders property feeds in the Kyero v3 XML format, pulled
+// by Kyero and the portals that accept it.
+//
+// The schema is checked by rules rather than by the XSD: required
+// elements, the price frequencies and currencies Kyero accepts, the
+// description languages and coordinate ranges. Properties breaking a rule
+// are left out of the feed and reported by the feed status.
+package kyero
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pulap/pulap/services/estate/internal/estate"
+)
+
+// Format is the name feeds use to select this formatter.
+const Format = "kyero"
+
+// FeedVersion is the version of the Kyero schema rendered.
+const FeedVersion = 3
+
+// MaxImages is the number of images Kyero takes per property; later
+// photos are left out.
+const MaxImages = 50
+
+// maxID is the length limit of the property id element.
+const maxID = 50
+
+// Languages lists the description and URL languages of the schema.
+var Languages = []string{"ca", "da", "de", "en", "es", "fi", "fr", "it", "nl", "no", "pt", "ru", "sv"}
+
+// Currencies lists the currencies Kyero accepts.
+var Currencies = []string{"EUR", "GBP", "USD"}
+
+// PriceFrequencies maps price types to Kyero price frequencies. Other
+// price types cannot be published.
+var PriceFrequencies = map[string]string{
+	"sale":         "sale",
+	"rent_monthly": "month",
+	"rent_weekly":  "week",
+}
+
+// DefaultTypes maps dictionary option keys to Kyero property types. The
+// subtype is looked up first, then the type and the category; unmapped
+// classifications use the type key.
+var DefaultTypes = map[string]string{
+	"apartment":       "apartment",
+	"basement_apt":    "apartment",
+	"condo":           "apartment",
+	"loft":            "apartment",
+	"studio":          "studio",
+	"penthouse":       "penthouse",
+	"duplex":          "duplex",
+	"triplex":         "duplex",
+	"house":           "house",
+	"detached_house":  "villa",
+	"chalet":          "villa",
+	"semi_detached":   "semi-detached house",
+	"townhouse":       "town house",
+	"row_house":       "town house",
+	"bungalow":        "bungalow",
+	"cottage":         "country house",
+	"cabin":           "country house",
+	"farm":            "finca",
+	"ranch":           "finca",
+	"land":            "plot",
+	"raw_land":        "plot",
+	"residential_lot": "plot",
+	"urban_land":      "plot",
+	"rural_land":      "plot",
+	"commercial":      "commercial",
+	"office":          "office",
+	"retail":          "commercial",
+	"retail_store":    "commercial",
+	"restaurant":      "restaurant",
+	"bar_pub":         "bar",
+	"hotel":           "hotel",
+	"warehouse":       "warehouse",
+	"parking_garage":  "garage",
+}
+
+// DefaultFeatures maps amenity flags and Features.Amenities entries to
+// Kyero features. The pool has its own element; unmapped amenities are
+// published as written.
+var DefaultFeatures = map[string]string{
+	"garden":           "Garden",
+	"balcony":          "Balcony",
+	"terrace":          "Terrace",
+	"elevator":         "Lift",
+	"air_conditioning": "Air conditioning",
+	"heating":          "Central heating",
+	"furnished":        "Furnished",
+	"pet_friendly":     "Pets allowed",
+	"storage":          "Storage room",
+	"laundry":          "Utility room",
+	"fireplace":        "Fireplace",
+	"gym":              "Gym",
+	"security":         "Security",
+	"concierge":        "Concierge",
+	"sea_view":         "Sea views",
+}
+
+// countries maps ISO 3166 codes to the country names Kyero expects.
+var countries = map[string]string{
+	"AD": "Andorra", "BG": "Bulgaria", "CY": "Cyprus", "DE": "Germany",
+	"ES": "Spain", "FR": "France", "GB": "United Kingdom", "GR": "Greece",
+	"HR": "Croatia", "IT": "Italy", "MA": "Morocco", "MT": "Malta",
+	"PL": "Poland", "PT": "Portugal", "TR": "Turkey", "US": "United States",
+}
+
+// Formatter is the Kyero v3 estate.FeedFormatter.
+type Formatter struct{}
+
+// NewFormatter returns a Kyero v3 formatter.
+func NewFormatter() *Formatter {
+	return &Formatter{}
+}
+
+// Name implements estate.FeedFormatter.
+func (f *Formatter) Name() string {
+	return Format
+}
+
+// ContentType implements estate.FeedFormatter.
+func (f *Formatter) ContentType() string {
+	return "application/xml; charset=utf-8"
+}
+
+// Check implements estate.FeedFormatter. The feed language must be a
+// Kyero language and its price types must have a Kyero frequency.
+func (f *Formatter) Check(feed *estate.Feed) error {
+	if !slices.Contains(Languages, language(feed)) {
+		return fmt.Errorf("kyero does not support language %q", feed.Language)
+	}
+	for _, t := range feed.PriceTypes {
+		if _, ok := PriceFrequencies[t]; !ok {
+			return fmt.Errorf("kyero does not support price type %q", t)
+		}
+	}
+	return nil
+}
+
+// Validate implements estate.FeedFormatter.
+func (f *Formatter) Validate(feed *estate.Feed, item *estate.FeedItem) []estate.ValidationError {
+	var errs []estate.ValidationError
+	add := func(field, msg string) {
+		errs = append(errs, estate.ValidationError{Field: field, Message: msg})
+	}
+
+	if id := item.ID.String(); len(id) > maxID {
+		add("id", fmt.Sprintf("must have at most %d characters", maxID))
+	}
+
+	if _, ok := PriceFrequencies[item.Price.Type]; !ok {
+		add("price.type", fmt.Sprintf("%s prices cannot be published on kyero", item.Price.Type))
+	}
+	if !slices.Contains(Currencies, item.Price.Currency) {
+		add("price.currency", "must be one of: "+strings.Join(Currencies, ", "))
+	}
+	if item.Price.Amount.Round(0).Sign() <= 0 {
+		add("price.amount", "must be at least 1")
+	}
+
+	if propertyType(feed, item.Classification) == "" {
+		add("classification", "has no kyero property type")
+	}
+	if strings.TrimSpace(item.Location.Address.City) == "" {
+		add("location.address.city", "is required (town)")
+	}
+	if strings.TrimSpace(item.Location.Address.State) == "" {
+		add("location.address.state", "is required (province)")
+	}
+
+	if c := item.Location.Coordinates; !c.IsZero() {
+		if c.Latitude < -90 || c.Latitude > 90 || c.Longitude < -180 || c.Longitude > 180 {
+			add("location.coordinates", "are out of range")
+		}
+	}
+
+	if item.URL != "" && !isAbsoluteURL(item.URL) {
+		add("url", "must be an absolute http(s) URL")
+	}
+	for _, img := range item.Images {
+		if !isAbsoluteURL(img) {
+			add("images", "must be absolute http(s) URLs")
+			break
+		}
+	}
+	return errs
+}
+
+// Write implements estate.FeedFormatter.
+func (f *Formatter) Write(w io.Writer, feed *estate.Feed, items []*estate.FeedItem) error {
+	doc := document{Kyero: header{FeedVersion: FeedVersion}}
+	for _, item := range items {
+		doc.Properties = append(doc.Properties, newProperty(feed, item))
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+type document struct {
+	XMLName    xml.Name   `xml:"root"`
+	Kyero      header     `xml:"kyero"`
+	Properties []property `xml:"property"`
+}
+
+type header struct {
+	FeedVersion int `xml:"feed_version"`
+}
+
+// property follows the element order of the Kyero v3 schema.
+type property struct {
+	ID            string       `xml:"id"`
+	Date          string       `xml:"date"`
+	Price         string       `xml:"price"`
+	Currency      string       `xml:"currency"`
+	PriceFreq     string       `xml:"price_freq"`
+	PartOwnership int          `xml:"part_ownership"`
+	Leasehold     int          `xml:"leasehold"`
+	NewBuild      int          `xml:"new_build"`
+	Type          string       `xml:"type"`
+	Town          string       `xml:"town"`
+	Province      string       `xml:"province"`
+	Country       string       `xml:"country,omitempty"`
+	Location      *location    `xml:"location,omitempty"`
+	Beds          int          `xml:"beds"`
+	Baths         int          `xml:"baths"`
+	Pool          int          `xml:"pool"`
+	SurfaceArea   *surfaceArea `xml:"surface_area,omitempty"`
+	URL           *localized   `xml:"url,omitempty"`
+	Desc          *localized   `xml:"desc,omitempty"`
+	Features      *features    `xml:"features,omitempty"`
+	Images        *images      `xml:"images,omitempty"`
+}
+
+type location struct {
+	Latitude  string `xml:"latitude"`
+	Longitude string `xml:"longitude"`
+}
+
+type surfaceArea struct {
+	Built int `xml:"built,omitempty"`
+	Plot  int `xml:"plot,omitempty"`
+}
+
+// localized holds one element per language, named after it.
+type localized struct {
+	Texts []text
+}
+
+type text struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
+
+type features struct {
+	Features []string `xml:"feature"`
+}
+
+type images struct {
+	Images []image `xml:"image"`
+}
+
+type image struct {
+	ID  int    `xml:"id,attr"`
+	URL string `xml:"url"`
+}
+
+func newProperty(feed *estate.Feed, item *estate.FeedItem) property {
+	lang := language(feed)
+	date := item.UpdatedAt
+	if date.IsZero() {
+		date = item.CreatedAt
+	}
+
+	p := property{
+		ID:        item.ID.String(),
+		Date:      date.UTC().Format("2006-01-02 15:04:05"),
+		Price:     item.Price.Amount.Round(0).String(),
+		Currency:  item.Price.Currency,
+		PriceFreq: PriceFrequencies[item.Price.Type],
+		NewBuild:  flag(item.Features.Condition == "new"),
+		Type:      propertyType(feed, item.Classification),
+		Town:      item.Location.Address.City,
+		Province:  item.Location.Address.State,
+		Country:   country(item.Location.Address.Country),
+		Beds:      item.Features.Bedrooms,
+		Baths:     item.Features.Bathrooms,
+		Pool:      flag(item.Features.Pool),
+	}
+
+	if c := item.Location.Coordinates; !c.IsZero() {
+		p.Location = &location{
+			Latitude:  strconv.FormatFloat(c.Latitude, 'f', -1, 64),
+			Longitude: strconv.FormatFloat(c.Longitude, 'f', -1, 64),
+		}
+	}
+
+	built := item.Features.CoveredArea
+	if built == 0 {
+		built = item.Features.TotalArea
+	}
+	if built > 0 || item.Features.LandArea > 0 {
+		p.SurfaceArea = &surfaceArea{Built: int(math.Round(built)), Plot: int(math.Round(item.Features.LandArea))}
+	}
+
+	if item.URL != "" {
+		p.URL = &localized{Texts: []text{{XMLName: xml.Name{Local: lang}, Value: item.URL}}}
+	}
+	if desc := strings.TrimSpace(item.Description); desc != "" {
+		p.Desc = &localized{Texts: []text{{XMLName: xml.Name{Local: lang}, Value: desc}}}
+	}
+
+	if list := featureList(feed, &item.Features); len(list) > 0 {
+		p.Features = &features{Features: list}
+	}
+
+	if len(item.Images) > 0 {
+		imgs := &images{}
+		for i, u := range item.Images[:min(len(item.Images), MaxImages)] {
+			imgs.Images = append(imgs.Images, image{ID: i + 1, URL: u})
+		}
+		p.Images = imgs
+	}
+	return p
+}
+
+// propertyType maps the most specific mapped classification key.
+func propertyType(feed *estate.Feed, c estate.FeedClassification) string {
+	for _, key := range []string{c.Subtype, c.Type, c.Category} {
+		if key == "" {
+			continue
+		}
+		if code, ok := mapped(feed.Types, DefaultTypes, key); ok {
+			return code
+		}
+	}
+	return strings.ReplaceAll(c.Type, "_", " ")
+}
+
+// featureList maps the amenity flags that are set and the amenities.
+func featureList(feed *estate.Feed, f *estate.Features) []string {
+	var keys []string
+	for _, flag := range estate.AmenityFlags {
+		if flag != "pool" && f.Has(flag) {
+			keys = append(keys, flag)
+		}
+	}
+	keys = append(keys, f.Amenities...)
+
+	var list []string
+	for _, key := range keys {
+		code, ok := mapped(feed.Features, DefaultFeatures, key)
+		if !ok {
+			code = key
+		}
+		if code != "" && !slices.Contains(list, code) {
+			list = append(list, code)
+		}
+	}
+	return list
+}
+
+// mapped looks a key up in the feed overrides, then in the defaults.
+func mapped(overrides, defaults map[string]string, key string) (string, bool) {
+	key = strings.ToLower(strings.TrimSpace(key))
+	if code, ok := overrides[key]; ok {
+		return code, true
+	}
+	code, ok := defaults[key]
+	return code, ok
+}
+
+func language(feed *estate.Feed) string {
+	if feed.Language == "" {
+		return "en"
+	}
+	return strings.ToLower(feed.Language)
+}
+
+func country(code string) string {
+	if name, ok := countries[strings.ToUpper(code)]; ok {
+		return name
+	}
+	return code
+}
+
+func flag(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func isAbsoluteURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -0,0 +1,179 @@
+package kyero
+
+import (
+	"bytes"
+	"encoding/xml"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/services/estate/internal/estate"
+	"github.com/pulap/pulap/services/estate/internal/money"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		feed    estate.Feed
+		wantErr bool
+	}{
+		{"defaults", estate.Feed{}, false},
+		{"language", estate.Feed{Language: "es"}, false},
+		{"unknown language", estate.Feed{Language: "pl"}, true},
+		{"price types", estate.Feed{PriceTypes: []string{"sale", "rent_weekly"}}, false},
+		{"daily rent", estate.Feed{PriceTypes: []string{"rent_daily"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewFormatter().Check(&tt.feed)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*estate.FeedItem)
+		fields []string
+	}{
+		{"valid", func(*estate.FeedItem) {}, nil},
+		{"yearly rent", func(i *estate.FeedItem) { i.Price.Type = "rent_yearly" }, []string{"price.type"}},
+		{"currency", func(i *estate.FeedItem) { i.Price.Currency = "ARS" }, []string{"price.currency"}},
+		{"zero price", func(i *estate.FeedItem) { i.Price.Amount = money.MustParse("0.40") }, []string{"price.amount"}},
+		{"no type", func(i *estate.FeedItem) { i.Classification = estate.FeedClassification{} }, []string{"classification"}},
+		{"no town", func(i *estate.FeedItem) { i.Location.Address.City = " " }, []string{"location.address.city"}},
+		{"no province", func(i *estate.FeedItem) { i.Location.Address.State = "" }, []string{"location.address.state"}},
+		{"coordinates", func(i *estate.FeedItem) { i.Location.Coordinates.Latitude = 91 }, []string{"location.coordinates"}},
+		{"relative url", func(i *estate.FeedItem) { i.URL = "/p/1" }, []string{"url"}},
+		{"image url", func(i *estate.FeedItem) { i.Images = []string{"ftp://example.com/a.jpg"} }, []string{"images"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := testItem()
+			tt.modify(item)
+			var fields []string
+			for _, e := range NewFormatter().Validate(&estate.Feed{}, item) {
+				fields = append(fields, e.Field)
+			}
+			if !slices.Equal(fields, tt.fields) {
+				t.Errorf("expected errors on %v, got %v", tt.fields, fields)
+			}
+		})
+	}
+}
+
+func TestPropertyType(t *testing.T) {
+	tests := []struct {
+		name           string
+		classification estate.FeedClassification
+		types          map[string]string
+		want           string
+	}{
+		{"subtype", estate.FeedClassification{Category: "residential", Type: "apartment", Subtype: "penthouse"}, nil, "penthouse"},
+		{"type", estate.FeedClassification{Category: "residential", Type: "detached_house", Subtype: "mansion"}, nil, "villa"},
+		{"category", estate.FeedClassification{Category: "land", Type: "orchard"}, nil, "plot"},
+		{"unmapped", estate.FeedClassification{Category: "residential", Type: "tiny_home"}, nil, "tiny home"},
+		{"override", estate.FeedClassification{Category: "residential", Type: "detached_house"}, map[string]string{"detached_house": "house"}, "house"},
+		{"omitted", estate.FeedClassification{Category: "commercial", Type: "warehouse"}, map[string]string{"warehouse": "", "commercial": ""}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := propertyType(&estate.Feed{Types: tt.types}, tt.classification)
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	item := testItem()
+	item.Features.Condition = "new"
+	item.Features.Pool = true
+	item.Features.Elevator = true
+	item.Features.Amenities = []string{"gym", "Sauna"}
+	item.Description = "Bright flat near the beach."
+	feed := &estate.Feed{Language: "es", Features: map[string]string{"gym": ""}}
+
+	var buf bytes.Buffer
+	if err := NewFormatter().Write(&buf, feed, []*estate.FeedItem{item}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	out := buf.String()
+	if !strings.HasPrefix(out, xml.Header) {
+		t.Errorf("expected an XML header, got %q", out[:min(len(out), 40)])
+	}
+
+	var doc struct {
+		Version    int `xml:"kyero>feed_version"`
+		Properties []struct {
+			ID        string   `xml:"id"`
+			Date      string   `xml:"date"`
+			Price     string   `xml:"price"`
+			PriceFreq string   `xml:"price_freq"`
+			NewBuild  int      `xml:"new_build"`
+			Type      string   `xml:"type"`
+			Town      string   `xml:"town"`
+			Province  string   `xml:"province"`
+			Country   string   `xml:"country"`
+			Latitude  string   `xml:"location>latitude"`
+			Beds      int      `xml:"beds"`
+			Pool      int      `xml:"pool"`
+			Built     int      `xml:"surface_area>built"`
+			URL       string   `xml:"url>es"`
+			Desc      string   `xml:"desc>es"`
+			Features  []string `xml:"features>feature"`
+			Images    []struct {
+				ID  int    `xml:"id,attr"`
+				URL string `xml:"url"`
+			} `xml:"images>image"`
+		} `xml:"property"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("parsing feed: %v\n%s", err, out)
+	}
+	if doc.Version != FeedVersion || len(doc.Properties) != 1 {
+		t.Fatalf("unexpected feed:\n%s", out)
+	}
+
+	p := doc.Properties[0]
+	if p.ID != item.ID.String() || p.Date != "2024-05-02 08:30:00" || p.Price != "250000" || p.PriceFreq != "sale" ||
+		p.NewBuild != 1 || p.Type != "apartment" || p.Town != "Marbella" || p.Province != "Málaga" || p.Country != "Spain" ||
+		p.Latitude != "36.5101" || p.Beds != 2 || p.Pool != 1 || p.Built != 86 ||
+		p.URL != item.URL || p.Desc != item.Description {
+		t.Errorf("unexpected property: %+v", p)
+	}
+	if want := []string{"Lift", "Sauna"}; !slices.Equal(p.Features, want) {
+		t.Errorf("expected features %v, got %v", want, p.Features)
+	}
+	if len(p.Images) != 2 || p.Images[0].ID != 1 || p.Images[1].URL != item.Images[1] {
+		t.Errorf("unexpected images: %+v", p.Images)
+	}
+}
+
+func testItem() *estate.FeedItem {
+	return &estate.FeedItem{
+		Property: &estate.Property{
+			ID:   uuid.MustParse("6f1c1f7e-7d55-4a57-9a64-2d2b0f5b5a01"),
+			Name: "Piso en Marbella",
+			Location: estate.Location{
+				Address:     estate.Address{Street: "Calle Mayor 1", City: "Marbella", State: "Málaga", Country: "ES"},
+				Coordinates: estate.Coordinates{Latitude: 36.5101, Longitude: -4.8825},
+			},
+			Features:  estate.Features{TotalArea: 85.5, Bedrooms: 2, Bathrooms: 1},
+			Status:    "available",
+			CreatedAt: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+			UpdatedAt: time.Date(2024, 5, 2, 8, 30, 0, 0, time.UTC),
+		},
+		Price:          estate.Price{Amount: money.MustParse("250000.00"), Currency: "EUR", Type: "sale"},
+		Classification: estate.FeedClassification{Category: "residential", Type: "apartment"},
+		URL:            "https://example.com/p/6f1c1f7e",
+		Images:         []string{"https://estate.example.com/a.jpg", "https://estate.example.com/b.jpg"},
+	}
+}
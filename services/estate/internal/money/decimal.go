@@ -0,0 +1,313 @@
+// Package money provides exact decimal amounts, the ISO 4217 currency
+// registry and dated exchange rates used to compare prices across currencies.
+package money
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Parsing limits, protecting against inputs that expand to huge numbers.
+const (
+	maxDigits   = 40
+	maxExponent = 40
+)
+
+// MaxStoredDigits is the number of digits of the amounts and rates that
+// every repository stores exactly: the 34 of IEEE 754 Decimal128.
+const MaxStoredDigits = 34
+
+// ErrInvalidDecimal is returned for malformed decimal numbers.
+var ErrInvalidDecimal = errors.New("invalid decimal")
+
+// Decimal is an exact decimal number: coef × 10^-scale. The zero value is 0.
+// Decimals are immutable; operations return new values.
+//
+// It is encoded as a JSON number and stored as text in SQL databases.
+type Decimal struct {
+	coef  *big.Int // nil means zero
+	scale int32    // Digits after the decimal point, never negative
+}
+
+// New returns coef × 10^-scale.
+func New(coef int64, scale int32) Decimal {
+	if scale < 0 {
+		return fromBig(new(big.Int).Mul(big.NewInt(coef), pow10(-scale)), 0)
+	}
+	return fromBig(big.NewInt(coef), scale)
+}
+
+// FromInt returns n as a decimal.
+func FromInt(n int64) Decimal {
+	return New(n, 0)
+}
+
+// FromFloat returns the shortest decimal that rounds to f. It is meant for
+// amounts previously kept as float64.
+func FromFloat(f float64) (Decimal, error) {
+	return Parse(strconv.FormatFloat(f, 'f', -1, 64))
+}
+
+// MustParse is like Parse but panics on error. It is meant for constants.
+func MustParse(s string) Decimal {
+	d, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// Parse reads a decimal in plain ("-1234.50") or exponent ("1.2e3") notation.
+func Parse(s string) (Decimal, error) {
+	invalid := fmt.Errorf("%w: %q", ErrInvalidDecimal, s)
+
+	mantissa, exponent := s, 0
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		exp, err := strconv.Atoi(s[i+1:])
+		if err != nil || exp > maxExponent || exp < -maxExponent {
+			return Decimal{}, invalid
+		}
+		mantissa, exponent = s[:i], exp
+	}
+
+	neg := false
+	switch {
+	case strings.HasPrefix(mantissa, "-"):
+		neg, mantissa = true, mantissa[1:]
+	case strings.HasPrefix(mantissa, "+"):
+		mantissa = mantissa[1:]
+	}
+
+	whole, frac, _ := strings.Cut(mantissa, ".")
+	digits := whole + frac
+	if digits == "" || len(digits) > maxDigits || !isDigits(digits) {
+		return Decimal{}, invalid
+	}
+
+	coef, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return Decimal{}, invalid
+	}
+	if neg {
+		coef.Neg(coef)
+	}
+
+	scale := int32(len(frac)) - int32(exponent)
+	if scale < 0 {
+		coef.Mul(coef, pow10(-scale))
+		scale = 0
+	}
+	return fromBig(coef, scale), nil
+}
+
+// String returns the number in plain notation, keeping its scale ("12.50").
+func (d Decimal) String() string {
+	digits := new(big.Int).Abs(d.int()).String()
+	if d.scale > 0 {
+		if pad := int(d.scale) + 1 - len(digits); pad > 0 {
+			digits = strings.Repeat("0", pad) + digits
+		}
+		cut := len(digits) - int(d.scale)
+		digits = digits[:cut] + "." + digits[cut:]
+	}
+	if d.Sign() < 0 {
+		return "-" + digits
+	}
+	return digits
+}
+
+// Scale returns the number of digits after the decimal point.
+func (d Decimal) Scale() int32 {
+	return d.scale
+}
+
+// Digits returns the number of digits of the coefficient: 4 for "12.50".
+func (d Decimal) Digits() int {
+	return len(new(big.Int).Abs(d.int()).String())
+}
+
+// Sign returns -1, 0 or +1.
+func (d Decimal) Sign() int {
+	if d.coef == nil {
+		return 0
+	}
+	return d.coef.Sign()
+}
+
+// IsZero reports whether d is 0.
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// Cmp compares d and e: -1 if d < e, 0 if equal, +1 if d > e.
+// Scale is ignored, so 1.5 and 1.50 are equal.
+func (d Decimal) Cmp(e Decimal) int {
+	a, b := align(d, e)
+	return a.Cmp(b)
+}
+
+// Equal reports whether d and e have the same value.
+func (d Decimal) Equal(e Decimal) bool {
+	return d.Cmp(e) == 0
+}
+
+// Add returns d + e.
+func (d Decimal) Add(e Decimal) Decimal {
+	a, b := align(d, e)
+	return fromBig(a.Add(a, b), max(d.scale, e.scale))
+}
+
+// Sub returns d - e.
+func (d Decimal) Sub(e Decimal) Decimal {
+	a, b := align(d, e)
+	return fromBig(a.Sub(a, b), max(d.scale, e.scale))
+}
+
+// Mul returns d × e exactly.
+func (d Decimal) Mul(e Decimal) Decimal {
+	return fromBig(new(big.Int).Mul(d.int(), e.int()), d.scale+e.scale)
+}
+
+// Quo returns d / e rounded to scale digits. It panics if e is zero.
+func (d Decimal) Quo(e Decimal, scale int32) Decimal {
+	if e.IsZero() {
+		panic("money: division by zero")
+	}
+
+	// d/e = (dc / 10^ds) / (ec / 10^es); scaled by 10^(scale+1) for rounding.
+	num := new(big.Int).Mul(d.int(), pow10(e.scale+scale+1))
+	den := new(big.Int).Mul(e.int(), pow10(d.scale))
+	q := num.Quo(num, den)
+	return roundTo(q, scale+1, scale)
+}
+
+// Round returns d with exactly scale digits after the decimal point,
+// rounding halves away from zero: 2.345 gives 2.35 and 7 gives 7.00.
+func (d Decimal) Round(scale int32) Decimal {
+	if scale < 0 {
+		scale = 0
+	}
+	if d.scale <= scale {
+		return fromBig(new(big.Int).Mul(d.int(), pow10(scale-d.scale)), scale)
+	}
+	return roundTo(d.int(), d.scale, scale)
+}
+
+// Float64 returns the nearest float64, for sorting and display.
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// MarshalJSON encodes d as a JSON number.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON accepts a JSON number or a string holding one.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	if strings.HasPrefix(s, `"`) {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+	}
+
+	parsed, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// Value implements driver.Valuer, storing d as text so no precision is lost.
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+// Scan implements sql.Scanner. Numeric columns are accepted for data
+// written before amounts were stored as text.
+func (d *Decimal) Scan(src any) error {
+	var (
+		parsed Decimal
+		err    error
+	)
+	switch v := src.(type) {
+	case nil:
+		parsed = Decimal{}
+	case string:
+		parsed, err = Parse(v)
+	case []byte:
+		parsed, err = Parse(string(v))
+	case int64:
+		parsed = FromInt(v)
+	case float64:
+		parsed, err = FromFloat(v)
+	default:
+		return fmt.Errorf("cannot scan %T into Decimal", src)
+	}
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+func (d Decimal) int() *big.Int {
+	if d.coef == nil {
+		return new(big.Int)
+	}
+	return d.coef
+}
+
+func fromBig(coef *big.Int, scale int32) Decimal {
+	if coef.Sign() == 0 {
+		return Decimal{scale: scale}
+	}
+	return Decimal{coef: coef, scale: scale}
+}
+
+// align returns copies of the coefficients of d and e at the same scale.
+func align(d, e Decimal) (*big.Int, *big.Int) {
+	a, b := new(big.Int).Set(d.int()), new(big.Int).Set(e.int())
+	switch {
+	case d.scale < e.scale:
+		a.Mul(a, pow10(e.scale-d.scale))
+	case e.scale < d.scale:
+		b.Mul(b, pow10(d.scale-e.scale))
+	}
+	return a, b
+}
+
+// roundTo rounds coef × 10^-from to scale digits, halves away from zero.
+func roundTo(coef *big.Int, from, scale int32) Decimal {
+	div := pow10(from - scale)
+	q, r := new(big.Int).QuoRem(coef, div, new(big.Int))
+	r.Abs(r).Mul(r, big.NewInt(2))
+	if r.Cmp(div) >= 0 {
+		q.Add(q, big.NewInt(int64(coef.Sign())))
+	}
+	return fromBig(q, scale)
+}
+
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,184 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "0", want: "0"},
+		{in: "1234.50", want: "1234.50"},
+		{in: "-0.05", want: "-0.05"},
+		{in: "+7", want: "7"},
+		{in: ".5", want: "0.5"},
+		{in: "1.2e3", want: "1200"},
+		{in: "1e-2", want: "0.01"},
+		{in: "12.5E-1", want: "1.25"},
+		{in: "", wantErr: true},
+		{in: "-", wantErr: true},
+		{in: "abc", wantErr: true},
+		{in: "1.2.3", wantErr: true},
+		{in: "1,5", wantErr: true},
+		{in: "1e999", wantErr: true},
+		{in: "12345678901234567890123456789012345678901", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			d, err := Parse(tt.in)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidDecimal) {
+					t.Fatalf("Parse(%q) = %s, %v; want ErrInvalidDecimal", tt.in, d, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.in, err)
+			}
+			if d.String() != tt.want {
+				t.Errorf("Parse(%q) = %s, want %s", tt.in, d, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecimalArithmetic(t *testing.T) {
+	tests := []struct {
+		name string
+		got  Decimal
+		want string
+	}{
+		{"add aligns scales", MustParse("1.5").Add(MustParse("0.25")), "1.75"},
+		{"sub goes negative", MustParse("1").Sub(MustParse("1.01")), "-0.01"},
+		{"mul is exact", MustParse("0.1").Mul(MustParse("0.2")), "0.02"},
+		{"quo rounds", FromInt(2).Quo(FromInt(3), 4), "0.6667"},
+		{"quo negative rounds away from zero", FromInt(-2).Quo(FromInt(3), 2), "-0.67"},
+		{"round half up", MustParse("2.345").Round(2), "2.35"},
+		{"round half away from zero", MustParse("-2.345").Round(2), "-2.35"},
+		{"round down", MustParse("2.344").Round(2), "2.34"},
+		{"round pads", FromInt(7).Round(2), "7.00"},
+		{"round zero pads", Decimal{}.Round(2), "0.00"},
+		{"round to units", MustParse("1499.5").Round(0), "1500"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got.String() != tt.want {
+				t.Errorf("got %s, want %s", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecimalCmp(t *testing.T) {
+	if !MustParse("1.5").Equal(MustParse("1.50")) {
+		t.Error("expected 1.5 to equal 1.50")
+	}
+	if MustParse("0.1").Cmp(MustParse("0.09")) != 1 {
+		t.Error("expected 0.1 > 0.09")
+	}
+	if MustParse("-3").Cmp(Decimal{}) != -1 {
+		t.Error("expected -3 < 0")
+	}
+	if !(Decimal{}).IsZero() || !MustParse("0.00").IsZero() {
+		t.Error("expected zero values to be zero")
+	}
+}
+
+func TestDecimalDigits(t *testing.T) {
+	for s, want := range map[string]int{"0": 1, "12.50": 4, "-0.001": 1, "1e3": 4} {
+		if got := MustParse(s).Digits(); got != want {
+			t.Errorf("Digits(%s) = %d, want %d", s, got, want)
+		}
+	}
+}
+
+func TestDecimalJSON(t *testing.T) {
+	var v struct {
+		A Decimal `json:"a"`
+		B Decimal `json:"b"`
+		C Decimal `json:"c"`
+	}
+	if err := json.Unmarshal([]byte(`{"a": 350000.50, "b": "0.1", "c": null}`), &v); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if v.A.String() != "350000.50" || v.B.String() != "0.1" || !v.C.IsZero() {
+		t.Errorf("unexpected values %s %s %s", v.A, v.B, v.C)
+	}
+
+	out, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if string(out) != `{"a":350000.50,"b":0.1,"c":0}` {
+		t.Errorf("unexpected JSON %s", out)
+	}
+
+	if err := json.Unmarshal([]byte(`{"a": "ten"}`), &v); !errors.Is(err, ErrInvalidDecimal) {
+		t.Errorf("expected ErrInvalidDecimal, got %v", err)
+	}
+}
+
+func TestDecimalScan(t *testing.T) {
+	tests := []struct {
+		src  any
+		want string
+	}{
+		{nil, "0"},
+		{"12.30", "12.30"},
+		{[]byte("-4.5"), "-4.5"},
+		{int64(250000), "250000"},
+		{float64(0.1), "0.1"},
+	}
+
+	for _, tt := range tests {
+		var d Decimal
+		if err := d.Scan(tt.src); err != nil {
+			t.Errorf("Scan(%#v): %v", tt.src, err)
+			continue
+		}
+		if d.String() != tt.want {
+			t.Errorf("Scan(%#v) = %s, want %s", tt.src, d, tt.want)
+		}
+	}
+
+	var d Decimal
+	if err := d.Scan(true); err == nil {
+		t.Error("expected an error scanning a bool")
+	}
+}
+
+func TestCurrencyFits(t *testing.T) {
+	tests := []struct {
+		code   string
+		amount string
+		want   bool
+	}{
+		{"EUR", "10.50", true},
+		{"EUR", "10.505", false},
+		{"EUR", "10.500", true},
+		{"JPY", "1000", true},
+		{"JPY", "1000.5", false},
+		{"KWD", "1.125", true},
+	}
+
+	for _, tt := range tests {
+		c, err := LookupCurrency(tt.code)
+		if err != nil {
+			t.Fatalf("LookupCurrency(%s): %v", tt.code, err)
+		}
+		if got := c.Fits(MustParse(tt.amount)); got != tt.want {
+			t.Errorf("%s.Fits(%s) = %v, want %v", tt.code, tt.amount, got, tt.want)
+		}
+	}
+
+	if _, err := LookupCurrency("XYZ"); !errors.Is(err, ErrUnknownCurrency) {
+		t.Errorf("expected ErrUnknownCurrency, got %v", err)
+	}
+}
@@ -0,0 +1,277 @@
+package money
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DateLayout is the layout of rate dates.
+const DateLayout = time.DateOnly
+
+// quoScale is the number of digits kept when dividing by a rate.
+const quoScale = 12
+
+var (
+	// ErrNoRate is returned when no rate converts between two currencies.
+	ErrNoRate = errors.New("no exchange rate")
+	// ErrInvalidRate is returned for malformed or inconsistent rates.
+	ErrInvalidRate = errors.New("invalid exchange rate")
+)
+
+// Rate states that 1 unit of From is worth Value units of To, starting on
+// Date and until a later rate for the same pair.
+type Rate struct {
+	From  string
+	To    string
+	Date  time.Time // UTC midnight
+	Value Decimal
+}
+
+type rateJSON struct {
+	From  string  `json:"from"`
+	To    string  `json:"to"`
+	Date  string  `json:"date"`
+	Value Decimal `json:"rate"`
+}
+
+// MarshalJSON encodes the rate with its date as YYYY-MM-DD.
+func (r Rate) MarshalJSON() ([]byte, error) {
+	return json.Marshal(rateJSON{From: r.From, To: r.To, Date: r.Date.Format(DateLayout), Value: r.Value})
+}
+
+// UnmarshalJSON decodes a rate with its date as YYYY-MM-DD.
+func (r *Rate) UnmarshalJSON(data []byte) error {
+	var raw rateJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	date, err := ParseDate(raw.Date)
+	if err != nil {
+		return err
+	}
+	*r = Rate{From: strings.ToUpper(raw.From), To: strings.ToUpper(raw.To), Date: date, Value: raw.Value}
+	return nil
+}
+
+// ParseDate parses a YYYY-MM-DD date as UTC midnight.
+func ParseDate(s string) (time.Time, error) {
+	date, err := time.Parse(DateLayout, strings.TrimSpace(s))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: date %q must be YYYY-MM-DD", ErrInvalidRate, s)
+	}
+	return date, nil
+}
+
+// Validate checks both currencies are registered and distinct and the value
+// is positive and storable.
+func (r Rate) Validate() error {
+	if !IsCurrency(r.From) {
+		return fmt.Errorf("%w: %w: %q", ErrInvalidRate, ErrUnknownCurrency, r.From)
+	}
+	if !IsCurrency(r.To) {
+		return fmt.Errorf("%w: %w: %q", ErrInvalidRate, ErrUnknownCurrency, r.To)
+	}
+	if r.From == r.To {
+		return fmt.Errorf("%w: %s to itself", ErrInvalidRate, r.From)
+	}
+	if r.Value.Sign() <= 0 {
+		return fmt.Errorf("%w: %s/%s must be positive", ErrInvalidRate, r.From, r.To)
+	}
+	if r.Value.Digits() > MaxStoredDigits {
+		return fmt.Errorf("%w: %s/%s has more than %d digits", ErrInvalidRate, r.From, r.To, MaxStoredDigits)
+	}
+	if r.Date.IsZero() {
+		return fmt.Errorf("%w: %s/%s has no date", ErrInvalidRate, r.From, r.To)
+	}
+	return nil
+}
+
+// ReadRates reads rates in "csv" or "json" format and validates them.
+//
+// CSV input needs a header naming the date, from, to and rate columns, in any
+// order. JSON input is an array of {"date", "from", "to", "rate"} objects.
+func ReadRates(r io.Reader, format string) ([]Rate, error) {
+	var (
+		rates []Rate
+		err   error
+	)
+	switch format {
+	case "csv":
+		rates, err = readCSV(r)
+	case "json":
+		err = json.NewDecoder(r).Decode(&rates)
+		if err != nil && !errors.Is(err, ErrInvalidRate) {
+			err = fmt.Errorf("%w: %v", ErrInvalidRate, err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported rate format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for i, rate := range rates {
+		if err := rate.Validate(); err != nil {
+			return nil, fmt.Errorf("rate %d: %w", i+1, err)
+		}
+	}
+	return rates, nil
+}
+
+func readCSV(r io.Reader) ([]Rate, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: cannot read header: %v", ErrInvalidRate, err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"date", "from", "to", "rate"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("%w: missing %q column", ErrInvalidRate, name)
+		}
+	}
+
+	var rates []Rate
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return rates, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidRate, err)
+		}
+
+		line, _ := cr.FieldPos(0)
+		date, err := ParseDate(record[columns["date"]])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		value, err := Parse(strings.TrimSpace(record[columns["rate"]]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w: %w", line, ErrInvalidRate, err)
+		}
+		rates = append(rates, Rate{
+			From:  strings.ToUpper(strings.TrimSpace(record[columns["from"]])),
+			To:    strings.ToUpper(strings.TrimSpace(record[columns["to"]])),
+			Date:  date,
+			Value: value,
+		})
+	}
+}
+
+type pair struct{ from, to string }
+
+// RateTable converts amounts with the rate in effect on a date. Besides
+// direct rates, it uses inverse rates and crosses through a third currency,
+// so rates quoted against a single reference currency are enough.
+// It is immutable once built.
+type RateTable struct {
+	pairs      map[pair][]Rate // Oldest first
+	currencies []string
+}
+
+// NewRateTable builds a table from rates. When a pair has several rates for
+// the same date, the last one wins.
+func NewRateTable(rates []Rate) *RateTable {
+	t := &RateTable{pairs: map[pair][]Rate{}}
+
+	seen := map[string]bool{}
+	for _, r := range rates {
+		p := pair{r.From, r.To}
+		t.pairs[p] = append(t.pairs[p], r)
+		for _, code := range []string{r.From, r.To} {
+			if !seen[code] {
+				seen[code] = true
+				t.currencies = append(t.currencies, code)
+			}
+		}
+	}
+	sort.Strings(t.currencies)
+
+	for p, list := range t.pairs {
+		sort.SliceStable(list, func(i, j int) bool { return list[i].Date.Before(list[j].Date) })
+		// Keep the last rate of each date.
+		out := list[:0]
+		for i, r := range list {
+			if i+1 < len(list) && list[i+1].Date.Equal(r.Date) {
+				continue
+			}
+			out = append(out, r)
+		}
+		t.pairs[p] = out
+	}
+	return t
+}
+
+// Len returns the number of distinct rates in the table.
+func (t *RateTable) Len() int {
+	n := 0
+	for _, list := range t.pairs {
+		n += len(list)
+	}
+	return n
+}
+
+// Convert converts amount from one currency to another with the rates in
+// effect on the given day. It returns the converted amount, not rounded, and
+// the date of the oldest rate used. It returns ErrNoRate when the currencies
+// are not connected directly or through a single intermediate currency.
+func (t *RateTable) Convert(amount Decimal, from, to string, on time.Time) (Decimal, time.Time, error) {
+	if from == to {
+		return amount, time.Time{}, nil
+	}
+
+	if out, date, ok := t.step(amount, from, to, on); ok {
+		return out, date, nil
+	}
+
+	for _, via := range t.currencies {
+		if via == from || via == to {
+			continue
+		}
+		mid, d1, ok := t.step(amount, from, via, on)
+		if !ok {
+			continue
+		}
+		if out, d2, ok := t.step(mid, via, to, on); ok {
+			if d2.Before(d1) {
+				d1 = d2
+			}
+			return out, d1, nil
+		}
+	}
+
+	return Decimal{}, time.Time{}, fmt.Errorf("%w from %s to %s on %s", ErrNoRate, from, to, on.Format(DateLayout))
+}
+
+// step converts with a direct or inverse rate of the pair.
+func (t *RateTable) step(amount Decimal, from, to string, on time.Time) (Decimal, time.Time, bool) {
+	if r, ok := t.find(from, to, on); ok {
+		return amount.Mul(r.Value), r.Date, true
+	}
+	if r, ok := t.find(to, from, on); ok {
+		return amount.Quo(r.Value, quoScale), r.Date, true
+	}
+	return Decimal{}, time.Time{}, false
+}
+
+// find returns the latest rate of the pair dated on or before the day.
+func (t *RateTable) find(from, to string, on time.Time) (Rate, bool) {
+	list := t.pairs[pair{from, to}]
+	i := sort.Search(len(list), func(i int) bool { return list[i].Date.After(on) })
+	if i == 0 {
+		return Rate{}, false
+	}
+	return list[i-1], true
+}
@@ -0,0 +1,133 @@
+package money
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadRates(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		input   string
+		want    int
+		wantErr string
+	}{
+		{
+			name:   "csv with reordered columns",
+			format: "csv",
+			input:  "from,to,rate,date\neur,USD,1.0950,2024-01-01\nPLN, EUR, 0.2301, 2024-01-01\n",
+			want:   2,
+		},
+		{
+			name:   "json",
+			format: "json",
+			input:  `[{"date": "2024-01-01", "from": "eur", "to": "usd", "rate": "1.0950"}]`,
+			want:   1,
+		},
+		{name: "missing column", format: "csv", input: "date,from,rate\n2024-01-01,EUR,1.1\n", wantErr: `missing "to" column`},
+		{name: "bad date", format: "csv", input: "date,from,to,rate\n2024-01-01,EUR,USD,1.1\n01/02/2024,EUR,USD,1.1\n", wantErr: "line 3"},
+		{name: "bad rate", format: "csv", input: "date,from,to,rate\n2024-01-01,EUR,USD,one\n", wantErr: "line 2"},
+		{name: "unknown currency", format: "json", input: `[{"date": "2024-01-01", "from": "EUR", "to": "XYZ", "rate": 1}]`, wantErr: "rate 1"},
+		{name: "same currency", format: "json", input: `[{"date": "2024-01-01", "from": "EUR", "to": "EUR", "rate": 1}]`, wantErr: "rate 1"},
+		{name: "non positive rate", format: "json", input: `[{"date": "2024-01-01", "from": "EUR", "to": "USD", "rate": 0}]`, wantErr: "rate 1"},
+		{name: "unsupported format", format: "xml", input: "", wantErr: "unsupported"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rates, err := ReadRates(strings.NewReader(tt.input), tt.format)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadRates: %v", err)
+			}
+			if len(rates) != tt.want {
+				t.Fatalf("expected %d rates, got %d", tt.want, len(rates))
+			}
+			if rates[0].From != "EUR" || rates[0].To != "USD" || rates[0].Value.String() != "1.0950" {
+				t.Errorf("unexpected first rate %+v", rates[0])
+			}
+		})
+	}
+}
+
+func TestRateTableConvert(t *testing.T) {
+	rate := func(from, to, date, value string) Rate {
+		d, err := ParseDate(date)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return Rate{From: from, To: to, Date: d, Value: MustParse(value)}
+	}
+	table := NewRateTable([]Rate{
+		rate("EUR", "USD", "2024-01-01", "1.10"),
+		rate("EUR", "USD", "2024-02-01", "1.08"),
+		rate("EUR", "USD", "2024-02-01", "1.09"), // Replaces the previous one
+		rate("PLN", "EUR", "2024-01-15", "0.25"),
+	})
+	if table.Len() != 3 {
+		t.Errorf("expected 3 rates, got %d", table.Len())
+	}
+
+	tests := []struct {
+		name     string
+		amount   string
+		from, to string
+		on       string
+		want     string
+		wantDate string
+		wantErr  error
+	}{
+		{name: "identity", amount: "100", from: "EUR", to: "EUR", on: "2024-03-01", want: "100"},
+		{name: "direct", amount: "100", from: "EUR", to: "USD", on: "2024-01-20", want: "110.00", wantDate: "2024-01-01"},
+		{name: "latest rate", amount: "100", from: "EUR", to: "USD", on: "2024-03-01", want: "109.00", wantDate: "2024-02-01"},
+		{name: "inverse", amount: "110", from: "USD", to: "EUR", on: "2024-01-20", want: "100.000000000000", wantDate: "2024-01-01"},
+		{name: "cross", amount: "1000", from: "PLN", to: "USD", on: "2024-03-01", want: "272.5000", wantDate: "2024-01-15"},
+		{name: "before first rate", amount: "100", from: "EUR", to: "USD", on: "2023-12-31", wantErr: ErrNoRate},
+		{name: "cross not yet in effect", amount: "100", from: "PLN", to: "USD", on: "2024-01-10", wantErr: ErrNoRate},
+		{name: "unconnected", amount: "100", from: "JPY", to: "USD", on: "2024-03-01", wantErr: ErrNoRate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			on, err := ParseDate(tt.on)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, date, err := table.Convert(MustParse(tt.amount), tt.from, tt.to, on)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %s, %v", tt.wantErr, got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Convert: %v", err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+			if tt.wantDate != "" && date.Format(DateLayout) != tt.wantDate {
+				t.Errorf("expected rate date %s, got %s", tt.wantDate, date.Format(DateLayout))
+			}
+		})
+	}
+}
+
+func TestRateValidate(t *testing.T) {
+	r := Rate{From: "EUR", To: "USD", Value: MustParse("1.1")}
+	if err := r.Validate(); !errors.Is(err, ErrInvalidRate) {
+		t.Errorf("expected ErrInvalidRate for a missing date, got %v", err)
+	}
+	r.Date = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := r.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,290 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/pulap/pulap/services/estate/internal/config"
+	"github.com/pulap/pulap/services/estate/internal/estate"
+)
+
+// appointmentsCollection holds the appointments, deleted by name when their
+// property is purged.
+const appointmentsCollection = "appointments"
+
+// AppointmentRepo implements the estate.AppointmentRepo interface using MongoDB.
+// Appointments are stored in the database of the property repository, which
+// must be started first.
+type AppointmentRepo struct {
+	properties *PropertyRepo
+	collection *mongo.Collection
+	xparams    config.XParams
+}
+
+// NewAppointmentRepo creates a new MongoDB repository for Appointment aggregates
+// stored alongside properties.
+func NewAppointmentRepo(properties *PropertyRepo, xparams config.XParams) *AppointmentRepo {
+	return &AppointmentRepo{
+		properties: properties,
+		xparams:    xparams,
+	}
+}
+
+// Start opens the appointments collection and creates its indexes.
+func (r *AppointmentRepo) Start(ctx context.Context) error {
+	if r.properties.db == nil {
+		return fmt.Errorf("property repository not started")
+	}
+
+	r.collection = r.properties.db.Collection(appointmentsCollection)
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "agent_id", Value: 1}, {Key: "starts_at", Value: 1}}},
+		{Keys: bson.D{{Key: "property_id", Value: 1}, {Key: "starts_at", Value: 1}}},
+	})
+	if err != nil {
+		return fmt.Errorf("cannot create indexes: %w", err)
+	}
+	return nil
+}
+
+// appointmentDocument is the stored form of an appointment.
+type appointmentDocument struct {
+	ID         string         `bson:"_id"`
+	PropertyID string         `bson:"property_id"`
+	AgentID    string         `bson:"agent_id"`
+	Client     estate.Contact `bson:"client"`
+	StartsAt   time.Time      `bson:"starts_at"`
+	EndsAt     time.Time      `bson:"ends_at"`
+	Status     string         `bson:"status"`
+	Notes      string         `bson:"notes,omitempty"`
+	Revision   int64          `bson:"revision"`
+	CreatedAt  time.Time      `bson:"created_at"`
+	CreatedBy  string         `bson:"created_by"`
+	UpdatedAt  time.Time      `bson:"updated_at"`
+	UpdatedBy  string         `bson:"updated_by"`
+}
+
+// CreateAppointment stores a new appointment unless it conflicts with
+// another under the buffers. The appointment is inserted before the
+// conflict check, so of two concurrent writes at least the later one sees
+// the other; an appointment found conflicting is deleted again.
+func (r *AppointmentRepo) CreateAppointment(ctx context.Context, a *estate.Appointment, buffers estate.AppointmentBuffers) error {
+	a.BeforeCreate()
+
+	if _, err := r.collection.InsertOne(ctx, toAppointmentDocument(a)); err != nil {
+		return fmt.Errorf("could not create appointment: %w", err)
+	}
+
+	err := r.checkAppointment(ctx, a, buffers)
+	if err == nil {
+		return nil
+	}
+
+	// The appointment must not outlive a failed check, even if ctx is done.
+	if _, derr := r.collection.DeleteOne(context.WithoutCancel(ctx), bson.M{"_id": a.ID.String()}); derr != nil {
+		return fmt.Errorf("could not delete conflicting appointment %s: %w", a.ID, derr)
+	}
+	return err
+}
+
+// GetAppointment retrieves an appointment.
+func (r *AppointmentRepo) GetAppointment(ctx context.Context, id uuid.UUID) (*estate.Appointment, error) {
+	var doc appointmentDocument
+	err := r.collection.FindOne(ctx, bson.M{"_id": id.String()}).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("appointment %s: %w", id, estate.ErrAppointmentNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not get appointment: %w", err)
+	}
+	return fromAppointmentDocument(&doc)
+}
+
+// SaveAppointment replaces an appointment if its revision still matches
+// and it does not conflict with another, and increments the revision. Like
+// in CreateAppointment, conflicts are checked after the write, which is
+// undone if one is found.
+func (r *AppointmentRepo) SaveAppointment(ctx context.Context, a *estate.Appointment, buffers estate.AppointmentBuffers) error {
+	a.BeforeUpdate()
+
+	var previous appointmentDocument
+	err := r.collection.FindOne(ctx, bson.M{"_id": a.ID.String(), "revision": a.Revision}).Decode(&previous)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return r.appointmentRevisionError(ctx, a.ID)
+	}
+	if err != nil {
+		return fmt.Errorf("could not get appointment: %w", err)
+	}
+
+	doc := toAppointmentDocument(a)
+	doc.Revision++
+	result, err := r.collection.ReplaceOne(ctx, bson.M{"_id": doc.ID, "revision": a.Revision}, doc)
+	if err != nil {
+		return fmt.Errorf("could not save appointment: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return r.appointmentRevisionError(ctx, a.ID)
+	}
+
+	if err := r.checkAppointment(ctx, a, buffers); err != nil {
+		// Writes made on top of this one since are kept.
+		_, rerr := r.collection.ReplaceOne(context.WithoutCancel(ctx), bson.M{"_id": doc.ID, "revision": doc.Revision}, &previous)
+		if rerr != nil {
+			return fmt.Errorf("could not restore conflicting appointment %s: %w", a.ID, rerr)
+		}
+		return err
+	}
+
+	a.Revision++
+	return nil
+}
+
+// ListAppointments lists the appointments matching the query, soonest
+// first.
+func (r *AppointmentRepo) ListAppointments(ctx context.Context, query estate.AppointmentQuery) ([]*estate.Appointment, error) {
+	filter := bson.M{}
+	if len(query.PropertyIDs) > 0 {
+		ids := make([]string, 0, len(query.PropertyIDs))
+		for _, id := range query.PropertyIDs {
+			ids = append(ids, id.String())
+		}
+		filter["property_id"] = bson.M{"$in": ids}
+	}
+	if len(query.AgentIDs) > 0 {
+		filter["agent_id"] = bson.M{"$in": query.AgentIDs}
+	}
+	if len(query.Statuses) > 0 {
+		filter["status"] = bson.M{"$in": query.Statuses}
+	}
+	if query.From != nil {
+		filter["ends_at"] = bson.M{"$gt": query.From.UTC()}
+	}
+	if query.To != nil {
+		filter["starts_at"] = bson.M{"$lt": query.To.UTC()}
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "starts_at", Value: 1}, {Key: "_id", Value: 1}}).
+		SetLimit(int64(query.Limit))
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("could not list appointments: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var appointments []*estate.Appointment
+	for cursor.Next(ctx) {
+		var doc appointmentDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("could not decode appointment: %w", err)
+		}
+		a, err := fromAppointmentDocument(&doc)
+		if err != nil {
+			return nil, err
+		}
+		appointments = append(appointments, a)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("cursor error: %w", err)
+	}
+
+	return appointments, nil
+}
+
+// checkAppointment returns estate.ErrAppointmentConflict if the stored
+// appointment conflicts with another. Appointments not holding their slots
+// are not checked.
+func (r *AppointmentRepo) checkAppointment(ctx context.Context, a *estate.Appointment, buffers estate.AppointmentBuffers) error {
+	if !a.Blocks() {
+		return nil
+	}
+
+	agentFrom, agentTo := buffers.AgentWindow(a)
+	propertyFrom, propertyTo := buffers.PropertyWindow(a)
+	var doc appointmentDocument
+	err := r.collection.FindOne(ctx, bson.M{
+		"_id":    bson.M{"$ne": a.ID.String()},
+		"status": bson.M{"$ne": estate.AppointmentCancelled},
+		"$or": bson.A{
+			bson.M{"agent_id": a.AgentID, "starts_at": bson.M{"$lt": agentTo}, "ends_at": bson.M{"$gt": agentFrom}},
+			bson.M{"property_id": a.PropertyID.String(), "starts_at": bson.M{"$lt": propertyTo}, "ends_at": bson.M{"$gt": propertyFrom}},
+		},
+	}, options.FindOne().SetSort(bson.D{{Key: "starts_at", Value: 1}})).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not check appointment conflicts: %w", err)
+	}
+
+	other, err := fromAppointmentDocument(&doc)
+	if err != nil {
+		return err
+	}
+	return buffers.ConflictError(a, other)
+}
+
+// appointmentRevisionError explains why a conditional write matched no
+// document: the appointment is either missing or at another revision.
+func (r *AppointmentRepo) appointmentRevisionError(ctx context.Context, id uuid.UUID) error {
+	n, err := r.collection.CountDocuments(ctx, bson.M{"_id": id.String()})
+	if err != nil {
+		return fmt.Errorf("could not check appointment: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("appointment %s: %w", id, estate.ErrAppointmentNotFound)
+	}
+	return fmt.Errorf("appointment %s: %w", id, estate.ErrRevisionConflict)
+}
+
+func toAppointmentDocument(a *estate.Appointment) *appointmentDocument {
+	return &appointmentDocument{
+		ID:         a.ID.String(),
+		PropertyID: a.PropertyID.String(),
+		AgentID:    a.AgentID,
+		Client:     a.Client,
+		StartsAt:   a.StartsAt,
+		EndsAt:     a.EndsAt,
+		Status:     a.Status,
+		Notes:      a.Notes,
+		Revision:   a.Revision,
+		CreatedAt:  a.CreatedAt,
+		CreatedBy:  a.CreatedBy,
+		UpdatedAt:  a.UpdatedAt,
+		UpdatedBy:  a.UpdatedBy,
+	}
+}
+
+func fromAppointmentDocument(doc *appointmentDocument) (*estate.Appointment, error) {
+	id, err := uuid.Parse(doc.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid appointment ID format: %w", err)
+	}
+	propertyID, err := uuid.Parse(doc.PropertyID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid appointment property ID format: %w", err)
+	}
+	return &estate.Appointment{
+		ID:         id,
+		PropertyID: propertyID,
+		AgentID:    doc.AgentID,
+		Client:     doc.Client,
+		StartsAt:   doc.StartsAt.UTC(),
+		EndsAt:     doc.EndsAt.UTC(),
+		Status:     doc.Status,
+		Notes:      doc.Notes,
+		Revision:   doc.Revision,
+		CreatedAt:  doc.CreatedAt,
+		CreatedBy:  doc.CreatedBy,
+		UpdatedAt:  doc.UpdatedAt,
+		UpdatedBy:  doc.UpdatedBy,
+	}, nil
+}
@@ -0,0 +1,21 @@
+package mongo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pulap/pulap/services/estate/internal/estate"
+	"github.com/pulap/pulap/services/estate/internal/estate/repotest"
+)
+
+func TestAppointmentRepo(t *testing.T) {
+	uri := mongoTestURI(t)
+	repotest.RunAppointmentRepo(t, func(t *testing.T) (estate.Repo, estate.AppointmentRepo) {
+		properties := startTestRepo(t, uri)
+		repo := NewAppointmentRepo(properties, properties.xparams)
+		if err := repo.Start(context.Background()); err != nil {
+			t.Fatalf("Start: %v", err)
+		}
+		return properties, repo
+	})
+}
@@ -0,0 +1,246 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/pulap/pulap/services/estate/internal/config"
+	"github.com/pulap/pulap/services/estate/internal/estate"
+)
+
+// DevelopmentRepo implements the estate.DevelopmentRepo interface using
+// MongoDB. Developments are stored in the database of the property
+// repository, which must be started first.
+type DevelopmentRepo struct {
+	properties *PropertyRepo
+	collection *mongo.Collection
+	xparams    config.XParams
+}
+
+// NewDevelopmentRepo creates a new MongoDB repository for Development
+// aggregates stored alongside properties.
+func NewDevelopmentRepo(properties *PropertyRepo, xparams config.XParams) *DevelopmentRepo {
+	return &DevelopmentRepo{
+		properties: properties,
+		xparams:    xparams,
+	}
+}
+
+// Start opens the developments collection and creates its indexes.
+func (r *DevelopmentRepo) Start(ctx context.Context) error {
+	if r.properties.db == nil {
+		return fmt.Errorf("property repository not started")
+	}
+
+	// Decode embedded documents (e.g. Location.Raw) as maps so they round-trip to JSON.
+	r.collection = r.properties.db.Collection("developments", options.Collection().
+		SetBSONOptions(&options.BSONOptions{DefaultDocumentM: true}))
+
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "name", Value: 1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "location.address.country", Value: 1}, {Key: "location.address.city", Value: 1}}},
+	})
+	if err != nil {
+		return fmt.Errorf("cannot create indexes: %w", err)
+	}
+	return nil
+}
+
+// developmentDocument is the stored form of a development.
+type developmentDocument struct {
+	ID           string          `bson:"_id"`
+	Kind         string          `bson:"kind"`
+	Name         string          `bson:"name"`
+	Description  string          `bson:"description"`
+	Developer    string          `bson:"developer,omitempty"`
+	Stage        string          `bson:"stage,omitempty"`
+	DeliveryDate string          `bson:"delivery_date,omitempty"`
+	Location     estate.Location `bson:"location"`
+	Amenities    []string        `bson:"amenities,omitempty"`
+	YearBuilt    int             `bson:"year_built,omitempty"`
+	OwnerID      string          `bson:"owner_id,omitempty"`
+	TeamID       string          `bson:"team_id,omitempty"`
+	Revision     int64           `bson:"revision"`
+	CreatedAt    time.Time       `bson:"created_at"`
+	CreatedBy    string          `bson:"created_by"`
+	UpdatedAt    time.Time       `bson:"updated_at"`
+	UpdatedBy    string          `bson:"updated_by"`
+}
+
+// CreateDevelopment stores a new development.
+func (r *DevelopmentRepo) CreateDevelopment(ctx context.Context, d *estate.Development) error {
+	d.BeforeCreate()
+	if _, err := r.collection.InsertOne(ctx, toDevelopmentDocument(d)); err != nil {
+		return fmt.Errorf("could not create development: %w", err)
+	}
+	return nil
+}
+
+// GetDevelopment retrieves a development.
+func (r *DevelopmentRepo) GetDevelopment(ctx context.Context, id uuid.UUID) (*estate.Development, error) {
+	var doc developmentDocument
+	err := r.collection.FindOne(ctx, bson.M{"_id": id.String()}).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("development %s: %w", id, estate.ErrDevelopmentNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not get development: %w", err)
+	}
+	return fromDevelopmentDocument(&doc)
+}
+
+// SaveDevelopment replaces a development if its revision still matches and
+// increments the revision.
+func (r *DevelopmentRepo) SaveDevelopment(ctx context.Context, d *estate.Development) error {
+	d.BeforeUpdate()
+
+	doc := toDevelopmentDocument(d)
+	doc.Revision++
+	result, err := r.collection.ReplaceOne(ctx, bson.M{"_id": doc.ID, "revision": d.Revision}, doc)
+	if err != nil {
+		return fmt.Errorf("could not save development: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return r.developmentRevisionError(ctx, d.ID)
+	}
+
+	d.Revision++
+	return nil
+}
+
+// DeleteDevelopment removes a development. A non-zero revision must match
+// the stored one.
+func (r *DevelopmentRepo) DeleteDevelopment(ctx context.Context, id uuid.UUID, revision int64) error {
+	filter := bson.M{"_id": id.String()}
+	if revision != 0 {
+		filter["revision"] = revision
+	}
+
+	result, err := r.collection.DeleteOne(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("could not delete development: %w", err)
+	}
+	if result.DeletedCount == 0 {
+		return r.developmentRevisionError(ctx, id)
+	}
+	return nil
+}
+
+// ListDevelopments lists the developments matching the query by name.
+func (r *DevelopmentRepo) ListDevelopments(ctx context.Context, query estate.DevelopmentQuery) ([]*estate.Development, error) {
+	filter := bson.M{}
+	if query.Kind != "" {
+		filter["kind"] = query.Kind
+	}
+	if query.City != "" {
+		filter["location.address.city"] = equalFold(query.City)
+	}
+	if query.Country != "" {
+		filter["location.address.country"] = equalFold(query.Country)
+	}
+	if query.Scoped {
+		// An empty $in matches nothing, a nil one is rejected
+		scopes := append([]string{}, query.Scopes...)
+		filter["$or"] = bson.A{
+			bson.M{"owner_id": bson.M{"$in": scopes}},
+			bson.M{"team_id": bson.M{"$in": scopes}},
+		}
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "name", Value: 1}, {Key: "_id", Value: 1}}).
+		SetLimit(int64(query.Limit))
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("could not list developments: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var developments []*estate.Development
+	for cursor.Next(ctx) {
+		var doc developmentDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("could not decode development: %w", err)
+		}
+		d, err := fromDevelopmentDocument(&doc)
+		if err != nil {
+			return nil, err
+		}
+		developments = append(developments, d)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("cursor error: %w", err)
+	}
+
+	return developments, nil
+}
+
+// developmentRevisionError explains why a conditional write matched no
+// document: the development is either missing or at another revision.
+func (r *DevelopmentRepo) developmentRevisionError(ctx context.Context, id uuid.UUID) error {
+	n, err := r.collection.CountDocuments(ctx, bson.M{"_id": id.String()})
+	if err != nil {
+		return fmt.Errorf("could not check development: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("development %s: %w", id, estate.ErrDevelopmentNotFound)
+	}
+	return fmt.Errorf("development %s: %w", id, estate.ErrRevisionConflict)
+}
+
+func toDevelopmentDocument(d *estate.Development) *developmentDocument {
+	return &developmentDocument{
+		ID:           d.ID.String(),
+		Kind:         d.Kind,
+		Name:         d.Name,
+		Description:  d.Description,
+		Developer:    d.Developer,
+		Stage:        d.Stage,
+		DeliveryDate: d.DeliveryDate,
+		Location:     d.Location,
+		Amenities:    d.Amenities,
+		YearBuilt:    d.YearBuilt,
+		OwnerID:      d.OwnerID,
+		TeamID:       d.TeamID,
+		Revision:     d.Revision,
+		CreatedAt:    d.CreatedAt,
+		CreatedBy:    d.CreatedBy,
+		UpdatedAt:    d.UpdatedAt,
+		UpdatedBy:    d.UpdatedBy,
+	}
+}
+
+func fromDevelopmentDocument(doc *developmentDocument) (*estate.Development, error) {
+	id, err := uuid.Parse(doc.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid development ID format: %w", err)
+	}
+	return &estate.Development{
+		ID:           id,
+		Kind:         doc.Kind,
+		Name:         doc.Name,
+		Description:  doc.Description,
+		Developer:    doc.Developer,
+		Stage:        doc.Stage,
+		DeliveryDate: doc.DeliveryDate,
+		Location:     doc.Location,
+		Amenities:    doc.Amenities,
+		YearBuilt:    doc.YearBuilt,
+		OwnerID:      doc.OwnerID,
+		TeamID:       doc.TeamID,
+		Revision:     doc.Revision,
+		CreatedAt:    doc.CreatedAt,
+		CreatedBy:    doc.CreatedBy,
+		UpdatedAt:    doc.UpdatedAt,
+		UpdatedBy:    doc.UpdatedBy,
+	}, nil
+}
@@ -0,0 +1,21 @@
+package mongo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pulap/pulap/services/estate/internal/estate"
+	"github.com/pulap/pulap/services/estate/internal/estate/repotest"
+)
+
+func TestDevelopmentRepo(t *testing.T) {
+	uri := mongoTestURI(t)
+	repotest.RunDevelopmentRepo(t, func(t *testing.T) estate.DevelopmentRepo {
+		properties := startTestRepo(t, uri)
+		repo := NewDevelopmentRepo(properties, properties.xparams)
+		if err := repo.Start(context.Background()); err != nil {
+			t.Fatalf("Start: %v", err)
+		}
+		return repo
+	})
+}
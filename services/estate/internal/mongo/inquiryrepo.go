@@ -0,0 +1,377 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/pulap/pulap/services/estate/internal/config"
+	"github.com/pulap/pulap/services/estate/internal/estate"
+)
+
+// Collections of inquiries and their notes, deleted by name when their
+// property is purged.
+const (
+	inquiriesCollection    = "inquiries"
+	inquiryNotesCollection = "inquiry_notes"
+)
+
+// InquiryRepo implements the estate.InquiryRepo interface using MongoDB.
+// Inquiries are stored in the database of the property repository, which
+// must be started first.
+type InquiryRepo struct {
+	properties *PropertyRepo
+	collection *mongo.Collection
+	notes      *mongo.Collection
+	xparams    config.XParams
+}
+
+// NewInquiryRepo creates a new MongoDB repository for Inquiry aggregates
+// stored alongside properties.
+func NewInquiryRepo(properties *PropertyRepo, xparams config.XParams) *InquiryRepo {
+	return &InquiryRepo{
+		properties: properties,
+		xparams:    xparams,
+	}
+}
+
+// Start opens the inquiries and inquiry notes collections and creates
+// their indexes.
+func (r *InquiryRepo) Start(ctx context.Context) error {
+	if r.properties.db == nil {
+		return fmt.Errorf("property repository not started")
+	}
+
+	r.collection = r.properties.db.Collection(inquiriesCollection)
+	r.notes = r.properties.db.Collection(inquiryNotesCollection)
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "property_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "agent_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "contact_lookup", Value: 1}, {Key: "property_id", Value: 1}, {Key: "created_at", Value: 1}}},
+		{Keys: bson.D{{Key: "created_at", Value: 1}}},
+	})
+	if err != nil {
+		return fmt.Errorf("cannot create indexes: %w", err)
+	}
+
+	_, err = r.notes.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "inquiry_id", Value: 1}, {Key: "created_at", Value: 1}}},
+	})
+	if err != nil {
+		return fmt.Errorf("cannot create indexes: %w", err)
+	}
+	return nil
+}
+
+// inquiryDocument is the stored form of an inquiry. The contact is only
+// stored sealed.
+type inquiryDocument struct {
+	ID            string     `bson:"_id"`
+	PropertyID    string     `bson:"property_id"`
+	ContactCT     []byte     `bson:"contact_ct"`
+	ContactIV     []byte     `bson:"contact_iv"`
+	ContactTag    []byte     `bson:"contact_tag"`
+	ContactLookup []byte     `bson:"contact_lookup"`
+	Message       string     `bson:"message,omitempty"`
+	Source        string     `bson:"source"`
+	AgentID       string     `bson:"agent_id"`
+	Stage         string     `bson:"stage"`
+	Outcome       string     `bson:"outcome"`
+	ClosedAt      *time.Time `bson:"closed_at,omitempty"`
+	Revision      int64      `bson:"revision"`
+	CreatedAt     time.Time  `bson:"created_at"`
+	CreatedBy     string     `bson:"created_by"`
+	UpdatedAt     time.Time  `bson:"updated_at"`
+	UpdatedBy     string     `bson:"updated_by"`
+}
+
+// inquiryNoteDocument is the stored form of a note of an inquiry.
+type inquiryNoteDocument struct {
+	ID        string    `bson:"_id"`
+	InquiryID string    `bson:"inquiry_id"`
+	Body      string    `bson:"body"`
+	CreatedAt time.Time `bson:"created_at"`
+	CreatedBy string    `bson:"created_by"`
+}
+
+// CreateInquiry stores a new inquiry.
+func (r *InquiryRepo) CreateInquiry(ctx context.Context, i *estate.Inquiry) error {
+	i.BeforeCreate()
+
+	if _, err := r.collection.InsertOne(ctx, toInquiryDocument(i)); err != nil {
+		return fmt.Errorf("could not create inquiry: %w", err)
+	}
+	return nil
+}
+
+// GetInquiry retrieves an inquiry.
+func (r *InquiryRepo) GetInquiry(ctx context.Context, id uuid.UUID) (*estate.Inquiry, error) {
+	var doc inquiryDocument
+	err := r.collection.FindOne(ctx, bson.M{"_id": id.String()}).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("inquiry %s: %w", id, estate.ErrInquiryNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not get inquiry: %w", err)
+	}
+	return fromInquiryDocument(&doc)
+}
+
+// SaveInquiry replaces an inquiry if its revision still matches and
+// increments the revision.
+func (r *InquiryRepo) SaveInquiry(ctx context.Context, i *estate.Inquiry) error {
+	i.BeforeUpdate()
+
+	doc := toInquiryDocument(i)
+	doc.Revision++
+	result, err := r.collection.ReplaceOne(ctx, bson.M{"_id": doc.ID, "revision": i.Revision}, doc)
+	if err != nil {
+		return fmt.Errorf("could not save inquiry: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return r.inquiryRevisionError(ctx, i.ID)
+	}
+
+	i.Revision++
+	return nil
+}
+
+// ListInquiries lists the inquiries matching the query, newest first.
+func (r *InquiryRepo) ListInquiries(ctx context.Context, query estate.InquiryQuery) ([]*estate.Inquiry, error) {
+	filter := bson.M{}
+	if len(query.PropertyIDs) > 0 {
+		ids := make([]string, 0, len(query.PropertyIDs))
+		for _, id := range query.PropertyIDs {
+			ids = append(ids, id.String())
+		}
+		filter["property_id"] = bson.M{"$in": ids}
+	}
+	if len(query.AgentIDs) > 0 {
+		filter["agent_id"] = bson.M{"$in": query.AgentIDs}
+	}
+	if query.Unassigned {
+		filter["agent_id"] = ""
+	}
+	if len(query.Stages) > 0 {
+		filter["stage"] = bson.M{"$in": query.Stages}
+	}
+	if len(query.Sources) > 0 {
+		filter["source"] = bson.M{"$in": query.Sources}
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: 1}}).
+		SetLimit(int64(query.Limit))
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("could not list inquiries: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var inquiries []*estate.Inquiry
+	for cursor.Next(ctx) {
+		var doc inquiryDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("could not decode inquiry: %w", err)
+		}
+		i, err := fromInquiryDocument(&doc)
+		if err != nil {
+			return nil, err
+		}
+		inquiries = append(inquiries, i)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("cursor error: %w", err)
+	}
+
+	return inquiries, nil
+}
+
+// HasInquirySince returns true if the contact with the lookup hash inquired
+// about the property since a time.
+func (r *InquiryRepo) HasInquirySince(ctx context.Context, propertyID uuid.UUID, contactLookup []byte, since time.Time) (bool, error) {
+	n, err := r.collection.CountDocuments(ctx, bson.M{
+		"contact_lookup": contactLookup,
+		"property_id":    propertyID.String(),
+		"created_at":     bson.M{"$gte": since.UTC()},
+	}, options.Count().SetLimit(1))
+	if err != nil {
+		return false, fmt.Errorf("could not check inquiries: %w", err)
+	}
+	return n > 0, nil
+}
+
+// AddInquiryNote attaches a note to an inquiry.
+func (r *InquiryRepo) AddInquiryNote(ctx context.Context, n *estate.InquiryNote) error {
+	count, err := r.collection.CountDocuments(ctx, bson.M{"_id": n.InquiryID.String()})
+	if err != nil {
+		return fmt.Errorf("could not check inquiry: %w", err)
+	}
+	if count == 0 {
+		return fmt.Errorf("inquiry %s: %w", n.InquiryID, estate.ErrInquiryNotFound)
+	}
+
+	n.BeforeCreate()
+	_, err = r.notes.InsertOne(ctx, &inquiryNoteDocument{
+		ID:        n.ID.String(),
+		InquiryID: n.InquiryID.String(),
+		Body:      n.Body,
+		CreatedAt: n.CreatedAt,
+		CreatedBy: n.CreatedBy,
+	})
+	if err != nil {
+		return fmt.Errorf("could not create inquiry note: %w", err)
+	}
+	return nil
+}
+
+// ListInquiryNotes lists the notes of an inquiry, oldest first.
+func (r *InquiryRepo) ListInquiryNotes(ctx context.Context, inquiryID uuid.UUID) ([]*estate.InquiryNote, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := r.notes.Find(ctx, bson.M{"inquiry_id": inquiryID.String()}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("could not list inquiry notes: %w", err)
+	}
+
+	var docs []inquiryNoteDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, fmt.Errorf("could not decode inquiry notes: %w", err)
+	}
+
+	notes := make([]*estate.InquiryNote, 0, len(docs))
+	for _, doc := range docs {
+		id, err := uuid.Parse(doc.ID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid inquiry note ID format: %w", err)
+		}
+		notes = append(notes, &estate.InquiryNote{
+			ID:        id,
+			InquiryID: inquiryID,
+			Body:      doc.Body,
+			CreatedAt: doc.CreatedAt,
+			CreatedBy: doc.CreatedBy,
+		})
+	}
+	return notes, nil
+}
+
+// CountInquiries counts the inquiries created since a time by property,
+// agent, stage and outcome.
+func (r *InquiryRepo) CountInquiries(ctx context.Context, since time.Time) ([]estate.InquiryCount, error) {
+	cursor, err := r.collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"created_at": bson.M{"$gte": since.UTC()}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id": bson.M{
+				"property_id": "$property_id",
+				"agent_id":    "$agent_id",
+				"stage":       "$stage",
+				"outcome":     "$outcome",
+			},
+			"count": bson.M{"$sum": 1},
+		}}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not count inquiries: %w", err)
+	}
+
+	var docs []struct {
+		Group struct {
+			PropertyID string `bson:"property_id"`
+			AgentID    string `bson:"agent_id"`
+			Stage      string `bson:"stage"`
+			Outcome    string `bson:"outcome"`
+		} `bson:"_id"`
+		Count int `bson:"count"`
+	}
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, fmt.Errorf("could not decode inquiry counts: %w", err)
+	}
+
+	counts := make([]estate.InquiryCount, 0, len(docs))
+	for _, doc := range docs {
+		propertyID, err := uuid.Parse(doc.Group.PropertyID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid inquiry property ID format: %w", err)
+		}
+		counts = append(counts, estate.InquiryCount{
+			PropertyID: propertyID,
+			AgentID:    doc.Group.AgentID,
+			Stage:      doc.Group.Stage,
+			Outcome:    doc.Group.Outcome,
+			Count:      doc.Count,
+		})
+	}
+	return counts, nil
+}
+
+// inquiryRevisionError explains why a conditional write matched no
+// document: the inquiry is either missing or at another revision.
+func (r *InquiryRepo) inquiryRevisionError(ctx context.Context, id uuid.UUID) error {
+	n, err := r.collection.CountDocuments(ctx, bson.M{"_id": id.String()})
+	if err != nil {
+		return fmt.Errorf("could not check inquiry: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("inquiry %s: %w", id, estate.ErrInquiryNotFound)
+	}
+	return fmt.Errorf("inquiry %s: %w", id, estate.ErrRevisionConflict)
+}
+
+func toInquiryDocument(i *estate.Inquiry) *inquiryDocument {
+	return &inquiryDocument{
+		ID:            i.ID.String(),
+		PropertyID:    i.PropertyID.String(),
+		ContactCT:     i.ContactCT,
+		ContactIV:     i.ContactIV,
+		ContactTag:    i.ContactTag,
+		ContactLookup: i.ContactLookup,
+		Message:       i.Message,
+		Source:        i.Source,
+		AgentID:       i.AgentID,
+		Stage:         i.Stage,
+		Outcome:       i.Outcome,
+		ClosedAt:      i.ClosedAt,
+		Revision:      i.Revision,
+		CreatedAt:     i.CreatedAt,
+		CreatedBy:     i.CreatedBy,
+		UpdatedAt:     i.UpdatedAt,
+		UpdatedBy:     i.UpdatedBy,
+	}
+}
+
+func fromInquiryDocument(doc *inquiryDocument) (*estate.Inquiry, error) {
+	id, err := uuid.Parse(doc.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid inquiry ID format: %w", err)
+	}
+	propertyID, err := uuid.Parse(doc.PropertyID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid inquiry property ID format: %w", err)
+	}
+	return &estate.Inquiry{
+		ID:            id,
+		PropertyID:    propertyID,
+		ContactCT:     doc.ContactCT,
+		ContactIV:     doc.ContactIV,
+		ContactTag:    doc.ContactTag,
+		ContactLookup: doc.ContactLookup,
+		Message:       doc.Message,
+		Source:        doc.Source,
+		AgentID:       doc.AgentID,
+		Stage:         doc.Stage,
+		Outcome:       doc.Outcome,
+		ClosedAt:      doc.ClosedAt,
+		Revision:      doc.Revision,
+		CreatedAt:     doc.CreatedAt,
+		CreatedBy:     doc.CreatedBy,
+		UpdatedAt:     doc.UpdatedAt,
+		UpdatedBy:     doc.UpdatedBy,
+	}, nil
+}
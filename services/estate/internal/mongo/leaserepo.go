@@ -0,0 +1,276 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/pulap/pulap/services/estate/internal/config"
+	"github.com/pulap/pulap/services/estate/internal/estate"
+)
+
+// leasesCollection holds the leases, deleted by name when their
+// property is purged.
+const leasesCollection = "leases"
+
+// LeaseRepo implements the estate.LeaseRepo interface using MongoDB.
+// Leases are stored in the database of the property repository, which
+// must be started first.
+type LeaseRepo struct {
+	properties *PropertyRepo
+	collection *mongo.Collection
+	xparams    config.XParams
+}
+
+// NewLeaseRepo creates a new MongoDB repository for Lease aggregates
+// stored alongside properties.
+func NewLeaseRepo(properties *PropertyRepo, xparams config.XParams) *LeaseRepo {
+	return &LeaseRepo{
+		properties: properties,
+		xparams:    xparams,
+	}
+}
+
+// Start opens the leases collection and creates its indexes.
+func (r *LeaseRepo) Start(ctx context.Context) error {
+	if r.properties.db == nil {
+		return fmt.Errorf("property repository not started")
+	}
+
+	r.collection = r.properties.db.Collection(leasesCollection)
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "property_id", Value: 1}, {Key: "start_date", Value: -1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "end_date", Value: 1}}},
+	})
+	if err != nil {
+		return fmt.Errorf("cannot create indexes: %w", err)
+	}
+	return nil
+}
+
+// leaseDocument is the stored form of a lease. LastDay is the day it
+// really ends, which overlaps are checked against.
+type leaseDocument struct {
+	ID                string            `bson:"_id"`
+	PropertyID        string            `bson:"property_id"`
+	Tenant            estate.Contact    `bson:"tenant"`
+	StartDate         string            `bson:"start_date"`
+	EndDate           string            `bson:"end_date"`
+	LastDay           string            `bson:"last_day"`
+	Rent              decimal           `bson:"rent"`
+	Currency          string            `bson:"currency"`
+	Frequency         string            `bson:"frequency"`
+	Deposit           decimal           `bson:"deposit"`
+	Indexation        estate.Indexation `bson:"indexation"`
+	Renewal           estate.Renewal    `bson:"renewal"`
+	Status            string            `bson:"status"`
+	TerminatedOn      string            `bson:"terminated_on,omitempty"`
+	TerminationReason string            `bson:"termination_reason,omitempty"`
+	Revision          int64             `bson:"revision"`
+	CreatedAt         time.Time         `bson:"created_at"`
+	CreatedBy         string            `bson:"created_by"`
+	UpdatedAt         time.Time         `bson:"updated_at"`
+	UpdatedBy         string            `bson:"updated_by"`
+}
+
+// CreateLease stores a new lease unless it overlaps another lease of the
+// property. The lease is inserted before the overlap check, so of two
+// concurrent creations at least the later one sees the other; a lease found
+// overlapping is deleted again.
+func (r *LeaseRepo) CreateLease(ctx context.Context, l *estate.Lease) error {
+	l.BeforeCreate()
+	doc := toLeaseDocument(l)
+
+	if _, err := r.collection.InsertOne(ctx, doc); err != nil {
+		return fmt.Errorf("could not create lease: %w", err)
+	}
+
+	var other leaseDocument
+	err := r.collection.FindOne(ctx, bson.M{
+		"_id":         bson.M{"$ne": doc.ID},
+		"property_id": doc.PropertyID,
+		"start_date":  bson.M{"$lte": doc.LastDay},
+		"last_day":    bson.M{"$gte": doc.StartDate},
+		"$expr":       bson.M{"$gte": bson.A{"$last_day", "$start_date"}},
+	}, options.FindOne().SetProjection(bson.M{"_id": 1})).Decode(&other)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil
+	}
+
+	// The lease must not outlive a failed check, even if ctx is done.
+	if _, derr := r.collection.DeleteOne(context.WithoutCancel(ctx), bson.M{"_id": doc.ID}); derr != nil {
+		return fmt.Errorf("could not delete overlapping lease %s: %w", doc.ID, derr)
+	}
+	if err != nil {
+		return fmt.Errorf("could not check lease overlap: %w", err)
+	}
+	return fmt.Errorf("%w: lease %s", estate.ErrLeaseOverlap, other.ID)
+}
+
+// GetLease retrieves a lease.
+func (r *LeaseRepo) GetLease(ctx context.Context, id uuid.UUID) (*estate.Lease, error) {
+	var doc leaseDocument
+	err := r.collection.FindOne(ctx, bson.M{"_id": id.String()}).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("lease %s: %w", id, estate.ErrLeaseNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not get lease: %w", err)
+	}
+	return fromLeaseDocument(&doc)
+}
+
+// SaveLease replaces a lease if its revision still matches and increments
+// the revision.
+func (r *LeaseRepo) SaveLease(ctx context.Context, l *estate.Lease) error {
+	l.BeforeUpdate()
+
+	doc := toLeaseDocument(l)
+	doc.Revision++
+	result, err := r.collection.ReplaceOne(ctx, bson.M{"_id": doc.ID, "revision": l.Revision}, doc)
+	if err != nil {
+		return fmt.Errorf("could not save lease: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return r.leaseRevisionError(ctx, l.ID)
+	}
+
+	l.Revision++
+	return nil
+}
+
+// ListLeases lists the leases matching the query, latest start first.
+func (r *LeaseRepo) ListLeases(ctx context.Context, query estate.LeaseQuery) ([]*estate.Lease, error) {
+	filter := bson.M{}
+	if len(query.PropertyIDs) > 0 {
+		ids := make([]string, 0, len(query.PropertyIDs))
+		for _, id := range query.PropertyIDs {
+			ids = append(ids, id.String())
+		}
+		filter["property_id"] = bson.M{"$in": ids}
+	}
+	if len(query.Statuses) > 0 {
+		filter["status"] = bson.M{"$in": query.Statuses}
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "start_date", Value: -1}, {Key: "_id", Value: 1}}).
+		SetLimit(int64(query.Limit))
+	return r.findLeases(ctx, filter, opts)
+}
+
+// ListExpiringLeases lists the active leases ending between two days,
+// soonest first.
+func (r *LeaseRepo) ListExpiringLeases(ctx context.Context, from, to string, limit int) ([]*estate.Lease, error) {
+	filter := bson.M{
+		"status":   estate.LeaseActive,
+		"end_date": bson.M{"$gte": from, "$lte": to},
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "end_date", Value: 1}, {Key: "_id", Value: 1}}).
+		SetLimit(int64(limit))
+	return r.findLeases(ctx, filter, opts)
+}
+
+func (r *LeaseRepo) findLeases(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]*estate.Lease, error) {
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("could not list leases: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var leases []*estate.Lease
+	for cursor.Next(ctx) {
+		var doc leaseDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("could not decode lease: %w", err)
+		}
+		l, err := fromLeaseDocument(&doc)
+		if err != nil {
+			return nil, err
+		}
+		leases = append(leases, l)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("cursor error: %w", err)
+	}
+
+	return leases, nil
+}
+
+// leaseRevisionError explains why a conditional write matched no document:
+// the lease is either missing or at another revision.
+func (r *LeaseRepo) leaseRevisionError(ctx context.Context, id uuid.UUID) error {
+	n, err := r.collection.CountDocuments(ctx, bson.M{"_id": id.String()})
+	if err != nil {
+		return fmt.Errorf("could not check lease: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("lease %s: %w", id, estate.ErrLeaseNotFound)
+	}
+	return fmt.Errorf("lease %s: %w", id, estate.ErrRevisionConflict)
+}
+
+func toLeaseDocument(l *estate.Lease) *leaseDocument {
+	return &leaseDocument{
+		ID:                l.ID.String(),
+		PropertyID:        l.PropertyID.String(),
+		Tenant:            l.Tenant,
+		StartDate:         l.StartDate,
+		EndDate:           l.EndDate,
+		LastDay:           l.LastDay(),
+		Rent:              decimal{l.Rent},
+		Currency:          l.Currency,
+		Frequency:         l.Frequency,
+		Deposit:           decimal{l.Deposit},
+		Indexation:        l.Indexation,
+		Renewal:           l.Renewal,
+		Status:            l.Status,
+		TerminatedOn:      l.TerminatedOn,
+		TerminationReason: l.TerminationReason,
+		Revision:          l.Revision,
+		CreatedAt:         l.CreatedAt,
+		CreatedBy:         l.CreatedBy,
+		UpdatedAt:         l.UpdatedAt,
+		UpdatedBy:         l.UpdatedBy,
+	}
+}
+
+func fromLeaseDocument(doc *leaseDocument) (*estate.Lease, error) {
+	id, err := uuid.Parse(doc.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid lease ID format: %w", err)
+	}
+	propertyID, err := uuid.Parse(doc.PropertyID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid lease property ID format: %w", err)
+	}
+	return &estate.Lease{
+		ID:                id,
+		PropertyID:        propertyID,
+		Tenant:            doc.Tenant,
+		StartDate:         doc.StartDate,
+		EndDate:           doc.EndDate,
+		Rent:              doc.Rent.Decimal,
+		Currency:          doc.Currency,
+		Frequency:         doc.Frequency,
+		Deposit:           doc.Deposit.Decimal,
+		Indexation:        doc.Indexation,
+		Renewal:           doc.Renewal,
+		Status:            doc.Status,
+		TerminatedOn:      doc.TerminatedOn,
+		TerminationReason: doc.TerminationReason,
+		Revision:          doc.Revision,
+		CreatedAt:         doc.CreatedAt,
+		CreatedBy:         doc.CreatedBy,
+		UpdatedAt:         doc.UpdatedAt,
+		UpdatedBy:         doc.UpdatedBy,
+	}, nil
+}
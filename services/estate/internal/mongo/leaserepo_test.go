@@ -0,0 +1,21 @@
+package mongo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pulap/pulap/services/estate/internal/estate"
+	"github.com/pulap/pulap/services/estate/internal/estate/repotest"
+)
+
+func TestLeaseRepo(t *testing.T) {
+	uri := mongoTestURI(t)
+	repotest.RunLeaseRepo(t, func(t *testing.T) (estate.Repo, estate.LeaseRepo) {
+		properties := startTestRepo(t, uri)
+		repo := NewLeaseRepo(properties, properties.xparams)
+		if err := repo.Start(context.Background()); err != nil {
+			t.Fatalf("Start: %v", err)
+		}
+		return properties, repo
+	})
+}
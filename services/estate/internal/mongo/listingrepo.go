@@ -0,0 +1,314 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/pulap/pulap/services/estate/internal/config"
+	"github.com/pulap/pulap/services/estate/internal/estate"
+)
+
+// listingsCollection holds the listings, deleted by name when their
+// property is purged.
+const listingsCollection = "listings"
+
+// ListingRepo implements the estate.ListingRepo interface using MongoDB.
+// Listings are stored in the database of the property repository, which
+// must be started first.
+type ListingRepo struct {
+	properties *PropertyRepo
+	collection *mongo.Collection
+	xparams    config.XParams
+}
+
+// NewListingRepo creates a new MongoDB repository for Listing aggregates
+// stored alongside properties.
+func NewListingRepo(properties *PropertyRepo, xparams config.XParams) *ListingRepo {
+	return &ListingRepo{
+		properties: properties,
+		xparams:    xparams,
+	}
+}
+
+// Start opens the listings collection and creates its indexes.
+func (r *ListingRepo) Start(ctx context.Context) error {
+	if r.properties.db == nil {
+		return fmt.Errorf("property repository not started")
+	}
+
+	r.collection = r.properties.db.Collection(listingsCollection)
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "property_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: -1}}},
+	})
+	if err != nil {
+		return fmt.Errorf("cannot create indexes: %w", err)
+	}
+	return nil
+}
+
+// listingDocument is the stored form of a listing. Its price history is kept
+// in the same document so it is written atomically with the price.
+type listingDocument struct {
+	ID         string                           `bson:"_id"`
+	PropertyID string                           `bson:"property_id"`
+	Channel    string                           `bson:"channel"`
+	Price      priceDocument                    `bson:"price"`
+	Content    map[string]estate.ListingContent `bson:"content"`
+	Status     string                           `bson:"status"`
+	StartsAt   *time.Time                       `bson:"starts_at"`
+	EndsAt     *time.Time                       `bson:"ends_at"`
+	EndedAt    *time.Time                       `bson:"ended_at,omitempty"`
+	Prices     []listingPriceDocument           `bson:"prices,omitempty"`
+	Revision   int64                            `bson:"revision"`
+	CreatedAt  time.Time                        `bson:"created_at"`
+	CreatedBy  string                           `bson:"created_by"`
+	UpdatedAt  time.Time                        `bson:"updated_at"`
+	UpdatedBy  string                           `bson:"updated_by"`
+}
+
+// listingPriceDocument is an entry of the price history of a listing.
+type listingPriceDocument struct {
+	Price priceDocument `bson:"price"`
+	Actor string        `bson:"actor"`
+	At    time.Time     `bson:"at"`
+}
+
+// withoutPrices leaves the price history out of listing reads.
+var withoutPrices = bson.M{"prices": 0}
+
+// CreateListing stores a new listing and the first entry of its price history.
+func (r *ListingRepo) CreateListing(ctx context.Context, l *estate.Listing) error {
+	l.BeforeCreate()
+
+	doc := toListingDocument(l)
+	doc.Prices = []listingPriceDocument{{Price: doc.Price, Actor: l.CreatedBy, At: l.CreatedAt}}
+	if _, err := r.collection.InsertOne(ctx, doc); err != nil {
+		return fmt.Errorf("could not create listing: %w", err)
+	}
+	return nil
+}
+
+// GetListing retrieves a listing.
+func (r *ListingRepo) GetListing(ctx context.Context, id uuid.UUID) (*estate.Listing, error) {
+	var doc listingDocument
+	err := r.collection.FindOne(ctx, bson.M{"_id": id.String()}, options.FindOne().SetProjection(withoutPrices)).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("listing %s: %w", id, estate.ErrListingNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not get listing: %w", err)
+	}
+	return fromListingDocument(&doc)
+}
+
+// SaveListing updates a listing if its revision still matches, increments
+// the revision and records the price when it changed.
+func (r *ListingRepo) SaveListing(ctx context.Context, l *estate.Listing) error {
+	l.BeforeUpdate()
+
+	before, err := r.GetListing(ctx, l.ID)
+	if err != nil {
+		return err
+	}
+
+	doc := toListingDocument(l)
+	update := bson.M{
+		"$set": bson.M{
+			"channel":    doc.Channel,
+			"price":      doc.Price,
+			"content":    doc.Content,
+			"status":     doc.Status,
+			"starts_at":  doc.StartsAt,
+			"ends_at":    doc.EndsAt,
+			"ended_at":   doc.EndedAt,
+			"updated_at": doc.UpdatedAt,
+			"updated_by": doc.UpdatedBy,
+		},
+		"$inc": bson.M{"revision": 1},
+	}
+	if !samePrice(before.Price, l.Price) {
+		update["$push"] = bson.M{"prices": listingPriceDocument{Price: doc.Price, Actor: l.UpdatedBy, At: l.UpdatedAt}}
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": doc.ID, "revision": l.Revision}, update)
+	if err != nil {
+		return fmt.Errorf("could not save listing: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return r.listingRevisionError(ctx, l.ID)
+	}
+
+	l.Revision++
+	return nil
+}
+
+// DeleteListing removes a listing and its price history. A non-zero
+// revision must match the stored one.
+func (r *ListingRepo) DeleteListing(ctx context.Context, id uuid.UUID, revision int64) error {
+	filter := bson.M{"_id": id.String()}
+	if revision != 0 {
+		filter["revision"] = revision
+	}
+
+	result, err := r.collection.DeleteOne(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("could not delete listing: %w", err)
+	}
+	if result.DeletedCount == 0 {
+		return r.listingRevisionError(ctx, id)
+	}
+	return nil
+}
+
+// ListListings lists the listings matching the query, newest first.
+func (r *ListingRepo) ListListings(ctx context.Context, query estate.ListingQuery) ([]*estate.Listing, error) {
+	filter := bson.M{}
+	if len(query.PropertyIDs) > 0 {
+		ids := make([]string, 0, len(query.PropertyIDs))
+		for _, id := range query.PropertyIDs {
+			ids = append(ids, id.String())
+		}
+		filter["property_id"] = bson.M{"$in": ids}
+	}
+	if len(query.Channels) > 0 {
+		filter["channel"] = bson.M{"$in": query.Channels}
+	}
+	if len(query.Statuses) > 0 {
+		filter["status"] = bson.M{"$in": query.Statuses}
+	}
+	if query.LiveAt != nil {
+		at := query.LiveAt.UTC()
+		filter["$and"] = bson.A{
+			bson.M{"status": estate.ListingActive},
+			bson.M{"$or": bson.A{bson.M{"starts_at": nil}, bson.M{"starts_at": bson.M{"$lte": at}}}},
+			bson.M{"$or": bson.A{bson.M{"ends_at": nil}, bson.M{"ends_at": bson.M{"$gt": at}}}},
+		}
+	}
+
+	opts := options.Find().
+		SetProjection(withoutPrices).
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: 1}}).
+		SetLimit(int64(query.Limit))
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("could not list listings: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var listings []*estate.Listing
+	for cursor.Next(ctx) {
+		var doc listingDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("could not decode listing: %w", err)
+		}
+		l, err := fromListingDocument(&doc)
+		if err != nil {
+			return nil, err
+		}
+		listings = append(listings, l)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("cursor error: %w", err)
+	}
+
+	return listings, nil
+}
+
+// ListListingPrices lists the price history of a listing, oldest first.
+func (r *ListingRepo) ListListingPrices(ctx context.Context, id uuid.UUID) ([]estate.ListingPrice, error) {
+	var doc listingDocument
+	opts := options.FindOne().SetProjection(bson.M{"prices": 1})
+	err := r.collection.FindOne(ctx, bson.M{"_id": id.String()}, opts).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not list listing prices: %w", err)
+	}
+
+	var prices []estate.ListingPrice
+	for _, p := range doc.Prices {
+		prices = append(prices, estate.ListingPrice{
+			ListingID: id,
+			Price:     estate.Price{Amount: p.Price.Amount.Decimal, Currency: p.Price.Currency, Type: p.Price.Type, Negotiable: p.Price.Negotiable},
+			Actor:     p.Actor,
+			At:        p.At,
+		})
+	}
+	return prices, nil
+}
+
+// listingRevisionError explains why a conditional write matched no
+// document: the listing is either missing or at another revision.
+func (r *ListingRepo) listingRevisionError(ctx context.Context, id uuid.UUID) error {
+	n, err := r.collection.CountDocuments(ctx, bson.M{"_id": id.String()})
+	if err != nil {
+		return fmt.Errorf("could not check listing: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("listing %s: %w", id, estate.ErrListingNotFound)
+	}
+	return fmt.Errorf("listing %s: %w", id, estate.ErrRevisionConflict)
+}
+
+// samePrice returns true if two prices are equal, whatever the scale of
+// their amounts.
+func samePrice(a, b estate.Price) bool {
+	return a.Amount.Equal(b.Amount) && a.Currency == b.Currency && a.Type == b.Type && a.Negotiable == b.Negotiable
+}
+
+func toListingDocument(l *estate.Listing) *listingDocument {
+	return &listingDocument{
+		ID:         l.ID.String(),
+		PropertyID: l.PropertyID.String(),
+		Channel:    l.Channel,
+		Price:      priceDocument{Amount: decimal{l.Price.Amount}, Currency: l.Price.Currency, Type: l.Price.Type, Negotiable: l.Price.Negotiable},
+		Content:    l.Content,
+		Status:     l.Status,
+		StartsAt:   l.StartsAt,
+		EndsAt:     l.EndsAt,
+		EndedAt:    l.EndedAt,
+		Revision:   l.Revision,
+		CreatedAt:  l.CreatedAt,
+		CreatedBy:  l.CreatedBy,
+		UpdatedAt:  l.UpdatedAt,
+		UpdatedBy:  l.UpdatedBy,
+	}
+}
+
+func fromListingDocument(doc *listingDocument) (*estate.Listing, error) {
+	id, err := uuid.Parse(doc.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid listing ID format: %w", err)
+	}
+	propertyID, err := uuid.Parse(doc.PropertyID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid listing property ID format: %w", err)
+	}
+	return &estate.Listing{
+		ID:         id,
+		PropertyID: propertyID,
+		Channel:    doc.Channel,
+		Price:      estate.Price{Amount: doc.Price.Amount.Decimal, Currency: doc.Price.Currency, Type: doc.Price.Type, Negotiable: doc.Price.Negotiable},
+		Content:    doc.Content,
+		Status:     doc.Status,
+		StartsAt:   doc.StartsAt,
+		EndsAt:     doc.EndsAt,
+		EndedAt:    doc.EndedAt,
+		Revision:   doc.Revision,
+		CreatedAt:  doc.CreatedAt,
+		CreatedBy:  doc.CreatedBy,
+		UpdatedAt:  doc.UpdatedAt,
+		UpdatedBy:  doc.UpdatedBy,
+	}, nil
+}
@@ -0,0 +1,166 @@
+package mongo
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/services/estate/internal/estate"
+)
+
+// propertyDocument represents the MongoDB document structure for a Property.
+// IDs are stored as strings so they can be matched by the string filters used
+// throughout the repository.
+type propertyDocument struct {
+	ID             string                 `bson:"_id"`
+	Name           string                 `bson:"name"`
+	Description    string                 `bson:"description"`
+	Classification classificationDocument `bson:"classification"`
+	Location       estate.Location        `bson:"location"`
+	Geo            *geoPoint              `bson:"geo,omitempty"` // Indexed copy of the coordinates, absent when unset
+	Features       estate.Features        `bson:"features"`
+	Prices         []priceDocument        `bson:"prices"`
+	Valuation      valuationDocument      `bson:"valuation"`
+	Status         string                 `bson:"status"`
+	OwnerID        string                 `bson:"owner_id,omitempty"`
+	TeamID         string                 `bson:"team_id,omitempty"`
+	DevelopmentID  string                 `bson:"development_id,omitempty"`
+	SchemaVersion  int                    `bson:"schema_version"`
+	Revision       int64                  `bson:"revision"`
+	CreatedAt      time.Time              `bson:"created_at"`
+	CreatedBy      string                 `bson:"created_by"`
+	UpdatedAt      time.Time              `bson:"updated_at"`
+	UpdatedBy      string                 `bson:"updated_by"`
+	DeletedAt      *time.Time             `bson:"deleted_at,omitempty"` // Absent unless the property is in the trash
+	DeletedBy      string                 `bson:"deleted_by,omitempty"`
+	Outbox         []eventDocument        `bson:"outbox,omitempty"`            // Pending events, see eventDocument
+	PendingRevs    []revisionDocument     `bson:"pending_revisions,omitempty"` // Pending revisions, see revisionDocument
+}
+
+// geoPoint is a GeoJSON Point; coordinates are [longitude, latitude].
+type geoPoint struct {
+	Type        string    `bson:"type"`
+	Coordinates []float64 `bson:"coordinates"`
+}
+
+type classificationDocument struct {
+	CategoryID string `bson:"category_id"`
+	TypeID     string `bson:"type_id"`
+	SubtypeID  string `bson:"subtype_id,omitempty"`
+}
+
+// toDocument converts a Property aggregate to its MongoDB document.
+func toDocument(p *estate.Property) *propertyDocument {
+	return &propertyDocument{
+		ID:          p.ID.String(),
+		Name:        p.Name,
+		Description: p.Description,
+		Classification: classificationDocument{
+			CategoryID: optionalUUIDString(p.Classification.CategoryID),
+			TypeID:     optionalUUIDString(p.Classification.TypeID),
+			SubtypeID:  optionalUUIDString(p.Classification.SubtypeID),
+		},
+		Location:      p.Location,
+		Geo:           toGeoPoint(p),
+		Features:      p.Features,
+		Prices:        toPriceDocuments(p.Prices),
+		Valuation:     toValuationDocument(p.Valuation),
+		Status:        p.Status,
+		OwnerID:       p.OwnerID,
+		TeamID:        p.TeamID,
+		DevelopmentID: uuidRefString(p.DevelopmentID),
+		SchemaVersion: p.SchemaVersion,
+		Revision:      p.Revision,
+		CreatedAt:     p.CreatedAt,
+		CreatedBy:     p.CreatedBy,
+		UpdatedAt:     p.UpdatedAt,
+		UpdatedBy:     p.UpdatedBy,
+		DeletedAt:     p.DeletedAt,
+		DeletedBy:     p.DeletedBy,
+	}
+}
+
+// fromDocument converts a MongoDB document to a Property aggregate,
+// upgrading documents stored with an older schema version (see estate.Schema).
+func fromDocument(doc *propertyDocument) (*estate.Property, error) {
+	id, err := uuid.Parse(doc.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid property ID format: %w", err)
+	}
+
+	property := &estate.Property{
+		ID:          id,
+		Name:        doc.Name,
+		Description: doc.Description,
+		Classification: estate.Classification{
+			CategoryID: parseOptionalUUID(doc.Classification.CategoryID),
+			TypeID:     parseOptionalUUID(doc.Classification.TypeID),
+			SubtypeID:  parseOptionalUUID(doc.Classification.SubtypeID),
+		},
+		Location:      doc.Location,
+		Features:      doc.Features,
+		Prices:        fromPriceDocuments(doc.Prices),
+		Valuation:     fromValuationDocument(doc.Valuation),
+		Status:        doc.Status,
+		OwnerID:       doc.OwnerID,
+		TeamID:        doc.TeamID,
+		DevelopmentID: parseUUIDRef(doc.DevelopmentID),
+		SchemaVersion: doc.SchemaVersion,
+		Revision:      doc.Revision,
+		CreatedAt:     doc.CreatedAt,
+		CreatedBy:     doc.CreatedBy,
+		UpdatedAt:     doc.UpdatedAt,
+		UpdatedBy:     doc.UpdatedBy,
+		DeletedBy:     doc.DeletedBy,
+	}
+	if doc.DeletedAt != nil {
+		deletedAt := doc.DeletedAt.UTC()
+		property.DeletedAt = &deletedAt
+	}
+
+	if _, err := estate.UpgradeSchema(property); err != nil {
+		return nil, err
+	}
+	return property, nil
+}
+
+func toGeoPoint(p *estate.Property) *geoPoint {
+	point, ok := estate.PointOf(p)
+	if !ok {
+		return nil
+	}
+	return &geoPoint{Type: "Point", Coordinates: []float64{point.Lng, point.Lat}}
+}
+
+func optionalUUIDString(id uuid.UUID) string {
+	if id == uuid.Nil {
+		return ""
+	}
+	return id.String()
+}
+
+func uuidRefString(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	return optionalUUIDString(*id)
+}
+
+func parseUUIDRef(s string) *uuid.UUID {
+	if id := parseOptionalUUID(s); id != uuid.Nil {
+		return &id
+	}
+	return nil
+}
+
+func parseOptionalUUID(s string) uuid.UUID {
+	if s == "" {
+		return uuid.Nil
+	}
+	id, err := uuid.Parse(s)
+	if err != nil {
+		return uuid.Nil
+	}
+	return id
+}
@@ -0,0 +1,312 @@
+package mongo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/pulap/pulap/services/estate/internal/estate"
+)
+
+// eventsCounter is the counters document positions of published events are
+// taken from.
+const eventsCounter = "property_events"
+
+// eventDocument is the stored form of a property event. Pending events are
+// kept in the outbox array of their property document, so they are written
+// in the same single-document write as the aggregate; once published they
+// are moved to the property_events collection with a position. The pending
+// events of a purged property wait in the property_outbox collection.
+type eventDocument struct {
+	ID          string     `bson:"_id"`
+	Type        string     `bson:"type"`
+	AggregateID string     `bson:"aggregate_id"`
+	Sequence    int64      `bson:"sequence"`
+	Actor       string     `bson:"actor"`
+	At          time.Time  `bson:"at"`
+	Data        string     `bson:"data"` // PropertyEventData as JSON
+	Position    int64      `bson:"position,omitempty"`
+	PublishedAt *time.Time `bson:"published_at,omitempty"`
+}
+
+// PendingEvents returns up to limit events from the outbox of the property
+// documents and the property_outbox collection, oldest first, with the
+// events of each property in sequence order. The events of the excluded
+// properties are left out.
+func (r *PropertyRepo) PendingEvents(ctx context.Context, limit int, exclude []uuid.UUID) ([]estate.Event, error) {
+	excluded := make([]string, 0, len(exclude))
+	for _, id := range exclude {
+		excluded = append(excluded, id.String())
+	}
+
+	opts := options.Find().
+		SetProjection(bson.M{"outbox": 1}).
+		SetSort(bson.D{{Key: "outbox.at", Value: 1}, {Key: "_id", Value: 1}}).
+		SetLimit(int64(limit))
+	filter := bson.M{"outbox.at": bson.M{"$exists": true}, "_id": bson.M{"$nin": excluded}}
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("could not list pending events: %w", err)
+	}
+
+	var docs []struct {
+		Outbox []eventDocument `bson:"outbox"`
+	}
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, fmt.Errorf("could not decode pending events: %w", err)
+	}
+
+	var pending []eventDocument
+	for _, doc := range docs {
+		pending = append(pending, doc.Outbox...)
+	}
+
+	opts = options.Find().SetSort(bson.D{{Key: "at", Value: 1}, {Key: "_id", Value: 1}}).SetLimit(int64(limit))
+	cursor, err = r.outbox.Find(ctx, bson.M{"aggregate_id": bson.M{"$nin": excluded}}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("could not list pending events of purged properties: %w", err)
+	}
+	var purged []eventDocument
+	if err := cursor.All(ctx, &purged); err != nil {
+		return nil, fmt.Errorf("could not decode pending events of purged properties: %w", err)
+	}
+	pending = append(pending, purged...)
+
+	// An event being moved by Purge may be in both places.
+	var events []estate.Event
+	seen := make(map[uuid.UUID]bool, len(pending))
+	byProperty := make(map[uuid.UUID][]estate.Event, len(docs))
+	for i := range pending {
+		e, err := fromEventDocument(&pending[i])
+		if err != nil {
+			return nil, err
+		}
+		if seen[e.ID] {
+			continue
+		}
+		seen[e.ID] = true
+		events = append(events, *e)
+		byProperty[e.AggregateID] = append(byProperty[e.AggregateID], *e)
+	}
+
+	// Clocks may disagree, so the events of each property are put back in
+	// sequence order within the places the sort gave them.
+	for _, list := range byProperty {
+		sort.SliceStable(list, func(i, j int) bool { return list[i].Sequence < list[j].Sequence })
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].At.Before(events[j].At) })
+	next := make(map[uuid.UUID]int, len(byProperty))
+	for i, e := range events {
+		events[i] = byProperty[e.AggregateID][next[e.AggregateID]]
+		next[e.AggregateID]++
+	}
+	if len(events) > limit {
+		events = events[:limit]
+	}
+	return events, nil
+}
+
+// MarkPublished moves the events to the property_events collection, in the
+// given order, and removes them from the outbox of their property or the
+// property_outbox collection. An event moved before keeps its position and
+// publish time.
+func (r *PropertyRepo) MarkPublished(ctx context.Context, events []estate.Event, at time.Time) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	first, err := r.nextPositions(ctx, len(events))
+	if err != nil {
+		return err
+	}
+
+	models := make([]mongo.WriteModel, 0, len(events))
+	ids := make([]string, 0, len(events))
+	for i := range events {
+		doc, err := toEventDocument(&events[i])
+		if err != nil {
+			return err
+		}
+		doc.Position = first + int64(i)
+		doc.PublishedAt = &at
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": doc.ID}).
+			SetUpdate(bson.M{"$setOnInsert": doc}).
+			SetUpsert(true))
+		ids = append(ids, doc.ID)
+	}
+
+	if _, err := r.events.BulkWrite(ctx, models); err != nil {
+		return fmt.Errorf("could not store published events: %w", err)
+	}
+
+	_, err = r.collection.UpdateMany(ctx,
+		bson.M{"outbox._id": bson.M{"$in": ids}},
+		bson.M{"$pull": bson.M{"outbox": bson.M{"_id": bson.M{"$in": ids}}}},
+	)
+	if err != nil {
+		return fmt.Errorf("could not clear published events: %w", err)
+	}
+
+	if _, err := r.outbox.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}}); err != nil {
+		return fmt.Errorf("could not clear published events of purged properties: %w", err)
+	}
+	return nil
+}
+
+// Events retrieves the published events matching the query by position.
+func (r *PropertyRepo) Events(ctx context.Context, query estate.EventQuery) ([]estate.Event, error) {
+	filter := bson.M{"position": bson.M{"$gt": query.After}}
+	if query.AggregateID != uuid.Nil {
+		filter["aggregate_id"] = query.AggregateID.String()
+	}
+	if len(query.Types) > 0 {
+		filter["type"] = bson.M{"$in": query.Types}
+	}
+	if !query.Since.IsZero() {
+		filter["at"] = bson.M{"$gte": query.Since}
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "position", Value: 1}})
+	if query.Limit > 0 {
+		opts.SetLimit(int64(query.Limit))
+	}
+
+	cursor, err := r.events.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("could not list events: %w", err)
+	}
+
+	var docs []eventDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, fmt.Errorf("could not decode events: %w", err)
+	}
+
+	events := make([]estate.Event, 0, len(docs))
+	for i := range docs {
+		e, err := fromEventDocument(&docs[i])
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, *e)
+	}
+	return events, nil
+}
+
+// nextPositions reserves n consecutive event positions and returns the first.
+func (r *PropertyRepo) nextPositions(ctx context.Context, n int) (int64, error) {
+	var counter struct {
+		Seq int64 `bson:"seq"`
+	}
+	err := r.counters.FindOneAndUpdate(ctx,
+		bson.M{"_id": eventsCounter},
+		bson.M{"$inc": bson.M{"seq": n}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&counter)
+	if err != nil {
+		return 0, fmt.Errorf("could not reserve event positions: %w", err)
+	}
+	return counter.Seq - int64(n) + 1, nil
+}
+
+// newRevision builds the revision recording a write and the event to add
+// to the outbox of the property with it. Both go in the write itself.
+func newRevision(ctx context.Context, action string, before, after *estate.Property) (revisionDocument, eventDocument, error) {
+	at := time.Now()
+	if after != nil {
+		at = after.UpdatedAt
+	}
+
+	rev, err := estate.NewRevision(ctx, action, before, after, at)
+	if err != nil {
+		return revisionDocument{}, eventDocument{}, err
+	}
+
+	event, err := toEventDocument(estate.NewEvent(rev, before))
+	if err != nil {
+		return revisionDocument{}, eventDocument{}, err
+	}
+	return toRevisionDocument(rev), event, nil
+}
+
+// pushPending returns the update operator adding a revision and its event
+// to a property document.
+func pushPending(rev revisionDocument, event eventDocument) bson.M {
+	return bson.M{"outbox": event, "pending_revisions": rev}
+}
+
+// replaceKeepingPending returns an update pipeline replacing a property
+// document with doc while keeping its pending events and revisions, adding
+// the given ones after them. Unlike a replace it does not drop the ones
+// added concurrently.
+func replaceKeepingPending(doc *propertyDocument, events []eventDocument, revisions []revisionDocument) mongo.Pipeline {
+	addedEvents := bson.A{}
+	for _, e := range events {
+		addedEvents = append(addedEvents, e)
+	}
+	addedRevisions := bson.A{}
+	for _, rev := range revisions {
+		addedRevisions = append(addedRevisions, rev)
+	}
+
+	outbox := bson.M{"$concatArrays": bson.A{bson.M{"$ifNull": bson.A{"$outbox", bson.A{}}}, bson.M{"$literal": addedEvents}}}
+	pending := bson.M{"$concatArrays": bson.A{bson.M{"$ifNull": bson.A{"$pending_revisions", bson.A{}}}, bson.M{"$literal": addedRevisions}}}
+	return mongo.Pipeline{{{Key: "$replaceWith", Value: bson.M{
+		"$mergeObjects": bson.A{bson.M{"$literal": doc}, bson.M{"outbox": outbox, "pending_revisions": pending}},
+	}}}}
+}
+
+func toEventDocument(e *estate.Event) (eventDocument, error) {
+	data, err := json.Marshal(e.Data)
+	if err != nil {
+		return eventDocument{}, fmt.Errorf("cannot encode event data: %w", err)
+	}
+
+	return eventDocument{
+		ID:          e.ID.String(),
+		Type:        e.Type,
+		AggregateID: e.AggregateID.String(),
+		Sequence:    e.Sequence,
+		Actor:       e.Actor,
+		At:          e.At,
+		Data:        string(data),
+		Position:    e.Position,
+		PublishedAt: e.PublishedAt,
+	}, nil
+}
+
+func fromEventDocument(doc *eventDocument) (*estate.Event, error) {
+	id, err := uuid.Parse(doc.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid event ID: %w", err)
+	}
+	aggregateID, err := uuid.Parse(doc.AggregateID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid event aggregate ID: %w", err)
+	}
+
+	e := &estate.Event{
+		ID:          id,
+		Type:        doc.Type,
+		AggregateID: aggregateID,
+		Sequence:    doc.Sequence,
+		Actor:       doc.Actor,
+		At:          doc.At.UTC(),
+		Position:    doc.Position,
+	}
+	if doc.PublishedAt != nil {
+		t := doc.PublishedAt.UTC()
+		e.PublishedAt = &t
+	}
+	if err := json.Unmarshal([]byte(doc.Data), &e.Data); err != nil {
+		return nil, fmt.Errorf("invalid event data: %w", err)
+	}
+	return e, nil
+}
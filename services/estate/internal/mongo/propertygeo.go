@@ -0,0 +1,106 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/pulap/pulap/services/estate/internal/estate"
+)
+
+// geoResultDocument is a property document annotated by $geoNear.
+type geoResultDocument struct {
+	propertyDocument `bson:",inline"`
+	Distance         float64 `bson:"distance"`
+}
+
+// SearchGeo retrieves properties inside the query area, nearest first.
+// It runs a $geoNear aggregation over the 2dsphere index on the geo field.
+func (r *PropertyRepo) SearchGeo(ctx context.Context, query estate.GeoQuery) ([]estate.GeoResult, error) {
+	filter := searchFilter(query.Filter)
+
+	geoNear := bson.M{
+		"near":          pointOf(query.Center),
+		"distanceField": "distance",
+		"key":           "geo",
+		"spherical":     true,
+	}
+
+	switch query.Shape {
+	case estate.GeoRadius:
+		geoNear["maxDistance"] = query.RadiusMeters
+	case estate.GeoBBox:
+		// Plain ranges match the lat/lng aligned rectangle, unlike a geodesic polygon.
+		b := query.BBox
+		filter["location.coordinates.latitude"] = bson.M{"$gte": b.South, "$lte": b.North}
+		filter["location.coordinates.longitude"] = bson.M{"$gte": b.West, "$lte": b.East}
+	case estate.GeoPolygon:
+		filter["geo"] = bson.M{"$geoWithin": bson.M{"$geometry": polygonOf(query.Polygon)}}
+	}
+	geoNear["query"] = filter
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$geoNear", Value: geoNear}},
+		{{Key: "$limit", Value: query.Limit}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("could not search properties by location: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var results []estate.GeoResult
+	for cursor.Next(ctx) {
+		var doc geoResultDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("could not decode Property aggregate: %w", err)
+		}
+		property, err := fromDocument(&doc.propertyDocument)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, estate.GeoResult{Property: property, DistanceMeters: doc.Distance})
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("cursor error while searching properties by location: %w", err)
+	}
+
+	return results, nil
+}
+
+// backfillGeo derives the geo field for documents stored before it existed.
+func (r *PropertyRepo) backfillGeo(ctx context.Context) error {
+	filter := bson.M{
+		"geo": bson.M{"$exists": false},
+		"$nor": bson.A{bson.M{
+			"location.coordinates.latitude":  0,
+			"location.coordinates.longitude": 0,
+		}},
+	}
+	update := mongo.Pipeline{{{Key: "$set", Value: bson.M{
+		"geo": bson.M{
+			"type":        "Point",
+			"coordinates": bson.A{"$location.coordinates.longitude", "$location.coordinates.latitude"},
+		},
+	}}}}
+
+	_, err := r.collection.UpdateMany(ctx, filter, update)
+	return err
+}
+
+func pointOf(p estate.GeoPoint) bson.M {
+	return bson.M{"type": "Point", "coordinates": bson.A{p.Lng, p.Lat}}
+}
+
+func polygonOf(vertices []estate.GeoPoint) bson.M {
+	ring := make(bson.A, 0, len(vertices)+1)
+	for _, v := range vertices {
+		ring = append(ring, bson.A{v.Lng, v.Lat})
+	}
+	ring = append(ring, bson.A{vertices[0].Lng, vertices[0].Lat})
+	return bson.M{"type": "Polygon", "coordinates": bson.A{ring}}
+}
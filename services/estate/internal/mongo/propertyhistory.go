@@ -0,0 +1,238 @@
+package mongo
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/pulap/pulap/services/estate/internal/estate"
+)
+
+// revisionDocument is the stored form of a property revision. Revisions are
+// only ever inserted and outlive the property they describe. A revision is
+// first written to the pending_revisions array of its property document, in
+// the same single-document write as the change it records, and then moved
+// to the property_revisions collection (see settleRevisions).
+type revisionDocument struct {
+	PropertyID   string            `bson:"property_id"`
+	Number       int64             `bson:"number"`
+	Action       string            `bson:"action"`
+	Actor        string            `bson:"actor"`
+	At           time.Time         `bson:"at"`
+	RestoredFrom int64             `bson:"restored_from,omitempty"`
+	Changes      []changeDocument  `bson:"changes"`
+	Snapshot     *propertyDocument `bson:"snapshot,omitempty"`
+}
+
+type changeDocument struct {
+	Path string `bson:"path"`
+	From any    `bson:"from"`
+	To   any    `bson:"to"`
+}
+
+// Revisions retrieves the revisions of a property without snapshots, newest
+// first, including the ones still pending in its document.
+func (r *PropertyRepo) Revisions(ctx context.Context, id uuid.UUID) ([]estate.PropertyRevision, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "number", Value: -1}}).
+		SetProjection(bson.M{"snapshot": 0})
+	cursor, err := r.revisions.Find(ctx, bson.M{"property_id": id.String()}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("could not list property revisions: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var revisions []estate.PropertyRevision
+	stored := make(map[int64]bool)
+	for cursor.Next(ctx) {
+		var doc revisionDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("could not decode property revision: %w", err)
+		}
+		rev, err := fromRevisionDocument(&doc)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, *rev)
+		stored[rev.Number] = true
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("cursor error while listing property revisions: %w", err)
+	}
+
+	pending, err := r.pendingRevisions(ctx, id.String())
+	if err != nil {
+		return nil, err
+	}
+	for i := range pending {
+		if stored[pending[i].Number] {
+			continue
+		}
+		pending[i].Snapshot = nil
+		rev, err := fromRevisionDocument(&pending[i])
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, *rev)
+	}
+	slices.SortFunc(revisions, func(a, b estate.PropertyRevision) int { return cmp.Compare(b.Number, a.Number) })
+
+	return revisions, nil
+}
+
+// Revision retrieves a single property revision including its snapshot.
+func (r *PropertyRepo) Revision(ctx context.Context, id uuid.UUID, number int64) (*estate.PropertyRevision, error) {
+	var doc revisionDocument
+	err := r.revisions.FindOne(ctx, bson.M{"property_id": id.String(), "number": number}).Decode(&doc)
+	if err == nil {
+		return fromRevisionDocument(&doc)
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("could not get property revision: %w", err)
+	}
+
+	pending, err := r.pendingRevisions(ctx, id.String())
+	if err != nil {
+		return nil, err
+	}
+	for i := range pending {
+		if pending[i].Number == number {
+			return fromRevisionDocument(&pending[i])
+		}
+	}
+	return nil, fmt.Errorf("revision %d of property %s: %w", number, id, estate.ErrRevisionNotFound)
+}
+
+// pendingRevisions returns the revisions not yet moved out of the document
+// of a property, in the trash or not.
+func (r *PropertyRepo) pendingRevisions(ctx context.Context, id string) ([]revisionDocument, error) {
+	var doc struct {
+		PendingRevisions []revisionDocument `bson:"pending_revisions"`
+	}
+	opts := options.FindOne().SetProjection(bson.M{"pending_revisions": 1})
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}, opts).Decode(&doc)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("could not get pending property revisions: %w", err)
+	}
+	return doc.PendingRevisions, nil
+}
+
+// settleRevisions moves the pending revisions of a property document to the
+// property_revisions collection. A revision moved before is left as it is,
+// so settling again after a failure is safe.
+func (r *PropertyRepo) settleRevisions(ctx context.Context, id string) error {
+	pending, err := r.pendingRevisions(ctx, id)
+	if err != nil || len(pending) == 0 {
+		return err
+	}
+
+	models := make([]mongo.WriteModel, 0, len(pending))
+	numbers := make([]int64, 0, len(pending))
+	for _, rev := range pending {
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"property_id": rev.PropertyID, "number": rev.Number}).
+			SetUpdate(bson.M{"$setOnInsert": rev}).
+			SetUpsert(true))
+		numbers = append(numbers, rev.Number)
+	}
+	if _, err := r.revisions.BulkWrite(ctx, models); err != nil {
+		return fmt.Errorf("could not record property revisions: %w", err)
+	}
+
+	_, err = r.collection.UpdateOne(ctx,
+		bson.M{"_id": id},
+		bson.M{"$pull": bson.M{"pending_revisions": bson.M{"number": bson.M{"$in": numbers}}}},
+	)
+	if err != nil {
+		return fmt.Errorf("could not clear recorded property revisions: %w", err)
+	}
+	return nil
+}
+
+// settle settles the revisions of a property after a write. The write is
+// already recorded in its document, so a failure is only logged; the next
+// write or a restart settles them.
+func (r *PropertyRepo) settle(ctx context.Context, id string) {
+	if err := r.settleRevisions(ctx, id); err != nil {
+		r.xparams.Log().Errorf("could not settle revisions of property %s: %v", id, err)
+	}
+}
+
+// settleAllRevisions settles the revisions left pending in any property
+// document, e.g. by a crash after a write.
+func (r *PropertyRepo) settleAllRevisions(ctx context.Context) error {
+	ids, err := r.collection.Distinct(ctx, "_id", bson.M{"pending_revisions.number": bson.M{"$exists": true}})
+	if err != nil {
+		return fmt.Errorf("could not list pending property revisions: %w", err)
+	}
+	for _, id := range ids {
+		if id, ok := id.(string); ok {
+			if err := r.settleRevisions(ctx, id); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func toRevisionDocument(rev *estate.PropertyRevision) revisionDocument {
+	doc := revisionDocument{
+		PropertyID:   rev.PropertyID.String(),
+		Number:       rev.Number,
+		Action:       rev.Action,
+		Actor:        rev.Actor,
+		At:           rev.At,
+		RestoredFrom: rev.RestoredFrom,
+		Changes:      make([]changeDocument, 0, len(rev.Changes)),
+		Snapshot:     toDocument(rev.Snapshot),
+	}
+	for _, c := range rev.Changes {
+		doc.Changes = append(doc.Changes, changeDocument{Path: c.Path, From: c.From, To: c.To})
+	}
+	return doc
+}
+
+// undo runs a compensating write after a failed insert and returns err.
+func (r *PropertyRepo) undo(id string, err error, compensate func() error) error {
+	if undoErr := compensate(); undoErr != nil {
+		r.xparams.Log().Errorf("could not undo write to property %s: %v", id, undoErr)
+	}
+	return err
+}
+
+func fromRevisionDocument(doc *revisionDocument) (*estate.PropertyRevision, error) {
+	propertyID, err := uuid.Parse(doc.PropertyID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid property ID: %w", err)
+	}
+
+	rev := &estate.PropertyRevision{
+		PropertyID:   propertyID,
+		Number:       doc.Number,
+		Action:       doc.Action,
+		Actor:        doc.Actor,
+		At:           doc.At.UTC(),
+		RestoredFrom: doc.RestoredFrom,
+		Changes:      make([]estate.FieldChange, 0, len(doc.Changes)),
+	}
+	for _, c := range doc.Changes {
+		rev.Changes = append(rev.Changes, estate.FieldChange{Path: c.Path, From: c.From, To: c.To})
+	}
+
+	if doc.Snapshot != nil {
+		if rev.Snapshot, err = fromDocument(doc.Snapshot); err != nil {
+			return nil, err
+		}
+	}
+
+	return rev, nil
+}
@@ -0,0 +1,210 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/pulap/pulap/services/estate/internal/estate"
+)
+
+// importDocument is the stored form of a bulk import job.
+type importDocument struct {
+	ID              string              `bson:"_id"`
+	Format          string              `bson:"format"`
+	Filename        string              `bson:"filename,omitempty"`
+	DryRun          bool                `bson:"dry_run"`
+	AllowDuplicates bool                `bson:"allow_duplicates,omitempty"`
+	Mapping         map[string]string   `bson:"mapping,omitempty"`
+	Ignored         []string            `bson:"ignored,omitempty"`
+	Status          string              `bson:"status"`
+	Total           int                 `bson:"total"`
+	Processed       int                 `bson:"processed"`
+	Valid           int                 `bson:"valid"`
+	Created         int                 `bson:"created"`
+	Invalid         int                 `bson:"invalid"`
+	Failed          int                 `bson:"failed"`
+	Errors          []importRowDocument `bson:"errors,omitempty"`
+	Error           string              `bson:"error,omitempty"`
+	CreatedAt       time.Time           `bson:"created_at"`
+	CreatedBy       string              `bson:"created_by"`
+	StartedAt       *time.Time          `bson:"started_at,omitempty"`
+	FinishedAt      *time.Time          `bson:"finished_at,omitempty"`
+}
+
+type importRowDocument struct {
+	Row        int                       `bson:"row"`
+	Status     string                    `bson:"status"`
+	Name       string                    `bson:"name,omitempty"`
+	PropertyID string                    `bson:"property_id,omitempty"`
+	Errors     []validationErrorDocument `bson:"errors,omitempty"`
+}
+
+type validationErrorDocument struct {
+	Field   string `bson:"field"`
+	Message string `bson:"message"`
+}
+
+// CreateImport stores a new import job.
+func (r *PropertyRepo) CreateImport(ctx context.Context, job *estate.ImportJob) error {
+	if _, err := r.imports.InsertOne(ctx, toImportDocument(job)); err != nil {
+		return fmt.Errorf("could not create import: %w", err)
+	}
+	return nil
+}
+
+// GetImport retrieves an import job.
+func (r *PropertyRepo) GetImport(ctx context.Context, id uuid.UUID) (*estate.ImportJob, error) {
+	var doc importDocument
+	err := r.imports.FindOne(ctx, bson.M{"_id": id.String()}).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("import %s: %w", id, estate.ErrImportNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not get import: %w", err)
+	}
+	return fromImportDocument(&doc)
+}
+
+// SaveImport updates the progress and outcome of an import job.
+func (r *PropertyRepo) SaveImport(ctx context.Context, job *estate.ImportJob) error {
+	doc := toImportDocument(job)
+	result, err := r.imports.UpdateOne(ctx, bson.M{"_id": doc.ID}, bson.M{"$set": bson.M{
+		"status":      doc.Status,
+		"total":       doc.Total,
+		"processed":   doc.Processed,
+		"valid":       doc.Valid,
+		"created":     doc.Created,
+		"invalid":     doc.Invalid,
+		"failed":      doc.Failed,
+		"errors":      doc.Errors,
+		"error":       doc.Error,
+		"started_at":  doc.StartedAt,
+		"finished_at": doc.FinishedAt,
+	}})
+	if err != nil {
+		return fmt.Errorf("could not update import: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("import %s: %w", job.ID, estate.ErrImportNotFound)
+	}
+	return nil
+}
+
+// ListImports lists import jobs, newest first. A limit of zero lists every job.
+func (r *PropertyRepo) ListImports(ctx context.Context, limit int) ([]estate.ImportJob, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: 1}})
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+
+	cursor, err := r.imports.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("could not list imports: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var jobs []estate.ImportJob
+	for cursor.Next(ctx) {
+		var doc importDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("could not decode import: %w", err)
+		}
+		job, err := fromImportDocument(&doc)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, *job)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("cursor error: %w", err)
+	}
+
+	return jobs, nil
+}
+
+func toImportDocument(job *estate.ImportJob) *importDocument {
+	doc := &importDocument{
+		ID:              job.ID.String(),
+		Format:          job.Format,
+		Filename:        job.Filename,
+		DryRun:          job.DryRun,
+		AllowDuplicates: job.AllowDuplicates,
+		Mapping:         job.Mapping,
+		Ignored:         job.Ignored,
+		Status:          job.Status,
+		Total:           job.Total,
+		Processed:       job.Processed,
+		Valid:           job.Valid,
+		Created:         job.Created,
+		Invalid:         job.Invalid,
+		Failed:          job.Failed,
+		Error:           job.Error,
+		CreatedAt:       job.CreatedAt,
+		CreatedBy:       job.CreatedBy,
+		StartedAt:       job.StartedAt,
+		FinishedAt:      job.FinishedAt,
+	}
+	for _, row := range job.Errors {
+		rd := importRowDocument{Row: row.Row, Status: row.Status, Name: row.Name}
+		if row.PropertyID != nil {
+			rd.PropertyID = row.PropertyID.String()
+		}
+		for _, e := range row.Errors {
+			rd.Errors = append(rd.Errors, validationErrorDocument{Field: e.Field, Message: e.Message})
+		}
+		doc.Errors = append(doc.Errors, rd)
+	}
+	return doc
+}
+
+func fromImportDocument(doc *importDocument) (*estate.ImportJob, error) {
+	id, err := uuid.Parse(doc.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid import ID %q: %w", doc.ID, err)
+	}
+
+	job := &estate.ImportJob{
+		ID:              id,
+		Format:          doc.Format,
+		Filename:        doc.Filename,
+		DryRun:          doc.DryRun,
+		AllowDuplicates: doc.AllowDuplicates,
+		Mapping:         doc.Mapping,
+		Ignored:         doc.Ignored,
+		Status:          doc.Status,
+		Total:           doc.Total,
+		Processed:       doc.Processed,
+		Valid:           doc.Valid,
+		Created:         doc.Created,
+		Invalid:         doc.Invalid,
+		Failed:          doc.Failed,
+		Error:           doc.Error,
+		CreatedAt:       doc.CreatedAt,
+		CreatedBy:       doc.CreatedBy,
+		StartedAt:       doc.StartedAt,
+		FinishedAt:      doc.FinishedAt,
+	}
+	for _, rd := range doc.Errors {
+		row := estate.ImportRowResult{Row: rd.Row, Status: rd.Status, Name: rd.Name}
+		if rd.PropertyID != "" {
+			propertyID, err := uuid.Parse(rd.PropertyID)
+			if err != nil {
+				return nil, fmt.Errorf("invalid property ID %q: %w", rd.PropertyID, err)
+			}
+			row.PropertyID = &propertyID
+		}
+		for _, e := range rd.Errors {
+			row.Errors = append(row.Errors, estate.ValidationError{Field: e.Field, Message: e.Message})
+		}
+		job.Errors = append(job.Errors, row)
+	}
+	return job, nil
+}
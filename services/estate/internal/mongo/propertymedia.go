@@ -0,0 +1,206 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/pulap/pulap/services/estate/internal/estate"
+)
+
+// mediaDocument is the stored form of a property media item.
+type mediaDocument struct {
+	ID          string              `bson:"_id"`
+	PropertyID  string              `bson:"property_id"`
+	Kind        string              `bson:"kind"`
+	Filename    string              `bson:"filename"`
+	ContentType string              `bson:"content_type"`
+	Size        int64               `bson:"size"`
+	Width       int                 `bson:"width,omitempty"`
+	Height      int                 `bson:"height,omitempty"`
+	Position    int                 `bson:"position"`
+	Cover       bool                `bson:"cover"`
+	Captions    map[string]string   `bson:"captions,omitempty"`
+	Thumbnails  []thumbnailDocument `bson:"thumbnails,omitempty"`
+	CreatedAt   time.Time           `bson:"created_at"`
+	CreatedBy   string              `bson:"created_by"`
+}
+
+type thumbnailDocument struct {
+	Size        string `bson:"size"`
+	ContentType string `bson:"content_type"`
+	Width       int    `bson:"width"`
+	Height      int    `bson:"height"`
+}
+
+// CreateMedia stores the metadata of a media item. The property must exist.
+func (r *PropertyRepo) CreateMedia(ctx context.Context, m *estate.Media) error {
+	count, err := r.collection.CountDocuments(ctx, bson.M{"_id": m.PropertyID.String()})
+	if err != nil {
+		return fmt.Errorf("could not check property: %w", err)
+	}
+	if count == 0 {
+		return fmt.Errorf("Property aggregate with ID %s: %w", m.PropertyID, estate.ErrNotFound)
+	}
+
+	if _, err := r.media.InsertOne(ctx, toMediaDocument(m)); err != nil {
+		return fmt.Errorf("could not create media: %w", err)
+	}
+	return nil
+}
+
+// GetMedia retrieves a media item of a property.
+func (r *PropertyRepo) GetMedia(ctx context.Context, propertyID, id uuid.UUID) (*estate.Media, error) {
+	var doc mediaDocument
+	err := r.media.FindOne(ctx, bson.M{"_id": id.String(), "property_id": propertyID.String()}).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("media %s of property %s: %w", id, propertyID, estate.ErrMediaNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not get media: %w", err)
+	}
+	return fromMediaDocument(&doc)
+}
+
+// ListMedia lists the media of a property by position.
+func (r *PropertyRepo) ListMedia(ctx context.Context, propertyID uuid.UUID) ([]estate.Media, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "position", Value: 1}, {Key: "created_at", Value: 1}})
+	cursor, err := r.media.Find(ctx, bson.M{"property_id": propertyID.String()}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("could not list media: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var media []estate.Media
+	for cursor.Next(ctx) {
+		var doc mediaDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("could not decode media: %w", err)
+		}
+		m, err := fromMediaDocument(&doc)
+		if err != nil {
+			return nil, err
+		}
+		media = append(media, *m)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("cursor error: %w", err)
+	}
+
+	return media, nil
+}
+
+// SaveMedia updates the position, cover flag and captions of a media item.
+func (r *PropertyRepo) SaveMedia(ctx context.Context, m *estate.Media) error {
+	update := bson.M{"$set": bson.M{"position": m.Position, "cover": m.Cover, "captions": m.Captions}}
+	if len(m.Captions) == 0 {
+		update = bson.M{
+			"$set":   bson.M{"position": m.Position, "cover": m.Cover},
+			"$unset": bson.M{"captions": ""},
+		}
+	}
+
+	result, err := r.media.UpdateOne(ctx, bson.M{"_id": m.ID.String(), "property_id": m.PropertyID.String()}, update)
+	if err != nil {
+		return fmt.Errorf("could not update media: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("media %s of property %s: %w", m.ID, m.PropertyID, estate.ErrMediaNotFound)
+	}
+	return nil
+}
+
+// ArrangeMedia updates the position and cover flag of items in one bulk write.
+func (r *PropertyRepo) ArrangeMedia(ctx context.Context, propertyID uuid.UUID, items []estate.Media) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	models := make([]mongo.WriteModel, 0, len(items))
+	for _, m := range items {
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": m.ID.String(), "property_id": propertyID.String()}).
+			SetUpdate(bson.M{"$set": bson.M{"position": m.Position, "cover": m.Cover}}))
+	}
+
+	result, err := r.media.BulkWrite(ctx, models)
+	if err != nil {
+		return fmt.Errorf("could not arrange media: %w", err)
+	}
+	if result.MatchedCount != int64(len(items)) {
+		return fmt.Errorf("media of property %s: %w", propertyID, estate.ErrMediaNotFound)
+	}
+	return nil
+}
+
+// DeleteMedia deletes a media item.
+func (r *PropertyRepo) DeleteMedia(ctx context.Context, propertyID, id uuid.UUID) error {
+	result, err := r.media.DeleteOne(ctx, bson.M{"_id": id.String(), "property_id": propertyID.String()})
+	if err != nil {
+		return fmt.Errorf("could not delete media: %w", err)
+	}
+	if result.DeletedCount == 0 {
+		return fmt.Errorf("media %s of property %s: %w", id, propertyID, estate.ErrMediaNotFound)
+	}
+	return nil
+}
+
+func toMediaDocument(m *estate.Media) *mediaDocument {
+	doc := &mediaDocument{
+		ID:          m.ID.String(),
+		PropertyID:  m.PropertyID.String(),
+		Kind:        m.Kind,
+		Filename:    m.Filename,
+		ContentType: m.ContentType,
+		Size:        m.Size,
+		Width:       m.Width,
+		Height:      m.Height,
+		Position:    m.Position,
+		Cover:       m.Cover,
+		Captions:    m.Captions,
+		CreatedAt:   m.CreatedAt,
+		CreatedBy:   m.CreatedBy,
+	}
+	for _, t := range m.Thumbnails {
+		doc.Thumbnails = append(doc.Thumbnails, thumbnailDocument{Size: t.Size, ContentType: t.ContentType, Width: t.Width, Height: t.Height})
+	}
+	return doc
+}
+
+func fromMediaDocument(doc *mediaDocument) (*estate.Media, error) {
+	id, err := uuid.Parse(doc.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid media ID %q: %w", doc.ID, err)
+	}
+	propertyID, err := uuid.Parse(doc.PropertyID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid property ID %q: %w", doc.PropertyID, err)
+	}
+
+	m := &estate.Media{
+		ID:          id,
+		PropertyID:  propertyID,
+		Kind:        doc.Kind,
+		Filename:    doc.Filename,
+		ContentType: doc.ContentType,
+		Size:        doc.Size,
+		Width:       doc.Width,
+		Height:      doc.Height,
+		Position:    doc.Position,
+		Cover:       doc.Cover,
+		Captions:    doc.Captions,
+		CreatedAt:   doc.CreatedAt,
+		CreatedBy:   doc.CreatedBy,
+	}
+	for _, t := range doc.Thumbnails {
+		m.Thumbnails = append(m.Thumbnails, estate.Thumbnail{Size: t.Size, ContentType: t.ContentType, Width: t.Width, Height: t.Height})
+	}
+	return m, nil
+}
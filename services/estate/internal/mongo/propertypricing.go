@@ -0,0 +1,219 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/pulap/pulap/services/estate/internal/estate"
+	"github.com/pulap/pulap/services/estate/internal/money"
+)
+
+// decimal stores a money.Decimal as Decimal128. Doubles and integers, as
+// written before amounts were exact, are accepted when decoding.
+type decimal struct {
+	money.Decimal
+}
+
+// MarshalBSONValue implements bson.ValueMarshaler.
+func (d decimal) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	v, err := toDecimal128(d.Decimal)
+	if err != nil {
+		return 0, nil, err
+	}
+	return bson.MarshalValue(v)
+}
+
+// UnmarshalBSONValue implements bson.ValueUnmarshaler.
+func (d *decimal) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	raw := bson.RawValue{Type: t, Value: data}
+
+	var err error
+	switch t {
+	case bson.TypeDecimal128:
+		d.Decimal, err = money.Parse(raw.Decimal128().String())
+	case bson.TypeDouble:
+		d.Decimal, err = money.FromFloat(raw.Double())
+	case bson.TypeInt32:
+		d.Decimal = money.FromInt(int64(raw.Int32()))
+	case bson.TypeInt64:
+		d.Decimal = money.FromInt(raw.Int64())
+	case bson.TypeNull:
+		d.Decimal = money.Decimal{}
+	default:
+		return fmt.Errorf("cannot decode %s into a decimal", t)
+	}
+	return err
+}
+
+// toDecimal128 converts d, failing for more than the money.MaxStoredDigits
+// digits Decimal128 holds, which validation keeps amounts within.
+func toDecimal128(d money.Decimal) (primitive.Decimal128, error) {
+	v, err := primitive.ParseDecimal128(d.String())
+	if err != nil {
+		return primitive.Decimal128{}, fmt.Errorf("cannot store %s as Decimal128: %w", d, err)
+	}
+	return v, nil
+}
+
+type priceDocument struct {
+	Amount     decimal `bson:"amount"`
+	Currency   string  `bson:"currency"`
+	Type       string  `bson:"type"`
+	Negotiable bool    `bson:"negotiable"`
+}
+
+// valuationDocument is always stored, with an empty currency and zero amounts
+// when the property has no valuation, so price sorting sees every document.
+type valuationDocument struct {
+	Currency       string  `bson:"currency"`
+	PriceType      string  `bson:"price_type"`
+	Amount         decimal `bson:"amount"`
+	PerSquareMeter decimal `bson:"per_square_meter"`
+	RateDate       string  `bson:"rate_date,omitempty"`
+}
+
+type rateDocument struct {
+	From  string  `bson:"from"`
+	To    string  `bson:"to"`
+	Date  string  `bson:"date"`
+	Value decimal `bson:"rate"`
+}
+
+// SaveRates upserts exchange rates by pair and date in one bulk write.
+func (r *PropertyRepo) SaveRates(ctx context.Context, rates []money.Rate) error {
+	if len(rates) == 0 {
+		return nil
+	}
+
+	models := make([]mongo.WriteModel, 0, len(rates))
+	for _, rate := range rates {
+		doc := rateDocument{From: rate.From, To: rate.To, Date: rate.Date.Format(money.DateLayout), Value: decimal{rate.Value}}
+		models = append(models, mongo.NewReplaceOneModel().
+			SetFilter(bson.M{"from": doc.From, "to": doc.To, "date": doc.Date}).
+			SetReplacement(doc).
+			SetUpsert(true))
+	}
+
+	if _, err := r.rates.BulkWrite(ctx, models); err != nil {
+		return fmt.Errorf("could not save exchange rates: %w", err)
+	}
+	return nil
+}
+
+// ListRates retrieves every stored exchange rate.
+func (r *PropertyRepo) ListRates(ctx context.Context) ([]money.Rate, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "from", Value: 1}, {Key: "to", Value: 1}, {Key: "date", Value: 1}})
+	cursor, err := r.rates.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("could not list exchange rates: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var rates []money.Rate
+	for cursor.Next(ctx) {
+		var doc rateDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("could not decode exchange rate: %w", err)
+		}
+		date, err := money.ParseDate(doc.Date)
+		if err != nil {
+			return nil, err
+		}
+		rates = append(rates, money.Rate{From: doc.From, To: doc.To, Date: date, Value: doc.Value.Decimal})
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("cursor error: %w", err)
+	}
+
+	return rates, nil
+}
+
+// SaveValuation replaces the valuation of a property without changing its revision.
+func (r *PropertyRepo) SaveValuation(ctx context.Context, id uuid.UUID, v *estate.Valuation) error {
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id.String()},
+		bson.M{"$set": bson.M{"valuation": toValuationDocument(v)}})
+	if err != nil {
+		return fmt.Errorf("could not update valuation: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("Property aggregate with ID %s: %w", id, estate.ErrNotFound)
+	}
+	return nil
+}
+
+// backfillValuations stores an empty valuation on documents written before
+// valuations existed.
+func (r *PropertyRepo) backfillValuations(ctx context.Context) error {
+	_, err := r.collection.UpdateMany(ctx,
+		bson.M{"valuation": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"valuation": toValuationDocument(nil)}},
+	)
+	return err
+}
+
+func toPriceDocuments(prices []estate.Price) []priceDocument {
+	if prices == nil {
+		return nil
+	}
+	docs := make([]priceDocument, 0, len(prices))
+	for _, p := range prices {
+		docs = append(docs, priceDocument{Amount: decimal{p.Amount}, Currency: p.Currency, Type: p.Type, Negotiable: p.Negotiable})
+	}
+	return docs
+}
+
+func fromPriceDocuments(docs []priceDocument) []estate.Price {
+	if docs == nil {
+		return nil
+	}
+	prices := make([]estate.Price, 0, len(docs))
+	for _, d := range docs {
+		prices = append(prices, estate.Price{Amount: d.Amount.Decimal, Currency: d.Currency, Type: d.Type, Negotiable: d.Negotiable})
+	}
+	return prices
+}
+
+func toValuationDocument(v *estate.Valuation) valuationDocument {
+	if v == nil {
+		return valuationDocument{}
+	}
+	return valuationDocument{
+		Currency:       v.Currency,
+		PriceType:      v.PriceType,
+		Amount:         decimal{v.Amount},
+		PerSquareMeter: decimal{v.PerSquareMeter},
+		RateDate:       v.RateDate,
+	}
+}
+
+func fromValuationDocument(doc valuationDocument) *estate.Valuation {
+	if doc.Currency == "" {
+		return nil
+	}
+	return &estate.Valuation{
+		Currency:       doc.Currency,
+		PriceType:      doc.PriceType,
+		Amount:         doc.Amount.Decimal,
+		PerSquareMeter: doc.PerSquareMeter.Decimal,
+		RateDate:       doc.RateDate,
+	}
+}
+
+// sortValue converts decimal cursor values to Decimal128 so they compare
+// exactly with the stored valuations.
+func sortValue(v any) any {
+	if d, ok := v.(money.Decimal); ok {
+		if dec, err := toDecimal128(d); err == nil {
+			return dec
+		}
+	}
+	return v
+}
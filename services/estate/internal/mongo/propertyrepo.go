@@ -2,6 +2,7 @@ package mongo
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -20,6 +21,14 @@ type PropertyRepo struct {
 	client     *mongo.Client
 	db         *mongo.Database
 	collection *mongo.Collection
+	history    *mongo.Collection
+	revisions  *mongo.Collection
+	media      *mongo.Collection
+	rates      *mongo.Collection
+	imports    *mongo.Collection
+	events     *mongo.Collection
+	outbox     *mongo.Collection // Pending events of purged properties
+	counters   *mongo.Collection
 	xparams    config.XParams
 }
 
@@ -62,12 +71,126 @@ func (r *PropertyRepo) Start(ctx context.Context) error {
 
 	r.client = client
 	r.db = client.Database(dbName)
-	r.collection = r.db.Collection("properties")
+	// Decode embedded documents (e.g. Location.Raw) as maps so they round-trip to JSON.
+	r.collection = r.db.Collection("properties", options.Collection().
+		SetBSONOptions(&options.BSONOptions{DefaultDocumentM: true}))
+	r.history = r.db.Collection("property_status_history")
+	r.revisions = r.db.Collection("property_revisions", options.Collection().
+		SetBSONOptions(&options.BSONOptions{DefaultDocumentM: true}))
+	r.media = r.db.Collection("property_media")
+	r.rates = r.db.Collection("exchange_rates")
+	r.imports = r.db.Collection("property_imports")
+	r.events = r.db.Collection("property_events")
+	r.outbox = r.db.Collection("property_outbox")
+	r.counters = r.db.Collection("counters")
+
+	if err := r.createIndexes(ctx); err != nil {
+		return fmt.Errorf("cannot create indexes: %w", err)
+	}
+
+	if err := r.backfillGeo(ctx); err != nil {
+		return fmt.Errorf("cannot backfill geo points: %w", err)
+	}
+
+	if err := r.backfillRevisions(ctx); err != nil {
+		return fmt.Errorf("cannot backfill revisions: %w", err)
+	}
+
+	if err := r.backfillValuations(ctx); err != nil {
+		return fmt.Errorf("cannot backfill valuations: %w", err)
+	}
+
+	if err := r.settleAllRevisions(ctx); err != nil {
+		return fmt.Errorf("cannot settle revisions: %w", err)
+	}
 
 	r.xparams.Log().Infof("Connected to MongoDB: %s, database: %s", connString, dbName)
 	return nil
 }
 
+// createIndexes creates the indexes backing the list and search queries.
+func (r *PropertyRepo) createIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "owner_id", Value: 1}}},
+		{Keys: bson.D{{Key: "team_id", Value: 1}}},
+		{Keys: bson.D{{Key: "development_id", Value: 1}}, Options: options.Index().SetSparse(true)},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "classification.category_id", Value: 1}, {Key: "classification.type_id", Value: 1}}},
+		{Keys: bson.D{{Key: "location.address.country", Value: 1}, {Key: "location.address.city", Value: 1}}},
+		{Keys: bson.D{{Key: "prices.type", Value: 1}, {Key: "prices.currency", Value: 1}, {Key: "prices.amount", Value: 1}}},
+		{Keys: bson.D{{Key: "features.bedrooms", Value: 1}}},
+		{Keys: bson.D{{Key: "features.total_area", Value: 1}}},
+		{Keys: bson.D{{Key: "features.amenities", Value: 1}}},
+		{Keys: bson.D{{Key: "geo", Value: "2dsphere"}}},
+		{Keys: bson.D{{Key: "valuation.amount", Value: 1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "deleted_at", Value: -1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "outbox.at", Value: 1}}, Options: options.Index().SetSparse(true)},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = r.history.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "property_id", Value: 1}, {Key: "at", Value: 1}},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = r.revisions.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "property_id", Value: 1}, {Key: "number", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = r.media.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "property_id", Value: 1}, {Key: "position", Value: 1}},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = r.rates.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "from", Value: 1}, {Key: "to", Value: 1}, {Key: "date", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = r.imports.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: 1}},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = r.events.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "position", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "aggregate_id", Value: 1}, {Key: "sequence", Value: 1}}},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = r.outbox.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "at", Value: 1}},
+	})
+	return err
+}
+
+// backfillRevisions sets revision 1 on documents stored before revisions existed.
+func (r *PropertyRepo) backfillRevisions(ctx context.Context) error {
+	_, err := r.collection.UpdateMany(ctx,
+		bson.M{"revision": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revision": 1}},
+	)
+	return err
+}
+
 // Stop closes the MongoDB connection.
 func (r *PropertyRepo) Stop(ctx context.Context) error {
 	if r.client != nil {
@@ -80,7 +203,8 @@ func (r *PropertyRepo) Stop(ctx context.Context) error {
 }
 
 // Create creates a new Property aggregate in MongoDB.
-// The entire aggregate is stored as a single document.
+// The entire aggregate is stored as a single document, with its event in the
+// outbox and its first revision pending, so all three are written at once.
 func (r *PropertyRepo) Create(ctx context.Context, property *estate.Property) error {
 	if property == nil {
 		return fmt.Errorf("property cannot be nil")
@@ -89,73 +213,313 @@ func (r *PropertyRepo) Create(ctx context.Context, property *estate.Property) er
 	property.EnsureID()
 	property.BeforeCreate()
 
-	_, err := r.collection.InsertOne(ctx, property)
+	rev, event, err := newRevision(ctx, estate.ActionCreate, nil, property)
 	if err != nil {
+		return err
+	}
+
+	doc := liveDocument(property)
+	doc.Outbox = []eventDocument{event}
+	doc.PendingRevs = []revisionDocument{rev}
+	if _, err := r.collection.InsertOne(ctx, doc); err != nil {
 		return fmt.Errorf("could not create Property aggregate: %w", err)
 	}
 
+	r.settle(ctx, doc.ID)
 	return nil
 }
 
 // Get retrieves a complete Property aggregate by ID from MongoDB.
 func (r *PropertyRepo) Get(ctx context.Context, id uuid.UUID) (*estate.Property, error) {
-	var property estate.Property
+	var doc propertyDocument
 
-	filter := bson.M{"_id": id.String()}
-	err := r.collection.FindOne(ctx, filter).Decode(&property)
+	filter := bson.M{"_id": id.String(), "deleted_at": nil}
+	err := r.collection.FindOne(ctx, filter).Decode(&doc)
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return nil, fmt.Errorf("Property aggregate with ID %s not found", id.String())
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, fmt.Errorf("Property aggregate with ID %s: %w", id.String(), estate.ErrNotFound)
 		}
 		return nil, fmt.Errorf("could not get Property aggregate: %w", err)
 	}
 
-	return &property, nil
+	return fromDocument(&doc)
 }
 
 // Save performs a unit-of-work save operation on the Property aggregate.
-// In MongoDB, this is straightforward since the entire aggregate is replaced as one document.
+// In MongoDB, this is straightforward since the entire aggregate is replaced
+// as one document, together with its event added to the outbox and its
+// revision to the pending ones. The replace matches the expected revision,
+// so a stale save changes nothing and the stored version is the one diffed
+// against.
 func (r *PropertyRepo) Save(ctx context.Context, property *estate.Property) error {
 	if property == nil {
 		return fmt.Errorf("property cannot be nil")
 	}
 
+	before, err := r.Get(ctx, property.GetID())
+	if err != nil {
+		return err
+	}
+	if before.Revision != property.Revision {
+		return fmt.Errorf("Property aggregate with ID %s: %w", property.GetID(), estate.ErrRevisionConflict)
+	}
+
 	property.BeforeUpdate()
+	// The document is replaced whole; creation is kept as stored
+	property.CreatedAt, property.CreatedBy = before.CreatedAt, before.CreatedBy
+
+	id := property.GetID().String()
+	filter := bson.M{"_id": id, "revision": property.Revision, "deleted_at": nil}
 
-	filter := bson.M{"_id": property.GetID().String()}
-	opts := options.Replace().SetUpsert(false)
+	doc := liveDocument(property)
+	doc.Revision++
 
-	result, err := r.collection.ReplaceOne(ctx, filter, property, opts)
+	after := *property
+	after.Revision = doc.Revision
+	rev, event, err := newRevision(ctx, estate.ActionUpdate, before, &after)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, replaceKeepingPending(doc, []eventDocument{event}, []revisionDocument{rev}))
 	if err != nil {
 		return fmt.Errorf("could not save Property aggregate: %w", err)
 	}
 
 	if result.MatchedCount == 0 {
-		return fmt.Errorf("Property aggregate with ID %s not found for update", property.GetID().String())
+		return r.revisionError(ctx, id)
 	}
 
+	r.settle(ctx, id)
+	property.Revision = doc.Revision
 	return nil
 }
 
-// Delete removes the entire Property aggregate from MongoDB.
-func (r *PropertyRepo) Delete(ctx context.Context, id uuid.UUID) error {
-	filter := bson.M{"_id": id.String()}
+// Delete moves the Property aggregate to the trash and records the deleted
+// state as a revision. A non-zero revision must match the stored one. The
+// document, its media and status history are kept until Purge.
+func (r *PropertyRepo) Delete(ctx context.Context, id uuid.UUID, revision int64) error {
+	before, err := r.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if revision == 0 {
+		revision = before.Revision
+	}
+
+	after := before.Trashed(estate.ActorFrom(ctx), time.Now().UTC())
+	rev, event, err := newRevision(ctx, estate.ActionDelete, before, nil)
+	if err != nil {
+		return err
+	}
 
-	result, err := r.collection.DeleteOne(ctx, filter)
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id.String(), "revision": revision, "deleted_at": nil},
+		bson.M{
+			"$set":  bson.M{"deleted_at": *after.DeletedAt, "deleted_by": after.DeletedBy},
+			"$inc":  bson.M{"revision": 1},
+			"$push": pushPending(rev, event),
+		},
+	)
 	if err != nil {
 		return fmt.Errorf("could not delete Property aggregate: %w", err)
 	}
 
-	if result.DeletedCount == 0 {
-		return fmt.Errorf("Property aggregate with ID %s not found for deletion", id.String())
+	if result.MatchedCount == 0 {
+		return r.revisionError(ctx, id.String())
 	}
 
+	r.settle(ctx, id.String())
 	return nil
 }
 
+// Undelete takes the Property aggregate out of the trash and records the
+// restored state as a revision. A non-zero revision must match the stored one.
+func (r *PropertyRepo) Undelete(ctx context.Context, id uuid.UUID, revision int64) error {
+	var doc propertyDocument
+	err := r.collection.FindOne(ctx, bson.M{"_id": id.String(), "deleted_at": bson.M{"$ne": nil}}).Decode(&doc)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return fmt.Errorf("Property aggregate with ID %s in trash: %w", id.String(), estate.ErrNotFound)
+		}
+		return fmt.Errorf("could not get Property aggregate: %w", err)
+	}
+	before, err := fromDocument(&doc)
+	if err != nil {
+		return err
+	}
+	if revision == 0 {
+		revision = before.Revision
+	}
+
+	after := before.Untrashed(estate.ActorFrom(ctx), time.Now())
+	rev, event, err := newRevision(ctx, estate.ActionUndelete, before, after)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id.String(), "revision": revision, "deleted_at": bson.M{"$ne": nil}},
+		bson.M{
+			"$set":   bson.M{"updated_at": after.UpdatedAt, "updated_by": after.UpdatedBy},
+			"$unset": bson.M{"deleted_at": "", "deleted_by": ""},
+			"$inc":   bson.M{"revision": 1},
+			"$push":  pushPending(rev, event),
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("could not restore Property aggregate: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("Property aggregate with ID %s: %w", id.String(), estate.ErrRevisionConflict)
+	}
+
+	r.settle(ctx, id.String())
+	return nil
+}
+
+// Purge deletes the documents of the properties moved to the trash before
+// the given time, followed by their status history, media, listings,
+// leases, appointments and inquiries. The pending events of a document are
+// moved to the property_outbox collection first, where the relay finds them.
+func (r *PropertyRepo) Purge(ctx context.Context, before time.Time) ([]uuid.UUID, error) {
+	expired := bson.M{"$lt": before}
+	opts := options.Find().
+		SetProjection(bson.M{"_id": 1, "outbox": 1}).
+		SetSort(bson.D{{Key: "deleted_at", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"deleted_at": expired}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("could not list purgeable properties: %w", err)
+	}
+
+	var candidates []struct {
+		ID     string          `bson:"_id"`
+		Outbox []eventDocument `bson:"outbox"`
+	}
+	if err := cursor.All(ctx, &candidates); err != nil {
+		return nil, fmt.Errorf("could not decode purgeable properties: %w", err)
+	}
+
+	var purged []uuid.UUID
+	for _, c := range candidates {
+		id, err := uuid.Parse(c.ID)
+		if err != nil {
+			return purged, fmt.Errorf("invalid property ID %q: %w", c.ID, err)
+		}
+
+		// Revisions are kept after the purge.
+		if err := r.settleRevisions(ctx, c.ID); err != nil {
+			return purged, err
+		}
+		pending, err := r.keepPending(ctx, c.Outbox)
+		if err != nil {
+			return purged, err
+		}
+
+		// A property restored meanwhile, or with events or revisions added
+		// since they were kept, no longer matches.
+		result, err := r.collection.DeleteOne(ctx, bson.M{
+			"_id":                      c.ID,
+			"deleted_at":               expired,
+			"outbox":                   bson.M{"$not": bson.M{"$elemMatch": bson.M{"_id": bson.M{"$nin": pending}}}},
+			"pending_revisions.number": bson.M{"$exists": false},
+		})
+		if err != nil {
+			return purged, fmt.Errorf("could not purge Property aggregate %s: %w", c.ID, err)
+		}
+		if result.DeletedCount == 0 {
+			if len(pending) > 0 {
+				if _, err := r.outbox.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": pending}}); err != nil {
+					return purged, fmt.Errorf("could not release pending events: %w", err)
+				}
+			}
+			continue
+		}
+		purged = append(purged, id)
+
+		if err := r.purgeRelated(ctx, c.ID); err != nil {
+			return purged, err
+		}
+	}
+
+	return purged, nil
+}
+
+// keepPending copies the pending events of a property about to be purged
+// to the property_outbox collection and returns their IDs.
+func (r *PropertyRepo) keepPending(ctx context.Context, events []eventDocument) ([]string, error) {
+	ids := make([]string, 0, len(events))
+	if len(events) == 0 {
+		return ids, nil
+	}
+
+	models := make([]mongo.WriteModel, 0, len(events))
+	for _, e := range events {
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": e.ID}).
+			SetUpdate(bson.M{"$setOnInsert": e}).
+			SetUpsert(true))
+		ids = append(ids, e.ID)
+	}
+	if _, err := r.outbox.BulkWrite(ctx, models); err != nil {
+		return nil, fmt.Errorf("could not keep pending events: %w", err)
+	}
+	return ids, nil
+}
+
+// purgeRelated deletes the documents describing a purged property. Those of
+// the aggregates stored alongside properties are reached by collection.
+func (r *PropertyRepo) purgeRelated(ctx context.Context, id string) error {
+	filter := bson.M{"property_id": id}
+	if _, err := r.history.DeleteMany(ctx, filter); err != nil {
+		return fmt.Errorf("could not delete status history: %w", err)
+	}
+	if _, err := r.media.DeleteMany(ctx, filter); err != nil {
+		return fmt.Errorf("could not delete media: %w", err)
+	}
+	if _, err := r.db.Collection(listingsCollection).DeleteMany(ctx, filter); err != nil {
+		return fmt.Errorf("could not delete listings: %w", err)
+	}
+	if _, err := r.db.Collection(leasesCollection).DeleteMany(ctx, filter); err != nil {
+		return fmt.Errorf("could not delete leases: %w", err)
+	}
+	if _, err := r.db.Collection(appointmentsCollection).DeleteMany(ctx, filter); err != nil {
+		return fmt.Errorf("could not delete appointments: %w", err)
+	}
+
+	// Notes go first, so none is left behind if deleting the inquiries fails.
+	inquiries, err := r.db.Collection(inquiriesCollection).Distinct(ctx, "_id", filter)
+	if err != nil {
+		return fmt.Errorf("could not list inquiries: %w", err)
+	}
+	if len(inquiries) > 0 {
+		if _, err := r.db.Collection(inquiryNotesCollection).DeleteMany(ctx, bson.M{"inquiry_id": bson.M{"$in": inquiries}}); err != nil {
+			return fmt.Errorf("could not delete inquiry notes: %w", err)
+		}
+	}
+	if _, err := r.db.Collection(inquiriesCollection).DeleteMany(ctx, filter); err != nil {
+		return fmt.Errorf("could not delete inquiries: %w", err)
+	}
+	return nil
+}
+
+// revisionError explains why a conditional write matched no document: the
+// property is either missing or at another revision.
+func (r *PropertyRepo) revisionError(ctx context.Context, id string) error {
+	count, err := r.collection.CountDocuments(ctx, bson.M{"_id": id})
+	if err != nil {
+		return fmt.Errorf("could not check property: %w", err)
+	}
+	if count == 0 {
+		return fmt.Errorf("Property aggregate with ID %s: %w", id, estate.ErrNotFound)
+	}
+	return fmt.Errorf("Property aggregate with ID %s: %w", id, estate.ErrRevisionConflict)
+}
+
 // List retrieves all Property aggregates from MongoDB.
 func (r *PropertyRepo) List(ctx context.Context) ([]*estate.Property, error) {
-	cursor, err := r.collection.Find(ctx, bson.M{})
+	cursor, err := r.collection.Find(ctx, bson.M{"deleted_at": nil}, sortByCreatedAt())
 	if err != nil {
 		return nil, fmt.Errorf("could not list Property aggregates: %w", err)
 	}
@@ -164,11 +528,15 @@ func (r *PropertyRepo) List(ctx context.Context) ([]*estate.Property, error) {
 	var properties []*estate.Property
 
 	for cursor.Next(ctx) {
-		var property estate.Property
-		if err := cursor.Decode(&property); err != nil {
+		var doc propertyDocument
+		if err := cursor.Decode(&doc); err != nil {
 			return nil, fmt.Errorf("could not decode Property aggregate: %w", err)
 		}
-		properties = append(properties, &property)
+		property, err := fromDocument(&doc)
+		if err != nil {
+			return nil, err
+		}
+		properties = append(properties, property)
 	}
 
 	if err := cursor.Err(); err != nil {
@@ -180,8 +548,8 @@ func (r *PropertyRepo) List(ctx context.Context) ([]*estate.Property, error) {
 
 // ListByOwner retrieves all properties for a specific owner.
 func (r *PropertyRepo) ListByOwner(ctx context.Context, ownerID string) ([]*estate.Property, error) {
-	filter := bson.M{"owner_id": ownerID}
-	cursor, err := r.collection.Find(ctx, filter)
+	filter := bson.M{"owner_id": ownerID, "deleted_at": nil}
+	cursor, err := r.collection.Find(ctx, filter, sortByCreatedAt())
 	if err != nil {
 		return nil, fmt.Errorf("could not list properties by owner: %w", err)
 	}
@@ -190,11 +558,15 @@ func (r *PropertyRepo) ListByOwner(ctx context.Context, ownerID string) ([]*esta
 	var properties []*estate.Property
 
 	for cursor.Next(ctx) {
-		var property estate.Property
-		if err := cursor.Decode(&property); err != nil {
+		var doc propertyDocument
+		if err := cursor.Decode(&doc); err != nil {
 			return nil, fmt.Errorf("could not decode Property aggregate: %w", err)
 		}
-		properties = append(properties, &property)
+		property, err := fromDocument(&doc)
+		if err != nil {
+			return nil, err
+		}
+		properties = append(properties, property)
 	}
 
 	if err := cursor.Err(); err != nil {
@@ -206,8 +578,8 @@ func (r *PropertyRepo) ListByOwner(ctx context.Context, ownerID string) ([]*esta
 
 // ListByStatus retrieves all properties with a specific status.
 func (r *PropertyRepo) ListByStatus(ctx context.Context, status string) ([]*estate.Property, error) {
-	filter := bson.M{"status": status}
-	cursor, err := r.collection.Find(ctx, filter)
+	filter := bson.M{"status": status, "deleted_at": nil}
+	cursor, err := r.collection.Find(ctx, filter, sortByCreatedAt())
 	if err != nil {
 		return nil, fmt.Errorf("could not list properties by status: %w", err)
 	}
@@ -216,11 +588,15 @@ func (r *PropertyRepo) ListByStatus(ctx context.Context, status string) ([]*esta
 	var properties []*estate.Property
 
 	for cursor.Next(ctx) {
-		var property estate.Property
-		if err := cursor.Decode(&property); err != nil {
+		var doc propertyDocument
+		if err := cursor.Decode(&doc); err != nil {
 			return nil, fmt.Errorf("could not decode Property aggregate: %w", err)
 		}
-		properties = append(properties, &property)
+		property, err := fromDocument(&doc)
+		if err != nil {
+			return nil, err
+		}
+		properties = append(properties, property)
 	}
 
 	if err := cursor.Err(); err != nil {
@@ -229,3 +605,16 @@ func (r *PropertyRepo) ListByStatus(ctx context.Context, status string) ([]*esta
 
 	return properties, nil
 }
+
+func sortByCreatedAt() *options.FindOptions {
+	return options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+}
+
+// liveDocument converts a property to the document of a property out of the
+// trash; Delete and Undelete are the only writes that move it.
+func liveDocument(p *estate.Property) *propertyDocument {
+	doc := toDocument(p)
+	doc.DeletedAt = nil
+	doc.DeletedBy = ""
+	return doc
+}
@@ -0,0 +1,103 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/pulap/pulap/pkg/lib/core"
+	"github.com/pulap/pulap/services/estate/internal/config"
+	"github.com/pulap/pulap/services/estate/internal/estate"
+	"github.com/pulap/pulap/services/estate/internal/estate/repotest"
+)
+
+// TestPropertyRepo runs the repository contract against a live MongoDB
+// (see mongoTestURI).
+func TestPropertyRepo(t *testing.T) {
+	uri := mongoTestURI(t)
+	repotest.RunPropertyRepo(t, func(t *testing.T) estate.Repo {
+		return startTestRepo(t, uri)
+	})
+}
+
+// mongoTestURI returns the MongoDB server to run the contracts against.
+// Set MONGO_TEST_URI to point at a server, as CI does; the test fails when
+// that server is unreachable. Without it the test is skipped when none is
+// reachable on localhost.
+func mongoTestURI(t *testing.T) string {
+	t.Helper()
+
+	uri, required := os.LookupEnv("MONGO_TEST_URI")
+	if uri == "" {
+		uri, required = "mongodb://localhost:27017", false
+	}
+
+	probe := newTestRepo(uri, "estate_probe")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := probe.Start(ctx); err != nil {
+		if required {
+			t.Fatalf("MongoDB not available at MONGO_TEST_URI %s: %v", uri, err)
+		}
+		t.Skipf("MongoDB not available at %s: %v", uri, err)
+	}
+	probe.Stop(context.Background())
+	return uri
+}
+
+var testDatabases atomic.Int64
+
+// startTestRepo starts a property repository on a new database dropped
+// when the test ends.
+func startTestRepo(t *testing.T, uri string) *PropertyRepo {
+	t.Helper()
+
+	n := testDatabases.Add(1)
+	repo := newTestRepo(uri, fmt.Sprintf("estate_test_%d_%d", time.Now().UnixNano(), n))
+
+	ctx := context.Background()
+	if err := repo.Start(ctx); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() {
+		repo.db.Drop(ctx)
+		repo.Stop(ctx)
+	})
+	return repo
+}
+
+func newTestRepo(uri, dbName string) *PropertyRepo {
+	cfg := config.New()
+	cfg.Database.MongoURL = uri
+	cfg.Database.MongoDatabase = dbName
+	return NewPropertyRepo(config.NewXParams(core.NewNoopLogger(), cfg))
+}
+
+func TestPropertyDocumentRoundTrip(t *testing.T) {
+	want := repotest.NewProperty("Mayor 12")
+	want.BeforeCreate()
+
+	data, err := bson.Marshal(toDocument(want))
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	var doc propertyDocument
+	if err := bson.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if doc.ID != want.ID.String() {
+		t.Errorf("expected _id %s, got %s", want.ID, doc.ID)
+	}
+
+	got, err := fromDocument(&doc)
+	if err != nil {
+		t.Fatalf("fromDocument: %v", err)
+	}
+	repotest.AssertSameProperty(t, want, got)
+}
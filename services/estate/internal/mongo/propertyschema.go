@@ -0,0 +1,69 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/pulap/pulap/services/estate/internal/estate"
+)
+
+// MigrateSchema rewrites every document stored with an older schema version
+// in its upgraded form. Each replace keeps the revision and only applies if
+// the document did not change meanwhile; documents that fail to upgrade or
+// to be replaced are reported and left as they are.
+func (r *PropertyRepo) MigrateSchema(ctx context.Context) (*estate.SchemaReport, error) {
+	filter := bson.M{"$or": bson.A{
+		bson.M{"schema_version": bson.M{"$lt": estate.Schema.Current()}},
+		bson.M{"schema_version": bson.M{"$exists": false}},
+	}}
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, fmt.Errorf("could not list outdated properties: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	report := &estate.SchemaReport{}
+	for cursor.Next(ctx) {
+		report.Scanned++
+
+		var doc propertyDocument
+		if err := cursor.Decode(&doc); err != nil {
+			report.Failures = append(report.Failures, estate.SchemaFailure{Error: fmt.Sprintf("could not decode property: %v", err)})
+			continue
+		}
+		if err := r.rewrite(ctx, &doc); err != nil {
+			id, _ := uuid.Parse(doc.ID)
+			report.Failures = append(report.Failures, estate.SchemaFailure{ID: id, Error: err.Error()})
+			continue
+		}
+		report.Upgraded++
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("cursor error while migrating properties: %w", err)
+	}
+
+	return report, nil
+}
+
+// rewrite replaces doc with its upgraded form at the same revision, keeping
+// its pending events and revisions.
+func (r *PropertyRepo) rewrite(ctx context.Context, doc *propertyDocument) error {
+	property, err := fromDocument(doc)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": doc.ID, "revision": doc.Revision}, replaceKeepingPending(toDocument(property), nil, nil))
+	if err != nil {
+		return fmt.Errorf("could not rewrite property: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return r.revisionError(ctx, doc.ID)
+	}
+	return nil
+}
@@ -0,0 +1,243 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/pulap/pulap/services/estate/internal/estate"
+)
+
+// sortPaths maps sort fields to document paths.
+var sortPaths = map[estate.SortField]string{
+	estate.SortCreatedAt:   "created_at",
+	estate.SortUpdatedAt:   "updated_at",
+	estate.SortName:        "name",
+	estate.SortBedrooms:    "features.bedrooms",
+	estate.SortBathrooms:   "features.bathrooms",
+	estate.SortTotalArea:   "features.total_area",
+	estate.SortCoveredArea: "features.covered_area",
+	estate.SortYearBuilt:   "features.year_built",
+	estate.SortPrice:       "valuation.amount",
+	estate.SortPricePerM2:  "valuation.per_square_meter",
+	estate.SortDeletedAt:   "deleted_at",
+}
+
+// Search retrieves a page of properties matching the query.
+// Pagination is keyset-based: the cursor holds the sort values of the last
+// returned document and the _id breaks ties.
+func (r *PropertyRepo) Search(ctx context.Context, query estate.PropertyQuery) (*estate.PropertyPage, error) {
+	filter := searchFilter(query)
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("could not count properties: %w", err)
+	}
+
+	cursorValues, err := query.CursorValues()
+	if err != nil {
+		return nil, err
+	}
+	if cursorValues != nil {
+		filter = bson.M{"$and": bson.A{filter, keysetFilter(query.Sort, cursorValues)}}
+	}
+
+	opts := options.Find().
+		SetSort(searchSort(query.Sort)).
+		SetLimit(int64(query.Limit) + 1)
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("could not search properties: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var properties []*estate.Property
+	for cursor.Next(ctx) {
+		var doc propertyDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("could not decode Property aggregate: %w", err)
+		}
+		property, err := fromDocument(&doc)
+		if err != nil {
+			return nil, err
+		}
+		properties = append(properties, property)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("cursor error while searching properties: %w", err)
+	}
+
+	page := &estate.PropertyPage{Items: properties, Total: total}
+	if len(properties) > query.Limit {
+		page.Items = properties[:query.Limit]
+		page.NextCursor = estate.NextCursor(page.Items[query.Limit-1], query.Sort)
+	}
+
+	return page, nil
+}
+
+func searchFilter(q estate.PropertyQuery) bson.M {
+	filter := bson.M{"deleted_at": nil}
+	if q.Deleted {
+		filter["deleted_at"] = bson.M{"$ne": nil}
+	}
+
+	if len(q.IDs) > 0 {
+		filter["_id"] = bson.M{"$in": uuidStrings(q.IDs)}
+	}
+	if q.OwnerID != "" {
+		filter["owner_id"] = q.OwnerID
+	}
+	if q.TeamID != "" {
+		filter["team_id"] = q.TeamID
+	}
+	if q.Scoped {
+		// An empty $in matches nothing, a nil one is rejected
+		scopes := append([]string{}, q.Scopes...)
+		filter["$or"] = bson.A{
+			bson.M{"owner_id": bson.M{"$in": scopes}},
+			bson.M{"team_id": bson.M{"$in": scopes}},
+		}
+	}
+	if len(q.Statuses) > 0 {
+		filter["status"] = bson.M{"$in": q.Statuses}
+	}
+
+	if len(q.DevelopmentIDs) > 0 {
+		filter["development_id"] = bson.M{"$in": uuidStrings(q.DevelopmentIDs)}
+	}
+
+	if len(q.CategoryIDs) > 0 {
+		filter["classification.category_id"] = bson.M{"$in": uuidStrings(q.CategoryIDs)}
+	}
+	if len(q.TypeIDs) > 0 {
+		filter["classification.type_id"] = bson.M{"$in": uuidStrings(q.TypeIDs)}
+	}
+	if len(q.SubtypeIDs) > 0 {
+		filter["classification.subtype_id"] = bson.M{"$in": uuidStrings(q.SubtypeIDs)}
+	}
+
+	if q.City != "" {
+		filter["location.address.city"] = equalFold(q.City)
+	}
+	if q.Country != "" {
+		filter["location.address.country"] = equalFold(q.Country)
+	}
+
+	if q.Price != nil {
+		match := bson.M{}
+		if q.Price.Type != "" {
+			match["type"] = q.Price.Type
+		}
+		if q.Price.Currency != "" {
+			match["currency"] = q.Price.Currency
+		}
+		if amount := rangeFilter(q.Price.Min, q.Price.Max); amount != nil {
+			match["amount"] = amount
+		}
+		filter["prices"] = bson.M{"$elemMatch": match}
+	}
+
+	addIntRange(filter, "features.bedrooms", q.Bedrooms)
+	addIntRange(filter, "features.bathrooms", q.Bathrooms)
+	addIntRange(filter, "features.year_built", q.YearBuilt)
+	addFloatRange(filter, "features.total_area", q.TotalArea)
+	addFloatRange(filter, "features.covered_area", q.CoveredArea)
+
+	for _, flag := range q.Flags {
+		filter["features."+flag] = true
+	}
+	if len(q.Amenities) > 0 {
+		filter["features.amenities"] = bson.M{"$all": q.Amenities}
+	}
+
+	return filter
+}
+
+// keysetFilter matches documents positioned after the cursor values:
+// (a > va) OR (a = va AND b > vb) OR ... OR (a = va AND ... AND _id > id).
+func keysetFilter(sort []estate.SortOrder, values []any) bson.M {
+	paths := make([]string, 0, len(sort)+1)
+	ops := make([]string, 0, len(sort)+1)
+	for _, s := range sort {
+		paths = append(paths, sortPaths[s.Field])
+		ops = append(ops, compareOp(s.Desc))
+	}
+	paths = append(paths, "_id")
+	ops = append(ops, compareOp(false))
+
+	var or bson.A
+	for i := range paths {
+		clause := bson.M{}
+		for j := 0; j < i; j++ {
+			clause[paths[j]] = sortValue(values[j])
+		}
+		clause[paths[i]] = bson.M{ops[i]: sortValue(values[i])}
+		or = append(or, clause)
+	}
+
+	return bson.M{"$or": or}
+}
+
+func searchSort(sort []estate.SortOrder) bson.D {
+	d := make(bson.D, 0, len(sort)+1)
+	for _, s := range sort {
+		direction := 1
+		if s.Desc {
+			direction = -1
+		}
+		d = append(d, bson.E{Key: sortPaths[s.Field], Value: direction})
+	}
+	return append(d, bson.E{Key: "_id", Value: 1})
+}
+
+func compareOp(desc bool) string {
+	if desc {
+		return "$lt"
+	}
+	return "$gt"
+}
+
+func equalFold(s string) bson.M {
+	return bson.M{"$regex": "^" + regexp.QuoteMeta(s) + "$", "$options": "i"}
+}
+
+func rangeFilter[T int | float64](min, max *T) bson.M {
+	if min == nil && max == nil {
+		return nil
+	}
+	r := bson.M{}
+	if min != nil {
+		r["$gte"] = *min
+	}
+	if max != nil {
+		r["$lte"] = *max
+	}
+	return r
+}
+
+func addIntRange(filter bson.M, path string, r estate.IntRange) {
+	if f := rangeFilter(r.Min, r.Max); f != nil {
+		filter[path] = f
+	}
+}
+
+func addFloatRange(filter bson.M, path string, r estate.FloatRange) {
+	if f := rangeFilter(r.Min, r.Max); f != nil {
+		filter[path] = f
+	}
+}
+
+func uuidStrings(ids []uuid.UUID) []string {
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		out = append(out, id.String())
+	}
+	return out
+}
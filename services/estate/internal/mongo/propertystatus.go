@@ -0,0 +1,151 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/pulap/pulap/services/estate/internal/estate"
+)
+
+// statusTransitionDocument is the stored form of a status transition.
+type statusTransitionDocument struct {
+	ID         string    `bson:"_id"`
+	PropertyID string    `bson:"property_id"`
+	From       string    `bson:"from"`
+	To         string    `bson:"to"`
+	Reason     string    `bson:"reason"`
+	Actor      string    `bson:"actor"`
+	Override   bool      `bson:"override"`
+	At         time.Time `bson:"at"`
+}
+
+// Transition updates the property status while it is still t.From and
+// unmodified since it was read, adding its event to the outbox and its
+// revision to the pending ones, then appends the transition to the status
+// history. If that insert fails the status change is reverted, so a status
+// is never changed without its records.
+func (r *PropertyRepo) Transition(ctx context.Context, t *estate.StatusTransition) error {
+	if t == nil {
+		return fmt.Errorf("transition cannot be nil")
+	}
+
+	id := t.PropertyID.String()
+	before, err := r.Get(ctx, t.PropertyID)
+	if err != nil {
+		return err
+	}
+	if before.Status != t.From {
+		return fmt.Errorf("Property aggregate with ID %s: %w", id, estate.ErrStatusConflict)
+	}
+
+	rev, event, err := newRevision(ctx, estate.ActionTransition, before, before.Transitioned(t))
+	if err != nil {
+		return err
+	}
+
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "status": t.From, "revision": before.Revision, "deleted_at": nil},
+		bson.M{
+			"$set":  bson.M{"status": t.To, "updated_at": t.At, "updated_by": t.Actor},
+			"$inc":  bson.M{"revision": 1},
+			"$push": pushPending(rev, event),
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("could not update property status: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		count, err := r.collection.CountDocuments(ctx, bson.M{"_id": id})
+		if err != nil {
+			return fmt.Errorf("could not check property: %w", err)
+		}
+		if count == 0 {
+			return fmt.Errorf("Property aggregate with ID %s: %w", id, estate.ErrNotFound)
+		}
+		return fmt.Errorf("Property aggregate with ID %s: %w", id, estate.ErrStatusConflict)
+	}
+
+	doc := statusTransitionDocument{
+		ID:         t.ID.String(),
+		PropertyID: id,
+		From:       t.From,
+		To:         t.To,
+		Reason:     t.Reason,
+		Actor:      t.Actor,
+		Override:   t.Override,
+		At:         t.At,
+	}
+	if _, err := r.history.InsertOne(ctx, doc); err != nil {
+		return r.undo(id, fmt.Errorf("could not record status transition: %w", err), func() error {
+			_, err := r.collection.UpdateOne(ctx,
+				bson.M{"_id": id, "status": t.To, "revision": rev.Number},
+				bson.M{
+					"$set":  bson.M{"status": t.From},
+					"$inc":  bson.M{"revision": -1},
+					"$pull": bson.M{"outbox": bson.M{"_id": event.ID}, "pending_revisions": bson.M{"number": rev.Number}},
+				},
+			)
+			return err
+		})
+	}
+
+	r.settle(ctx, id)
+	return nil
+}
+
+// StatusHistory retrieves the status transitions of a property, oldest first.
+func (r *PropertyRepo) StatusHistory(ctx context.Context, id uuid.UUID) ([]estate.StatusTransition, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "at", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := r.history.Find(ctx, bson.M{"property_id": id.String()}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("could not list status transitions: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var history []estate.StatusTransition
+	for cursor.Next(ctx) {
+		var doc statusTransitionDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("could not decode status transition: %w", err)
+		}
+		t, err := fromStatusTransitionDocument(&doc)
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, t)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("cursor error while listing status transitions: %w", err)
+	}
+
+	return history, nil
+}
+
+func fromStatusTransitionDocument(doc *statusTransitionDocument) (estate.StatusTransition, error) {
+	id, err := uuid.Parse(doc.ID)
+	if err != nil {
+		return estate.StatusTransition{}, fmt.Errorf("invalid status transition ID: %w", err)
+	}
+	propertyID, err := uuid.Parse(doc.PropertyID)
+	if err != nil {
+		return estate.StatusTransition{}, fmt.Errorf("invalid property ID: %w", err)
+	}
+
+	return estate.StatusTransition{
+		ID:         id,
+		PropertyID: propertyID,
+		From:       doc.From,
+		To:         doc.To,
+		Reason:     doc.Reason,
+		Actor:      doc.Actor,
+		Override:   doc.Override,
+		At:         doc.At.UTC(),
+	}, nil
+}
@@ -0,0 +1,125 @@
+package odata
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"strings"
+)
+
+// Entity is an instance of an entity type: property values in the order
+// they are set, which is the order they are serialized in. Values are
+// strings, numbers, decimals with a Float64 method, booleans, time.Time,
+// nil, and entities or slices of entities for expanded navigation
+// properties.
+type Entity struct {
+	names  []string
+	values map[string]any
+}
+
+// NewEntity returns an empty entity.
+func NewEntity() *Entity {
+	return &Entity{values: map[string]any{}}
+}
+
+// Set sets a property, appending it if new.
+func (e *Entity) Set(name string, v any) {
+	if _, ok := e.values[name]; !ok {
+		e.names = append(e.names, name)
+	}
+	e.values[name] = v
+}
+
+// Get returns a property.
+func (e *Entity) Get(name string) (any, bool) {
+	v, ok := e.values[name]
+	return v, ok
+}
+
+// Select returns a copy with the named properties only, plus annotations
+// such as "@odata.id". An empty list selects every property.
+func (e *Entity) Select(names []string) *Entity {
+	out := NewEntity()
+	for _, name := range e.names {
+		if len(names) == 0 || strings.HasPrefix(name, "@") || slices.Contains(names, name) {
+			out.Set(name, e.values[name])
+		}
+	}
+	return out
+}
+
+// WithContext returns a copy annotated with the context URL, as returned
+// for a single entity.
+func (e *Entity) WithContext(context string) *Entity {
+	out := NewEntity()
+	out.Set("@odata.context", context)
+	for _, name := range e.names {
+		out.Set(name, e.values[name])
+	}
+	return out
+}
+
+// MarshalJSON implements json.Marshaler.
+func (e *Entity) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, name := range e.names {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(name)
+		buf.Write(key)
+		buf.WriteByte(':')
+		value, err := json.Marshal(e.values[name])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// Collection is the response to a request for an entity set.
+type Collection struct {
+	Context  string
+	Count    *int64
+	Value    []*Entity
+	NextLink string
+}
+
+// MarshalJSON implements json.Marshaler.
+func (c Collection) MarshalJSON() ([]byte, error) {
+	out := NewEntity()
+	out.Set("@odata.context", c.Context)
+	if c.Count != nil {
+		out.Set("@odata.count", *c.Count)
+	}
+	value := c.Value
+	if value == nil {
+		value = []*Entity{}
+	}
+	out.Set("value", value)
+	if c.NextLink != "" {
+		out.Set("@odata.nextLink", c.NextLink)
+	}
+	return out.MarshalJSON()
+}
+
+// ServiceDocument is the response to a request for the service root,
+// listing its entity sets.
+func ServiceDocument(metadataURL string, sets []EntitySet) any {
+	type entry struct {
+		Name string `json:"name"`
+		Kind string `json:"kind"`
+		URL  string `json:"url"`
+	}
+	doc := struct {
+		Context string  `json:"@odata.context"`
+		Value   []entry `json:"value"`
+	}{Context: metadataURL, Value: []entry{}}
+	for _, s := range sets {
+		doc.Value = append(doc.Value, entry{Name: s.Name, Kind: "EntitySet", URL: s.Name})
+	}
+	return doc
+}
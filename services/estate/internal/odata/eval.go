@@ -0,0 +1,349 @@
+package odata
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Kind is the kind of value an expression evaluates to. Edm types of the
+// same kind compare with each other.
+type Kind int
+
+const (
+	KindNull Kind = iota
+	KindString
+	KindNumber
+	KindBool
+	KindTime
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindString:
+		return "string"
+	case KindNumber:
+		return "number"
+	case KindBool:
+		return "boolean"
+	case KindTime:
+		return "date"
+	default:
+		return "null"
+	}
+}
+
+// Check reports a filter that references unknown properties, calls
+// functions with the wrong arguments or compares values of different kinds.
+// kinds maps the filterable properties to their kinds.
+func Check(e Expr, kinds map[string]Kind) error {
+	k, err := check(e, kinds)
+	if err != nil {
+		return err
+	}
+	if k != KindBool {
+		return fmt.Errorf("filter must be a boolean expression")
+	}
+	return nil
+}
+
+func check(e Expr, kinds map[string]Kind) (Kind, error) {
+	switch e := e.(type) {
+	case Path:
+		k, ok := kinds[e.Name]
+		if !ok {
+			return 0, fmt.Errorf("cannot filter by %s", e.Name)
+		}
+		return k, nil
+
+	case Literal:
+		return literalKind(e.Value), nil
+
+	case Not:
+		if k, err := check(e.X, kinds); err != nil || k != KindBool {
+			return 0, orError(err, "not requires a boolean operand")
+		}
+		return KindBool, nil
+
+	case In:
+		k, err := check(e.X, kinds)
+		if err != nil {
+			return 0, err
+		}
+		for _, lit := range e.List {
+			if lk := literalKind(lit.Value); lk != k && lk != KindNull {
+				return 0, fmt.Errorf("in list mixes %s and %s values", k, lk)
+			}
+		}
+		return KindBool, nil
+
+	case Call:
+		fn := functions[e.Name]
+		if len(e.Args) != len(fn.args) {
+			return 0, fmt.Errorf("%s takes %d arguments", e.Name, len(fn.args))
+		}
+		for i, arg := range e.Args {
+			k, err := check(arg, kinds)
+			if err != nil {
+				return 0, err
+			}
+			if k != fn.args[i] && k != KindNull {
+				return 0, fmt.Errorf("%s requires %s arguments", e.Name, fn.args[i])
+			}
+		}
+		return fn.result, nil
+
+	case Binary:
+		lk, err := check(e.Left, kinds)
+		if err != nil {
+			return 0, err
+		}
+		rk, err := check(e.Right, kinds)
+		if err != nil {
+			return 0, err
+		}
+		if e.Op == "and" || e.Op == "or" {
+			if lk != KindBool || rk != KindBool {
+				return 0, fmt.Errorf("%s requires boolean operands", e.Op)
+			}
+			return KindBool, nil
+		}
+		if lk != rk && lk != KindNull && rk != KindNull {
+			return 0, fmt.Errorf("cannot compare %s with %s", lk, rk)
+		}
+		if (lk == KindBool || rk == KindBool) && e.Op != "eq" && e.Op != "ne" {
+			return 0, fmt.Errorf("booleans only support eq and ne")
+		}
+		return KindBool, nil
+	}
+	return 0, fmt.Errorf("unsupported expression")
+}
+
+func orError(err error, msg string) error {
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("%s", msg)
+}
+
+func literalKind(v any) Kind {
+	switch v.(type) {
+	case string:
+		return KindString
+	case int64, float64:
+		return KindNumber
+	case bool:
+		return KindBool
+	case time.Time:
+		return KindTime
+	default:
+		return KindNull
+	}
+}
+
+// Match evaluates a checked filter against an entity. Comparisons with
+// null are false except eq and ne, and functions of null are null, which
+// does not match.
+func Match(e Expr, entity *Entity) bool {
+	b, _ := eval(e, entity).(bool)
+	return b
+}
+
+func eval(e Expr, entity *Entity) any {
+	switch e := e.(type) {
+	case Path:
+		v, _ := entity.Get(e.Name)
+		return normalize(v)
+
+	case Literal:
+		return normalize(e.Value)
+
+	case Not:
+		if b, ok := eval(e.X, entity).(bool); ok {
+			return !b
+		}
+		return nil
+
+	case In:
+		x := eval(e.X, entity)
+		for _, lit := range e.List {
+			if compare("eq", x, normalize(lit.Value)) {
+				return true
+			}
+		}
+		return false
+
+	case Call:
+		args := make([]any, len(e.Args))
+		for i, arg := range e.Args {
+			if args[i] = eval(arg, entity); args[i] == nil {
+				return nil
+			}
+		}
+		return call(e.Name, args)
+
+	case Binary:
+		l, r := eval(e.Left, entity), eval(e.Right, entity)
+		switch e.Op {
+		case "and":
+			lb, lok := l.(bool)
+			rb, rok := r.(bool)
+			if (lok && !lb) || (rok && !rb) {
+				return false
+			}
+			if !lok || !rok {
+				return nil
+			}
+			return true
+		case "or":
+			lb, lok := l.(bool)
+			rb, rok := r.(bool)
+			if (lok && lb) || (rok && rb) {
+				return true
+			}
+			if !lok || !rok {
+				return nil
+			}
+			return false
+		}
+		return compare(e.Op, l, r)
+	}
+	return nil
+}
+
+func call(name string, args []any) any {
+	switch name {
+	case "contains", "startswith", "endswith":
+		s, _ := args[0].(string)
+		sub, _ := args[1].(string)
+		switch name {
+		case "contains":
+			return strings.Contains(s, sub)
+		case "startswith":
+			return strings.HasPrefix(s, sub)
+		default:
+			return strings.HasSuffix(s, sub)
+		}
+	case "tolower":
+		s, _ := args[0].(string)
+		return strings.ToLower(s)
+	case "toupper":
+		s, _ := args[0].(string)
+		return strings.ToUpper(s)
+	case "trim":
+		s, _ := args[0].(string)
+		return strings.TrimSpace(s)
+	case "length":
+		s, _ := args[0].(string)
+		return float64(len([]rune(s)))
+	case "year", "month", "day":
+		t, _ := args[0].(time.Time)
+		t = t.UTC()
+		switch name {
+		case "year":
+			return float64(t.Year())
+		case "month":
+			return float64(t.Month())
+		default:
+			return float64(t.Day())
+		}
+	}
+	return nil
+}
+
+// compare applies a comparison operator. Values are normalized.
+func compare(op string, l, r any) bool {
+	if l == nil || r == nil {
+		switch op {
+		case "eq":
+			return l == nil && r == nil
+		case "ne":
+			return (l == nil) != (r == nil)
+		}
+		return false
+	}
+
+	var c int
+	switch l := l.(type) {
+	case string:
+		rs, ok := r.(string)
+		if !ok {
+			return false
+		}
+		c = strings.Compare(l, rs)
+	case float64:
+		rf, ok := r.(float64)
+		if !ok {
+			return false
+		}
+		c = cmpFloat(l, rf)
+	case time.Time:
+		rt, ok := r.(time.Time)
+		if !ok {
+			return false
+		}
+		c = l.Compare(rt)
+	case bool:
+		rb, ok := r.(bool)
+		if !ok {
+			return false
+		}
+		if op == "eq" {
+			return l == rb
+		}
+		if op == "ne" {
+			return l != rb
+		}
+		return false
+	default:
+		return false
+	}
+
+	switch op {
+	case "eq":
+		return c == 0
+	case "ne":
+		return c != 0
+	case "gt":
+		return c > 0
+	case "ge":
+		return c >= 0
+	case "lt":
+		return c < 0
+	default:
+		return c <= 0
+	}
+}
+
+func cmpFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// normalize converts numbers, decimals included, to float64 and
+// dereferences times so values of the same kind compare directly.
+func normalize(v any) any {
+	switch v := v.(type) {
+	case interface{ Float64() float64 }:
+		return v.Float64()
+	case int:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case float32:
+		return float64(v)
+	case *time.Time:
+		if v == nil {
+			return nil
+		}
+		return *v
+	}
+	return v
+}
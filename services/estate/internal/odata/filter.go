@@ -0,0 +1,481 @@
+package odata
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Expr is a node of a parsed $filter expression.
+type Expr interface {
+	expr()
+}
+
+// Path references a property of the entity being filtered.
+type Path struct {
+	Name string
+}
+
+// Literal is a constant: a string, int64, float64, bool, time.Time or nil.
+type Literal struct {
+	Value any
+}
+
+// Binary is a logical (and, or) or comparison (eq, ne, gt, ge, lt, le)
+// operation.
+type Binary struct {
+	Op          string
+	Left, Right Expr
+}
+
+// Not negates a boolean expression.
+type Not struct {
+	X Expr
+}
+
+// In matches an expression against a list of literals.
+type In struct {
+	X    Expr
+	List []Literal
+}
+
+// Call is a call to a built-in function, e.g. contains(Name,'x').
+type Call struct {
+	Name string
+	Args []Expr
+}
+
+func (Path) expr()    {}
+func (Literal) expr() {}
+func (Binary) expr()  {}
+func (Not) expr()     {}
+func (In) expr()      {}
+func (Call) expr()    {}
+
+var comparisons = map[string]bool{"eq": true, "ne": true, "gt": true, "ge": true, "lt": true, "le": true}
+
+// functions lists the supported built-in functions with their argument and
+// result kinds.
+var functions = map[string]struct {
+	args   []Kind
+	result Kind
+}{
+	"contains":   {[]Kind{KindString, KindString}, KindBool},
+	"startswith": {[]Kind{KindString, KindString}, KindBool},
+	"endswith":   {[]Kind{KindString, KindString}, KindBool},
+	"tolower":    {[]Kind{KindString}, KindString},
+	"toupper":    {[]Kind{KindString}, KindString},
+	"trim":       {[]Kind{KindString}, KindString},
+	"length":     {[]Kind{KindString}, KindNumber},
+	"year":       {[]Kind{KindTime}, KindNumber},
+	"month":      {[]Kind{KindTime}, KindNumber},
+	"day":        {[]Kind{KindTime}, KindNumber},
+}
+
+// ParseFilter parses a $filter expression. Property names are not checked;
+// see Check.
+func ParseFilter(s string) (Expr, error) {
+	tokens, err := lex(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	e, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %s", t)
+	}
+	return e, nil
+}
+
+// Properties returns the names of the properties an expression references.
+func Properties(e Expr) []string {
+	var names []string
+	var walk func(Expr)
+	walk = func(e Expr) {
+		switch e := e.(type) {
+		case Path:
+			names = append(names, e.Name)
+		case Binary:
+			walk(e.Left)
+			walk(e.Right)
+		case Not:
+			walk(e.X)
+		case In:
+			walk(e.X)
+		case Call:
+			for _, a := range e.Args {
+				walk(a)
+			}
+		}
+	}
+	walk(e)
+	return names
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+// keyword consumes the next token if it is the given keyword.
+func (p *parser) keyword(word string) bool {
+	if t := p.peek(); t.kind == tokIdent && t.text == word {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(kind tokenKind) error {
+	if t := p.next(); t.kind != kind {
+		return fmt.Errorf("expected %s, got %s", kind, t)
+	}
+	return nil
+}
+
+func (p *parser) or() (Expr, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or") {
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = Binary{Op: "or", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) and() (Expr, error) {
+	left, err := p.not()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("and") {
+		right, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		left = Binary{Op: "and", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) not() (Expr, error) {
+	if p.keyword("not") {
+		x, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		return Not{X: x}, nil
+	}
+	return p.comparison()
+}
+
+func (p *parser) comparison() (Expr, error) {
+	left, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+
+	t := p.peek()
+	if t.kind != tokIdent {
+		return left, nil
+	}
+	if comparisons[t.text] {
+		p.next()
+		right, err := p.primary()
+		if err != nil {
+			return nil, err
+		}
+		return Binary{Op: t.text, Left: left, Right: right}, nil
+	}
+	if t.text == "in" {
+		p.next()
+		list, err := p.list()
+		if err != nil {
+			return nil, err
+		}
+		return In{X: left, List: list}, nil
+	}
+	return left, nil
+}
+
+func (p *parser) list() ([]Literal, error) {
+	if err := p.expect(tokLParen); err != nil {
+		return nil, err
+	}
+	var list []Literal
+	for {
+		t := p.next()
+		lit, ok := t.literal()
+		if !ok {
+			return nil, fmt.Errorf("expected a literal in list, got %s", t)
+		}
+		list = append(list, lit)
+
+		t = p.next()
+		if t.kind == tokRParen {
+			return list, nil
+		}
+		if t.kind != tokComma {
+			return nil, fmt.Errorf("expected , or ), got %s", t)
+		}
+	}
+}
+
+func (p *parser) primary() (Expr, error) {
+	t := p.next()
+	if lit, ok := t.literal(); ok {
+		return lit, nil
+	}
+
+	switch t.kind {
+	case tokLParen:
+		e, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokRParen); err != nil {
+			return nil, err
+		}
+		return e, nil
+
+	case tokIdent:
+		if p.peek().kind != tokLParen {
+			if isKeyword(t.text) {
+				return nil, fmt.Errorf("unexpected %s", t)
+			}
+			return Path{Name: t.text}, nil
+		}
+		if _, ok := functions[t.text]; !ok {
+			return nil, fmt.Errorf("unknown function %s", t.text)
+		}
+		p.next()
+		call := Call{Name: t.text}
+		if p.peek().kind == tokRParen {
+			p.next()
+			return call, nil
+		}
+		for {
+			arg, err := p.or()
+			if err != nil {
+				return nil, err
+			}
+			call.Args = append(call.Args, arg)
+			t := p.next()
+			if t.kind == tokRParen {
+				return call, nil
+			}
+			if t.kind != tokComma {
+				return nil, fmt.Errorf("expected , or ), got %s", t)
+			}
+		}
+	}
+	return nil, fmt.Errorf("unexpected %s", t)
+}
+
+func isKeyword(s string) bool {
+	return comparisons[s] || s == "and" || s == "or" || s == "not" || s == "in"
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokTime
+	tokGUID
+	tokLParen
+	tokRParen
+	tokComma
+)
+
+func (k tokenKind) String() string {
+	switch k {
+	case tokEOF:
+		return "end of expression"
+	case tokIdent:
+		return "name"
+	case tokString:
+		return "string"
+	case tokNumber:
+		return "number"
+	case tokTime:
+		return "date"
+	case tokGUID:
+		return "guid"
+	case tokLParen:
+		return "("
+	case tokRParen:
+		return ")"
+	default:
+		return ","
+	}
+}
+
+type token struct {
+	kind  tokenKind
+	text  string
+	value any
+}
+
+func (t token) String() string {
+	if t.text == "" {
+		return t.kind.String()
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// literal returns the literal a token denotes, if any.
+func (t token) literal() (Literal, bool) {
+	switch t.kind {
+	case tokString, tokNumber, tokTime, tokGUID:
+		return Literal{Value: t.value}, true
+	case tokIdent:
+		switch t.text {
+		case "true":
+			return Literal{Value: true}, true
+		case "false":
+			return Literal{Value: false}, true
+		case "null":
+			return Literal{Value: nil}, true
+		}
+	}
+	return Literal{}, false
+}
+
+var (
+	identPattern    = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	intPattern      = regexp.MustCompile(`^-?[0-9]+$`)
+	decimalPattern  = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+	datePattern     = regexp.MustCompile(`^[0-9]{4}-[0-9]{2}-[0-9]{2}$`)
+	dateTimePattern = regexp.MustCompile(`^[0-9]{4}-[0-9]{2}-[0-9]{2}T`)
+	guidPattern     = regexp.MustCompile(`^[0-9A-Fa-f]{8}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{12}$`)
+)
+
+func lex(s string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			tokens = append(tokens, token{kind: tokLParen})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokRParen})
+			i++
+		case c == ',':
+			tokens = append(tokens, token{kind: tokComma})
+			i++
+		case c == '\'':
+			str, n, err := lexString(s[i:])
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokString, text: s[i : i+n], value: str})
+			i += n
+		default:
+			j := i
+			for j < len(s) && isWordByte(s[j]) {
+				j++
+			}
+			if j == i {
+				return nil, fmt.Errorf("unexpected character %q", rune(c))
+			}
+			t, err := lexWord(s[i:j])
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, t)
+			i = j
+		}
+	}
+	return append(tokens, token{kind: tokEOF}), nil
+}
+
+// lexString reads a single-quoted string, where ” escapes a quote, and
+// returns its value and length.
+func lexString(s string) (string, int, error) {
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		if s[i] != '\'' {
+			b.WriteByte(s[i])
+			continue
+		}
+		if i+1 < len(s) && s[i+1] == '\'' {
+			b.WriteByte('\'')
+			i++
+			continue
+		}
+		return b.String(), i + 1, nil
+	}
+	return "", 0, fmt.Errorf("unterminated string")
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c == '.' || c == ':' || c == '-' || c == '+' ||
+		'0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func lexWord(w string) (token, error) {
+	switch {
+	case guidPattern.MatchString(w):
+		return token{kind: tokGUID, text: w, value: strings.ToLower(w)}, nil
+
+	case identPattern.MatchString(w):
+		return token{kind: tokIdent, text: w}, nil
+
+	case intPattern.MatchString(w):
+		n, err := strconv.ParseInt(w, 10, 64)
+		if err != nil {
+			return token{}, fmt.Errorf("invalid number %s", w)
+		}
+		return token{kind: tokNumber, text: w, value: n}, nil
+
+	case decimalPattern.MatchString(w):
+		f, err := strconv.ParseFloat(w, 64)
+		if err != nil {
+			return token{}, fmt.Errorf("invalid number %s", w)
+		}
+		return token{kind: tokNumber, text: w, value: f}, nil
+
+	case datePattern.MatchString(w):
+		d, err := time.Parse(time.DateOnly, w)
+		if err != nil {
+			return token{}, fmt.Errorf("invalid date %s", w)
+		}
+		return token{kind: tokTime, text: w, value: d}, nil
+
+	case dateTimePattern.MatchString(w):
+		d, err := time.Parse(time.RFC3339Nano, w)
+		if err != nil {
+			return token{}, fmt.Errorf("invalid date-time %s", w)
+		}
+		return token{kind: tokTime, text: w, value: d}, nil
+	}
+	return token{}, fmt.Errorf("unexpected %q", w)
+}
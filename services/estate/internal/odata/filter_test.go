@@ -0,0 +1,169 @@
+package odata
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		filter string
+		want   Expr
+	}{
+		{"City eq 'Madrid'", Binary{Op: "eq", Left: Path{Name: "City"}, Right: Literal{Value: "Madrid"}}},
+		{"Name eq 'O''Brien'", Binary{Op: "eq", Left: Path{Name: "Name"}, Right: Literal{Value: "O'Brien"}}},
+		{"Price le -1.5e3", Binary{Op: "le", Left: Path{Name: "Price"}, Right: Literal{Value: -1500.0}}},
+		{"Beds gt 2 and not Pool", Binary{Op: "and",
+			Left:  Binary{Op: "gt", Left: Path{Name: "Beds"}, Right: Literal{Value: int64(2)}},
+			Right: Not{X: Path{Name: "Pool"}}}},
+		{"A eq 1 or B eq 2 and C eq 3", Binary{Op: "or",
+			Left: Binary{Op: "eq", Left: Path{Name: "A"}, Right: Literal{Value: int64(1)}},
+			Right: Binary{Op: "and",
+				Left:  Binary{Op: "eq", Left: Path{Name: "B"}, Right: Literal{Value: int64(2)}},
+				Right: Binary{Op: "eq", Left: Path{Name: "C"}, Right: Literal{Value: int64(3)}}}}},
+		{"(A eq 1 or B eq 2) and C eq null", Binary{Op: "and",
+			Left: Binary{Op: "or",
+				Left:  Binary{Op: "eq", Left: Path{Name: "A"}, Right: Literal{Value: int64(1)}},
+				Right: Binary{Op: "eq", Left: Path{Name: "B"}, Right: Literal{Value: int64(2)}}},
+			Right: Binary{Op: "eq", Left: Path{Name: "C"}, Right: Literal{Value: nil}}}},
+		{"Status in ('Active','Pending')", In{X: Path{Name: "Status"}, List: []Literal{{Value: "Active"}, {Value: "Pending"}}}},
+		{"contains(tolower(City),'mad')", Call{Name: "contains", Args: []Expr{Call{Name: "tolower", Args: []Expr{Path{Name: "City"}}}, Literal{Value: "mad"}}}},
+		{"Modified ge 2024-05-01T10:00:00Z", Binary{Op: "ge", Left: Path{Name: "Modified"}, Right: Literal{Value: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)}}},
+		{"Listed lt 2024-05-01", Binary{Op: "lt", Left: Path{Name: "Listed"}, Right: Literal{Value: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)}}},
+		{"Key eq 6F1C1F7E-7D55-4A57-9A64-2D2B0F5B5A01", Binary{Op: "eq", Left: Path{Name: "Key"}, Right: Literal{Value: "6f1c1f7e-7d55-4a57-9a64-2d2b0f5b5a01"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			got, err := ParseFilter(tt.filter)
+			if err != nil {
+				t.Fatalf("ParseFilter: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %#v, got %#v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseFilterInvalid(t *testing.T) {
+	tests := []struct {
+		filter string
+		want   string
+	}{
+		{"City eq 'Madrid", "unterminated string"},
+		{"City eq", "unexpected end of expression"},
+		{"City eq 'a' 'b'", `unexpected "'b'"`},
+		{"(City eq 'a'", "expected ), got end of expression"},
+		{"matches(City,'a')", "unknown function matches"},
+		{"City eq 'a' and", "unexpected end of expression"},
+		{"Beds eq 2024-13-01", "invalid date 2024-13-01"},
+		{"Beds eq 1-2", `unexpected "1-2"`},
+		{"Status in (City)", `expected a literal in list, got "City"`},
+		{"City eq 'a' ; drop", "unexpected character ';'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			_, err := ParseFilter(tt.filter)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	kinds := map[string]Kind{"City": KindString, "Beds": KindNumber, "Pool": KindBool, "Listed": KindTime}
+	tests := []struct {
+		filter string
+		want   string
+	}{
+		{"City eq 'Madrid' and Beds ge 2 and Pool and Listed gt 2024-01-01", ""},
+		{"City eq null or year(Listed) eq 2024 or length(City) gt 3", ""},
+		{"Beds in (1, 2.5, null)", ""},
+		{"Garden eq true", "cannot filter by Garden"},
+		{"City eq 2", "cannot compare string with number"},
+		{"Pool gt false", "booleans only support eq and ne"},
+		{"City", "filter must be a boolean expression"},
+		{"Beds and Pool", "and requires boolean operands"},
+		{"not City", "not requires a boolean operand"},
+		{"contains(Beds,'1')", "contains requires string arguments"},
+		{"contains(City)", "contains takes 2 arguments"},
+		{"City in ('a', 1)", "in list mixes string and number values"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			e, err := ParseFilter(tt.filter)
+			if err != nil {
+				t.Fatalf("ParseFilter: %v", err)
+			}
+			err = Check(e, kinds)
+			if tt.want == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if tt.want != "" && (err == nil || err.Error() != tt.want) {
+				t.Errorf("expected error %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+type testDecimal float64
+
+func (d testDecimal) Float64() float64 { return float64(d) }
+
+func TestMatch(t *testing.T) {
+	e := NewEntity()
+	e.Set("City", "Madrid")
+	e.Set("Beds", 3)
+	e.Set("Price", testDecimal(250000.5))
+	e.Set("Pool", true)
+	e.Set("Listed", time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC))
+	e.Set("State", nil)
+
+	tests := []struct {
+		filter string
+		want   bool
+	}{
+		{"City eq 'Madrid'", true},
+		{"City eq 'madrid'", false},
+		{"tolower(City) eq 'madrid'", true},
+		{"City ne 'Madrid'", false},
+		{"Beds ge 3 and Beds lt 4", true},
+		{"Beds gt 3", false},
+		{"Beds eq 3.0", true},
+		{"Price gt 250000", true},
+		{"Price le 250000", false},
+		{"Pool eq true", true},
+		{"Pool", true},
+		{"not Pool", false},
+		{"Listed ge 2024-05-01", true},
+		{"Listed gt 2024-05-01T10:00:00Z", false},
+		{"Listed eq 2024-05-01T12:00:00+02:00", true},
+		{"year(Listed) eq 2024 and month(Listed) eq 5 and day(Listed) eq 1", true},
+		{"State eq null", true},
+		{"State ne null", false},
+		{"State gt 'A'", false},
+		{"City ne null", true},
+		{"contains(State,'a')", false},
+		{"not contains(State,'a')", false},
+		{"contains(State,'a') or Pool", true},
+		{"contains(City,'dri') and startswith(City,'Ma') and endswith(City,'id')", true},
+		{"length(City) eq 6", true},
+		{"City in ('Sevilla', 'Madrid')", true},
+		{"Beds in (1, 2)", false},
+		{"Missing eq null", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			f, err := ParseFilter(tt.filter)
+			if err != nil {
+				t.Fatalf("ParseFilter: %v", err)
+			}
+			if got := Match(f, e); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
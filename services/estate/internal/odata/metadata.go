@@ -0,0 +1,186 @@
+package odata
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+// Schema describes the entity types and sets of a service for $metadata.
+type Schema struct {
+	Namespace   string
+	Container   string
+	EntityTypes []*EntityType
+	EntitySets  []EntitySet
+}
+
+// EntitySet exposes the entities of a type at the service root.
+type EntitySet struct {
+	Name string
+	Type *EntityType
+}
+
+// EntityType is a structured type with a key.
+type EntityType struct {
+	Name       string
+	Key        string
+	Properties []PropertyType
+	Navigation []NavigationProperty
+}
+
+// PropertyType is a primitive property of an entity type.
+type PropertyType struct {
+	Name     string
+	Type     string // Edm primitive type, e.g. "Edm.String"
+	Nullable bool
+	Sortable bool // Accepted by $orderby
+}
+
+// NavigationProperty relates an entity to contained entities of another
+// type, which are only reachable through $expand.
+type NavigationProperty struct {
+	Name       string
+	Type       *EntityType
+	Collection bool
+}
+
+// Property returns a primitive property by name.
+func (t *EntityType) Property(name string) *PropertyType {
+	for i := range t.Properties {
+		if t.Properties[i].Name == name {
+			return &t.Properties[i]
+		}
+	}
+	return nil
+}
+
+// NavigationProperty returns a navigation property by name.
+func (t *EntityType) NavigationProperty(name string) *NavigationProperty {
+	for i := range t.Navigation {
+		if t.Navigation[i].Name == name {
+			return &t.Navigation[i]
+		}
+	}
+	return nil
+}
+
+// Kinds returns the filterable properties with their kinds.
+func (t *EntityType) Kinds() map[string]Kind {
+	kinds := map[string]Kind{}
+	for _, p := range t.Properties {
+		kinds[p.Name] = KindOf(p.Type)
+	}
+	return kinds
+}
+
+// KindOf returns the kind of an Edm primitive type.
+func KindOf(edmType string) Kind {
+	switch edmType {
+	case "Edm.String", "Edm.Guid":
+		return KindString
+	case "Edm.Boolean":
+		return KindBool
+	case "Edm.DateTimeOffset", "Edm.Date":
+		return KindTime
+	case "Edm.Byte", "Edm.Int16", "Edm.Int32", "Edm.Int64", "Edm.Decimal", "Edm.Double", "Edm.Single":
+		return KindNumber
+	}
+	return KindNull
+}
+
+// WriteMetadata writes the CSDL XML document of a schema.
+func WriteMetadata(w io.Writer, s *Schema) error {
+	schema := csdlSchema{XMLNS: "http://docs.oasis-open.org/odata/ns/edm", Namespace: s.Namespace}
+	for _, t := range s.EntityTypes {
+		et := csdlEntityType{Name: t.Name, Key: csdlKey{PropertyRef: csdlPropertyRef{Name: t.Key}}}
+		for _, p := range t.Properties {
+			cp := csdlProperty{Name: p.Name, Type: p.Type}
+			if !p.Nullable {
+				cp.Nullable = "false"
+			}
+			et.Properties = append(et.Properties, cp)
+		}
+		for _, n := range t.Navigation {
+			typ := s.Namespace + "." + n.Type.Name
+			if n.Collection {
+				typ = "Collection(" + typ + ")"
+			}
+			et.Navigation = append(et.Navigation, csdlNavigationProperty{Name: n.Name, Type: typ, ContainsTarget: "true"})
+		}
+		schema.EntityTypes = append(schema.EntityTypes, et)
+	}
+	schema.Container.Name = s.Container
+	for _, set := range s.EntitySets {
+		schema.Container.EntitySets = append(schema.Container.EntitySets, csdlEntitySet{Name: set.Name, EntityType: s.Namespace + "." + set.Type.Name})
+	}
+
+	doc := csdlDocument{XMLNSEdmx: "http://docs.oasis-open.org/odata/ns/edmx", Version: Version}
+	doc.DataServices.Schema = schema
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// MetadataURL returns the $metadata URL of a service root.
+func MetadataURL(root string) string {
+	return strings.TrimSuffix(root, "/") + "/$metadata"
+}
+
+type csdlDocument struct {
+	XMLName      xml.Name `xml:"edmx:Edmx"`
+	XMLNSEdmx    string   `xml:"xmlns:edmx,attr"`
+	Version      string   `xml:"Version,attr"`
+	DataServices struct {
+		Schema csdlSchema `xml:"Schema"`
+	} `xml:"edmx:DataServices"`
+}
+
+type csdlSchema struct {
+	XMLNS       string           `xml:"xmlns,attr"`
+	Namespace   string           `xml:"Namespace,attr"`
+	EntityTypes []csdlEntityType `xml:"EntityType"`
+	Container   struct {
+		Name       string          `xml:"Name,attr"`
+		EntitySets []csdlEntitySet `xml:"EntitySet"`
+	} `xml:"EntityContainer"`
+}
+
+type csdlEntityType struct {
+	Name       string                   `xml:"Name,attr"`
+	Key        csdlKey                  `xml:"Key"`
+	Properties []csdlProperty           `xml:"Property"`
+	Navigation []csdlNavigationProperty `xml:"NavigationProperty"`
+}
+
+type csdlKey struct {
+	PropertyRef csdlPropertyRef `xml:"PropertyRef"`
+}
+
+type csdlPropertyRef struct {
+	Name string `xml:"Name,attr"`
+}
+
+type csdlProperty struct {
+	Name     string `xml:"Name,attr"`
+	Type     string `xml:"Type,attr"`
+	Nullable string `xml:"Nullable,attr,omitempty"`
+}
+
+type csdlNavigationProperty struct {
+	Name           string `xml:"Name,attr"`
+	Type           string `xml:"Type,attr"`
+	ContainsTarget string `xml:"ContainsTarget,attr,omitempty"`
+}
+
+type csdlEntitySet struct {
+	Name       string `xml:"Name,attr"`
+	EntityType string `xml:"EntityType,attr"`
+}
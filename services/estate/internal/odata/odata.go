@@ -0,0 +1,180 @@
+// Package odata implements the read side of the OData v4 protocol: system
+// query options, $filter expressions, entity serialization and the CSDL
+// $metadata document. Entity sets and how they are stored are left to the
+// caller.
+package odata
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Version is the OData-Version header value of responses.
+const Version = "4.0"
+
+// Media types of OData responses.
+const (
+	ContentType         = "application/json;odata.metadata=minimal"
+	MetadataContentType = "application/xml"
+)
+
+// ErrNotImplemented is returned for system query options the package does
+// not support, e.g. $search or $apply.
+var ErrNotImplemented = errors.New("not implemented")
+
+// Query holds the system query options of a request.
+type Query struct {
+	Filter  Expr     // nil for no filter
+	Select  []string // Empty for every property
+	Expand  []string // Navigation properties
+	OrderBy []OrderBy
+	Top     *int // nil for no limit
+	Skip    int
+	Count   bool
+}
+
+// OrderBy orders results by a property.
+type OrderBy struct {
+	Property string
+	Desc     bool
+}
+
+// ParseQuery parses the system query options of a request URL. Custom
+// query options, which do not start with "$", are ignored.
+func ParseQuery(values url.Values) (*Query, error) {
+	q := &Query{}
+	for name, v := range values {
+		if !strings.HasPrefix(name, "$") {
+			continue
+		}
+		if len(v) > 1 {
+			return nil, fmt.Errorf("%s is repeated", name)
+		}
+		value := strings.TrimSpace(v[0])
+
+		var err error
+		switch name {
+		case "$filter":
+			if q.Filter, err = ParseFilter(value); err != nil {
+				err = fmt.Errorf("$filter: %w", err)
+			}
+		case "$select":
+			if value != "*" {
+				q.Select, err = parseNames(name, value)
+			}
+		case "$expand":
+			if strings.ContainsAny(value, "()") {
+				return nil, fmt.Errorf("$expand options are %w", ErrNotImplemented)
+			}
+			q.Expand, err = parseNames(name, value)
+		case "$orderby":
+			q.OrderBy, err = parseOrderBy(value)
+		case "$top":
+			var n int
+			if n, err = parseCount(name, value); err == nil {
+				q.Top = &n
+			}
+		case "$skip":
+			q.Skip, err = parseCount(name, value)
+		case "$count":
+			switch value {
+			case "true":
+				q.Count = true
+			case "false":
+			default:
+				err = fmt.Errorf("$count must be true or false")
+			}
+		case "$format":
+			if value != "json" && !strings.HasPrefix(value, "application/json") {
+				err = fmt.Errorf("$format %q is not supported", value)
+			}
+		case "$search", "$apply", "$compute", "$index", "$skiptoken", "$deltatoken", "$levels":
+			return nil, fmt.Errorf("%s is %w", name, ErrNotImplemented)
+		default:
+			err = fmt.Errorf("unknown system query option %s", name)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return q, nil
+}
+
+func parseNames(option, value string) ([]string, error) {
+	var names []string
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if !identPattern.MatchString(name) {
+			return nil, fmt.Errorf("%s: invalid property %q", option, name)
+		}
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+func parseOrderBy(value string) ([]OrderBy, error) {
+	var orders []OrderBy
+	for _, item := range strings.Split(value, ",") {
+		fields := strings.Fields(item)
+		if len(fields) == 0 || len(fields) > 2 || !identPattern.MatchString(fields[0]) {
+			return nil, fmt.Errorf("$orderby: invalid item %q", strings.TrimSpace(item))
+		}
+		order := OrderBy{Property: fields[0]}
+		if len(fields) == 2 {
+			switch fields[1] {
+			case "asc":
+			case "desc":
+				order.Desc = true
+			default:
+				return nil, fmt.Errorf("$orderby: invalid direction %q", fields[1])
+			}
+		}
+		orders = append(orders, order)
+	}
+	return orders, nil
+}
+
+func parseCount(option, value string) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative integer", option)
+	}
+	return n, nil
+}
+
+// Validate checks the options against the entity type: selected, filtered
+// and ordered properties must exist, ordered ones must be sortable and
+// expanded ones must be navigation properties.
+func (q *Query) Validate(t *EntityType) error {
+	for _, name := range q.Select {
+		if t.Property(name) == nil && t.NavigationProperty(name) == nil {
+			return fmt.Errorf("$select: %s has no property %s", t.Name, name)
+		}
+	}
+	for _, name := range q.Expand {
+		if t.NavigationProperty(name) == nil {
+			return fmt.Errorf("$expand: %s has no navigation property %s", t.Name, name)
+		}
+	}
+	for _, o := range q.OrderBy {
+		p := t.Property(o.Property)
+		if p == nil {
+			return fmt.Errorf("$orderby: %s has no property %s", t.Name, o.Property)
+		}
+		if !p.Sortable {
+			return fmt.Errorf("$orderby: cannot order by %s", o.Property)
+		}
+	}
+	if q.Filter != nil {
+		if err := Check(q.Filter, t.Kinds()); err != nil {
+			return fmt.Errorf("$filter: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+package odata
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	testMedia = &EntityType{
+		Name:       "Media",
+		Key:        "MediaKey",
+		Properties: []PropertyType{{Name: "MediaKey", Type: "Edm.String"}},
+	}
+	testListing = &EntityType{
+		Name: "Listing",
+		Key:  "Key",
+		Properties: []PropertyType{
+			{Name: "Key", Type: "Edm.String"},
+			{Name: "City", Type: "Edm.String", Nullable: true},
+			{Name: "Price", Type: "Edm.Decimal", Nullable: true, Sortable: true},
+		},
+		Navigation: []NavigationProperty{{Name: "Media", Type: testMedia, Collection: true}},
+	}
+)
+
+func TestParseQuery(t *testing.T) {
+	values := url.Values{
+		"$filter":  {"Price gt 100"},
+		"$select":  {"City, Price,City"},
+		"$expand":  {"Media"},
+		"$orderby": {"Price desc, City"},
+		"$top":     {"10"},
+		"$skip":    {"20"},
+		"$count":   {"true"},
+		"$format":  {"json"},
+		"custom":   {"ignored"},
+	}
+	q, err := ParseQuery(values)
+	if err != nil {
+		t.Fatalf("ParseQuery: %v", err)
+	}
+	top := 10
+	want := &Query{
+		Filter:  Binary{Op: "gt", Left: Path{Name: "Price"}, Right: Literal{Value: int64(100)}},
+		Select:  []string{"City", "Price"},
+		Expand:  []string{"Media"},
+		OrderBy: []OrderBy{{Property: "Price", Desc: true}, {Property: "City"}},
+		Top:     &top,
+		Skip:    20,
+		Count:   true,
+	}
+	if !reflect.DeepEqual(q, want) {
+		t.Errorf("expected %+v, got %+v", want, q)
+	}
+}
+
+func TestParseQueryInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		values url.Values
+		want   string
+	}{
+		{"filter", url.Values{"$filter": {"City eq"}}, "$filter: unexpected end of expression"},
+		{"top", url.Values{"$top": {"-1"}}, "$top must be a non-negative integer"},
+		{"skip", url.Values{"$skip": {"x"}}, "$skip must be a non-negative integer"},
+		{"count", url.Values{"$count": {"yes"}}, "$count must be true or false"},
+		{"orderby", url.Values{"$orderby": {"Price down"}}, `$orderby: invalid direction "down"`},
+		{"select", url.Values{"$select": {"City,Media/MediaKey"}}, `$select: invalid property "Media/MediaKey"`},
+		{"format", url.Values{"$format": {"xml"}}, `$format "xml" is not supported`},
+		{"repeated", url.Values{"$top": {"1", "2"}}, "$top is repeated"},
+		{"unknown", url.Values{"$foo": {"1"}}, "unknown system query option $foo"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseQuery(tt.values)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("expected error %q, got %v", tt.want, err)
+			}
+		})
+	}
+
+	for _, values := range []url.Values{{"$search": {"pool"}}, {"$expand": {"Media($top=1)"}}} {
+		if _, err := ParseQuery(values); !errors.Is(err, ErrNotImplemented) {
+			t.Errorf("%v: expected ErrNotImplemented, got %v", values, err)
+		}
+	}
+}
+
+func TestQueryValidate(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"$select=City,Media&$expand=Media&$orderby=Price&$filter=City eq 'Madrid'", ""},
+		{"$select=Beds", "$select: Listing has no property Beds"},
+		{"$expand=City", "$expand: Listing has no navigation property City"},
+		{"$orderby=City", "$orderby: cannot order by City"},
+		{"$orderby=Beds", "$orderby: Listing has no property Beds"},
+		{"$filter=Price eq 'x'", "$filter: cannot compare number with string"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			values, _ := url.ParseQuery(strings.ReplaceAll(tt.query, " ", "+"))
+			q, err := ParseQuery(values)
+			if err != nil {
+				t.Fatalf("ParseQuery: %v", err)
+			}
+			err = q.Validate(testListing)
+			if tt.want == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if tt.want != "" && (err == nil || err.Error() != tt.want) {
+				t.Errorf("expected error %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestCollectionJSON(t *testing.T) {
+	media := NewEntity()
+	media.Set("MediaKey", "m1")
+
+	e := NewEntity()
+	e.Set("Key", "k1")
+	e.Set("City", nil)
+	e.Set("Price", 1.5)
+	e.Set("Listed", time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC))
+	e.Set("Media", []*Entity{media})
+	e.Set("City", "Madrid")
+
+	count := int64(7)
+	data, err := json.Marshal(Collection{
+		Context:  "/odata/$metadata#Listing",
+		Count:    &count,
+		Value:    []*Entity{e, e.Select([]string{"Price"})},
+		NextLink: "/odata/Listing?$skip=2",
+	})
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	want := `{"@odata.context":"/odata/$metadata#Listing","@odata.count":7,"value":[` +
+		`{"Key":"k1","City":"Madrid","Price":1.5,"Listed":"2024-05-01T10:00:00Z","Media":[{"MediaKey":"m1"}]},` +
+		`{"Price":1.5}],"@odata.nextLink":"/odata/Listing?$skip=2"}`
+	if string(data) != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, data)
+	}
+
+	data, _ = json.Marshal(Collection{Context: "c"})
+	if string(data) != `{"@odata.context":"c","value":[]}` {
+		t.Errorf("unexpected empty collection %s", data)
+	}
+
+	data, _ = json.Marshal(e.Select([]string{"Key"}).WithContext("c/$entity"))
+	if string(data) != `{"@odata.context":"c/$entity","Key":"k1"}` {
+		t.Errorf("unexpected entity %s", data)
+	}
+}
+
+func TestWriteMetadata(t *testing.T) {
+	var buf bytes.Buffer
+	schema := &Schema{
+		Namespace:   "org.example",
+		Container:   "Default",
+		EntityTypes: []*EntityType{testListing, testMedia},
+		EntitySets:  []EntitySet{{Name: "Listing", Type: testListing}},
+	}
+	if err := WriteMetadata(&buf, schema); err != nil {
+		t.Fatalf("WriteMetadata: %v", err)
+	}
+
+	var doc struct {
+		Version string `xml:"Version,attr"`
+		Schema  struct {
+			Namespace   string `xml:"Namespace,attr"`
+			EntityTypes []struct {
+				Name string `xml:"Name,attr"`
+				Key  struct {
+					Name string `xml:"Name,attr"`
+				} `xml:"Key>PropertyRef"`
+				Properties []struct {
+					Name     string `xml:"Name,attr"`
+					Type     string `xml:"Type,attr"`
+					Nullable string `xml:"Nullable,attr"`
+				} `xml:"Property"`
+				Navigation []struct {
+					Name string `xml:"Name,attr"`
+					Type string `xml:"Type,attr"`
+				} `xml:"NavigationProperty"`
+			} `xml:"EntityType"`
+			EntitySets []struct {
+				Name       string `xml:"Name,attr"`
+				EntityType string `xml:"EntityType,attr"`
+			} `xml:"EntityContainer>EntitySet"`
+		} `xml:"DataServices>Schema"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("parsing metadata: %v\n%s", err, buf.String())
+	}
+
+	s := doc.Schema
+	if doc.Version != "4.0" || s.Namespace != "org.example" || len(s.EntityTypes) != 2 || len(s.EntitySets) != 1 {
+		t.Fatalf("unexpected metadata:\n%s", buf.String())
+	}
+	listing := s.EntityTypes[0]
+	if listing.Name != "Listing" || listing.Key.Name != "Key" || len(listing.Properties) != 3 {
+		t.Errorf("unexpected entity type %+v", listing)
+	}
+	if p := listing.Properties[0]; p.Name != "Key" || p.Type != "Edm.String" || p.Nullable != "false" {
+		t.Errorf("unexpected key property %+v", p)
+	}
+	if p := listing.Properties[1]; p.Nullable != "" {
+		t.Errorf("expected a nullable property, got %+v", p)
+	}
+	if len(listing.Navigation) != 1 || listing.Navigation[0].Type != "Collection(org.example.Media)" {
+		t.Errorf("unexpected navigation %+v", listing.Navigation)
+	}
+	if s.EntitySets[0].EntityType != "org.example.Listing" {
+		t.Errorf("unexpected entity set %+v", s.EntitySets[0])
+	}
+}
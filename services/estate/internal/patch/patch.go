@@ -0,0 +1,404 @@
+// Package patch applies JSON Merge Patch (RFC 7396) and JSON Patch (RFC 6902)
+// documents to JSON values.
+package patch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Media types of the supported patch formats.
+const (
+	MergePatchType = "application/merge-patch+json"
+	JSONPatchType  = "application/json-patch+json"
+)
+
+var (
+	// ErrInvalidPatch is returned for malformed patch documents.
+	ErrInvalidPatch = errors.New("invalid patch")
+	// ErrConflict is returned when a valid patch cannot be applied to the
+	// document, e.g. a missing path or a failed test operation.
+	ErrConflict = errors.New("patch cannot be applied")
+)
+
+// Merge applies a JSON Merge Patch to doc. Objects are merged recursively,
+// null removes a member and any other value, arrays included, replaces it.
+func Merge(doc, patch []byte) ([]byte, error) {
+	var target any
+	if err := decode(doc, &target); err != nil {
+		return nil, fmt.Errorf("invalid document: %w", err)
+	}
+
+	var p any
+	if err := decode(patch, &p); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+
+	return json.Marshal(mergeValue(target, p))
+}
+
+func mergeValue(target, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	t, ok := target.(map[string]any)
+	if !ok {
+		t = map[string]any{}
+	}
+	for key, value := range p {
+		if value == nil {
+			delete(t, key)
+			continue
+		}
+		t[key] = mergeValue(t[key], value)
+	}
+	return t
+}
+
+// Operation is a single JSON Patch operation.
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  *string         `json:"path"`
+	From  *string         `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// Apply applies a JSON Patch to doc. Operations are applied in order and the
+// patch is atomic: on error doc is left unchanged and nothing is returned.
+func Apply(doc, patch []byte) ([]byte, error) {
+	var target any
+	if err := decode(doc, &target); err != nil {
+		return nil, fmt.Errorf("invalid document: %w", err)
+	}
+
+	var ops []Operation
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+
+	for i, op := range ops {
+		var err error
+		if target, err = apply(target, op); err != nil {
+			return nil, fmt.Errorf("operation %d (%s): %w", i, op.Op, err)
+		}
+	}
+
+	return json.Marshal(target)
+}
+
+func apply(doc any, op Operation) (any, error) {
+	if op.Path == nil {
+		return nil, fmt.Errorf("%w: missing path", ErrInvalidPatch)
+	}
+	path, err := parsePointer(*op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add", "replace", "test":
+		value, err := decodeValue(op.Value)
+		if err != nil {
+			return nil, err
+		}
+		switch op.Op {
+		case "add":
+			return add(doc, path, value)
+		case "replace":
+			if len(path) == 0 {
+				return value, nil
+			}
+			if _, err := get(doc, path); err != nil {
+				return nil, err
+			}
+			if doc, err = remove(doc, path); err != nil {
+				return nil, err
+			}
+			return add(doc, path, value)
+		default:
+			current, err := get(doc, path)
+			if err != nil {
+				return nil, err
+			}
+			if !equal(current, value) {
+				return nil, fmt.Errorf("%w: test failed at %q", ErrConflict, *op.Path)
+			}
+			return doc, nil
+		}
+
+	case "remove":
+		return remove(doc, path)
+
+	case "move", "copy":
+		if op.From == nil {
+			return nil, fmt.Errorf("%w: missing from", ErrInvalidPatch)
+		}
+		from, err := parsePointer(*op.From)
+		if err != nil {
+			return nil, err
+		}
+		value, err := get(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		if op.Op == "copy" {
+			return add(doc, path, deepCopy(value))
+		}
+		if isProperPrefix(from, path) {
+			return nil, fmt.Errorf("%w: cannot move %q into itself", ErrInvalidPatch, *op.From)
+		}
+		if doc, err = remove(doc, from); err != nil {
+			return nil, err
+		}
+		return add(doc, path, value)
+
+	default:
+		return nil, fmt.Errorf("%w: unknown op %q", ErrInvalidPatch, op.Op)
+	}
+}
+
+func decodeValue(raw json.RawMessage) (any, error) {
+	if raw == nil {
+		return nil, fmt.Errorf("%w: missing value", ErrInvalidPatch)
+	}
+	var value any
+	if err := decode(raw, &value); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+	return value, nil
+}
+
+// decode unmarshals a single JSON value into v keeping numbers as
+// json.Number, so the ones a patch does not touch are written back exactly
+// as they were instead of going through float64.
+func decode(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return errors.New("unexpected data after top-level value")
+	}
+	return nil
+}
+
+// equal reports whether two decoded values are equal, comparing numbers by
+// value as RFC 6902 requires, e.g. 1 equals 1.0.
+func equal(a, b any) bool {
+	switch x := a.(type) {
+	case json.Number:
+		y, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		rx, okx := new(big.Rat).SetString(x.String())
+		ry, oky := new(big.Rat).SetString(y.String())
+		return okx && oky && rx.Cmp(ry) == 0
+	case map[string]any:
+		y, ok := b.(map[string]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for key, item := range x {
+			other, ok := y[key]
+			if !ok || !equal(item, other) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		y, ok := b.([]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !equal(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(a, b)
+	}
+}
+
+// parsePointer splits a JSON Pointer (RFC 6901) into unescaped reference tokens.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("%w: path %q must start with /", ErrInvalidPatch, pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func get(doc any, path []string) (any, error) {
+	current := doc
+	for _, token := range path {
+		switch c := current.(type) {
+		case map[string]any:
+			value, ok := c[token]
+			if !ok {
+				return nil, fmt.Errorf("%w: %q not found", ErrConflict, token)
+			}
+			current = value
+		case []any:
+			i, err := arrayIndex(token, len(c)-1)
+			if err != nil {
+				return nil, err
+			}
+			current = c[i]
+		default:
+			return nil, fmt.Errorf("%w: %q not found", ErrConflict, token)
+		}
+	}
+	return current, nil
+}
+
+// add returns doc with value added at path. Containers are updated in place
+// except for arrays, whose new slice is stored in the parent.
+func add(doc any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	parent, err := get(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	token := path[len(path)-1]
+
+	switch p := parent.(type) {
+	case map[string]any:
+		p[token] = value
+		return doc, nil
+	case []any:
+		i := len(p)
+		if token != "-" {
+			if i, err = arrayIndex(token, len(p)); err != nil {
+				return nil, err
+			}
+		}
+		updated := append(p[:i:i], append([]any{value}, p[i:]...)...)
+		return replaceContainer(doc, path[:len(path)-1], updated)
+	default:
+		return nil, fmt.Errorf("%w: cannot add to %q", ErrConflict, token)
+	}
+}
+
+func remove(doc any, path []string) (any, error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("%w: cannot remove the whole document", ErrConflict)
+	}
+
+	parent, err := get(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	token := path[len(path)-1]
+
+	switch p := parent.(type) {
+	case map[string]any:
+		if _, ok := p[token]; !ok {
+			return nil, fmt.Errorf("%w: %q not found", ErrConflict, token)
+		}
+		delete(p, token)
+		return doc, nil
+	case []any:
+		i, err := arrayIndex(token, len(p)-1)
+		if err != nil {
+			return nil, err
+		}
+		updated := append(p[:i:i], p[i+1:]...)
+		return replaceContainer(doc, path[:len(path)-1], updated)
+	default:
+		return nil, fmt.Errorf("%w: %q not found", ErrConflict, token)
+	}
+}
+
+// replaceContainer stores value at path, which is known to exist.
+func replaceContainer(doc any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	parent, err := get(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	token := path[len(path)-1]
+
+	switch p := parent.(type) {
+	case map[string]any:
+		p[token] = value
+	case []any:
+		i, err := arrayIndex(token, len(p)-1)
+		if err != nil {
+			return nil, err
+		}
+		p[i] = value
+	}
+	return doc, nil
+}
+
+// arrayIndex parses an array index token no greater than max.
+func arrayIndex(token string, max int) (int, error) {
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("%w: invalid array index %q", ErrInvalidPatch, token)
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 {
+		return 0, fmt.Errorf("%w: invalid array index %q", ErrInvalidPatch, token)
+	}
+	if i > max {
+		return 0, fmt.Errorf("%w: array index %d out of range", ErrConflict, i)
+	}
+	return i, nil
+}
+
+func isProperPrefix(prefix, path []string) bool {
+	if len(prefix) >= len(path) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func deepCopy(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		c := make(map[string]any, len(v))
+		for key, item := range v {
+			c[key] = deepCopy(item)
+		}
+		return c
+	case []any:
+		c := make([]any, len(v))
+		for i, item := range v {
+			c[i] = deepCopy(item)
+		}
+		return c
+	default:
+		return v
+	}
+}
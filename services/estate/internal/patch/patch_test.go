@@ -0,0 +1,164 @@
+package patch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	// Examples from RFC 7396, Appendix A.
+	tests := []struct {
+		doc, patch, want string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+
+	for _, tt := range tests {
+		got, err := Merge([]byte(tt.doc), []byte(tt.patch))
+		if err != nil {
+			t.Errorf("Merge(%s, %s): %v", tt.doc, tt.patch, err)
+			continue
+		}
+		assertJSON(t, got, tt.want)
+	}
+}
+
+func TestMergeInvalidPatch(t *testing.T) {
+	for _, p := range []string{`{`, `{} {}`} {
+		if _, err := Merge([]byte(`{}`), []byte(p)); !errors.Is(err, ErrInvalidPatch) {
+			t.Errorf("Merge(%s): expected ErrInvalidPatch, got %v", p, err)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	// Mostly examples from RFC 6902, Appendix A.
+	tests := []struct {
+		name, doc, patch, want string
+	}{
+		{"add member", `{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"baz":"qux","foo":"bar"}`},
+		{"add array element", `{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{"append array element", `{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":"baz"}]`, `{"foo":["bar","baz"]}`},
+		{"remove member", `{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`},
+		{"remove array element", `{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`},
+		{"replace", `{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`},
+		{"replace array element", `{"p":[{"a":1},{"a":2}]}`, `[{"op":"replace","path":"/p/1/a","value":5}]`, `{"p":[{"a":1},{"a":5}]}`},
+		{"move member", `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`, `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{"move array element", `{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`},
+		{"copy", `{"a":{"b":1}}`, `[{"op":"copy","from":"/a","path":"/c"},{"op":"replace","path":"/c/b","value":2}]`, `{"a":{"b":1},"c":{"b":2}}`},
+		{"test passes", `{"baz":"qux","foo":["a",2,"c"]}`, `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`, `{"baz":"qux","foo":["a",2,"c"]}`},
+		{"test equal numbers", `{"a":{"b":[1.0,2e1]}}`, `[{"op":"test","path":"/a","value":{"b":[1,20]}}]`, `{"a":{"b":[1,20]}}`},
+		{"add nested member", `{"foo":"bar"}`, `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`, `{"foo":"bar","child":{"grandchild":{}}}`},
+		{"escaped pointer", `{"a/b":1,"m~n":2}`, `[{"op":"replace","path":"/a~1b","value":3},{"op":"remove","path":"/m~0n"}]`, `{"a/b":3}`},
+		{"add null value", `{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":null}]`, `{"baz":null,"foo":"bar"}`},
+		{"replace root", `{"foo":"bar"}`, `[{"op":"replace","path":"","value":{"baz":1}}]`, `{"baz":1}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Apply([]byte(tt.doc), []byte(tt.patch))
+			if err != nil {
+				t.Fatalf("Apply: %v", err)
+			}
+			assertJSON(t, got, tt.want)
+		})
+	}
+}
+
+func TestApplyErrors(t *testing.T) {
+	tests := []struct {
+		name, doc, patch string
+		want             error
+	}{
+		{"malformed", `{}`, `{"op":"add"}`, ErrInvalidPatch},
+		{"unknown op", `{}`, `[{"op":"merge","path":"/a","value":1}]`, ErrInvalidPatch},
+		{"missing path", `{}`, `[{"op":"add","value":1}]`, ErrInvalidPatch},
+		{"missing value", `{}`, `[{"op":"add","path":"/a"}]`, ErrInvalidPatch},
+		{"relative path", `{}`, `[{"op":"add","path":"a","value":1}]`, ErrInvalidPatch},
+		{"leading zero index", `{"a":[1,2]}`, `[{"op":"remove","path":"/a/01"}]`, ErrInvalidPatch},
+		{"missing parent", `{"foo":"bar"}`, `[{"op":"add","path":"/baz/bat","value":"qux"}]`, ErrConflict},
+		{"remove missing", `{"foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, ErrConflict},
+		{"replace missing", `{"foo":"bar"}`, `[{"op":"replace","path":"/baz","value":1}]`, ErrConflict},
+		{"index out of range", `{"foo":["bar"]}`, `[{"op":"add","path":"/foo/2","value":"baz"}]`, ErrConflict},
+		{"test fails", `{"baz":"qux"}`, `[{"op":"test","path":"/baz","value":"bar"}]`, ErrConflict},
+		{"test number type", `{"baz":1}`, `[{"op":"test","path":"/baz","value":"1"}]`, ErrConflict},
+		{"move into child", `{"a":{"b":{}}}`, `[{"op":"move","from":"/a","path":"/a/b/c"}]`, ErrInvalidPatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Apply([]byte(tt.doc), []byte(tt.patch))
+			if !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestPatchKeepsUntouchedNumbers(t *testing.T) {
+	doc := []byte(`{"description":"old","prices":[{"amount":12345678901234567.89,"currency":"EUR"},{"amount":100.50,"currency":"USD"}]}`)
+	patches := []struct {
+		name  string
+		apply func(doc, patch []byte) ([]byte, error)
+		patch string
+	}{
+		{"merge", Merge, `{"description":"new"}`},
+		{"json patch", Apply, `[{"op":"replace","path":"/description","value":"new"}]`},
+	}
+
+	for _, p := range patches {
+		t.Run(p.name, func(t *testing.T) {
+			got, err := p.apply(doc, []byte(p.patch))
+			if err != nil {
+				t.Fatalf("patch: %v", err)
+			}
+			for _, amount := range []string{`"amount":12345678901234567.89`, `"amount":100.50`} {
+				if !bytes.Contains(got, []byte(amount)) {
+					t.Errorf("expected %s unchanged in %s", amount, got)
+				}
+			}
+		})
+	}
+}
+
+func TestApplyIsAtomic(t *testing.T) {
+	doc := []byte(`{"a":1}`)
+	patch := []byte(`[{"op":"replace","path":"/a","value":2},{"op":"test","path":"/a","value":1}]`)
+
+	if got, err := Apply(doc, patch); err == nil || got != nil {
+		t.Fatalf("expected failure without result, got %s, %v", got, err)
+	}
+	assertJSON(t, doc, `{"a":1}`)
+}
+
+func assertJSON(t *testing.T, got []byte, want string) {
+	t.Helper()
+
+	var g, w any
+	if err := json.Unmarshal(got, &g); err != nil {
+		t.Fatalf("invalid result %s: %v", got, err)
+	}
+	if err := json.Unmarshal([]byte(want), &w); err != nil {
+		t.Fatalf("invalid expectation %s: %v", want, err)
+	}
+	if !reflect.DeepEqual(g, w) {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
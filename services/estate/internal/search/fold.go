@@ -0,0 +1,33 @@
+package search
+
+import (
+	"github.com/blevesearch/bleve/v2/analysis"
+	"github.com/blevesearch/bleve/v2/analysis/char/asciifolding"
+	"github.com/blevesearch/bleve/v2/registry"
+)
+
+// foldFilterName is the token filter that folds terms to ASCII.
+const foldFilterName = "estate_ascii_fold"
+
+// foldFilter folds token terms to ASCII ("kraków" to "krakow"). Folding
+// terms rather than the input text keeps token offsets on the original text,
+// which highlighting relies on.
+type foldFilter struct {
+	fold *asciifolding.AsciiFoldingFilter
+}
+
+func (f *foldFilter) Filter(input analysis.TokenStream) analysis.TokenStream {
+	for _, token := range input {
+		token.Term = f.fold.Filter(token.Term)
+	}
+	return input
+}
+
+func init() {
+	err := registry.RegisterTokenFilter(foldFilterName, func(map[string]any, *registry.Cache) (analysis.TokenFilter, error) {
+		return &foldFilter{fold: asciifolding.New()}, nil
+	})
+	if err != nil {
+		panic(err)
+	}
+}
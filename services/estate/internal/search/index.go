@@ -0,0 +1,284 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/search/highlight/highlighter/html"
+	"github.com/blevesearch/bleve/v2/search/query"
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/services/estate/internal/config"
+	"github.com/pulap/pulap/services/estate/internal/estate"
+)
+
+const mappingVersionKey = "mapping_version"
+
+// Index is an embedded full-text index of properties backed by Bleve.
+// It stores the name, description and address of each property, analyzed
+// once per supported locale.
+type Index struct {
+	mu      sync.RWMutex
+	idx     bleve.Index
+	fresh   bool
+	xparams config.XParams
+}
+
+// NewIndex creates a new full-text Index. The index is opened on Start.
+func NewIndex(xparams config.XParams) *Index {
+	return &Index{
+		xparams: xparams,
+	}
+}
+
+// Start opens the index at the configured path, creating it when missing or
+// when it was built with a different mapping. An empty path keeps the index
+// in memory.
+func (i *Index) Start(ctx context.Context) error {
+	path := i.xparams.Cfg().Search.Path
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if path == "" {
+		idx, err := create("")
+		if err != nil {
+			return err
+		}
+		i.idx, i.fresh = idx, true
+		i.xparams.Log().Infof("Opened in-memory search index")
+		return nil
+	}
+
+	idx, err := bleve.Open(path)
+	switch {
+	case errors.Is(err, bleve.ErrorIndexPathDoesNotExist):
+		idx, err = create(path)
+		i.fresh = true
+	case err == nil && !hasMappingVersion(idx):
+		i.xparams.Log().Infof("Search index mapping changed, rebuilding: %s", path)
+		idx.Close()
+		idx, err = recreate(path)
+		i.fresh = true
+	}
+	if err != nil {
+		return fmt.Errorf("cannot open search index: %w", err)
+	}
+
+	i.idx = idx
+	i.xparams.Log().Infof("Opened search index: %s", path)
+	return nil
+}
+
+// Stop closes the index.
+func (i *Index) Stop(ctx context.Context) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.idx != nil {
+		if err := i.idx.Close(); err != nil {
+			return fmt.Errorf("cannot close search index: %w", err)
+		}
+		i.idx = nil
+	}
+	return nil
+}
+
+// Fresh returns true if the index was created on Start and needs to be filled.
+func (i *Index) Fresh() bool {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.fresh
+}
+
+// Index adds or replaces a property in the index.
+func (i *Index) Index(p *estate.Property) error {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	if err := i.idx.Index(p.ID.String(), newDocument(p)); err != nil {
+		return fmt.Errorf("could not index property %s: %w", p.ID, err)
+	}
+	return nil
+}
+
+// Remove deletes a property from the index.
+func (i *Index) Remove(id uuid.UUID) error {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	if err := i.idx.Delete(id.String()); err != nil {
+		return fmt.Errorf("could not remove property %s from index: %w", id, err)
+	}
+	return nil
+}
+
+// Rebuild replaces the index content with the properties produced by fill,
+// which calls add once per page. The new index is built next to the current
+// one, which stays searchable until fill returns successfully.
+func (i *Index) Rebuild(ctx context.Context, fill func(add func([]*estate.Property) error) error) (int, error) {
+	path := i.xparams.Cfg().Search.Path
+	buildPath := ""
+	if path != "" {
+		buildPath = path + ".rebuild"
+		if err := os.RemoveAll(buildPath); err != nil {
+			return 0, fmt.Errorf("cannot clear search index build directory: %w", err)
+		}
+	}
+
+	next, err := create(buildPath)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	err = fill(func(properties []*estate.Property) error {
+		batch := next.NewBatch()
+		for _, p := range properties {
+			if err := batch.Index(p.ID.String(), newDocument(p)); err != nil {
+				return fmt.Errorf("could not index property %s: %w", p.ID, err)
+			}
+		}
+		if err := next.Batch(batch); err != nil {
+			return fmt.Errorf("could not write index batch: %w", err)
+		}
+		count += len(properties)
+		return nil
+	})
+	if err != nil {
+		next.Close()
+		if buildPath != "" {
+			os.RemoveAll(buildPath)
+		}
+		return 0, err
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.idx != nil {
+		i.idx.Close()
+		i.idx = nil
+	}
+
+	if path != "" {
+		next.Close()
+		if err := os.RemoveAll(path); err != nil {
+			return 0, fmt.Errorf("cannot remove previous search index: %w", err)
+		}
+		if err := os.Rename(buildPath, path); err != nil {
+			return 0, fmt.Errorf("cannot replace search index: %w", err)
+		}
+		if next, err = bleve.Open(path); err != nil {
+			return 0, fmt.Errorf("cannot open search index: %w", err)
+		}
+	}
+
+	i.idx, i.fresh = next, false
+	return count, nil
+}
+
+// SearchText runs a full-text query over name, address and description using
+// the locale analyzer. Hits are ordered by score and carry HTML highlights.
+func (i *Index) SearchText(ctx context.Context, q estate.TextQuery) ([]estate.TextHit, error) {
+	if !estate.IsTextLocale(q.Locale) {
+		return nil, fmt.Errorf("unsupported locale: %q", q.Locale)
+	}
+
+	disjuncts := make([]query.Query, 0, len(textFields))
+	fields := make([]string, 0, len(textFields))
+	for _, f := range textFields {
+		name := fieldName(f.name, q.Locale)
+		mq := bleve.NewMatchQuery(q.Text)
+		mq.SetField(name)
+		mq.Analyzer = analyzerName(q.Locale)
+		mq.SetBoost(f.boost)
+		disjuncts = append(disjuncts, mq)
+		fields = append(fields, name)
+	}
+
+	req := bleve.NewSearchRequestOptions(bleve.NewDisjunctionQuery(disjuncts...), q.Limit, 0, false)
+	req.Highlight = bleve.NewHighlightWithStyle(html.Name)
+	req.Highlight.Fields = fields
+
+	i.mu.RLock()
+	res, err := i.idx.SearchInContext(ctx, req)
+	i.mu.RUnlock()
+	if err != nil {
+		return nil, fmt.Errorf("could not search index: %w", err)
+	}
+
+	hits := make([]estate.TextHit, 0, len(res.Hits))
+	for _, h := range res.Hits {
+		id, err := uuid.Parse(h.ID)
+		if err != nil {
+			continue
+		}
+		hit := estate.TextHit{ID: id, Score: h.Score}
+		for _, f := range textFields {
+			if fragments := matchedFragments(h.Fragments[fieldName(f.name, q.Locale)]); len(fragments) > 0 {
+				if hit.Highlights == nil {
+					hit.Highlights = map[string][]string{}
+				}
+				hit.Highlights[f.name] = fragments
+			}
+		}
+		hits = append(hits, hit)
+	}
+
+	return hits, nil
+}
+
+// matchedFragments drops fragments without highlighted terms; the highlighter
+// returns the leading text of every requested field even when it did not match.
+func matchedFragments(fragments []string) []string {
+	var matched []string
+	for _, f := range fragments {
+		if strings.Contains(f, "<mark>") {
+			matched = append(matched, f)
+		}
+	}
+	return matched
+}
+
+// create builds an empty index; an empty path creates it in memory.
+func create(path string) (bleve.Index, error) {
+	im, err := newIndexMapping()
+	if err != nil {
+		return nil, err
+	}
+
+	var idx bleve.Index
+	if path == "" {
+		idx, err = bleve.NewMemOnly(im)
+	} else {
+		idx, err = bleve.New(path, im)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot create search index: %w", err)
+	}
+
+	if err := idx.SetInternal([]byte(mappingVersionKey), []byte(mappingVersion)); err != nil {
+		idx.Close()
+		return nil, fmt.Errorf("cannot write search index version: %w", err)
+	}
+	return idx, nil
+}
+
+// recreate removes the index at path and builds an empty one.
+func recreate(path string) (bleve.Index, error) {
+	if err := os.RemoveAll(path); err != nil {
+		return nil, err
+	}
+	return create(path)
+}
+
+func hasMappingVersion(idx bleve.Index) bool {
+	v, err := idx.GetInternal([]byte(mappingVersionKey))
+	return err == nil && string(v) == mappingVersion
+}
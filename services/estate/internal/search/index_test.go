@@ -0,0 +1,267 @@
+package search
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/pkg/lib/core"
+	"github.com/pulap/pulap/services/estate/internal/config"
+	"github.com/pulap/pulap/services/estate/internal/estate"
+	"github.com/pulap/pulap/services/estate/internal/estate/repotest"
+	"github.com/pulap/pulap/services/estate/internal/sqlite"
+)
+
+func TestIndexSearchText(t *testing.T) {
+	index := newTestIndex(t, "")
+
+	krakow := property("Mieszkanie przy Rynku", "Przestronne mieszkania z balkonem", "Kraków")
+	madrid := property("Piso luminoso", "Apartamento con terraza y vistas al parque", "Madrid")
+	london := property("Riverside flat", "Bright flats with a balcony overlooking the river", "London")
+	for _, p := range []*estate.Property{krakow, madrid, london} {
+		if err := index.Index(p); err != nil {
+			t.Fatalf("Index: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		text   string
+		locale string
+		want   []uuid.UUID
+	}{
+		{"diacritics folded in query", "krakow", "pl", []uuid.UUID{krakow.ID}},
+		{"diacritics kept in query", "KRAKÓW", "es", []uuid.UUID{krakow.ID}},
+		{"spanish plural stemmed", "terrazas", "es", []uuid.UUID{madrid.ID}},
+		{"spanish accent folded", "apartamentó", "es", []uuid.UUID{madrid.ID}},
+		{"polish inflection stemmed", "mieszkaniu", "pl", []uuid.UUID{krakow.ID}},
+		{"english plural stemmed", "balconies", "en", []uuid.UUID{london.ID}},
+		{"no match", "garaje", "es", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits, err := index.SearchText(context.Background(), estate.TextQuery{Text: tt.text, Locale: tt.locale, Limit: 10})
+			if err != nil {
+				t.Fatalf("SearchText: %v", err)
+			}
+			if got := hitIDs(hits); !sameIDs(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIndexSearchTextRanksAndHighlights(t *testing.T) {
+	index := newTestIndex(t, "")
+
+	inName := property("Ático con terraza", "Vivienda reformada", "Madrid")
+	inDescription := property("Piso reformado", "Salón amplio y terraza", "Madrid")
+	for _, p := range []*estate.Property{inDescription, inName} {
+		if err := index.Index(p); err != nil {
+			t.Fatalf("Index: %v", err)
+		}
+	}
+
+	hits, err := index.SearchText(context.Background(), estate.TextQuery{Text: "terraza", Locale: "es", Limit: 10})
+	if err != nil {
+		t.Fatalf("SearchText: %v", err)
+	}
+	if len(hits) != 2 || hits[0].ID != inName.ID {
+		t.Fatalf("expected name match first, got %v", hitIDs(hits))
+	}
+
+	fragments := hits[0].Highlights["name"]
+	if len(fragments) == 0 || !strings.Contains(fragments[0], "<mark>terraza</mark>") {
+		t.Errorf("unexpected name highlights: %v", hits[0].Highlights)
+	}
+	if _, ok := hits[1].Highlights["description"]; !ok {
+		t.Errorf("expected description highlights, got %v", hits[1].Highlights)
+	}
+}
+
+func TestIndexSearchTextRejectsUnknownLocale(t *testing.T) {
+	index := newTestIndex(t, "")
+
+	if _, err := index.SearchText(context.Background(), estate.TextQuery{Text: "piso", Locale: "fr", Limit: 10}); err == nil {
+		t.Fatal("expected error for unsupported locale")
+	}
+}
+
+func TestIndexReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "search.bleve")
+	ctx := context.Background()
+
+	index := newTestIndex(t, path)
+	if !index.Fresh() {
+		t.Fatal("expected new index to be fresh")
+	}
+	p := property("Casa de campo", "Casa rural", "Toledo")
+	if err := index.Index(p); err != nil {
+		t.Fatalf("Index: %v", err)
+	}
+	if err := index.Stop(ctx); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+
+	reopened := newTestIndex(t, path)
+	if reopened.Fresh() {
+		t.Error("expected existing index not to be fresh")
+	}
+	hits, err := reopened.SearchText(ctx, estate.TextQuery{Text: "toledo", Locale: "es", Limit: 10})
+	if err != nil {
+		t.Fatalf("SearchText: %v", err)
+	}
+	if !sameIDs(hitIDs(hits), []uuid.UUID{p.ID}) {
+		t.Errorf("got %v, want %v", hitIDs(hits), p.ID)
+	}
+}
+
+func TestIndexedRepoSync(t *testing.T) {
+	ctx := context.Background()
+	repo, _ := newTestIndexedRepo(t)
+
+	p := repotest.NewProperty("Piso con piscina")
+	if err := repo.Create(ctx, p); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	assertHits(t, repo, "piscina", p.ID)
+
+	p.Name = "Piso con jardín"
+	if err := repo.Save(ctx, p); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	assertHits(t, repo, "piscina")
+	assertHits(t, repo, "jardin", p.ID)
+
+	if err := repo.Delete(ctx, p.ID, 0); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	assertHits(t, repo, "jardin")
+
+	// A failed write must not reach the index.
+	missing := repotest.NewProperty("Piso fantasma")
+	if err := repo.Save(ctx, missing); err == nil {
+		t.Fatal("expected Save of missing property to fail")
+	}
+	assertHits(t, repo, "fantasma")
+}
+
+func TestIndexedRepoReindex(t *testing.T) {
+	ctx := context.Background()
+	repo, index := newTestIndexedRepo(t)
+
+	var ids []uuid.UUID
+	for _, name := range []string{"Loft industrial", "Loft céntrico", "Casa adosada"} {
+		p := repotest.NewProperty(name)
+		if err := repo.Repo.Create(ctx, p); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		ids = append(ids, p.ID)
+	}
+	assertHits(t, repo, "loft")
+
+	count, err := repo.Reindex(ctx)
+	if err != nil {
+		t.Fatalf("Reindex: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("expected 3 indexed properties, got %d", count)
+	}
+	if index.Fresh() {
+		t.Error("expected index not to be fresh after reindex")
+	}
+	assertHits(t, repo, "loft", ids[0], ids[1])
+	assertHits(t, repo, "centrico", ids[1])
+}
+
+func newTestIndex(t *testing.T, path string) *Index {
+	t.Helper()
+
+	cfg := config.New()
+	cfg.Search.Path = path
+	index := NewIndex(config.NewXParams(core.NewNoopLogger(), cfg))
+
+	ctx := context.Background()
+	if err := index.Start(ctx); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() { index.Stop(ctx) })
+	return index
+}
+
+func newTestIndexedRepo(t *testing.T) (*IndexedRepo, *Index) {
+	t.Helper()
+
+	cfg := config.New()
+	cfg.Database.Path = filepath.Join(t.TempDir(), "estate.db")
+	cfg.Search.Path = ""
+	xparams := config.NewXParams(core.NewNoopLogger(), cfg)
+
+	ctx := context.Background()
+	inner := sqlite.NewPropertyRepo(xparams)
+	if err := inner.Start(ctx); err != nil {
+		t.Fatalf("Start repo: %v", err)
+	}
+	t.Cleanup(func() { inner.Stop(ctx) })
+
+	index := NewIndex(xparams)
+	if err := index.Start(ctx); err != nil {
+		t.Fatalf("Start index: %v", err)
+	}
+	t.Cleanup(func() { index.Stop(ctx) })
+
+	repo := NewIndexedRepo(inner, index, xparams)
+	if err := repo.Start(ctx); err != nil {
+		t.Fatalf("Start indexed repo: %v", err)
+	}
+	return repo, index
+}
+
+func property(name, description, city string) *estate.Property {
+	p := estate.New()
+	p.Name = name
+	p.Description = description
+	p.Location.Address = estate.Address{Street: "Calle Mayor", Number: "1", City: city, Country: "ES"}
+	return p
+}
+
+func assertHits(t *testing.T, searcher estate.TextSearcher, text string, want ...uuid.UUID) {
+	t.Helper()
+
+	hits, err := searcher.SearchText(context.Background(), estate.TextQuery{Text: text, Locale: "es", Limit: 10})
+	if err != nil {
+		t.Fatalf("SearchText(%q): %v", text, err)
+	}
+	if got := hitIDs(hits); !sameIDs(got, want) {
+		t.Errorf("SearchText(%q) = %v, want %v", text, got, want)
+	}
+}
+
+func hitIDs(hits []estate.TextHit) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(hits))
+	for _, h := range hits {
+		ids = append(ids, h.ID)
+	}
+	return ids
+}
+
+func sameIDs(a, b []uuid.UUID) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	seen := make(map[uuid.UUID]int, len(a))
+	for _, id := range a {
+		seen[id]++
+	}
+	for _, id := range b {
+		seen[id]--
+		if seen[id] < 0 {
+			return false
+		}
+	}
+	return true
+}
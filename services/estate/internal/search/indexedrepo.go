@@ -0,0 +1,121 @@
+package search
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/services/estate/internal/config"
+	"github.com/pulap/pulap/services/estate/internal/estate"
+)
+
+// IndexedRepo decorates an estate.Repo keeping the full-text Index in sync
+// with successful writes. Index failures are logged and do not fail the
+// write; a reindex repairs any drift.
+type IndexedRepo struct {
+	estate.Repo
+	index   *Index
+	xparams config.XParams
+}
+
+// NewIndexedRepo wraps repo so that writes are mirrored into index.
+func NewIndexedRepo(repo estate.Repo, index *Index, xparams config.XParams) *IndexedRepo {
+	return &IndexedRepo{
+		Repo:    repo,
+		index:   index,
+		xparams: xparams,
+	}
+}
+
+// Start fills the index when it was just created. The wrapped repository and
+// the index must be started before.
+func (r *IndexedRepo) Start(ctx context.Context) error {
+	if !r.index.Fresh() {
+		return nil
+	}
+
+	count, err := r.Reindex(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot build search index: %w", err)
+	}
+	r.xparams.Log().Infof("Indexed %d properties", count)
+	return nil
+}
+
+// Create creates the property and adds it to the index.
+func (r *IndexedRepo) Create(ctx context.Context, property *estate.Property) error {
+	if err := r.Repo.Create(ctx, property); err != nil {
+		return err
+	}
+	r.sync(property)
+	return nil
+}
+
+// Save saves the property and refreshes its index entry.
+func (r *IndexedRepo) Save(ctx context.Context, property *estate.Property) error {
+	if err := r.Repo.Save(ctx, property); err != nil {
+		return err
+	}
+	r.sync(property)
+	return nil
+}
+
+// Delete moves the property to the trash and removes it from the index.
+func (r *IndexedRepo) Delete(ctx context.Context, id uuid.UUID, revision int64) error {
+	if err := r.Repo.Delete(ctx, id, revision); err != nil {
+		return err
+	}
+	if err := r.index.Remove(id); err != nil {
+		r.xparams.Log().Errorf("search index out of sync: %v", err)
+	}
+	return nil
+}
+
+// Undelete restores the property from the trash and adds it back to the index.
+func (r *IndexedRepo) Undelete(ctx context.Context, id uuid.UUID, revision int64) error {
+	if err := r.Repo.Undelete(ctx, id, revision); err != nil {
+		return err
+	}
+	property, err := r.Repo.Get(ctx, id)
+	if err != nil {
+		r.xparams.Log().Errorf("search index out of sync: %v", err)
+		return nil
+	}
+	r.sync(property)
+	return nil
+}
+
+// SearchText runs a full-text query against the index.
+func (r *IndexedRepo) SearchText(ctx context.Context, query estate.TextQuery) ([]estate.TextHit, error) {
+	return r.index.SearchText(ctx, query)
+}
+
+// Reindex rebuilds the index from every property in the repository and
+// returns how many were indexed.
+func (r *IndexedRepo) Reindex(ctx context.Context) (int, error) {
+	return r.index.Rebuild(ctx, func(add func([]*estate.Property) error) error {
+		query := estate.PropertyQuery{Limit: estate.MaxSearchLimit}
+		query.Normalize()
+
+		for {
+			page, err := r.Repo.Search(ctx, query)
+			if err != nil {
+				return fmt.Errorf("could not read properties: %w", err)
+			}
+			if err := add(page.Items); err != nil {
+				return err
+			}
+			if page.NextCursor == "" {
+				return nil
+			}
+			query.Cursor = page.NextCursor
+		}
+	})
+}
+
+func (r *IndexedRepo) sync(property *estate.Property) {
+	if err := r.index.Index(property); err != nil {
+		r.xparams.Log().Errorf("search index out of sync: %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+package search
+
+import (
+	"fmt"
+
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/custom"
+	"github.com/blevesearch/bleve/v2/analysis/lang/en"
+	"github.com/blevesearch/bleve/v2/analysis/lang/es"
+	"github.com/blevesearch/bleve/v2/analysis/lang/pl"
+	"github.com/blevesearch/bleve/v2/analysis/token/lowercase"
+	"github.com/blevesearch/bleve/v2/analysis/token/porter"
+	"github.com/blevesearch/bleve/v2/analysis/tokenizer/unicode"
+	"github.com/blevesearch/bleve/v2/mapping"
+
+	"github.com/pulap/pulap/services/estate/internal/estate"
+)
+
+// mappingVersion identifies the analyzers and fields below. Bump it whenever
+// they change so existing indexes are rebuilt on start.
+const mappingVersion = "1"
+
+// Indexed text fields and their query boosts.
+var textFields = []struct {
+	name  string
+	boost float64
+}{
+	{"name", 3},
+	{"address", 2},
+	{"description", 1},
+}
+
+// localeTokenFilters lists the token filters per locale. Terms are
+// ASCII-folded after stop word removal and before stemming, so "Kraków" and
+// "krakow" match.
+var localeTokenFilters = map[string][]string{
+	"en": {en.PossessiveName, lowercase.Name, en.StopName, foldFilterName, porter.Name},
+	"es": {lowercase.Name, es.StopName, foldFilterName, es.LightStemmerName},
+	"pl": {lowercase.Name, pl.StopName, foldFilterName, pl.SnowballStemmerName},
+}
+
+// document is the indexed form of a property.
+type document struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Address     string `json:"address"`
+}
+
+func newDocument(p *estate.Property) document {
+	address := p.Location.Address.FullAddress()
+	if p.Location.DisplayName != "" && p.Location.DisplayName != address {
+		address += " " + p.Location.DisplayName
+	}
+	return document{
+		Name:        p.Name,
+		Description: p.Description,
+		Address:     address,
+	}
+}
+
+// newIndexMapping indexes each text field once per locale, as <field>_<locale>,
+// with the locale analyzer.
+func newIndexMapping() (mapping.IndexMapping, error) {
+	im := mapping.NewIndexMapping()
+
+	for _, locale := range estate.TextLocales {
+		err := im.AddCustomAnalyzer(analyzerName(locale), map[string]any{
+			"type":          custom.Name,
+			"tokenizer":     unicode.Name,
+			"token_filters": localeTokenFilters[locale],
+		})
+		if err != nil {
+			return nil, fmt.Errorf("cannot register %s analyzer: %w", locale, err)
+		}
+	}
+
+	doc := mapping.NewDocumentStaticMapping()
+	for _, field := range textFields {
+		for _, locale := range estate.TextLocales {
+			fm := mapping.NewTextFieldMapping()
+			fm.Name = fieldName(field.name, locale)
+			fm.Analyzer = analyzerName(locale)
+			fm.Store = true
+			fm.IncludeTermVectors = true
+			fm.IncludeInAll = false
+			doc.AddFieldMappingsAt(field.name, fm)
+		}
+	}
+
+	im.DefaultMapping = doc
+	return im, nil
+}
+
+func analyzerName(locale string) string {
+	return locale + "_folded"
+}
+
+func fieldName(field, locale string) string {
+	return field + "_" + locale
+}
@@ -0,0 +1,43 @@
+package sqlite
+
+const (
+	// appointmentColumns lists the appointments columns in scan order.
+	appointmentColumns = `id, property_id, agent_id, client_name, client_email, client_phone, starts_at, ends_at,
+		status, notes, revision, created_at, created_by, updated_at, updated_by`
+
+	// appointmentConflict matches the other appointments holding their slots
+	// too close to an appointment, taking whether it holds its own slot, its
+	// ID, then the agent and property with the ends and starts of their
+	// buffered windows.
+	appointmentConflict = `? AND other.status <> 'cancelled' AND other.id <> ? AND (
+		(other.agent_id = ? AND other.starts_at < ? AND other.ends_at > ?) OR
+		(other.property_id = ? AND other.starts_at < ? AND other.ends_at > ?))`
+
+	// QueryCreateAppointment inserts an appointment unless it conflicts with
+	// another, taking the conflict arguments after the columns.
+	QueryCreateAppointment = `INSERT INTO appointments (` + appointmentColumns + `)
+		SELECT ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+		WHERE NOT EXISTS (SELECT 1 FROM appointments AS other WHERE ` + appointmentConflict + `)`
+
+	// QueryFindConflictingAppointment finds the first appointment an
+	// appointment conflicts with.
+	QueryFindConflictingAppointment = `SELECT ` + appointmentColumns + ` FROM appointments AS other
+		WHERE ` + appointmentConflict + ` ORDER BY starts_at LIMIT 1`
+
+	// QueryGetAppointment retrieves an appointment.
+	QueryGetAppointment = `SELECT ` + appointmentColumns + ` FROM appointments WHERE id = ?`
+
+	// QueryUpdateAppointment updates every mutable column of an appointment
+	// and increments its revision, if the revision still matches and it does
+	// not conflict with another, taking the conflict arguments last.
+	QueryUpdateAppointment = `UPDATE appointments SET agent_id = ?, client_name = ?, client_email = ?, client_phone = ?,
+		starts_at = ?, ends_at = ?, status = ?, notes = ?, updated_at = ?, updated_by = ?, revision = revision + 1
+		WHERE id = ? AND revision = ?
+		AND NOT EXISTS (SELECT 1 FROM appointments AS other WHERE ` + appointmentConflict + `)`
+
+	// QueryGetAppointmentRevision reads the revision of an appointment.
+	QueryGetAppointmentRevision = `SELECT revision FROM appointments WHERE id = ?`
+
+	// QueryListAppointments selects appointments; the WHERE, ORDER BY and LIMIT clauses are appended.
+	QueryListAppointments = `SELECT ` + appointmentColumns + ` FROM appointments`
+)
@@ -0,0 +1,197 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/services/estate/internal/config"
+	"github.com/pulap/pulap/services/estate/internal/estate"
+)
+
+// AppointmentRepo implements the estate.AppointmentRepo interface using SQLite.
+// Appointments are stored in the database of the property repository, which
+// must be started first.
+type AppointmentRepo struct {
+	properties *PropertyRepo
+	db         *sql.DB
+	xparams    config.XParams
+}
+
+// NewAppointmentRepo creates a new SQLite repository for Appointment aggregates
+// stored alongside properties.
+func NewAppointmentRepo(properties *PropertyRepo, xparams config.XParams) *AppointmentRepo {
+	return &AppointmentRepo{
+		properties: properties,
+		xparams:    xparams,
+	}
+}
+
+// Start takes the database connection of the property repository, whose
+// migrations create the appointments tables.
+func (r *AppointmentRepo) Start(ctx context.Context) error {
+	if r.properties.db == nil {
+		return fmt.Errorf("property repository not started")
+	}
+	r.db = r.properties.db
+	return nil
+}
+
+// CreateAppointment stores a new appointment unless it conflicts with
+// another under the buffers. The check and the insert are a single
+// statement.
+func (r *AppointmentRepo) CreateAppointment(ctx context.Context, a *estate.Appointment, buffers estate.AppointmentBuffers) error {
+	a.BeforeCreate()
+
+	args := []any{a.ID.String(), a.PropertyID.String()}
+	args = append(args, appointmentArgs(a)...)
+	args = append(args, a.Revision, a.CreatedAt.UTC(), a.CreatedBy, a.UpdatedAt.UTC(), a.UpdatedBy)
+	result, err := r.db.ExecContext(ctx, QueryCreateAppointment, append(args, appointmentConflictArgs(a, buffers)...)...)
+	if err != nil {
+		return fmt.Errorf("could not create appointment: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n > 0 {
+		return nil
+	}
+	return r.appointmentConflictError(ctx, a, buffers)
+}
+
+// GetAppointment retrieves an appointment.
+func (r *AppointmentRepo) GetAppointment(ctx context.Context, id uuid.UUID) (*estate.Appointment, error) {
+	a, err := scanAppointment(r.db.QueryRowContext(ctx, QueryGetAppointment, id.String()))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("appointment %s: %w", id, estate.ErrAppointmentNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not get appointment: %w", err)
+	}
+	return a, nil
+}
+
+// SaveAppointment updates an appointment if its revision still matches and
+// it does not conflict with another, and increments the revision.
+func (r *AppointmentRepo) SaveAppointment(ctx context.Context, a *estate.Appointment, buffers estate.AppointmentBuffers) error {
+	a.BeforeUpdate()
+
+	args := append(appointmentArgs(a), a.UpdatedAt.UTC(), a.UpdatedBy, a.ID.String(), a.Revision)
+	result, err := r.db.ExecContext(ctx, QueryUpdateAppointment, append(args, appointmentConflictArgs(a, buffers)...)...)
+	if err != nil {
+		return fmt.Errorf("could not save appointment: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n > 0 {
+		a.Revision++
+		return nil
+	}
+
+	var revision int64
+	err = r.db.QueryRowContext(ctx, QueryGetAppointmentRevision, a.ID.String()).Scan(&revision)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("appointment %s: %w", a.ID, estate.ErrAppointmentNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("could not check appointment: %w", err)
+	}
+	if revision != a.Revision {
+		return fmt.Errorf("appointment %s: %w", a.ID, estate.ErrRevisionConflict)
+	}
+	return r.appointmentConflictError(ctx, a, buffers)
+}
+
+// ListAppointments lists the appointments matching the query, soonest
+// first.
+func (r *AppointmentRepo) ListAppointments(ctx context.Context, query estate.AppointmentQuery) ([]*estate.Appointment, error) {
+	w := &whereBuilder{}
+	if len(query.PropertyIDs) > 0 {
+		w.add("property_id IN "+placeholders(len(query.PropertyIDs)), uuidArgs(query.PropertyIDs)...)
+	}
+	if len(query.AgentIDs) > 0 {
+		w.add("agent_id IN "+placeholders(len(query.AgentIDs)), stringArgs(query.AgentIDs)...)
+	}
+	if len(query.Statuses) > 0 {
+		w.add("status IN "+placeholders(len(query.Statuses)), stringArgs(query.Statuses)...)
+	}
+	if query.From != nil {
+		w.add("ends_at > ?", query.From.UTC())
+	}
+	if query.To != nil {
+		w.add("starts_at < ?", query.To.UTC())
+	}
+
+	stmt := QueryListAppointments + w.String() + " ORDER BY starts_at, id LIMIT ?"
+	rows, err := r.db.QueryContext(ctx, stmt, append(w.args, query.Limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("could not list appointments: %w", err)
+	}
+	defer rows.Close()
+
+	var appointments []*estate.Appointment
+	for rows.Next() {
+		a, err := scanAppointment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("could not scan appointment: %w", err)
+		}
+		appointments = append(appointments, a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating appointments: %w", err)
+	}
+
+	return appointments, nil
+}
+
+// appointmentConflictError explains a write that conflicted with another
+// appointment.
+func (r *AppointmentRepo) appointmentConflictError(ctx context.Context, a *estate.Appointment, buffers estate.AppointmentBuffers) error {
+	other, err := scanAppointment(r.db.QueryRowContext(ctx, QueryFindConflictingAppointment, appointmentConflictArgs(a, buffers)...))
+	if errors.Is(err, sql.ErrNoRows) {
+		// The conflicting appointment moved away since the write
+		return fmt.Errorf("appointment %s: %w", a.ID, estate.ErrRevisionConflict)
+	}
+	if err != nil {
+		return fmt.Errorf("could not check appointment conflicts: %w", err)
+	}
+	return buffers.ConflictError(a, other)
+}
+
+// appointmentArgs returns the appointment columns from agent_id to notes.
+func appointmentArgs(a *estate.Appointment) []any {
+	return []any{
+		a.AgentID, a.Client.Name, a.Client.Email, a.Client.Phone,
+		a.StartsAt.UTC(), a.EndsAt.UTC(), a.Status, a.Notes,
+	}
+}
+
+// appointmentConflictArgs returns the arguments of appointmentConflict.
+func appointmentConflictArgs(a *estate.Appointment, buffers estate.AppointmentBuffers) []any {
+	agentFrom, agentTo := buffers.AgentWindow(a)
+	propertyFrom, propertyTo := buffers.PropertyWindow(a)
+	return []any{
+		a.Blocks(), a.ID.String(),
+		a.AgentID, agentTo.UTC(), agentFrom.UTC(),
+		a.PropertyID.String(), propertyTo.UTC(), propertyFrom.UTC(),
+	}
+}
+
+func scanAppointment(row rowScanner) (*estate.Appointment, error) {
+	var (
+		a              estate.Appointment
+		id, propertyID string
+	)
+	err := row.Scan(&id, &propertyID, &a.AgentID, &a.Client.Name, &a.Client.Email, &a.Client.Phone, &a.StartsAt, &a.EndsAt,
+		&a.Status, &a.Notes, &a.Revision, &a.CreatedAt, &a.CreatedBy, &a.UpdatedAt, &a.UpdatedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	if a.ID, err = uuid.Parse(id); err != nil {
+		return nil, fmt.Errorf("invalid appointment ID %q: %w", id, err)
+	}
+	if a.PropertyID, err = uuid.Parse(propertyID); err != nil {
+		return nil, fmt.Errorf("invalid appointment property ID %q: %w", propertyID, err)
+	}
+	return &a, nil
+}
@@ -0,0 +1,35 @@
+package sqlite
+
+const (
+	// developmentColumns lists the developments columns in scan order.
+	developmentColumns = `id, kind, name, description, developer, stage, delivery_date, location, amenities, year_built,
+		owner_id, team_id, revision, created_at, created_by, updated_at, updated_by`
+
+	// QueryCreateDevelopment inserts a development; city and country are copied from its location.
+	QueryCreateDevelopment = `INSERT INTO developments (` + developmentColumns + `, city, country) VALUES (
+		?, ?, ?, ?, ?, ?, ?, ?, ?, ?,
+		?, ?, ?, ?, ?, ?, ?,
+		?, ?)`
+
+	// QueryGetDevelopment retrieves a development.
+	QueryGetDevelopment = `SELECT ` + developmentColumns + ` FROM developments WHERE id = ?`
+
+	// QueryUpdateDevelopment updates every mutable column of a development and
+	// increments its revision, if the revision still matches.
+	QueryUpdateDevelopment = `UPDATE developments SET kind = ?, name = ?, description = ?, developer = ?, stage = ?, delivery_date = ?,
+		location = ?, amenities = ?, year_built = ?, owner_id = ?, team_id = ?, updated_at = ?, updated_by = ?,
+		city = ?, country = ?, revision = revision + 1
+		WHERE id = ? AND revision = ?`
+
+	// QueryDeleteDevelopment deletes a development.
+	QueryDeleteDevelopment = `DELETE FROM developments WHERE id = ?`
+
+	// QueryDeleteDevelopmentRevision deletes a development if the revision still matches.
+	QueryDeleteDevelopmentRevision = `DELETE FROM developments WHERE id = ? AND revision = ?`
+
+	// QueryDevelopmentExists checks whether a development row exists.
+	QueryDevelopmentExists = `SELECT 1 FROM developments WHERE id = ?`
+
+	// QueryListDevelopments selects developments; the WHERE, ORDER BY and LIMIT clauses are appended.
+	QueryListDevelopments = `SELECT ` + developmentColumns + ` FROM developments`
+)
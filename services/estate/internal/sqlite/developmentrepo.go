@@ -0,0 +1,211 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/services/estate/internal/config"
+	"github.com/pulap/pulap/services/estate/internal/estate"
+)
+
+// DevelopmentRepo implements the estate.DevelopmentRepo interface using
+// SQLite. Developments are stored in the database of the property
+// repository, which must be started first.
+type DevelopmentRepo struct {
+	properties *PropertyRepo
+	db         *sql.DB
+	xparams    config.XParams
+}
+
+// NewDevelopmentRepo creates a new SQLite repository for Development
+// aggregates stored alongside properties.
+func NewDevelopmentRepo(properties *PropertyRepo, xparams config.XParams) *DevelopmentRepo {
+	return &DevelopmentRepo{
+		properties: properties,
+		xparams:    xparams,
+	}
+}
+
+// Start takes the database connection of the property repository, whose
+// migrations create the developments table.
+func (r *DevelopmentRepo) Start(ctx context.Context) error {
+	if r.properties.db == nil {
+		return fmt.Errorf("property repository not started")
+	}
+	r.db = r.properties.db
+	return nil
+}
+
+// CreateDevelopment stores a new development.
+func (r *DevelopmentRepo) CreateDevelopment(ctx context.Context, d *estate.Development) error {
+	d.BeforeCreate()
+
+	location, amenities, err := encodeDevelopment(d)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx, QueryCreateDevelopment,
+		d.ID.String(), d.Kind, d.Name, d.Description, d.Developer, d.Stage, d.DeliveryDate, location, amenities, d.YearBuilt,
+		d.OwnerID, d.TeamID, d.Revision, d.CreatedAt.UTC(), d.CreatedBy, d.UpdatedAt.UTC(), d.UpdatedBy,
+		d.Location.Address.City, d.Location.Address.Country)
+	if err != nil {
+		return fmt.Errorf("could not create development: %w", err)
+	}
+	return nil
+}
+
+// GetDevelopment retrieves a development.
+func (r *DevelopmentRepo) GetDevelopment(ctx context.Context, id uuid.UUID) (*estate.Development, error) {
+	d, err := scanDevelopment(r.db.QueryRowContext(ctx, QueryGetDevelopment, id.String()))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("development %s: %w", id, estate.ErrDevelopmentNotFound)
+	}
+	return d, err
+}
+
+// SaveDevelopment updates a development if its revision still matches and
+// increments the revision.
+func (r *DevelopmentRepo) SaveDevelopment(ctx context.Context, d *estate.Development) error {
+	d.BeforeUpdate()
+
+	location, amenities, err := encodeDevelopment(d)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(ctx, QueryUpdateDevelopment,
+		d.Kind, d.Name, d.Description, d.Developer, d.Stage, d.DeliveryDate,
+		location, amenities, d.YearBuilt, d.OwnerID, d.TeamID, d.UpdatedAt.UTC(), d.UpdatedBy,
+		d.Location.Address.City, d.Location.Address.Country,
+		d.ID.String(), d.Revision)
+	if err != nil {
+		return fmt.Errorf("could not save development: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return r.developmentRevisionError(ctx, d.ID)
+	}
+
+	d.Revision++
+	return nil
+}
+
+// DeleteDevelopment removes a development. A non-zero revision must match
+// the stored one.
+func (r *DevelopmentRepo) DeleteDevelopment(ctx context.Context, id uuid.UUID, revision int64) error {
+	query, args := QueryDeleteDevelopment, []any{id.String()}
+	if revision != 0 {
+		query, args = QueryDeleteDevelopmentRevision, append(args, revision)
+	}
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("could not delete development: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return r.developmentRevisionError(ctx, id)
+	}
+	return nil
+}
+
+// ListDevelopments lists the developments matching the query by name.
+func (r *DevelopmentRepo) ListDevelopments(ctx context.Context, query estate.DevelopmentQuery) ([]*estate.Development, error) {
+	w := &whereBuilder{}
+	if query.Kind != "" {
+		w.add("kind = ?", query.Kind)
+	}
+	if query.City != "" {
+		w.add("city = ? COLLATE "+collationNoCaseUnicode, query.City)
+	}
+	if query.Country != "" {
+		w.add("country = ? COLLATE "+collationNoCaseUnicode, query.Country)
+	}
+	if query.Scoped {
+		if len(query.Scopes) == 0 {
+			w.add("0")
+		} else {
+			in := placeholders(len(query.Scopes))
+			args := stringArgs(query.Scopes)
+			w.add("(owner_id IN "+in+" OR team_id IN "+in+")", append(args, args...)...)
+		}
+	}
+
+	stmt := QueryListDevelopments + w.String() + " ORDER BY name, id LIMIT ?"
+	rows, err := r.db.QueryContext(ctx, stmt, append(w.args, query.Limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("could not list developments: %w", err)
+	}
+	defer rows.Close()
+
+	var developments []*estate.Development
+	for rows.Next() {
+		d, err := scanDevelopment(rows)
+		if err != nil {
+			return nil, err
+		}
+		developments = append(developments, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating developments: %w", err)
+	}
+
+	return developments, nil
+}
+
+// developmentRevisionError explains why a conditional write matched no
+// row: the development is either missing or at another revision.
+func (r *DevelopmentRepo) developmentRevisionError(ctx context.Context, id uuid.UUID) error {
+	var exists int
+	err := r.db.QueryRowContext(ctx, QueryDevelopmentExists, id.String()).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("development %s: %w", id, estate.ErrDevelopmentNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("could not check development: %w", err)
+	}
+	return fmt.Errorf("development %s: %w", id, estate.ErrRevisionConflict)
+}
+
+func encodeDevelopment(d *estate.Development) (string, string, error) {
+	location, err := json.Marshal(d.Location)
+	if err != nil {
+		return "", "", fmt.Errorf("cannot encode development location: %w", err)
+	}
+	amenities, err := json.Marshal(d.Amenities)
+	if err != nil {
+		return "", "", fmt.Errorf("cannot encode development amenities: %w", err)
+	}
+	return string(location), string(amenities), nil
+}
+
+func scanDevelopment(row rowScanner) (*estate.Development, error) {
+	var (
+		d                       estate.Development
+		id, location, amenities string
+	)
+	err := row.Scan(&id, &d.Kind, &d.Name, &d.Description, &d.Developer, &d.Stage, &d.DeliveryDate, &location, &amenities, &d.YearBuilt,
+		&d.OwnerID, &d.TeamID, &d.Revision, &d.CreatedAt, &d.CreatedBy, &d.UpdatedAt, &d.UpdatedBy)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("could not scan development: %w", err)
+	}
+
+	if d.ID, err = uuid.Parse(id); err != nil {
+		return nil, fmt.Errorf("invalid development ID %q: %w", id, err)
+	}
+	if err := json.Unmarshal([]byte(location), &d.Location); err != nil {
+		return nil, fmt.Errorf("cannot decode development location: %w", err)
+	}
+	if err := json.Unmarshal([]byte(amenities), &d.Amenities); err != nil {
+		return nil, fmt.Errorf("cannot decode development amenities: %w", err)
+	}
+	return &d, nil
+}
@@ -0,0 +1,20 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pulap/pulap/services/estate/internal/estate"
+	"github.com/pulap/pulap/services/estate/internal/estate/repotest"
+)
+
+func TestDevelopmentRepo(t *testing.T) {
+	repotest.RunDevelopmentRepo(t, func(t *testing.T) estate.DevelopmentRepo {
+		properties := newTestRepo(t)
+		repo := NewDevelopmentRepo(properties, properties.xparams)
+		if err := repo.Start(context.Background()); err != nil {
+			t.Fatalf("Start: %v", err)
+		}
+		return repo
+	})
+}
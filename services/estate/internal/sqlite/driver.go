@@ -0,0 +1,25 @@
+package sqlite
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// driverName is the sqlite3 driver with the estate collations registered.
+const driverName = "sqlite3_estate"
+
+// collationNoCaseUnicode compares text case-insensitively beyond ASCII
+// (the built-in NOCASE only folds A-Z), so "Kraków" matches "KRAKÓW".
+const collationNoCaseUnicode = "NOCASE_UNICODE"
+
+func init() {
+	sql.Register(driverName, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			return conn.RegisterCollation(collationNoCaseUnicode, func(a, b string) int {
+				return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+			})
+		},
+	})
+}
@@ -0,0 +1,116 @@
+package sqlite
+
+import (
+	"math"
+
+	"github.com/pulap/pulap/services/estate/internal/estate"
+)
+
+const (
+	geohashAlphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+	// geohashPrecision is the length of stored geohashes (~4.8 m cells).
+	geohashPrecision = 9
+
+	// maxGeohashCells bounds the number of prefix ranges used to cover a search area.
+	maxGeohashCells = 32
+)
+
+// encodeGeohash returns the geohash of a point with the given precision.
+func encodeGeohash(lat, lng float64, precision int) string {
+	latRange := [2]float64{-90, 90}
+	lngRange := [2]float64{-180, 180}
+
+	hash := make([]byte, 0, precision)
+	bit, ch, even := 0, 0, true
+
+	for len(hash) < precision {
+		if even {
+			mid := (lngRange[0] + lngRange[1]) / 2
+			if lng >= mid {
+				ch |= 1 << (4 - bit)
+				lngRange[0] = mid
+			} else {
+				lngRange[1] = mid
+			}
+		} else {
+			mid := (latRange[0] + latRange[1]) / 2
+			if lat >= mid {
+				ch |= 1 << (4 - bit)
+				latRange[0] = mid
+			} else {
+				latRange[1] = mid
+			}
+		}
+		even = !even
+
+		if bit < 4 {
+			bit++
+		} else {
+			hash = append(hash, geohashAlphabet[ch])
+			bit, ch = 0, 0
+		}
+	}
+
+	return string(hash)
+}
+
+// propertyGeohash returns the stored geohash for a property, empty when it has no coordinates.
+func propertyGeohash(p *estate.Property) string {
+	point, ok := estate.PointOf(p)
+	if !ok {
+		return ""
+	}
+	return encodeGeohash(point.Lat, point.Lng, geohashPrecision)
+}
+
+// geohashCellSize returns the height and width in degrees of a cell at the given precision.
+func geohashCellSize(precision int) (latDeg, lngDeg float64) {
+	bits := 5 * precision
+	lngBits := (bits + 1) / 2
+	latBits := bits / 2
+	return 180 / math.Exp2(float64(latBits)), 360 / math.Exp2(float64(lngBits))
+}
+
+// geohashCover returns the geohash prefixes of the cells intersecting the box,
+// using the longest prefix that keeps the cover within maxGeohashCells.
+func geohashCover(b estate.BoundingBox) []string {
+	for precision := geohashPrecision; precision > 1; precision-- {
+		latDeg, lngDeg := geohashCellSize(precision)
+		rows := int(math.Floor(b.North/latDeg)-math.Floor(b.South/latDeg)) + 1
+		cols := int(math.Floor(b.East/lngDeg)-math.Floor(b.West/lngDeg)) + 1
+		if rows*cols <= maxGeohashCells {
+			return coverCells(b, precision, latDeg, lngDeg)
+		}
+	}
+	return coverCells(b, 1, 45, 45)
+}
+
+func coverCells(b estate.BoundingBox, precision int, latDeg, lngDeg float64) []string {
+	seen := map[string]bool{}
+	var cells []string
+
+	add := func(lat, lng float64) {
+		h := encodeGeohash(math.Min(lat, 90), math.Min(lng, 180), precision)
+		if !seen[h] {
+			seen[h] = true
+			cells = append(cells, h)
+		}
+	}
+
+	for lat := b.South; ; lat += latDeg {
+		lat = math.Min(lat, b.North)
+		for lng := b.West; ; lng += lngDeg {
+			lng = math.Min(lng, b.East)
+			add(lat, lng)
+			if lng >= b.East {
+				break
+			}
+		}
+		if lat >= b.North {
+			break
+		}
+	}
+
+	return cells
+}
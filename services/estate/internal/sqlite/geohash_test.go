@@ -0,0 +1,64 @@
+package sqlite
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pulap/pulap/services/estate/internal/estate"
+)
+
+func TestEncodeGeohash(t *testing.T) {
+	tests := []struct {
+		lat, lng  float64
+		precision int
+		want      string
+	}{
+		{57.64911, 10.40744, 11, "u4pruydqqvj"},
+		{42.6, -5.6, 5, "ezs42"},
+		{-33.8688, 151.2093, 6, "r3gx2f"},
+	}
+
+	for _, tt := range tests {
+		if got := encodeGeohash(tt.lat, tt.lng, tt.precision); got != tt.want {
+			t.Errorf("encodeGeohash(%v, %v, %d) = %s, want %s", tt.lat, tt.lng, tt.precision, got, tt.want)
+		}
+	}
+}
+
+func TestGeohashCover(t *testing.T) {
+	tests := []struct {
+		name string
+		box  estate.BoundingBox
+	}{
+		{"neighbourhood", estate.BoundingBox{South: 40.41, West: -3.72, North: 40.42, East: -3.68}},
+		{"city", estate.BoundingBox{South: 40.3, West: -3.9, North: 40.6, East: -3.5}},
+		{"country", estate.BoundingBox{South: 36, West: -9.5, North: 43.8, East: 3.3}},
+		{"point", estate.BoundingBox{South: 40.4169, West: -3.7035, North: 40.4169, East: -3.7035}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cells := geohashCover(tt.box)
+			if len(cells) == 0 || len(cells) > maxGeohashCells {
+				t.Fatalf("expected 1..%d cells, got %d", maxGeohashCells, len(cells))
+			}
+
+			// Every point of the box must fall inside one of the cells.
+			for _, lat := range []float64{tt.box.South, (tt.box.South + tt.box.North) / 2, tt.box.North} {
+				for _, lng := range []float64{tt.box.West, (tt.box.West + tt.box.East) / 2, tt.box.East} {
+					hash := encodeGeohash(lat, lng, geohashPrecision)
+					covered := false
+					for _, cell := range cells {
+						if strings.HasPrefix(hash, cell) {
+							covered = true
+							break
+						}
+					}
+					if !covered {
+						t.Errorf("point %v,%v (%s) not covered by %v", lat, lng, hash, cells)
+					}
+				}
+			}
+		})
+	}
+}
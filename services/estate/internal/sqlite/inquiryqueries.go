@@ -0,0 +1,44 @@
+package sqlite
+
+const (
+	// inquiryColumns lists the inquiries columns in scan order.
+	inquiryColumns = `id, property_id, contact_ct, contact_iv, contact_tag, contact_lookup, message, source,
+		agent_id, stage, outcome, closed_at, revision, created_at, created_by, updated_at, updated_by`
+
+	// QueryCreateInquiry inserts an inquiry.
+	QueryCreateInquiry = `INSERT INTO inquiries (` + inquiryColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	// QueryGetInquiry retrieves an inquiry.
+	QueryGetInquiry = `SELECT ` + inquiryColumns + ` FROM inquiries WHERE id = ?`
+
+	// QueryUpdateInquiry updates every mutable column of an inquiry and
+	// increments its revision, if the revision still matches.
+	QueryUpdateInquiry = `UPDATE inquiries SET contact_ct = ?, contact_iv = ?, contact_tag = ?, contact_lookup = ?,
+		message = ?, source = ?, agent_id = ?, stage = ?, outcome = ?, closed_at = ?,
+		updated_at = ?, updated_by = ?, revision = revision + 1
+		WHERE id = ? AND revision = ?`
+
+	// QueryGetInquiryRevision reads the revision of an inquiry.
+	QueryGetInquiryRevision = `SELECT revision FROM inquiries WHERE id = ?`
+
+	// QueryListInquiries selects inquiries; the WHERE, ORDER BY and LIMIT clauses are appended.
+	QueryListInquiries = `SELECT ` + inquiryColumns + ` FROM inquiries`
+
+	// QueryHasInquirySince checks for an inquiry of a contact about a
+	// property since a time.
+	QueryHasInquirySince = `SELECT EXISTS (SELECT 1 FROM inquiries WHERE contact_lookup = ? AND property_id = ? AND created_at >= ?)`
+
+	// QueryCountInquiries counts the inquiries created since a time by
+	// property, agent, stage and outcome.
+	QueryCountInquiries = `SELECT property_id, agent_id, stage, outcome, COUNT(*) FROM inquiries
+		WHERE created_at >= ? GROUP BY property_id, agent_id, stage, outcome`
+
+	// QueryCreateInquiryNote inserts a note if its inquiry exists.
+	QueryCreateInquiryNote = `INSERT INTO inquiry_notes (id, inquiry_id, body, created_at, created_by)
+		SELECT ?, ?, ?, ?, ? WHERE EXISTS (SELECT 1 FROM inquiries WHERE id = ?)`
+
+	// QueryListInquiryNotes lists the notes of an inquiry, oldest first.
+	QueryListInquiryNotes = `SELECT id, inquiry_id, body, created_at, created_by FROM inquiry_notes
+		WHERE inquiry_id = ? ORDER BY created_at, rowid`
+)
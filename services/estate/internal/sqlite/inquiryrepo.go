@@ -0,0 +1,255 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/services/estate/internal/config"
+	"github.com/pulap/pulap/services/estate/internal/estate"
+)
+
+// InquiryRepo implements the estate.InquiryRepo interface using SQLite.
+// Inquiries are stored in the database of the property repository, which
+// must be started first.
+type InquiryRepo struct {
+	properties *PropertyRepo
+	db         *sql.DB
+	xparams    config.XParams
+}
+
+// NewInquiryRepo creates a new SQLite repository for Inquiry aggregates
+// stored alongside properties.
+func NewInquiryRepo(properties *PropertyRepo, xparams config.XParams) *InquiryRepo {
+	return &InquiryRepo{
+		properties: properties,
+		xparams:    xparams,
+	}
+}
+
+// Start takes the database connection of the property repository, whose
+// migrations create the inquiries tables.
+func (r *InquiryRepo) Start(ctx context.Context) error {
+	if r.properties.db == nil {
+		return fmt.Errorf("property repository not started")
+	}
+	r.db = r.properties.db
+	return nil
+}
+
+// CreateInquiry stores a new inquiry.
+func (r *InquiryRepo) CreateInquiry(ctx context.Context, i *estate.Inquiry) error {
+	i.BeforeCreate()
+
+	args := []any{i.ID.String(), i.PropertyID.String()}
+	args = append(args, inquiryArgs(i)...)
+	args = append(args, i.Revision, i.CreatedAt.UTC(), i.CreatedBy, i.UpdatedAt.UTC(), i.UpdatedBy)
+	if _, err := r.db.ExecContext(ctx, QueryCreateInquiry, args...); err != nil {
+		return fmt.Errorf("could not create inquiry: %w", err)
+	}
+	return nil
+}
+
+// GetInquiry retrieves an inquiry.
+func (r *InquiryRepo) GetInquiry(ctx context.Context, id uuid.UUID) (*estate.Inquiry, error) {
+	i, err := scanInquiry(r.db.QueryRowContext(ctx, QueryGetInquiry, id.String()))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("inquiry %s: %w", id, estate.ErrInquiryNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not get inquiry: %w", err)
+	}
+	return i, nil
+}
+
+// SaveInquiry updates an inquiry if its revision still matches and
+// increments the revision.
+func (r *InquiryRepo) SaveInquiry(ctx context.Context, i *estate.Inquiry) error {
+	i.BeforeUpdate()
+
+	args := append(inquiryArgs(i), i.UpdatedAt.UTC(), i.UpdatedBy, i.ID.String(), i.Revision)
+	result, err := r.db.ExecContext(ctx, QueryUpdateInquiry, args...)
+	if err != nil {
+		return fmt.Errorf("could not save inquiry: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n > 0 {
+		i.Revision++
+		return nil
+	}
+
+	var revision int64
+	err = r.db.QueryRowContext(ctx, QueryGetInquiryRevision, i.ID.String()).Scan(&revision)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("inquiry %s: %w", i.ID, estate.ErrInquiryNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("could not check inquiry: %w", err)
+	}
+	return fmt.Errorf("inquiry %s: %w", i.ID, estate.ErrRevisionConflict)
+}
+
+// ListInquiries lists the inquiries matching the query, newest first.
+func (r *InquiryRepo) ListInquiries(ctx context.Context, query estate.InquiryQuery) ([]*estate.Inquiry, error) {
+	w := &whereBuilder{}
+	if len(query.PropertyIDs) > 0 {
+		w.add("property_id IN "+placeholders(len(query.PropertyIDs)), uuidArgs(query.PropertyIDs)...)
+	}
+	if len(query.AgentIDs) > 0 {
+		w.add("agent_id IN "+placeholders(len(query.AgentIDs)), stringArgs(query.AgentIDs)...)
+	}
+	if query.Unassigned {
+		w.add("agent_id = ''")
+	}
+	if len(query.Stages) > 0 {
+		w.add("stage IN "+placeholders(len(query.Stages)), stringArgs(query.Stages)...)
+	}
+	if len(query.Sources) > 0 {
+		w.add("source IN "+placeholders(len(query.Sources)), stringArgs(query.Sources)...)
+	}
+
+	stmt := QueryListInquiries + w.String() + " ORDER BY created_at DESC, id LIMIT ?"
+	rows, err := r.db.QueryContext(ctx, stmt, append(w.args, query.Limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("could not list inquiries: %w", err)
+	}
+	defer rows.Close()
+
+	var inquiries []*estate.Inquiry
+	for rows.Next() {
+		i, err := scanInquiry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("could not scan inquiry: %w", err)
+		}
+		inquiries = append(inquiries, i)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating inquiries: %w", err)
+	}
+
+	return inquiries, nil
+}
+
+// HasInquirySince returns true if the contact with the lookup hash inquired
+// about the property since a time.
+func (r *InquiryRepo) HasInquirySince(ctx context.Context, propertyID uuid.UUID, contactLookup []byte, since time.Time) (bool, error) {
+	var found bool
+	err := r.db.QueryRowContext(ctx, QueryHasInquirySince, contactLookup, propertyID.String(), since.UTC()).Scan(&found)
+	if err != nil {
+		return false, fmt.Errorf("could not check inquiries: %w", err)
+	}
+	return found, nil
+}
+
+// AddInquiryNote attaches a note to an inquiry.
+func (r *InquiryRepo) AddInquiryNote(ctx context.Context, n *estate.InquiryNote) error {
+	n.BeforeCreate()
+
+	result, err := r.db.ExecContext(ctx, QueryCreateInquiryNote,
+		n.ID.String(), n.InquiryID.String(), n.Body, n.CreatedAt.UTC(), n.CreatedBy, n.InquiryID.String())
+	if err != nil {
+		return fmt.Errorf("could not create inquiry note: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return fmt.Errorf("inquiry %s: %w", n.InquiryID, estate.ErrInquiryNotFound)
+	}
+	return nil
+}
+
+// ListInquiryNotes lists the notes of an inquiry, oldest first.
+func (r *InquiryRepo) ListInquiryNotes(ctx context.Context, inquiryID uuid.UUID) ([]*estate.InquiryNote, error) {
+	rows, err := r.db.QueryContext(ctx, QueryListInquiryNotes, inquiryID.String())
+	if err != nil {
+		return nil, fmt.Errorf("could not list inquiry notes: %w", err)
+	}
+	defer rows.Close()
+
+	var notes []*estate.InquiryNote
+	for rows.Next() {
+		var (
+			n             estate.InquiryNote
+			id, inquiryID string
+		)
+		if err := rows.Scan(&id, &inquiryID, &n.Body, &n.CreatedAt, &n.CreatedBy); err != nil {
+			return nil, fmt.Errorf("could not scan inquiry note: %w", err)
+		}
+		if n.ID, err = uuid.Parse(id); err != nil {
+			return nil, fmt.Errorf("invalid inquiry note ID %q: %w", id, err)
+		}
+		if n.InquiryID, err = uuid.Parse(inquiryID); err != nil {
+			return nil, fmt.Errorf("invalid inquiry ID %q: %w", inquiryID, err)
+		}
+		notes = append(notes, &n)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating inquiry notes: %w", err)
+	}
+
+	return notes, nil
+}
+
+// CountInquiries counts the inquiries created since a time by property,
+// agent, stage and outcome.
+func (r *InquiryRepo) CountInquiries(ctx context.Context, since time.Time) ([]estate.InquiryCount, error) {
+	rows, err := r.db.QueryContext(ctx, QueryCountInquiries, since.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("could not count inquiries: %w", err)
+	}
+	defer rows.Close()
+
+	var counts []estate.InquiryCount
+	for rows.Next() {
+		var (
+			c          estate.InquiryCount
+			propertyID string
+		)
+		if err := rows.Scan(&propertyID, &c.AgentID, &c.Stage, &c.Outcome, &c.Count); err != nil {
+			return nil, fmt.Errorf("could not scan inquiry count: %w", err)
+		}
+		if c.PropertyID, err = uuid.Parse(propertyID); err != nil {
+			return nil, fmt.Errorf("invalid inquiry property ID %q: %w", propertyID, err)
+		}
+		counts = append(counts, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating inquiry counts: %w", err)
+	}
+
+	return counts, nil
+}
+
+// inquiryArgs returns the inquiry columns from contact_ct to closed_at.
+func inquiryArgs(i *estate.Inquiry) []any {
+	return []any{
+		i.ContactCT, i.ContactIV, i.ContactTag, i.ContactLookup,
+		i.Message, i.Source, i.AgentID, i.Stage, i.Outcome, utcTime(i.ClosedAt),
+	}
+}
+
+func scanInquiry(row rowScanner) (*estate.Inquiry, error) {
+	var (
+		i              estate.Inquiry
+		id, propertyID string
+		closedAt       sql.NullTime
+	)
+	err := row.Scan(&id, &propertyID, &i.ContactCT, &i.ContactIV, &i.ContactTag, &i.ContactLookup, &i.Message, &i.Source,
+		&i.AgentID, &i.Stage, &i.Outcome, &closedAt, &i.Revision, &i.CreatedAt, &i.CreatedBy, &i.UpdatedAt, &i.UpdatedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	if i.ID, err = uuid.Parse(id); err != nil {
+		return nil, fmt.Errorf("invalid inquiry ID %q: %w", id, err)
+	}
+	if i.PropertyID, err = uuid.Parse(propertyID); err != nil {
+		return nil, fmt.Errorf("invalid inquiry property ID %q: %w", propertyID, err)
+	}
+	i.ClosedAt = nullTime(closedAt)
+	return &i, nil
+}
@@ -0,0 +1,20 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pulap/pulap/services/estate/internal/estate"
+	"github.com/pulap/pulap/services/estate/internal/estate/repotest"
+)
+
+func TestInquiryRepo(t *testing.T) {
+	repotest.RunInquiryRepo(t, func(t *testing.T) (estate.Repo, estate.InquiryRepo) {
+		properties := newTestRepo(t)
+		repo := NewInquiryRepo(properties, properties.xparams)
+		if err := repo.Start(context.Background()); err != nil {
+			t.Fatalf("Start: %v", err)
+		}
+		return properties, repo
+	})
+}
@@ -0,0 +1,42 @@
+package sqlite
+
+const (
+	// leaseColumns lists the leases columns in scan order.
+	leaseColumns = `id, property_id, tenant_name, tenant_email, tenant_phone, start_date, end_date, last_day,
+		rent, currency, frequency, deposit, indexation, renewal, status, terminated_on, termination_reason,
+		revision, created_at, created_by, updated_at, updated_by`
+
+	// leaseOverlap matches the leases of a property running on a day
+	// between a first and a last day, both included. Void leases, ending
+	// before they start, match nothing.
+	leaseOverlap = `property_id = ? AND last_day >= start_date AND start_date <= ? AND last_day >= ?`
+
+	// QueryCreateLease inserts a lease unless it overlaps another of its
+	// property, taking the overlap arguments after the columns.
+	QueryCreateLease = `INSERT INTO leases (` + leaseColumns + `)
+		SELECT ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+		WHERE NOT EXISTS (SELECT 1 FROM leases WHERE ` + leaseOverlap + `)`
+
+	// QueryFindOverlappingLease finds a lease overlapping the given days.
+	QueryFindOverlappingLease = `SELECT id FROM leases WHERE ` + leaseOverlap + ` ORDER BY start_date LIMIT 1`
+
+	// QueryGetLease retrieves a lease.
+	QueryGetLease = `SELECT ` + leaseColumns + ` FROM leases WHERE id = ?`
+
+	// QueryUpdateLease updates every mutable column of a lease and
+	// increments its revision, if the revision still matches.
+	QueryUpdateLease = `UPDATE leases SET tenant_name = ?, tenant_email = ?, tenant_phone = ?, start_date = ?, end_date = ?, last_day = ?,
+		rent = ?, currency = ?, frequency = ?, deposit = ?, indexation = ?, renewal = ?, status = ?, terminated_on = ?, termination_reason = ?,
+		updated_at = ?, updated_by = ?, revision = revision + 1
+		WHERE id = ? AND revision = ?`
+
+	// QueryLeaseExists checks whether a lease row exists.
+	QueryLeaseExists = `SELECT 1 FROM leases WHERE id = ?`
+
+	// QueryListLeases selects leases; the WHERE, ORDER BY and LIMIT clauses are appended.
+	QueryListLeases = `SELECT ` + leaseColumns + ` FROM leases`
+
+	// QueryListExpiringLeases lists the active leases ending between two days, soonest first.
+	QueryListExpiringLeases = `SELECT ` + leaseColumns + ` FROM leases
+		WHERE status = 'active' AND end_date BETWEEN ? AND ? ORDER BY end_date, id LIMIT ?`
+)
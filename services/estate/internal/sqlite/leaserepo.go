@@ -0,0 +1,210 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/services/estate/internal/config"
+	"github.com/pulap/pulap/services/estate/internal/estate"
+)
+
+// LeaseRepo implements the estate.LeaseRepo interface using SQLite.
+// Leases are stored in the database of the property repository, which
+// must be started first.
+type LeaseRepo struct {
+	properties *PropertyRepo
+	db         *sql.DB
+	xparams    config.XParams
+}
+
+// NewLeaseRepo creates a new SQLite repository for Lease aggregates
+// stored alongside properties.
+func NewLeaseRepo(properties *PropertyRepo, xparams config.XParams) *LeaseRepo {
+	return &LeaseRepo{
+		properties: properties,
+		xparams:    xparams,
+	}
+}
+
+// Start takes the database connection of the property repository, whose
+// migrations create the leases tables.
+func (r *LeaseRepo) Start(ctx context.Context) error {
+	if r.properties.db == nil {
+		return fmt.Errorf("property repository not started")
+	}
+	r.db = r.properties.db
+	return nil
+}
+
+// CreateLease stores a new lease unless it overlaps another lease of the
+// property. The check and the insert are a single statement.
+func (r *LeaseRepo) CreateLease(ctx context.Context, l *estate.Lease) error {
+	l.BeforeCreate()
+
+	args, err := leaseArgs(l)
+	if err != nil {
+		return err
+	}
+
+	overlap := []any{l.PropertyID.String(), l.LastDay(), l.StartDate}
+	columns := append([]any{l.ID.String(), l.PropertyID.String()}, args...)
+	columns = append(columns, l.Revision, l.CreatedAt.UTC(), l.CreatedBy, l.UpdatedAt.UTC(), l.UpdatedBy)
+	result, err := r.db.ExecContext(ctx, QueryCreateLease, append(columns, overlap...)...)
+	if err != nil {
+		return fmt.Errorf("could not create lease: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n > 0 {
+		return nil
+	}
+
+	var other string
+	err = r.db.QueryRowContext(ctx, QueryFindOverlappingLease, overlap...).Scan(&other)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("could not check lease overlap: %w", err)
+	}
+	return fmt.Errorf("%w: lease %s", estate.ErrLeaseOverlap, other)
+}
+
+// GetLease retrieves a lease.
+func (r *LeaseRepo) GetLease(ctx context.Context, id uuid.UUID) (*estate.Lease, error) {
+	l, err := scanLease(r.db.QueryRowContext(ctx, QueryGetLease, id.String()))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("lease %s: %w", id, estate.ErrLeaseNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not get lease: %w", err)
+	}
+	return l, nil
+}
+
+// SaveLease updates a lease if its revision still matches and increments
+// the revision.
+func (r *LeaseRepo) SaveLease(ctx context.Context, l *estate.Lease) error {
+	l.BeforeUpdate()
+
+	args, err := leaseArgs(l)
+	if err != nil {
+		return err
+	}
+
+	args = append(args, l.UpdatedAt.UTC(), l.UpdatedBy, l.ID.String(), l.Revision)
+	result, err := r.db.ExecContext(ctx, QueryUpdateLease, args...)
+	if err != nil {
+		return fmt.Errorf("could not save lease: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return r.leaseRevisionError(ctx, l.ID)
+	}
+
+	l.Revision++
+	return nil
+}
+
+// ListLeases lists the leases matching the query, latest start first.
+func (r *LeaseRepo) ListLeases(ctx context.Context, query estate.LeaseQuery) ([]*estate.Lease, error) {
+	w := &whereBuilder{}
+	if len(query.PropertyIDs) > 0 {
+		w.add("property_id IN "+placeholders(len(query.PropertyIDs)), uuidArgs(query.PropertyIDs)...)
+	}
+	if len(query.Statuses) > 0 {
+		w.add("status IN "+placeholders(len(query.Statuses)), stringArgs(query.Statuses)...)
+	}
+
+	stmt := QueryListLeases + w.String() + " ORDER BY start_date DESC, id LIMIT ?"
+	return r.queryLeases(ctx, stmt, append(w.args, query.Limit)...)
+}
+
+// ListExpiringLeases lists the active leases ending between two days,
+// soonest first.
+func (r *LeaseRepo) ListExpiringLeases(ctx context.Context, from, to string, limit int) ([]*estate.Lease, error) {
+	return r.queryLeases(ctx, QueryListExpiringLeases, from, to, limit)
+}
+
+func (r *LeaseRepo) queryLeases(ctx context.Context, stmt string, args ...any) ([]*estate.Lease, error) {
+	rows, err := r.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, fmt.Errorf("could not list leases: %w", err)
+	}
+	defer rows.Close()
+
+	var leases []*estate.Lease
+	for rows.Next() {
+		l, err := scanLease(rows)
+		if err != nil {
+			return nil, fmt.Errorf("could not scan lease: %w", err)
+		}
+		leases = append(leases, l)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating leases: %w", err)
+	}
+
+	return leases, nil
+}
+
+// leaseRevisionError explains why a conditional write matched no row: the
+// lease is either missing or at another revision.
+func (r *LeaseRepo) leaseRevisionError(ctx context.Context, id uuid.UUID) error {
+	var exists int
+	err := r.db.QueryRowContext(ctx, QueryLeaseExists, id.String()).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("lease %s: %w", id, estate.ErrLeaseNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("could not check lease: %w", err)
+	}
+	return fmt.Errorf("lease %s: %w", id, estate.ErrRevisionConflict)
+}
+
+// leaseArgs returns the mutable lease columns, from tenant_name to
+// termination_reason.
+func leaseArgs(l *estate.Lease) ([]any, error) {
+	indexation, err := json.Marshal(l.Indexation)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode lease indexation: %w", err)
+	}
+	renewal, err := json.Marshal(l.Renewal)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode lease renewal: %w", err)
+	}
+	return []any{
+		l.Tenant.Name, l.Tenant.Email, l.Tenant.Phone, l.StartDate, l.EndDate, l.LastDay(),
+		l.Rent, l.Currency, l.Frequency, l.Deposit, string(indexation), string(renewal),
+		l.Status, l.TerminatedOn, l.TerminationReason,
+	}, nil
+}
+
+func scanLease(row rowScanner) (*estate.Lease, error) {
+	var (
+		l                   estate.Lease
+		id, propertyID      string
+		lastDay             string
+		indexation, renewal string
+	)
+	err := row.Scan(&id, &propertyID, &l.Tenant.Name, &l.Tenant.Email, &l.Tenant.Phone, &l.StartDate, &l.EndDate, &lastDay,
+		&l.Rent, &l.Currency, &l.Frequency, &l.Deposit, &indexation, &renewal, &l.Status, &l.TerminatedOn, &l.TerminationReason,
+		&l.Revision, &l.CreatedAt, &l.CreatedBy, &l.UpdatedAt, &l.UpdatedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	if l.ID, err = uuid.Parse(id); err != nil {
+		return nil, fmt.Errorf("invalid lease ID %q: %w", id, err)
+	}
+	if l.PropertyID, err = uuid.Parse(propertyID); err != nil {
+		return nil, fmt.Errorf("invalid lease property ID %q: %w", propertyID, err)
+	}
+	if err := json.Unmarshal([]byte(indexation), &l.Indexation); err != nil {
+		return nil, fmt.Errorf("cannot decode lease indexation: %w", err)
+	}
+	if err := json.Unmarshal([]byte(renewal), &l.Renewal); err != nil {
+		return nil, fmt.Errorf("cannot decode lease renewal: %w", err)
+	}
+	return &l, nil
+}
@@ -0,0 +1,37 @@
+package sqlite
+
+const (
+	// listingColumns lists the listings columns in scan order.
+	listingColumns = `id, property_id, channel, price_amount, price_currency, price_type, price_negotiable, content, status,
+		starts_at, ends_at, ended_at, revision, created_at, created_by, updated_at, updated_by`
+
+	// QueryCreateListing inserts a listing.
+	QueryCreateListing = `INSERT INTO listings (` + listingColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	// QueryGetListing retrieves a listing.
+	QueryGetListing = `SELECT ` + listingColumns + ` FROM listings WHERE id = ?`
+
+	// QueryUpdateListing updates every mutable column of a listing and
+	// increments its revision, if the revision still matches.
+	QueryUpdateListing = `UPDATE listings SET channel = ?, price_amount = ?, price_currency = ?, price_type = ?, price_negotiable = ?,
+		content = ?, status = ?, starts_at = ?, ends_at = ?, ended_at = ?, updated_at = ?, updated_by = ?, revision = revision + 1
+		WHERE id = ? AND revision = ?`
+
+	// QueryDeleteListing deletes a listing; its price history cascades.
+	QueryDeleteListing = `DELETE FROM listings WHERE id = ?`
+
+	// QueryDeleteListingRevision deletes a listing if the revision still matches.
+	QueryDeleteListingRevision = `DELETE FROM listings WHERE id = ? AND revision = ?`
+
+	// QueryListingExists checks whether a listing row exists.
+	QueryListingExists = `SELECT 1 FROM listings WHERE id = ?`
+
+	// QueryListListings selects listings; the WHERE, ORDER BY and LIMIT clauses are appended.
+	QueryListListings = `SELECT ` + listingColumns + ` FROM listings`
+
+	// QueryCreateListingPrice appends an entry to the price history of a listing.
+	QueryCreateListingPrice = `INSERT INTO listing_prices (listing_id, amount, currency, type, negotiable, actor, at) VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+	// QueryListListingPrices lists the price history of a listing, oldest first.
+	QueryListListingPrices = `SELECT listing_id, amount, currency, type, negotiable, actor, at FROM listing_prices WHERE listing_id = ? ORDER BY at, rowid`
+)
@@ -0,0 +1,290 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/services/estate/internal/config"
+	"github.com/pulap/pulap/services/estate/internal/estate"
+)
+
+// ListingRepo implements the estate.ListingRepo interface using SQLite.
+// Listings are stored in the database of the property repository, which
+// must be started first.
+type ListingRepo struct {
+	properties *PropertyRepo
+	db         *sql.DB
+	xparams    config.XParams
+}
+
+// NewListingRepo creates a new SQLite repository for Listing aggregates
+// stored alongside properties.
+func NewListingRepo(properties *PropertyRepo, xparams config.XParams) *ListingRepo {
+	return &ListingRepo{
+		properties: properties,
+		xparams:    xparams,
+	}
+}
+
+// Start takes the database connection of the property repository, whose
+// migrations create the listings tables.
+func (r *ListingRepo) Start(ctx context.Context) error {
+	if r.properties.db == nil {
+		return fmt.Errorf("property repository not started")
+	}
+	r.db = r.properties.db
+	return nil
+}
+
+// CreateListing stores a new listing and the first entry of its price history.
+func (r *ListingRepo) CreateListing(ctx context.Context, l *estate.Listing) error {
+	l.BeforeCreate()
+
+	content, err := json.Marshal(l.Content)
+	if err != nil {
+		return fmt.Errorf("cannot encode listing content: %w", err)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, QueryCreateListing,
+		l.ID.String(), l.PropertyID.String(), l.Channel,
+		l.Price.Amount, l.Price.Currency, l.Price.Type, l.Price.Negotiable, string(content), l.Status,
+		utcTime(l.StartsAt), utcTime(l.EndsAt), utcTime(l.EndedAt),
+		l.Revision, l.CreatedAt.UTC(), l.CreatedBy, l.UpdatedAt.UTC(), l.UpdatedBy)
+	if err != nil {
+		return fmt.Errorf("could not create listing: %w", err)
+	}
+
+	if err := insertListingPrice(ctx, tx, l.ID, l.Price, l.CreatedBy, l.CreatedAt); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("could not commit transaction: %w", err)
+	}
+	return nil
+}
+
+// GetListing retrieves a listing.
+func (r *ListingRepo) GetListing(ctx context.Context, id uuid.UUID) (*estate.Listing, error) {
+	l, err := scanListing(r.db.QueryRowContext(ctx, QueryGetListing, id.String()))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("listing %s: %w", id, estate.ErrListingNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not get listing: %w", err)
+	}
+	return l, nil
+}
+
+// SaveListing updates a listing if its revision still matches, increments
+// the revision and records the price when it changed.
+func (r *ListingRepo) SaveListing(ctx context.Context, l *estate.Listing) error {
+	l.BeforeUpdate()
+
+	content, err := json.Marshal(l.Content)
+	if err != nil {
+		return fmt.Errorf("cannot encode listing content: %w", err)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	before, err := scanListing(tx.QueryRowContext(ctx, QueryGetListing, l.ID.String()))
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("listing %s: %w", l.ID, estate.ErrListingNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("could not get listing: %w", err)
+	}
+
+	result, err := tx.ExecContext(ctx, QueryUpdateListing,
+		l.Channel, l.Price.Amount, l.Price.Currency, l.Price.Type, l.Price.Negotiable, string(content), l.Status,
+		utcTime(l.StartsAt), utcTime(l.EndsAt), utcTime(l.EndedAt), l.UpdatedAt.UTC(), l.UpdatedBy,
+		l.ID.String(), l.Revision)
+	if err != nil {
+		return fmt.Errorf("could not save listing: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("listing %s: %w", l.ID, estate.ErrRevisionConflict)
+	}
+
+	if !samePrice(before.Price, l.Price) {
+		if err := insertListingPrice(ctx, tx, l.ID, l.Price, l.UpdatedBy, l.UpdatedAt); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("could not commit transaction: %w", err)
+	}
+
+	l.Revision++
+	return nil
+}
+
+// DeleteListing removes a listing and its price history. A non-zero
+// revision must match the stored one.
+func (r *ListingRepo) DeleteListing(ctx context.Context, id uuid.UUID, revision int64) error {
+	query, args := QueryDeleteListing, []any{id.String()}
+	if revision != 0 {
+		query, args = QueryDeleteListingRevision, append(args, revision)
+	}
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("could not delete listing: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n > 0 {
+		return nil
+	}
+
+	var exists int
+	err = r.db.QueryRowContext(ctx, QueryListingExists, id.String()).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("listing %s: %w", id, estate.ErrListingNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("could not check listing: %w", err)
+	}
+	return fmt.Errorf("listing %s: %w", id, estate.ErrRevisionConflict)
+}
+
+// ListListings lists the listings matching the query, newest first.
+func (r *ListingRepo) ListListings(ctx context.Context, query estate.ListingQuery) ([]*estate.Listing, error) {
+	w := &whereBuilder{}
+	if len(query.PropertyIDs) > 0 {
+		w.add("property_id IN "+placeholders(len(query.PropertyIDs)), uuidArgs(query.PropertyIDs)...)
+	}
+	if len(query.Channels) > 0 {
+		w.add("channel IN "+placeholders(len(query.Channels)), stringArgs(query.Channels)...)
+	}
+	if len(query.Statuses) > 0 {
+		w.add("status IN "+placeholders(len(query.Statuses)), stringArgs(query.Statuses)...)
+	}
+	if query.LiveAt != nil {
+		at := query.LiveAt.UTC()
+		w.add("status = ?", estate.ListingActive)
+		w.add("(starts_at IS NULL OR starts_at <= ?)", at)
+		w.add("(ends_at IS NULL OR ends_at > ?)", at)
+	}
+
+	stmt := QueryListListings + w.String() + " ORDER BY created_at DESC, id LIMIT ?"
+	rows, err := r.db.QueryContext(ctx, stmt, append(w.args, query.Limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("could not list listings: %w", err)
+	}
+	defer rows.Close()
+
+	var listings []*estate.Listing
+	for rows.Next() {
+		l, err := scanListing(rows)
+		if err != nil {
+			return nil, fmt.Errorf("could not scan listing: %w", err)
+		}
+		listings = append(listings, l)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating listings: %w", err)
+	}
+
+	return listings, nil
+}
+
+// ListListingPrices lists the price history of a listing, oldest first.
+func (r *ListingRepo) ListListingPrices(ctx context.Context, id uuid.UUID) ([]estate.ListingPrice, error) {
+	rows, err := r.db.QueryContext(ctx, QueryListListingPrices, id.String())
+	if err != nil {
+		return nil, fmt.Errorf("could not list listing prices: %w", err)
+	}
+	defer rows.Close()
+
+	var prices []estate.ListingPrice
+	for rows.Next() {
+		var (
+			p         estate.ListingPrice
+			listingID string
+		)
+		err := rows.Scan(&listingID, &p.Price.Amount, &p.Price.Currency, &p.Price.Type, &p.Price.Negotiable, &p.Actor, &p.At)
+		if err != nil {
+			return nil, fmt.Errorf("could not scan listing price: %w", err)
+		}
+		if p.ListingID, err = uuid.Parse(listingID); err != nil {
+			return nil, fmt.Errorf("invalid listing ID %q: %w", listingID, err)
+		}
+		prices = append(prices, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating listing prices: %w", err)
+	}
+
+	return prices, nil
+}
+
+func insertListingPrice(ctx context.Context, tx *sql.Tx, id uuid.UUID, price estate.Price, actor string, at time.Time) error {
+	_, err := tx.ExecContext(ctx, QueryCreateListingPrice,
+		id.String(), price.Amount, price.Currency, price.Type, price.Negotiable, actor, at.UTC())
+	if err != nil {
+		return fmt.Errorf("could not record listing price: %w", err)
+	}
+	return nil
+}
+
+// samePrice returns true if two prices are equal, whatever the scale of
+// their amounts.
+func samePrice(a, b estate.Price) bool {
+	return a.Amount.Equal(b.Amount) && a.Currency == b.Currency && a.Type == b.Type && a.Negotiable == b.Negotiable
+}
+
+func scanListing(row rowScanner) (*estate.Listing, error) {
+	var (
+		l                estate.Listing
+		id, propertyID   string
+		content          string
+		startsAt, endsAt sql.NullTime
+		endedAt          sql.NullTime
+	)
+	err := row.Scan(&id, &propertyID, &l.Channel,
+		&l.Price.Amount, &l.Price.Currency, &l.Price.Type, &l.Price.Negotiable, &content, &l.Status,
+		&startsAt, &endsAt, &endedAt, &l.Revision, &l.CreatedAt, &l.CreatedBy, &l.UpdatedAt, &l.UpdatedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	if l.ID, err = uuid.Parse(id); err != nil {
+		return nil, fmt.Errorf("invalid listing ID %q: %w", id, err)
+	}
+	if l.PropertyID, err = uuid.Parse(propertyID); err != nil {
+		return nil, fmt.Errorf("invalid listing property ID %q: %w", propertyID, err)
+	}
+	if err := json.Unmarshal([]byte(content), &l.Content); err != nil {
+		return nil, fmt.Errorf("cannot decode listing content: %w", err)
+	}
+	l.StartsAt = nullTime(startsAt)
+	l.EndsAt = nullTime(endsAt)
+	l.EndedAt = nullTime(endedAt)
+	return &l, nil
+}
+
+func nullTime(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
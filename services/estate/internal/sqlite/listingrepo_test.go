@@ -0,0 +1,20 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pulap/pulap/services/estate/internal/estate"
+	"github.com/pulap/pulap/services/estate/internal/estate/repotest"
+)
+
+func TestListingRepo(t *testing.T) {
+	repotest.RunListingRepo(t, func(t *testing.T) (estate.Repo, estate.ListingRepo) {
+		properties := newTestRepo(t)
+		repo := NewListingRepo(properties, properties.xparams)
+		if err := repo.Start(context.Background()); err != nil {
+			t.Fatalf("Start: %v", err)
+		}
+		return properties, repo
+	})
+}
@@ -0,0 +1,84 @@
+-- Property aggregate root. Classification, Location and Features are value
+-- objects flattened into the root row; Prices and Amenities are child tables.
+CREATE TABLE properties (
+	id              TEXT PRIMARY KEY,
+	name            TEXT NOT NULL DEFAULT '',
+	description     TEXT NOT NULL DEFAULT '',
+
+	category_id     TEXT NOT NULL DEFAULT '',
+	type_id         TEXT NOT NULL DEFAULT '',
+	subtype_id      TEXT NOT NULL DEFAULT '',
+
+	street          TEXT NOT NULL DEFAULT '',
+	number          TEXT NOT NULL DEFAULT '',
+	unit            TEXT NOT NULL DEFAULT '',
+	city            TEXT NOT NULL DEFAULT '',
+	state           TEXT NOT NULL DEFAULT '',
+	postal_code     TEXT NOT NULL DEFAULT '',
+	country         TEXT NOT NULL DEFAULT '',
+	latitude        REAL NOT NULL DEFAULT 0,
+	longitude       REAL NOT NULL DEFAULT 0,
+	region          TEXT NOT NULL DEFAULT '',
+	provider        TEXT NOT NULL DEFAULT '',
+	provider_url    TEXT NOT NULL DEFAULT '',
+	provider_ref    TEXT NOT NULL DEFAULT '',
+	location_raw    TEXT NOT NULL DEFAULT '',
+	display_name    TEXT NOT NULL DEFAULT '',
+
+	total_area      REAL NOT NULL DEFAULT 0,
+	covered_area    REAL NOT NULL DEFAULT 0,
+	land_area       REAL NOT NULL DEFAULT 0,
+	bedrooms        INTEGER NOT NULL DEFAULT 0,
+	bathrooms       INTEGER NOT NULL DEFAULT 0,
+	half_baths      INTEGER NOT NULL DEFAULT 0,
+	rooms           INTEGER NOT NULL DEFAULT 0,
+	parking         INTEGER NOT NULL DEFAULT 0,
+	covered_parking INTEGER NOT NULL DEFAULT 0,
+	floors          INTEGER NOT NULL DEFAULT 0,
+	floor           INTEGER NOT NULL DEFAULT 0,
+	year_built      INTEGER NOT NULL DEFAULT 0,
+	condition       TEXT NOT NULL DEFAULT '',
+	pool            BOOLEAN NOT NULL DEFAULT 0,
+	garden          BOOLEAN NOT NULL DEFAULT 0,
+	balcony         BOOLEAN NOT NULL DEFAULT 0,
+	terrace         BOOLEAN NOT NULL DEFAULT 0,
+	elevator        BOOLEAN NOT NULL DEFAULT 0,
+	air_conditioning BOOLEAN NOT NULL DEFAULT 0,
+	heating         BOOLEAN NOT NULL DEFAULT 0,
+	furnished       BOOLEAN NOT NULL DEFAULT 0,
+	pet_friendly    BOOLEAN NOT NULL DEFAULT 0,
+	storage         BOOLEAN NOT NULL DEFAULT 0,
+	laundry         BOOLEAN NOT NULL DEFAULT 0,
+	fireplace       BOOLEAN NOT NULL DEFAULT 0,
+
+	status          TEXT NOT NULL DEFAULT 'available',
+	owner_id        TEXT NOT NULL DEFAULT '',
+	schema_version  INTEGER NOT NULL DEFAULT 0,
+	created_at      DATETIME NOT NULL,
+	created_by      TEXT NOT NULL DEFAULT '',
+	updated_at      DATETIME NOT NULL,
+	updated_by      TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_properties_owner_id ON properties(owner_id);
+CREATE INDEX idx_properties_status ON properties(status);
+CREATE INDEX idx_properties_created_at ON properties(created_at);
+
+CREATE TABLE property_prices (
+	property_id TEXT NOT NULL REFERENCES properties(id) ON DELETE CASCADE,
+	position    INTEGER NOT NULL,
+	amount      REAL NOT NULL DEFAULT 0,
+	currency    TEXT NOT NULL DEFAULT '',
+	type        TEXT NOT NULL DEFAULT '',
+	negotiable  BOOLEAN NOT NULL DEFAULT 0,
+	PRIMARY KEY (property_id, position)
+);
+
+CREATE TABLE property_amenities (
+	property_id TEXT NOT NULL REFERENCES properties(id) ON DELETE CASCADE,
+	position    INTEGER NOT NULL,
+	amenity     TEXT NOT NULL,
+	PRIMARY KEY (property_id, position)
+);
+
+CREATE INDEX idx_property_amenities_amenity ON property_amenities(amenity);
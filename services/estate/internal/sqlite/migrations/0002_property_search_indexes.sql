@@ -0,0 +1,13 @@
+-- Indexes backing PropertyRepo.Search filters, sort orders and keyset pagination.
+-- NOCASE_UNICODE is registered by the estate sqlite driver (see driver.go).
+CREATE INDEX idx_properties_status_created_at ON properties(status, created_at, id);
+CREATE INDEX idx_properties_created_at_id ON properties(created_at, id);
+CREATE INDEX idx_properties_classification ON properties(category_id, type_id, subtype_id);
+CREATE INDEX idx_properties_country_city ON properties(country COLLATE NOCASE_UNICODE, city COLLATE NOCASE_UNICODE);
+CREATE INDEX idx_properties_bedrooms ON properties(bedrooms);
+CREATE INDEX idx_properties_bathrooms ON properties(bathrooms);
+CREATE INDEX idx_properties_total_area ON properties(total_area);
+CREATE INDEX idx_properties_year_built ON properties(year_built);
+
+CREATE INDEX idx_property_prices_lookup ON property_prices(type, currency, amount, property_id);
+CREATE INDEX idx_property_amenities_lookup ON property_amenities(amenity, property_id);
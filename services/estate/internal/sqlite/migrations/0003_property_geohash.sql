@@ -0,0 +1,5 @@
+-- Geohash of the property coordinates for geo queries; empty when the
+-- property has no coordinates. Existing rows are backfilled on start.
+ALTER TABLE properties ADD COLUMN geohash TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX idx_properties_geohash ON properties(geohash);
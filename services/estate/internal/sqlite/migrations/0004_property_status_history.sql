@@ -0,0 +1,13 @@
+-- Status transitions of each property, appended by the status state machine.
+CREATE TABLE property_status_history (
+	id          TEXT PRIMARY KEY,
+	property_id TEXT NOT NULL REFERENCES properties(id) ON DELETE CASCADE,
+	from_status TEXT NOT NULL,
+	to_status   TEXT NOT NULL,
+	reason      TEXT NOT NULL DEFAULT '',
+	actor       TEXT NOT NULL DEFAULT '',
+	override    BOOLEAN NOT NULL DEFAULT 0,
+	at          TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_property_status_history_property ON property_status_history(property_id, at);
@@ -0,0 +1,3 @@
+-- Revision of each property for optimistic concurrency; incremented on every
+-- write. Existing rows start at revision 1.
+ALTER TABLE properties ADD COLUMN revision INTEGER NOT NULL DEFAULT 1;
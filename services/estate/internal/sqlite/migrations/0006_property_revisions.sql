@@ -0,0 +1,23 @@
+-- Immutable history of every write to a property. Rows are kept when the
+-- property is deleted, so there is no foreign key to properties.
+CREATE TABLE property_revisions (
+	property_id   TEXT NOT NULL,
+	number        INTEGER NOT NULL,
+	action        TEXT NOT NULL,
+	actor         TEXT NOT NULL DEFAULT '',
+	at            TIMESTAMP NOT NULL,
+	restored_from INTEGER NOT NULL DEFAULT 0,
+	changes       TEXT NOT NULL DEFAULT '[]',
+	snapshot      TEXT NOT NULL,
+	PRIMARY KEY (property_id, number)
+);
+
+CREATE TRIGGER property_revisions_no_update BEFORE UPDATE ON property_revisions
+BEGIN
+	SELECT RAISE(ABORT, 'property revisions are immutable');
+END;
+
+CREATE TRIGGER property_revisions_no_delete BEFORE DELETE ON property_revisions
+BEGIN
+	SELECT RAISE(ABORT, 'property revisions are immutable');
+END;
@@ -0,0 +1,20 @@
+-- Photos, floor plans and documents of each property. The content lives in
+-- the blob store; captions and thumbnails are kept as JSON.
+CREATE TABLE property_media (
+	id           TEXT PRIMARY KEY,
+	property_id  TEXT NOT NULL REFERENCES properties(id) ON DELETE CASCADE,
+	kind         TEXT NOT NULL,
+	filename     TEXT NOT NULL DEFAULT '',
+	content_type TEXT NOT NULL,
+	size         INTEGER NOT NULL DEFAULT 0,
+	width        INTEGER NOT NULL DEFAULT 0,
+	height       INTEGER NOT NULL DEFAULT 0,
+	position     INTEGER NOT NULL DEFAULT 0,
+	cover        BOOLEAN NOT NULL DEFAULT 0,
+	captions     TEXT NOT NULL DEFAULT '{}',
+	thumbnails   TEXT NOT NULL DEFAULT '[]',
+	created_at   TIMESTAMP NOT NULL,
+	created_by   TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_property_media_property ON property_media(property_id, position);
@@ -0,0 +1,38 @@
+-- Price amounts are kept as exact decimal text instead of REAL. SQLite cannot
+-- change a column type, so the table is rebuilt; whole amounts lose the ".0"
+-- suffix of their REAL text form.
+CREATE TABLE property_prices_decimal (
+	property_id TEXT NOT NULL REFERENCES properties(id) ON DELETE CASCADE,
+	position    INTEGER NOT NULL,
+	amount      TEXT NOT NULL DEFAULT '0',
+	currency    TEXT NOT NULL DEFAULT '',
+	type        TEXT NOT NULL DEFAULT '',
+	negotiable  BOOLEAN NOT NULL DEFAULT 0,
+	PRIMARY KEY (property_id, position)
+);
+
+INSERT INTO property_prices_decimal (property_id, position, amount, currency, type, negotiable)
+SELECT property_id, position,
+	CASE WHEN amount = CAST(amount AS INTEGER) THEN CAST(CAST(amount AS INTEGER) AS TEXT) ELSE CAST(amount AS TEXT) END,
+	currency, type, negotiable
+FROM property_prices;
+
+DROP TABLE property_prices;
+ALTER TABLE property_prices_decimal RENAME TO property_prices;
+
+-- Valuation of the primary price in the base currency, derived by the service.
+-- Amounts are decimal text, empty when the property has no valuation.
+ALTER TABLE properties ADD COLUMN valuation_currency TEXT NOT NULL DEFAULT '';
+ALTER TABLE properties ADD COLUMN valuation_price_type TEXT NOT NULL DEFAULT '';
+ALTER TABLE properties ADD COLUMN valuation_amount TEXT NOT NULL DEFAULT '';
+ALTER TABLE properties ADD COLUMN valuation_per_m2 TEXT NOT NULL DEFAULT '';
+ALTER TABLE properties ADD COLUMN valuation_rate_date TEXT NOT NULL DEFAULT '';
+
+-- Exchange rates: 1 from_currency is worth rate to_currency from date on.
+CREATE TABLE exchange_rates (
+	from_currency TEXT NOT NULL,
+	to_currency   TEXT NOT NULL,
+	date          TEXT NOT NULL,
+	rate          TEXT NOT NULL,
+	PRIMARY KEY (from_currency, to_currency, date)
+);
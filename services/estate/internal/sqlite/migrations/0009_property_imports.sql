@@ -0,0 +1,25 @@
+-- Bulk property import jobs. The uploaded file and the per-row result file
+-- live in the blob store; mapping, ignored columns and row errors are JSON.
+CREATE TABLE property_imports (
+	id          TEXT PRIMARY KEY,
+	format      TEXT NOT NULL,
+	filename    TEXT NOT NULL DEFAULT '',
+	dry_run     BOOLEAN NOT NULL DEFAULT 0,
+	mapping     TEXT NOT NULL DEFAULT '{}',
+	ignored     TEXT NOT NULL DEFAULT '[]',
+	status      TEXT NOT NULL,
+	total       INTEGER NOT NULL DEFAULT 0,
+	processed   INTEGER NOT NULL DEFAULT 0,
+	valid       INTEGER NOT NULL DEFAULT 0,
+	created     INTEGER NOT NULL DEFAULT 0,
+	invalid     INTEGER NOT NULL DEFAULT 0,
+	failed      INTEGER NOT NULL DEFAULT 0,
+	errors      TEXT NOT NULL DEFAULT '[]',
+	error       TEXT NOT NULL DEFAULT '',
+	created_at  TIMESTAMP NOT NULL,
+	created_by  TEXT NOT NULL DEFAULT '',
+	started_at  TIMESTAMP,
+	finished_at TIMESTAMP
+);
+
+CREATE INDEX idx_property_imports_created ON property_imports(created_at DESC);
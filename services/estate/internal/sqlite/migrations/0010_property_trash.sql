@@ -0,0 +1,6 @@
+-- Deleted properties stay in the trash until they are restored or purged.
+-- Live queries filter on deleted_at IS NULL.
+ALTER TABLE properties ADD COLUMN deleted_at TIMESTAMP;
+ALTER TABLE properties ADD COLUMN deleted_by TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX idx_properties_deleted_at_id ON properties(deleted_at, id);
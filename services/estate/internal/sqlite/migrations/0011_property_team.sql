@@ -0,0 +1,5 @@
+-- Properties may be assigned to the team of their owner; authorization
+-- grants in the estate scope of a team apply to its properties.
+ALTER TABLE properties ADD COLUMN team_id TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX idx_properties_team_id ON properties(team_id);
@@ -0,0 +1,17 @@
+-- Outbox of property events. Each event is inserted in the transaction of
+-- the property write it describes and published by the relay in position
+-- order; published events are kept for replays.
+CREATE TABLE property_events (
+	position     INTEGER PRIMARY KEY AUTOINCREMENT,
+	id           TEXT NOT NULL UNIQUE,
+	type         TEXT NOT NULL,
+	aggregate_id TEXT NOT NULL,
+	sequence     INTEGER NOT NULL,
+	actor        TEXT NOT NULL DEFAULT '',
+	at           TIMESTAMP NOT NULL,
+	data         TEXT NOT NULL,
+	published_at TIMESTAMP
+);
+
+CREATE INDEX idx_property_events_pending ON property_events(position) WHERE published_at IS NULL;
+CREATE INDEX idx_property_events_aggregate ON property_events(aggregate_id, sequence);
@@ -0,0 +1,3 @@
+-- Import rows matching existing properties are rejected unless the job
+-- allows duplicates.
+ALTER TABLE property_imports ADD COLUMN allow_duplicates BOOLEAN NOT NULL DEFAULT 0;
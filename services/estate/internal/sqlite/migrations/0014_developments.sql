@@ -0,0 +1,30 @@
+-- Developments and buildings group properties as their units. Units
+-- reference them through development_id; location and amenities are JSON,
+-- with city and country also in columns for filtering.
+CREATE TABLE developments (
+	id            TEXT PRIMARY KEY,
+	kind          TEXT NOT NULL,
+	name          TEXT NOT NULL,
+	description   TEXT NOT NULL DEFAULT '',
+	developer     TEXT NOT NULL DEFAULT '',
+	stage         TEXT NOT NULL DEFAULT '',
+	delivery_date TEXT NOT NULL DEFAULT '',
+	location      TEXT NOT NULL DEFAULT '{}',
+	city          TEXT NOT NULL DEFAULT '',
+	country       TEXT NOT NULL DEFAULT '',
+	amenities     TEXT NOT NULL DEFAULT '[]',
+	year_built    INTEGER NOT NULL DEFAULT 0,
+	owner_id      TEXT NOT NULL DEFAULT '',
+	team_id       TEXT NOT NULL DEFAULT '',
+	revision      INTEGER NOT NULL DEFAULT 1,
+	created_at    TIMESTAMP NOT NULL,
+	created_by    TEXT NOT NULL DEFAULT '',
+	updated_at    TIMESTAMP NOT NULL,
+	updated_by    TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_developments_name ON developments(name, id);
+
+ALTER TABLE properties ADD COLUMN development_id TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX idx_properties_development_id ON properties(development_id);
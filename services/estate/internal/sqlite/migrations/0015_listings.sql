@@ -0,0 +1,37 @@
+-- Listings are the commercial offers of a property, kept after they end for
+-- reporting. There is no foreign key; Purge deletes them with the property.
+-- Content is JSON by locale. Every price a listing had is in listing_prices.
+CREATE TABLE listings (
+	id               TEXT PRIMARY KEY,
+	property_id      TEXT NOT NULL,
+	channel          TEXT NOT NULL,
+	price_amount     TEXT NOT NULL,
+	price_currency   TEXT NOT NULL,
+	price_type       TEXT NOT NULL,
+	price_negotiable BOOLEAN NOT NULL DEFAULT 0,
+	content          TEXT NOT NULL DEFAULT '{}',
+	status           TEXT NOT NULL,
+	starts_at        TIMESTAMP,
+	ends_at          TIMESTAMP,
+	ended_at         TIMESTAMP,
+	revision         INTEGER NOT NULL DEFAULT 1,
+	created_at       TIMESTAMP NOT NULL,
+	created_by       TEXT NOT NULL DEFAULT '',
+	updated_at       TIMESTAMP NOT NULL,
+	updated_by       TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_listings_property ON listings(property_id, created_at DESC);
+CREATE INDEX idx_listings_status ON listings(status, created_at DESC);
+
+CREATE TABLE listing_prices (
+	listing_id TEXT NOT NULL REFERENCES listings(id) ON DELETE CASCADE,
+	amount     TEXT NOT NULL,
+	currency   TEXT NOT NULL,
+	type       TEXT NOT NULL,
+	negotiable BOOLEAN NOT NULL DEFAULT 0,
+	actor      TEXT NOT NULL DEFAULT '',
+	at         TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_listing_prices_listing ON listing_prices(listing_id, at);
@@ -0,0 +1,31 @@
+-- Leases record the tenancies of properties. last_day is the day a lease
+-- really ends, its termination date when terminated early, and is what
+-- overlaps are checked against. Like listings they have no foreign key and
+-- are deleted by Purge with the property.
+CREATE TABLE leases (
+	id                 TEXT PRIMARY KEY,
+	property_id        TEXT NOT NULL,
+	tenant_name        TEXT NOT NULL,
+	tenant_email       TEXT NOT NULL DEFAULT '',
+	tenant_phone       TEXT NOT NULL DEFAULT '',
+	start_date         TEXT NOT NULL,
+	end_date           TEXT NOT NULL,
+	last_day           TEXT NOT NULL,
+	rent               TEXT NOT NULL,
+	currency           TEXT NOT NULL,
+	frequency          TEXT NOT NULL,
+	deposit            TEXT NOT NULL DEFAULT '0',
+	indexation         TEXT NOT NULL DEFAULT '{}',
+	renewal            TEXT NOT NULL DEFAULT '{}',
+	status             TEXT NOT NULL,
+	terminated_on      TEXT NOT NULL DEFAULT '',
+	termination_reason TEXT NOT NULL DEFAULT '',
+	revision           INTEGER NOT NULL DEFAULT 1,
+	created_at         TIMESTAMP NOT NULL,
+	created_by         TEXT NOT NULL DEFAULT '',
+	updated_at         TIMESTAMP NOT NULL,
+	updated_by         TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_leases_property ON leases(property_id, start_date DESC);
+CREATE INDEX idx_leases_expiring ON leases(status, end_date);
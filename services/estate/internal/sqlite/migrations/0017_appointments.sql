@@ -0,0 +1,25 @@
+-- Appointments are the viewings of properties. Two appointments of the same
+-- agent or property that hold their slots, all but cancelled ones, cannot
+-- be closer than the configured buffers; writes check it in the same
+-- statement. Like leases they have no foreign key and are deleted by Purge
+-- with the property.
+CREATE TABLE appointments (
+	id           TEXT PRIMARY KEY,
+	property_id  TEXT NOT NULL,
+	agent_id     TEXT NOT NULL,
+	client_name  TEXT NOT NULL,
+	client_email TEXT NOT NULL DEFAULT '',
+	client_phone TEXT NOT NULL DEFAULT '',
+	starts_at    TIMESTAMP NOT NULL,
+	ends_at      TIMESTAMP NOT NULL,
+	status       TEXT NOT NULL,
+	notes        TEXT NOT NULL DEFAULT '',
+	revision     INTEGER NOT NULL DEFAULT 1,
+	created_at   TIMESTAMP NOT NULL,
+	created_by   TEXT NOT NULL DEFAULT '',
+	updated_at   TIMESTAMP NOT NULL,
+	updated_by   TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_appointments_agent ON appointments(agent_id, starts_at);
+CREATE INDEX idx_appointments_property ON appointments(property_id, starts_at);
@@ -0,0 +1,38 @@
+-- Inquiries are the leads about properties. The contact is encrypted by the
+-- service; contact_lookup is an HMAC of its email or phone to find repeated
+-- inquiries without decrypting. Like leases they have no foreign key and are
+-- deleted by Purge with the property. Notes go with their inquiry.
+CREATE TABLE inquiries (
+	id             TEXT PRIMARY KEY,
+	property_id    TEXT NOT NULL,
+	contact_ct     BLOB NOT NULL,
+	contact_iv     BLOB NOT NULL,
+	contact_tag    BLOB NOT NULL,
+	contact_lookup BLOB NOT NULL,
+	message        TEXT NOT NULL DEFAULT '',
+	source         TEXT NOT NULL,
+	agent_id       TEXT NOT NULL DEFAULT '',
+	stage          TEXT NOT NULL,
+	outcome        TEXT NOT NULL DEFAULT '',
+	closed_at      TIMESTAMP,
+	revision       INTEGER NOT NULL DEFAULT 1,
+	created_at     TIMESTAMP NOT NULL,
+	created_by     TEXT NOT NULL DEFAULT '',
+	updated_at     TIMESTAMP NOT NULL,
+	updated_by     TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_inquiries_property ON inquiries(property_id, created_at DESC);
+CREATE INDEX idx_inquiries_agent ON inquiries(agent_id, created_at DESC);
+CREATE INDEX idx_inquiries_lookup ON inquiries(contact_lookup, property_id, created_at);
+CREATE INDEX idx_inquiries_created ON inquiries(created_at);
+
+CREATE TABLE inquiry_notes (
+	id         TEXT PRIMARY KEY,
+	inquiry_id TEXT NOT NULL REFERENCES inquiries(id) ON DELETE CASCADE,
+	body       TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	created_by TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_inquiry_notes_inquiry ON inquiry_notes(inquiry_id, created_at);
@@ -0,0 +1,147 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/services/estate/internal/estate"
+)
+
+// PendingEvents returns up to limit unpublished events in outbox order,
+// leaving out those of the excluded properties. Writes to a property are
+// serialized by its revision, so its events are inserted, and positioned, in
+// sequence order.
+func (r *PropertyRepo) PendingEvents(ctx context.Context, limit int, exclude []uuid.UUID) ([]estate.Event, error) {
+	query := QueryListPendingEvents
+	args := make([]any, 0, len(exclude)+1)
+	if len(exclude) > 0 {
+		query += " AND aggregate_id NOT IN " + placeholders(len(exclude))
+		for _, id := range exclude {
+			args = append(args, id.String())
+		}
+	}
+	args = append(args, limit)
+	return r.listEvents(ctx, query+" ORDER BY position LIMIT ?", args...)
+}
+
+// MarkPublished sets the publish time of the events still pending.
+func (r *PropertyRepo) MarkPublished(ctx context.Context, events []estate.Event, at time.Time) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	args := make([]any, 0, len(events)+1)
+	args = append(args, at.UTC())
+	for _, e := range events {
+		args = append(args, e.ID.String())
+	}
+	in := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(events)), ", ") + ")"
+
+	if _, err := r.db.ExecContext(ctx, QueryMarkEventsPublished+in, args...); err != nil {
+		return fmt.Errorf("could not mark events published: %w", err)
+	}
+	return nil
+}
+
+// Events retrieves the published events matching the query by position.
+func (r *PropertyRepo) Events(ctx context.Context, query estate.EventQuery) ([]estate.Event, error) {
+	var sb strings.Builder
+	sb.WriteString(QueryListEvents)
+	args := []any{query.After}
+
+	if query.AggregateID != uuid.Nil {
+		sb.WriteString(" AND aggregate_id = ?")
+		args = append(args, query.AggregateID.String())
+	}
+	if len(query.Types) > 0 {
+		sb.WriteString(" AND type IN (" + strings.TrimSuffix(strings.Repeat("?, ", len(query.Types)), ", ") + ")")
+		for _, t := range query.Types {
+			args = append(args, t)
+		}
+	}
+	if !query.Since.IsZero() {
+		sb.WriteString(" AND at >= ?")
+		args = append(args, query.Since.UTC())
+	}
+	sb.WriteString(" ORDER BY position")
+	if query.Limit > 0 {
+		sb.WriteString(" LIMIT ?")
+		args = append(args, query.Limit)
+	}
+
+	return r.listEvents(ctx, sb.String(), args...)
+}
+
+func (r *PropertyRepo) listEvents(ctx context.Context, query string, args ...any) ([]estate.Event, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("could not list events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []estate.Event
+	for rows.Next() {
+		e, err := scanEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, *e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating events: %w", err)
+	}
+
+	return events, nil
+}
+
+// insertEvent appends the event of a revision to the outbox inside the
+// transaction of the write.
+func insertEvent(ctx context.Context, tx *sql.Tx, e *estate.Event) error {
+	data, err := json.Marshal(e.Data)
+	if err != nil {
+		return fmt.Errorf("cannot encode event data: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, QueryCreateEvent,
+		e.ID.String(), e.Type, e.AggregateID.String(), e.Sequence, e.Actor, e.At.UTC(), string(data))
+	if err != nil {
+		return fmt.Errorf("could not record property event: %w", err)
+	}
+	return nil
+}
+
+func scanEvent(row rowScanner) (*estate.Event, error) {
+	var (
+		e               estate.Event
+		id, aggregateID string
+		data            string
+		publishedAt     sql.NullTime
+	)
+
+	err := row.Scan(&e.Position, &id, &e.Type, &aggregateID, &e.Sequence, &e.Actor, &e.At, &data, &publishedAt)
+	if err != nil {
+		return nil, fmt.Errorf("could not scan event: %w", err)
+	}
+
+	if e.ID, err = uuid.Parse(id); err != nil {
+		return nil, fmt.Errorf("invalid event ID: %w", err)
+	}
+	if e.AggregateID, err = uuid.Parse(aggregateID); err != nil {
+		return nil, fmt.Errorf("invalid event aggregate ID: %w", err)
+	}
+	if err := json.Unmarshal([]byte(data), &e.Data); err != nil {
+		return nil, fmt.Errorf("invalid event data: %w", err)
+	}
+	if publishedAt.Valid {
+		e.PublishedAt = &publishedAt.Time
+	}
+
+	return &e, nil
+}
@@ -0,0 +1,98 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pulap/pulap/services/estate/internal/estate"
+)
+
+// SearchGeo retrieves properties inside the query area, nearest first.
+// Candidates are narrowed with geohash prefix ranges and the area bounding box,
+// then matched exactly against the shape and sorted by distance.
+func (r *PropertyRepo) SearchGeo(ctx context.Context, query estate.GeoQuery) ([]estate.GeoResult, error) {
+	where := &whereBuilder{}
+	searchFilter(where, query.Filter)
+	geoFilter(where, query.Bounds())
+
+	candidates, err := r.list(ctx, QuerySearchProperties+where.String(), where.args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []estate.GeoResult
+	for _, p := range candidates {
+		point, ok := estate.PointOf(p)
+		if !ok || !query.Matches(point) {
+			continue
+		}
+		results = append(results, estate.GeoResult{
+			Property:       p,
+			DistanceMeters: estate.DistanceMeters(query.Center, point),
+		})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].DistanceMeters < results[j].DistanceMeters
+	})
+	if len(results) > query.Limit {
+		results = results[:query.Limit]
+	}
+
+	return results, nil
+}
+
+// geoFilter restricts rows to the geohash cells covering the box and to the box itself.
+func geoFilter(w *whereBuilder, b estate.BoundingBox) {
+	cells := geohashCover(b)
+
+	ranges := make([]string, 0, len(cells))
+	args := make([]any, 0, 2*len(cells))
+	for _, cell := range cells {
+		// "{" sorts right after "z", the last geohash character.
+		ranges = append(ranges, "(geohash >= ? AND geohash < ?)")
+		args = append(args, cell, cell+"{")
+	}
+
+	w.add("("+strings.Join(ranges, " OR ")+")", args...)
+	w.add("latitude BETWEEN ? AND ?", b.South, b.North)
+	w.add("longitude BETWEEN ? AND ?", b.West, b.East)
+}
+
+// backfillGeohashes computes the geohash of rows written before the column existed.
+func backfillGeohashes(ctx context.Context, db *sql.DB) error {
+	rows, err := db.QueryContext(ctx, QueryListMissingGeohashes)
+	if err != nil {
+		return fmt.Errorf("could not list properties without geohash: %w", err)
+	}
+
+	type pending struct {
+		id   string
+		hash string
+	}
+	var updates []pending
+	for rows.Next() {
+		var id string
+		var lat, lng float64
+		if err := rows.Scan(&id, &lat, &lng); err != nil {
+			rows.Close()
+			return fmt.Errorf("could not scan property coordinates: %w", err)
+		}
+		updates = append(updates, pending{id: id, hash: encodeGeohash(lat, lng, geohashPrecision)})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("rows error while listing properties without geohash: %w", err)
+	}
+
+	for _, u := range updates {
+		if _, err := db.ExecContext(ctx, QueryUpdateGeohash, u.hash, u.id); err != nil {
+			return fmt.Errorf("could not update geohash: %w", err)
+		}
+	}
+
+	return nil
+}
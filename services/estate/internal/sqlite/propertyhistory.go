@@ -0,0 +1,108 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/services/estate/internal/estate"
+)
+
+// Revisions retrieves the revisions of a property without snapshots, newest first.
+func (r *PropertyRepo) Revisions(ctx context.Context, id uuid.UUID) ([]estate.PropertyRevision, error) {
+	rows, err := r.db.QueryContext(ctx, QueryListPropertyRevisions, id.String())
+	if err != nil {
+		return nil, fmt.Errorf("could not list property revisions: %w", err)
+	}
+	defer rows.Close()
+
+	var revisions []estate.PropertyRevision
+	for rows.Next() {
+		rev, err := scanRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, *rev)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating property revisions: %w", err)
+	}
+
+	return revisions, nil
+}
+
+// Revision retrieves a single property revision including its snapshot.
+func (r *PropertyRepo) Revision(ctx context.Context, id uuid.UUID, number int64) (*estate.PropertyRevision, error) {
+	rev, err := scanRevision(r.db.QueryRowContext(ctx, QueryGetPropertyRevision, id.String(), number))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("revision %d of property %s: %w", number, id, estate.ErrRevisionNotFound)
+	}
+	return rev, err
+}
+
+// insertRevision records the revision of a write and its event inside the
+// transaction of the write.
+func insertRevision(ctx context.Context, tx *sql.Tx, action string, before, after *estate.Property) error {
+	at := time.Now()
+	if after != nil {
+		at = after.UpdatedAt
+	}
+
+	rev, err := estate.NewRevision(ctx, action, before, after, at)
+	if err != nil {
+		return err
+	}
+
+	changes, err := json.Marshal(rev.Changes)
+	if err != nil {
+		return fmt.Errorf("cannot encode revision changes: %w", err)
+	}
+	snapshot, err := json.Marshal(rev.Snapshot)
+	if err != nil {
+		return fmt.Errorf("cannot encode revision snapshot: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, QueryCreatePropertyRevision,
+		rev.PropertyID.String(), rev.Number, rev.Action, rev.Actor, rev.At, rev.RestoredFrom, string(changes), string(snapshot))
+	if err != nil {
+		return fmt.Errorf("could not record property revision: %w", err)
+	}
+
+	return insertEvent(ctx, tx, estate.NewEvent(rev, before))
+}
+
+func scanRevision(row rowScanner) (*estate.PropertyRevision, error) {
+	var (
+		rev                           estate.PropertyRevision
+		propertyID, changes, snapshot string
+	)
+
+	err := row.Scan(&propertyID, &rev.Number, &rev.Action, &rev.Actor, &rev.At, &rev.RestoredFrom, &changes, &snapshot)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("could not scan property revision: %w", err)
+	}
+
+	if rev.PropertyID, err = uuid.Parse(propertyID); err != nil {
+		return nil, fmt.Errorf("invalid property ID: %w", err)
+	}
+	if err := json.Unmarshal([]byte(changes), &rev.Changes); err != nil {
+		return nil, fmt.Errorf("invalid revision changes: %w", err)
+	}
+	if snapshot != "" {
+		rev.Snapshot = &estate.Property{}
+		if err := json.Unmarshal([]byte(snapshot), rev.Snapshot); err != nil {
+			return nil, fmt.Errorf("invalid revision snapshot: %w", err)
+		}
+	}
+
+	return &rev, nil
+}
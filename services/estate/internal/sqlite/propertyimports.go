@@ -0,0 +1,135 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/services/estate/internal/estate"
+)
+
+// CreateImport stores a new import job.
+func (r *PropertyRepo) CreateImport(ctx context.Context, job *estate.ImportJob) error {
+	mapping, err := json.Marshal(job.Mapping)
+	if err != nil {
+		return fmt.Errorf("cannot encode import mapping: %w", err)
+	}
+	ignored, err := json.Marshal(job.Ignored)
+	if err != nil {
+		return fmt.Errorf("cannot encode ignored columns: %w", err)
+	}
+	rowErrors, err := json.Marshal(job.Errors)
+	if err != nil {
+		return fmt.Errorf("cannot encode import errors: %w", err)
+	}
+
+	_, err = r.db.ExecContext(ctx, QueryCreateImport,
+		job.ID.String(), job.Format, job.Filename, job.DryRun, job.AllowDuplicates, string(mapping), string(ignored), job.Status,
+		job.Total, job.Processed, job.Valid, job.Created, job.Invalid, job.Failed, string(rowErrors), job.Error,
+		job.CreatedAt.UTC(), job.CreatedBy, utcTime(job.StartedAt), utcTime(job.FinishedAt))
+	if err != nil {
+		return fmt.Errorf("could not create import: %w", err)
+	}
+	return nil
+}
+
+// GetImport retrieves an import job.
+func (r *PropertyRepo) GetImport(ctx context.Context, id uuid.UUID) (*estate.ImportJob, error) {
+	job, err := scanImport(r.db.QueryRowContext(ctx, QueryGetImport, id.String()))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("import %s: %w", id, estate.ErrImportNotFound)
+	}
+	return job, err
+}
+
+// SaveImport updates the progress and outcome of an import job.
+func (r *PropertyRepo) SaveImport(ctx context.Context, job *estate.ImportJob) error {
+	rowErrors, err := json.Marshal(job.Errors)
+	if err != nil {
+		return fmt.Errorf("cannot encode import errors: %w", err)
+	}
+
+	result, err := r.db.ExecContext(ctx, QueryUpdateImport,
+		job.Status, job.Total, job.Processed, job.Valid, job.Created, job.Invalid, job.Failed,
+		string(rowErrors), job.Error, utcTime(job.StartedAt), utcTime(job.FinishedAt), job.ID.String())
+	if err != nil {
+		return fmt.Errorf("could not update import: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("import %s: %w", job.ID, estate.ErrImportNotFound)
+	}
+	return nil
+}
+
+// ListImports lists import jobs, newest first. A limit of zero lists every job.
+func (r *PropertyRepo) ListImports(ctx context.Context, limit int) ([]estate.ImportJob, error) {
+	query, args := QueryListImports, []any{}
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("could not list imports: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []estate.ImportJob
+	for rows.Next() {
+		job, err := scanImport(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, *job)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating imports: %w", err)
+	}
+
+	return jobs, nil
+}
+
+func scanImport(row rowScanner) (*estate.ImportJob, error) {
+	var (
+		job                             estate.ImportJob
+		id, mapping, ignored, rowErrors string
+	)
+	err := row.Scan(&id, &job.Format, &job.Filename, &job.DryRun, &job.AllowDuplicates, &mapping, &ignored, &job.Status,
+		&job.Total, &job.Processed, &job.Valid, &job.Created, &job.Invalid, &job.Failed, &rowErrors, &job.Error,
+		&job.CreatedAt, &job.CreatedBy, &job.StartedAt, &job.FinishedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("could not scan import: %w", err)
+	}
+
+	if job.ID, err = uuid.Parse(id); err != nil {
+		return nil, fmt.Errorf("invalid import ID %q: %w", id, err)
+	}
+	if err := json.Unmarshal([]byte(mapping), &job.Mapping); err != nil {
+		return nil, fmt.Errorf("cannot decode import mapping: %w", err)
+	}
+	if err := json.Unmarshal([]byte(ignored), &job.Ignored); err != nil {
+		return nil, fmt.Errorf("cannot decode ignored columns: %w", err)
+	}
+	if err := json.Unmarshal([]byte(rowErrors), &job.Errors); err != nil {
+		return nil, fmt.Errorf("cannot decode import errors: %w", err)
+	}
+	return &job, nil
+}
+
+// utcTime converts an optional time to UTC, keeping nil as NULL.
+func utcTime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.UTC()
+}
@@ -0,0 +1,166 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/mattn/go-sqlite3"
+
+	"github.com/pulap/pulap/services/estate/internal/estate"
+)
+
+// CreateMedia stores the metadata of a media item. The property must exist.
+func (r *PropertyRepo) CreateMedia(ctx context.Context, m *estate.Media) error {
+	captions, thumbnails, err := encodeMedia(m)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx, QueryCreateMedia,
+		m.ID.String(), m.PropertyID.String(), m.Kind, m.Filename, m.ContentType, m.Size, m.Width, m.Height,
+		m.Position, m.Cover, captions, thumbnails, m.CreatedAt.UTC(), m.CreatedBy)
+	if err != nil {
+		if isForeignKeyError(err) {
+			return fmt.Errorf("Property aggregate with ID %s: %w", m.PropertyID, estate.ErrNotFound)
+		}
+		return fmt.Errorf("could not create media: %w", err)
+	}
+	return nil
+}
+
+// GetMedia retrieves a media item of a property.
+func (r *PropertyRepo) GetMedia(ctx context.Context, propertyID, id uuid.UUID) (*estate.Media, error) {
+	m, err := scanMedia(r.db.QueryRowContext(ctx, QueryGetMedia, propertyID.String(), id.String()))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("media %s of property %s: %w", id, propertyID, estate.ErrMediaNotFound)
+	}
+	return m, err
+}
+
+// ListMedia lists the media of a property by position.
+func (r *PropertyRepo) ListMedia(ctx context.Context, propertyID uuid.UUID) ([]estate.Media, error) {
+	rows, err := r.db.QueryContext(ctx, QueryListMedia, propertyID.String())
+	if err != nil {
+		return nil, fmt.Errorf("could not list media: %w", err)
+	}
+	defer rows.Close()
+
+	var media []estate.Media
+	for rows.Next() {
+		m, err := scanMedia(rows)
+		if err != nil {
+			return nil, err
+		}
+		media = append(media, *m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating media: %w", err)
+	}
+
+	return media, nil
+}
+
+// SaveMedia updates the position, cover flag and captions of a media item.
+func (r *PropertyRepo) SaveMedia(ctx context.Context, m *estate.Media) error {
+	captions, _, err := encodeMedia(m)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(ctx, QueryUpdateMedia, m.Position, m.Cover, captions, m.PropertyID.String(), m.ID.String())
+	if err != nil {
+		return fmt.Errorf("could not update media: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("media %s of property %s: %w", m.ID, m.PropertyID, estate.ErrMediaNotFound)
+	}
+	return nil
+}
+
+// ArrangeMedia updates the position and cover flag of items in one transaction.
+func (r *PropertyRepo) ArrangeMedia(ctx context.Context, propertyID uuid.UUID, items []estate.Media) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, m := range items {
+		result, err := tx.ExecContext(ctx, QueryArrangeMedia, m.Position, m.Cover, propertyID.String(), m.ID.String())
+		if err != nil {
+			return fmt.Errorf("could not arrange media: %w", err)
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			return fmt.Errorf("media %s of property %s: %w", m.ID, propertyID, estate.ErrMediaNotFound)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("could not commit transaction: %w", err)
+	}
+	return nil
+}
+
+// DeleteMedia deletes a media item.
+func (r *PropertyRepo) DeleteMedia(ctx context.Context, propertyID, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, QueryDeleteMedia, propertyID.String(), id.String())
+	if err != nil {
+		return fmt.Errorf("could not delete media: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("media %s of property %s: %w", id, propertyID, estate.ErrMediaNotFound)
+	}
+	return nil
+}
+
+// isForeignKeyError reports whether err is a foreign key violation.
+func isForeignKeyError(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintForeignKey
+}
+
+func encodeMedia(m *estate.Media) (string, string, error) {
+	captions, err := json.Marshal(m.Captions)
+	if err != nil {
+		return "", "", fmt.Errorf("cannot encode media captions: %w", err)
+	}
+	thumbnails, err := json.Marshal(m.Thumbnails)
+	if err != nil {
+		return "", "", fmt.Errorf("cannot encode media thumbnails: %w", err)
+	}
+	return string(captions), string(thumbnails), nil
+}
+
+func scanMedia(row rowScanner) (*estate.Media, error) {
+	var (
+		m                                estate.Media
+		id, propertyID, captions, thumbs string
+	)
+	err := row.Scan(&id, &propertyID, &m.Kind, &m.Filename, &m.ContentType, &m.Size, &m.Width, &m.Height,
+		&m.Position, &m.Cover, &captions, &thumbs, &m.CreatedAt, &m.CreatedBy)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("could not scan media: %w", err)
+	}
+
+	if m.ID, err = uuid.Parse(id); err != nil {
+		return nil, fmt.Errorf("invalid media ID %q: %w", id, err)
+	}
+	if m.PropertyID, err = uuid.Parse(propertyID); err != nil {
+		return nil, fmt.Errorf("invalid property ID %q: %w", propertyID, err)
+	}
+	if err := json.Unmarshal([]byte(captions), &m.Captions); err != nil {
+		return nil, fmt.Errorf("cannot decode media captions: %w", err)
+	}
+	if err := json.Unmarshal([]byte(thumbs), &m.Thumbnails); err != nil {
+		return nil, fmt.Errorf("cannot decode media thumbnails: %w", err)
+	}
+	return &m, nil
+}
@@ -0,0 +1,105 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/services/estate/internal/estate"
+	"github.com/pulap/pulap/services/estate/internal/money"
+)
+
+// SaveRates stores exchange rates in one transaction, replacing any with the
+// same pair and date.
+func (r *PropertyRepo) SaveRates(ctx context.Context, rates []money.Rate) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, rate := range rates {
+		if _, err := tx.ExecContext(ctx, QuerySaveRate, rate.From, rate.To, rate.Date.Format(money.DateLayout), rate.Value); err != nil {
+			return fmt.Errorf("could not save exchange rate: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("could not commit transaction: %w", err)
+	}
+	return nil
+}
+
+// ListRates retrieves every stored exchange rate.
+func (r *PropertyRepo) ListRates(ctx context.Context) ([]money.Rate, error) {
+	rows, err := r.db.QueryContext(ctx, QueryListRates)
+	if err != nil {
+		return nil, fmt.Errorf("could not list exchange rates: %w", err)
+	}
+	defer rows.Close()
+
+	var rates []money.Rate
+	for rows.Next() {
+		var (
+			rate money.Rate
+			date string
+		)
+		if err := rows.Scan(&rate.From, &rate.To, &date, &rate.Value); err != nil {
+			return nil, fmt.Errorf("could not scan exchange rate: %w", err)
+		}
+		if rate.Date, err = money.ParseDate(date); err != nil {
+			return nil, err
+		}
+		rates = append(rates, rate)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating exchange rates: %w", err)
+	}
+
+	return rates, nil
+}
+
+// SaveValuation replaces the valuation of a property without changing its revision.
+func (r *PropertyRepo) SaveValuation(ctx context.Context, id uuid.UUID, v *estate.Valuation) error {
+	args := append(valuationArgs(v), id.String())
+	result, err := r.db.ExecContext(ctx, QueryUpdateValuation, args...)
+	if err != nil {
+		return fmt.Errorf("could not update valuation: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("Property aggregate with ID %s: %w", id, estate.ErrNotFound)
+	}
+	return nil
+}
+
+// valuationColumns receives the valuation columns of a properties row.
+type valuationColumns struct {
+	currency, priceType, amount, perM2, rateDate string
+}
+
+// decode returns the valuation, nil when the row has none.
+func (c valuationColumns) decode() (*estate.Valuation, error) {
+	if c.currency == "" {
+		return nil, nil
+	}
+
+	v := &estate.Valuation{Currency: c.currency, PriceType: c.priceType, RateDate: c.rateDate}
+	var err error
+	if v.Amount, err = money.Parse(c.amount); err != nil {
+		return nil, fmt.Errorf("invalid valuation amount: %w", err)
+	}
+	if v.PerSquareMeter, err = money.Parse(c.perM2); err != nil {
+		return nil, fmt.Errorf("invalid valuation per m²: %w", err)
+	}
+	return v, nil
+}
+
+// valuationArgs returns the valuation columns in table order; empty when v is nil.
+func valuationArgs(v *estate.Valuation) []any {
+	if v == nil {
+		return []any{"", "", "", "", ""}
+	}
+	return []any{v.Currency, v.PriceType, v.Amount.String(), v.PerSquareMeter.String(), v.RateDate}
+}
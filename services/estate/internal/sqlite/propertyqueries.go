@@ -0,0 +1,229 @@
+package sqlite
+
+const (
+	// propertyColumns lists the properties table columns in scan order.
+	propertyColumns = `id, name, description,
+		category_id, type_id, subtype_id,
+		street, number, unit, city, state, postal_code, country,
+		latitude, longitude, region, provider, provider_url, provider_ref, location_raw, display_name,
+		total_area, covered_area, land_area, bedrooms, bathrooms, half_baths, rooms,
+		parking, covered_parking, floors, floor, year_built, condition,
+		pool, garden, balcony, terrace, elevator, air_conditioning, heating,
+		furnished, pet_friendly, storage, laundry, fireplace,
+		valuation_currency, valuation_price_type, valuation_amount, valuation_per_m2, valuation_rate_date,
+		status, owner_id, team_id, development_id, schema_version, revision, created_at, created_by, updated_at, updated_by,
+		deleted_at, deleted_by`
+
+	// QueryCreateProperty inserts a Property aggregate root row.
+	QueryCreateProperty = `INSERT INTO properties (` + propertyColumns + `, geohash) VALUES (
+		?, ?, ?,
+		?, ?, ?,
+		?, ?, ?, ?, ?, ?, ?,
+		?, ?, ?, ?, ?, ?, ?, ?,
+		?, ?, ?, ?, ?, ?, ?,
+		?, ?, ?, ?, ?, ?,
+		?, ?, ?, ?, ?, ?, ?,
+		?, ?, ?, ?, ?,
+		?, ?, ?, ?, ?,
+		?, ?, ?, ?, ?, ?, ?, ?, ?, ?,
+		?, ?,
+		?)`
+
+	// QueryGetProperty retrieves a Property aggregate root row by ID unless it is in the trash.
+	QueryGetProperty = `SELECT ` + propertyColumns + ` FROM properties WHERE id = ? AND deleted_at IS NULL`
+
+	// QueryGetStoredProperty retrieves a Property aggregate root row by ID, in the trash or not.
+	QueryGetStoredProperty = `SELECT ` + propertyColumns + ` FROM properties WHERE id = ?`
+
+	// propertySet assigns every mutable column of a Property aggregate root row, in updateArgs order.
+	propertySet = `name = ?, description = ?,
+		category_id = ?, type_id = ?, subtype_id = ?,
+		street = ?, number = ?, unit = ?, city = ?, state = ?, postal_code = ?, country = ?,
+		latitude = ?, longitude = ?, region = ?, provider = ?, provider_url = ?, provider_ref = ?, location_raw = ?, display_name = ?,
+		total_area = ?, covered_area = ?, land_area = ?, bedrooms = ?, bathrooms = ?, half_baths = ?, rooms = ?,
+		parking = ?, covered_parking = ?, floors = ?, floor = ?, year_built = ?, condition = ?,
+		pool = ?, garden = ?, balcony = ?, terrace = ?, elevator = ?, air_conditioning = ?, heating = ?,
+		furnished = ?, pet_friendly = ?, storage = ?, laundry = ?, fireplace = ?,
+		valuation_currency = ?, valuation_price_type = ?, valuation_amount = ?, valuation_per_m2 = ?, valuation_rate_date = ?,
+		status = ?, owner_id = ?, team_id = ?, development_id = ?, schema_version = ?, updated_at = ?, updated_by = ?,
+		geohash = ?`
+
+	// QueryUpdateProperty updates every mutable column of a Property aggregate
+	// root row and increments its revision, if the revision still matches and
+	// it is not in the trash.
+	QueryUpdateProperty = `UPDATE properties SET ` + propertySet + `, revision = revision + 1 WHERE id = ? AND revision = ? AND deleted_at IS NULL`
+
+	// QueryRewriteProperty updates every mutable column of a Property aggregate
+	// root row keeping its revision, if the revision still matches.
+	QueryRewriteProperty = `UPDATE properties SET ` + propertySet + ` WHERE id = ? AND revision = ?`
+
+	// QueryTrashProperty moves a Property aggregate root row to the trash and
+	// increments its revision, if the revision still matches.
+	QueryTrashProperty = `UPDATE properties SET deleted_at = ?, deleted_by = ?, revision = revision + 1
+		WHERE id = ? AND revision = ? AND deleted_at IS NULL`
+
+	// QueryUntrashProperty takes a Property aggregate root row out of the
+	// trash and increments its revision, if the revision still matches.
+	QueryUntrashProperty = `UPDATE properties SET deleted_at = NULL, deleted_by = '', updated_at = ?, updated_by = ?, revision = revision + 1
+		WHERE id = ? AND revision = ? AND deleted_at IS NOT NULL`
+
+	// QueryListPurgeable lists the IDs of properties moved to the trash before a time.
+	QueryListPurgeable = `SELECT id FROM properties WHERE deleted_at < ? ORDER BY deleted_at, id`
+
+	// QueryPurgeProperty deletes a Property aggregate root row moved to the trash before a time; children cascade.
+	QueryPurgeProperty = `DELETE FROM properties WHERE id = ? AND deleted_at < ?`
+
+	// QueryPurgeListings deletes the listings of a purged property; their prices cascade.
+	QueryPurgeListings = `DELETE FROM listings WHERE property_id = ?`
+
+	// QueryPurgeLeases deletes the leases of a purged property.
+	QueryPurgeLeases = `DELETE FROM leases WHERE property_id = ?`
+
+	// QueryPurgeAppointments deletes the appointments of a purged property.
+	QueryPurgeAppointments = `DELETE FROM appointments WHERE property_id = ?`
+
+	// QueryPurgeInquiries deletes the inquiries of a purged property; their notes cascade.
+	QueryPurgeInquiries = `DELETE FROM inquiries WHERE property_id = ?`
+
+	// QueryListProperties lists all Property aggregate root rows not in the trash.
+	QueryListProperties = `SELECT ` + propertyColumns + ` FROM properties WHERE deleted_at IS NULL ORDER BY created_at DESC`
+
+	// QueryListPropertiesByOwner lists Property aggregate root rows not in the trash for an owner.
+	QueryListPropertiesByOwner = `SELECT ` + propertyColumns + ` FROM properties WHERE owner_id = ? AND deleted_at IS NULL ORDER BY created_at DESC`
+
+	// QueryListPropertiesByStatus lists Property aggregate root rows not in the trash with a status.
+	QueryListPropertiesByStatus = `SELECT ` + propertyColumns + ` FROM properties WHERE status = ? AND deleted_at IS NULL ORDER BY created_at DESC`
+
+	// QuerySearchProperties selects Property aggregate root rows; the WHERE, ORDER BY and LIMIT clauses are appended.
+	QuerySearchProperties = `SELECT ` + propertyColumns + ` FROM properties`
+
+	// QueryCountProperties counts Property aggregate root rows; the WHERE clause is appended.
+	QueryCountProperties = `SELECT COUNT(*) FROM properties`
+
+	// QueryListMissingGeohashes lists properties with coordinates but no geohash.
+	QueryListMissingGeohashes = `SELECT id, latitude, longitude FROM properties WHERE geohash = '' AND NOT (latitude = 0 AND longitude = 0)`
+
+	// QueryUpdateGeohash sets the geohash of a property.
+	QueryUpdateGeohash = `UPDATE properties SET geohash = ? WHERE id = ?`
+
+	// QueryUpdatePropertyStatus sets the status of a property not in the trash if it still has the expected status and revision.
+	QueryUpdatePropertyStatus = `UPDATE properties SET status = ?, updated_at = ?, updated_by = ?, revision = revision + 1
+		WHERE id = ? AND status = ? AND revision = ? AND deleted_at IS NULL`
+
+	// QueryListOutdatedSchema lists the IDs of properties stored with an older schema version.
+	QueryListOutdatedSchema = `SELECT id FROM properties WHERE schema_version < ? ORDER BY id`
+
+	// QueryUpdateValuation sets the valuation columns of a property, keeping its revision.
+	QueryUpdateValuation = `UPDATE properties SET valuation_currency = ?, valuation_price_type = ?, valuation_amount = ?, valuation_per_m2 = ?, valuation_rate_date = ? WHERE id = ?`
+
+	// QueryPropertyExists checks whether a property row exists.
+	QueryPropertyExists = `SELECT 1 FROM properties WHERE id = ?`
+
+	// Queries for the status history
+
+	// QueryCreateStatusTransition appends a status transition.
+	QueryCreateStatusTransition = `INSERT INTO property_status_history (id, property_id, from_status, to_status, reason, actor, override, at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	// QueryListStatusTransitions lists the status transitions of a property, oldest first.
+	QueryListStatusTransitions = `SELECT id, property_id, from_status, to_status, reason, actor, override, at FROM property_status_history WHERE property_id = ? ORDER BY at, rowid`
+
+	// Queries for the revision history
+
+	// QueryCreatePropertyRevision appends a property revision.
+	QueryCreatePropertyRevision = `INSERT INTO property_revisions (property_id, number, action, actor, at, restored_from, changes, snapshot) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	// QueryListPropertyRevisions lists the revisions of a property without snapshots, newest first.
+	QueryListPropertyRevisions = `SELECT property_id, number, action, actor, at, restored_from, changes, '' FROM property_revisions WHERE property_id = ? ORDER BY number DESC`
+
+	// QueryGetPropertyRevision retrieves a single property revision with its snapshot.
+	QueryGetPropertyRevision = `SELECT property_id, number, action, actor, at, restored_from, changes, snapshot FROM property_revisions WHERE property_id = ? AND number = ?`
+
+	// Queries for the event outbox
+
+	// eventColumns lists the property_events columns in scan order.
+	eventColumns = `position, id, type, aggregate_id, sequence, actor, at, data, published_at`
+
+	// QueryCreateEvent appends a pending event to the outbox.
+	QueryCreateEvent = `INSERT INTO property_events (id, type, aggregate_id, sequence, actor, at, data) VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+	// QueryListPendingEvents selects unpublished events; the exclusions, ORDER BY and LIMIT clauses are appended.
+	QueryListPendingEvents = `SELECT ` + eventColumns + ` FROM property_events WHERE published_at IS NULL`
+
+	// QueryMarkEventsPublished marks events as published; the IN list is appended.
+	QueryMarkEventsPublished = `UPDATE property_events SET published_at = ? WHERE published_at IS NULL AND id IN `
+
+	// QueryListEvents selects published events; the filters, ORDER BY and LIMIT clauses are appended.
+	QueryListEvents = `SELECT ` + eventColumns + ` FROM property_events WHERE published_at IS NOT NULL AND position > ?`
+
+	// Queries for property media
+
+	// mediaColumns lists the property_media columns in scan order.
+	mediaColumns = `id, property_id, kind, filename, content_type, size, width, height, position, cover, captions, thumbnails, created_at, created_by`
+
+	// QueryCreateMedia inserts a media item.
+	QueryCreateMedia = `INSERT INTO property_media (` + mediaColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	// QueryGetMedia retrieves a media item of a property.
+	QueryGetMedia = `SELECT ` + mediaColumns + ` FROM property_media WHERE property_id = ? AND id = ?`
+
+	// QueryListMedia lists the media of a property by position.
+	QueryListMedia = `SELECT ` + mediaColumns + ` FROM property_media WHERE property_id = ? ORDER BY position, created_at`
+
+	// QueryUpdateMedia updates the editable fields of a media item.
+	QueryUpdateMedia = `UPDATE property_media SET position = ?, cover = ?, captions = ? WHERE property_id = ? AND id = ?`
+
+	// QueryArrangeMedia updates the position and cover flag of a media item.
+	QueryArrangeMedia = `UPDATE property_media SET position = ?, cover = ? WHERE property_id = ? AND id = ?`
+
+	// QueryDeleteMedia deletes a media item.
+	QueryDeleteMedia = `DELETE FROM property_media WHERE property_id = ? AND id = ?`
+
+	// Queries for bulk import jobs
+
+	// importColumns lists the property_imports columns in scan order.
+	importColumns = `id, format, filename, dry_run, allow_duplicates, mapping, ignored, status, total, processed, valid, created, invalid, failed, errors, error, created_at, created_by, started_at, finished_at`
+
+	// QueryCreateImport inserts an import job.
+	QueryCreateImport = `INSERT INTO property_imports (` + importColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	// QueryGetImport retrieves an import job.
+	QueryGetImport = `SELECT ` + importColumns + ` FROM property_imports WHERE id = ?`
+
+	// QueryListImports lists import jobs, newest first; a LIMIT may be appended.
+	QueryListImports = `SELECT ` + importColumns + ` FROM property_imports ORDER BY created_at DESC, id`
+
+	// QueryUpdateImport updates the progress and outcome of an import job.
+	QueryUpdateImport = `UPDATE property_imports SET status = ?, total = ?, processed = ?, valid = ?, created = ?, invalid = ?, failed = ?,
+		errors = ?, error = ?, started_at = ?, finished_at = ? WHERE id = ?`
+
+	// Queries for the Prices child collection
+
+	// QueryCreatePrice inserts a single price row.
+	QueryCreatePrice = `INSERT INTO property_prices (property_id, position, amount, currency, type, negotiable) VALUES (?, ?, ?, ?, ?, ?)`
+
+	// QueryDeletePrices deletes every price row of a property.
+	QueryDeletePrices = `DELETE FROM property_prices WHERE property_id = ?`
+
+	// QueryListPricesIn lists price rows for a set of properties; the IN list is appended.
+	QueryListPricesIn = `SELECT property_id, amount, currency, type, negotiable FROM property_prices WHERE property_id IN `
+
+	// Queries for exchange rates
+
+	// QuerySaveRate inserts an exchange rate, replacing the rate of the same pair and date.
+	QuerySaveRate = `INSERT INTO exchange_rates (from_currency, to_currency, date, rate) VALUES (?, ?, ?, ?)
+		ON CONFLICT (from_currency, to_currency, date) DO UPDATE SET rate = excluded.rate`
+
+	// QueryListRates lists every exchange rate.
+	QueryListRates = `SELECT from_currency, to_currency, date, rate FROM exchange_rates ORDER BY from_currency, to_currency, date`
+
+	// Queries for the Amenities child collection
+
+	// QueryCreateAmenity inserts a single amenity row.
+	QueryCreateAmenity = `INSERT INTO property_amenities (property_id, position, amenity) VALUES (?, ?, ?)`
+
+	// QueryDeleteAmenities deletes every amenity row of a property.
+	QueryDeleteAmenities = `DELETE FROM property_amenities WHERE property_id = ?`
+
+	// QueryListAmenitiesIn lists amenity rows for a set of properties; the IN list is appended.
+	QueryListAmenitiesIn = `SELECT property_id, amenity FROM property_amenities WHERE property_id IN `
+)
@@ -0,0 +1,669 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/services/estate/internal/config"
+	"github.com/pulap/pulap/services/estate/internal/estate"
+)
+
+// PropertyRepo implements the estate.Repo interface using SQLite.
+// The aggregate root and its value objects live in the properties table;
+// Prices and Amenities are stored in child tables and written in the same
+// transaction as the root.
+type PropertyRepo struct {
+	db      *sql.DB
+	xparams config.XParams
+}
+
+// NewPropertyRepo creates a new SQLite repository for Property aggregates.
+func NewPropertyRepo(xparams config.XParams) *PropertyRepo {
+	return &PropertyRepo{
+		xparams: xparams,
+	}
+}
+
+// Start opens the database connection and runs the embedded migrations.
+func (r *PropertyRepo) Start(ctx context.Context) error {
+	appCfg := r.xparams.Cfg()
+
+	dbPath := appCfg.Database.Path
+
+	db, err := sql.Open(driverName, fmt.Sprintf("%s?_foreign_keys=on&_busy_timeout=5000", dbPath))
+	if err != nil {
+		return fmt.Errorf("cannot open database: %w", err)
+	}
+
+	if err := db.PingContext(ctx); err != nil {
+		return fmt.Errorf("cannot connect to database: %w", err)
+	}
+
+	if err := migrate(ctx, db); err != nil {
+		db.Close()
+		return fmt.Errorf("cannot migrate database: %w", err)
+	}
+
+	if err := backfillGeohashes(ctx, db); err != nil {
+		db.Close()
+		return fmt.Errorf("cannot backfill geohashes: %w", err)
+	}
+
+	r.db = db
+	r.xparams.Log().Infof("Opened SQLite database: %s", dbPath)
+	return nil
+}
+
+// Stop closes the database connection.
+func (r *PropertyRepo) Stop(ctx context.Context) error {
+	if r.db != nil {
+		if err := r.db.Close(); err != nil {
+			return fmt.Errorf("cannot close database: %w", err)
+		}
+	}
+	return nil
+}
+
+// Create creates a new Property aggregate in SQLite.
+// This involves inserting the root and all child rows in a single transaction.
+func (r *PropertyRepo) Create(ctx context.Context, property *estate.Property) error {
+	if property == nil {
+		return fmt.Errorf("property cannot be nil")
+	}
+
+	property.EnsureID()
+	property.BeforeCreate()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	args, err := createArgs(property)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, QueryCreateProperty, args...); err != nil {
+		return fmt.Errorf("could not create Property aggregate: %w", err)
+	}
+
+	if err := r.insertChildren(ctx, tx, property); err != nil {
+		return err
+	}
+
+	if err := insertRevision(ctx, tx, estate.ActionCreate, nil, property); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("could not commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// Get retrieves a complete Property aggregate by ID from SQLite.
+func (r *PropertyRepo) Get(ctx context.Context, id uuid.UUID) (*estate.Property, error) {
+	return r.get(ctx, QueryGetProperty, id)
+}
+
+// get retrieves a complete Property aggregate with a query by ID.
+func (r *PropertyRepo) get(ctx context.Context, query string, id uuid.UUID) (*estate.Property, error) {
+	property, err := scanProperty(r.db.QueryRowContext(ctx, query, id.String()))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("Property aggregate with ID %s: %w", id.String(), estate.ErrNotFound)
+		}
+		return nil, fmt.Errorf("could not get Property aggregate: %w", err)
+	}
+
+	if err := r.loadChildren(ctx, []*estate.Property{property}); err != nil {
+		return nil, err
+	}
+
+	if _, err := estate.UpgradeSchema(property); err != nil {
+		return nil, err
+	}
+
+	return property, nil
+}
+
+// Save performs a unit-of-work save operation on the Property aggregate.
+// The root row is updated, the child collections are replaced and a revision
+// is recorded. The root update is a compare-and-swap on the revision, so a
+// stale save changes nothing and the stored version is the one diffed against.
+func (r *PropertyRepo) Save(ctx context.Context, property *estate.Property) error {
+	if property == nil {
+		return fmt.Errorf("property cannot be nil")
+	}
+
+	before, err := r.Get(ctx, property.GetID())
+	if err != nil {
+		return err
+	}
+	if before.Revision != property.Revision {
+		return fmt.Errorf("Property aggregate with ID %s: %w", property.GetID(), estate.ErrRevisionConflict)
+	}
+
+	property.BeforeUpdate()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := r.update(ctx, tx, QueryUpdateProperty, property); err != nil {
+		return err
+	}
+
+	after := *property
+	after.Revision++
+	if err := insertRevision(ctx, tx, estate.ActionUpdate, before, &after); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("could not commit transaction: %w", err)
+	}
+
+	property.Revision++
+	return nil
+}
+
+// update runs a conditional root update and replaces the child collections.
+func (r *PropertyRepo) update(ctx context.Context, tx *sql.Tx, query string, property *estate.Property) error {
+	args, err := updateArgs(property)
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("could not save Property aggregate: %w", err)
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return revisionError(ctx, tx, property.GetID().String())
+	}
+
+	if _, err := tx.ExecContext(ctx, QueryDeletePrices, property.GetID().String()); err != nil {
+		return fmt.Errorf("could not clear prices: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, QueryDeleteAmenities, property.GetID().String()); err != nil {
+		return fmt.Errorf("could not clear amenities: %w", err)
+	}
+
+	return r.insertChildren(ctx, tx, property)
+}
+
+// Delete moves the Property aggregate to the trash and records the deleted
+// state as a revision. A non-zero revision must match the stored one. The
+// rows are kept until Purge.
+func (r *PropertyRepo) Delete(ctx context.Context, id uuid.UUID, revision int64) error {
+	before, err := r.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if revision == 0 {
+		revision = before.Revision
+	}
+	if before.Revision != revision {
+		return fmt.Errorf("Property aggregate with ID %s: %w", id.String(), estate.ErrRevisionConflict)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	after := before.Trashed(estate.ActorFrom(ctx), time.Now().UTC())
+	result, err := tx.ExecContext(ctx, QueryTrashProperty, *after.DeletedAt, after.DeletedBy, id.String(), revision)
+	if err != nil {
+		return fmt.Errorf("could not delete Property aggregate: %w", err)
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return revisionError(ctx, tx, id.String())
+	}
+
+	if err := insertRevision(ctx, tx, estate.ActionDelete, before, nil); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("could not commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// Undelete takes the Property aggregate out of the trash and records the
+// restored state as a revision. A non-zero revision must match the stored one.
+func (r *PropertyRepo) Undelete(ctx context.Context, id uuid.UUID, revision int64) error {
+	before, err := r.get(ctx, QueryGetStoredProperty, id)
+	if err != nil {
+		return err
+	}
+	if before.DeletedAt == nil {
+		return fmt.Errorf("Property aggregate with ID %s in trash: %w", id.String(), estate.ErrNotFound)
+	}
+	if revision == 0 {
+		revision = before.Revision
+	}
+	if before.Revision != revision {
+		return fmt.Errorf("Property aggregate with ID %s: %w", id.String(), estate.ErrRevisionConflict)
+	}
+
+	after := before.Untrashed(estate.ActorFrom(ctx), time.Now())
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, QueryUntrashProperty, after.UpdatedAt.UTC(), after.UpdatedBy, id.String(), revision)
+	if err != nil {
+		return fmt.Errorf("could not restore Property aggregate: %w", err)
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("Property aggregate with ID %s: %w", id.String(), estate.ErrRevisionConflict)
+	}
+
+	if err := insertRevision(ctx, tx, estate.ActionUndelete, before, after); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("could not commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// Purge deletes the rows of the properties moved to the trash before the
+// given time; prices, amenities, media and status history cascade. Their
+// listings, leases, appointments and inquiries are deleted in the same
+// transaction. Pending events stay in the outbox for the relay.
+func (r *PropertyRepo) Purge(ctx context.Context, before time.Time) ([]uuid.UUID, error) {
+	rows, err := r.db.QueryContext(ctx, QueryListPurgeable, before.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("could not list purgeable properties: %w", err)
+	}
+
+	var candidates []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("could not scan property ID: %w", err)
+		}
+		candidates = append(candidates, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error while listing purgeable properties: %w", err)
+	}
+
+	var purged []uuid.UUID
+	for _, raw := range candidates {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return purged, fmt.Errorf("invalid property ID %q: %w", raw, err)
+		}
+
+		ok, err := r.purgeProperty(ctx, raw, before)
+		if err != nil {
+			return purged, err
+		}
+		if ok {
+			purged = append(purged, id)
+		}
+	}
+
+	return purged, nil
+}
+
+// purgeQueries delete the rows of the other aggregates of a purged property.
+var purgeQueries = []string{QueryPurgeListings, QueryPurgeLeases, QueryPurgeAppointments, QueryPurgeInquiries}
+
+// purgeProperty deletes a property moved to the trash before the given
+// time and everything recorded about it. It returns false if the property
+// was restored meanwhile.
+func (r *PropertyRepo) purgeProperty(ctx context.Context, id string, before time.Time) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, QueryPurgeProperty, id, before.UTC())
+	if err != nil {
+		return false, fmt.Errorf("could not purge Property aggregate %s: %w", id, err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return false, nil
+	}
+
+	for _, query := range purgeQueries {
+		if _, err := tx.ExecContext(ctx, query, id); err != nil {
+			return false, fmt.Errorf("could not purge Property aggregate %s: %w", id, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("could not commit transaction: %w", err)
+	}
+	return true, nil
+}
+
+// querier is satisfied by *sql.DB and *sql.Tx.
+type querier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// revisionError explains why a conditional write matched no row: the
+// property is either missing or at another revision.
+func revisionError(ctx context.Context, q querier, id string) error {
+	var exists int
+	err := q.QueryRowContext(ctx, QueryPropertyExists, id).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("Property aggregate with ID %s: %w", id, estate.ErrNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("could not check property: %w", err)
+	}
+	return fmt.Errorf("Property aggregate with ID %s: %w", id, estate.ErrRevisionConflict)
+}
+
+// List retrieves all Property aggregates from SQLite.
+func (r *PropertyRepo) List(ctx context.Context) ([]*estate.Property, error) {
+	return r.list(ctx, QueryListProperties)
+}
+
+// ListByOwner retrieves all properties for a specific owner.
+func (r *PropertyRepo) ListByOwner(ctx context.Context, ownerID string) ([]*estate.Property, error) {
+	return r.list(ctx, QueryListPropertiesByOwner, ownerID)
+}
+
+// ListByStatus retrieves all properties with a specific status.
+func (r *PropertyRepo) ListByStatus(ctx context.Context, status string) ([]*estate.Property, error) {
+	return r.list(ctx, QueryListPropertiesByStatus, status)
+}
+
+func (r *PropertyRepo) list(ctx context.Context, query string, args ...any) ([]*estate.Property, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("could not list Property aggregates: %w", err)
+	}
+	defer rows.Close()
+
+	var properties []*estate.Property
+	for rows.Next() {
+		property, err := scanProperty(rows)
+		if err != nil {
+			return nil, fmt.Errorf("could not scan Property aggregate: %w", err)
+		}
+		properties = append(properties, property)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error while listing Property aggregates: %w", err)
+	}
+
+	if err := r.loadChildren(ctx, properties); err != nil {
+		return nil, err
+	}
+
+	for _, property := range properties {
+		if _, err := estate.UpgradeSchema(property); err != nil {
+			return nil, err
+		}
+	}
+
+	return properties, nil
+}
+
+func (r *PropertyRepo) insertChildren(ctx context.Context, tx *sql.Tx, property *estate.Property) error {
+	id := property.GetID().String()
+
+	for i, price := range property.Prices {
+		if _, err := tx.ExecContext(ctx, QueryCreatePrice,
+			id, i, price.Amount, price.Currency, price.Type, price.Negotiable,
+		); err != nil {
+			return fmt.Errorf("could not insert price: %w", err)
+		}
+	}
+
+	for i, amenity := range property.Features.Amenities {
+		if _, err := tx.ExecContext(ctx, QueryCreateAmenity, id, i, amenity); err != nil {
+			return fmt.Errorf("could not insert amenity: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// loadChildren populates Prices and Amenities for the given properties with
+// one query per child table and chunk of at most estate.MaxSearchLimit
+// properties, keeping each IN list well under SQLite's variable limit.
+func (r *PropertyRepo) loadChildren(ctx context.Context, properties []*estate.Property) error {
+	for chunk := range slices.Chunk(properties, estate.MaxSearchLimit) {
+		if err := r.loadChildrenChunk(ctx, chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *PropertyRepo) loadChildrenChunk(ctx context.Context, properties []*estate.Property) error {
+	byID := make(map[string]*estate.Property, len(properties))
+	args := make([]any, 0, len(properties))
+	for _, p := range properties {
+		byID[p.ID.String()] = p
+		args = append(args, p.ID.String())
+	}
+	in := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(args)), ", ") + ")"
+
+	priceRows, err := r.db.QueryContext(ctx, QueryListPricesIn+in+" ORDER BY property_id, position", args...)
+	if err != nil {
+		return fmt.Errorf("could not load prices: %w", err)
+	}
+	defer priceRows.Close()
+
+	for priceRows.Next() {
+		var propertyID string
+		var price estate.Price
+		if err := priceRows.Scan(&propertyID, &price.Amount, &price.Currency, &price.Type, &price.Negotiable); err != nil {
+			return fmt.Errorf("could not scan price: %w", err)
+		}
+		if p, ok := byID[propertyID]; ok {
+			p.Prices = append(p.Prices, price)
+		}
+	}
+	if err := priceRows.Err(); err != nil {
+		return fmt.Errorf("rows error while loading prices: %w", err)
+	}
+
+	amenityRows, err := r.db.QueryContext(ctx, QueryListAmenitiesIn+in+" ORDER BY property_id, position", args...)
+	if err != nil {
+		return fmt.Errorf("could not load amenities: %w", err)
+	}
+	defer amenityRows.Close()
+
+	for amenityRows.Next() {
+		var propertyID, amenity string
+		if err := amenityRows.Scan(&propertyID, &amenity); err != nil {
+			return fmt.Errorf("could not scan amenity: %w", err)
+		}
+		if p, ok := byID[propertyID]; ok {
+			p.Features.Amenities = append(p.Features.Amenities, amenity)
+		}
+	}
+	if err := amenityRows.Err(); err != nil {
+		return fmt.Errorf("rows error while loading amenities: %w", err)
+	}
+
+	return nil
+}
+
+// rowScanner is satisfied by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanProperty(row rowScanner) (*estate.Property, error) {
+	var (
+		p                               estate.Property
+		id, categoryID, typeID, subtype string
+		developmentID                   string
+		raw                             string
+		valuation                       valuationColumns
+		deletedAt                       sql.NullTime
+	)
+
+	loc := &p.Location
+	addr := &p.Location.Address
+	f := &p.Features
+
+	err := row.Scan(
+		&id, &p.Name, &p.Description,
+		&categoryID, &typeID, &subtype,
+		&addr.Street, &addr.Number, &addr.Unit, &addr.City, &addr.State, &addr.PostalCode, &addr.Country,
+		&loc.Coordinates.Latitude, &loc.Coordinates.Longitude, &loc.Region, &loc.Provider, &loc.ProviderURL, &loc.ProviderRef, &raw, &loc.DisplayName,
+		&f.TotalArea, &f.CoveredArea, &f.LandArea, &f.Bedrooms, &f.Bathrooms, &f.HalfBaths, &f.Rooms,
+		&f.Parking, &f.CoveredParking, &f.Floors, &f.Floor, &f.YearBuilt, &f.Condition,
+		&f.Pool, &f.Garden, &f.Balcony, &f.Terrace, &f.Elevator, &f.AirConditioning, &f.Heating,
+		&f.Furnished, &f.PetFriendly, &f.Storage, &f.Laundry, &f.Fireplace,
+		&valuation.currency, &valuation.priceType, &valuation.amount, &valuation.perM2, &valuation.rateDate,
+		&p.Status, &p.OwnerID, &p.TeamID, &developmentID, &p.SchemaVersion, &p.Revision, &p.CreatedAt, &p.CreatedBy, &p.UpdatedAt, &p.UpdatedBy,
+		&deletedAt, &p.DeletedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.ID, err = uuid.Parse(id); err != nil {
+		return nil, fmt.Errorf("invalid property ID %q: %w", id, err)
+	}
+	if deletedAt.Valid {
+		p.DeletedAt = &deletedAt.Time
+	}
+	p.Classification.CategoryID = parseOptionalUUID(categoryID)
+	p.Classification.TypeID = parseOptionalUUID(typeID)
+	p.Classification.SubtypeID = parseOptionalUUID(subtype)
+	if id := parseOptionalUUID(developmentID); id != uuid.Nil {
+		p.DevelopmentID = &id
+	}
+
+	if raw != "" {
+		if err := json.Unmarshal([]byte(raw), &loc.Raw); err != nil {
+			return nil, fmt.Errorf("invalid location raw payload: %w", err)
+		}
+	}
+
+	if p.Valuation, err = valuation.decode(); err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+// Timestamps are stored in UTC so that their text form sorts chronologically.
+// Properties are created out of the trash.
+func createArgs(p *estate.Property) ([]any, error) {
+	raw, err := encodeRaw(p.Location.Raw)
+	if err != nil {
+		return nil, err
+	}
+
+	args := []any{p.ID.String(), p.Name, p.Description}
+	args = append(args, valueArgs(p, raw)...)
+	return append(args,
+		p.Status, p.OwnerID, p.TeamID, formatUUIDRef(p.DevelopmentID), p.SchemaVersion, p.Revision, p.CreatedAt.UTC(), p.CreatedBy, p.UpdatedAt.UTC(), p.UpdatedBy,
+		nil, "",
+		propertyGeohash(p),
+	), nil
+}
+
+func updateArgs(p *estate.Property) ([]any, error) {
+	raw, err := encodeRaw(p.Location.Raw)
+	if err != nil {
+		return nil, err
+	}
+
+	args := []any{p.Name, p.Description}
+	args = append(args, valueArgs(p, raw)...)
+	return append(args,
+		p.Status, p.OwnerID, p.TeamID, formatUUIDRef(p.DevelopmentID), p.SchemaVersion, p.UpdatedAt.UTC(), p.UpdatedBy,
+		propertyGeohash(p),
+		p.ID.String(), p.Revision,
+	), nil
+}
+
+// valueArgs returns the Classification, Location, Features and Valuation columns in table order.
+func valueArgs(p *estate.Property, raw string) []any {
+	c := p.Classification
+	loc := p.Location
+	addr := p.Location.Address
+	f := p.Features
+
+	return append([]any{
+		formatOptionalUUID(c.CategoryID), formatOptionalUUID(c.TypeID), formatOptionalUUID(c.SubtypeID),
+		addr.Street, addr.Number, addr.Unit, addr.City, addr.State, addr.PostalCode, addr.Country,
+		loc.Coordinates.Latitude, loc.Coordinates.Longitude, loc.Region, loc.Provider, loc.ProviderURL, loc.ProviderRef, raw, loc.DisplayName,
+		f.TotalArea, f.CoveredArea, f.LandArea, f.Bedrooms, f.Bathrooms, f.HalfBaths, f.Rooms,
+		f.Parking, f.CoveredParking, f.Floors, f.Floor, f.YearBuilt, f.Condition,
+		f.Pool, f.Garden, f.Balcony, f.Terrace, f.Elevator, f.AirConditioning, f.Heating,
+		f.Furnished, f.PetFriendly, f.Storage, f.Laundry, f.Fireplace,
+	}, valuationArgs(p.Valuation)...)
+}
+
+func encodeRaw(raw map[string]any) (string, error) {
+	if len(raw) == 0 {
+		return "", nil
+	}
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return "", fmt.Errorf("cannot encode location raw payload: %w", err)
+	}
+	return string(b), nil
+}
+
+func formatOptionalUUID(id uuid.UUID) string {
+	if id == uuid.Nil {
+		return ""
+	}
+	return id.String()
+}
+
+func formatUUIDRef(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	return formatOptionalUUID(*id)
+}
+
+func parseOptionalUUID(s string) uuid.UUID {
+	if s == "" {
+		return uuid.Nil
+	}
+	id, err := uuid.Parse(s)
+	if err != nil {
+		return uuid.Nil
+	}
+	return id
+}
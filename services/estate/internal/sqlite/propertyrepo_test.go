@@ -0,0 +1,119 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/pulap/pulap/pkg/lib/core"
+	"github.com/pulap/pulap/services/estate/internal/config"
+	"github.com/pulap/pulap/services/estate/internal/estate"
+	"github.com/pulap/pulap/services/estate/internal/estate/repotest"
+)
+
+func TestPropertyRepo(t *testing.T) {
+	repotest.RunPropertyRepo(t, func(t *testing.T) estate.Repo {
+		return newTestRepo(t)
+	})
+}
+
+func TestPropertyRepoMigrateIsIdempotent(t *testing.T) {
+	repo := newTestRepo(t)
+
+	if err := migrate(context.Background(), repo.db); err != nil {
+		t.Fatalf("second migrate: %v", err)
+	}
+}
+
+func TestPropertyRepoMigrateSchema(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+
+	legacy := repotest.NewProperty("Mayor 12")
+	current := repotest.NewProperty("Mayor 14")
+	for _, p := range []*estate.Property{legacy, current} {
+		if err := repo.Create(ctx, p); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+	if _, err := repo.db.ExecContext(ctx, `UPDATE properties SET schema_version = 0 WHERE id = ?`, legacy.ID.String()); err != nil {
+		t.Fatalf("unversion: %v", err)
+	}
+
+	report, err := repo.MigrateSchema(ctx)
+	if err != nil {
+		t.Fatalf("MigrateSchema: %v", err)
+	}
+	if report.Scanned != 1 || report.Upgraded != 1 || len(report.Failures) != 0 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+
+	var version int
+	row := repo.db.QueryRowContext(ctx, `SELECT schema_version FROM properties WHERE id = ?`, legacy.ID.String())
+	if err := row.Scan(&version); err != nil {
+		t.Fatalf("scan version: %v", err)
+	}
+	if version != estate.Schema.Current() {
+		t.Errorf("expected stored v%d, got v%d", estate.Schema.Current(), version)
+	}
+
+	got, err := repo.Get(ctx, legacy.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.Revision != legacy.Revision {
+		t.Errorf("expected property at revision %d, got %d", legacy.Revision, got.Revision)
+	}
+
+	if _, err := repo.db.ExecContext(ctx, `UPDATE properties SET schema_version = 1 WHERE id = ?`, current.ID.String()); err != nil {
+		t.Fatalf("downgrade: %v", err)
+	}
+	report, err = repo.MigrateSchema(ctx)
+	if err != nil {
+		t.Fatalf("second MigrateSchema: %v", err)
+	}
+	if report.Scanned != 1 || report.Upgraded != 0 || len(report.Failures) != 1 || report.Failures[0].ID != current.ID {
+		t.Errorf("expected the unknown version to be reported, got %+v", report)
+	}
+}
+
+func TestPropertyRepoListLoadsChildrenInChunks(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+
+	count := estate.MaxSearchLimit + 1
+	for i := range count {
+		if err := repo.Create(ctx, repotest.NewProperty(fmt.Sprintf("Mayor %d", i))); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	properties, err := repo.List(ctx)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(properties) != count {
+		t.Fatalf("expected %d properties, got %d", count, len(properties))
+	}
+	for _, p := range properties {
+		if len(p.Prices) == 0 || len(p.Features.Amenities) == 0 {
+			t.Fatalf("expected prices and amenities loaded for %s, got %+v", p.Name, p)
+		}
+	}
+}
+
+func newTestRepo(t *testing.T) *PropertyRepo {
+	t.Helper()
+
+	cfg := config.New()
+	cfg.Database.Path = filepath.Join(t.TempDir(), "estate.db")
+	repo := NewPropertyRepo(config.NewXParams(core.NewNoopLogger(), cfg))
+
+	ctx := context.Background()
+	if err := repo.Start(ctx); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() { repo.Stop(ctx) })
+	return repo
+}
@@ -0,0 +1,83 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/services/estate/internal/estate"
+)
+
+// MigrateSchema rewrites every property stored with an older schema version
+// in its upgraded form. Each property is rewritten in its own transaction
+// and keeps its revision; properties that fail to upgrade, or that change
+// while being rewritten, are reported and left as they are.
+func (r *PropertyRepo) MigrateSchema(ctx context.Context) (*estate.SchemaReport, error) {
+	ids, err := r.outdatedSchemaIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &estate.SchemaReport{Scanned: len(ids)}
+	for _, id := range ids {
+		if err := r.rewrite(ctx, id); err != nil {
+			report.Failures = append(report.Failures, estate.SchemaFailure{ID: id, Error: err.Error()})
+			continue
+		}
+		report.Upgraded++
+	}
+
+	return report, nil
+}
+
+func (r *PropertyRepo) outdatedSchemaIDs(ctx context.Context) ([]uuid.UUID, error) {
+	rows, err := r.db.QueryContext(ctx, QueryListOutdatedSchema, estate.Schema.Current())
+	if err != nil {
+		return nil, fmt.Errorf("could not list outdated properties: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var raw string
+		if err := rows.Scan(&raw); err != nil {
+			return nil, fmt.Errorf("could not scan property ID: %w", err)
+		}
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid property ID %q: %w", raw, err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating outdated properties: %w", err)
+	}
+
+	return ids, nil
+}
+
+// rewrite stores the upgraded form returned by get, in the trash or not.
+func (r *PropertyRepo) rewrite(ctx context.Context, id uuid.UUID) error {
+	property, err := r.get(ctx, QueryGetStoredProperty, id)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := r.update(ctx, tx, QueryRewriteProperty, property); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("could not commit transaction: %w", err)
+	}
+
+	return nil
+}
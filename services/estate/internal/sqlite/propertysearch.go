@@ -0,0 +1,268 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/services/estate/internal/estate"
+	"github.com/pulap/pulap/services/estate/internal/money"
+)
+
+// sortColumns maps sort fields to properties table columns.
+var sortColumns = map[estate.SortField]string{
+	estate.SortCreatedAt:   "created_at",
+	estate.SortUpdatedAt:   "updated_at",
+	estate.SortName:        "name",
+	estate.SortBedrooms:    "bedrooms",
+	estate.SortBathrooms:   "bathrooms",
+	estate.SortTotalArea:   "total_area",
+	estate.SortCoveredArea: "covered_area",
+	estate.SortYearBuilt:   "year_built",
+	estate.SortPrice:       "CAST(valuation_amount AS REAL)",
+	estate.SortPricePerM2:  "CAST(valuation_per_m2 AS REAL)",
+	estate.SortDeletedAt:   "deleted_at",
+}
+
+// flagColumns maps amenity flags to properties table columns.
+var flagColumns = map[string]string{
+	"pool":             "pool",
+	"garden":           "garden",
+	"balcony":          "balcony",
+	"terrace":          "terrace",
+	"elevator":         "elevator",
+	"air_conditioning": "air_conditioning",
+	"heating":          "heating",
+	"furnished":        "furnished",
+	"pet_friendly":     "pet_friendly",
+	"storage":          "storage",
+	"laundry":          "laundry",
+	"fireplace":        "fireplace",
+}
+
+// Search retrieves a page of properties matching the query.
+// Pagination is keyset-based: the cursor holds the sort values of the last
+// returned row and the id breaks ties.
+func (r *PropertyRepo) Search(ctx context.Context, query estate.PropertyQuery) (*estate.PropertyPage, error) {
+	where := &whereBuilder{}
+	searchFilter(where, query)
+
+	var total int64
+	if err := r.db.QueryRowContext(ctx, QueryCountProperties+where.String(), where.args...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("could not count properties: %w", err)
+	}
+
+	cursorValues, err := query.CursorValues()
+	if err != nil {
+		return nil, err
+	}
+	if cursorValues != nil {
+		keysetFilter(where, query.Sort, cursorValues)
+	}
+
+	stmt := QuerySearchProperties + where.String() + searchOrder(query.Sort) + " LIMIT ?"
+	properties, err := r.list(ctx, stmt, append(where.args, query.Limit+1)...)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &estate.PropertyPage{Items: properties, Total: total}
+	if len(properties) > query.Limit {
+		page.Items = properties[:query.Limit]
+		page.NextCursor = estate.NextCursor(page.Items[query.Limit-1], query.Sort)
+	}
+
+	return page, nil
+}
+
+// whereBuilder accumulates AND-ed conditions and their arguments.
+type whereBuilder struct {
+	conds []string
+	args  []any
+}
+
+func (w *whereBuilder) add(cond string, args ...any) {
+	w.conds = append(w.conds, cond)
+	w.args = append(w.args, args...)
+}
+
+func (w *whereBuilder) String() string {
+	if len(w.conds) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(w.conds, " AND ")
+}
+
+func searchFilter(w *whereBuilder, q estate.PropertyQuery) {
+	if q.Deleted {
+		w.add("deleted_at IS NOT NULL")
+	} else {
+		w.add("deleted_at IS NULL")
+	}
+	if len(q.IDs) > 0 {
+		w.add("id IN "+placeholders(len(q.IDs)), uuidArgs(q.IDs)...)
+	}
+	if q.OwnerID != "" {
+		w.add("owner_id = ?", q.OwnerID)
+	}
+	if q.TeamID != "" {
+		w.add("team_id = ?", q.TeamID)
+	}
+	if q.Scoped {
+		if len(q.Scopes) == 0 {
+			w.add("0")
+		} else {
+			in := placeholders(len(q.Scopes))
+			args := stringArgs(q.Scopes)
+			w.add("(owner_id IN "+in+" OR team_id IN "+in+")", append(args, args...)...)
+		}
+	}
+	if len(q.Statuses) > 0 {
+		w.add("status IN "+placeholders(len(q.Statuses)), stringArgs(q.Statuses)...)
+	}
+
+	if len(q.CategoryIDs) > 0 {
+		w.add("category_id IN "+placeholders(len(q.CategoryIDs)), uuidArgs(q.CategoryIDs)...)
+	}
+	if len(q.TypeIDs) > 0 {
+		w.add("type_id IN "+placeholders(len(q.TypeIDs)), uuidArgs(q.TypeIDs)...)
+	}
+	if len(q.SubtypeIDs) > 0 {
+		w.add("subtype_id IN "+placeholders(len(q.SubtypeIDs)), uuidArgs(q.SubtypeIDs)...)
+	}
+
+	if len(q.DevelopmentIDs) > 0 {
+		w.add("development_id IN "+placeholders(len(q.DevelopmentIDs)), uuidArgs(q.DevelopmentIDs)...)
+	}
+
+	if q.City != "" {
+		w.add("city = ? COLLATE "+collationNoCaseUnicode, q.City)
+	}
+	if q.Country != "" {
+		w.add("country = ? COLLATE "+collationNoCaseUnicode, q.Country)
+	}
+
+	if q.Price != nil {
+		conds := []string{"pp.property_id = properties.id"}
+		var args []any
+		if q.Price.Type != "" {
+			conds = append(conds, "pp.type = ?")
+			args = append(args, q.Price.Type)
+		}
+		if q.Price.Currency != "" {
+			conds = append(conds, "pp.currency = ?")
+			args = append(args, q.Price.Currency)
+		}
+		if q.Price.Min != nil {
+			conds = append(conds, "CAST(pp.amount AS REAL) >= ?")
+			args = append(args, *q.Price.Min)
+		}
+		if q.Price.Max != nil {
+			conds = append(conds, "CAST(pp.amount AS REAL) <= ?")
+			args = append(args, *q.Price.Max)
+		}
+		w.add("EXISTS (SELECT 1 FROM property_prices pp WHERE "+strings.Join(conds, " AND ")+")", args...)
+	}
+
+	addRange(w, "bedrooms", q.Bedrooms.Min, q.Bedrooms.Max)
+	addRange(w, "bathrooms", q.Bathrooms.Min, q.Bathrooms.Max)
+	addRange(w, "year_built", q.YearBuilt.Min, q.YearBuilt.Max)
+	addRange(w, "total_area", q.TotalArea.Min, q.TotalArea.Max)
+	addRange(w, "covered_area", q.CoveredArea.Min, q.CoveredArea.Max)
+
+	for _, flag := range q.Flags {
+		if column, ok := flagColumns[flag]; ok {
+			w.add(column + " = 1")
+		}
+	}
+	for _, amenity := range q.Amenities {
+		w.add("EXISTS (SELECT 1 FROM property_amenities pa WHERE pa.property_id = properties.id AND pa.amenity = ?)", amenity)
+	}
+}
+
+// keysetFilter restricts rows to those positioned after the cursor values:
+// (a > va) OR (a = va AND b > vb) OR ... OR (a = va AND ... AND id > id0).
+func keysetFilter(w *whereBuilder, sort []estate.SortOrder, values []any) {
+	columns := make([]string, 0, len(sort)+1)
+	ops := make([]string, 0, len(sort)+1)
+	for _, s := range sort {
+		columns = append(columns, sortColumns[s.Field])
+		ops = append(ops, compareOp(s.Desc))
+	}
+	columns = append(columns, "id")
+	ops = append(ops, compareOp(false))
+
+	var or []string
+	var args []any
+	for i := range columns {
+		var and []string
+		for j := 0; j < i; j++ {
+			and = append(and, columns[j]+" = ?")
+			args = append(args, keysetArg(values[j]))
+		}
+		and = append(and, columns[i]+" "+ops[i]+" ?")
+		args = append(args, keysetArg(values[i]))
+		or = append(or, "("+strings.Join(and, " AND ")+")")
+	}
+
+	w.add("("+strings.Join(or, " OR ")+")", args...)
+}
+
+// keysetArg converts decimal cursor values to the REAL the price columns are
+// compared as.
+func keysetArg(v any) any {
+	if d, ok := v.(money.Decimal); ok {
+		return d.Float64()
+	}
+	return v
+}
+
+func searchOrder(sort []estate.SortOrder) string {
+	terms := make([]string, 0, len(sort)+1)
+	for _, s := range sort {
+		direction := "ASC"
+		if s.Desc {
+			direction = "DESC"
+		}
+		terms = append(terms, sortColumns[s.Field]+" "+direction)
+	}
+	return " ORDER BY " + strings.Join(append(terms, "id ASC"), ", ")
+}
+
+func compareOp(desc bool) string {
+	if desc {
+		return "<"
+	}
+	return ">"
+}
+
+func addRange[T int | float64](w *whereBuilder, column string, min, max *T) {
+	if min != nil {
+		w.add(column+" >= ?", *min)
+	}
+	if max != nil {
+		w.add(column+" <= ?", *max)
+	}
+}
+
+func placeholders(n int) string {
+	return "(" + strings.TrimSuffix(strings.Repeat("?, ", n), ", ") + ")"
+}
+
+func stringArgs(values []string) []any {
+	args := make([]any, 0, len(values))
+	for _, v := range values {
+		args = append(args, v)
+	}
+	return args
+}
+
+func uuidArgs(ids []uuid.UUID) []any {
+	args := make([]any, 0, len(ids))
+	for _, id := range ids {
+		args = append(args, id.String())
+	}
+	return args
+}
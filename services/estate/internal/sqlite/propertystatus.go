@@ -0,0 +1,101 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/pulap/pulap/services/estate/internal/estate"
+)
+
+// Transition updates the property status, appends the transition to its
+// history and records a revision in a single transaction. The update only
+// applies while the stored status is still t.From and the property has not
+// been modified since it was read for the revision diff.
+func (r *PropertyRepo) Transition(ctx context.Context, t *estate.StatusTransition) error {
+	if t == nil {
+		return fmt.Errorf("transition cannot be nil")
+	}
+
+	id := t.PropertyID.String()
+	before, err := r.Get(ctx, t.PropertyID)
+	if err != nil {
+		return err
+	}
+	if before.Status != t.From {
+		return fmt.Errorf("Property aggregate with ID %s: %w", id, estate.ErrStatusConflict)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, QueryUpdatePropertyStatus, t.To, t.At.UTC(), t.Actor, id, t.From, before.Revision)
+	if err != nil {
+		return fmt.Errorf("could not update property status: %w", err)
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		var exists int
+		err := tx.QueryRowContext(ctx, QueryPropertyExists, id).Scan(&exists)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("Property aggregate with ID %s: %w", id, estate.ErrNotFound)
+		}
+		if err != nil {
+			return fmt.Errorf("could not check property: %w", err)
+		}
+		return fmt.Errorf("Property aggregate with ID %s: %w", id, estate.ErrStatusConflict)
+	}
+
+	_, err = tx.ExecContext(ctx, QueryCreateStatusTransition,
+		t.ID.String(), id, t.From, t.To, t.Reason, t.Actor, t.Override, t.At.UTC())
+	if err != nil {
+		return fmt.Errorf("could not record status transition: %w", err)
+	}
+
+	if err := insertRevision(ctx, tx, estate.ActionTransition, before, before.Transitioned(t)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("could not commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// StatusHistory retrieves the status transitions of a property, oldest first.
+func (r *PropertyRepo) StatusHistory(ctx context.Context, id uuid.UUID) ([]estate.StatusTransition, error) {
+	rows, err := r.db.QueryContext(ctx, QueryListStatusTransitions, id.String())
+	if err != nil {
+		return nil, fmt.Errorf("could not list status transitions: %w", err)
+	}
+	defer rows.Close()
+
+	var history []estate.StatusTransition
+	for rows.Next() {
+		var t estate.StatusTransition
+		var transitionID, propertyID string
+		if err := rows.Scan(&transitionID, &propertyID, &t.From, &t.To, &t.Reason, &t.Actor, &t.Override, &t.At); err != nil {
+			return nil, fmt.Errorf("could not scan status transition: %w", err)
+		}
+		if t.ID, err = uuid.Parse(transitionID); err != nil {
+			return nil, fmt.Errorf("invalid status transition ID: %w", err)
+		}
+		if t.PropertyID, err = uuid.Parse(propertyID); err != nil {
+			return nil, fmt.Errorf("invalid property ID: %w", err)
+		}
+		history = append(history, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating status transitions: %w", err)
+	}
+
+	return history, nil
+}
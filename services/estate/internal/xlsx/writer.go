@@ -0,0 +1,212 @@
+// Package xlsx writes single sheet Office Open XML workbooks as a stream:
+// rows are written to the output as they come, so exports of any size use
+// constant memory. Cells are inline strings, numbers or booleans; there are
+// no styles, formulas or shared strings.
+package xlsx
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ContentType is the media type of a workbook.
+const ContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// Sheet names are limited by Excel.
+const maxSheetName = 31
+
+// Number is a cell holding a numeric literal, such as an exact decimal
+// amount, written as is.
+type Number string
+
+// ErrClosed is returned when writing to a closed Writer.
+var ErrClosed = errors.New("xlsx: writer is closed")
+
+// Writer writes the rows of a single sheet workbook.
+type Writer struct {
+	zw     *zip.Writer
+	sheet  *bufio.Writer
+	row    int
+	closed bool
+}
+
+// NewWriter starts a workbook with one sheet named sheetName on w. The
+// caller must Close the Writer to complete the file.
+func NewWriter(w io.Writer, sheetName string) (*Writer, error) {
+	zw := zip.NewWriter(w)
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", contentTypesXML},
+		{"_rels/.rels", relsXML},
+		{"xl/workbook.xml", fmt.Sprintf(workbookXML, escape(sheetTitle(sheetName)))},
+		{"xl/_rels/workbook.xml.rels", workbookRelsXML},
+	}
+	for _, part := range parts {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return nil, err
+		}
+	}
+
+	// The sheet is the last part, so it can be written row by row
+	f, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	sheet := bufio.NewWriter(f)
+	if _, err := sheet.WriteString(sheetHeaderXML); err != nil {
+		return nil, err
+	}
+	return &Writer{zw: zw, sheet: sheet}, nil
+}
+
+// WriteRow appends a row. Values may be strings, Numbers, integers,
+// floats, booleans, times (written as RFC 3339 text) or nil for an empty
+// cell; other values are written with fmt.Sprint.
+func (w *Writer) WriteRow(values ...any) error {
+	if w.closed {
+		return ErrClosed
+	}
+	w.row++
+
+	b := w.sheet
+	fmt.Fprintf(b, `<row r="%d">`, w.row)
+	for i, v := range values {
+		ref := ColumnName(i) + strconv.Itoa(w.row)
+		switch v := v.(type) {
+		case nil:
+			continue
+		case string:
+			writeString(b, ref, v)
+		case Number:
+			writeNumber(b, ref, string(v))
+		case int:
+			writeNumber(b, ref, strconv.Itoa(v))
+		case int64:
+			writeNumber(b, ref, strconv.FormatInt(v, 10))
+		case float64:
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				continue
+			}
+			writeNumber(b, ref, strconv.FormatFloat(v, 'f', -1, 64))
+		case bool:
+			val := "0"
+			if v {
+				val = "1"
+			}
+			fmt.Fprintf(b, `<c r="%s" t="b"><v>%s</v></c>`, ref, val)
+		case time.Time:
+			if v.IsZero() {
+				continue
+			}
+			writeString(b, ref, v.UTC().Format(time.RFC3339))
+		default:
+			writeString(b, ref, fmt.Sprint(v))
+		}
+	}
+	_, err := b.WriteString("</row>")
+	return err
+}
+
+// Flush writes buffered rows to the underlying writer.
+func (w *Writer) Flush() error {
+	if w.closed {
+		return ErrClosed
+	}
+	if err := w.sheet.Flush(); err != nil {
+		return err
+	}
+	return w.zw.Flush()
+}
+
+// Close completes the sheet and the workbook. It does not close the
+// underlying writer.
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	if _, err := w.sheet.WriteString(sheetFooterXML); err != nil {
+		return err
+	}
+	if err := w.sheet.Flush(); err != nil {
+		return err
+	}
+	return w.zw.Close()
+}
+
+// ColumnName returns the letters of a zero based column index: A, B, ...
+// Z, AA, AB and so on.
+func ColumnName(i int) string {
+	name := ""
+	for i >= 0 {
+		name = string(rune('A'+i%26)) + name
+		i = i/26 - 1
+	}
+	return name
+}
+
+func writeString(b *bufio.Writer, ref, s string) {
+	fmt.Fprintf(b, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, escape(s))
+}
+
+func writeNumber(b *bufio.Writer, ref, n string) {
+	fmt.Fprintf(b, `<c r="%s"><v>%s</v></c>`, ref, n)
+}
+
+// escape escapes XML text, replacing characters XML cannot hold.
+func escape(s string) string {
+	var sb strings.Builder
+	_ = xml.EscapeText(&sb, []byte(s))
+	return sb.String()
+}
+
+// sheetTitle removes the characters Excel rejects in sheet names.
+func sheetTitle(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return -1
+		}
+		return r
+	}, name)
+	if name == "" {
+		return "Sheet1"
+	}
+	if r := []rune(name); len(r) > maxSheetName {
+		name = string(r[:maxSheetName])
+	}
+	return name
+}
+
+const contentTypesXML = xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+	`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+	`</Types>`
+
+const relsXML = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+const workbookXML = xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+	`<sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets>` +
+	`</workbook>`
+
+const workbookRelsXML = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+	`</Relationships>`
+
+const sheetHeaderXML = xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+
+const sheetFooterXML = `</sheetData></worksheet>`
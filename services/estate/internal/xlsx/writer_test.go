@@ -0,0 +1,104 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestColumnName(t *testing.T) {
+	tests := []struct {
+		index int
+		want  string
+	}{
+		{0, "A"},
+		{25, "Z"},
+		{26, "AA"},
+		{51, "AZ"},
+		{52, "BA"},
+		{701, "ZZ"},
+		{702, "AAA"},
+	}
+	for _, tt := range tests {
+		if got := ColumnName(tt.index); got != tt.want {
+			t.Errorf("ColumnName(%d): expected %s, got %s", tt.index, tt.want, got)
+		}
+	}
+}
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, "Properties: all/2024")
+	if err != nil {
+		t.Fatalf("NewWriter: %v", err)
+	}
+	rows := [][]any{
+		{"name", "area", "price", "pool"},
+		{"Piso <Centro> & co", 85.5, Number("250000.00"), true},
+		{" padded ", nil, 3, false, time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), math.NaN()},
+	}
+	for _, row := range rows {
+		if err := w.WriteRow(row...); err != nil {
+			t.Fatalf("WriteRow: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if err := w.WriteRow("late"); err != ErrClosed {
+		t.Errorf("expected ErrClosed after Close, got %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("reading workbook: %v", err)
+	}
+	parts := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("opening %s: %v", f.Name, err)
+		}
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		parts[f.Name] = string(data)
+
+		var v any
+		if err := xml.Unmarshal(data, &v); err != nil {
+			t.Errorf("%s is not well formed: %v", f.Name, err)
+		}
+	}
+
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/worksheets/sheet1.xml"} {
+		if _, ok := parts[name]; !ok {
+			t.Errorf("missing part %s", name)
+		}
+	}
+	if !strings.Contains(parts["xl/workbook.xml"], `name="Properties all2024"`) {
+		t.Errorf("expected a sanitized sheet name, got %s", parts["xl/workbook.xml"])
+	}
+
+	sheet := parts["xl/worksheets/sheet1.xml"]
+	for _, want := range []string{
+		`<c r="A2" t="inlineStr"><is><t xml:space="preserve">Piso &lt;Centro&gt; &amp; co</t></is></c>`,
+		`<c r="B2"><v>85.5</v></c>`,
+		`<c r="C2"><v>250000.00</v></c>`,
+		`<c r="D2" t="b"><v>1</v></c>`,
+		`<c r="C3"><v>3</v></c>`,
+		`<c r="E3" t="inlineStr"><is><t xml:space="preserve">2024-05-01T10:00:00Z</t></is></c>`,
+	} {
+		if !strings.Contains(sheet, want) {
+			t.Errorf("expected sheet to contain %s", want)
+		}
+	}
+	for _, absent := range []string{`r="B3"`, `r="F3"`} {
+		if strings.Contains(sheet, absent) {
+			t.Errorf("expected no cell %s", absent)
+		}
+	}
+}
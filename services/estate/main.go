@@ -2,17 +2,35 @@ package main
 
 import (
 	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
 	"log"
+	"maps"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/go-chi/chi/v5"
+
+	authpkg "github.com/pulap/pulap/pkg/lib/auth"
 	"github.com/pulap/pulap/pkg/lib/core"
+	"github.com/pulap/pulap/services/estate/internal/blob"
+	"github.com/pulap/pulap/services/estate/internal/bus"
 	"github.com/pulap/pulap/services/estate/internal/config"
+	"github.com/pulap/pulap/services/estate/internal/dictionary"
 	"github.com/pulap/pulap/services/estate/internal/estate"
 	"github.com/pulap/pulap/services/estate/internal/fake"
+	"github.com/pulap/pulap/services/estate/internal/kyero"
+	"github.com/pulap/pulap/services/estate/internal/money"
 	"github.com/pulap/pulap/services/estate/internal/mongo"
+	"github.com/pulap/pulap/services/estate/internal/patch"
+	"github.com/pulap/pulap/services/estate/internal/search"
+	"github.com/pulap/pulap/services/estate/internal/sqlite"
 )
 
 const (
@@ -33,26 +51,186 @@ func main() {
 
 	xparams := config.NewXParams(logger, cfg)
 
+	trustedProxies, err := core.ParseTrustedProxies(cfg.Server.TrustedProxies)
+	if err != nil {
+		log.Fatalf("Cannot setup %s(%s): invalid server.trusted_proxies: %v", name, version, err)
+	}
+
 	corsOpts := core.DefaultCORSOptions()
 	corsOpts.AllowCredentials = true
+	corsOpts.AllowedHeaders = append(corsOpts.AllowedHeaders, "If-Match")
+	corsOpts.ExposedHeaders = append(corsOpts.ExposedHeaders, "ETag", "Accept-Patch")
 	router := core.NewRouterWithOptions(core.StackOptions{
-		Timeout:     60 * time.Second,
-		CORS:        &corsOpts,
-		DebugRoutes: cfg.Debug.Routes,
+		Timeout:      60 * time.Second,
+		CORS:         &corsOpts,
+		DebugRoutes:  cfg.Debug.Routes,
+		ContentTypes: []string{patch.MergePatchType, patch.JSONPatchType, "text/csv", "application/x-ndjson", "application/jsonl"},
+		RealIP:       core.RealIPMiddleware(trustedProxies),
 	}, xparams)
 
 	var deps []any
 
-	// Initialize MongoDB repository
-	propertyRepo := mongo.NewPropertyRepo(xparams)
-	deps = append(deps, propertyRepo)
+	// Initialize repositories; the property repository opens the database
+	// the others share, so it starts first
+	repos := configureRepos(cfg, xparams)
+	propertyRepo := repos.properties
+	logger.Infof("property repository: %T", propertyRepo)
+	deps = append(deps, propertyRepo, repos.developments, repos.listings, repos.leases, repos.appointments, repos.inquiries)
+
+	// Initialize pricing; writes are valued in the base currency before they
+	// reach the repository, which also keeps the exchange rates
+	var pricing *estate.Pricing
+	writeRepo := propertyRepo
+	if pricingRepo, ok := propertyRepo.(estate.PricingRepo); ok {
+		pricing = estate.NewPricing(propertyRepo, pricingRepo, strings.ToUpper(cfg.Pricing.BaseCurrency))
+		deps = append(deps, pricing)
+		writeRepo = estate.NewPricedRepo(propertyRepo, pricing)
+	}
+
+	// Initialize full-text index; the indexed repository keeps it in sync
+	searchIndex := search.NewIndex(xparams)
+	indexedRepo := search.NewIndexedRepo(writeRepo, searchIndex, xparams)
+	deps = append(deps, searchIndex)
+
+	if len(os.Args) > 1 && os.Args[1] == "reindex" {
+		if err := reindex(ctx, indexedRepo, deps); err != nil {
+			logger.Errorf("Cannot reindex %s(%s): %v", name, version, err)
+			os.Exit(1)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "migrate-schema" {
+		if err := migrateSchema(ctx, propertyRepo, indexedRepo, deps); err != nil {
+			logger.Errorf("Cannot migrate schema %s(%s): %v", name, version, err)
+			os.Exit(1)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "import-rates" {
+		if err := importRates(ctx, pricing, os.Args[2:], deps); err != nil {
+			logger.Errorf("Cannot import exchange rates %s(%s): %v", name, version, err)
+			os.Exit(1)
+		}
+		return
+	}
+	deps = append(deps, indexedRepo)
+
+	// Initialize dictionary client
+	dictClient := configureDictionaryClient(cfg, xparams)
+	logger.Infof("dictionary client: %T", dictClient)
+
+	// Initialize token verification and authorizer
+	authenticator, err := configureAuthenticator(cfg)
+	if err != nil {
+		logger.Errorf("Cannot setup authentication %s(%s): %v", name, version, err)
+		os.Exit(1)
+	}
+	logger.Infof("authenticator: %T", authenticator)
+
+	authorizer := configureAuthorizer(cfg)
+	logger.Infof("authorizer: %T", authorizer)
+
+	// Initialize the blob store shared by media and imports
+	blobStore := configureBlobStore(cfg, xparams)
+	logger.Infof("media blob store: %T", blobStore)
+	deps = append(deps, blobStore)
 
-	// Initialize fake fake client (will be replaced with HTTP client when fake service exists)
-	dictClient := fake.NewDictionary()
+	// Initialize media library; metadata is kept by the property repository
+	var mediaLibrary *estate.MediaLibrary
+	if mediaRepo, ok := propertyRepo.(estate.MediaRepo); ok {
+		mediaLibrary = estate.NewMediaLibrary(mediaRepo, blobStore, cfg.Media.MaxUploadBytes)
+	}
+
+	// Initialize duplicate detection, used by creates and imports
+	duplicates, err := estate.NewDuplicateDetector(indexedRepo, cfg.Duplicates.Threshold)
+	if err != nil {
+		logger.Errorf("Cannot setup duplicate detection %s(%s): %v", name, version, err)
+		os.Exit(1)
+	}
+
+	// Initialize bulk imports; jobs are kept by the property repository and
+	// rows are created through the indexed repository
+	var importer *estate.Importer
+	if importRepo, ok := propertyRepo.(estate.ImportRepo); ok {
+		importer = estate.NewImporter(indexedRepo, importRepo, blobStore, dictClient, duplicates, cfg.Imports.MaxUploadBytes)
+		deps = append(deps, importer)
+	}
+
+	// Initialize syndication feeds; photos are linked when the repository
+	// keeps media
+	mediaRepo, _ := propertyRepo.(estate.MediaRepo)
+	feeds, err := configureFeeds(cfg, indexedRepo, dictClient, mediaRepo, logger)
+	if err != nil {
+		logger.Errorf("Cannot setup feeds %s(%s): %v", name, version, err)
+		os.Exit(1)
+	}
+	deps = append(deps, feeds)
 
-	// Initialize property handler
-	propertyHandler := estate.NewHandler(propertyRepo, dictClient, xparams)
-	deps = append(deps, propertyHandler)
+	// Initialize the trash purge; media blobs are removed with the purged
+	// properties
+	trash, err := configureTrash(cfg, indexedRepo, mediaLibrary, logger)
+	if err != nil {
+		logger.Errorf("Cannot setup trash %s(%s): %v", name, version, err)
+		os.Exit(1)
+	}
+	deps = append(deps, trash)
+
+	// Initialize developments; units are saved through the indexed repository
+	developments := estate.NewDevelopments(indexedRepo, repos.developments)
+
+	// Initialize appointments
+	appointments, err := configureAppointments(cfg, repos.appointments)
+	if err != nil {
+		logger.Errorf("Cannot setup appointments %s(%s): %v", name, version, err)
+		os.Exit(1)
+	}
+
+	// Initialize inquiries when an encryption key is set; without one the
+	// public form and the leads API answer 503, so say so loudly
+	inquiries, err := configureInquiries(cfg, repos.inquiries)
+	if err != nil {
+		logger.Errorf("Cannot setup inquiries %s(%s): %v", name, version, err)
+		os.Exit(1)
+	}
+	if inquiries == nil {
+		logger.Errorf("Inquiries are disabled: set inquiries.encryption_key (or ESTATE_INQUIRIES_ENCRYPTION_KEY) to enable them")
+	}
+
+	// Initialize the event relay; events are written to the outbox by the
+	// property repository
+	relay, err := configureRelay(cfg, propertyRepo, logger)
+	if err != nil {
+		logger.Errorf("Cannot setup event relay %s(%s): %v", name, version, err)
+		os.Exit(1)
+	}
+	if relay != nil {
+		deps = append(deps, relay)
+	}
+
+	// Initialize handlers; they share the access checks on properties
+	access := estate.NewPropertyAccess(indexedRepo, authenticator, authorizer, xparams)
+	propertyHandler := estate.NewHandler(estate.HandlerDeps{
+		Access:       access,
+		Dictionary:   dictClient,
+		Searcher:     indexedRepo,
+		Trash:        trash,
+		Duplicates:   duplicates,
+		Developments: developments,
+	}, xparams)
+	deps = append(deps,
+		propertyHandler,
+		estate.NewDevelopmentHandler(developments, access, xparams),
+		estate.NewListingHandler(repos.listings, access, xparams),
+		estate.NewLeaseHandler(repos.leases, access, xparams),
+		estate.NewAppointmentHandler(appointments, access, xparams),
+		estate.NewInquiryHandler(inquiries, access, xparams),
+		estate.NewMediaHandler(mediaLibrary, access, xparams),
+		estate.NewPricingHandler(pricing, access, xparams),
+		estate.NewImportHandler(importer, access, xparams),
+		estate.NewFeedHandler(feeds, access, xparams),
+		estate.NewEventHandler(relay, access, xparams),
+		estate.NewResoHandler(dictClient, mediaLibrary, access, xparams),
+	)
 
 	starts, stops, _ := core.Setup(ctx, router, deps...)
 
@@ -74,3 +252,324 @@ func main() {
 	logger.Infof("Shutting down %s(%s)...", name, version)
 	cancel()
 }
+
+func configureDictionaryClient(cfg *config.Config, xparams config.XParams) estate.Client {
+	switch strings.ToLower(strings.TrimSpace(cfg.Dictionary.Client)) {
+	case "fake":
+		return fake.NewDictionary()
+	default:
+		return dictionary.NewHTTPClient(xparams)
+	}
+}
+
+func configureAuthenticator(cfg *config.Config) (core.Authenticator, error) {
+	switch strings.ToLower(strings.TrimSpace(cfg.Authn.Client)) {
+	case "fake":
+		return core.NewFakeAuthenticator(), nil
+	case "", "token":
+		key, err := base64.StdEncoding.DecodeString(cfg.Authn.PublicKey)
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("authn.public_key must be the base64 Ed25519 public key of the authn service (or set authn.client to fake for offline development)")
+		}
+		return authpkg.NewTokenVerifier(ed25519.PublicKey(key), "session"), nil
+	default:
+		return nil, fmt.Errorf("unknown authn client: %s", cfg.Authn.Client)
+	}
+}
+
+func configureAuthorizer(cfg *config.Config) estate.Authorizer {
+	switch strings.ToLower(strings.TrimSpace(cfg.Authz.Client)) {
+	case "fake":
+		return fake.NewAuthorizer()
+	default:
+		return core.NewAuthZHTTPClient(cfg.Services.AuthzURL)
+	}
+}
+
+// repos are the repositories of the configured database driver.
+type repos struct {
+	properties   estate.Repo
+	developments estate.DevelopmentRepo
+	listings     estate.ListingRepo
+	leases       estate.LeaseRepo
+	appointments estate.AppointmentRepo
+	inquiries    estate.InquiryRepo
+}
+
+func configureRepos(cfg *config.Config, xparams config.XParams) repos {
+	switch strings.ToLower(strings.TrimSpace(cfg.Database.Driver)) {
+	case "sqlite":
+		properties := sqlite.NewPropertyRepo(xparams)
+		return repos{
+			properties:   properties,
+			developments: sqlite.NewDevelopmentRepo(properties, xparams),
+			listings:     sqlite.NewListingRepo(properties, xparams),
+			leases:       sqlite.NewLeaseRepo(properties, xparams),
+			appointments: sqlite.NewAppointmentRepo(properties, xparams),
+			inquiries:    sqlite.NewInquiryRepo(properties, xparams),
+		}
+	default:
+		properties := mongo.NewPropertyRepo(xparams)
+		return repos{
+			properties:   properties,
+			developments: mongo.NewDevelopmentRepo(properties, xparams),
+			listings:     mongo.NewListingRepo(properties, xparams),
+			leases:       mongo.NewLeaseRepo(properties, xparams),
+			appointments: mongo.NewAppointmentRepo(properties, xparams),
+			inquiries:    mongo.NewInquiryRepo(properties, xparams),
+		}
+	}
+}
+
+func configureBlobStore(cfg *config.Config, xparams config.XParams) estate.BlobStore {
+	switch strings.ToLower(strings.TrimSpace(cfg.Media.Store)) {
+	case "", "fs":
+		return blob.NewFSStore(xparams)
+	default:
+		log.Fatalf("unknown media store: %s", cfg.Media.Store)
+		return nil
+	}
+}
+
+func configureFeeds(cfg *config.Config, repo estate.Repo, dict estate.Client, media estate.MediaRepo, logger core.Logger) (*estate.Feeds, error) {
+	refresh, err := time.ParseDuration(cfg.Feeds.Refresh)
+	if err != nil || refresh <= 0 {
+		return nil, fmt.Errorf("invalid feeds.refresh %q", cfg.Feeds.Refresh)
+	}
+
+	names := slices.Sorted(maps.Keys(cfg.Feeds.Portals))
+	feeds := make([]estate.Feed, 0, len(names))
+	for _, n := range names {
+		p := cfg.Feeds.Portals[n]
+		feeds = append(feeds, estate.Feed{
+			Name:       n,
+			Format:     strings.ToLower(strings.TrimSpace(p.Format)),
+			Statuses:   p.Statuses,
+			OwnerID:    p.OwnerID,
+			PriceTypes: p.PriceTypes,
+			Language:   p.Language,
+			Types:      p.Types,
+			Features:   p.Features,
+		})
+	}
+
+	links := estate.FeedLinks{Listing: cfg.Feeds.ListingURL, Media: cfg.Feeds.MediaURL}
+	return estate.NewFeeds(repo, dict, media, feeds, []estate.FeedFormatter{kyero.NewFormatter()}, links, refresh, logger)
+}
+
+func configureTrash(cfg *config.Config, repo estate.Repo, media *estate.MediaLibrary, logger core.Logger) (*estate.Trash, error) {
+	if cfg.Trash.RetentionDays < 0 {
+		return nil, fmt.Errorf("invalid trash.retention_days %d", cfg.Trash.RetentionDays)
+	}
+	interval, err := time.ParseDuration(cfg.Trash.PurgeInterval)
+	if err != nil || interval <= 0 {
+		return nil, fmt.Errorf("invalid trash.purge_interval %q", cfg.Trash.PurgeInterval)
+	}
+
+	retention := time.Duration(cfg.Trash.RetentionDays) * 24 * time.Hour
+	return estate.NewTrash(repo, media, retention, interval, logger)
+}
+
+// configureAppointments returns the appointments stored in repo under the
+// configured buffers.
+func configureAppointments(cfg *config.Config, repo estate.AppointmentRepo) (*estate.Appointments, error) {
+	agent, err := time.ParseDuration(cfg.Appointments.AgentBuffer)
+	if err != nil {
+		return nil, fmt.Errorf("invalid appointments.agent_buffer %q", cfg.Appointments.AgentBuffer)
+	}
+	property, err := time.ParseDuration(cfg.Appointments.PropertyBuffer)
+	if err != nil {
+		return nil, fmt.Errorf("invalid appointments.property_buffer %q", cfg.Appointments.PropertyBuffer)
+	}
+
+	buffers := estate.AppointmentBuffers{Agent: agent, Property: property}
+	return estate.NewAppointments(repo, buffers, []byte(cfg.Appointments.FeedSecret), cfg.Appointments.FeedURL)
+}
+
+// configureInquiries returns the inquiries stored in repo with contacts
+// encrypted under the configured keys, or nil when no encryption key is
+// set.
+func configureInquiries(cfg *config.Config, repo estate.InquiryRepo) (*estate.Inquiries, error) {
+	if cfg.Inquiries.EncryptionKey == "" {
+		return nil, nil
+	}
+	rateWindow, err := time.ParseDuration(cfg.Inquiries.RateWindow)
+	if err != nil {
+		return nil, fmt.Errorf("invalid inquiries.rate_window %q", cfg.Inquiries.RateWindow)
+	}
+	duplicateWindow, err := time.ParseDuration(cfg.Inquiries.DuplicateWindow)
+	if err != nil {
+		return nil, fmt.Errorf("invalid inquiries.duplicate_window %q", cfg.Inquiries.DuplicateWindow)
+	}
+
+	guard := estate.InquiryGuard{
+		RateLimit:       cfg.Inquiries.RateLimit,
+		RateWindow:      rateWindow,
+		DuplicateWindow: duplicateWindow,
+		MaxLinks:        cfg.Inquiries.MaxLinks,
+	}
+	return estate.NewInquiries(repo, []byte(cfg.Inquiries.EncryptionKey), []byte(cfg.Inquiries.LookupKey), guard)
+}
+
+// configureRelay returns the relay publishing the outbox to the configured
+// bus, or nil when the bus is "none" or the repository keeps no outbox.
+func configureRelay(cfg *config.Config, repo estate.Repo, logger core.Logger) (*estate.Relay, error) {
+	outbox, ok := repo.(estate.OutboxRepo)
+	if !ok {
+		return nil, nil
+	}
+
+	var eventBus estate.EventBus
+	switch strings.ToLower(strings.TrimSpace(cfg.Events.Bus)) {
+	case "none":
+		return nil, nil
+	case "", "log":
+		eventBus = bus.NewLogBus(logger)
+	case "webhook":
+		timeout, err := time.ParseDuration(cfg.Events.WebhookTimeout)
+		if err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("invalid events.webhook_timeout %q", cfg.Events.WebhookTimeout)
+		}
+		if eventBus, err = bus.NewWebhookBus(cfg.Events.WebhookURL, timeout); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown events bus: %s", cfg.Events.Bus)
+	}
+
+	interval, err := time.ParseDuration(cfg.Events.Interval)
+	if err != nil || interval <= 0 {
+		return nil, fmt.Errorf("invalid events.interval %q", cfg.Events.Interval)
+	}
+	return estate.NewRelay(outbox, eventBus, interval, cfg.Events.BatchSize, logger)
+}
+
+// reindex rebuilds the full-text index from the property repository and exits.
+// Usage: estate reindex [flags]
+func reindex(ctx context.Context, repo *search.IndexedRepo, deps []any) error {
+	starts, stops, _ := core.Setup(ctx, chi.NewRouter(), deps...)
+	if err := core.Start(ctx, starts, stops); err != nil {
+		return err
+	}
+	defer func() {
+		for i := len(stops) - 1; i >= 0; i-- {
+			stops[i](context.Background())
+		}
+	}()
+
+	start := time.Now()
+	count, err := repo.Reindex(ctx)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("indexed %d properties in %s", count, time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+// migrateSchema rewrites every stored property older than the current schema
+// version, refreshes the full-text index and exits.
+// Usage: estate migrate-schema [flags]
+func migrateSchema(ctx context.Context, repo estate.Repo, indexed *search.IndexedRepo, deps []any) error {
+	migrator, ok := repo.(estate.SchemaMigrator)
+	if !ok {
+		return fmt.Errorf("%T does not support schema migrations", repo)
+	}
+
+	starts, stops, _ := core.Setup(ctx, chi.NewRouter(), deps...)
+	if err := core.Start(ctx, starts, stops); err != nil {
+		return err
+	}
+	defer func() {
+		for i := len(stops) - 1; i >= 0; i-- {
+			stops[i](context.Background())
+		}
+	}()
+
+	start := time.Now()
+	report, err := migrator.MigrateSchema(ctx)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("upgraded %d of %d properties to schema v%d in %s",
+		report.Upgraded, report.Scanned, estate.Schema.Current(), time.Since(start).Round(time.Millisecond))
+	for _, f := range report.Failures {
+		log.Printf("cannot upgrade property %s: %s", f.ID, f.Error)
+	}
+
+	if report.Upgraded > 0 {
+		if _, err := indexed.Reindex(ctx); err != nil {
+			return fmt.Errorf("cannot reindex: %w", err)
+		}
+	}
+	if len(report.Failures) > 0 {
+		return fmt.Errorf("%d properties could not be upgraded", len(report.Failures))
+	}
+	return nil
+}
+
+// importRates stores the exchange rates of a CSV or JSON file, revalues every
+// property in the base currency and exits. Without a file, properties are
+// only revalued, e.g. after changing the base currency.
+// Usage: estate import-rates [rates.csv|rates.json] [flags]
+func importRates(ctx context.Context, pricing *estate.Pricing, args []string, deps []any) error {
+	if pricing == nil {
+		return fmt.Errorf("the property repository does not support pricing")
+	}
+
+	var rates []money.Rate
+	if path := firstArg(args); path != "" {
+		format := strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		rates, err = money.ReadRates(f, format)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	starts, stops, _ := core.Setup(ctx, chi.NewRouter(), deps...)
+	if err := core.Start(ctx, starts, stops); err != nil {
+		return err
+	}
+	defer func() {
+		for i := len(stops) - 1; i >= 0; i-- {
+			stops[i](context.Background())
+		}
+	}()
+
+	start := time.Now()
+	var (
+		result *estate.RateImport
+		err    error
+	)
+	if len(rates) > 0 {
+		result, err = pricing.ImportRates(ctx, rates)
+	} else {
+		result, err = pricing.Revalue(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Printf("imported %d exchange rates (%d stored), revalued %d properties in %s in %s",
+		result.Imported, result.Stored, result.Revalued, pricing.Base(), time.Since(start).Round(time.Millisecond))
+	if result.Unconverted > 0 {
+		log.Printf("%d properties have no exchange rate to %s", result.Unconverted, pricing.Base())
+	}
+	return nil
+}
+
+// firstArg returns the first positional argument, skipping flags.
+func firstArg(args []string) string {
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			return arg
+		}
+	}
+	return ""
+}